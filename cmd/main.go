@@ -2,10 +2,16 @@ package main
 
 import (
 	"chatx-01-backend/internal/app"
+	"chatx-01-backend/internal/bench"
+	"chatx-01-backend/internal/export"
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -17,8 +23,19 @@ func main() {
 	command := os.Args[1]
 
 	switch command {
-	case "http", "createsuperuser", "consume":
-		run(command)
+	case "http":
+		run(command, (*app.App).RunHTTPServer)
+	case "dev":
+		runWith(command, app.BuildDev, (*app.App).RunDevServer)
+	case "createsuperuser":
+		run(command, (*app.App).CreateSuperUser)
+	case "consume":
+		run(command, (*app.App).RunNotificationConsumer)
+	case "export":
+		opts := parseExportFlags(os.Args[2:])
+		run(command, func(a *app.App) error { return a.Export(opts) })
+	case "bench":
+		runBench(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n\n", command)
 		printUsage()
@@ -26,28 +43,86 @@ func main() {
 	}
 }
 
-func run(command string) {
+func run(command string, fn func(*app.App) error) {
+	runWith(command, app.Build, fn)
+}
+
+func runWith(command string, build func(context.Context) (*app.App, error), fn func(*app.App) error) {
 	ctx := context.Background()
 
-	application, err := app.Build(ctx)
+	application, err := build(ctx)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to build application", "error", err)
+		os.Exit(1)
 	}
-	defer application.Close()
 
-	switch command {
-	case "http":
-		if err := application.RunHTTPServer(); err != nil {
-			log.Fatal(err)
-		}
-	case "createsuperuser":
-		if err := application.CreateSuperUser(); err != nil {
-			log.Fatal(err)
-		}
-	case "consume":
-		if err := application.RunNotificationConsumer(); err != nil {
-			log.Fatal(err)
-		}
+	runErr := fn(application)
+
+	// Close explicitly rather than deferring, os.Exit would skip deferred calls.
+	application.Close()
+
+	if runErr != nil {
+		slog.Error("command failed", "command", command, "error", runErr)
+		os.Exit(1)
+	}
+}
+
+// parseExportFlags parses the export flags before the application is built,
+// so mistakes are reported without connecting anywhere.
+func parseExportFlags(args []string) app.ExportOptions {
+	var what, since string
+	opts := app.ExportOptions{}
+
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.StringVar(&what, "what", "", "dataset to export: users, chats or messages")
+	fs.StringVar(&since, "since", "", "only rows changed at or after this date (2006-01-02) or RFC 3339 time")
+	fs.StringVar(&opts.Out, "out", "", "output file, defaults to <what>-<time>.ndjson")
+	fs.BoolVar(&opts.Gzip, "gzip", false, "gzip the output file")
+	fs.BoolVar(&opts.Upload, "upload", false, "upload gzip'd to MinIO under exports/ instead of writing a file")
+	_ = fs.Parse(args)
+
+	opts.Dataset = export.Dataset(what)
+	if !opts.Dataset.IsValid() {
+		fmt.Fprintf(os.Stderr, "invalid -what %q: must be users, chats or messages\n", what)
+		os.Exit(2)
+	}
+
+	t, err := export.ParseSince(since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -since: %v\n", err)
+		os.Exit(2)
+	}
+	opts.Since = t
+
+	return opts
+}
+
+// runBench generates load against a running server, so unlike the other
+// commands it doesn't build the application.
+func runBench(args []string) {
+	cfg := bench.Config{}
+
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.StringVar(&cfg.BaseURL, "url", "http://localhost:9900", "server base URL")
+	fs.StringVar(&cfg.AdminEmail, "admin-email", os.Getenv("BENCH_ADMIN_EMAIL"), "email of the admin creating the users")
+	fs.StringVar(&cfg.AdminPassword, "admin-password", os.Getenv("BENCH_ADMIN_PASSWORD"), "password of the admin")
+	fs.StringVar(&cfg.Workspace, "workspace", "", "workspace slug, defaults to the admin's default workspace")
+	fs.IntVar(&cfg.Users, "users", 50, "number of simulated users")
+	fs.IntVar(&cfg.GroupSize, "group-size", 5, "users per generated group chat")
+	fs.Float64Var(&cfg.Rate, "rate", 20, "messages per second across all users")
+	fs.DurationVar(&cfg.Duration, "duration", 30*time.Second, "how long to send messages")
+	fs.DurationVar(&cfg.Drain, "drain", 5*time.Second, "how long to wait for outstanding deliveries")
+	fs.DurationVar(&cfg.Timeout, "timeout", 10*time.Second, "HTTP request timeout")
+	fs.BoolVar(&cfg.Cleanup, "cleanup", true, "delete the generated users afterwards")
+	_ = fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := bench.Run(ctx, cfg, slog.Default(), os.Stdout); err != nil {
+		stop()
+		slog.Error("command failed", "command", "bench", "error", err)
+		os.Exit(1)
 	}
 }
 
@@ -56,6 +131,9 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Available commands:")
 	fmt.Println("  http              Start HTTP server")
+	fmt.Println("  dev               Start HTTP server with in-memory data, for frontend development")
 	fmt.Println("  createsuperuser   Create a super user (admin)")
 	fmt.Println("  consume           Start notification consumer service")
+	fmt.Println("  export            Export users, chats or messages as NDJSON (see export -h)")
+	fmt.Println("  bench             Generate chat load against a running server (see bench -h)")
 }
@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"chatx-01-backend/internal/events"
+	eventbus "chatx-01-backend/pkg/events"
+)
+
+const (
+	queueSize   = 1024
+	sendTimeout = 5 * time.Second
+)
+
+type EventType string
+
+const (
+	EventMessageSent EventType = "message_sent"
+	EventChatCreated EventType = "chat_created"
+	EventLogin       EventType = "login"
+	EventWSConnect   EventType = "ws_connect"
+)
+
+// Event is a product usage event. The user ID never leaves the process; it
+// is replaced by a keyed hash before publishing.
+type Event struct {
+	Type        EventType
+	UserID      int
+	WorkspaceID int
+	Properties  map[string]string // low-cardinality values, no user content
+}
+
+// Publisher records product usage events.
+type Publisher interface {
+	// Track queues an event for delivery. It must not block the caller.
+	Track(ctx context.Context, event Event)
+}
+
+// Config holds configuration for the Kafka publisher.
+type Config struct {
+	// SampleRate is the share of users whose events are published, from 0 to 1.
+	// Sampling is per user so their events stay complete.
+	SampleRate float64
+	// Salt keys the hash that anonymizes user IDs.
+	Salt string
+}
+
+// BrokerPublisher publishes events to the event broker from a background worker.
+type BrokerPublisher struct {
+	producer eventbus.Producer
+	cfg      Config
+	logger   *slog.Logger
+
+	queue chan events.AnalyticsEvent
+	wg    sync.WaitGroup
+}
+
+// New creates a publisher sending events through producer.
+func New(producer eventbus.Producer, cfg Config, logger *slog.Logger) *BrokerPublisher {
+	p := &BrokerPublisher{
+		producer: producer,
+		cfg:      cfg,
+		logger:   logger,
+		queue:    make(chan events.AnalyticsEvent, queueSize),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// Track anonymizes and queues the event unless the user is sampled out;
+// events are dropped when the queue is full.
+func (p *BrokerPublisher) Track(ctx context.Context, event Event) {
+	anonymousID, sample := p.anonymize(event.UserID)
+	if sample >= p.cfg.SampleRate {
+		return
+	}
+
+	ae := events.AnalyticsEvent{
+		EventID:     newEventID(),
+		Type:        string(event.Type),
+		AnonymousID: anonymousID,
+		WorkspaceID: event.WorkspaceID,
+		Properties:  event.Properties,
+		OccurredAt:  time.Now().UTC(),
+	}
+
+	p.wg.Add(1)
+	select {
+	case p.queue <- ae:
+	default:
+		p.wg.Done()
+		p.logger.WarnContext(ctx, "analytics queue full, dropping event", "type", ae.Type)
+	}
+}
+
+// Flush waits until queued events are sent or timeout elapses.
+func (p *BrokerPublisher) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func (p *BrokerPublisher) run() {
+	for event := range p.queue {
+		if err := p.send(event); err != nil {
+			p.logger.Warn("failed to publish analytics event", "type", event.Type, "error", err)
+		}
+		p.wg.Done()
+	}
+}
+
+func (p *BrokerPublisher) send(event events.AnalyticsEvent) error {
+	data, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Not tied to the request, which has usually finished by now
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	return p.producer.SendMessage(ctx, &eventbus.Message{
+		Key:   []byte(event.AnonymousID),
+		Value: data,
+	})
+}
+
+// anonymize returns the keyed hash of a user ID and a number in [0, 1)
+// derived from it for sampling.
+func (p *BrokerPublisher) anonymize(userID int) (string, float64) {
+	mac := hmac.New(sha256.New, []byte(p.cfg.Salt))
+	mac.Write([]byte(strconv.Itoa(userID)))
+	sum := mac.Sum(nil)
+
+	sample := float64(binary.BigEndian.Uint64(sum[:8])) / (math.MaxUint64 + 1.0)
+	return hex.EncodeToString(sum[:16]), sample
+}
+
+// Nop returns a publisher that discards events, used when analytics is disabled.
+func Nop() Publisher {
+	return nopPublisher{}
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Track(context.Context, Event) {}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
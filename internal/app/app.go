@@ -2,42 +2,97 @@ package app
 
 import (
 	"bufio"
+	"chatx-01-backend/internal/analytics"
 	authHttp "chatx-01-backend/internal/auth/controller/http"
+	authDomain "chatx-01-backend/internal/auth/domain"
 	authInfra "chatx-01-backend/internal/auth/infra"
 	authPortal "chatx-01-backend/internal/auth/portal"
+	"chatx-01-backend/internal/auth/usecase/apikeyuc"
+	"chatx-01-backend/internal/auth/usecase/audituc"
 	"chatx-01-backend/internal/auth/usecase/authuc"
+	"chatx-01-backend/internal/auth/usecase/contactuc"
+	"chatx-01-backend/internal/auth/usecase/spamuc"
+	"chatx-01-backend/internal/auth/usecase/termsuc"
 	"chatx-01-backend/internal/auth/usecase/useruc"
+	"chatx-01-backend/internal/auth/usecase/workspaceuc"
 	chatHttp "chatx-01-backend/internal/chat/controller/http"
+	"chatx-01-backend/internal/chat/controller/ws"
+	chatDomain "chatx-01-backend/internal/chat/domain"
 	chatInfra "chatx-01-backend/internal/chat/infra"
+	chatPortal "chatx-01-backend/internal/chat/portal"
 	"chatx-01-backend/internal/chat/usecase/chatuc"
 	"chatx-01-backend/internal/chat/usecase/messageuc"
 	"chatx-01-backend/internal/chat/usecase/notificationuc"
-	"chatx-01-backend/internal/chat/controller/ws"
 	"chatx-01-backend/internal/config"
+	"chatx-01-backend/internal/export"
 	"chatx-01-backend/internal/notifications"
+	notificationHttp "chatx-01-backend/internal/notifications/controller/http"
+	notificationDomain "chatx-01-backend/internal/notifications/domain"
+	notificationInfra "chatx-01-backend/internal/notifications/infra"
+	notificationPortal "chatx-01-backend/internal/notifications/portal"
 	notificationUC "chatx-01-backend/internal/notifications/usecase"
+	"chatx-01-backend/internal/notifications/usecase/inboxuc"
 	"chatx-01-backend/pkg/email"
+	"chatx-01-backend/pkg/errreport"
+	"chatx-01-backend/pkg/events"
 	"chatx-01-backend/pkg/filestore"
 	"chatx-01-backend/pkg/hasher"
+	"chatx-01-backend/pkg/httptools"
 	"chatx-01-backend/pkg/kafka"
+	"chatx-01-backend/pkg/linkscan"
+	"chatx-01-backend/pkg/llm"
+	"chatx-01-backend/pkg/logger"
+	"chatx-01-backend/pkg/metrics"
 	"chatx-01-backend/pkg/middleware"
+	"chatx-01-backend/pkg/natsjs"
 	"chatx-01-backend/pkg/pg"
+	"chatx-01-backend/pkg/rabbitmq"
+	"chatx-01-backend/pkg/ratelimit"
 	"chatx-01-backend/pkg/redis"
+	"chatx-01-backend/pkg/requestid"
+	"chatx-01-backend/pkg/snowflake"
+	"chatx-01-backend/pkg/spam"
 	"chatx-01-backend/pkg/token"
+	"chatx-01-backend/pkg/totp"
+	"chatx-01-backend/pkg/translate"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"fmt"
-	"log"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Event topics consumed by the notification service.
+const (
+	registrationEmailTopic   = "user.registration.email"
+	inviteEmailTopic         = "user.invite.email"
+	verificationEmailTopic   = "user.verification.email"
+	joinRequestEmailTopic    = "chat.join_request.email"
+	contactRequestEmailTopic = "user.contact_request.email"
 )
 
+// analyticsTopic receives anonymized usage events for downstream dashboards.
+const analyticsTopic = "analytics.events"
+
+// participantCountBatch is how many chats each guest cleanup run checks for
+// a drifted participant count.
+const participantCountBatch = 1000
+
 type App struct {
 	cfg         *config.Config
 	pool        *pgxpool.Pool
@@ -46,18 +101,60 @@ type App struct {
 	uc          *useCases
 	wsHub       *ws.Hub
 	wsHandler   *ws.Handler
+	logger      *slog.Logger
 }
 
 type infrastructure struct {
 	tokenService   *token.Service
 	passwordHasher hasher.Hasher
 	fileStore      filestore.Store
-	eventProducer  *kafka.Producer
+	eventBus       *events.MemoryBus // Set when events are delivered in-process
+	eventProducer  events.Producer
+	inviteProducer events.Producer
+	inviteSigner   *token.InviteSigner
+	guestSigner    *token.GuestLinkSigner
 	emailSender    email.Sender
-
-	userRepo    *authInfra.PgUserRepo
-	chatRepo    *chatInfra.PgChatRepo
-	messageRepo *chatInfra.PgMessageRepo
+	translator     translate.Translator
+	translations   messageuc.TranslationCache
+	assistant      llm.Provider
+	assistantCache messageuc.AssistantCache
+	linkScanner    linkscan.Scanner
+	linkScanCache  messageuc.LinkScanCache
+	rateLimiter    ratelimit.Limiter
+	spamCounter    spam.DistinctCounter
+	messageIDs     *snowflake.Generator
+
+	verificationProducer events.Producer
+	verificationSigner   *token.VerificationSigner
+
+	joinRequestProducer    events.Producer
+	contactRequestProducer events.Producer
+
+	analyticsProducer  events.Producer
+	analyticsPublisher *analytics.BrokerPublisher
+	analytics          analytics.Publisher
+
+	userRepo      authDomain.UserRepository
+	termsRepo     authDomain.TermsRepository
+	workspaceRepo authDomain.WorkspaceRepository
+	spamRepo      authDomain.SpamRepository
+	blockRepo     authDomain.BlockRepository
+	contactRepo   authDomain.ContactRepository
+	apiKeyRepo    authDomain.APIKeyRepository
+	auditRepo     authDomain.AuditRepository
+	chatRepo      chatDomain.ChatRepository
+	messageRepo   chatDomain.MessageRepository
+	reactionRepo  chatDomain.ReactionRepository
+	mentionRepo   chatDomain.MentionRepository
+
+	notificationRepo notificationDomain.NotificationRepository
+
+	// Maintenance of the Postgres tables; nil in dev mode, where the
+	// in-memory repositories derive their counters
+	messageArchiver *chatInfra.MessageArchiver
+	partitionRepo   *chatInfra.PgPartitionRepo
+	pgChatRepo      *chatInfra.PgChatRepo
+	pgMessageRepo   *chatInfra.ArchivedMessageRepo
 
 	authPortal *authPortal.Portal
 }
@@ -65,43 +162,213 @@ type infrastructure struct {
 type useCases struct {
 	auth         authuc.UseCase
 	user         useruc.UseCase
+	terms        termsuc.UseCase
+	workspace    workspaceuc.UseCase
+	spam         spamuc.UseCase
+	contact      contactuc.UseCase
+	apiKey       apikeyuc.UseCase
+	audit        audituc.UseCase
 	chat         chatuc.UseCase
 	message      messageuc.UseCase
 	notification notificationuc.UseCase
 	emailNotif   notificationUC.UseCase
+	inbox        inboxuc.UseCase
 }
 
+// Build builds the application on Postgres, Redis, MinIO and the
+// configured event broker.
 func Build(ctx context.Context) (*App, error) {
+	return build(ctx, false)
+}
+
+// BuildDev builds the application for the dev command. Data is kept in
+// process, files on the local disk and events are delivered in-process, so
+// no external service is needed.
+func BuildDev(ctx context.Context) (*App, error) {
+	return build(ctx, true)
+}
+
+func build(ctx context.Context, dev bool) (*App, error) {
 	cfg := config.Load()
-	logger := slog.Default()
+	if dev {
+		cfg.Events.Broker = "memory"
+	}
+	appLogger := logger.New(logger.Config{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+	})
 
-	pool, err := pg.NewPostgresPool(ctx, cfg.Postgres.DSN())
-	if err != nil {
-		return nil, fmt.Errorf("failed to init postgres pool: %w", err)
+	if cfg.Sentry.DSN != "" {
+		reporter, err := errreport.NewSentry(errreport.SentryConfig{
+			DSN:         cfg.Sentry.DSN,
+			Environment: cfg.Sentry.Environment,
+			Release:     cfg.Sentry.Release,
+			ServerName:  hostname(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to init error reporter: %w", err)
+		}
+		errreport.SetDefault(reporter)
 	}
 
-	// Initialize Redis client
-	redisClient, err := redis.NewClient(redis.Config{
-		Host:     cfg.Redis.Host,
-		Port:     cfg.Redis.Port,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to init redis client: %w", err)
+	if cfg.LinkSigning.Secret == "" && dev {
+		// Links signed before a restart stop working
+		cfg.LinkSigning.Secret = rand.Text()
+		appLogger.Warn("LINK_SIGNING_SECRET is not set, signing links with a random secret")
+	}
+	if cfg.LinkSigning.Secret == "" {
+		return nil, fmt.Errorf("LINK_SIGNING_SECRET must be set")
+	}
+	if cfg.LinkSigning.Secret == cfg.AuthToken.Secret {
+		// Rotating the token keys would otherwise invalidate every link
+		return nil, fmt.Errorf("LINK_SIGNING_SECRET must differ from AUTH_TOKEN_SECRET")
+	}
+
+	if cfg.Analytics.Enabled && cfg.Analytics.Salt == "" && dev {
+		// Anonymized IDs change on every restart
+		cfg.Analytics.Salt = rand.Text()
+		appLogger.Warn("ANALYTICS_SALT is not set, anonymizing with a random salt")
+	}
+	if cfg.Analytics.Enabled && cfg.Analytics.Salt == "" {
+		return nil, fmt.Errorf("ANALYTICS_SALT must be set when analytics is enabled")
+	}
+
+	if !useruc.RegistrationMode(cfg.Registration.Mode).IsValid() {
+		return nil, fmt.Errorf("invalid registration mode %q", cfg.Registration.Mode)
+	}
+	if !authPortal.TermsEnforcement(cfg.Terms.Enforcement).IsValid() {
+		return nil, fmt.Errorf("invalid terms enforcement %q", cfg.Terms.Enforcement)
+	}
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.TLS.CertFile != "" && len(cfg.TLS.AutocertDomains) > 0 {
+		return nil, fmt.Errorf("set either TLS_CERT_FILE or TLS_AUTOCERT_DOMAINS, not both")
+	}
+	if !authDomain.SpamAction(cfg.Spam.Action).IsValid() {
+		return nil, fmt.Errorf("invalid spam action %q", cfg.Spam.Action)
+	}
+	if !useruc.DisposableEmailAction(cfg.Spam.DisposableEmailAction).IsValid() {
+		return nil, fmt.Errorf("invalid disposable email action %q", cfg.Spam.DisposableEmailAction)
+	}
+	if !ws.HubMode(cfg.WS.HubMode).IsValid() {
+		return nil, fmt.Errorf("invalid websocket hub mode %q", cfg.WS.HubMode)
+	}
+	if cfg.WS.TypingTTL <= 0 {
+		return nil, fmt.Errorf("invalid websocket typing ttl %s", cfg.WS.TypingTTL)
+	}
+	if cfg.WS.LastSeenInterval <= 0 {
+		return nil, fmt.Errorf("invalid websocket last seen interval %s", cfg.WS.LastSeenInterval)
+	}
+	if cfg.WS.ReplayTTL < 0 {
+		return nil, fmt.Errorf("invalid websocket replay ttl %s", cfg.WS.ReplayTTL)
+	}
+	if cfg.WS.ReplayTTL > 0 && cfg.WS.ReplaySize <= 0 {
+		return nil, fmt.Errorf("invalid websocket replay size %d", cfg.WS.ReplaySize)
+	}
+	if cfg.WS.MessageRate < 0 || cfg.WS.TypingRate < 0 || cfg.WS.FloodWarnings < 0 {
+		return nil, fmt.Errorf("invalid websocket rate limits")
+	}
+	if cfg.Chat.MaxAttachmentSize <= 0 {
+		return nil, fmt.Errorf("invalid attachment max size %d", cfg.Chat.MaxAttachmentSize)
+	}
+	if !chatDomain.RetentionMode(cfg.Retention.Mode).IsValid() {
+		return nil, fmt.Errorf("invalid message retention mode %q", cfg.Retention.Mode)
+	}
+	if cfg.Retention.Days < 0 || cfg.Retention.Days > chatDomain.MaxRetentionDays {
+		return nil, fmt.Errorf("invalid message retention days %d", cfg.Retention.Days)
+	}
+	if cfg.Retention.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid message retention batch size %d", cfg.Retention.BatchSize)
+	}
+	if cfg.Archive.AfterDays < 0 {
+		return nil, fmt.Errorf("invalid message archive after days %d", cfg.Archive.AfterDays)
+	}
+	if cfg.Archive.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid message archive batch size %d", cfg.Archive.BatchSize)
+	}
+	if cfg.Profile.DeletionGraceDays < 0 {
+		return nil, fmt.Errorf("invalid account deletion grace days %d", cfg.Profile.DeletionGraceDays)
+	}
+	if cfg.Partition.AheadMonths < 1 {
+		return nil, fmt.Errorf("invalid message partition ahead months %d", cfg.Partition.AheadMonths)
+	}
+	if cfg.Unread.RepairBatchSize <= 0 {
+		return nil, fmt.Errorf("invalid unread repair batch size %d", cfg.Unread.RepairBatchSize)
+	}
+
+	var (
+		pool        *pgxpool.Pool
+		redisClient *redis.Client
+		presence    *ws.Presence
+		relay       *ws.Relay
+		lastSeen    ws.LastSeenStore = ws.NewMemoryLastSeenStore()
+		replay      ws.ReplayStore   = ws.NewMemoryReplayStore()
+	)
+	if !dev {
+		var err error
+		pool, err = pg.NewPostgresPool(ctx, cfg.Postgres.DSN())
+		if err != nil {
+			return nil, fmt.Errorf("failed to init postgres pool: %w", err)
+		}
+
+		// Initialize Redis client
+		redisClient, err = redis.NewClient(redis.Config{
+			Host:     cfg.Redis.Host,
+			Port:     cfg.Redis.Port,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to init redis client: %w", err)
+		}
+		lastSeen = redisClient
+		replay = redisClient
+
+		// A single instance, as in dev or local mode, has no one to share
+		// presence and events with
+		if ws.HubMode(cfg.WS.HubMode) == ws.HubModeDistributed {
+			instance := instanceID()
+			presence = ws.NewPresence(redisClient, instance, cfg.WS.PresenceTTL, appLogger)
+			relay = ws.NewRelay(redisClient, instance, appLogger)
+		}
 	}
 
 	// Initialize WebSocket hub
-	wsHub := ws.NewHub(logger)
+	wsHub := ws.NewHub(
+		appLogger,
+		presence,
+		relay,
+		ws.NewLastSeen(lastSeen, cfg.WS.LastSeenInterval, appLogger),
+		newReplay(replay, cfg.WS, appLogger),
+		cfg.WS.TypingTTL,
+	)
 
 	// Initialize broadcaster
-	broadcaster := ws.NewBroadcaster(wsHub)
+	broadcaster := ws.NewBroadcaster(wsHub, cfg.WS.ReadReceiptWindow)
 
-	infra := initInfrastructure(pool, redisClient, cfg)
-	uc := initUseCases(infra, broadcaster, wsHub)
+	infra, err := initInfrastructure(pool, redisClient, cfg, appLogger)
+	if err != nil {
+		return nil, err
+	}
+	uc := initUseCases(cfg, infra, broadcaster, wsHub, appLogger)
 
 	// Initialize WebSocket handler
-	wsHandler := ws.NewHandler(wsHub, infra.chatRepo, infra.authPortal, logger)
+	wsHandler := ws.NewHandler(
+		wsHub,
+		infra.chatRepo,
+		infra.authPortal,
+		infra.analytics,
+		cfg.Chat.MaxMessageLength,
+		clientActions(uc.message, uc.notification),
+		ws.FloodLimits{
+			MessageRate: cfg.WS.MessageRate,
+			TypingRate:  cfg.WS.TypingRate,
+			MaxWarnings: cfg.WS.FloodWarnings,
+		},
+		cfg.WS.AllowedOrigins,
+		appLogger,
+	)
 
 	return &App{
 		cfg:         cfg,
@@ -111,72 +378,279 @@ func Build(ctx context.Context) (*App, error) {
 		uc:          uc,
 		wsHub:       wsHub,
 		wsHandler:   wsHandler,
+		logger:      appLogger,
 	}, nil
 }
 
+// newReplay keeps events for resuming clients, unless resuming is disabled.
+func newReplay(store ws.ReplayStore, cfg config.WSConfig, logger *slog.Logger) *ws.Replay {
+	if cfg.ReplayTTL == 0 {
+		return nil
+	}
+	return ws.NewReplay(store, cfg.ReplayTTL, cfg.ReplaySize, logger)
+}
+
+// clientActions runs the messages WebSocket clients send through the same use
+// cases and validation as the REST endpoints.
+func clientActions(message messageuc.UseCase, notification notificationuc.UseCase) ws.Actions {
+	return ws.Actions{
+		MarkRead: func(ctx context.Context, reads []ws.ReadPayload) error {
+			req := notificationuc.MarkChatsAsReadReq{
+				Reads: make([]notificationuc.ReadMarkDTO, len(reads)),
+			}
+			for i, read := range reads {
+				req.Reads[i] = notificationuc.ReadMarkDTO{ChatID: read.ChatID, MessageID: read.MessageID}
+			}
+			if err := req.Validate(); err != nil {
+				return err
+			}
+			return notification.MarkChatsAsRead(ctx, req)
+		},
+		SendMessage: func(ctx context.Context, chatID int, content string, attachments []string) (int, string, error) {
+			req := messageuc.SendMessageReq{ChatID: chatID, Content: content, Attachments: attachments}
+			if err := req.Validate(); err != nil {
+				return 0, "", err
+			}
+			resp, err := message.SendMessage(ctx, req)
+			if err != nil {
+				return 0, "", err
+			}
+			return resp.MessageID, resp.PublicID, nil
+		},
+		EditMessage: func(ctx context.Context, messageID int, content string) error {
+			req := messageuc.EditMessageReq{MessageID: messageID, Content: content}
+			if err := req.Validate(); err != nil {
+				return err
+			}
+			return message.EditMessage(ctx, req)
+		},
+		DeleteMessage: func(ctx context.Context, messageID int) error {
+			req := messageuc.DeleteMessageReq{MessageID: messageID}
+			if err := req.Validate(); err != nil {
+				return err
+			}
+			return message.DeleteMessage(ctx, req)
+		},
+	}
+}
+
 func (a *App) Close() {
+	errreport.Flush(5 * time.Second)
+
+	// Deliver queued usage events before their producer goes away
+	if a.infra.analyticsPublisher != nil {
+		a.infra.analyticsPublisher.Flush(5 * time.Second)
+	}
+
+	if a.infra.analyticsProducer != nil {
+		if err := a.infra.analyticsProducer.Close(); err != nil {
+			a.logger.Error("failed to close analytics event producer", "error", err)
+		} else {
+			a.logger.Info("analytics event producer closed")
+		}
+	}
+
 	if a.infra.eventProducer != nil {
 		if err := a.infra.eventProducer.Close(); err != nil {
-			log.Printf("Failed to close Kafka producer: %v", err)
+			a.logger.Error("failed to close event producer", "error", err)
+		} else {
+			a.logger.Info("event producer closed")
+		}
+	}
+
+	if a.infra.inviteProducer != nil {
+		if err := a.infra.inviteProducer.Close(); err != nil {
+			a.logger.Error("failed to close invite event producer", "error", err)
+		} else {
+			a.logger.Info("invite event producer closed")
+		}
+	}
+
+	if a.infra.verificationProducer != nil {
+		if err := a.infra.verificationProducer.Close(); err != nil {
+			a.logger.Error("failed to close verification event producer", "error", err)
+		} else {
+			a.logger.Info("verification event producer closed")
+		}
+	}
+
+	if a.infra.joinRequestProducer != nil {
+		if err := a.infra.joinRequestProducer.Close(); err != nil {
+			a.logger.Error("failed to close join request event producer", "error", err)
 		} else {
-			log.Println("Kafka producer closed")
+			a.logger.Info("join request event producer closed")
+		}
+	}
+
+	if a.infra.contactRequestProducer != nil {
+		if err := a.infra.contactRequestProducer.Close(); err != nil {
+			a.logger.Error("failed to close contact request event producer", "error", err)
+		} else {
+			a.logger.Info("contact request event producer closed")
 		}
 	}
 
 	if a.redisClient != nil {
 		if err := a.redisClient.Close(); err != nil {
-			log.Printf("Failed to close Redis client: %v", err)
+			a.logger.Error("failed to close redis client", "error", err)
 		} else {
-			log.Println("Redis client closed")
+			a.logger.Info("redis client closed")
 		}
 	}
 
 	if a.pool != nil {
 		a.pool.Close()
-		log.Println("Postgres pool closed")
+		a.logger.Info("postgres pool closed")
 	}
 }
 
-func initInfrastructure(pool *pgxpool.Pool, redisClient *redis.Client, cfg *config.Config) *infrastructure {
-	// Initialize JWT generator
-	tokenGenerator := token.NewGenerator(
-		cfg.AuthToken.Secret,
-		cfg.AuthToken.AccessTokenTTL,
-		cfg.AuthToken.RefreshTokenTTL,
-	)
+// loadTokenKeys returns the JWT signing keys, the current one first. Tokens
+// are signed with the private key if one is configured, else with the
+// secret.
+func loadTokenKeys(cfg config.AuthTokenConfig) ([]token.SigningKey, error) {
+	current := token.SigningKey{ID: cfg.KeyID, Secret: cfg.Secret}
+
+	// A PEM key in an environment variable may have its newlines escaped
+	privatePEM := []byte(strings.ReplaceAll(cfg.PrivateKey, `\n`, "\n"))
+	if cfg.PrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read AUTH_TOKEN_PRIVATE_KEY_FILE: %w", err)
+		}
+		privatePEM = data
+	}
+	if len(privatePEM) > 0 {
+		key, err := token.ParsePrivateKeyPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token private key: %w", err)
+		}
+		current = token.SigningKey{ID: cfg.KeyID, PrivateKey: key}
+	}
+
+	previous, err := token.ParseSigningKeys(cfg.PreviousKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_TOKEN_PREVIOUS_KEYS: %w", err)
+	}
+
+	keys := append([]token.SigningKey{current}, previous...)
+	for _, pair := range cfg.PreviousPublicKeys {
+		id, path, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("invalid AUTH_TOKEN_PREVIOUS_PUBLIC_KEY_FILES entry %q: want kid:path", pair)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key %q: %w", id, err)
+		}
+		key, err := token.ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %q: %w", id, err)
+		}
+		keys = append(keys, token.SigningKey{ID: id, PublicKey: key})
+	}
 
-	// Initialize token service with Redis
+	return keys, nil
+}
+
+// initInfrastructure wires the repositories and services. pool and
+// redisClient are nil in dev mode, which keeps their data in process.
+func initInfrastructure(
+	pool *pgxpool.Pool,
+	redisClient *redis.Client,
+	cfg *config.Config,
+	logger *slog.Logger,
+) (*infrastructure, error) {
+	// Initialize JWT generator, signing with the current key
+	tokenKeys, err := loadTokenKeys(cfg.AuthToken)
+	if err != nil {
+		return nil, err
+	}
+	tokenGenerator, err := token.NewGenerator(token.GeneratorConfig{
+		Keys:            tokenKeys,
+		Issuer:          cfg.AuthToken.Issuer,
+		Audience:        cfg.AuthToken.Audience,
+		AccessTokenTTL:  cfg.AuthToken.AccessTokenTTL,
+		RefreshTokenTTL: cfg.AuthToken.RefreshTokenTTL,
+		UnkeyedBefore:   cfg.AuthToken.UnkeyedIssuedBefore,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init token generator: %w", err)
+	}
+
+	// Initialize token service with Redis, or in process in dev mode
+	var tokenStore token.TokenStore = token.NewMemoryStore()
+	if redisClient != nil {
+		tokenStore = redisClient
+	}
 	tokenService := token.NewService(
 		tokenGenerator,
-		redisClient,
+		tokenStore,
 		cfg.AuthToken.AccessTokenTTL,
 		cfg.AuthToken.RefreshTokenTTL,
+		cfg.AuthToken.ValidationCacheTTL,
 	)
 
 	passwordHasher := hasher.NewHasher(100000, 16, 32)
-	fileStore := filestore.NewMinioStore(filestore.Config{
-		Endpoint:        cfg.MinIO.Endpoint,
-		Bucket:          cfg.MinIO.Bucket,
-		AccessKeyID:     cfg.MinIO.AccessKeyID,
-		SecretAccessKey: cfg.MinIO.SecretAccessKey,
-		UseSSL:          cfg.MinIO.UseSSL,
-	})
+	fileStore, err := newFileStore(cfg, pool == nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init file store: %w", err)
+	}
 
-	// Initialize Kafka producer
-	eventProducer, err := kafka.NewProducer(
-		kafka.ProducerConfig{
-			Brokers:      cfg.Kafka.Brokers,
-			SaslUsername: cfg.Kafka.SaslUsername,
-			SaslPassword: cfg.Kafka.SaslPassword,
-		},
-		"user.registration.email",
-		"chatx-api",
-	)
+	// Initialize event producers
+	var eventBus *events.MemoryBus
+	if cfg.Events.Broker == "memory" {
+		eventBus = events.NewMemoryBus()
+	}
+
+	eventProducer, err := newEventProducer(cfg, eventBus, registrationEmailTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event producer: %w", err)
+	}
+
+	inviteProducer, err := newEventProducer(cfg, eventBus, inviteEmailTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite event producer: %w", err)
+	}
+
+	verificationProducer, err := newEventProducer(cfg, eventBus, verificationEmailTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification event producer: %w", err)
+	}
+
+	joinRequestProducer, err := newEventProducer(cfg, eventBus, joinRequestEmailTopic)
 	if err != nil {
-		log.Fatalf("failed to create kafka producer: %v", err)
+		return nil, fmt.Errorf("failed to create join request event producer: %w", err)
 	}
 
-	// Initialize email sender
+	contactRequestProducer, err := newEventProducer(cfg, eventBus, contactRequestEmailTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contact request event producer: %w", err)
+	}
+
+	// Initialize analytics publisher
+	var (
+		analyticsProducer  events.Producer
+		analyticsPublisher *analytics.BrokerPublisher
+		analyticsPr        = analytics.Nop()
+	)
+	if cfg.Analytics.Enabled {
+		analyticsProducer, err = newEventProducer(cfg, eventBus, analyticsTopic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create analytics event producer: %w", err)
+		}
+
+		analyticsPublisher = analytics.New(analyticsProducer, analytics.Config{
+			SampleRate: cfg.Analytics.SampleRate,
+			Salt:       cfg.Analytics.Salt,
+		}, logger)
+		analyticsPr = analyticsPublisher
+	}
+
+	inviteSigner := token.NewInviteSigner(cfg.LinkSigning.Secret, cfg.LinkSigning.PreviousSecrets, cfg.Invite.TTL)
+	guestSigner := token.NewGuestLinkSigner(cfg.LinkSigning.Secret, cfg.LinkSigning.PreviousSecrets, cfg.Guest.LinkTTL)
+	verificationSigner := token.NewVerificationSigner(cfg.LinkSigning.Secret, cfg.LinkSigning.PreviousSecrets, cfg.Verification.TTL)
+
+	// Initialize email sender; without SMTP in dev mode emails are logged
 	emailSender := email.New(email.Config{
 		Host:     cfg.SMTP.Host,
 		Port:     cfg.SMTP.Port,
@@ -184,41 +658,469 @@ func initInfrastructure(pool *pgxpool.Pool, redisClient *redis.Client, cfg *conf
 		Password: cfg.SMTP.Password,
 		From:     cfg.SMTP.From,
 	})
+	if pool == nil && cfg.SMTP.Host == "" {
+		emailSender = email.NewLogSender(logger)
+	}
 
-	userRepo := authInfra.NewPgUserRepo(pool)
-	chatRepo := chatInfra.NewPgChatRepo(pool)
-	messageRepo := chatInfra.NewPgMessageRepo(pool)
+	translator, err := newTranslator(cfg.Translation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init translator: %w", err)
+	}
 
-	authPr := authPortal.New(userRepo, tokenService)
+	assistant, err := newAssistant(cfg.Assistant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init assistant: %w", err)
+	}
+
+	linkScanner, err := newLinkScanner(cfg.LinkScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init link scanner: %w", err)
+	}
+
+	// Initialize message ID generator
+	messageIDs, err := snowflake.New(cfg.Snowflake.WorkerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create message id generator: %w", err)
+	}
 
-	return &infrastructure{
+	infra := &infrastructure{
 		tokenService:   tokenService,
 		passwordHasher: passwordHasher,
 		fileStore:      fileStore,
+		eventBus:       eventBus,
 		eventProducer:  eventProducer,
+		inviteProducer: inviteProducer,
+		inviteSigner:   inviteSigner,
+		guestSigner:    guestSigner,
 		emailSender:    emailSender,
-		userRepo:       userRepo,
-		chatRepo:       chatRepo,
-		messageRepo:    messageRepo,
-		authPortal:     authPr,
+		translator:     translator,
+		assistant:      assistant,
+		linkScanner:    linkScanner,
+		messageIDs:     messageIDs,
+
+		verificationProducer: verificationProducer,
+		verificationSigner:   verificationSigner,
+
+		joinRequestProducer:    joinRequestProducer,
+		contactRequestProducer: contactRequestProducer,
+
+		analyticsProducer:  analyticsProducer,
+		analyticsPublisher: analyticsPublisher,
+		analytics:          analyticsPr,
+	}
+
+	if pool == nil {
+		authStore := authInfra.NewMemStore()
+		chatStore := chatInfra.NewMemStore()
+		authStore.OnUserDeleted(chatStore.DeleteUser)
+
+		infra.userRepo = authInfra.NewMemUserRepo(authStore)
+		infra.termsRepo = authInfra.NewMemTermsRepo(authStore)
+		infra.workspaceRepo = authInfra.NewMemWorkspaceRepo(authStore)
+		infra.spamRepo = authInfra.NewMemSpamRepo(authStore)
+		infra.blockRepo = authInfra.NewMemBlockRepo(authStore)
+		infra.contactRepo = authInfra.NewMemContactRepo(authStore)
+		infra.apiKeyRepo = authInfra.NewMemAPIKeyRepo(authStore)
+		infra.auditRepo = authInfra.NewMemAuditRepo(authStore)
+		infra.chatRepo = chatInfra.NewMemChatRepo(chatStore)
+		infra.messageRepo = chatInfra.NewMemMessageRepo(chatStore, messageIDs)
+		infra.reactionRepo = chatInfra.NewMemReactionRepo(chatStore)
+		infra.mentionRepo = chatInfra.NewMemMentionRepo(chatStore)
+		infra.translations = translate.NewMemoryCache()
+		infra.assistantCache = llm.NewMemoryCache()
+		infra.linkScanCache = linkscan.NewMemoryCache()
+		infra.rateLimiter = ratelimit.NewMemory()
+		infra.spamCounter = spam.NewMemory()
+		infra.notificationRepo = notificationInfra.NewMemNotificationRepo()
+	} else {
+		archiveRepo := chatInfra.NewPgArchiveRepo(pool)
+		infra.pgChatRepo = chatInfra.NewPgChatRepo(pool)
+		infra.pgMessageRepo = chatInfra.NewArchivedMessageRepo(
+			chatInfra.NewPgMessageRepo(pool, messageIDs),
+			archiveRepo,
+			fileStore,
+		)
+		infra.messageArchiver = chatInfra.NewMessageArchiver(archiveRepo, fileStore)
+		infra.partitionRepo = chatInfra.NewPgPartitionRepo(pool)
+
+		infra.userRepo = authInfra.NewPgUserRepo(pool)
+		infra.termsRepo = authInfra.NewPgTermsRepo(pool)
+		infra.workspaceRepo = authInfra.NewPgWorkspaceRepo(pool)
+		infra.spamRepo = authInfra.NewPgSpamRepo(pool)
+		infra.blockRepo = authInfra.NewPgBlockRepo(pool)
+		infra.contactRepo = authInfra.NewPgContactRepo(pool)
+		infra.apiKeyRepo = authInfra.NewPgAPIKeyRepo(pool)
+		infra.auditRepo = authInfra.NewPgAuditRepo(pool)
+		infra.chatRepo = infra.pgChatRepo
+		infra.messageRepo = infra.pgMessageRepo
+		infra.reactionRepo = chatInfra.NewPgReactionRepo(pool)
+		infra.mentionRepo = chatInfra.NewPgMentionRepo(pool)
+		infra.translations = redisClient
+		infra.assistantCache = redisClient
+		infra.linkScanCache = redisClient
+		infra.rateLimiter = redisClient
+		infra.spamCounter = redisClient
+		infra.notificationRepo = notificationInfra.NewPgNotificationRepo(pool)
+	}
+
+	adminNets, adminOTPKey, err := adminProtection(cfg.Admin)
+	if err != nil {
+		return nil, err
+	}
+
+	infra.authPortal = authPortal.New(
+		infra.userRepo,
+		infra.termsRepo,
+		infra.workspaceRepo,
+		infra.spamRepo,
+		infra.blockRepo,
+		infra.apiKeyRepo,
+		infra.auditRepo,
+		tokenService,
+		authPortal.Config{
+			TermsVersion:     cfg.Terms.Version,
+			TermsEnforcement: authPortal.TermsEnforcement(cfg.Terms.Enforcement),
+			AdminAllowedNets: adminNets,
+			AdminOTPKey:      adminOTPKey,
+		},
+		logger,
+	)
+
+	return infra, nil
+}
+
+// adminProtection parses the admin IP allowlist and one-time code secret.
+func adminProtection(cfg config.AdminConfig) ([]netip.Prefix, []byte, error) {
+	nets := make([]netip.Prefix, 0, len(cfg.AllowedCIDRs))
+	for _, cidr := range cfg.AllowedCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid admin allowed CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, prefix.Masked())
+	}
+
+	var otpKey []byte
+	if cfg.OTPSecret != "" {
+		var err error
+		otpKey, err = totp.DecodeSecret(cfg.OTPSecret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid ADMIN_OTP_SECRET: %w", err)
+		}
 	}
+
+	return nets, otpKey, nil
 }
 
-func initUseCases(infra *infrastructure, broadcaster ws.Broadcaster, wsHub *ws.Hub) *useCases {
+// newFileStore returns the MinIO store, or in dev mode a store on the local
+// disk.
+func newFileStore(cfg *config.Config, dev bool) (filestore.Store, error) {
+	if dev {
+		return filestore.NewFSStore(filepath.Join(cfg.Dev.DataDir, "files"))
+	}
+
+	return filestore.NewMinioStore(filestore.Config{
+		Endpoint:        cfg.MinIO.Endpoint,
+		Bucket:          cfg.MinIO.Bucket,
+		AccessKeyID:     cfg.MinIO.AccessKeyID,
+		SecretAccessKey: cfg.MinIO.SecretAccessKey,
+		UseSSL:          cfg.MinIO.UseSSL,
+	}), nil
+}
+
+func initUseCases(cfg *config.Config, infra *infrastructure, broadcaster ws.Broadcaster, wsHub *ws.Hub, logger *slog.Logger) *useCases {
+	chatPr := chatPortal.New(infra.chatRepo, infra.authPortal, broadcaster)
+	notificationPr := notificationPortal.New(infra.notificationRepo, broadcaster)
+
+	// With detection off, messages are not scored and disposable addresses
+	// register like any other; existing flags still apply
+	spamCfg := cfg.Spam
+	if !spamCfg.Enabled {
+		spamCfg.MessageLimit = 0
+		spamCfg.DuplicateChats = 0
+		spamCfg.DisposableEmailAction = string(useruc.DisposableEmailAllow)
+	}
+
 	return &useCases{
-		auth: authuc.New(infra.userRepo, infra.passwordHasher, infra.tokenService),
+		auth: authuc.New(
+			infra.userRepo,
+			infra.workspaceRepo,
+			infra.passwordHasher,
+			infra.tokenService,
+			infra.analytics,
+			infra.authPortal,
+			authuc.Config{RequireEmailVerification: cfg.Verification.Required},
+		),
 		user: useruc.New(
 			infra.userRepo,
+			infra.workspaceRepo,
+			infra.spamRepo,
+			infra.blockRepo,
 			infra.passwordHasher,
 			infra.fileStore,
 			infra.authPortal,
+			chatPr,
 			infra.eventProducer,
+			infra.verificationProducer,
 			infra.tokenService,
+			infra.inviteSigner,
+			infra.guestSigner,
+			infra.verificationSigner,
+			spam.NewDomainList(spamCfg.DisposableDomains),
+			logger,
+			useruc.Config{
+				RegistrationMode:      useruc.RegistrationMode(cfg.Registration.Mode),
+				GuestTTL:              cfg.Guest.TTL,
+				VerifyEmailURL:        cfg.Verification.URL,
+				ConfirmEmailChangeURL: cfg.Verification.ChangeURL,
+				UsernameCooldown:      cfg.Profile.UsernameCooldown,
+				DeletionGraceDays:     cfg.Profile.DeletionGraceDays,
+				DisposableEmailAction: useruc.DisposableEmailAction(spamCfg.DisposableEmailAction),
+			},
+		),
+		terms: termsuc.New(
+			infra.termsRepo,
+			infra.authPortal,
+			termsuc.Config{Version: cfg.Terms.Version, URL: cfg.Terms.URL},
+		),
+		workspace: workspaceuc.New(
+			infra.workspaceRepo,
+			infra.authPortal,
+			infra.tokenService,
+			logger,
+		),
+		spam: spamuc.New(infra.spamRepo, infra.userRepo, infra.authPortal),
+		contact: contactuc.New(
+			infra.contactRepo,
+			infra.userRepo,
+			infra.blockRepo,
+			infra.authPortal,
+			chatPr,
+			infra.contactRequestProducer,
+			logger,
+		),
+		apiKey: apikeyuc.New(
+			infra.apiKeyRepo,
+			infra.authPortal,
+			logger,
+		),
+		audit: audituc.New(infra.auditRepo),
+		chat: chatuc.New(
+			infra.chatRepo,
+			infra.messageRepo,
+			infra.fileStore,
+			infra.authPortal,
+			notificationPr,
+			broadcaster,
+			infra.inviteSigner,
+			infra.inviteProducer,
+			infra.joinRequestProducer,
+			infra.guestSigner,
+			infra.analytics,
+			chatuc.Config{
+				InviteRegisterURL:  cfg.Invite.RegisterURL,
+				GuestJoinURL:       cfg.Guest.JoinURL,
+				WebhookBaseURL:     cfg.Webhook.BaseURL,
+				MaxWebhooksPerChat: cfg.Webhook.MaxPerChat,
+			},
+		),
+		message: messageuc.New(
+			infra.chatRepo,
+			infra.messageRepo,
+			infra.reactionRepo,
+			infra.mentionRepo,
+			infra.authPortal,
+			notificationPr,
+			broadcaster,
+			infra.fileStore,
+			infra.translator,
+			infra.translations,
+			infra.assistant,
+			infra.assistantCache,
+			infra.rateLimiter,
+			infra.spamCounter,
+			infra.messageIDs,
+			infra.linkScanner,
+			infra.linkScanCache,
+			infra.analytics,
+			logger,
+			messageuc.Config{
+				MaxMessageLength:     cfg.Chat.MaxMessageLength,
+				MaxAttachmentSize:    cfg.Chat.MaxAttachmentSize,
+				AttachmentTypes:      cfg.Chat.AttachmentTypes,
+				TranslationCacheTTL:  cfg.Translation.CacheTTL,
+				AssistantMaxMessages: cfg.Assistant.MaxMessages,
+				AssistantCacheTTL:    cfg.Assistant.CacheTTL,
+				WebhookRateLimit:     cfg.Webhook.RateLimit,
+				LinkScanCacheTTL:     cfg.LinkScan.CacheTTL,
+				SpamMessageLimit:     spamCfg.MessageLimit,
+				SpamMessageWindow:    spamCfg.MessageWindow,
+				SpamDuplicateChats:   spamCfg.DuplicateChats,
+				SpamDuplicateWindow:  spamCfg.DuplicateWindow,
+				SpamShadow:           authDomain.SpamAction(spamCfg.Action) == authDomain.SpamActionShadow,
+				RetentionDays:        cfg.Retention.Days,
+				RetentionMode:        chatDomain.RetentionMode(cfg.Retention.Mode),
+				RetentionDryRun:      cfg.Retention.DryRun,
+				RetentionBatchSize:   cfg.Retention.BatchSize,
+			},
 		),
-		chat:         chatuc.New(infra.chatRepo, infra.messageRepo, infra.authPortal),
-		message:      messageuc.New(infra.chatRepo, infra.messageRepo, infra.authPortal, broadcaster),
-		notification: notificationuc.New(infra.chatRepo, infra.messageRepo, infra.authPortal, broadcaster, wsHub),
-		emailNotif:   notificationUC.New(infra.emailSender),
+		notification: notificationuc.New(
+			infra.chatRepo,
+			infra.messageRepo,
+			infra.mentionRepo,
+			infra.authPortal,
+			broadcaster,
+			wsHub,
+			wsHub,
+		),
+		emailNotif: notificationUC.New(infra.emailSender, logger),
+		inbox:      inboxuc.New(infra.notificationRepo, infra.authPortal, broadcaster),
+	}
+}
+
+// newEventProducer returns a producer for the topic on the configured broker.
+// bus is only used by the memory broker.
+func newEventProducer(cfg *config.Config, bus *events.MemoryBus, topic string) (events.Producer, error) {
+	const serviceName = "chatx-api"
+
+	switch cfg.Events.Broker {
+	case "memory":
+		return bus.NewProducer(topic), nil
+	case "kafka":
+		return kafka.NewProducer(kafka.ProducerConfig{
+			Brokers:      cfg.Kafka.Brokers,
+			SaslUsername: cfg.Kafka.SaslUsername,
+			SaslPassword: cfg.Kafka.SaslPassword,
+		}, topic, serviceName)
+	case "nats":
+		return natsjs.NewProducer(natsConfig(cfg.NATS), topic, serviceName)
+	case "rabbitmq":
+		return rabbitmq.NewProducer(rabbitmq.Config{URL: cfg.RabbitMQ.URL}, topic, serviceName)
+	default:
+		return nil, fmt.Errorf("unknown event broker %q", cfg.Events.Broker)
+	}
+}
+
+// newEventConsumer returns a consumer of the topic on the configured broker.
+// Consumers sharing a group ID split the topic's messages between them.
+func (a *App) newEventConsumer(
+	topic, groupID, serviceName, serviceVersion string,
+	handleFn events.HandleFunc,
+) (events.Consumer, error) {
+	switch a.cfg.Events.Broker {
+	case "memory":
+		return a.infra.eventBus.NewConsumer(topic, groupID, handleFn, a.logger), nil
+	case "kafka":
+		return kafka.NewConsumer(
+			kafka.ConsumerConfig{
+				Brokers:      a.cfg.Kafka.Brokers,
+				SaslUsername: a.cfg.Kafka.SaslUsername,
+				SaslPassword: a.cfg.Kafka.SaslPassword,
+				GroupID:      groupID,
+
+				HandlerTimeout: a.cfg.Kafka.HandlerTimeout,
+				RetryDisabled:  a.cfg.Kafka.RetryCount <= 0,
+				RetryCount:     uint8(min(a.cfg.Kafka.RetryCount, 255)),
+				RetryDelay:     a.cfg.Kafka.RetryDelay,
+			},
+			topic,
+			serviceName,
+			serviceVersion,
+			handleFn,
+			a.logger,
+		)
+	case "nats":
+		return natsjs.NewConsumer(
+			natsjs.ConsumerConfig{
+				Config:  natsConfig(a.cfg.NATS),
+				Durable: groupID,
+
+				HandlerTimeout: a.cfg.NATS.HandlerTimeout,
+				RetryDisabled:  a.cfg.NATS.RetryCount <= 0,
+				RetryCount:     uint8(min(a.cfg.NATS.RetryCount, 255)),
+				RetryDelay:     a.cfg.NATS.RetryDelay,
+			},
+			topic,
+			serviceName,
+			serviceVersion,
+			handleFn,
+			a.logger,
+		)
+	case "rabbitmq":
+		return rabbitmq.NewConsumer(
+			rabbitmq.ConsumerConfig{
+				Config:  rabbitmq.Config{URL: a.cfg.RabbitMQ.URL},
+				GroupID: groupID,
+
+				HandlerTimeout: a.cfg.RabbitMQ.HandlerTimeout,
+				RetryDisabled:  a.cfg.RabbitMQ.RetryCount <= 0,
+				RetryCount:     uint8(min(a.cfg.RabbitMQ.RetryCount, 255)),
+				RetryDelay:     a.cfg.RabbitMQ.RetryDelay,
+			},
+			topic,
+			serviceName,
+			serviceVersion,
+			handleFn,
+			a.logger,
+		)
+	default:
+		return nil, fmt.Errorf("unknown event broker %q", a.cfg.Events.Broker)
+	}
+}
+
+func natsConfig(cfg config.NATSConfig) natsjs.Config {
+	return natsjs.Config{
+		URL:          cfg.URL,
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		StreamMaxAge: cfg.StreamMaxAge,
+	}
+}
+
+// newTranslator returns the configured translation provider, or nil if
+// translation is disabled.
+func newTranslator(cfg config.TranslationConfig) (translate.Translator, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "deepl":
+		return translate.NewDeepL(translate.DeepLConfig{APIKey: cfg.APIKey})
+	case "google":
+		return translate.NewGoogle(translate.GoogleConfig{APIKey: cfg.APIKey})
+	default:
+		return nil, fmt.Errorf("unknown translation provider %q", cfg.Provider)
+	}
+}
+
+// newLinkScanner returns the configured link scanning provider, or nil if
+// link scanning is disabled.
+func newLinkScanner(cfg config.LinkScanConfig) (linkscan.Scanner, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "safebrowsing":
+		return linkscan.NewSafeBrowsing(linkscan.SafeBrowsingConfig{APIKey: cfg.APIKey})
+	case "blocklist":
+		return linkscan.NewBlocklist(cfg.Blocklist), nil
+	default:
+		return nil, fmt.Errorf("unknown link scan provider %q", cfg.Provider)
+	}
+}
+
+// newAssistant returns the configured provider for chat summaries and
+// suggested replies, or nil if they are disabled.
+func newAssistant(cfg config.AssistantConfig) (llm.Provider, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "openai":
+		return llm.NewOpenAI(llm.OpenAIConfig{BaseURL: cfg.BaseURL, APIKey: cfg.APIKey, Model: cfg.Model})
+	default:
+		return nil, fmt.Errorf("unknown assistant provider %q", cfg.Provider)
 	}
 }
 
@@ -227,25 +1129,271 @@ func (a *App) RunHTTPServer() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go a.wsHub.Run(ctx)
+	go a.runGuestCleanup(ctx)
+	go a.runAccountDeletion(ctx)
+	go a.runRetention(ctx)
+	if a.pool != nil {
+		go a.runPartitionMaintenance(ctx)
+		go a.runUnreadRepair(ctx)
+		if a.cfg.Archive.AfterDays > 0 {
+			go a.runArchival(ctx)
+		}
+	}
+	if a.infra.eventBus != nil {
+		if err := a.startInProcessConsumers(ctx); err != nil {
+			return err
+		}
+	}
 
 	srv := a.setupHTTPServer()
-	return a.runServer(srv)
+
+	tlsCfg, certManager, err := newTLSConfig(a.cfg.TLS)
+	if err != nil {
+		return fmt.Errorf("failed to init tls: %w", err)
+	}
+	srv.TLSConfig = tlsCfg
+
+	var redirect *http.Server
+	if tlsCfg != nil && a.cfg.TLS.RedirectAddr != "" {
+		handler := redirectToHTTPS(a.cfg.Server.Addr)
+		if certManager != nil {
+			handler = certManager.HTTPHandler(handler)
+		}
+		redirect = &http.Server{
+			Addr:              a.cfg.TLS.RedirectAddr,
+			Handler:           handler,
+			ReadHeaderTimeout: a.cfg.Server.ReadTimeout,
+			IdleTimeout:       a.cfg.Server.IdleTimeout,
+		}
+	}
+
+	return a.runServer(srv, redirect)
+}
+
+// runPartitionMaintenance creates upcoming partitions of the messages table
+// at startup and then periodically until ctx is cancelled. Unlike the other
+// jobs it runs right away, so a server started after a long downtime does not
+// wait an interval before messages can be stored again.
+func (a *App) runPartitionMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Partition.Interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := a.infra.partitionRepo.EnsureMessagePartitions(ctx, time.Now(), a.cfg.Partition.AheadMonths)
+		if err != nil {
+			a.logger.ErrorContext(ctx, "failed to create message partitions", "error", err)
+		}
+		if created > 0 {
+			a.logger.InfoContext(ctx, "created message partitions", "count", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runGuestCleanup periodically deletes expired guest accounts until ctx is
+// cancelled. With Postgres each run also checks the next batch of chats'
+// participant counts, so every chat is checked once per pass over the table.
+func (a *App) runGuestCleanup(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Guest.CleanupInterval)
+	defer ticker.Stop()
+
+	lastChatID := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := a.uc.user.CleanupExpiredGuests(ctx)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "failed to clean up expired guests", "error", err)
+			} else if deleted > 0 {
+				a.logger.InfoContext(ctx, "deleted expired guests", "count", deleted)
+			}
+			if a.infra.pgChatRepo == nil {
+				continue
+			}
+
+			next, repaired, err := a.infra.pgChatRepo.RepairParticipantCounts(ctx, lastChatID, participantCountBatch)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "failed to check participant counts", "error", err)
+				continue
+			}
+			if repaired > 0 {
+				a.logger.WarnContext(ctx, "corrected drifted participant counts", "count", repaired)
+			}
+			lastChatID = next
+		}
+	}
+}
+
+// runAccountDeletion periodically anonymizes accounts deactivated longer
+// than the deletion grace period until ctx is cancelled.
+func (a *App) runAccountDeletion(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Profile.DeletionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			anonymized, err := a.uc.user.AnonymizeDeactivatedUsers(ctx)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "failed to anonymize deactivated users", "error", err)
+			} else if anonymized > 0 {
+				a.logger.InfoContext(ctx, "anonymized deactivated users", "count", anonymized)
+			}
+		}
+	}
+}
+
+// runRetention periodically purges messages past their retention period
+// until ctx is cancelled. Chats can have a retention period without a
+// global one, so it runs either way.
+func (a *App) runRetention(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Retention.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := a.uc.message.ApplyRetention(ctx)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "failed to apply message retention", "error", err)
+			}
+			if result == nil {
+				continue
+			}
+			if result.DryRun {
+				a.logger.InfoContext(ctx, "message retention dry run", "mode", result.Mode, "expired", result.Messages)
+			} else if result.Messages > 0 {
+				a.logger.InfoContext(ctx, "applied message retention", "mode", result.Mode, "count", result.Messages)
+			}
+		}
+	}
+}
+
+// runUnreadRepair periodically corrects unread counters that drifted from
+// the messages table until ctx is cancelled.
+func (a *App) runUnreadRepair(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Unread.RepairInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			repaired, err := a.infra.pgMessageRepo.RepairUnreadCounters(ctx, a.cfg.Unread.RepairBatchSize)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "failed to repair unread counters", "error", err)
+			}
+			if repaired > 0 {
+				a.logger.InfoContext(ctx, "repaired unread counters", "count", repaired)
+			}
+		}
+	}
+}
+
+// runArchival periodically moves old messages to the file store until ctx
+// is cancelled.
+func (a *App) runArchival(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Archive.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := time.Now().AddDate(0, 0, -a.cfg.Archive.AfterDays)
+			result, err := a.infra.messageArchiver.Archive(ctx, cutoff, a.cfg.Archive.BatchSize)
+			if err != nil {
+				a.logger.ErrorContext(ctx, "failed to archive messages", "error", err)
+			}
+			if result != nil && result.Archives > 0 {
+				a.logger.InfoContext(ctx, "archived messages", "archives", result.Archives, "count", result.Messages)
+			}
+		}
+	}
 }
 
 func (a *App) setupHTTPServer() *http.Server {
 	// base handler/router/server
 	mux := http.NewServeMux()
 
+	// localize errors for clients that don't send a supported Accept-Language
+	httptools.SetUserLocale(a.infra.authPortal.UserLocale)
+
+	// path parameters that also accept public UUIDs
+	publicIDs := map[string]httptools.PublicIDResolver{
+		"user_id":      a.infra.userRepo.GetIDByPublicID,
+		"chat_id":      a.infra.chatRepo.GetIDByPublicID,
+		"message_id":   a.infra.messageRepo.GetIDByPublicID,
+		"workspace_id": a.infra.workspaceRepo.GetIDByPublicID,
+	}
+
 	// register module handlers
-	authHttp.Register(mux, "/auth", a.uc.auth, a.uc.user, a.infra.authPortal)
-	chatHttp.Register(mux, "/chat", a.uc.chat, a.uc.message, a.uc.notification, a.infra.authPortal)
+	authHttp.Register(
+		mux,
+		"/auth",
+		authHttp.Config{
+			ImageCacheMaxAge:    a.cfg.Image.CacheMaxAge,
+			ImageCacheImmutable: a.cfg.Image.CacheImmutable,
+			UploadTimeout:       a.cfg.Server.UploadHandlerTimeout,
+		},
+		a.uc.auth,
+		a.uc.user,
+		a.uc.terms,
+		a.uc.workspace,
+		a.uc.spam,
+		a.uc.contact,
+		a.uc.apiKey,
+		a.uc.audit,
+		a.infra.authPortal,
+		publicIDs,
+	)
+	chatHttp.Register(
+		mux,
+		"/chat",
+		chatHttp.Config{
+			UploadTimeout:     a.cfg.Server.UploadHandlerTimeout,
+			MaxAttachmentSize: a.cfg.Chat.MaxAttachmentSize,
+		},
+		a.uc.chat,
+		a.uc.message,
+		a.uc.notification,
+		a.infra.authPortal,
+		publicIDs,
+	)
+	notificationHttp.Register(mux, "/notifications", a.uc.inbox, a.infra.authPortal)
 
 	// global middlewares for HTTP handlers
-	httpHandler := middleware.Recovery(middleware.Logger(middleware.CORS(mux)))
+	httpHandler := middleware.Chain(
+		mux,
+		middleware.RequestID,
+		middleware.RequestScope,
+		middleware.AccessLog(a.logger),
+		middleware.Recovery(a.logger),
+		middleware.CORS,
+		middleware.Timeout(a.cfg.Server.ReadHandlerTimeout, a.cfg.Server.WriteHandlerTimeout),
+		middleware.BodyLimit(a.cfg.Server.MaxBodySize),
+		middleware.RoutePattern,
+	)
 
-	// Create root mux that routes WebSocket separately (without middleware that breaks Hijacker)
+	// Create root mux that routes WebSocket and event streams separately (without
+	// middleware that breaks Hijacker or cuts streams short)
 	rootMux := http.NewServeMux()
 	rootMux.Handle("GET /chat/ws", a.wsHandler)
+	rootMux.Handle("GET /chat/events/stream", middleware.CORS(http.HandlerFunc(a.wsHandler.ServeStream)))
+	rootMux.Handle("GET /metrics", metrics.Handler())
 	rootMux.Handle("/", httpHandler)
 
 	return &http.Server{
@@ -257,13 +1405,24 @@ func (a *App) setupHTTPServer() *http.Server {
 	}
 }
 
-func (a *App) runServer(srv *http.Server) error {
-	serverErrors := make(chan error, 1)
+func (a *App) runServer(srv, redirect *http.Server) error {
+	serverErrors := make(chan error, 2)
 
 	go func() {
-		log.Printf("Starting server on %s", srv.Addr)
+		if srv.TLSConfig != nil {
+			a.logger.Info("starting https server", "addr", srv.Addr)
+			serverErrors <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		a.logger.Info("starting http server", "addr", srv.Addr)
 		serverErrors <- srv.ListenAndServe()
 	}()
+	if redirect != nil {
+		go func() {
+			a.logger.Info("starting https redirect server", "addr", redirect.Addr)
+			serverErrors <- redirect.ListenAndServe()
+		}()
+	}
 
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -273,11 +1432,24 @@ func (a *App) runServer(srv *http.Server) error {
 		return fmt.Errorf("server error: %w", err)
 
 	case sig := <-shutdown:
-		log.Printf("Received shutdown signal: %v", sig)
+		a.logger.Info("received shutdown signal", "signal", sig.String())
 
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		if redirect != nil {
+			if err := redirect.Shutdown(shutdownCtx); err != nil {
+				a.logger.Error("failed to shutdown https redirect server", "error", err)
+			}
+		}
+
+		// Drain the hub first: srv.Shutdown waits for event streams, which
+		// aren't hijacked, until the hub closes them. Events queued by
+		// requests still in flight are kept for the clients to resume.
+		if err := a.wsHub.Shutdown(shutdownCtx); err != nil {
+			a.logger.Error("failed to drain websocket connections", "error", err)
+		}
+
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			if closeErr := srv.Close(); closeErr != nil {
 				return fmt.Errorf("failed to close server: %w", closeErr)
@@ -285,11 +1457,98 @@ func (a *App) runServer(srv *http.Server) error {
 			return fmt.Errorf("failed to gracefully shutdown server: %w", err)
 		}
 
-		log.Println("Server stopped gracefully")
+		a.logger.Info("http server stopped gracefully")
 		return nil
 	}
 }
 
+// newTLSConfig returns the TLS settings of the HTTP server, or nil if it
+// serves plain HTTP. With autocert, the returned manager obtains
+// certificates from Let's Encrypt and answers its HTTP challenges.
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, *autocert.Manager, error) {
+	if !cfg.Enabled() {
+		return nil, nil, nil
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// TLS 1.3 suites aren't configurable and are all modern; for TLS 1.2
+		// only forward-secret AEAD suites are offered
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+		return tlsCfg, nil, nil
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+		Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		Email:      cfg.AutocertEmail,
+	}
+	tlsCfg.GetCertificate = manager.GetCertificate
+	// Lets Let's Encrypt validate the domains on the HTTPS port as well
+	tlsCfg.NextProtos = append(tlsCfg.NextProtos, acme.ALPNProto)
+
+	return tlsCfg, manager, nil
+}
+
+// redirectToHTTPS redirects requests to the same URL over HTTPS on the port
+// of addr.
+func redirectToHTTPS(addr string) http.Handler {
+	_, port, _ := net.SplitHostPort(addr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// RunDevServer creates the admin account of the dev configuration and runs
+// the HTTP server. The application must have been built by BuildDev, whose
+// data starts out empty.
+func (a *App) RunDevServer() error {
+	req := useruc.CreateSuperUserReq{
+		Email:    a.cfg.Dev.AdminEmail,
+		Username: a.cfg.Dev.AdminUsername,
+		Password: a.cfg.Dev.AdminPassword,
+	}
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid dev admin account: %w", err)
+	}
+
+	if _, err := a.uc.user.CreateSuperUser(context.Background(), req); err != nil {
+		return fmt.Errorf("failed to create dev admin account: %w", err)
+	}
+	a.logger.Info("dev mode: data is kept in memory and lost on exit",
+		"admin_email", req.Email,
+		"files", filepath.Join(a.cfg.Dev.DataDir, "files"),
+	)
+
+	return a.RunHTTPServer()
+}
+
 func (a *App) CreateSuperUser() error {
 	reader := bufio.NewReader(os.Stdin)
 
@@ -349,65 +1608,234 @@ func (a *App) CreateSuperUser() error {
 	return nil
 }
 
+// ExportOptions selects what the export command writes and where.
+type ExportOptions struct {
+	Dataset export.Dataset
+	Since   time.Time
+	Out     string // Local file; defaults to a name derived from the dataset
+	Gzip    bool   // Compress the local file
+	Upload  bool   // Upload gzip'd to the file store instead of writing Out
+}
+
+func (a *App) Export(opts ExportOptions) error {
+	ctx := context.Background()
+	exporter := export.New(a.pool)
+	name := fmt.Sprintf("%s-%s.ndjson", opts.Dataset, time.Now().UTC().Format("20060102T150405Z"))
+
+	if !opts.Upload {
+		out := opts.Out
+		if out == "" {
+			out = name
+			if opts.Gzip {
+				out += ".gz"
+			}
+		}
+
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		n, err := writeExport(ctx, exporter, opts, f, opts.Gzip)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+
+		a.logger.Info("export written", "dataset", opts.Dataset, "rows", n, "file", out)
+		return nil
+	}
+
+	// Staged on disk since the file store needs the size up front and the
+	// export may not fit in memory
+	f, err := os.CreateTemp("", "chatx-export-*.ndjson.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	n, err := writeExport(ctx, exporter, opts, f, true)
+	if err != nil {
+		return err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	path := "exports/" + name + ".gz"
+	if err := a.infra.fileStore.Upload(ctx, path, f, size, "application/gzip"); err != nil {
+		return err
+	}
+
+	a.logger.Info("export uploaded", "dataset", opts.Dataset, "rows", n, "path", path, "bytes", size)
+	return nil
+}
+
+func writeExport(
+	ctx context.Context,
+	exporter *export.Exporter,
+	opts ExportOptions,
+	w io.Writer,
+	compress bool,
+) (int64, error) {
+	if !compress {
+		return exporter.Export(ctx, opts.Dataset, opts.Since, w)
+	}
+
+	gz := gzip.NewWriter(w)
+	n, err := exporter.Export(ctx, opts.Dataset, opts.Since, gz)
+	if err != nil {
+		return n, err
+	}
+	return n, gz.Close()
+}
+
+// notificationSubscription is a topic handled by the notification service.
+type notificationSubscription struct {
+	topic    string
+	groupID  string
+	handleFn events.HandleFunc
+}
+
+func (a *App) notificationSubscriptions(serviceName string) []notificationSubscription {
+	handler := notifications.NewHandler(a.uc.emailNotif)
+
+	// One consumer group per topic, so a rebalance of one does not pause the other
+	return []notificationSubscription{
+		{topic: registrationEmailTopic, groupID: serviceName, handleFn: handler.HandleUserRegistration},
+		{topic: inviteEmailTopic, groupID: serviceName + "-invites", handleFn: handler.HandleUserInvite},
+		{
+			topic:    verificationEmailTopic,
+			groupID:  serviceName + "-verification",
+			handleFn: handler.HandleEmailVerification,
+		},
+		{
+			topic:    joinRequestEmailTopic,
+			groupID:  serviceName + "-join-requests",
+			handleFn: handler.HandleJoinRequest,
+		},
+		{
+			topic:    contactRequestEmailTopic,
+			groupID:  serviceName + "-contact-requests",
+			handleFn: handler.HandleContactRequest,
+		},
+	}
+}
+
+// startInProcessConsumers handles the notification events of the memory
+// broker in this process until ctx is cancelled, in place of the consumer
+// service.
+func (a *App) startInProcessConsumers(ctx context.Context) error {
+	for _, sub := range a.notificationSubscriptions("chatx-notifications") {
+		consumer, err := a.newEventConsumer(sub.topic, sub.groupID, "chatx-api", "1.0.0", sub.handleFn)
+		if err != nil {
+			return fmt.Errorf("failed to create event consumer for %s: %w", sub.topic, err)
+		}
+
+		go func() {
+			if err := consumer.Start(); err != nil {
+				a.logger.Error("in-process event consumer failed", "topic", sub.topic, "error", err)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			if err := consumer.Stop(); err != nil {
+				a.logger.Error("failed to stop in-process event consumer", "topic", sub.topic, "error", err)
+			}
+		}()
+	}
+
+	a.logger.Info("in-process event consumers started")
+	return nil
+}
+
 func (a *App) RunNotificationConsumer() error {
 	const (
 		serviceName    = "chatx-notifications"
 		serviceVersion = "1.0.0"
-		topicName      = "user.registration.email"
 	)
 
-	slog.Info("starting notification consumer service", "version", serviceVersion)
-
-	// Create notification handler
-	handler := notifications.NewHandler(a.uc.emailNotif)
+	a.logger.Info("starting notification consumer service", "version", serviceVersion)
 
-	// Create Kafka consumer
-	consumer, err := kafka.NewConsumer(
-		kafka.ConsumerConfig{
-			Brokers:      a.cfg.Kafka.Brokers,
-			SaslUsername: a.cfg.Kafka.SaslUsername,
-			SaslPassword: a.cfg.Kafka.SaslPassword,
-			GroupID:      serviceName,
-		},
-		topicName,
-		serviceName,
-		serviceVersion,
-		handler.HandleUserRegistration,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create kafka consumer: %w", err)
+	if a.infra.eventBus != nil {
+		return fmt.Errorf("the memory event broker delivers events within the HTTP server, not a separate consumer")
 	}
 
-	slog.Info("kafka consumer initialized",
-		"topic", topicName,
-		"group_id", serviceName,
-	)
+	subscriptions := a.notificationSubscriptions(serviceName)
 
-	// Start consumer in a goroutine
-	consumerErrors := make(chan error, 1)
-	go func() {
-		slog.Info("starting kafka consumer")
-		consumerErrors <- consumer.Start()
-	}()
+	consumers := make([]events.Consumer, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		consumer, err := a.newEventConsumer(sub.topic, sub.groupID, serviceName, serviceVersion, sub.handleFn)
+		if err != nil {
+			return fmt.Errorf("failed to create event consumer for %s: %w", sub.topic, err)
+		}
+
+		a.logger.Info("event consumer initialized",
+			"broker", a.cfg.Events.Broker,
+			"topic", sub.topic,
+			"group_id", sub.groupID,
+		)
+		consumers = append(consumers, consumer)
+	}
+
+	// Start consumers in goroutines
+	consumerErrors := make(chan error, len(consumers))
+	for _, consumer := range consumers {
+		go func() {
+			consumerErrors <- consumer.Start()
+		}()
+	}
+	a.logger.Info("event consumers started")
 
 	// Wait for interrupt signal or consumer error
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
+	var runErr error
 	select {
 	case err := <-consumerErrors:
-		return fmt.Errorf("consumer error: %w", err)
+		runErr = fmt.Errorf("consumer error: %w", err)
 
 	case sig := <-shutdown:
-		slog.Info("received shutdown signal", "signal", sig)
+		a.logger.Info("received shutdown signal", "signal", sig.String())
+	}
 
-		// Stop consumer gracefully
+	// Stop consumers gracefully
+	for _, consumer := range consumers {
 		if err := consumer.Stop(); err != nil {
-			slog.Error("failed to stop consumer", "error", err)
-			return fmt.Errorf("failed to stop consumer: %w", err)
+			a.logger.Error("failed to stop consumer", "error", err)
+			if runErr == nil {
+				runErr = fmt.Errorf("failed to stop consumer: %w", err)
+			}
 		}
+	}
 
-		slog.Info("notification consumer stopped gracefully")
-		return nil
+	if runErr == nil {
+		a.logger.Info("notification consumer stopped gracefully")
+	}
+	return runErr
+}
+
+// instanceID identifies this process among API replicas.
+func instanceID() string {
+	name := hostname()
+	if name == "" {
+		name = "chatx"
+	}
+	return name + "-" + requestid.New()[:8]
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
 	}
+	return name
 }
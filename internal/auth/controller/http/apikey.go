@@ -0,0 +1,55 @@
+package http
+
+import (
+	"chatx-01-backend/internal/auth/usecase/apikeyuc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[apikeyuc.CreateAPIKeyReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.apiKeyUsecase.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[apikeyuc.ListAPIKeysReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.apiKeyUsecase.ListAPIKeys(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[apikeyuc.RevokeAPIKeyReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.apiKeyUsecase.RevokeAPIKey(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"chatx-01-backend/internal/auth/usecase/audituc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) getAuditEvents(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[audituc.GetAuditEventsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.auditUsecase.GetAuditEvents(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
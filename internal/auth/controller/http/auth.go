@@ -10,13 +10,13 @@ import (
 func (c *ctrl) login(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[authuc.LoginReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.authUsecase.Login(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -26,7 +26,7 @@ func (c *ctrl) login(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) logout(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[authuc.LogoutReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -41,9 +41,43 @@ func (c *ctrl) logout(w http.ResponseWriter, r *http.Request) {
 
 	err = c.authUsecase.Logout(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	httptools.WriteResponse(http.StatusNoContent, w, nil)
 }
+
+func (c *ctrl) refresh(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[authuc.RefreshReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.authUsecase.Refresh(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getJWKS(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[authuc.GetJWKSReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.authUsecase.GetJWKS(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	// Keys only change on a deploy; let verifiers cache them briefly
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
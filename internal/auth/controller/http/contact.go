@@ -0,0 +1,103 @@
+package http
+
+import (
+	"chatx-01-backend/internal/auth/usecase/contactuc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) sendContactRequest(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[contactuc.SendContactRequestReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.contactUsecase.SendContactRequest(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) acceptContactRequest(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[contactuc.AcceptContactRequestReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.contactUsecase.AcceptContactRequest(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) declineContactRequest(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[contactuc.DeclineContactRequestReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.contactUsecase.DeclineContactRequest(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) getContactRequests(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[contactuc.GetContactRequestsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.contactUsecase.GetContactRequests(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getContacts(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[contactuc.GetContactsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.contactUsecase.GetContacts(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) removeContact(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[contactuc.RemoveContactReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.contactUsecase.RemoveContact(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
@@ -1,35 +1,76 @@
 package http
 
 import (
+	"chatx-01-backend/internal/auth/usecase/apikeyuc"
+	"chatx-01-backend/internal/auth/usecase/audituc"
 	"chatx-01-backend/internal/auth/usecase/authuc"
+	"chatx-01-backend/internal/auth/usecase/contactuc"
+	"chatx-01-backend/internal/auth/usecase/spamuc"
+	"chatx-01-backend/internal/auth/usecase/termsuc"
 	"chatx-01-backend/internal/auth/usecase/useruc"
+	"chatx-01-backend/internal/auth/usecase/workspaceuc"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/middleware"
 	"net/http"
+	"time"
 )
 
+// Config holds HTTP-level settings for the auth module handlers.
+type Config struct {
+	ImageCacheMaxAge    time.Duration
+	ImageCacheImmutable bool
+	UploadTimeout       time.Duration
+}
+
 type ctrl struct {
 	mux    *http.ServeMux
 	prefix string
+	cfg    Config
 
-	authUsecase authuc.UseCase
-	userUsecase useruc.UseCase
+	authUsecase      authuc.UseCase
+	userUsecase      useruc.UseCase
+	termsUsecase     termsuc.UseCase
+	workspaceUsecase workspaceuc.UseCase
+	spamUsecase      spamuc.UseCase
+	contactUsecase   contactuc.UseCase
+	apiKeyUsecase    apikeyuc.UseCase
+	auditUsecase     audituc.UseCase
 
 	authPr auth.Portal
+
+	publicIDs map[string]httptools.PublicIDResolver
 }
 
 func Register(
 	mux *http.ServeMux,
 	prefix string,
+	cfg Config,
 	authUsecase authuc.UseCase,
 	userUsecase useruc.UseCase,
+	termsUsecase termsuc.UseCase,
+	workspaceUsecase workspaceuc.UseCase,
+	spamUsecase spamuc.UseCase,
+	contactUsecase contactuc.UseCase,
+	apiKeyUsecase apikeyuc.UseCase,
+	auditUsecase audituc.UseCase,
 	authPr auth.Portal,
+	publicIDs map[string]httptools.PublicIDResolver,
 ) {
 	c := &ctrl{
-		mux:         mux,
-		prefix:      prefix,
-		authUsecase: authUsecase,
-		userUsecase: userUsecase,
-		authPr:      authPr,
+		mux:              mux,
+		prefix:           prefix,
+		cfg:              cfg,
+		authUsecase:      authUsecase,
+		userUsecase:      userUsecase,
+		termsUsecase:     termsUsecase,
+		workspaceUsecase: workspaceUsecase,
+		spamUsecase:      spamUsecase,
+		contactUsecase:   contactUsecase,
+		apiKeyUsecase:    apiKeyUsecase,
+		auditUsecase:     auditUsecase,
+		authPr:           authPr,
+		publicIDs:        publicIDs,
 	}
 
 	c.registerHandlers()
@@ -39,19 +80,90 @@ func Register(
 func (c *ctrl) registerHandlers() {
 	// auth endpoints
 	c.register(http.MethodPost, "/login", http.HandlerFunc(c.login))
-	c.register(http.MethodPost, "/logout", http.HandlerFunc(c.logout), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/logout", http.HandlerFunc(c.logout), c.authPr.RequireAuthPendingTerms())
+	c.register(http.MethodPost, "/refresh", http.HandlerFunc(c.refresh))
+	c.register(http.MethodPost, "/register", http.HandlerFunc(c.registerUser))
+	c.register(http.MethodPost, "/verify-email", http.HandlerFunc(c.verifyEmail))
+	c.register(http.MethodPost, "/verify-email/resend", http.HandlerFunc(c.resendVerification))
+	c.register(http.MethodPost, "/confirm-email", http.HandlerFunc(c.confirmEmailChange))
+	c.register(http.MethodPost, "/guests", http.HandlerFunc(c.joinAsGuest))
+	c.register(http.MethodGet, "/.well-known/jwks.json", http.HandlerFunc(c.getJWKS))
+
+	// terms endpoints
+	c.register(http.MethodGet, "/terms", http.HandlerFunc(c.getTerms), c.authPr.RequireAuthPendingTerms())
+	c.register(http.MethodPost, "/terms/accept", http.HandlerFunc(c.acceptTerms), c.authPr.RequireAuthPendingTerms())
+
+	// workspace endpoints
+	c.register(http.MethodPost, "/workspaces", http.HandlerFunc(c.createWorkspace), c.authPr.RequireMember())
+	c.register(http.MethodGet, "/workspaces", http.HandlerFunc(c.getMyWorkspaces), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/workspaces/{workspace_id}/members", http.HandlerFunc(c.addWorkspaceMember), c.authPr.RequireMember())
+	c.register(http.MethodDelete, "/workspaces/{workspace_id}/members/{user_id}", http.HandlerFunc(c.removeWorkspaceMember), c.authPr.RequireMember())
+	c.register(http.MethodPost, "/workspaces/{workspace_id}/switch", http.HandlerFunc(c.switchWorkspace), c.authPr.RequireAuth())
 
 	// user endpoints
-	c.register(http.MethodPost, "/users", http.HandlerFunc(c.createUser), c.authPr.RequireAdmin())
-	c.register(http.MethodGet, "/users", http.HandlerFunc(c.getUsersList), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/users", http.HandlerFunc(c.createUser), c.authPr.RequireWorkspaceAdmin())
+	c.register(http.MethodGet, "/users", http.HandlerFunc(c.getUsersList), c.authPr.RequireMember())
 	c.register(http.MethodGet, "/users/{user_id}", http.HandlerFunc(c.getUser), c.authPr.RequireAuth())
 	c.register(http.MethodDelete, "/users/{user_id}", http.HandlerFunc(c.deleteUser), c.authPr.RequireAdmin())
-	c.register(http.MethodGet, "/users/me", http.HandlerFunc(c.getMe), c.authPr.RequireAuth())
-	c.register(http.MethodPut, "/users/me/password", http.HandlerFunc(c.changePassword), c.authPr.RequireAuth())
+	c.register(http.MethodDelete, "/users/{user_id}/purge", http.HandlerFunc(c.purgeUser), c.authPr.RequireAdmin())
+	c.register(http.MethodPut, "/users/{user_id}/role", http.HandlerFunc(c.setUserRole), c.authPr.RequireAdmin())
+	c.register(http.MethodGet, "/users/me", http.HandlerFunc(c.getMe), c.authPr.RequireAuthPendingTerms())
+	c.register(http.MethodPut, "/users/me", http.HandlerFunc(c.updateProfile), c.authPr.RequireAuth())
+	c.register(http.MethodPut, "/users/me/password", http.HandlerFunc(c.changePassword), c.authPr.RequireMember())
+	c.register(http.MethodPut, "/users/me/username", http.HandlerFunc(c.changeUsername), c.authPr.RequireMember())
+	c.register(http.MethodPut, "/users/me/email", http.HandlerFunc(c.requestEmailChange), c.authPr.RequireMember())
+	c.register(http.MethodPost, "/users/me/deactivate", http.HandlerFunc(c.deactivateAccount), c.authPr.RequireMember())
 	c.register(http.MethodPut, "/users/me/image", http.HandlerFunc(c.changeImage), c.authPr.RequireAuth())
+	c.register(http.MethodPut, "/users/me/locale", http.HandlerFunc(c.changeLocale), c.authPr.RequireAuthPendingTerms())
+	c.register(http.MethodPut, "/users/me/privacy", http.HandlerFunc(c.changePrivacy), c.authPr.RequireAuth())
+	c.register(http.MethodGet, "/users/me/blocks", http.HandlerFunc(c.getBlockedUsers), c.authPr.RequireAuth())
+	c.register(http.MethodPut, "/users/me/blocks/{user_id}", http.HandlerFunc(c.blockUser), c.authPr.RequireAuth())
+	c.register(http.MethodDelete, "/users/me/blocks/{user_id}", http.HandlerFunc(c.unblockUser), c.authPr.RequireAuth())
+
+	// contact endpoints
+	c.register(http.MethodGet, "/users/me/contacts", http.HandlerFunc(c.getContacts), c.authPr.RequireMember())
+	c.register(http.MethodDelete, "/users/me/contacts/{user_id}", http.HandlerFunc(c.removeContact), c.authPr.RequireMember())
+	c.register(http.MethodGet, "/users/me/contact-requests", http.HandlerFunc(c.getContactRequests), c.authPr.RequireMember())
+	c.register(http.MethodPost, "/users/me/contact-requests/{user_id}", http.HandlerFunc(c.sendContactRequest), c.authPr.RequireMember())
+	c.register(
+		http.MethodPost,
+		"/users/me/contact-requests/{user_id}/accept",
+		http.HandlerFunc(c.acceptContactRequest),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodPost,
+		"/users/me/contact-requests/{user_id}/decline",
+		http.HandlerFunc(c.declineContactRequest),
+		c.authPr.RequireMember(),
+	)
+
+	// api key endpoints
+	c.register(http.MethodPost, "/api-keys", http.HandlerFunc(c.createAPIKey), c.authPr.RequireMember())
+	c.register(http.MethodGet, "/api-keys", http.HandlerFunc(c.listAPIKeys), c.authPr.RequireMember())
+	c.register(http.MethodDelete, "/api-keys/{key_id}", http.HandlerFunc(c.revokeAPIKey), c.authPr.RequireMember())
+
+	// spam review endpoints
+	c.register(http.MethodGet, "/admin/spam-flags", http.HandlerFunc(c.getSpamFlags), c.authPr.RequireAdmin())
+	c.register(
+		http.MethodPost,
+		"/admin/spam-flags/{flag_id}/resolve",
+		http.HandlerFunc(c.resolveSpamFlag),
+		c.authPr.RequireAdmin(),
+	)
+
+	// audit log endpoints
+	c.register(http.MethodGet, "/admin/audit", http.HandlerFunc(c.getAuditEvents), c.authPr.RequireAdmin())
 
 	// image endpoints
-	c.register(http.MethodPost, "/images/upload", http.HandlerFunc(c.uploadImage), c.authPr.RequireAuth())
+	c.register(
+		http.MethodPost,
+		"/images/upload",
+		http.HandlerFunc(c.uploadImage),
+		middleware.TimeoutOverride(c.cfg.UploadTimeout),
+		middleware.BodyLimitOverride(maxImageSize),
+		c.authPr.RequireAuth(),
+	)
 	c.register(http.MethodGet, "/images/{image_path...}", http.HandlerFunc(c.downloadImage))
 }
 
@@ -61,6 +173,9 @@ func (c *ctrl) register(
 	handler http.Handler,
 	middlewares ...func(http.Handler) http.Handler,
 ) {
+	// Path IDs may be public UUIDs; resolve them right before binding.
+	handler = httptools.ResolvePublicIDs(c.publicIDs)(handler)
+
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		handler = middlewares[i](handler)
 	}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"chatx-01-backend/internal/auth/usecase/spamuc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) getSpamFlags(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[spamuc.GetSpamFlagsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.spamUsecase.GetSpamFlags(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) resolveSpamFlag(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[spamuc.ResolveSpamFlagReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.spamUsecase.ResolveSpamFlag(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"chatx-01-backend/internal/auth/usecase/termsuc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) getTerms(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[termsuc.GetTermsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.termsUsecase.GetTerms(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) acceptTerms(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[termsuc.AcceptTermsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.termsUsecase.AcceptTerms(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
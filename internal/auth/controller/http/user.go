@@ -3,21 +3,42 @@ package http
 import (
 	"chatx-01-backend/internal/auth/usecase/useruc"
 	"chatx-01-backend/pkg/httptools"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 )
 
+const maxImageSize = 10 << 20 // 10 MB
+
 func (c *ctrl) getMe(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.GetMeReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.GetMe(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) updateProfile(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.UpdateProfileReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.userUsecase.UpdateProfile(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -27,13 +48,61 @@ func (c *ctrl) getMe(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) changePassword(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.ChangePasswordReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	err = c.userUsecase.ChangePassword(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) changeUsername(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.ChangeUsernameReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.userUsecase.ChangeUsername(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) requestEmailChange(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.RequestEmailChangeReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.RequestEmailChange(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusAccepted, w, nil)
+}
+
+func (c *ctrl) deactivateAccount(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.DeactivateAccountReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.DeactivateAccount(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -43,13 +112,93 @@ func (c *ctrl) changePassword(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) changeImage(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.ChangeImageReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.ChangeImage(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) changeLocale(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.ChangeLocaleReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.ChangeLocale(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) changePrivacy(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.ChangePrivacyReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.ChangePrivacy(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) blockUser(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.BlockUserReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.BlockUser(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) unblockUser(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.UnblockUserReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.UnblockUser(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) getBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.GetBlockedUsersReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.userUsecase.GetBlockedUsers(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -59,13 +208,13 @@ func (c *ctrl) changeImage(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) createUser(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.CreateUserReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.CreateUser(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -75,13 +224,45 @@ func (c *ctrl) createUser(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) deleteUser(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.DeleteUserReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	err = c.userUsecase.DeleteUser(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) purgeUser(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.PurgeUserReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.PurgeUser(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) setUserRole(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.SetUserRoleReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.SetUserRole(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -91,13 +272,13 @@ func (c *ctrl) deleteUser(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) getUser(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.GetUserReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.GetUser(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -107,13 +288,13 @@ func (c *ctrl) getUser(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) getUsersList(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[useruc.GetUsersListReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.GetUsersList(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -121,24 +302,21 @@ func (c *ctrl) getUsersList(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *ctrl) uploadImage(w http.ResponseWriter, r *http.Request) {
-	const maxFileSize = 10 << 20 // 10 MB
-
-	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
-	if err := r.ParseMultipartForm(maxFileSize); err != nil {
-		httptools.HandleError(w, err)
+	if err := r.ParseMultipartForm(maxImageSize); err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 	defer file.Close()
 
 	fileData, err := io.ReadAll(file)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -155,13 +333,13 @@ func (c *ctrl) uploadImage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := req.Validate(); err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.UploadImage(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -176,13 +354,24 @@ func (c *ctrl) downloadImage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := req.Validate(); err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.userUsecase.DownloadImage(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(resp.File)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", c.imageCacheControl(resp.Immutable))
+
+	if match := r.Header.Get("If-None-Match"); match != "" && match == etag {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -191,3 +380,98 @@ func (c *ctrl) downloadImage(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(resp.File)
 }
+
+// imageCacheControl builds the Cache-Control value for a served image.
+// Content-addressed images never change, so they can be cached for the
+// configured max-age; legacy paths must be revalidated via ETag.
+func (c *ctrl) imageCacheControl(immutable bool) string {
+	if !immutable {
+		return "public, no-cache"
+	}
+
+	value := fmt.Sprintf("public, max-age=%d", int(c.cfg.ImageCacheMaxAge.Seconds()))
+	if c.cfg.ImageCacheImmutable {
+		value += ", immutable"
+	}
+	return value
+}
+
+func (c *ctrl) registerUser(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.RegisterReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.userUsecase.Register(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.VerifyEmailReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.VerifyEmail(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) resendVerification(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.ResendVerificationReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.ResendVerification(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusAccepted, w, nil)
+}
+
+func (c *ctrl) confirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.ConfirmEmailChangeReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.userUsecase.ConfirmEmailChange(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) joinAsGuest(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[useruc.JoinAsGuestReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.userUsecase.JoinAsGuest(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"chatx-01-backend/internal/auth/usecase/workspaceuc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) createWorkspace(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[workspaceuc.CreateWorkspaceReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.workspaceUsecase.CreateWorkspace(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) getMyWorkspaces(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[workspaceuc.GetMyWorkspacesReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.workspaceUsecase.GetMyWorkspaces(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) addWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[workspaceuc.AddMemberReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.workspaceUsecase.AddMember(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) removeWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[workspaceuc.RemoveMemberReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.workspaceUsecase.RemoveMember(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) switchWorkspace(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[workspaceuc.SwitchWorkspaceReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.workspaceUsecase.SwitchWorkspace(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
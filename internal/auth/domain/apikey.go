@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKeyScope limits what an API key may do. Keys for the chat module and for
+// the rest of the API are scoped separately, each to reading or writing.
+type APIKeyScope string
+
+const (
+	APIKeyScopeChatRead     APIKeyScope = "chat:read"
+	APIKeyScopeChatWrite    APIKeyScope = "chat:write"
+	APIKeyScopeAccountRead  APIKeyScope = "account:read"
+	APIKeyScopeAccountWrite APIKeyScope = "account:write"
+)
+
+func (s APIKeyScope) IsValid() bool {
+	return s == APIKeyScopeChatRead ||
+		s == APIKeyScopeChatWrite ||
+		s == APIKeyScopeAccountRead ||
+		s == APIKeyScopeAccountWrite
+}
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognize.
+const APIKeyPrefix = "chx_"
+
+// apiKeyHintLen is how many characters of a key are kept to tell keys apart.
+const apiKeyHintLen = len(APIKeyPrefix) + 6
+
+// MaxAPIKeysPerUser is how many unrevoked API keys a user may have.
+const MaxAPIKeysPerUser = 10
+
+// MaxAPIKeyNameLength is the maximum length of an API key name.
+const MaxAPIKeyNameLength = 64
+
+// APIKey lets a bot or integration act as its owner in one workspace within
+// its scopes. Only a hash of the key is stored.
+type APIKey struct {
+	ID          int
+	UserID      int
+	WorkspaceID int // Workspace the key acts in, like the workspace of a token
+	Name        string
+	Hint        string // First characters of the key
+	KeyHash     string // Hex SHA-256 of the key
+	Scopes      []APIKeyScope
+	CreatedAt   time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+// NewAPIKey generates a random API key, its hint and its hash.
+func NewAPIKey() (key, hint, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyHintLen], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsRevoked reports whether the key was revoked.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// HasScope reports whether the key was granted scope. Write scopes include
+// reading.
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	if slices.Contains(k.Scopes, scope) {
+		return true
+	}
+	switch scope {
+	case APIKeyScopeChatRead:
+		return slices.Contains(k.Scopes, APIKeyScopeChatWrite)
+	case APIKeyScopeAccountRead:
+		return slices.Contains(k.Scopes, APIKeyScopeAccountWrite)
+	}
+	return false
+}
+
+// RequiredAPIKeyScope returns the scope an API key needs for a request: the
+// chat scopes for the chat module, the account scopes otherwise, reading for
+// safe methods and writing for the rest.
+func RequiredAPIKeyScope(method, path string) APIKeyScope {
+	read := method == http.MethodGet || method == http.MethodHead
+	if path == "/chat" || strings.HasPrefix(path, "/chat/") {
+		if read {
+			return APIKeyScopeChatRead
+		}
+		return APIKeyScopeChatWrite
+	}
+	if read {
+		return APIKeyScopeAccountRead
+	}
+	return APIKeyScopeAccountWrite
+}
+
+// APIKeyRepository defines the interface for API key data access.
+type APIKeyRepository interface {
+	// Create stores a new API key and sets its ID.
+	Create(ctx context.Context, key *APIKey) error
+
+	// GetByHash retrieves an API key, revoked or not, by the hash of the key.
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+
+	// ListByUser returns the unrevoked API keys of a user, newest first.
+	ListByUser(ctx context.Context, userID int) ([]*APIKey, error)
+
+	// CountByUser returns how many unrevoked API keys a user has.
+	CountByUser(ctx context.Context, userID int) (int, error)
+
+	// Revoke marks an unrevoked API key of a user as revoked.
+	// Returns errs.ErrNotFound if the user has no such key.
+	Revoke(ctx context.Context, userID, id int, revokedAt time.Time) error
+
+	// Touch records that an API key was used.
+	Touch(ctx context.Context, id int, usedAt time.Time) error
+}
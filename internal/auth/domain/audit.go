@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction is a security-sensitive action recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditLogin                  AuditAction = "login"
+	AuditLogout                 AuditAction = "logout"
+	AuditPasswordChange         AuditAction = "password_change"
+	AuditUserDelete             AuditAction = "user_delete"
+	AuditUserPurge              AuditAction = "user_purge"
+	AuditRoleChange             AuditAction = "role_change"
+	AuditGroupDelete            AuditAction = "group_delete"
+	AuditGroupOwnershipTransfer AuditAction = "group_ownership_transfer"
+	AuditGroupParticipantRemove AuditAction = "group_participant_remove"
+	AuditAdminAccessDenied      AuditAction = "admin_access_denied"
+	AuditMessageDelete          AuditAction = "message_delete"
+	AuditAPIKeyCreate           AuditAction = "api_key_create"
+	AuditAPIKeyRevoke           AuditAction = "api_key_revoke"
+	AuditAccountDeactivate      AuditAction = "account_deactivate"
+	AuditUsernameChange         AuditAction = "username_change"
+	AuditEmailChangeRequest     AuditAction = "email_change_request"
+	AuditEmailChange            AuditAction = "email_change"
+)
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditLogin, AuditLogout, AuditPasswordChange, AuditUserDelete, AuditUserPurge, AuditRoleChange,
+		AuditGroupDelete, AuditGroupOwnershipTransfer, AuditGroupParticipantRemove, AuditAdminAccessDenied,
+		AuditMessageDelete, AuditAPIKeyCreate, AuditAPIKeyRevoke, AuditAccountDeactivate, AuditUsernameChange,
+		AuditEmailChangeRequest, AuditEmailChange:
+		return true
+	}
+	return false
+}
+
+// AuditEvent records who did a security-sensitive action, to whom and from
+// where. Events outlive the users they mention.
+type AuditEvent struct {
+	ID           int
+	Action       AuditAction
+	ActorID      int
+	TargetUserID *int
+	ChatID       *int
+	WorkspaceID  *int
+	IP           string
+	UserAgent    string
+	Details      string // Extra context, e.g. the old and new role
+	CreatedAt    time.Time
+}
+
+// AuditFilter selects audit events. Zero fields match everything.
+type AuditFilter struct {
+	Action       AuditAction
+	ActorID      int
+	TargetUserID int
+	ChatID       int
+	From         *time.Time // Inclusive
+	To           *time.Time // Exclusive
+}
+
+// AuditRepository defines the interface for audit log data access.
+type AuditRepository interface {
+	// Create stores an audit event and sets its ID.
+	Create(ctx context.Context, event *AuditEvent) error
+
+	// ListWithCount returns a page of the events matching the filter, newest
+	// first, and the total number of them.
+	ListWithCount(ctx context.Context, filter AuditFilter, offset, limit int) ([]AuditEvent, int, error)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserBlock records that a user blocked another. Blocked pairs can't start
+// direct chats or message each other there, and don't see each other's
+// presence or typing, whichever of them blocked the other.
+type UserBlock struct {
+	BlockerID int
+	BlockedID int
+	CreatedAt time.Time
+}
+
+// BlockRepository defines the interface for user block data access.
+type BlockRepository interface {
+	// Block stores a block.
+	// Returns errs.ErrAlreadyExists if the user already blocked the other.
+	Block(ctx context.Context, block *UserBlock) error
+
+	// Unblock deletes the block of a user by another.
+	// Returns errs.ErrNotFound if there is no such block.
+	Unblock(ctx context.Context, blockerID, blockedID int) error
+
+	// ListByBlocker returns the blocks made by a user, newest first.
+	ListByBlocker(ctx context.Context, blockerID int) ([]UserBlock, error)
+
+	// IsBlocked reports whether either user blocked the other.
+	IsBlocked(ctx context.Context, userID1, userID2 int) (bool, error)
+
+	// GetBlockedUserIDs returns the IDs of the users a user blocked or was
+	// blocked by.
+	GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error)
+}
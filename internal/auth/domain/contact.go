@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ContactStatus string
+
+const (
+	// ContactStatusPending is a request the addressee hasn't answered yet.
+	ContactStatusPending ContactStatus = "pending"
+	// ContactStatusAccepted links two users who are each other's contacts.
+	ContactStatusAccepted ContactStatus = "accepted"
+)
+
+// Contact links two users who agreed to be contacts, or records a pending
+// request of one to the other. Two users have at most one of them.
+type Contact struct {
+	RequesterID int
+	AddresseeID int
+	Status      ContactStatus
+	CreatedAt   time.Time
+	AcceptedAt  *time.Time
+}
+
+// OtherID returns the ID of the user on the other side of the contact from
+// userID.
+func (c *Contact) OtherID(userID int) int {
+	if c.RequesterID == userID {
+		return c.AddresseeID
+	}
+	return c.RequesterID
+}
+
+// ContactRepository defines the interface for contact data access.
+type ContactRepository interface {
+	// Create stores a pending contact request.
+	// Returns errs.ErrAlreadyExists if the users already have a request or
+	// are contacts, whoever sent it.
+	Create(ctx context.Context, contact *Contact) error
+
+	// Get returns the request or contact between two users, whoever sent it.
+	// Returns errs.ErrNotFound if there is none.
+	Get(ctx context.Context, userID1, userID2 int) (*Contact, error)
+
+	// Accept marks the pending request of a user to another accepted.
+	// Returns errs.ErrNotFound if there is no such pending request.
+	Accept(ctx context.Context, requesterID, addresseeID int, acceptedAt time.Time) error
+
+	// Delete deletes the request or contact between two users.
+	// Returns errs.ErrNotFound if there is none.
+	Delete(ctx context.Context, userID1, userID2 int) error
+
+	// ListContacts returns the accepted contacts of a user, most recently
+	// accepted first.
+	ListContacts(ctx context.Context, userID int) ([]Contact, error)
+
+	// ListIncoming returns the pending requests sent to a user, newest first.
+	ListIncoming(ctx context.Context, userID int) ([]Contact, error)
+}
@@ -1,9 +1,30 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"chatx-01-backend/pkg/errs"
+)
 
 // Domain-specific errors for auth module.
 var (
-	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrIncorrectPassword  = errors.New("incorrect password")
+	ErrInvalidCredentials  = errs.NewUnauthorizedError("invalid_credentials", "invalid username, email or password")
+	ErrInvalidRefreshToken = errs.NewUnauthorizedError("invalid_refresh_token", "refresh token is invalid or expired")
+	ErrIncorrectPassword   = errors.New("incorrect password")
+	ErrUsernameTaken       = errors.New("username already exists")
+
+	ErrEmailNotVerified     = errs.NewForbiddenError("email_not_verified", "email address is not verified")
+	ErrAccountDeactivated   = errs.NewForbiddenError("account_deactivated", "account is deactivated")
+	ErrInviteRequired       = errs.NewForbiddenError("invite_required", "registration requires an invite")
+	ErrRegistrationDisabled = errs.NewForbiddenError("registration_disabled", "registration is disabled")
+	ErrDisposableEmail      = errs.NewForbiddenError("disposable_email", "disposable email addresses are not allowed")
+	ErrTermsNotAccepted     = errs.NewForbiddenError("terms_not_accepted", "the current terms of service must be accepted")
+	ErrNotWorkspaceMember   = errs.NewForbiddenError("not_workspace_member", "user is not a member of the workspace")
+	ErrNotWorkspaceAdmin    = errs.NewForbiddenError("not_workspace_admin", "only workspace admins can manage members")
+	ErrAdminIPNotAllowed    = errs.NewForbiddenError("admin_ip_not_allowed", "admin access is not allowed from this address")
+	ErrAdminOTPInvalid      = errs.NewForbiddenError("admin_otp_invalid", "a valid admin one-time code is required")
+	ErrUserBlocked          = errs.NewForbiddenError("user_blocked", "you can't add this user as a contact")
+	ErrCannotManageRoles    = errs.NewForbiddenError("cannot_manage_roles", "you can't assign roles")
+	ErrAPIKeyNotAllowed     = errs.NewForbiddenError("api_key_not_allowed", "this action can't be done with an api key")
+	ErrAPIKeyLimit          = errs.NewForbiddenError("api_key_limit", "api key limit reached, revoke a key first")
 )
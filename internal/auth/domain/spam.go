@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SpamReason is the signal a spam flag was raised for.
+type SpamReason string
+
+const (
+	// SpamReasonMessageRate means the user sent too many messages in a short time.
+	SpamReasonMessageRate SpamReason = "message_rate"
+	// SpamReasonDuplicateContent means the user sent the same message to many chats.
+	SpamReasonDuplicateContent SpamReason = "duplicate_content"
+	// SpamReasonDisposableEmail means the user signed up with a disposable email address.
+	SpamReasonDisposableEmail SpamReason = "disposable_email"
+)
+
+func (r SpamReason) IsValid() bool {
+	return r == SpamReasonMessageRate || r == SpamReasonDuplicateContent || r == SpamReasonDisposableEmail
+}
+
+// SpamAction is what happens to a flagged user until an admin resolves the flag.
+type SpamAction string
+
+const (
+	// SpamActionFlag only records the flag for review.
+	SpamActionFlag SpamAction = "flag"
+	// SpamActionShadow also withholds the user's messages from other users
+	// without telling them.
+	SpamActionShadow SpamAction = "shadow"
+)
+
+func (a SpamAction) IsValid() bool {
+	return a == SpamActionFlag || a == SpamActionShadow
+}
+
+// SpamFlagStatus selects flags by whether they were resolved.
+type SpamFlagStatus string
+
+const (
+	SpamFlagOpen     SpamFlagStatus = "open"
+	SpamFlagResolved SpamFlagStatus = "resolved"
+	SpamFlagAll      SpamFlagStatus = "all"
+)
+
+func (s SpamFlagStatus) IsValid() bool {
+	return s == SpamFlagOpen || s == SpamFlagResolved || s == SpamFlagAll
+}
+
+// SpamFlag records a spam signal about a user for admin review.
+type SpamFlag struct {
+	ID         int
+	UserID     int
+	Reason     SpamReason
+	Action     SpamAction
+	Details    string // What triggered the flag, without message content
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+	ResolvedBy *int
+}
+
+// IsOpen reports whether the flag still awaits review.
+func (f *SpamFlag) IsOpen() bool {
+	return f.ResolvedAt == nil
+}
+
+// SpamRepository defines the interface for spam flag data access.
+type SpamRepository interface {
+	// Flag stores a flag and sets its ID, unless the user already has an open
+	// flag for the reason. Returns whether the flag was stored.
+	Flag(ctx context.Context, flag *SpamFlag) (bool, error)
+
+	// ListWithCount returns a page of flags with the status, newest first,
+	// and the total number of them.
+	ListWithCount(ctx context.Context, status SpamFlagStatus, offset, limit int) ([]SpamFlag, int, error)
+
+	// Resolve marks an open flag as reviewed.
+	// Returns errs.ErrNotFound if there's no open flag with the ID.
+	Resolve(ctx context.Context, id, resolverID int, resolvedAt time.Time) (*SpamFlag, error)
+
+	// IsShadowLimited reports whether the user has an open shadow flag.
+	IsShadowLimited(ctx context.Context, userID int) (bool, error)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TermsAcceptance records that a user accepted a version of the terms of
+// service and privacy policy.
+type TermsAcceptance struct {
+	UserID     int
+	Version    string
+	AcceptedAt time.Time
+}
+
+// TermsRepository defines the interface for terms acceptance data access.
+type TermsRepository interface {
+	// Accept records the acceptance. Accepting a version again keeps the
+	// original time.
+	Accept(ctx context.Context, acceptance *TermsAcceptance) error
+
+	// Get retrieves the user's acceptance of a version.
+	// Returns errs.ErrNotFound if the user hasn't accepted it.
+	Get(ctx context.Context, userID int, version string) (*TermsAcceptance, error)
+}
@@ -8,54 +8,137 @@ import (
 type UserRole string
 
 const (
-	RoleAdmin UserRole = "admin"
-	RoleUser  UserRole = "user"
+	RoleAdmin     UserRole = "admin"
+	RoleModerator UserRole = "moderator" // Moderates messages and groups, see auth.Permission
+	RoleUser      UserRole = "user"
+	RoleGuest     UserRole = "guest" // Temporary account limited to the chat it joined
 )
 
 func (r UserRole) IsValid() bool {
-	return r == RoleAdmin || r == RoleUser
+	return r == RoleAdmin || r == RoleModerator || r == RoleUser || r == RoleGuest
 }
 
 func (r UserRole) String() string {
 	return string(r)
 }
 
+// DeletedUsername is shown in place of the name of a deleted user.
+const DeletedUsername = "Deleted User"
+
+// Profile field limits, in characters.
+const (
+	MaxDisplayNameLength = 64
+	MaxBioLength         = 500
+)
+
 type User struct {
-	ID           int
-	Email        string
-	Username     string
-	PasswordHash string
-	Role         UserRole
-	ImagePath    *string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID              int
+	PublicID        string // UUID exposed in the API instead of the sequential ID
+	Email           string
+	Username        string
+	PasswordHash    string
+	Role            UserRole
+	ImagePath       *string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
+	ExpiresAt       *time.Time // Set for guests, who are deleted once it passes
+	EmailVerifiedAt *time.Time
+	Locale          string // Preferred error message language; empty if not set
+	HideLastSeen    bool   // Hide when the user was last online from other users
+	DisplayName     string // Name shown instead of the username; empty if not set
+	Bio             string
+	Phone           string // E.164 format; empty if not set
+	// UsernameChangedAt is when the user last changed their username; nil
+	// if they never did
+	UsernameChangedAt *time.Time
+	// DeactivatedAt is when the user deactivated their account; they are
+	// anonymized once the deletion grace period passes
+	DeactivatedAt *time.Time
+}
+
+// IsDeleted reports whether the user account was deleted.
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// IsDeactivated reports whether the user deactivated their account.
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// IsEmailVerified reports whether the user confirmed their email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // UserRepository defines the interface for user data access.
 type UserRepository interface {
-	// Create creates a new user and sets its ID.
+	// Create creates a new user and sets its ID. Returns ErrUsernameTaken if
+	// another active user has the username, or errs.ErrAlreadyExists if the
+	// email is taken; both are compared case-insensitively.
 	Create(ctx context.Context, user *User) error
 
-	// GetByID retrieves a user by their ID.
+	// GetByID retrieves a user by their ID, including deleted users.
 	GetByID(ctx context.Context, id int) (*User, error)
 
-	// GetByEmail retrieves a user by their email address.
+	// GetByIDs retrieves the users with the given IDs, including deleted
+	// users, in no particular order. Missing IDs are skipped.
+	GetByIDs(ctx context.Context, ids []int) ([]*User, error)
+
+	// GetIDByPublicID returns the internal ID of the user with the given public ID.
+	GetIDByPublicID(ctx context.Context, publicID string) (int, error)
+
+	// GetByEmail retrieves an active user by their email address, ignoring case.
 	GetByEmail(ctx context.Context, email string) (*User, error)
 
-	// GetByUsername retrieves a user by their username.
+	// GetByUsername retrieves an active user by their username, ignoring case.
 	GetByUsername(ctx context.Context, username string) (*User, error)
 
-	// Update updates an existing user's information.
+	// Update updates an existing user's information. Returns
+	// ErrUsernameTaken or errs.ErrAlreadyExists like Create.
 	Update(ctx context.Context, user *User) error
 
-	// Delete removes a user by their ID.
+	// SoftDelete marks an active user as deleted, keeping their chats and messages.
+	SoftDelete(ctx context.Context, id int, deletedAt time.Time) error
+
+	// Delete permanently removes a user by their ID, cascading to their
+	// chat memberships, messages and the chats they created.
 	Delete(ctx context.Context, id int) error
 
-	// ListWithCount returns paginated list of users.
+	// MarkEmailVerified records that the active user confirmed their email.
+	MarkEmailVerified(ctx context.Context, id int, verifiedAt time.Time) error
+
+	// SoftDeleteExpiredGuests marks guests whose accounts expired before now
+	// as deleted. Returns the IDs of the deleted guests.
+	SoftDeleteExpiredGuests(ctx context.Context, now time.Time) ([]int, error)
+
+	// Deactivate marks an active user as deactivated.
+	Deactivate(ctx context.Context, id int, deactivatedAt time.Time) error
+
+	// AnonymizeDeactivated marks users deactivated before deactivatedBefore
+	// as deleted and erases their email, password and profile. Returns the
+	// IDs of the anonymized users and the image paths they had.
+	AnonymizeDeactivated(ctx context.Context, deactivatedBefore, now time.Time) ([]int, []string, error)
+
+	// ListWithCount returns paginated list of active members of a workspace,
+	// leaving out deactivated users.
+	// Returns users slice, total count, and error.
+	ListWithCount(ctx context.Context, workspaceID, offset, limit int) ([]*User, int, error)
+
+	// SearchByUsernameWithCount returns paginated list of active members of a
+	// workspace filtered by username search, leaving out deactivated users.
 	// Returns users slice, total count, and error.
-	ListWithCount(ctx context.Context, offset, limit int) ([]*User, int, error)
+	SearchByUsernameWithCount(ctx context.Context, workspaceID int, username string, offset, limit int) ([]*User, int, error)
 
-	// SearchByUsernameWithCount returns paginated list of users filtered by username search.
+	// ListContactsWithCount returns paginated list of active members of a
+	// workspace who are contacts of a user, filtered by username search
+	// unless username is empty.
 	// Returns users slice, total count, and error.
-	SearchByUsernameWithCount(ctx context.Context, username string, offset, limit int) ([]*User, int, error)
+	ListContactsWithCount(
+		ctx context.Context,
+		workspaceID, userID int,
+		username string,
+		offset, limit int,
+	) ([]*User, int, error)
 }
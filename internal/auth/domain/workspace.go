@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultWorkspaceSlug is the slug of the workspace that existing users and
+// self-registered accounts belong to.
+const DefaultWorkspaceSlug = "default"
+
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleAdmin  WorkspaceRole = "admin"
+	WorkspaceRoleMember WorkspaceRole = "member"
+)
+
+func (r WorkspaceRole) IsValid() bool {
+	return r == WorkspaceRoleAdmin || r == WorkspaceRoleMember
+}
+
+func (r WorkspaceRole) String() string {
+	return string(r)
+}
+
+// Workspace is an isolated community. Its members only see each other and
+// the chats created in it.
+type Workspace struct {
+	ID        int
+	PublicID  string // UUID exposed in the API instead of the sequential ID
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int
+	UserID      int
+	Role        WorkspaceRole
+	JoinedAt    time.Time
+}
+
+// WorkspaceMembership is a workspace together with the user's role in it.
+type WorkspaceMembership struct {
+	Workspace
+	Role     WorkspaceRole
+	JoinedAt time.Time
+}
+
+// WorkspaceRepository defines the interface for workspace data access.
+type WorkspaceRepository interface {
+	// Create creates a new workspace with the creator as its admin and sets
+	// its ID.
+	Create(ctx context.Context, workspace *Workspace, creatorID int) error
+
+	// GetByID retrieves a workspace by its ID.
+	GetByID(ctx context.Context, id int) (*Workspace, error)
+
+	// GetBySlug retrieves a workspace by its slug.
+	GetBySlug(ctx context.Context, slug string) (*Workspace, error)
+
+	// GetIDByPublicID returns the internal ID of the workspace with the given public ID.
+	GetIDByPublicID(ctx context.Context, publicID string) (int, error)
+
+	// ListByUser returns the workspaces a user belongs to, oldest membership first.
+	ListByUser(ctx context.Context, userID int) ([]WorkspaceMembership, error)
+
+	// ListMemberIDs returns the IDs of the active users who belong to a
+	// workspace.
+	ListMemberIDs(ctx context.Context, workspaceID int) ([]int, error)
+
+	// GetMember retrieves a user's membership of a workspace.
+	// Returns errs.ErrNotFound if the user isn't a member.
+	GetMember(ctx context.Context, workspaceID, userID int) (*WorkspaceMember, error)
+
+	// AddMember adds a user to a workspace.
+	// Returns errs.ErrAlreadyExists if the user is already a member.
+	AddMember(ctx context.Context, member *WorkspaceMember) error
+
+	// RemoveMember removes a user from a workspace.
+	// Returns errs.ErrNotFound if the user isn't a member.
+	RemoveMember(ctx context.Context, workspaceID, userID int) error
+}
@@ -0,0 +1,116 @@
+package infra
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemAPIKeyRepo is an in-memory APIKeyRepository for the dev command.
+type MemAPIKeyRepo struct {
+	store *MemStore
+}
+
+func NewMemAPIKeyRepo(store *MemStore) *MemAPIKeyRepo {
+	return &MemAPIKeyRepo{
+		store: store,
+	}
+}
+
+func (r *MemAPIKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastAPIKeyID++
+	key.ID = r.store.lastAPIKeyID
+
+	stored := *key
+	stored.Scopes = slices.Clone(key.Scopes)
+	r.store.apiKeys[key.ID] = &stored
+
+	return nil
+}
+
+func (r *MemAPIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	const op = "memapikey.GetByHash"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, key := range r.store.apiKeys {
+		if key.KeyHash == keyHash {
+			found := *key
+			found.Scopes = slices.Clone(key.Scopes)
+			return &found, nil
+		}
+	}
+
+	return nil, errs.Wrap(op, errs.ErrNotFound)
+}
+
+func (r *MemAPIKeyRepo) ListByUser(ctx context.Context, userID int) ([]*domain.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := make([]*domain.APIKey, 0)
+	for _, key := range r.store.apiKeys {
+		if key.UserID != userID || key.IsRevoked() {
+			continue
+		}
+		found := *key
+		found.Scopes = slices.Clone(key.Scopes)
+		keys = append(keys, &found)
+	}
+
+	slices.SortFunc(keys, func(a, b *domain.APIKey) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+
+	return keys, nil
+}
+
+func (r *MemAPIKeyRepo) CountByUser(ctx context.Context, userID int) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, key := range r.store.apiKeys {
+		if key.UserID == userID && !key.IsRevoked() {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MemAPIKeyRepo) Revoke(ctx context.Context, userID, id int, revokedAt time.Time) error {
+	const op = "memapikey.Revoke"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || key.UserID != userID || key.IsRevoked() {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	key.RevokedAt = &revokedAt
+
+	return nil
+}
+
+func (r *MemAPIKeyRepo) Touch(ctx context.Context, id int, usedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if key, ok := r.store.apiKeys[id]; ok {
+		key.LastUsedAt = &usedAt
+	}
+
+	return nil
+}
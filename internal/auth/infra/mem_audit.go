@@ -0,0 +1,66 @@
+package infra
+
+import (
+	"context"
+	"slices"
+
+	"chatx-01-backend/internal/auth/domain"
+)
+
+// MemAuditRepo is an in-memory AuditRepository for the dev command.
+type MemAuditRepo struct {
+	store *MemStore
+}
+
+func NewMemAuditRepo(store *MemStore) *MemAuditRepo {
+	return &MemAuditRepo{
+		store: store,
+	}
+}
+
+func (r *MemAuditRepo) Create(ctx context.Context, event *domain.AuditEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event.ID = len(r.store.auditEvents) + 1
+	r.store.auditEvents = append(r.store.auditEvents, *event)
+
+	return nil
+}
+
+func (r *MemAuditRepo) ListWithCount(
+	ctx context.Context,
+	filter domain.AuditFilter,
+	offset, limit int,
+) ([]domain.AuditEvent, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := make([]domain.AuditEvent, 0)
+	for _, event := range r.store.auditEvents {
+		if auditEventMatches(event, filter) {
+			events = append(events, event)
+		}
+	}
+	slices.Reverse(events)
+
+	return page(events, offset, limit), len(events), nil
+}
+
+func auditEventMatches(event domain.AuditEvent, filter domain.AuditFilter) bool {
+	switch {
+	case filter.Action != "" && event.Action != filter.Action:
+		return false
+	case filter.ActorID > 0 && event.ActorID != filter.ActorID:
+		return false
+	case filter.TargetUserID > 0 && (event.TargetUserID == nil || *event.TargetUserID != filter.TargetUserID):
+		return false
+	case filter.ChatID > 0 && (event.ChatID == nil || *event.ChatID != filter.ChatID):
+		return false
+	case filter.From != nil && event.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !event.CreatedAt.Before(*filter.To):
+		return false
+	}
+	return true
+}
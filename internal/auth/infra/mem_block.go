@@ -0,0 +1,96 @@
+package infra
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemBlockRepo is an in-memory BlockRepository for the dev command.
+type MemBlockRepo struct {
+	store *MemStore
+}
+
+func NewMemBlockRepo(store *MemStore) *MemBlockRepo {
+	return &MemBlockRepo{
+		store: store,
+	}
+}
+
+func (r *MemBlockRepo) Block(ctx context.Context, block *domain.UserBlock) error {
+	const op = "memblock.Block"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := blockKey{blockerID: block.BlockerID, blockedID: block.BlockedID}
+	if _, ok := r.store.blocks[key]; ok {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+	r.store.blocks[key] = *block
+
+	return nil
+}
+
+func (r *MemBlockRepo) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	const op = "memblock.Unblock"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := blockKey{blockerID: blockerID, blockedID: blockedID}
+	if _, ok := r.store.blocks[key]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	delete(r.store.blocks, key)
+
+	return nil
+}
+
+func (r *MemBlockRepo) ListByBlocker(ctx context.Context, blockerID int) ([]domain.UserBlock, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	blocks := make([]domain.UserBlock, 0)
+	for key, block := range r.store.blocks {
+		if key.blockerID == blockerID {
+			blocks = append(blocks, block)
+		}
+	}
+	slices.SortFunc(blocks, func(a, b domain.UserBlock) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.BlockedID, a.BlockedID))
+	})
+
+	return blocks, nil
+}
+
+func (r *MemBlockRepo) IsBlocked(ctx context.Context, userID1, userID2 int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, blocked := r.store.blocks[blockKey{blockerID: userID1, blockedID: userID2}]
+	_, blockedBy := r.store.blocks[blockKey{blockerID: userID2, blockedID: userID1}]
+
+	return blocked || blockedBy, nil
+}
+
+func (r *MemBlockRepo) GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ids := make([]int, 0)
+	for key := range r.store.blocks {
+		switch userID {
+		case key.blockerID:
+			ids = append(ids, key.blockedID)
+		case key.blockedID:
+			ids = append(ids, key.blockerID)
+		}
+	}
+	slices.Sort(ids)
+
+	return slices.Compact(ids), nil
+}
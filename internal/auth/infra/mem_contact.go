@@ -0,0 +1,120 @@
+package infra
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemContactRepo is an in-memory ContactRepository for the dev command.
+type MemContactRepo struct {
+	store *MemStore
+}
+
+func NewMemContactRepo(store *MemStore) *MemContactRepo {
+	return &MemContactRepo{
+		store: store,
+	}
+}
+
+func (r *MemContactRepo) Create(ctx context.Context, contact *domain.Contact) error {
+	const op = "memcontact.Create"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := newContactKey(contact.RequesterID, contact.AddresseeID)
+	if _, ok := r.store.contacts[key]; ok {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+	stored := *contact
+	r.store.contacts[key] = &stored
+
+	return nil
+}
+
+func (r *MemContactRepo) Get(ctx context.Context, userID1, userID2 int) (*domain.Contact, error) {
+	const op = "memcontact.Get"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	contact, ok := r.store.contacts[newContactKey(userID1, userID2)]
+	if !ok {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+	found := *contact
+
+	return &found, nil
+}
+
+func (r *MemContactRepo) Accept(ctx context.Context, requesterID, addresseeID int, acceptedAt time.Time) error {
+	const op = "memcontact.Accept"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	contact, ok := r.store.contacts[newContactKey(requesterID, addresseeID)]
+	if !ok || contact.RequesterID != requesterID || contact.Status != domain.ContactStatusPending {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	contact.Status = domain.ContactStatusAccepted
+	contact.AcceptedAt = &acceptedAt
+
+	return nil
+}
+
+func (r *MemContactRepo) Delete(ctx context.Context, userID1, userID2 int) error {
+	const op = "memcontact.Delete"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := newContactKey(userID1, userID2)
+	if _, ok := r.store.contacts[key]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	delete(r.store.contacts, key)
+
+	return nil
+}
+
+func (r *MemContactRepo) ListContacts(ctx context.Context, userID int) ([]domain.Contact, error) {
+	contacts := r.filter(func(c *domain.Contact) bool {
+		return (c.RequesterID == userID || c.AddresseeID == userID) && c.Status == domain.ContactStatusAccepted
+	})
+	slices.SortFunc(contacts, func(a, b domain.Contact) int {
+		return b.AcceptedAt.Compare(*a.AcceptedAt)
+	})
+
+	return contacts, nil
+}
+
+func (r *MemContactRepo) ListIncoming(ctx context.Context, userID int) ([]domain.Contact, error) {
+	contacts := r.filter(func(c *domain.Contact) bool {
+		return c.AddresseeID == userID && c.Status == domain.ContactStatusPending
+	})
+	slices.SortFunc(contacts, func(a, b domain.Contact) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.RequesterID, a.RequesterID))
+	})
+
+	return contacts, nil
+}
+
+// filter returns copies of the requests and contacts matching match.
+func (r *MemContactRepo) filter(match func(c *domain.Contact) bool) []domain.Contact {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	contacts := make([]domain.Contact, 0)
+	for _, contact := range r.store.contacts {
+		if match(contact) {
+			contacts = append(contacts, *contact)
+		}
+	}
+	return contacts
+}
@@ -0,0 +1,93 @@
+package infra
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemSpamRepo is an in-memory SpamRepository for the dev command.
+type MemSpamRepo struct {
+	store *MemStore
+}
+
+func NewMemSpamRepo(store *MemStore) *MemSpamRepo {
+	return &MemSpamRepo{
+		store: store,
+	}
+}
+
+func (r *MemSpamRepo) Flag(ctx context.Context, flag *domain.SpamFlag) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.spamFlags {
+		if existing.UserID == flag.UserID && existing.Reason == flag.Reason && existing.IsOpen() {
+			return false, nil
+		}
+	}
+
+	r.store.lastSpamFlagID++
+	flag.ID = r.store.lastSpamFlagID
+
+	stored := *flag
+	r.store.spamFlags[flag.ID] = &stored
+
+	return true, nil
+}
+
+func (r *MemSpamRepo) ListWithCount(
+	ctx context.Context,
+	status domain.SpamFlagStatus,
+	offset, limit int,
+) ([]domain.SpamFlag, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	flags := make([]domain.SpamFlag, 0)
+	for _, flag := range r.store.spamFlags {
+		if status == domain.SpamFlagOpen && !flag.IsOpen() || status == domain.SpamFlagResolved && flag.IsOpen() {
+			continue
+		}
+		flags = append(flags, *flag)
+	}
+	slices.SortFunc(flags, func(a, b domain.SpamFlag) int {
+		return b.ID - a.ID
+	})
+
+	return page(flags, offset, limit), len(flags), nil
+}
+
+func (r *MemSpamRepo) Resolve(ctx context.Context, id, resolverID int, resolvedAt time.Time) (*domain.SpamFlag, error) {
+	const op = "memspam.Resolve"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	flag, ok := r.store.spamFlags[id]
+	if !ok || !flag.IsOpen() {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	flag.ResolvedAt = &resolvedAt
+	flag.ResolvedBy = &resolverID
+
+	resolved := *flag
+	return &resolved, nil
+}
+
+func (r *MemSpamRepo) IsShadowLimited(ctx context.Context, userID int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, flag := range r.store.spamFlags {
+		if flag.UserID == userID && flag.Action == domain.SpamActionShadow && flag.IsOpen() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
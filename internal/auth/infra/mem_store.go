@@ -0,0 +1,101 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chatx-01-backend/internal/auth/domain"
+)
+
+// MemStore holds the tables of the in-memory repositories used by the dev
+// command. Repositories sharing a store see each other's writes, like the
+// Postgres repositories sharing a database. Data is lost when the process
+// exits.
+type MemStore struct {
+	mu sync.RWMutex
+
+	users           map[int]*domain.User
+	userIDs         map[string]int // public ID -> ID
+	lastUserID      int
+	workspaces      map[int]*domain.Workspace
+	lastWorkspaceID int
+	members         map[int]map[int]domain.WorkspaceMember // workspace ID -> user ID -> member
+	terms           map[termsKey]domain.TermsAcceptance
+	spamFlags       map[int]*domain.SpamFlag
+	lastSpamFlagID  int
+	blocks          map[blockKey]domain.UserBlock
+	contacts        map[contactKey]*domain.Contact
+	apiKeys         map[int]*domain.APIKey
+	lastAPIKeyID    int
+	auditEvents     []domain.AuditEvent // In order of creation, kept after users are deleted
+
+	// onUserDeleted cascades a user deletion to the stores of other modules
+	onUserDeleted []func(ctx context.Context, userID int)
+}
+
+type termsKey struct {
+	userID  int
+	version string
+}
+
+type blockKey struct {
+	blockerID int
+	blockedID int
+}
+
+// contactKey identifies the pair of users of a contact, whichever of them
+// sent the request.
+type contactKey struct {
+	lowID  int
+	highID int
+}
+
+func newContactKey(userID1, userID2 int) contactKey {
+	return contactKey{lowID: min(userID1, userID2), highID: max(userID1, userID2)}
+}
+
+// NewMemStore creates an empty store holding the default workspace, which
+// the Postgres migrations create.
+func NewMemStore() *MemStore {
+	s := &MemStore{
+		users:      make(map[int]*domain.User),
+		userIDs:    make(map[string]int),
+		workspaces: make(map[int]*domain.Workspace),
+		members:    make(map[int]map[int]domain.WorkspaceMember),
+		terms:      make(map[termsKey]domain.TermsAcceptance),
+		spamFlags:  make(map[int]*domain.SpamFlag),
+		blocks:     make(map[blockKey]domain.UserBlock),
+		contacts:   make(map[contactKey]*domain.Contact),
+		apiKeys:    make(map[int]*domain.APIKey),
+	}
+	s.insertWorkspace(&domain.Workspace{
+		Slug:      domain.DefaultWorkspaceSlug,
+		Name:      "Default",
+		CreatedAt: time.Now(),
+	})
+	return s
+}
+
+// OnUserDeleted registers fn to be called after a user is permanently
+// deleted, in place of the foreign key cascades of the database.
+func (s *MemStore) OnUserDeleted(fn func(ctx context.Context, userID int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onUserDeleted = append(s.onUserDeleted, fn)
+}
+
+// insertWorkspace stores the workspace and sets its IDs. The caller holds
+// the lock or has the store to itself.
+func (s *MemStore) insertWorkspace(workspace *domain.Workspace) {
+	s.lastWorkspaceID++
+	workspace.ID = s.lastWorkspaceID
+	workspace.PublicID = uuid.NewString()
+
+	stored := *workspace
+	s.workspaces[workspace.ID] = &stored
+	s.members[workspace.ID] = make(map[int]domain.WorkspaceMember)
+}
@@ -0,0 +1,48 @@
+package infra
+
+import (
+	"context"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemTermsRepo is an in-memory TermsRepository for the dev command.
+type MemTermsRepo struct {
+	store *MemStore
+}
+
+func NewMemTermsRepo(store *MemStore) *MemTermsRepo {
+	return &MemTermsRepo{
+		store: store,
+	}
+}
+
+func (r *MemTermsRepo) Accept(ctx context.Context, acceptance *domain.TermsAcceptance) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := termsKey{userID: acceptance.UserID, version: acceptance.Version}
+	if existing, ok := r.store.terms[key]; ok {
+		acceptance.AcceptedAt = existing.AcceptedAt
+		return nil
+	}
+
+	r.store.terms[key] = *acceptance
+
+	return nil
+}
+
+func (r *MemTermsRepo) Get(ctx context.Context, userID int, version string) (*domain.TermsAcceptance, error) {
+	const op = "memterms.Get"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	acceptance, ok := r.store.terms[termsKey{userID: userID, version: version}]
+	if !ok {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return &acceptance, nil
+}
@@ -0,0 +1,405 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemUserRepo is an in-memory UserRepository for the dev command.
+type MemUserRepo struct {
+	store *MemStore
+}
+
+func NewMemUserRepo(store *MemStore) *MemUserRepo {
+	return &MemUserRepo{
+		store: store,
+	}
+}
+
+func (r *MemUserRepo) Create(ctx context.Context, user *domain.User) error {
+	const op = "memuser.Create"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user.Role != domain.RoleGuest && r.usernameTaken(user.Username, 0) {
+		return errs.Wrap(op, domain.ErrUsernameTaken)
+	}
+	if r.emailTaken(user.Email, 0) {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+
+	r.store.lastUserID++
+	user.ID = r.store.lastUserID
+	user.PublicID = uuid.NewString()
+
+	stored := *user
+	r.store.users[user.ID] = &stored
+	r.store.userIDs[user.PublicID] = user.ID
+
+	return nil
+}
+
+func (r *MemUserRepo) GetByID(ctx context.Context, id int) (*domain.User, error) {
+	const op = "memuser.GetByID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	found := *user
+	return &found, nil
+}
+
+func (r *MemUserRepo) GetByIDs(ctx context.Context, ids []int) ([]*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		user, ok := r.store.users[id]
+		if !ok || slices.ContainsFunc(users, func(u *domain.User) bool { return u.ID == id }) {
+			continue
+		}
+		found := *user
+		users = append(users, &found)
+	}
+
+	return users, nil
+}
+
+func (r *MemUserRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "memuser.GetIDByPublicID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	id, ok := r.store.userIDs[publicID]
+	if !ok {
+		return 0, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return id, nil
+}
+
+func (r *MemUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	const op = "memuser.GetByEmail"
+
+	return r.find(op, func(u *domain.User) bool {
+		return email != "" && strings.EqualFold(u.Email, email) && !u.IsDeleted()
+	})
+}
+
+func (r *MemUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	const op = "memuser.GetByUsername"
+
+	return r.find(op, func(u *domain.User) bool {
+		return strings.EqualFold(u.Username, username) && u.Role != domain.RoleGuest && !u.IsDeleted()
+	})
+}
+
+func (r *MemUserRepo) Update(ctx context.Context, user *domain.User) error {
+	const op = "memuser.Update"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+	if stored.DeletedAt == nil && stored.Role != domain.RoleGuest && r.usernameTaken(user.Username, user.ID) {
+		return errs.Wrap(op, domain.ErrUsernameTaken)
+	}
+	if stored.DeletedAt == nil && r.emailTaken(user.Email, user.ID) {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+
+	stored.Email = user.Email
+	stored.Username = user.Username
+	stored.PasswordHash = user.PasswordHash
+	stored.Role = user.Role
+	stored.ImagePath = user.ImagePath
+	stored.UpdatedAt = user.UpdatedAt
+	stored.Locale = user.Locale
+	stored.HideLastSeen = user.HideLastSeen
+	stored.DisplayName = user.DisplayName
+	stored.Bio = user.Bio
+	stored.Phone = user.Phone
+	stored.UsernameChangedAt = user.UsernameChangedAt
+
+	return nil
+}
+
+func (r *MemUserRepo) SoftDelete(ctx context.Context, id int, deletedAt time.Time) error {
+	const op = "memuser.SoftDelete"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.IsDeleted() {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	user.DeletedAt = &deletedAt
+	user.UpdatedAt = deletedAt
+
+	return nil
+}
+
+func (r *MemUserRepo) MarkEmailVerified(ctx context.Context, id int, verifiedAt time.Time) error {
+	const op = "memuser.MarkEmailVerified"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.IsDeleted() {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	user.EmailVerifiedAt = &verifiedAt
+	user.UpdatedAt = verifiedAt
+
+	return nil
+}
+
+func (r *MemUserRepo) SoftDeleteExpiredGuests(ctx context.Context, now time.Time) ([]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := make([]int, 0)
+	for _, user := range r.store.users {
+		if user.Role != domain.RoleGuest || user.IsDeleted() || user.ExpiresAt == nil || user.ExpiresAt.After(now) {
+			continue
+		}
+
+		user.DeletedAt = &now
+		user.UpdatedAt = now
+		ids = append(ids, user.ID)
+	}
+
+	return ids, nil
+}
+
+func (r *MemUserRepo) Deactivate(ctx context.Context, id int, deactivatedAt time.Time) error {
+	const op = "memuser.Deactivate"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.IsDeleted() || user.IsDeactivated() {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	user.DeactivatedAt = &deactivatedAt
+	user.UpdatedAt = deactivatedAt
+
+	return nil
+}
+
+func (r *MemUserRepo) AnonymizeDeactivated(ctx context.Context, deactivatedBefore, now time.Time) ([]int, []string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := make([]int, 0)
+	images := make([]string, 0)
+	for _, user := range r.store.users {
+		if user.IsDeleted() || user.DeactivatedAt == nil || user.DeactivatedAt.After(deactivatedBefore) {
+			continue
+		}
+
+		if user.ImagePath != nil {
+			images = append(images, *user.ImagePath)
+		}
+		user.DeletedAt = &now
+		user.UpdatedAt = now
+		user.Email = ""
+		user.Username = domain.DeletedUsername
+		user.PasswordHash = ""
+		user.ImagePath = nil
+		user.DisplayName = ""
+		user.Bio = ""
+		user.Phone = ""
+		ids = append(ids, user.ID)
+	}
+
+	return ids, images, nil
+}
+
+func (r *MemUserRepo) Delete(ctx context.Context, id int) error {
+	const op = "memuser.Delete"
+
+	r.store.mu.Lock()
+	user, ok := r.store.users[id]
+	if !ok {
+		r.store.mu.Unlock()
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	delete(r.store.users, id)
+	delete(r.store.userIDs, user.PublicID)
+	for _, members := range r.store.members {
+		delete(members, id)
+	}
+	for key := range r.store.terms {
+		if key.userID == id {
+			delete(r.store.terms, key)
+		}
+	}
+	for flagID, flag := range r.store.spamFlags {
+		if flag.UserID == id {
+			delete(r.store.spamFlags, flagID)
+		} else if flag.ResolvedBy != nil && *flag.ResolvedBy == id {
+			flag.ResolvedBy = nil
+		}
+	}
+	for key := range r.store.blocks {
+		if key.blockerID == id || key.blockedID == id {
+			delete(r.store.blocks, key)
+		}
+	}
+	for key := range r.store.contacts {
+		if key.lowID == id || key.highID == id {
+			delete(r.store.contacts, key)
+		}
+	}
+	for keyID, key := range r.store.apiKeys {
+		if key.UserID == id {
+			delete(r.store.apiKeys, keyID)
+		}
+	}
+	cascades := slices.Clone(r.store.onUserDeleted)
+	r.store.mu.Unlock()
+
+	for _, fn := range cascades {
+		fn(ctx, id)
+	}
+
+	return nil
+}
+
+func (r *MemUserRepo) ListWithCount(ctx context.Context, workspaceID, offset, limit int) ([]*domain.User, int, error) {
+	users := r.members(workspaceID, func(u *domain.User) bool { return !u.IsDeactivated() })
+	return page(users, offset, limit), len(users), nil
+}
+
+func (r *MemUserRepo) SearchByUsernameWithCount(
+	ctx context.Context,
+	workspaceID int,
+	username string,
+	offset, limit int,
+) ([]*domain.User, int, error) {
+	search := strings.ToLower(username)
+	users := r.members(workspaceID, func(u *domain.User) bool {
+		return !u.IsDeactivated() && strings.Contains(strings.ToLower(u.Username), search)
+	})
+	return page(users, offset, limit), len(users), nil
+}
+
+func (r *MemUserRepo) ListContactsWithCount(
+	ctx context.Context,
+	workspaceID, userID int,
+	username string,
+	offset, limit int,
+) ([]*domain.User, int, error) {
+	search := strings.ToLower(username)
+	users := r.members(workspaceID, func(u *domain.User) bool {
+		contact, ok := r.store.contacts[newContactKey(userID, u.ID)]
+		if !ok || u.ID == userID || contact.Status != domain.ContactStatusAccepted {
+			return false
+		}
+		return strings.Contains(strings.ToLower(u.Username), search)
+	})
+	return page(users, offset, limit), len(users), nil
+}
+
+// find returns a copy of the first user matching match.
+func (r *MemUserRepo) find(op string, match func(u *domain.User) bool) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+
+	return nil, errs.Wrap(op, errs.ErrNotFound)
+}
+
+// members returns copies of the active members of a workspace matching
+// match, newest first. match is called with the store locked.
+func (r *MemUserRepo) members(workspaceID int, match func(u *domain.User) bool) []*domain.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*domain.User, 0)
+	for userID := range r.store.members[workspaceID] {
+		user, ok := r.store.users[userID]
+		if !ok || user.IsDeleted() || !match(user) {
+			continue
+		}
+		found := *user
+		users = append(users, &found)
+	}
+
+	slices.SortFunc(users, func(a, b *domain.User) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+
+	return users
+}
+
+// emailTaken reports whether an active user other than exceptID has the
+// email, like the partial unique index on users. The caller holds the lock.
+func (r *MemUserRepo) emailTaken(email string, exceptID int) bool {
+	if email == "" {
+		return false
+	}
+
+	for _, user := range r.store.users {
+		if user.ID != exceptID && strings.EqualFold(user.Email, email) && !user.IsDeleted() {
+			return true
+		}
+	}
+	return false
+}
+
+// usernameTaken reports whether an active non-guest user other than exceptID
+// has the username.
+func (r *MemUserRepo) usernameTaken(username string, exceptID int) bool {
+	for _, user := range r.store.users {
+		if user.ID != exceptID && strings.EqualFold(user.Username, username) &&
+			user.Role != domain.RoleGuest && !user.IsDeleted() {
+			return true
+		}
+	}
+	return false
+}
+
+// page returns up to limit items starting at offset.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
@@ -0,0 +1,170 @@
+package infra
+
+import (
+	"context"
+	"slices"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemWorkspaceRepo is an in-memory WorkspaceRepository for the dev command.
+type MemWorkspaceRepo struct {
+	store *MemStore
+}
+
+func NewMemWorkspaceRepo(store *MemStore) *MemWorkspaceRepo {
+	return &MemWorkspaceRepo{
+		store: store,
+	}
+}
+
+func (r *MemWorkspaceRepo) Create(ctx context.Context, workspace *domain.Workspace, creatorID int) error {
+	const op = "memworkspace.Create"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, w := range r.store.workspaces {
+		if w.Slug == workspace.Slug {
+			return errs.Wrap(op, errs.ErrAlreadyExists)
+		}
+	}
+
+	r.store.insertWorkspace(workspace)
+	r.store.members[workspace.ID][creatorID] = domain.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      creatorID,
+		Role:        domain.WorkspaceRoleAdmin,
+		JoinedAt:    workspace.CreatedAt,
+	}
+
+	return nil
+}
+
+func (r *MemWorkspaceRepo) GetByID(ctx context.Context, id int) (*domain.Workspace, error) {
+	const op = "memworkspace.GetByID"
+
+	return r.find(op, func(w *domain.Workspace) bool { return w.ID == id })
+}
+
+func (r *MemWorkspaceRepo) GetBySlug(ctx context.Context, slug string) (*domain.Workspace, error) {
+	const op = "memworkspace.GetBySlug"
+
+	return r.find(op, func(w *domain.Workspace) bool { return w.Slug == slug })
+}
+
+func (r *MemWorkspaceRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "memworkspace.GetIDByPublicID"
+
+	workspace, err := r.find(op, func(w *domain.Workspace) bool { return w.PublicID == publicID })
+	if err != nil {
+		return 0, err
+	}
+
+	return workspace.ID, nil
+}
+
+func (r *MemWorkspaceRepo) ListByUser(ctx context.Context, userID int) ([]domain.WorkspaceMembership, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	memberships := make([]domain.WorkspaceMembership, 0)
+	for workspaceID, members := range r.store.members {
+		member, ok := members[userID]
+		if !ok {
+			continue
+		}
+		memberships = append(memberships, domain.WorkspaceMembership{
+			Workspace: *r.store.workspaces[workspaceID],
+			Role:      member.Role,
+			JoinedAt:  member.JoinedAt,
+		})
+	}
+
+	slices.SortFunc(memberships, func(a, b domain.WorkspaceMembership) int {
+		if c := a.JoinedAt.Compare(b.JoinedAt); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+
+	return memberships, nil
+}
+
+func (r *MemWorkspaceRepo) ListMemberIDs(ctx context.Context, workspaceID int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	userIDs := make([]int, 0, len(r.store.members[workspaceID]))
+	for userID := range r.store.members[workspaceID] {
+		if user, ok := r.store.users[userID]; ok && !user.IsDeleted() {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (r *MemWorkspaceRepo) GetMember(ctx context.Context, workspaceID, userID int) (*domain.WorkspaceMember, error) {
+	const op = "memworkspace.GetMember"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	member, ok := r.store.members[workspaceID][userID]
+	if !ok {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return &member, nil
+}
+
+func (r *MemWorkspaceRepo) AddMember(ctx context.Context, member *domain.WorkspaceMember) error {
+	const op = "memworkspace.AddMember"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	members, ok := r.store.members[member.WorkspaceID]
+	if !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	if _, ok := members[member.UserID]; ok {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+
+	members[member.UserID] = *member
+
+	return nil
+}
+
+func (r *MemWorkspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID int) error {
+	const op = "memworkspace.RemoveMember"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.members[workspaceID][userID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	delete(r.store.members[workspaceID], userID)
+
+	return nil
+}
+
+// find returns a copy of the first workspace matching match.
+func (r *MemWorkspaceRepo) find(op string, match func(w *domain.Workspace) bool) (*domain.Workspace, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, workspace := range r.store.workspaces {
+		if match(workspace) {
+			found := *workspace
+			return &found, nil
+		}
+	}
+
+	return nil, errs.Wrap(op, errs.ErrNotFound)
+}
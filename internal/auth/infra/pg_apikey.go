@@ -0,0 +1,172 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgAPIKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgAPIKeyRepo(pool *pgxpool.Pool) *PgAPIKeyRepo {
+	return &PgAPIKeyRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgAPIKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	const op = "pgapikey.Create"
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	query := `
+		INSERT INTO api_keys (user_id, workspace_id, name, hint, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		key.UserID,
+		key.WorkspaceID,
+		key.Name,
+		key.Hint,
+		key.KeyHash,
+		scopes,
+		key.CreatedAt,
+	).Scan(&key.ID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgAPIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	const op = "pgapikey.GetByHash"
+
+	query := `
+		SELECT id, user_id, workspace_id, name, hint, key_hash, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return key, nil
+}
+
+func (r *PgAPIKeyRepo) ListByUser(ctx context.Context, userID int) ([]*domain.APIKey, error) {
+	const op = "pgapikey.ListByUser"
+
+	query := `
+		SELECT id, user_id, workspace_id, name, hint, key_hash, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return keys, nil
+}
+
+func (r *PgAPIKeyRepo) CountByUser(ctx context.Context, userID int) (int, error) {
+	const op = "pgapikey.CountByUser"
+
+	query := `SELECT COUNT(*) FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
+
+func (r *PgAPIKeyRepo) Revoke(ctx context.Context, userID, id int, revokedAt time.Time) error {
+	const op = "pgapikey.Revoke"
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, id, userID, revokedAt)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgAPIKeyRepo) Touch(ctx context.Context, id int, usedAt time.Time) error {
+	const op = "pgapikey.Touch"
+
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, usedAt); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+// scanAPIKey scans a row of the api_keys columns in table order.
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var scopes []string
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.WorkspaceID,
+		&key.Name,
+		&key.Hint,
+		&key.KeyHash,
+		&scopes,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]domain.APIKeyScope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = domain.APIKeyScope(scope)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,138 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgAuditRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgAuditRepo(pool *pgxpool.Pool) *PgAuditRepo {
+	return &PgAuditRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgAuditRepo) Create(ctx context.Context, event *domain.AuditEvent) error {
+	const op = "pgaudit.Create"
+
+	query := `
+		INSERT INTO audit_events (
+			action, actor_id, target_user_id, chat_id, workspace_id, ip, user_agent, details, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		event.Action,
+		event.ActorID,
+		event.TargetUserID,
+		event.ChatID,
+		event.WorkspaceID,
+		event.IP,
+		event.UserAgent,
+		event.Details,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgAuditRepo) ListWithCount(
+	ctx context.Context,
+	filter domain.AuditFilter,
+	offset, limit int,
+) ([]domain.AuditEvent, int, error) {
+	const op = "pgaudit.ListWithCount"
+
+	var conditions []string
+	var args []any
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.ActorID > 0 {
+		args = append(args, filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	if filter.TargetUserID > 0 {
+		args = append(args, filter.TargetUserID)
+		conditions = append(conditions, fmt.Sprintf("target_user_id = $%d", len(args)))
+	}
+	if filter.ChatID > 0 {
+		args = append(args, filter.ChatID)
+		conditions = append(conditions, fmt.Sprintf("chat_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	var where string
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_events `+where, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	args = append(args, limit, offset)
+	query := `
+		SELECT id, action, actor_id, target_user_id, chat_id, workspace_id, ip, user_agent, details, created_at
+		FROM audit_events
+		` + where + fmt.Sprintf(`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	events := make([]domain.AuditEvent, 0)
+	for rows.Next() {
+		event := domain.AuditEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.Action,
+			&event.ActorID,
+			&event.TargetUserID,
+			&event.ChatID,
+			&event.WorkspaceID,
+			&event.IP,
+			&event.UserAgent,
+			&event.Details,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	return events, totalCount, nil
+}
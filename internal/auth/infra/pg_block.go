@@ -0,0 +1,128 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgBlockRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgBlockRepo(pool *pgxpool.Pool) *PgBlockRepo {
+	return &PgBlockRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgBlockRepo) Block(ctx context.Context, block *domain.UserBlock) error {
+	const op = "pgblock.Block"
+
+	query := `
+		INSERT INTO user_blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3)`
+
+	_, err := r.pool.Exec(ctx, query, block.BlockerID, block.BlockedID, block.CreatedAt)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgBlockRepo) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	const op = "pgblock.Unblock"
+
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	result, err := r.pool.Exec(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgBlockRepo) ListByBlocker(ctx context.Context, blockerID int) ([]domain.UserBlock, error) {
+	const op = "pgblock.ListByBlocker"
+
+	query := `
+		SELECT blocker_id, blocked_id, created_at
+		FROM user_blocks
+		WHERE blocker_id = $1
+		ORDER BY created_at DESC, blocked_id DESC`
+
+	rows, err := r.pool.Query(ctx, query, blockerID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	blocks := make([]domain.UserBlock, 0)
+	for rows.Next() {
+		var block domain.UserBlock
+		if err := rows.Scan(&block.BlockerID, &block.BlockedID, &block.CreatedAt); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		blocks = append(blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return blocks, nil
+}
+
+func (r *PgBlockRepo) IsBlocked(ctx context.Context, userID1, userID2 int) (bool, error) {
+	const op = "pgblock.IsBlocked"
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)`
+
+	var blocked bool
+	if err := r.pool.QueryRow(ctx, query, userID1, userID2).Scan(&blocked); err != nil {
+		return false, pg.WrapRepoError(op, err)
+	}
+
+	return blocked, nil
+}
+
+func (r *PgBlockRepo) GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error) {
+	const op = "pgblock.GetBlockedUserIDs"
+
+	query := `
+		SELECT blocked_id FROM user_blocks WHERE blocker_id = $1
+		UNION
+		SELECT blocker_id FROM user_blocks WHERE blocked_id = $1`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,169 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgContactRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgContactRepo(pool *pgxpool.Pool) *PgContactRepo {
+	return &PgContactRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgContactRepo) Create(ctx context.Context, contact *domain.Contact) error {
+	const op = "pgcontact.Create"
+
+	query := `
+		INSERT INTO user_contacts (requester_id, addressee_id, status, created_at, accepted_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.pool.Exec(ctx, query,
+		contact.RequesterID,
+		contact.AddresseeID,
+		contact.Status,
+		contact.CreatedAt,
+		contact.AcceptedAt,
+	)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgContactRepo) Get(ctx context.Context, userID1, userID2 int) (*domain.Contact, error) {
+	const op = "pgcontact.Get"
+
+	query := `
+		SELECT requester_id, addressee_id, status, created_at, accepted_at
+		FROM user_contacts
+		WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)`
+
+	var contact domain.Contact
+	err := r.pool.QueryRow(ctx, query, userID1, userID2).Scan(
+		&contact.RequesterID,
+		&contact.AddresseeID,
+		&contact.Status,
+		&contact.CreatedAt,
+		&contact.AcceptedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return &contact, nil
+}
+
+func (r *PgContactRepo) Accept(ctx context.Context, requesterID, addresseeID int, acceptedAt time.Time) error {
+	const op = "pgcontact.Accept"
+
+	query := `
+		UPDATE user_contacts
+		SET status = $3, accepted_at = $4
+		WHERE requester_id = $1 AND addressee_id = $2 AND status = $5`
+
+	result, err := r.pool.Exec(ctx, query,
+		requesterID,
+		addresseeID,
+		domain.ContactStatusAccepted,
+		acceptedAt,
+		domain.ContactStatusPending,
+	)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgContactRepo) Delete(ctx context.Context, userID1, userID2 int) error {
+	const op = "pgcontact.Delete"
+
+	query := `
+		DELETE FROM user_contacts
+		WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)`
+
+	result, err := r.pool.Exec(ctx, query, userID1, userID2)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgContactRepo) ListContacts(ctx context.Context, userID int) ([]domain.Contact, error) {
+	const op = "pgcontact.ListContacts"
+
+	query := `
+		SELECT requester_id, addressee_id, status, created_at, accepted_at
+		FROM user_contacts
+		WHERE (requester_id = $1 OR addressee_id = $1) AND status = $2
+		ORDER BY accepted_at DESC`
+
+	contacts, err := r.list(ctx, query, userID, domain.ContactStatusAccepted)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return contacts, nil
+}
+
+func (r *PgContactRepo) ListIncoming(ctx context.Context, userID int) ([]domain.Contact, error) {
+	const op = "pgcontact.ListIncoming"
+
+	query := `
+		SELECT requester_id, addressee_id, status, created_at, accepted_at
+		FROM user_contacts
+		WHERE addressee_id = $1 AND status = $2
+		ORDER BY created_at DESC`
+
+	contacts, err := r.list(ctx, query, userID, domain.ContactStatusPending)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return contacts, nil
+}
+
+func (r *PgContactRepo) list(ctx context.Context, query string, args ...any) ([]domain.Contact, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := make([]domain.Contact, 0)
+	for rows.Next() {
+		var contact domain.Contact
+		err := rows.Scan(
+			&contact.RequesterID,
+			&contact.AddresseeID,
+			&contact.Status,
+			&contact.CreatedAt,
+			&contact.AcceptedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
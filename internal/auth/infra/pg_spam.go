@@ -0,0 +1,156 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgSpamRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgSpamRepo(pool *pgxpool.Pool) *PgSpamRepo {
+	return &PgSpamRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgSpamRepo) Flag(ctx context.Context, flag *domain.SpamFlag) (bool, error) {
+	const op = "pgspam.Flag"
+
+	// The partial unique index keeps one open flag per user and reason
+	query := `
+		INSERT INTO spam_flags (user_id, reason, action, details, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, reason) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING id`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		flag.UserID,
+		flag.Reason,
+		flag.Action,
+		flag.Details,
+		flag.CreatedAt,
+	).Scan(&flag.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, pg.WrapRepoError(op, err)
+	}
+
+	return true, nil
+}
+
+func (r *PgSpamRepo) ListWithCount(
+	ctx context.Context,
+	status domain.SpamFlagStatus,
+	offset, limit int,
+) ([]domain.SpamFlag, int, error) {
+	const op = "pgspam.ListWithCount"
+
+	var where string
+	switch status {
+	case domain.SpamFlagOpen:
+		where = "WHERE resolved_at IS NULL"
+	case domain.SpamFlagResolved:
+		where = "WHERE resolved_at IS NOT NULL"
+	}
+
+	var totalCount int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM spam_flags `+where).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	query := `
+		SELECT id, user_id, reason, action, details, created_at, resolved_at, resolved_by
+		FROM spam_flags
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	flags := make([]domain.SpamFlag, 0)
+	for rows.Next() {
+		flag := domain.SpamFlag{}
+		err := rows.Scan(
+			&flag.ID,
+			&flag.UserID,
+			&flag.Reason,
+			&flag.Action,
+			&flag.Details,
+			&flag.CreatedAt,
+			&flag.ResolvedAt,
+			&flag.ResolvedBy,
+		)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
+		flags = append(flags, flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	return flags, totalCount, nil
+}
+
+func (r *PgSpamRepo) Resolve(ctx context.Context, id, resolverID int, resolvedAt time.Time) (*domain.SpamFlag, error) {
+	const op = "pgspam.Resolve"
+
+	query := `
+		UPDATE spam_flags
+		SET resolved_at = $2, resolved_by = $3
+		WHERE id = $1 AND resolved_at IS NULL
+		RETURNING id, user_id, reason, action, details, created_at, resolved_at, resolved_by`
+
+	flag := &domain.SpamFlag{}
+	err := r.pool.QueryRow(ctx, query, id, resolvedAt, resolverID).Scan(
+		&flag.ID,
+		&flag.UserID,
+		&flag.Reason,
+		&flag.Action,
+		&flag.Details,
+		&flag.CreatedAt,
+		&flag.ResolvedAt,
+		&flag.ResolvedBy,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return flag, nil
+}
+
+func (r *PgSpamRepo) IsShadowLimited(ctx context.Context, userID int) (bool, error) {
+	const op = "pgspam.IsShadowLimited"
+
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM spam_flags
+			WHERE user_id = $1 AND resolved_at IS NULL AND action = 'shadow'
+		)`
+
+	var limited bool
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&limited); err != nil {
+		return false, pg.WrapRepoError(op, err)
+	}
+
+	return limited, nil
+}
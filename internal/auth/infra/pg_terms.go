@@ -0,0 +1,60 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgTermsRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgTermsRepo(pool *pgxpool.Pool) *PgTermsRepo {
+	return &PgTermsRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgTermsRepo) Accept(ctx context.Context, acceptance *domain.TermsAcceptance) error {
+	const op = "pgterms.Accept"
+
+	// The no-op update makes RETURNING yield the existing row on conflict
+	query := `
+		INSERT INTO terms_acceptances (user_id, version, accepted_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, version) DO UPDATE SET version = EXCLUDED.version
+		RETURNING accepted_at`
+
+	err := r.pool.QueryRow(ctx, query, acceptance.UserID, acceptance.Version, acceptance.AcceptedAt).
+		Scan(&acceptance.AcceptedAt)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgTermsRepo) Get(ctx context.Context, userID int, version string) (*domain.TermsAcceptance, error) {
+	const op = "pgterms.Get"
+
+	query := `
+		SELECT user_id, version, accepted_at
+		FROM terms_acceptances
+		WHERE user_id = $1 AND version = $2`
+
+	acceptance := &domain.TermsAcceptance{}
+	err := r.pool.QueryRow(ctx, query, userID, version).Scan(
+		&acceptance.UserID,
+		&acceptance.Version,
+		&acceptance.AcceptedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return acceptance, nil
+}
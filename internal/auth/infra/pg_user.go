@@ -3,6 +3,7 @@ package infra
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -11,6 +12,9 @@ import (
 	"chatx-01-backend/pkg/pg"
 )
 
+// usernameIndex is the unique index on active usernames.
+const usernameIndex = "idx_users_username_active"
+
 type PgUserRepo struct {
 	pool *pgxpool.Pool
 }
@@ -25,9 +29,9 @@ func (r *PgUserRepo) Create(ctx context.Context, user *domain.User) error {
 	const op = "pguser.Create"
 
 	query := `
-		INSERT INTO users (email, username, password_hash, role, image_path, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id`
+		INSERT INTO users (email, username, password_hash, role, image_path, created_at, updated_at, expires_at, email_verified_at)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, public_id`
 
 	err := r.pool.QueryRow(
 		ctx,
@@ -39,8 +43,13 @@ func (r *PgUserRepo) Create(ctx context.Context, user *domain.User) error {
 		user.ImagePath,
 		user.CreatedAt,
 		user.UpdatedAt,
-	).Scan(&user.ID)
+		user.ExpiresAt,
+		user.EmailVerifiedAt,
+	).Scan(&user.ID, &user.PublicID)
 	if err != nil {
+		if pg.ConstraintName(err) == usernameIndex {
+			return errs.Wrap(op, domain.ErrUsernameTaken)
+		}
 		return pg.WrapRepoError(op, err)
 	}
 
@@ -51,13 +60,15 @@ func (r *PgUserRepo) GetByID(ctx context.Context, id int) (*domain.User, error)
 	const op = "pguser.GetByID"
 
 	query := `
-		SELECT id, email, username, password_hash, role, image_path, created_at, updated_at
+		SELECT id, public_id, COALESCE(email, ''), username, password_hash, role, image_path, created_at, updated_at, deleted_at, expires_at, email_verified_at, COALESCE(locale, ''),
+			hide_last_seen, COALESCE(display_name, ''), COALESCE(bio, ''), COALESCE(phone, ''), username_changed_at, deactivated_at
 		FROM users
 		WHERE id = $1`
 
 	user := &domain.User{}
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&user.ID,
+		&user.PublicID,
 		&user.Email,
 		&user.Username,
 		&user.PasswordHash,
@@ -65,6 +76,16 @@ func (r *PgUserRepo) GetByID(ctx context.Context, id int) (*domain.User, error)
 		&user.ImagePath,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeletedAt,
+		&user.ExpiresAt,
+		&user.EmailVerifiedAt,
+		&user.Locale,
+		&user.HideLastSeen,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Phone,
+		&user.UsernameChangedAt,
+		&user.DeactivatedAt,
 	)
 	if err != nil {
 		return nil, pg.WrapRepoError(op, err)
@@ -73,17 +94,84 @@ func (r *PgUserRepo) GetByID(ctx context.Context, id int) (*domain.User, error)
 	return user, nil
 }
 
+func (r *PgUserRepo) GetByIDs(ctx context.Context, ids []int) ([]*domain.User, error) {
+	const op = "pguser.GetByIDs"
+
+	query := `
+		SELECT id, public_id, COALESCE(email, ''), username, password_hash, role, image_path, created_at, updated_at, deleted_at, expires_at, email_verified_at, COALESCE(locale, ''),
+			hide_last_seen, COALESCE(display_name, ''), COALESCE(bio, ''), COALESCE(phone, ''), username_changed_at, deactivated_at
+		FROM users
+		WHERE id = ANY($1)`
+
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0, len(ids))
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.PublicID,
+			&user.Email,
+			&user.Username,
+			&user.PasswordHash,
+			&user.Role,
+			&user.ImagePath,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.DeletedAt,
+			&user.ExpiresAt,
+			&user.EmailVerifiedAt,
+			&user.Locale,
+			&user.HideLastSeen,
+			&user.DisplayName,
+			&user.Bio,
+			&user.Phone,
+			&user.UsernameChangedAt,
+			&user.DeactivatedAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return users, nil
+}
+
+func (r *PgUserRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "pguser.GetIDByPublicID"
+
+	query := `SELECT id FROM users WHERE public_id = $1`
+
+	var id int
+	if err := r.pool.QueryRow(ctx, query, publicID).Scan(&id); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return id, nil
+}
+
 func (r *PgUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	const op = "pguser.GetByEmail"
 
 	query := `
-		SELECT id, email, username, password_hash, role, image_path, created_at, updated_at
+		SELECT id, public_id, COALESCE(email, ''), username, password_hash, role, image_path, created_at, updated_at, deleted_at, expires_at, email_verified_at, COALESCE(locale, ''),
+			hide_last_seen, COALESCE(display_name, ''), COALESCE(bio, ''), COALESCE(phone, ''), username_changed_at, deactivated_at
 		FROM users
-		WHERE email = $1`
+		WHERE lower(email) = lower($1) AND deleted_at IS NULL`
 
 	user := &domain.User{}
 	err := r.pool.QueryRow(ctx, query, email).Scan(
 		&user.ID,
+		&user.PublicID,
 		&user.Email,
 		&user.Username,
 		&user.PasswordHash,
@@ -91,6 +179,16 @@ func (r *PgUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User
 		&user.ImagePath,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeletedAt,
+		&user.ExpiresAt,
+		&user.EmailVerifiedAt,
+		&user.Locale,
+		&user.HideLastSeen,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Phone,
+		&user.UsernameChangedAt,
+		&user.DeactivatedAt,
 	)
 	if err != nil {
 		return nil, pg.WrapRepoError(op, err)
@@ -103,13 +201,15 @@ func (r *PgUserRepo) GetByUsername(ctx context.Context, username string) (*domai
 	const op = "pguser.GetByUsername"
 
 	query := `
-		SELECT id, email, username, password_hash, role, image_path, created_at, updated_at
+		SELECT id, public_id, COALESCE(email, ''), username, password_hash, role, image_path, created_at, updated_at, deleted_at, expires_at, email_verified_at, COALESCE(locale, ''),
+			hide_last_seen, COALESCE(display_name, ''), COALESCE(bio, ''), COALESCE(phone, ''), username_changed_at, deactivated_at
 		FROM users
-		WHERE username = $1`
+		WHERE lower(username) = lower($1) AND role != 'guest' AND deleted_at IS NULL`
 
 	user := &domain.User{}
 	err := r.pool.QueryRow(ctx, query, username).Scan(
 		&user.ID,
+		&user.PublicID,
 		&user.Email,
 		&user.Username,
 		&user.PasswordHash,
@@ -117,6 +217,16 @@ func (r *PgUserRepo) GetByUsername(ctx context.Context, username string) (*domai
 		&user.ImagePath,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeletedAt,
+		&user.ExpiresAt,
+		&user.EmailVerifiedAt,
+		&user.Locale,
+		&user.HideLastSeen,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Phone,
+		&user.UsernameChangedAt,
+		&user.DeactivatedAt,
 	)
 	if err != nil {
 		return nil, pg.WrapRepoError(op, err)
@@ -130,8 +240,10 @@ func (r *PgUserRepo) Update(ctx context.Context, user *domain.User) error {
 
 	query := `
 		UPDATE users
-		SET email = $1, username = $2, password_hash = $3, role = $4, image_path = $5, updated_at = $6
-		WHERE id = $7`
+		SET email = NULLIF($1, ''), username = $2, password_hash = $3, role = $4, image_path = $5, updated_at = $6,
+			locale = NULLIF($7, ''), hide_last_seen = $8, display_name = NULLIF($9, ''), bio = NULLIF($10, ''),
+			phone = NULLIF($11, ''), username_changed_at = $12
+		WHERE id = $13`
 
 	result, err := r.pool.Exec(
 		ctx,
@@ -142,9 +254,18 @@ func (r *PgUserRepo) Update(ctx context.Context, user *domain.User) error {
 		user.Role,
 		user.ImagePath,
 		user.UpdatedAt,
+		user.Locale,
+		user.HideLastSeen,
+		user.DisplayName,
+		user.Bio,
+		user.Phone,
+		user.UsernameChangedAt,
 		user.ID,
 	)
 	if err != nil {
+		if pg.ConstraintName(err) == usernameIndex {
+			return errs.Wrap(op, domain.ErrUsernameTaken)
+		}
 		return pg.WrapRepoError(op, err)
 	}
 
@@ -156,6 +277,144 @@ func (r *PgUserRepo) Update(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
+func (r *PgUserRepo) SoftDelete(ctx context.Context, id int, deletedAt time.Time) error {
+	const op = "pguser.SoftDelete"
+
+	query := `
+		UPDATE users
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, deletedAt, id)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	return nil
+}
+
+func (r *PgUserRepo) MarkEmailVerified(ctx context.Context, id int, verifiedAt time.Time) error {
+	const op = "pguser.MarkEmailVerified"
+
+	query := `
+		UPDATE users
+		SET email_verified_at = $2, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, id, verifiedAt)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgUserRepo) SoftDeleteExpiredGuests(ctx context.Context, now time.Time) ([]int, error) {
+	const op = "pguser.SoftDeleteExpiredGuests"
+
+	query := `
+		UPDATE users
+		SET deleted_at = $1, updated_at = $1
+		WHERE role = 'guest' AND expires_at <= $1 AND deleted_at IS NULL
+		RETURNING id`
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return ids, nil
+}
+
+func (r *PgUserRepo) Deactivate(ctx context.Context, id int, deactivatedAt time.Time) error {
+	const op = "pguser.Deactivate"
+
+	query := `
+		UPDATE users
+		SET deactivated_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL AND deactivated_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, deactivatedAt, id)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	return nil
+}
+
+func (r *PgUserRepo) AnonymizeDeactivated(ctx context.Context, deactivatedBefore, now time.Time) ([]int, []string, error) {
+	const op = "pguser.AnonymizeDeactivated"
+
+	// The CTE reads the image paths before they are cleared
+	query := `
+		WITH expired AS (
+			SELECT id, image_path
+			FROM users
+			WHERE deactivated_at <= $1 AND deleted_at IS NULL
+			FOR UPDATE
+		)
+		UPDATE users u
+		SET deleted_at = $2, updated_at = $2, email = NULL, username = $3, password_hash = '',
+			image_path = NULL, display_name = NULL, bio = NULL, phone = NULL
+		FROM expired e
+		WHERE u.id = e.id
+		RETURNING u.id, e.image_path`
+
+	rows, err := r.pool.Query(ctx, query, deactivatedBefore, now, domain.DeletedUsername)
+	if err != nil {
+		return nil, nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	images := make([]string, 0)
+	for rows.Next() {
+		var id int
+		var imagePath *string
+		if err := rows.Scan(&id, &imagePath); err != nil {
+			return nil, nil, pg.WrapRepoError(op, err)
+		}
+		ids = append(ids, id)
+		if imagePath != nil {
+			images = append(images, *imagePath)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, pg.WrapRepoError(op, err)
+	}
+
+	return ids, images, nil
+}
+
 func (r *PgUserRepo) Delete(ctx context.Context, id int) error {
 	const op = "pguser.Delete"
 
@@ -174,23 +433,30 @@ func (r *PgUserRepo) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-func (r *PgUserRepo) ListWithCount(ctx context.Context, offset, limit int) ([]*domain.User, int, error) {
+func (r *PgUserRepo) ListWithCount(ctx context.Context, workspaceID, offset, limit int) ([]*domain.User, int, error) {
 	const op = "pguser.ListWithCount"
 
 	var totalCount int
-	countQuery := `SELECT COUNT(*) FROM users`
-	err := r.pool.QueryRow(ctx, countQuery).Scan(&totalCount)
+	countQuery := `
+		SELECT COUNT(*)
+		FROM users u
+		INNER JOIN workspace_members wm ON u.id = wm.user_id AND wm.workspace_id = $1
+		WHERE u.deleted_at IS NULL AND u.deactivated_at IS NULL`
+	err := r.pool.QueryRow(ctx, countQuery, workspaceID).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
 
 	query := `
-		SELECT id, email, username, password_hash, role, image_path, created_at, updated_at
-		FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		SELECT u.id, u.public_id, COALESCE(u.email, ''), u.username, u.password_hash, u.role, u.image_path, u.created_at, u.updated_at, u.deleted_at, u.expires_at, u.email_verified_at, COALESCE(u.locale, ''),
+			u.hide_last_seen, COALESCE(u.display_name, ''), COALESCE(u.bio, ''), COALESCE(u.phone, ''), u.username_changed_at, u.deactivated_at
+		FROM users u
+		INNER JOIN workspace_members wm ON u.id = wm.user_id AND wm.workspace_id = $1
+		WHERE u.deleted_at IS NULL AND u.deactivated_at IS NULL
+		ORDER BY u.created_at DESC
+		LIMIT $2 OFFSET $3`
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, workspaceID, limit, offset)
 	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
@@ -201,6 +467,7 @@ func (r *PgUserRepo) ListWithCount(ctx context.Context, offset, limit int) ([]*d
 		user := &domain.User{}
 		err := rows.Scan(
 			&user.ID,
+			&user.PublicID,
 			&user.Email,
 			&user.Username,
 			&user.PasswordHash,
@@ -208,6 +475,16 @@ func (r *PgUserRepo) ListWithCount(ctx context.Context, offset, limit int) ([]*d
 			&user.ImagePath,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.DeletedAt,
+			&user.ExpiresAt,
+			&user.EmailVerifiedAt,
+			&user.Locale,
+			&user.HideLastSeen,
+			&user.DisplayName,
+			&user.Bio,
+			&user.Phone,
+			&user.UsernameChangedAt,
+			&user.DeactivatedAt,
 		)
 		if err != nil {
 			return nil, 0, pg.WrapRepoError(op, err)
@@ -222,26 +499,116 @@ func (r *PgUserRepo) ListWithCount(ctx context.Context, offset, limit int) ([]*d
 	return users, totalCount, nil
 }
 
-func (r *PgUserRepo) SearchByUsernameWithCount(ctx context.Context, username string, offset, limit int) ([]*domain.User, int, error) {
+func (r *PgUserRepo) SearchByUsernameWithCount(
+	ctx context.Context,
+	workspaceID int,
+	username string,
+	offset, limit int,
+) ([]*domain.User, int, error) {
 	const op = "pguser.SearchByUsernameWithCount"
 
 	searchPattern := "%" + username + "%"
 
 	var totalCount int
-	countQuery := `SELECT COUNT(*) FROM users WHERE username ILIKE $1`
-	err := r.pool.QueryRow(ctx, countQuery, searchPattern).Scan(&totalCount)
+	countQuery := `
+		SELECT COUNT(*)
+		FROM users u
+		INNER JOIN workspace_members wm ON u.id = wm.user_id AND wm.workspace_id = $1
+		WHERE u.username ILIKE $2 AND u.deleted_at IS NULL AND u.deactivated_at IS NULL`
+	err := r.pool.QueryRow(ctx, countQuery, workspaceID, searchPattern).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
 
 	query := `
-		SELECT id, email, username, password_hash, role, image_path, created_at, updated_at
-		FROM users
-		WHERE username ILIKE $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+		SELECT u.id, u.public_id, COALESCE(u.email, ''), u.username, u.password_hash, u.role, u.image_path, u.created_at, u.updated_at, u.deleted_at, u.expires_at, u.email_verified_at, COALESCE(u.locale, ''),
+			u.hide_last_seen, COALESCE(u.display_name, ''), COALESCE(u.bio, ''), COALESCE(u.phone, ''), u.username_changed_at, u.deactivated_at
+		FROM users u
+		INNER JOIN workspace_members wm ON u.id = wm.user_id AND wm.workspace_id = $1
+		WHERE u.username ILIKE $2 AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+		ORDER BY u.created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID, searchPattern, limit, offset)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.PublicID,
+			&user.Email,
+			&user.Username,
+			&user.PasswordHash,
+			&user.Role,
+			&user.ImagePath,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.DeletedAt,
+			&user.ExpiresAt,
+			&user.EmailVerifiedAt,
+			&user.Locale,
+			&user.HideLastSeen,
+			&user.DisplayName,
+			&user.Bio,
+			&user.Phone,
+			&user.UsernameChangedAt,
+			&user.DeactivatedAt,
+		)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	return users, totalCount, nil
+}
+
+func (r *PgUserRepo) ListContactsWithCount(
+	ctx context.Context,
+	workspaceID, userID int,
+	username string,
+	offset, limit int,
+) ([]*domain.User, int, error) {
+	const op = "pguser.ListContactsWithCount"
+
+	searchPattern := "%" + username + "%"
+
+	var totalCount int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM users u
+		INNER JOIN workspace_members wm ON u.id = wm.user_id AND wm.workspace_id = $1
+		INNER JOIN user_contacts uc ON uc.status = 'accepted' AND (
+			(uc.requester_id = $2 AND uc.addressee_id = u.id) OR (uc.addressee_id = $2 AND uc.requester_id = u.id)
+		)
+		WHERE u.username ILIKE $3 AND u.deleted_at IS NULL`
+	err := r.pool.QueryRow(ctx, countQuery, workspaceID, userID, searchPattern).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	query := `
+		SELECT u.id, u.public_id, COALESCE(u.email, ''), u.username, u.password_hash, u.role, u.image_path, u.created_at, u.updated_at, u.deleted_at, u.expires_at, u.email_verified_at, COALESCE(u.locale, ''),
+			u.hide_last_seen, COALESCE(u.display_name, ''), COALESCE(u.bio, ''), COALESCE(u.phone, ''), u.username_changed_at, u.deactivated_at
+		FROM users u
+		INNER JOIN workspace_members wm ON u.id = wm.user_id AND wm.workspace_id = $1
+		INNER JOIN user_contacts uc ON uc.status = 'accepted' AND (
+			(uc.requester_id = $2 AND uc.addressee_id = u.id) OR (uc.addressee_id = $2 AND uc.requester_id = u.id)
+		)
+		WHERE u.username ILIKE $3 AND u.deleted_at IS NULL
+		ORDER BY u.created_at DESC
+		LIMIT $4 OFFSET $5`
 
-	rows, err := r.pool.Query(ctx, query, searchPattern, limit, offset)
+	rows, err := r.pool.Query(ctx, query, workspaceID, userID, searchPattern, limit, offset)
 	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
@@ -252,6 +619,7 @@ func (r *PgUserRepo) SearchByUsernameWithCount(ctx context.Context, username str
 		user := &domain.User{}
 		err := rows.Scan(
 			&user.ID,
+			&user.PublicID,
 			&user.Email,
 			&user.Username,
 			&user.PasswordHash,
@@ -259,6 +627,16 @@ func (r *PgUserRepo) SearchByUsernameWithCount(ctx context.Context, username str
 			&user.ImagePath,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.DeletedAt,
+			&user.ExpiresAt,
+			&user.EmailVerifiedAt,
+			&user.Locale,
+			&user.HideLastSeen,
+			&user.DisplayName,
+			&user.Bio,
+			&user.Phone,
+			&user.UsernameChangedAt,
+			&user.DeactivatedAt,
 		)
 		if err != nil {
 			return nil, 0, pg.WrapRepoError(op, err)
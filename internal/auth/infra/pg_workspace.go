@@ -0,0 +1,238 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgWorkspaceRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgWorkspaceRepo(pool *pgxpool.Pool) *PgWorkspaceRepo {
+	return &PgWorkspaceRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgWorkspaceRepo) Create(ctx context.Context, workspace *domain.Workspace, creatorID int) error {
+	const op = "pgworkspace.Create"
+
+	// The creator's membership is inserted in the same statement so a
+	// workspace never exists without an admin
+	query := `
+		WITH ws AS (
+			INSERT INTO workspaces (slug, name, created_at)
+			VALUES ($1, $2, $3)
+			RETURNING id, public_id
+		), member AS (
+			INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+			SELECT id, $4, $5, $3 FROM ws
+		)
+		SELECT id, public_id FROM ws`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		workspace.Slug,
+		workspace.Name,
+		workspace.CreatedAt,
+		creatorID,
+		domain.WorkspaceRoleAdmin,
+	).Scan(&workspace.ID, &workspace.PublicID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgWorkspaceRepo) GetByID(ctx context.Context, id int) (*domain.Workspace, error) {
+	const op = "pgworkspace.GetByID"
+
+	query := `
+		SELECT id, public_id, slug, name, created_at
+		FROM workspaces
+		WHERE id = $1`
+
+	workspace := &domain.Workspace{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&workspace.ID,
+		&workspace.PublicID,
+		&workspace.Slug,
+		&workspace.Name,
+		&workspace.CreatedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return workspace, nil
+}
+
+func (r *PgWorkspaceRepo) GetBySlug(ctx context.Context, slug string) (*domain.Workspace, error) {
+	const op = "pgworkspace.GetBySlug"
+
+	query := `
+		SELECT id, public_id, slug, name, created_at
+		FROM workspaces
+		WHERE slug = $1`
+
+	workspace := &domain.Workspace{}
+	err := r.pool.QueryRow(ctx, query, slug).Scan(
+		&workspace.ID,
+		&workspace.PublicID,
+		&workspace.Slug,
+		&workspace.Name,
+		&workspace.CreatedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return workspace, nil
+}
+
+func (r *PgWorkspaceRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "pgworkspace.GetIDByPublicID"
+
+	query := `SELECT id FROM workspaces WHERE public_id = $1`
+
+	var id int
+	if err := r.pool.QueryRow(ctx, query, publicID).Scan(&id); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return id, nil
+}
+
+func (r *PgWorkspaceRepo) ListByUser(ctx context.Context, userID int) ([]domain.WorkspaceMembership, error) {
+	const op = "pgworkspace.ListByUser"
+
+	query := `
+		SELECT w.id, w.public_id, w.slug, w.name, w.created_at, wm.role, wm.joined_at
+		FROM workspaces w
+		INNER JOIN workspace_members wm ON w.id = wm.workspace_id
+		WHERE wm.user_id = $1
+		ORDER BY wm.joined_at ASC, w.id ASC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	memberships := make([]domain.WorkspaceMembership, 0)
+	for rows.Next() {
+		m := domain.WorkspaceMembership{}
+		err := rows.Scan(
+			&m.ID,
+			&m.PublicID,
+			&m.Slug,
+			&m.Name,
+			&m.CreatedAt,
+			&m.Role,
+			&m.JoinedAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		memberships = append(memberships, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return memberships, nil
+}
+
+func (r *PgWorkspaceRepo) ListMemberIDs(ctx context.Context, workspaceID int) ([]int, error) {
+	const op = "pgworkspace.ListMemberIDs"
+
+	query := `
+		SELECT wm.user_id
+		FROM workspace_members wm
+		INNER JOIN users u ON u.id = wm.user_id
+		WHERE wm.workspace_id = $1 AND u.deleted_at IS NULL`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]int, 0)
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return userIDs, nil
+}
+
+func (r *PgWorkspaceRepo) GetMember(ctx context.Context, workspaceID, userID int) (*domain.WorkspaceMember, error) {
+	const op = "pgworkspace.GetMember"
+
+	query := `
+		SELECT workspace_id, user_id, role, joined_at
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2`
+
+	member := &domain.WorkspaceMember{}
+	err := r.pool.QueryRow(ctx, query, workspaceID, userID).Scan(
+		&member.WorkspaceID,
+		&member.UserID,
+		&member.Role,
+		&member.JoinedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return member, nil
+}
+
+func (r *PgWorkspaceRepo) AddMember(ctx context.Context, member *domain.WorkspaceMember) error {
+	const op = "pgworkspace.AddMember"
+
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)`
+
+	_, err := r.pool.Exec(ctx, query, member.WorkspaceID, member.UserID, member.Role, member.JoinedAt)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgWorkspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID int) error {
+	const op = "pgworkspace.RemoveMember"
+
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	result, err := r.pool.Exec(ctx, query, workspaceID, userID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
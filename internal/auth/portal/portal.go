@@ -3,39 +3,125 @@ package portal
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
 	"chatx-01-backend/internal/auth/domain"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/reqctx"
 	"chatx-01-backend/pkg/token"
+	"chatx-01-backend/pkg/totp"
 )
 
 const (
 	authUserKey = "authenticated_user"
+
+	// termsVersionHeader carries the terms version the user still has to
+	// accept.
+	termsVersionHeader = "X-Terms-Version-Required"
+
+	// adminOTPHeader carries the one-time code of admin requests.
+	adminOTPHeader = "X-Admin-OTP"
+
+	// apiKeyHeader carries the API key of integrations, in place of a bearer
+	// token.
+	apiKeyHeader = "X-API-Key"
+
+	// apiKeyTouchInterval is how often the last use of an API key is written.
+	apiKeyTouchInterval = time.Minute
+)
+
+// TermsEnforcement is how requests from users who haven't accepted the
+// current terms are handled.
+type TermsEnforcement string
+
+const (
+	// TermsEnforcementOff ignores terms acceptance.
+	TermsEnforcementOff TermsEnforcement = "off"
+	// TermsEnforcementFlag serves the request with the required version in
+	// the X-Terms-Version-Required header.
+	TermsEnforcementFlag TermsEnforcement = "flag"
+	// TermsEnforcementBlock rejects the request with 403 Forbidden.
+	TermsEnforcementBlock TermsEnforcement = "block"
 )
 
+func (e TermsEnforcement) IsValid() bool {
+	return e == TermsEnforcementOff || e == TermsEnforcementFlag || e == TermsEnforcementBlock
+}
+
+// Config holds the terms of service policy and the admin protection applied
+// by the auth middlewares.
+type Config struct {
+	// TermsVersion is the version users must accept. Empty disables the check.
+	TermsVersion     string
+	TermsEnforcement TermsEnforcement
+
+	// AdminAllowedNets restricts RequireAdmin routes to clients in these
+	// ranges. Empty allows any address.
+	AdminAllowedNets []netip.Prefix
+	// AdminOTPKey is the TOTP key of the codes RequireAdmin routes require in
+	// the X-Admin-OTP header. Empty disables the check.
+	AdminOTPKey []byte
+}
+
 var (
-	errNoAuthUser = errors.New("no authenticated user found in context")
+	errNoAuthUser    = errors.New("no authenticated user found in context")
+	errInvalidAPIKey = errors.New("unauthorized: invalid or revoked api key")
+	errAPIKeyScope   = errors.New("forbidden: api key lacks the scope of this request")
 
 	// Interface guard.
 	_ auth.Portal = (*Portal)(nil)
 )
 
 type Portal struct {
-	userRepo     domain.UserRepository
-	tokenService *token.Service
+	userRepo      domain.UserRepository
+	termsRepo     domain.TermsRepository
+	workspaceRepo domain.WorkspaceRepository
+	spamRepo      domain.SpamRepository
+	blockRepo     domain.BlockRepository
+	apiKeyRepo    domain.APIKeyRepository
+	auditRepo     domain.AuditRepository
+	tokenService  *token.Service
+	cfg           Config
+	logger        *slog.Logger
+
+	// termsAccepted holds IDs of users known to have accepted the current
+	// terms version, which never changes while the process runs.
+	termsAccepted sync.Map
 }
 
 func New(
 	userRepo domain.UserRepository,
+	termsRepo domain.TermsRepository,
+	workspaceRepo domain.WorkspaceRepository,
+	spamRepo domain.SpamRepository,
+	blockRepo domain.BlockRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	auditRepo domain.AuditRepository,
 	tokenService *token.Service,
+	cfg Config,
+	logger *slog.Logger,
 ) *Portal {
 	return &Portal{
-		userRepo:     userRepo,
-		tokenService: tokenService,
+		userRepo:      userRepo,
+		termsRepo:     termsRepo,
+		workspaceRepo: workspaceRepo,
+		spamRepo:      spamRepo,
+		blockRepo:     blockRepo,
+		apiKeyRepo:    apiKeyRepo,
+		auditRepo:     auditRepo,
+		tokenService:  tokenService,
+		cfg:           cfg,
+		logger:        logger,
 	}
 }
 
@@ -48,6 +134,8 @@ func (p *Portal) GetAuthUser(ctx context.Context) (auth.AuthenticatedUser, error
 }
 
 func (p *Portal) SetAuthUser(ctx context.Context, au auth.AuthenticatedUser) context.Context {
+	// Expose the user to outer middlewares (access log, error reporting).
+	reqctx.SetUserID(ctx, au.ID)
 	return context.WithValue(ctx, authUserKey, au)
 }
 
@@ -60,13 +148,17 @@ func (p *Portal) GetUserByID(ctx context.Context, id int) (*auth.User, error) {
 		return nil, err
 	}
 
-	return &auth.User{
-		ID:        u.ID,
-		Email:     u.Email,
-		Username:  u.Username,
-		Role:      u.Role.String(),
-		ImagePath: u.ImagePath,
-	}, nil
+	return toPortalUser(u), nil
+}
+
+// UserLocale returns the preferred error message locale of a user, or "" if
+// they have none or the lookup fails.
+func (p *Portal) UserLocale(ctx context.Context, userID int) string {
+	u, err := p.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	return u.Locale
 }
 
 func (p *Portal) GetUsersByIDs(ctx context.Context, ids []int) ([]*auth.User, error) {
@@ -74,46 +166,136 @@ func (p *Portal) GetUsersByIDs(ctx context.Context, ids []int) ([]*auth.User, er
 		return []*auth.User{}, nil
 	}
 
-	users := make([]*auth.User, 0, len(ids))
-	for _, id := range ids {
-		u, err := p.userRepo.GetByID(ctx, id)
-		if err != nil {
-			// Skip users that don't exist
-			if errors.Is(err, pgx.ErrNoRows) {
-				continue
-			}
-			return nil, err
-		}
+	found, err := p.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
 
-		users = append(users, &auth.User{
-			ID:        u.ID,
-			Email:     u.Email,
-			Username:  u.Username,
-			Role:      u.Role.String(),
-			ImagePath: u.ImagePath,
-		})
+	users := make([]*auth.User, len(found))
+	for i, u := range found {
+		users[i] = toPortalUser(u)
 	}
 
 	return users, nil
 }
 
+func (p *Portal) GetUserByEmail(ctx context.Context, email string) (*auth.User, error) {
+	u, err := p.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPortalUser(u), nil
+}
+
 func (p *Portal) UserExists(ctx context.Context, id int) (bool, error) {
-	_, err := p.userRepo.GetByID(ctx, id)
+	u, err := p.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
 		}
 		return false, err
 	}
+	return !u.IsDeleted(), nil
+}
+
+func (p *Portal) IsWorkspaceMember(ctx context.Context, workspaceID, userID int) (bool, error) {
+	_, err := p.workspaceRepo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
 	return true, nil
 }
 
+func (p *Portal) GetWorkspaceMemberIDs(ctx context.Context, workspaceID int) ([]int, error) {
+	return p.workspaceRepo.ListMemberIDs(ctx, workspaceID)
+}
+
+func (p *Portal) AddWorkspaceMember(ctx context.Context, workspaceID, userID int) error {
+	err := p.workspaceRepo.AddMember(ctx, &domain.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        domain.WorkspaceRoleMember,
+		JoinedAt:    time.Now(),
+	})
+	if err != nil && !errors.Is(err, errs.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+func (p *Portal) ReportSpam(ctx context.Context, report auth.SpamReport) error {
+	action := domain.SpamActionFlag
+	if report.Shadow {
+		action = domain.SpamActionShadow
+	}
+
+	_, err := p.spamRepo.Flag(ctx, &domain.SpamFlag{
+		UserID:    report.UserID,
+		Reason:    domain.SpamReason(report.Reason),
+		Action:    action,
+		Details:   report.Details,
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+func (p *Portal) RecordAudit(ctx context.Context, event auth.AuditEvent) {
+	ip, userAgent := reqctx.Client(ctx)
+
+	err := p.auditRepo.Create(ctx, &domain.AuditEvent{
+		Action:       domain.AuditAction(event.Action),
+		ActorID:      event.ActorID,
+		TargetUserID: optionalID(event.TargetUserID),
+		ChatID:       optionalID(event.ChatID),
+		WorkspaceID:  optionalID(event.WorkspaceID),
+		IP:           ip,
+		UserAgent:    userAgent,
+		Details:      event.Details,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to record audit event",
+			"action", event.Action,
+			"actor_id", event.ActorID,
+			"error", err,
+		)
+	}
+}
+
+// optionalID returns nil for a zero ID.
+func optionalID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func (p *Portal) IsShadowLimited(ctx context.Context, userID int) (bool, error) {
+	return p.spamRepo.IsShadowLimited(ctx, userID)
+}
+
+func (p *Portal) IsBlocked(ctx context.Context, userID1, userID2 int) (bool, error) {
+	return p.blockRepo.IsBlocked(ctx, userID1, userID2)
+}
+
+func (p *Portal) GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error) {
+	return p.blockRepo.GetBlockedUserIDs(ctx, userID)
+}
+
 func (p *Portal) RequireAuth() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			au, err := p.authenticate(r)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusUnauthorized)
+				http.Error(w, err.Error(), authErrorStatus(err))
+				return
+			}
+
+			if !p.checkTerms(w, r, au) {
 				return
 			}
 
@@ -128,7 +310,7 @@ func (p *Portal) RequireAdmin() func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			au, err := p.authenticate(r)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusUnauthorized)
+				http.Error(w, err.Error(), authErrorStatus(err))
 				return
 			}
 
@@ -137,14 +319,176 @@ func (p *Portal) RequireAdmin() func(http.Handler) http.Handler {
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			// Admin requests need an interactive session
+			if au.APIKeyID != 0 {
+				http.Error(w, "forbidden: api keys can't be used for admin requests", http.StatusForbidden)
+				return
+			}
+
+			if !p.checkAdminAccess(w, r, au) {
+				return
+			}
+
+			if !p.checkTerms(w, r, au) {
+				return
+			}
+
+			ctx := p.SetAuthUser(r.Context(), au)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (p *Portal) RequireWorkspaceAdmin() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			au, err := p.authenticate(r)
+			if err != nil {
+				http.Error(w, err.Error(), authErrorStatus(err))
+				return
+			}
+
+			if au.Role != domain.RoleAdmin.String() && au.WorkspaceRole != domain.WorkspaceRoleAdmin.String() {
+				http.Error(w, "forbidden: insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			if !p.checkTerms(w, r, au) {
+				return
+			}
+
+			ctx := p.SetAuthUser(r.Context(), au)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (p *Portal) RequireMember() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			au, err := p.authenticate(r)
+			if err != nil {
+				http.Error(w, err.Error(), authErrorStatus(err))
+				return
+			}
+
+			if au.Role == domain.RoleGuest.String() {
+				http.Error(w, "forbidden: not available to guests", http.StatusForbidden)
+				return
+			}
+
+			if !p.checkTerms(w, r, au) {
+				return
+			}
+
+			ctx := p.SetAuthUser(r.Context(), au)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+func (p *Portal) RequireAuthPendingTerms() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			au, err := p.authenticate(r)
+			if err != nil {
+				http.Error(w, err.Error(), authErrorStatus(err))
+				return
+			}
+
+			ctx := p.SetAuthUser(r.Context(), au)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// checkTerms applies the terms policy to an authenticated request. It
+// reports false if the request was rejected.
+func (p *Portal) checkTerms(w http.ResponseWriter, r *http.Request, au auth.AuthenticatedUser) bool {
+	if p.cfg.TermsVersion == "" || p.cfg.TermsEnforcement == TermsEnforcementOff {
+		return true
+	}
+	if _, ok := p.termsAccepted.Load(au.ID); ok {
+		return true
+	}
+
+	_, err := p.termsRepo.Get(r.Context(), au.ID, p.cfg.TermsVersion)
+	if err == nil {
+		p.termsAccepted.Store(au.ID, struct{}{})
+		return true
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		httptools.HandleError(w, r, err)
+		return false
+	}
+
+	if p.cfg.TermsEnforcement == TermsEnforcementBlock {
+		httptools.HandleError(w, r, domain.ErrTermsNotAccepted)
+		return false
+	}
+
+	w.Header().Set(termsVersionHeader, p.cfg.TermsVersion)
+	return true
+}
+
+// checkAdminAccess applies the admin IP allowlist and one-time code to an
+// admin request. Denied attempts are recorded in the audit log. It reports false
+// if the request was rejected.
+func (p *Portal) checkAdminAccess(w http.ResponseWriter, r *http.Request, au auth.AuthenticatedUser) bool {
+	ip := httptools.ClientIP(r)
+
+	var denied error
+	switch {
+	case len(p.cfg.AdminAllowedNets) > 0 && !p.adminIPAllowed(ip):
+		denied = domain.ErrAdminIPNotAllowed
+	case len(p.cfg.AdminOTPKey) > 0 && !totp.Validate(p.cfg.AdminOTPKey, r.Header.Get(adminOTPHeader), time.Now()):
+		denied = domain.ErrAdminOTPInvalid
+	default:
+		return true
+	}
+
+	path := reqctx.LogPath(r.Context(), r.URL.Path)
+	p.logger.WarnContext(r.Context(), "admin access denied",
+		"audit", true,
+		"user_id", au.ID,
+		"ip", ip,
+		"method", r.Method,
+		"path", path,
+		"reason", denied.Error(),
+	)
+	p.RecordAudit(r.Context(), auth.AuditEvent{
+		Action:      string(domain.AuditAdminAccessDenied),
+		ActorID:     au.ID,
+		WorkspaceID: au.WorkspaceID,
+		Details:     fmt.Sprintf("%s %s: %s", r.Method, path, denied),
+	})
+	httptools.HandleError(w, r, denied)
+	return false
+}
+
+// adminIPAllowed reports whether ip is in one of the admin allowlist ranges.
+func (p *Portal) adminIPAllowed(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range p.cfg.AdminAllowedNets {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Portal) authenticate(r *http.Request) (auth.AuthenticatedUser, error) {
 	var au auth.AuthenticatedUser
 
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return p.authenticateAPIKey(r, key)
+	}
+
 	// Extract token from Authorization header
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
@@ -160,6 +504,61 @@ func (p *Portal) authenticate(r *http.Request) (auth.AuthenticatedUser, error) {
 	return p.ValidateToken(r.Context(), parts[1])
 }
 
+// authenticateAPIKey authenticates a request as the owner of an API key, in
+// the key's workspace, if the key has the scope the request needs.
+func (p *Portal) authenticateAPIKey(r *http.Request, rawKey string) (auth.AuthenticatedUser, error) {
+	var au auth.AuthenticatedUser
+	ctx := r.Context()
+
+	key, err := p.apiKeyRepo.GetByHash(ctx, domain.HashAPIKey(rawKey))
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			p.logger.ErrorContext(ctx, "failed to get api key", "error", err)
+		}
+		return au, errInvalidAPIKey
+	}
+	if key.IsRevoked() {
+		return au, errInvalidAPIKey
+	}
+
+	// The owner may have been deleted or left the workspace since
+	user, err := p.userRepo.GetByID(ctx, key.UserID)
+	if err != nil || user.IsDeleted() || user.IsDeactivated() {
+		return au, errInvalidAPIKey
+	}
+	member, err := p.workspaceRepo.GetMember(ctx, key.WorkspaceID, user.ID)
+	if err != nil {
+		return au, errInvalidAPIKey
+	}
+
+	if !key.HasScope(domain.RequiredAPIKeyScope(r.Method, r.URL.Path)) {
+		return au, errAPIKeyScope
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := p.apiKeyRepo.Touch(ctx, key.ID, now); err != nil {
+			p.logger.WarnContext(ctx, "failed to record api key use", "api_key_id", key.ID, "error", err)
+		}
+	}
+
+	au.ID = user.ID
+	au.Role = user.Role.String()
+	au.WorkspaceID = key.WorkspaceID
+	au.WorkspaceRole = member.Role.String()
+	au.APIKeyID = key.ID
+
+	return au, nil
+}
+
+// authErrorStatus returns the status of a failed authentication.
+func authErrorStatus(err error) int {
+	if errors.Is(err, errAPIKeyScope) {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
+
 func (p *Portal) ValidateToken(ctx context.Context, tokenString string) (auth.AuthenticatedUser, error) {
 	var au auth.AuthenticatedUser
 
@@ -176,6 +575,35 @@ func (p *Portal) ValidateToken(ctx context.Context, tokenString string) (auth.Au
 
 	au.ID = claims.UserID
 	au.Role = claims.Role
+	au.WorkspaceID = claims.WorkspaceID
+	au.WorkspaceRole = claims.WorkspaceRole
+	au.ExpiresAt = time.Unix(claims.Exp, 0)
 
 	return au, nil
 }
+
+// toPortalUser converts a domain user, replacing the profile of a deleted
+// user with an anonymous placeholder so their messages stay readable.
+func toPortalUser(u *domain.User) *auth.User {
+	if u.IsDeleted() {
+		return &auth.User{
+			ID:           u.ID,
+			PublicID:     u.PublicID,
+			Username:     domain.DeletedUsername,
+			Role:         u.Role.String(),
+			HideLastSeen: true,
+		}
+	}
+
+	return &auth.User{
+		ID:           u.ID,
+		PublicID:     u.PublicID,
+		Email:        u.Email,
+		Username:     u.Username,
+		Role:         u.Role.String(),
+		ImagePath:    u.ImagePath,
+		DisplayName:  u.DisplayName,
+		Bio:          u.Bio,
+		HideLastSeen: u.HideLastSeen,
+	}
+}
@@ -0,0 +1,82 @@
+package apikeyuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+type UseCase interface {
+	CreateAPIKey(ctx context.Context, req CreateAPIKeyReq) (*CreateAPIKeyResp, error)
+	ListAPIKeys(ctx context.Context, req ListAPIKeysReq) (*ListAPIKeysResp, error)
+	RevokeAPIKey(ctx context.Context, req RevokeAPIKeyReq) error
+}
+
+type CreateAPIKeyReq struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func (req CreateAPIKeyReq) Validate() error {
+	var verr error
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		verr = errs.AddFieldError(verr, "name", "name is required")
+	} else if utf8.RuneCountInString(name) > domain.MaxAPIKeyNameLength {
+		verr = errs.AddFieldError(verr, "name", "name must be 64 characters or less")
+	}
+
+	if len(req.Scopes) == 0 {
+		verr = errs.AddFieldError(verr, "scopes", "at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !domain.APIKeyScope(scope).IsValid() {
+			verr = errs.AddFieldError(verr, "scopes", "scopes must be chat:read, chat:write, account:read or account:write")
+			break
+		}
+	}
+
+	return verr
+}
+
+type CreateAPIKeyResp struct {
+	APIKeyDTO
+	Key string `json:"key"` // Shown only once
+}
+
+type ListAPIKeysReq struct{}
+
+func (req ListAPIKeysReq) Validate() error {
+	return nil
+}
+
+type ListAPIKeysResp struct {
+	Keys []APIKeyDTO `json:"keys"`
+}
+
+type APIKeyDTO struct {
+	KeyID       int      `json:"key_id"`
+	Name        string   `json:"name"`
+	Hint        string   `json:"hint"`
+	Scopes      []string `json:"scopes"`
+	WorkspaceID int      `json:"workspace_id"`
+	CreatedAt   string   `json:"created_at"`
+	LastUsedAt  *string  `json:"last_used_at"`
+}
+
+type RevokeAPIKeyReq struct {
+	KeyID int `path:"key_id"`
+}
+
+func (req RevokeAPIKeyReq) Validate() error {
+	var verr error
+
+	if req.KeyID <= 0 {
+		verr = errs.AddFieldError(verr, "key_id", "invalid key id")
+	}
+
+	return verr
+}
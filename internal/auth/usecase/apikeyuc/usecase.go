@@ -0,0 +1,175 @@
+package apikeyuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+type useCase struct {
+	apiKeyRepo domain.APIKeyRepository
+	authPr     auth.Portal
+	logger     *slog.Logger
+}
+
+func New(
+	apiKeyRepo domain.APIKeyRepository,
+	authPr auth.Portal,
+	logger *slog.Logger,
+) UseCase {
+	return &useCase{
+		apiKeyRepo: apiKeyRepo,
+		authPr:     authPr,
+		logger:     logger,
+	}
+}
+
+// CreateAPIKey creates an API key acting as the caller in the caller's
+// current workspace. The key itself is only returned here.
+func (uc *useCase) CreateAPIKey(ctx context.Context, req CreateAPIKeyReq) (*CreateAPIKeyResp, error) {
+	const op = "apikeyuc.CreateAPIKey"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	// A leaked key mustn't be able to mint more keys
+	if au.APIKeyID != 0 {
+		return nil, errs.Wrap(op, domain.ErrAPIKeyNotAllowed)
+	}
+
+	count, err := uc.apiKeyRepo.CountByUser(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if count >= domain.MaxAPIKeysPerUser {
+		return nil, errs.Wrap(op, domain.ErrAPIKeyLimit)
+	}
+
+	key, hint, hash, err := domain.NewAPIKey()
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	scopes := make([]domain.APIKeyScope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(scopes, domain.APIKeyScope(scope)) {
+			scopes = append(scopes, domain.APIKeyScope(scope))
+		}
+	}
+
+	apiKey := &domain.APIKey{
+		UserID:      au.ID,
+		WorkspaceID: au.WorkspaceID,
+		Name:        strings.TrimSpace(req.Name),
+		Hint:        hint,
+		KeyHash:     hash,
+		Scopes:      scopes,
+		CreatedAt:   time.Now(),
+	}
+	if err := uc.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	uc.logger.InfoContext(ctx, "api key created",
+		"audit", true,
+		"user_id", au.ID,
+		"api_key_id", apiKey.ID,
+		"workspace_id", apiKey.WorkspaceID,
+		"scopes", req.Scopes,
+	)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:      string(domain.AuditAPIKeyCreate),
+		ActorID:     au.ID,
+		WorkspaceID: apiKey.WorkspaceID,
+		Details:     fmt.Sprintf("key %d, scopes %s", apiKey.ID, strings.Join(req.Scopes, ",")),
+	})
+
+	return &CreateAPIKeyResp{
+		APIKeyDTO: toDTO(apiKey),
+		Key:       key,
+	}, nil
+}
+
+// ListAPIKeys lists the caller's unrevoked API keys, newest first.
+func (uc *useCase) ListAPIKeys(ctx context.Context, req ListAPIKeysReq) (*ListAPIKeysResp, error) {
+	const op = "apikeyuc.ListAPIKeys"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	keys, err := uc.apiKeyRepo.ListByUser(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	items := make([]APIKeyDTO, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, toDTO(key))
+	}
+
+	return &ListAPIKeysResp{
+		Keys: items,
+	}, nil
+}
+
+// RevokeAPIKey revokes one of the caller's API keys. Requests with the key
+// fail from then on.
+func (uc *useCase) RevokeAPIKey(ctx context.Context, req RevokeAPIKeyReq) error {
+	const op = "apikeyuc.RevokeAPIKey"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if au.APIKeyID != 0 {
+		return errs.Wrap(op, domain.ErrAPIKeyNotAllowed)
+	}
+
+	if err := uc.apiKeyRepo.Revoke(ctx, au.ID, req.KeyID, time.Now()); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("key_id", "api key not found"))
+	}
+
+	uc.logger.InfoContext(ctx, "api key revoked",
+		"audit", true,
+		"user_id", au.ID,
+		"api_key_id", req.KeyID,
+	)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:      string(domain.AuditAPIKeyRevoke),
+		ActorID:     au.ID,
+		WorkspaceID: au.WorkspaceID,
+		Details:     fmt.Sprintf("key %d", req.KeyID),
+	})
+
+	return nil
+}
+
+func toDTO(key *domain.APIKey) APIKeyDTO {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	dto := APIKeyDTO{
+		KeyID:       key.ID,
+		Name:        key.Name,
+		Hint:        key.Hint,
+		Scopes:      scopes,
+		WorkspaceID: key.WorkspaceID,
+		CreatedAt:   key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.LastUsedAt != nil {
+		lastUsedAt := key.LastUsedAt.Format(time.RFC3339)
+		dto.LastUsedAt = &lastUsedAt
+	}
+	return dto
+}
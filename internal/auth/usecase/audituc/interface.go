@@ -0,0 +1,71 @@
+package audituc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"time"
+)
+
+type UseCase interface {
+	GetAuditEvents(ctx context.Context, req GetAuditEventsReq) (*GetAuditEventsResp, error)
+}
+
+type GetAuditEventsReq struct {
+	Action       string     `query:"action"`
+	ActorID      int        `query:"actor_id"`
+	TargetUserID int        `query:"target_user_id"`
+	ChatID       int        `query:"chat_id"`
+	From         *time.Time `query:"from"` // Inclusive
+	To           *time.Time `query:"to"`   // Exclusive
+	Page         int        `query:"page"`
+	Limit        int        `query:"limit"`
+}
+
+func (req GetAuditEventsReq) Validate() error {
+	var verr error
+
+	if req.Action != "" && !domain.AuditAction(req.Action).IsValid() {
+		verr = errs.AddFieldError(verr, "action", "unknown audit action")
+	}
+	if req.ActorID < 0 {
+		verr = errs.AddFieldError(verr, "actor_id", "invalid user id")
+	}
+	if req.TargetUserID < 0 {
+		verr = errs.AddFieldError(verr, "target_user_id", "invalid user id")
+	}
+	if req.ChatID < 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		verr = errs.AddFieldError(verr, "to", "to must be after from")
+	}
+	if req.Page < 0 {
+		verr = errs.AddFieldError(verr, "page", "page must be non-negative")
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
+	}
+
+	return verr
+}
+
+type GetAuditEventsResp struct {
+	Events []AuditEventDTO `json:"events"`
+	Total  int             `json:"total"`
+	Page   int             `json:"page"`
+	Limit  int             `json:"limit"`
+}
+
+type AuditEventDTO struct {
+	EventID      int    `json:"event_id"`
+	Action       string `json:"action"`
+	ActorID      int    `json:"actor_id"`
+	TargetUserID *int   `json:"target_user_id"`
+	ChatID       *int   `json:"chat_id"`
+	WorkspaceID  *int   `json:"workspace_id"`
+	IP           string `json:"ip"`
+	UserAgent    string `json:"user_agent"`
+	Details      string `json:"details"`
+	CreatedAt    string `json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package audituc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"time"
+)
+
+type useCase struct {
+	auditRepo domain.AuditRepository
+}
+
+func New(auditRepo domain.AuditRepository) UseCase {
+	return &useCase{
+		auditRepo: auditRepo,
+	}
+}
+
+// GetAuditEvents lists audit events for admin review, newest first.
+func (uc *useCase) GetAuditEvents(ctx context.Context, req GetAuditEventsReq) (*GetAuditEventsResp, error) {
+	const op = "audituc.GetAuditEvents"
+
+	filter := domain.AuditFilter{
+		Action:       domain.AuditAction(req.Action),
+		ActorID:      req.ActorID,
+		TargetUserID: req.TargetUserID,
+		ChatID:       req.ChatID,
+		From:         req.From,
+		To:           req.To,
+	}
+
+	events, total, err := uc.auditRepo.ListWithCount(ctx, filter, req.Page*req.Limit, req.Limit)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]AuditEventDTO, 0, len(events))
+	for _, event := range events {
+		dtos = append(dtos, AuditEventDTO{
+			EventID:      event.ID,
+			Action:       string(event.Action),
+			ActorID:      event.ActorID,
+			TargetUserID: event.TargetUserID,
+			ChatID:       event.ChatID,
+			WorkspaceID:  event.WorkspaceID,
+			IP:           event.IP,
+			UserAgent:    event.UserAgent,
+			Details:      event.Details,
+			CreatedAt:    event.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &GetAuditEventsResp{
+		Events: dtos,
+		Total:  total,
+		Page:   req.Page,
+		Limit:  req.Limit,
+	}, nil
+}
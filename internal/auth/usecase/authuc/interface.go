@@ -3,33 +3,62 @@ package authuc
 import (
 	"chatx-01-backend/internal/auth/domain"
 	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/token"
 	"chatx-01-backend/pkg/val"
 	"context"
+	"strings"
 )
 
 type UseCase interface {
 	Login(ctx context.Context, req LoginReq) (*LoginResp, error)
 	Logout(ctx context.Context, req LogoutReq) error
+	Refresh(ctx context.Context, req RefreshReq) (*RefreshResp, error)
+	GetJWKS(ctx context.Context, req GetJWKSReq) (*GetJWKSResp, error)
 }
 
 type LoginReq struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	// Identifier is a username or an email address. Older clients send
+	// Username or Email instead.
+	Identifier string `json:"identifier"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	Workspace  string `json:"workspace"` // Optional slug, defaults to the user's first workspace
 }
 
 func (req LoginReq) Validate() error {
 	var verr error
 
-	if err := val.ValidateUsername(req.Username); err != nil {
-		verr = errs.AddFieldError(verr, "username", err.Error())
+	if req.Identifier == "" && req.Username == "" && req.Email == "" {
+		verr = errs.AddFieldError(verr, "identifier", "username or email is required")
 	}
 	if req.Password == "" {
 		verr = errs.AddFieldError(verr, "password", "password is required")
 	}
+	if req.Workspace != "" {
+		if err := val.ValidateSlug(req.Workspace); err != nil {
+			verr = errs.AddFieldError(verr, "workspace", err.Error())
+		}
+	}
 
 	return verr
 }
 
+// LoginIdentifier returns the username or email address the user logs in
+// with, and whether it is an email address.
+func (req LoginReq) LoginIdentifier() (identifier string, isEmail bool) {
+	switch {
+	case req.Identifier != "":
+		identifier = strings.TrimSpace(req.Identifier)
+		// Usernames can't contain "@"
+		return identifier, strings.Contains(identifier, "@")
+	case req.Email != "":
+		return strings.TrimSpace(req.Email), true
+	default:
+		return strings.TrimSpace(req.Username), false
+	}
+}
+
 type LoginResp struct {
 	UserID       int             `json:"user_id"`
 	Username     string          `json:"username"`
@@ -38,6 +67,10 @@ type LoginResp struct {
 	ImagePath    *string         `json:"image_path"`
 	AccessToken  string          `json:"access_token"`
 	RefreshToken string          `json:"refresh_token"`
+
+	WorkspaceID   int                  `json:"workspace_id"`
+	WorkspaceSlug string               `json:"workspace_slug"`
+	WorkspaceRole domain.WorkspaceRole `json:"workspace_role"`
 }
 
 type LogoutReq struct {
@@ -47,3 +80,35 @@ type LogoutReq struct {
 func (req LogoutReq) Validate() error {
 	return nil
 }
+
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (req RefreshReq) Validate() error {
+	var verr error
+
+	if req.RefreshToken == "" {
+		verr = errs.AddFieldError(verr, "refresh_token", "refresh token is required")
+	}
+
+	return verr
+}
+
+type RefreshResp struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+
+	WorkspaceID   int                  `json:"workspace_id"`
+	WorkspaceRole domain.WorkspaceRole `json:"workspace_role"`
+}
+
+type GetJWKSReq struct{}
+
+func (req GetJWKSReq) Validate() error {
+	return nil
+}
+
+type GetJWKSResp struct {
+	Keys []token.JWK `json:"keys"`
+}
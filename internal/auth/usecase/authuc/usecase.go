@@ -1,36 +1,77 @@
 package authuc
 
 import (
+	"chatx-01-backend/internal/analytics"
 	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/portal/auth"
 	"chatx-01-backend/pkg/errs"
 	"chatx-01-backend/pkg/hasher"
 	"chatx-01-backend/pkg/token"
 	"context"
+	"errors"
 )
 
+// Config holds login settings.
+type Config struct {
+	// RequireEmailVerification blocks login until the user verifies their email.
+	RequireEmailVerification bool
+}
+
 type useCase struct {
 	userRepo       domain.UserRepository
+	workspaceRepo  domain.WorkspaceRepository
 	passwordHasher hasher.Hasher
 	tokenService   *token.Service
+	analytics      analytics.Publisher
+	authPr         auth.Portal
+	cfg            Config
+
+	// dummyHash is compared against when no user matches a login, so the
+	// response time doesn't reveal whether an account exists
+	dummyHash string
 }
 
 func New(
 	userRepo domain.UserRepository,
+	workspaceRepo domain.WorkspaceRepository,
 	passwordHasher hasher.Hasher,
 	tokenService *token.Service,
+	analyticsPr analytics.Publisher,
+	authPr auth.Portal,
+	cfg Config,
 ) UseCase {
+	// Hashing can only fail to read random bytes; comparing against an empty
+	// hash then fails quickly, which is no worse than before
+	dummyHash, _ := passwordHasher.Hash("chatx dummy password")
+
 	return &useCase{
 		userRepo:       userRepo,
+		workspaceRepo:  workspaceRepo,
 		passwordHasher: passwordHasher,
 		tokenService:   tokenService,
+		analytics:      analyticsPr,
+		authPr:         authPr,
+		cfg:            cfg,
+		dummyHash:      dummyHash,
 	}
 }
 
 func (uc *useCase) Login(ctx context.Context, req LoginReq) (*LoginResp, error) {
 	const op = "authuc.Login"
 
-	user, err := uc.userRepo.GetByUsername(ctx, req.Username)
+	identifier, isEmail := req.LoginIdentifier()
+
+	var user *domain.User
+	var err error
+	if isEmail {
+		user, err = uc.userRepo.GetByEmail(ctx, identifier)
+	} else {
+		user, err = uc.userRepo.GetByUsername(ctx, identifier)
+	}
 	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			_ = uc.passwordHasher.Compare(uc.dummyHash, req.Password)
+		}
 		return nil, errs.ReplaceOn(err, errs.ErrNotFound, domain.ErrInvalidCredentials)
 	}
 
@@ -39,18 +80,51 @@ func (uc *useCase) Login(ctx context.Context, req LoginReq) (*LoginResp, error)
 		return nil, errs.Wrap(op, domain.ErrInvalidCredentials)
 	}
 
+	// Checked after the password so the response doesn't reveal whether an
+	// unverified or deactivated account exists
+	if user.IsDeactivated() {
+		return nil, errs.Wrap(op, domain.ErrAccountDeactivated)
+	}
+	if uc.cfg.RequireEmailVerification && !user.IsEmailVerified() {
+		return nil, errs.Wrap(op, domain.ErrEmailNotVerified)
+	}
+
+	membership, err := uc.loginWorkspace(ctx, user.ID, req.Workspace)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	sub := token.Subject{
+		UserID:        user.ID,
+		Role:          user.Role.String(),
+		WorkspaceID:   membership.ID,
+		WorkspaceRole: membership.Role.String(),
+	}
+
 	// Generate and store access token in Redis
-	accessToken, err := uc.tokenService.GenerateAndStore(ctx, user.ID, user.Role.String(), token.TokenTypeAccess)
+	accessToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeAccess)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
 
 	// Generate and store refresh token in Redis
-	refreshToken, err := uc.tokenService.GenerateAndStore(ctx, user.ID, user.Role.String(), token.TokenTypeRefresh)
+	refreshToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeRefresh)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
 
+	uc.analytics.Track(ctx, analytics.Event{
+		Type:        analytics.EventLogin,
+		UserID:      user.ID,
+		WorkspaceID: membership.ID,
+		Properties:  map[string]string{"role": user.Role.String()},
+	})
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:      string(domain.AuditLogin),
+		ActorID:     user.ID,
+		WorkspaceID: membership.ID,
+	})
+
 	return &LoginResp{
 		UserID:       user.ID,
 		Username:     user.Username,
@@ -59,12 +133,21 @@ func (uc *useCase) Login(ctx context.Context, req LoginReq) (*LoginResp, error)
 		ImagePath:    user.ImagePath,
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+
+		WorkspaceID:   membership.ID,
+		WorkspaceSlug: membership.Slug,
+		WorkspaceRole: membership.Role,
 	}, nil
 }
 
 func (uc *useCase) Logout(ctx context.Context, req LogoutReq) error {
 	const op = "authuc.Logout"
 
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
 	// Revoke access token
 	if req.AccessToken != "" {
 		err := uc.tokenService.Revoke(ctx, req.AccessToken)
@@ -73,5 +156,86 @@ func (uc *useCase) Logout(ctx context.Context, req LogoutReq) error {
 		}
 	}
 
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:      string(domain.AuditLogout),
+		ActorID:     au.ID,
+		WorkspaceID: au.WorkspaceID,
+	})
+
 	return nil
 }
+
+// Refresh exchanges a refresh token for a new token pair. The refresh token
+// is consumed, so each one can be used once; reusing one signs the user out
+// everywhere. Role changes since the token was issued are picked up.
+func (uc *useCase) Refresh(ctx context.Context, req RefreshReq) (*RefreshResp, error) {
+	const op = "authuc.Refresh"
+
+	claims, err := uc.tokenService.Consume(ctx, req.RefreshToken, token.TokenTypeRefresh)
+	if err != nil {
+		return nil, errs.Wrap(op, domain.ErrInvalidRefreshToken)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, domain.ErrInvalidRefreshToken)
+	}
+	if user.IsDeleted() {
+		return nil, errs.Wrap(op, domain.ErrInvalidRefreshToken)
+	}
+
+	member, err := uc.workspaceRepo.GetMember(ctx, claims.WorkspaceID, user.ID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, domain.ErrNotWorkspaceMember)
+	}
+
+	sub := token.Subject{
+		UserID:        user.ID,
+		Role:          user.Role.String(),
+		WorkspaceID:   member.WorkspaceID,
+		WorkspaceRole: member.Role.String(),
+	}
+
+	accessToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeAccess)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	refreshToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeRefresh)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &RefreshResp{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+
+		WorkspaceID:   member.WorkspaceID,
+		WorkspaceRole: member.Role,
+	}, nil
+}
+
+// loginWorkspace returns the user's membership of the workspace with the
+// slug, or of their first workspace if the slug is empty.
+func (uc *useCase) loginWorkspace(ctx context.Context, userID int, slug string) (*domain.WorkspaceMembership, error) {
+	memberships, err := uc.workspaceRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range memberships {
+		if slug == "" || memberships[i].Slug == slug {
+			return &memberships[i], nil
+		}
+	}
+
+	return nil, domain.ErrNotWorkspaceMember
+}
+
+// GetJWKS returns the public keys tokens are verified with. It is empty when
+// tokens are signed with the HMAC secret.
+func (uc *useCase) GetJWKS(ctx context.Context, req GetJWKSReq) (*GetJWKSResp, error) {
+	return &GetJWKSResp{
+		Keys: uc.tokenService.JWKS(),
+	}, nil
+}
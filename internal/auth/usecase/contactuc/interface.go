@@ -0,0 +1,111 @@
+package contactuc
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"context"
+)
+
+type UseCase interface {
+	SendContactRequest(ctx context.Context, req SendContactRequestReq) (*SendContactRequestResp, error)
+	AcceptContactRequest(ctx context.Context, req AcceptContactRequestReq) error
+	DeclineContactRequest(ctx context.Context, req DeclineContactRequestReq) error
+	GetContactRequests(ctx context.Context, req GetContactRequestsReq) (*GetContactRequestsResp, error)
+	GetContacts(ctx context.Context, req GetContactsReq) (*GetContactsResp, error)
+	RemoveContact(ctx context.Context, req RemoveContactReq) error
+}
+
+type SendContactRequestReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req SendContactRequestReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type SendContactRequestResp struct {
+	Status string `json:"status"` // pending, or accepted if the user had asked too
+}
+
+type AcceptContactRequestReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req AcceptContactRequestReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type DeclineContactRequestReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req DeclineContactRequestReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type GetContactRequestsReq struct{}
+
+func (req GetContactRequestsReq) Validate() error {
+	return nil
+}
+
+type GetContactRequestsResp struct {
+	Requests []ContactRequestDTO `json:"requests"`
+}
+
+type ContactRequestDTO struct {
+	UserID      int     `json:"user_id"`
+	PublicID    string  `json:"public_id"`
+	Username    string  `json:"username"`
+	ImagePath   *string `json:"image_path"`
+	RequestedAt string  `json:"requested_at"`
+}
+
+type GetContactsReq struct{}
+
+func (req GetContactsReq) Validate() error {
+	return nil
+}
+
+type GetContactsResp struct {
+	Contacts []ContactDTO `json:"contacts"`
+}
+
+type ContactDTO struct {
+	UserID    int     `json:"user_id"`
+	PublicID  string  `json:"public_id"`
+	Username  string  `json:"username"`
+	ImagePath *string `json:"image_path"`
+	Since     string  `json:"since"`
+}
+
+type RemoveContactReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req RemoveContactReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
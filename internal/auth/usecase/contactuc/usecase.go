@@ -0,0 +1,373 @@
+package contactuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/events"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/internal/portal/chat"
+	"chatx-01-backend/pkg/errs"
+	eventbus "chatx-01-backend/pkg/events"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type useCase struct {
+	contactRepo     domain.ContactRepository
+	userRepo        domain.UserRepository
+	blockRepo       domain.BlockRepository
+	authPr          auth.Portal
+	chatPr          chat.Portal
+	requestProducer eventbus.Producer
+	logger          *slog.Logger
+}
+
+func New(
+	contactRepo domain.ContactRepository,
+	userRepo domain.UserRepository,
+	blockRepo domain.BlockRepository,
+	authPr auth.Portal,
+	chatPr chat.Portal,
+	requestProducer eventbus.Producer,
+	logger *slog.Logger,
+) UseCase {
+	return &useCase{
+		contactRepo:     contactRepo,
+		userRepo:        userRepo,
+		blockRepo:       blockRepo,
+		authPr:          authPr,
+		chatPr:          chatPr,
+		requestProducer: requestProducer,
+		logger:          logger,
+	}
+}
+
+// SendContactRequest asks a member of the caller's workspace to become the
+// caller's contact. If the user already asked the caller, their request is
+// accepted instead.
+func (uc *useCase) SendContactRequest(
+	ctx context.Context,
+	req SendContactRequestReq,
+) (*SendContactRequestResp, error) {
+	const op = "contactuc.SendContactRequest"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if req.UserID == au.ID {
+		return nil, errs.AddFieldError(nil, "user_id", "you can't add yourself as a contact")
+	}
+
+	user, err := uc.workspaceUser(ctx, au.WorkspaceID, req.UserID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	blocked, err := uc.blockRepo.IsBlocked(ctx, au.ID, user.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if blocked {
+		return nil, errs.Wrap(op, domain.ErrUserBlocked)
+	}
+
+	existing, err := uc.contactRepo.Get(ctx, au.ID, user.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, errs.Wrap(op, err)
+	}
+	if existing != nil {
+		if existing.Status == domain.ContactStatusPending && existing.RequesterID == user.ID {
+			if err := uc.accept(ctx, user.ID, au.ID); err != nil {
+				return nil, errs.Wrap(op, err)
+			}
+			return &SendContactRequestResp{Status: string(domain.ContactStatusAccepted)}, nil
+		}
+		// Asking again or asking a contact is a no-op
+		return &SendContactRequestResp{Status: string(existing.Status)}, nil
+	}
+
+	contact := &domain.Contact{
+		RequesterID: au.ID,
+		AddresseeID: user.ID,
+		Status:      domain.ContactStatusPending,
+		CreatedAt:   time.Now(),
+	}
+	if err := uc.contactRepo.Create(ctx, contact); err != nil {
+		return nil, errs.ReplaceOn(
+			err,
+			errs.ErrAlreadyExists,
+			errs.NewConflictError("user_id", "contact request already exists"),
+		)
+	}
+
+	requester, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if err := uc.chatPr.NotifyContactRequested(ctx, user.ID, profile(requester)); err != nil {
+		uc.logger.ErrorContext(ctx, "failed to notify contact request", "user_id", user.ID, "error", err)
+	}
+	if err := uc.sendContactRequestEmail(ctx, user, requester, contact.CreatedAt); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &SendContactRequestResp{
+		Status: string(contact.Status),
+	}, nil
+}
+
+// AcceptContactRequest accepts the pending request of a user to the caller.
+func (uc *useCase) AcceptContactRequest(ctx context.Context, req AcceptContactRequestReq) error {
+	const op = "contactuc.AcceptContactRequest"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	if err := uc.accept(ctx, req.UserID, au.ID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "contact request not found"))
+	}
+
+	return nil
+}
+
+// DeclineContactRequest drops the pending request of a user to the caller.
+// The user isn't told.
+func (uc *useCase) DeclineContactRequest(ctx context.Context, req DeclineContactRequestReq) error {
+	const op = "contactuc.DeclineContactRequest"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	errNotFound := errs.NewNotFoundError("user_id", "contact request not found")
+
+	contact, err := uc.contactRepo.Get(ctx, req.UserID, au.ID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errNotFound)
+	}
+	if contact.Status != domain.ContactStatusPending || contact.AddresseeID != au.ID {
+		return errNotFound
+	}
+
+	if err := uc.contactRepo.Delete(ctx, req.UserID, au.ID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errNotFound)
+	}
+
+	return nil
+}
+
+// GetContactRequests lists the pending requests sent to the caller, newest
+// first.
+func (uc *useCase) GetContactRequests(ctx context.Context, req GetContactRequestsReq) (*GetContactRequestsResp, error) {
+	const op = "contactuc.GetContactRequests"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	contacts, err := uc.contactRepo.ListIncoming(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	users, err := uc.usersOf(ctx, au.ID, contacts)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	requests := make([]ContactRequestDTO, 0, len(contacts))
+	for _, contact := range contacts {
+		user, ok := users[contact.RequesterID]
+		if !ok || user.IsDeleted() {
+			continue
+		}
+		requests = append(requests, ContactRequestDTO{
+			UserID:      user.ID,
+			PublicID:    user.PublicID,
+			Username:    user.Username,
+			ImagePath:   user.ImagePath,
+			RequestedAt: contact.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &GetContactRequestsResp{
+		Requests: requests,
+	}, nil
+}
+
+// GetContacts lists the caller's contacts, most recently added first.
+func (uc *useCase) GetContacts(ctx context.Context, req GetContactsReq) (*GetContactsResp, error) {
+	const op = "contactuc.GetContacts"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	contacts, err := uc.contactRepo.ListContacts(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	users, err := uc.usersOf(ctx, au.ID, contacts)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	items := make([]ContactDTO, 0, len(contacts))
+	for _, contact := range contacts {
+		user, ok := users[contact.OtherID(au.ID)]
+		if !ok || user.IsDeleted() {
+			continue
+		}
+		items = append(items, ContactDTO{
+			UserID:    user.ID,
+			PublicID:  user.PublicID,
+			Username:  user.Username,
+			ImagePath: user.ImagePath,
+			Since:     contact.AcceptedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &GetContactsResp{
+		Contacts: items,
+	}, nil
+}
+
+// RemoveContact removes a contact of the caller, or withdraws the caller's
+// pending request to the user.
+func (uc *useCase) RemoveContact(ctx context.Context, req RemoveContactReq) error {
+	const op = "contactuc.RemoveContact"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	errNotFound := errs.NewNotFoundError("user_id", "contact not found")
+
+	contact, err := uc.contactRepo.Get(ctx, au.ID, req.UserID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errNotFound)
+	}
+	// Requests sent to the caller are declined instead
+	if contact.Status == domain.ContactStatusPending && contact.RequesterID != au.ID {
+		return errNotFound
+	}
+
+	if err := uc.contactRepo.Delete(ctx, au.ID, req.UserID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errNotFound)
+	}
+
+	return nil
+}
+
+// accept accepts the pending request of requesterID to addresseeID and tells
+// the requester.
+func (uc *useCase) accept(ctx context.Context, requesterID, addresseeID int) error {
+	if err := uc.contactRepo.Accept(ctx, requesterID, addresseeID, time.Now()); err != nil {
+		return err
+	}
+
+	addressee, err := uc.userRepo.GetByID(ctx, addresseeID)
+	if err != nil {
+		return err
+	}
+	if err := uc.chatPr.NotifyContactAccepted(ctx, requesterID, profile(addressee)); err != nil {
+		uc.logger.ErrorContext(ctx, "failed to notify accepted contact request", "user_id", requesterID, "error", err)
+	}
+
+	return nil
+}
+
+// workspaceUser returns an active member of a workspace.
+func (uc *useCase) workspaceUser(ctx context.Context, workspaceID, userID int) (*domain.User, error) {
+	errNotFound := errs.NewNotFoundError("user_id", "user not found")
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errNotFound)
+	}
+	if user.IsDeleted() {
+		return nil, errNotFound
+	}
+
+	isMember, err := uc.authPr.IsWorkspaceMember(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errNotFound
+	}
+
+	return user, nil
+}
+
+// usersOf returns the users on the other side of the contacts of a user by ID.
+func (uc *useCase) usersOf(ctx context.Context, userID int, contacts []domain.Contact) (map[int]*domain.User, error) {
+	ids := make([]int, len(contacts))
+	for i, contact := range contacts {
+		ids[i] = contact.OtherID(userID)
+	}
+
+	users, err := uc.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*domain.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+// sendContactRequestEmail queues an email telling a user about a contact
+// request. Users without an email address, such as guests, are skipped.
+func (uc *useCase) sendContactRequestEmail(
+	ctx context.Context,
+	addressee, requester *domain.User,
+	requestedAt time.Time,
+) error {
+	if addressee.Email == "" {
+		return nil
+	}
+
+	event := events.ContactRequestedEvent{
+		Email:         addressee.Email,
+		RequesterName: requester.Username,
+		RequestedAt:   requestedAt,
+	}
+
+	eventData, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = uc.requestProducer.SendMessage(ctx, &eventbus.Message{
+		Key:   []byte(addressee.Email),
+		Value: eventData,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send contact request event: %w", err)
+	}
+
+	return nil
+}
+
+func profile(user *domain.User) chat.UserProfile {
+	return chat.UserProfile{
+		ID:          user.ID,
+		PublicID:    user.PublicID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		ImagePath:   user.ImagePath,
+	}
+}
@@ -0,0 +1,68 @@
+package spamuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"context"
+)
+
+type UseCase interface {
+	GetSpamFlags(ctx context.Context, req GetSpamFlagsReq) (*GetSpamFlagsResp, error)
+	ResolveSpamFlag(ctx context.Context, req ResolveSpamFlagReq) (*SpamFlagDTO, error)
+}
+
+type GetSpamFlagsReq struct {
+	Status string `query:"status"` // open (default), resolved or all
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+}
+
+func (req GetSpamFlagsReq) Validate() error {
+	var verr error
+
+	if req.Status != "" && !domain.SpamFlagStatus(req.Status).IsValid() {
+		verr = errs.AddFieldError(verr, "status", "status must be open, resolved or all")
+	}
+	if req.Page < 0 {
+		verr = errs.AddFieldError(verr, "page", "page must be non-negative")
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
+	}
+
+	return verr
+}
+
+type GetSpamFlagsResp struct {
+	Flags []SpamFlagDTO `json:"flags"`
+	Total int           `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+}
+
+type SpamFlagDTO struct {
+	FlagID     int     `json:"flag_id"`
+	UserID     int     `json:"user_id"`
+	Username   string  `json:"username"`
+	Email      string  `json:"email"`
+	Reason     string  `json:"reason"`
+	Action     string  `json:"action"`
+	Details    string  `json:"details"`
+	CreatedAt  string  `json:"created_at"`
+	ResolvedAt *string `json:"resolved_at"`
+	ResolvedBy *int    `json:"resolved_by"`
+}
+
+type ResolveSpamFlagReq struct {
+	FlagID int `path:"flag_id"`
+}
+
+func (req ResolveSpamFlagReq) Validate() error {
+	var verr error
+
+	if req.FlagID <= 0 {
+		verr = errs.AddFieldError(verr, "flag_id", "invalid flag id")
+	}
+
+	return verr
+}
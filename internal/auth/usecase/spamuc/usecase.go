@@ -0,0 +1,108 @@
+package spamuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"time"
+)
+
+type useCase struct {
+	spamRepo domain.SpamRepository
+	userRepo domain.UserRepository
+	authPr   auth.Portal
+}
+
+func New(
+	spamRepo domain.SpamRepository,
+	userRepo domain.UserRepository,
+	authPr auth.Portal,
+) UseCase {
+	return &useCase{
+		spamRepo: spamRepo,
+		userRepo: userRepo,
+		authPr:   authPr,
+	}
+}
+
+// GetSpamFlags lists spam flags for admin review, newest first.
+func (uc *useCase) GetSpamFlags(ctx context.Context, req GetSpamFlagsReq) (*GetSpamFlagsResp, error) {
+	const op = "spamuc.GetSpamFlags"
+
+	status := domain.SpamFlagOpen
+	if req.Status != "" {
+		status = domain.SpamFlagStatus(req.Status)
+	}
+
+	flags, total, err := uc.spamRepo.ListWithCount(ctx, status, req.Page*req.Limit, req.Limit)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]SpamFlagDTO, 0, len(flags))
+	for _, flag := range flags {
+		dto, err := uc.flagDTO(ctx, &flag)
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+		dtos = append(dtos, *dto)
+	}
+
+	return &GetSpamFlagsResp{
+		Flags: dtos,
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+	}, nil
+}
+
+// ResolveSpamFlag marks a flag as reviewed. Resolving a shadow flag delivers
+// the user's future messages again; offenders are removed with the user
+// endpoints instead.
+func (uc *useCase) ResolveSpamFlag(ctx context.Context, req ResolveSpamFlagReq) (*SpamFlagDTO, error) {
+	const op = "spamuc.ResolveSpamFlag"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	flag, err := uc.spamRepo.Resolve(ctx, req.FlagID, au.ID, time.Now())
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("flag_id", "spam flag not found"))
+	}
+
+	dto, err := uc.flagDTO(ctx, flag)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return dto, nil
+}
+
+func (uc *useCase) flagDTO(ctx context.Context, flag *domain.SpamFlag) (*SpamFlagDTO, error) {
+	user, err := uc.userRepo.GetByID(ctx, flag.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolvedAt *string
+	if flag.ResolvedAt != nil {
+		formatted := flag.ResolvedAt.Format(time.RFC3339)
+		resolvedAt = &formatted
+	}
+
+	return &SpamFlagDTO{
+		FlagID:     flag.ID,
+		UserID:     flag.UserID,
+		Username:   user.Username,
+		Email:      user.Email,
+		Reason:     string(flag.Reason),
+		Action:     string(flag.Action),
+		Details:    flag.Details,
+		CreatedAt:  flag.CreatedAt.Format(time.RFC3339),
+		ResolvedAt: resolvedAt,
+		ResolvedBy: flag.ResolvedBy,
+	}, nil
+}
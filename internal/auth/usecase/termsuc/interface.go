@@ -0,0 +1,43 @@
+package termsuc
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"context"
+)
+
+type UseCase interface {
+	GetTerms(ctx context.Context, req GetTermsReq) (*GetTermsResp, error)
+	AcceptTerms(ctx context.Context, req AcceptTermsReq) (*AcceptTermsResp, error)
+}
+
+type GetTermsReq struct{}
+
+func (req GetTermsReq) Validate() error {
+	return nil
+}
+
+type GetTermsResp struct {
+	Version    string  `json:"version"`
+	URL        string  `json:"url,omitempty"`
+	Accepted   bool    `json:"accepted"`
+	AcceptedAt *string `json:"accepted_at,omitempty"`
+}
+
+type AcceptTermsReq struct {
+	Version string `json:"version"`
+}
+
+func (req AcceptTermsReq) Validate() error {
+	var verr error
+
+	if req.Version == "" {
+		verr = errs.AddFieldError(verr, "version", "version is required")
+	}
+
+	return verr
+}
+
+type AcceptTermsResp struct {
+	Version    string `json:"version"`
+	AcceptedAt string `json:"accepted_at"`
+}
@@ -0,0 +1,99 @@
+package termsuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"errors"
+	"time"
+)
+
+// Config holds the current terms of service and privacy policy.
+type Config struct {
+	// Version is the version users must accept.
+	Version string
+	// URL is where the current documents are published.
+	URL string
+}
+
+type useCase struct {
+	termsRepo domain.TermsRepository
+	authPr    auth.Portal
+	cfg       Config
+}
+
+func New(
+	termsRepo domain.TermsRepository,
+	authPr auth.Portal,
+	cfg Config,
+) UseCase {
+	return &useCase{
+		termsRepo: termsRepo,
+		authPr:    authPr,
+		cfg:       cfg,
+	}
+}
+
+func (uc *useCase) GetTerms(ctx context.Context, req GetTermsReq) (*GetTermsResp, error) {
+	const op = "termsuc.GetTerms"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	resp := &GetTermsResp{
+		Version: uc.cfg.Version,
+		URL:     uc.cfg.URL,
+	}
+
+	// Nothing to accept while no version is configured
+	if uc.cfg.Version == "" {
+		resp.Accepted = true
+		return resp, nil
+	}
+
+	acceptance, err := uc.termsRepo.Get(ctx, au.ID, uc.cfg.Version)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return resp, nil
+		}
+		return nil, errs.Wrap(op, err)
+	}
+
+	acceptedAt := acceptance.AcceptedAt.Format(time.RFC3339)
+	resp.Accepted = true
+	resp.AcceptedAt = &acceptedAt
+
+	return resp, nil
+}
+
+func (uc *useCase) AcceptTerms(ctx context.Context, req AcceptTermsReq) (*AcceptTermsResp, error) {
+	const op = "termsuc.AcceptTerms"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	// Only the current version can be accepted, so a client showing stale
+	// documents can't record consent to them
+	if req.Version != uc.cfg.Version {
+		return nil, errs.AddFieldError(nil, "version", "version is not the current terms version")
+	}
+
+	acceptance := &domain.TermsAcceptance{
+		UserID:     au.ID,
+		Version:    req.Version,
+		AcceptedAt: time.Now(),
+	}
+	if err := uc.termsRepo.Accept(ctx, acceptance); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &AcceptTermsResp{
+		Version:    acceptance.Version,
+		AcceptedAt: acceptance.AcceptedAt.Format(time.RFC3339),
+	}, nil
+}
@@ -2,20 +2,41 @@ package useruc
 
 import (
 	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/portal/auth"
 	"chatx-01-backend/pkg/errs"
 	"chatx-01-backend/pkg/val"
 	"context"
+	"strings"
+	"unicode/utf8"
 )
 
 type UseCase interface {
 	CreateUser(ctx context.Context, req CreateUserReq) (*CreateUserResp, error)
+	Register(ctx context.Context, req RegisterReq) (*RegisterResp, error)
+	VerifyEmail(ctx context.Context, req VerifyEmailReq) error
+	ResendVerification(ctx context.Context, req ResendVerificationReq) error
+	JoinAsGuest(ctx context.Context, req JoinAsGuestReq) (*JoinAsGuestResp, error)
+	CleanupExpiredGuests(ctx context.Context) (int, error)
+	DeactivateAccount(ctx context.Context, req DeactivateAccountReq) error
+	AnonymizeDeactivatedUsers(ctx context.Context) (int, error)
 	CreateSuperUser(ctx context.Context, req CreateSuperUserReq) (*CreateSuperUserResp, error)
 	DeleteUser(ctx context.Context, req DeleteUserReq) error
+	PurgeUser(ctx context.Context, req PurgeUserReq) error
+	SetUserRole(ctx context.Context, req SetUserRoleReq) error
 	GetUser(ctx context.Context, req GetUserReq) (*GetUserResp, error)
 	GetUsersList(ctx context.Context, req GetUsersListReq) (*GetUsersListResp, error)
 	GetMe(ctx context.Context, req GetMeReq) (*GetMeResp, error)
+	UpdateProfile(ctx context.Context, req UpdateProfileReq) (*UpdateProfileResp, error)
 	ChangePassword(ctx context.Context, req ChangePasswordReq) error
+	ChangeUsername(ctx context.Context, req ChangeUsernameReq) (*ChangeUsernameResp, error)
+	RequestEmailChange(ctx context.Context, req RequestEmailChangeReq) error
+	ConfirmEmailChange(ctx context.Context, req ConfirmEmailChangeReq) error
 	ChangeImage(ctx context.Context, req ChangeImageReq) (*ChangeImageResp, error)
+	ChangeLocale(ctx context.Context, req ChangeLocaleReq) error
+	ChangePrivacy(ctx context.Context, req ChangePrivacyReq) error
+	BlockUser(ctx context.Context, req BlockUserReq) error
+	UnblockUser(ctx context.Context, req UnblockUserReq) error
+	GetBlockedUsers(ctx context.Context, req GetBlockedUsersReq) (*GetBlockedUsersResp, error)
 	UploadImage(ctx context.Context, req UploadImageReq) (*UploadImageResp, error)
 	DownloadImage(ctx context.Context, req DownloadImageReq) (*DownloadImageResp, error)
 }
@@ -40,7 +61,98 @@ func (req CreateUserReq) Validate() error {
 }
 
 type CreateUserResp struct {
-	UserID int `json:"user_id"`
+	UserID   int    `json:"user_id"`
+	PublicID string `json:"public_id"`
+}
+
+type RegisterReq struct {
+	Token    string `json:"token"` // Invite token; fixes the email when set
+	Email    string `json:"email"` // Required without an invite token
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (req RegisterReq) Validate() error {
+	var verr error
+
+	if req.Email != "" {
+		if err := val.ValidateEmail(req.Email); err != nil {
+			verr = errs.AddFieldError(verr, "email", err.Error())
+		}
+	}
+	if err := val.ValidateUsername(req.Username); err != nil {
+		verr = errs.AddFieldError(verr, "username", err.Error())
+	}
+	if len(req.Password) < 8 {
+		verr = errs.AddFieldError(verr, "password", "password must be at least 8 characters")
+	}
+
+	return verr
+}
+
+type RegisterResp struct {
+	UserID        int    `json:"user_id"`
+	PublicID      string `json:"public_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	ChatIDs       []int  `json:"chat_ids"` // Chats joined through pending invites
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token"`
+}
+
+func (req VerifyEmailReq) Validate() error {
+	var verr error
+
+	if req.Token == "" {
+		verr = errs.AddFieldError(verr, "token", "verification token is required")
+	}
+
+	return verr
+}
+
+type ResendVerificationReq struct {
+	Email string `json:"email"`
+}
+
+func (req ResendVerificationReq) Validate() error {
+	var verr error
+
+	if err := val.ValidateEmail(req.Email); err != nil {
+		verr = errs.AddFieldError(verr, "email", err.Error())
+	}
+
+	return verr
+}
+
+type JoinAsGuestReq struct {
+	Token string `json:"token"`
+	Name  string `json:"name"`
+}
+
+func (req JoinAsGuestReq) Validate() error {
+	var verr error
+
+	if req.Token == "" {
+		verr = errs.AddFieldError(verr, "token", "guest link token is required")
+	}
+	if err := val.ValidateUsername(req.Name); err != nil {
+		verr = errs.AddFieldError(verr, "name", err.Error())
+	}
+
+	return verr
+}
+
+type JoinAsGuestResp struct {
+	UserID       int    `json:"user_id"`
+	PublicID     string `json:"public_id"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	ChatID       int    `json:"chat_id"`
+	ExpiresAt    string `json:"expires_at"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type CreateSuperUserReq struct {
@@ -86,6 +198,38 @@ func (req DeleteUserReq) Validate() error {
 	return verr
 }
 
+type SetUserRoleReq struct {
+	UserID int             `path:"user_id"`
+	Role   domain.UserRole `json:"role"`
+}
+
+func (req SetUserRoleReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+	if req.Role == domain.RoleGuest || !req.Role.IsValid() {
+		verr = errs.AddFieldError(verr, "role", "role must be user, moderator or admin")
+	}
+
+	return verr
+}
+
+type PurgeUserReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req PurgeUserReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
 type GetUserReq struct {
 	UserID int `path:"user_id"`
 }
@@ -101,18 +245,24 @@ func (req GetUserReq) Validate() error {
 }
 
 type GetUserResp struct {
-	UserID    int             `json:"user_id"`
-	Username  string          `json:"username"`
-	Email     string          `json:"email"`
-	Role      domain.UserRole `json:"role"`
-	ImagePath *string         `json:"image_path"`
-	CreatedAt string          `json:"created_at"`
+	UserID      int             `json:"user_id"`
+	PublicID    string          `json:"public_id"`
+	Username    string          `json:"username"`
+	Email       string          `json:"email"`
+	Role        domain.UserRole `json:"role"`
+	ImagePath   *string         `json:"image_path"`
+	DisplayName string          `json:"display_name"` // Empty if not set
+	Bio         string          `json:"bio"`
+	Phone       string          `json:"phone"`
+	CreatedAt   string          `json:"created_at"`
+	DeletedAt   *string         `json:"deleted_at,omitempty"`
 }
 
 type GetUsersListReq struct {
-	Page   int    `query:"page"`
-	Limit  int    `query:"limit"`
-	Search string `query:"search"`
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+	Search   string `query:"search"`
+	Contacts bool   `query:"contacts"` // Only the caller's contacts
 }
 
 func (req GetUsersListReq) Validate() error {
@@ -137,6 +287,7 @@ type GetUsersListResp struct {
 
 type UserListItem struct {
 	UserID    int             `json:"user_id"`
+	PublicID  string          `json:"public_id"`
 	Username  string          `json:"username"`
 	Email     string          `json:"email"`
 	Role      domain.UserRole `json:"role"`
@@ -151,11 +302,50 @@ func (req GetMeReq) Validate() error {
 }
 
 type GetMeResp struct {
-	UserID    int             `json:"user_id"`
-	Username  string          `json:"username"`
-	Email     string          `json:"email"`
-	Role      domain.UserRole `json:"role"`
-	ImagePath *string         `json:"image_path"`
+	UserID       int               `json:"user_id"`
+	PublicID     string            `json:"public_id"`
+	Username     string            `json:"username"`
+	Email        string            `json:"email"`
+	Role         domain.UserRole   `json:"role"`
+	ImagePath    *string           `json:"image_path"`
+	Locale       string            `json:"locale"` // Empty if not set
+	HideLastSeen bool              `json:"hide_last_seen"`
+	DisplayName  string            `json:"display_name"` // Empty if not set
+	Bio          string            `json:"bio"`
+	Phone        string            `json:"phone"`
+	Permissions  []auth.Permission `json:"permissions"` // Granted by the role
+}
+
+// UpdateProfileReq replaces the caller's profile details; an empty field
+// clears it.
+type UpdateProfileReq struct {
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	Phone       string `json:"phone"`
+}
+
+func (req UpdateProfileReq) Validate() error {
+	var verr error
+
+	if utf8.RuneCountInString(strings.TrimSpace(req.DisplayName)) > domain.MaxDisplayNameLength {
+		verr = errs.AddFieldError(verr, "display_name", "display name must be 64 characters or less")
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(req.Bio)) > domain.MaxBioLength {
+		verr = errs.AddFieldError(verr, "bio", "bio must be 500 characters or less")
+	}
+	if phone := strings.TrimSpace(req.Phone); phone != "" {
+		if err := val.ValidatePhone(phone); err != nil {
+			verr = errs.AddFieldError(verr, "phone", err.Error())
+		}
+	}
+
+	return verr
+}
+
+type UpdateProfileResp struct {
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	Phone       string `json:"phone"`
 }
 
 type ChangePasswordReq struct {
@@ -179,6 +369,138 @@ func (req ChangePasswordReq) Validate() error {
 	return verr
 }
 
+type ChangeUsernameReq struct {
+	Username string `json:"username"`
+}
+
+func (req ChangeUsernameReq) Validate() error {
+	var verr error
+
+	if err := val.ValidateUsername(req.Username); err != nil {
+		verr = errs.AddFieldError(verr, "username", err.Error())
+	}
+
+	return verr
+}
+
+type ChangeUsernameResp struct {
+	Username string `json:"username"`
+}
+
+type RequestEmailChangeReq struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (req RequestEmailChangeReq) Validate() error {
+	var verr error
+
+	if err := val.ValidateEmail(req.Email); err != nil {
+		verr = errs.AddFieldError(verr, "email", err.Error())
+	}
+	if req.Password == "" {
+		verr = errs.AddFieldError(verr, "password", "password is required")
+	}
+
+	return verr
+}
+
+type ConfirmEmailChangeReq struct {
+	Token string `json:"token"`
+}
+
+func (req ConfirmEmailChangeReq) Validate() error {
+	var verr error
+
+	if req.Token == "" {
+		verr = errs.AddFieldError(verr, "token", "email change token is required")
+	}
+
+	return verr
+}
+
+type DeactivateAccountReq struct {
+	Password string `json:"password"`
+}
+
+func (req DeactivateAccountReq) Validate() error {
+	var verr error
+
+	if req.Password == "" {
+		verr = errs.AddFieldError(verr, "password", "password is required")
+	}
+
+	return verr
+}
+
+type ChangeLocaleReq struct {
+	Locale string `json:"locale"` // Empty clears the preference
+}
+
+func (req ChangeLocaleReq) Validate() error {
+	var verr error
+
+	if req.Locale != "" && !errs.IsSupportedLocale(req.Locale) {
+		verr = errs.AddFieldError(verr, "locale", "locale must be en, ru or uz")
+	}
+
+	return verr
+}
+
+type ChangePrivacyReq struct {
+	HideLastSeen bool `json:"hide_last_seen"`
+}
+
+func (req ChangePrivacyReq) Validate() error {
+	return nil
+}
+
+type BlockUserReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req BlockUserReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type UnblockUserReq struct {
+	UserID int `path:"user_id"`
+}
+
+func (req UnblockUserReq) Validate() error {
+	var verr error
+
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type GetBlockedUsersReq struct{}
+
+func (req GetBlockedUsersReq) Validate() error {
+	return nil
+}
+
+type GetBlockedUsersResp struct {
+	Users []BlockedUserItem `json:"users"`
+}
+
+type BlockedUserItem struct {
+	UserID    int     `json:"user_id"`
+	PublicID  string  `json:"public_id"`
+	Username  string  `json:"username"`
+	ImagePath *string `json:"image_path"`
+	BlockedAt string  `json:"blocked_at"`
+}
+
 type ChangeImageReq struct {
 	ImagePath string `json:"image_path"`
 }
@@ -242,4 +564,5 @@ type DownloadImageResp struct {
 	File        []byte
 	ContentType string
 	FileName    string
+	Immutable   bool // true when the path is content-addressed and never changes
 }
@@ -5,51 +5,165 @@ import (
 	"chatx-01-backend/internal/auth/domain"
 	"chatx-01-backend/internal/events"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/internal/portal/chat"
 	"chatx-01-backend/pkg/errs"
+	eventbus "chatx-01-backend/pkg/events"
 	"chatx-01-backend/pkg/filestore"
 	"chatx-01-backend/pkg/hasher"
-	"chatx-01-backend/pkg/kafka"
+	"chatx-01-backend/pkg/spam"
 	"chatx-01-backend/pkg/token"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/url"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
 )
 
+// imageHashLen is the number of hex characters of the content hash
+// embedded in uploaded image names.
+const imageHashLen = 16
+
+// contentAddressedImage matches image names produced by UploadImage,
+// e.g. "profile-3f2a9c0d1e4b5a67.png".
+var contentAddressedImage = regexp.MustCompile(`^profile-[0-9a-f]{16}\.[A-Za-z0-9]+$`)
+
+// RegistrationMode controls who may create an account through Register.
+type RegistrationMode string
+
+const (
+	// RegistrationModeOpen lets anyone register; accounts without an invite
+	// verify their email afterwards.
+	RegistrationModeOpen RegistrationMode = "open"
+	// RegistrationModeInvite requires an invite token.
+	RegistrationModeInvite RegistrationMode = "invite"
+	// RegistrationModeAdmin disables Register; only admins create users.
+	RegistrationModeAdmin RegistrationMode = "admin"
+)
+
+func (m RegistrationMode) IsValid() bool {
+	return m == RegistrationModeOpen || m == RegistrationModeInvite || m == RegistrationModeAdmin
+}
+
+// DisposableEmailAction is what happens when someone registers with a
+// disposable email address.
+type DisposableEmailAction string
+
+const (
+	// DisposableEmailAllow registers the account like any other.
+	DisposableEmailAllow DisposableEmailAction = "allow"
+	// DisposableEmailFlag registers the account and flags it for review.
+	DisposableEmailFlag DisposableEmailAction = "flag"
+	// DisposableEmailShadow registers the account and withholds its messages
+	// until an admin reviews the flag.
+	DisposableEmailShadow DisposableEmailAction = "shadow"
+	// DisposableEmailBlock rejects the registration.
+	DisposableEmailBlock DisposableEmailAction = "block"
+)
+
+func (a DisposableEmailAction) IsValid() bool {
+	return a == DisposableEmailAllow ||
+		a == DisposableEmailFlag ||
+		a == DisposableEmailShadow ||
+		a == DisposableEmailBlock
+}
+
+// Config holds settings for registration and guest accounts.
+type Config struct {
+	// RegistrationMode controls who may create an account through Register.
+	RegistrationMode RegistrationMode
+	// DisposableEmailAction applies to registrations without an invite from
+	// disposable email domains.
+	DisposableEmailAction DisposableEmailAction
+	// GuestTTL is how long a guest account lives after joining.
+	GuestTTL time.Duration
+	// VerifyEmailURL is the page linked from email verification emails.
+	VerifyEmailURL string
+	// ConfirmEmailChangeURL is the page linked from email change emails.
+	ConfirmEmailChangeURL string
+	// UsernameCooldown is how long a user waits between username changes;
+	// zero disables the limit.
+	UsernameCooldown time.Duration
+	// DeletionGraceDays is how many days after deactivation an account is
+	// anonymized.
+	DeletionGraceDays int
+}
+
 type useCase struct {
-	userRepo       domain.UserRepository
-	passwordHasher hasher.Hasher
-	fileStore      filestore.Store
-	authPr         auth.Portal
-	eventProducer  *kafka.Producer
-	tokenService   *token.Service
+	userRepo             domain.UserRepository
+	workspaceRepo        domain.WorkspaceRepository
+	spamRepo             domain.SpamRepository
+	blockRepo            domain.BlockRepository
+	passwordHasher       hasher.Hasher
+	fileStore            filestore.Store
+	authPr               auth.Portal
+	chatPr               chat.Portal
+	eventProducer        eventbus.Producer
+	verificationProducer eventbus.Producer
+	tokenService         *token.Service
+	inviteSigner         *token.InviteSigner
+	guestSigner          *token.GuestLinkSigner
+	verificationSigner   *token.VerificationSigner
+	disposableDomains    *spam.DomainList
+	logger               *slog.Logger
+	cfg                  Config
 }
 
 func New(
 	userRepo domain.UserRepository,
+	workspaceRepo domain.WorkspaceRepository,
+	spamRepo domain.SpamRepository,
+	blockRepo domain.BlockRepository,
 	passwordHasher hasher.Hasher,
 	fileStore filestore.Store,
 	authPr auth.Portal,
-	eventProducer *kafka.Producer,
+	chatPr chat.Portal,
+	eventProducer eventbus.Producer,
+	verificationProducer eventbus.Producer,
 	tokenService *token.Service,
+	inviteSigner *token.InviteSigner,
+	guestSigner *token.GuestLinkSigner,
+	verificationSigner *token.VerificationSigner,
+	disposableDomains *spam.DomainList,
+	logger *slog.Logger,
+	cfg Config,
 ) UseCase {
 	return &useCase{
 		userRepo,
+		workspaceRepo,
+		spamRepo,
+		blockRepo,
 		passwordHasher,
 		fileStore,
 		authPr,
+		chatPr,
 		eventProducer,
+		verificationProducer,
 		tokenService,
+		inviteSigner,
+		guestSigner,
+		verificationSigner,
+		disposableDomains,
+		logger,
+		cfg,
 	}
 }
 
 func (uc *useCase) CreateUser(ctx context.Context, req CreateUserReq) (*CreateUserResp, error) {
 	const op = "useruc.CreateUser"
 
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
 	// Send user registration event to Kafka with plain password before hashing
 	event := events.UserRegisteredEvent{
 		Email:    req.Email,
@@ -62,7 +176,7 @@ func (uc *useCase) CreateUser(ctx context.Context, req CreateUserReq) (*CreateUs
 		return nil, errs.Wrap(op, err)
 	}
 
-	err = uc.eventProducer.SendMessage(ctx, &kafka.Message{
+	err = uc.eventProducer.SendMessage(ctx, &eventbus.Message{
 		Key:   []byte(req.Email),
 		Value: eventData,
 	})
@@ -76,29 +190,321 @@ func (uc *useCase) CreateUser(ctx context.Context, req CreateUserReq) (*CreateUs
 		return nil, errs.Wrap(op, err)
 	}
 
+	// The generated password is emailed to the address, so logging in
+	// proves it
+	now := time.Now()
 	user := &domain.User{
-		Email:        req.Email,
-		Username:     req.Username,
-		PasswordHash: passwordHash,
-		Role:         domain.RoleUser,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Email:           req.Email,
+		Username:        req.Username,
+		PasswordHash:    passwordHash,
+		Role:            domain.RoleUser,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerifiedAt: &now,
 	}
 
 	err = uc.userRepo.Create(ctx, user)
 	if err != nil {
-		return nil, errs.ReplaceOn(
-			err,
-			errs.ErrAlreadyExists,
-			errs.NewConflictError("email", "email already exists"),
-		)
+		return nil, createConflict(err)
+	}
+
+	// The user joins the workspace of the admin who created them
+	if err := uc.authPr.AddWorkspaceMember(ctx, au.WorkspaceID, user.ID); err != nil {
+		return nil, errs.Wrap(op, err)
 	}
 
+	uc.joinInvitedChats(ctx, user)
+
 	return &CreateUserResp{
-		UserID: user.ID,
+		UserID:   user.ID,
+		PublicID: user.PublicID,
 	}, nil
 }
 
+func (uc *useCase) Register(ctx context.Context, req RegisterReq) (*RegisterResp, error) {
+	const op = "useruc.Register"
+
+	switch uc.cfg.RegistrationMode {
+	case RegistrationModeAdmin:
+		return nil, errs.Wrap(op, domain.ErrRegistrationDisabled)
+	case RegistrationModeInvite:
+		if req.Token == "" {
+			return nil, errs.Wrap(op, domain.ErrInviteRequired)
+		}
+	}
+
+	now := time.Now()
+	email := req.Email
+	var verifiedAt *time.Time
+
+	if req.Token != "" {
+		// The signed token proves the email was invited, so no verification
+		// email is needed.
+		invited, err := uc.inviteSigner.Verify(req.Token)
+		if err != nil {
+			return nil, errs.AddFieldError(nil, "token", err.Error())
+		}
+		if email != "" && !strings.EqualFold(email, invited) {
+			return nil, errs.AddFieldError(nil, "email", "email does not match the invite")
+		}
+		email = invited
+		verifiedAt = &now
+	} else if email == "" {
+		return nil, errs.AddFieldError(nil, "email", "email is required without an invite token")
+	}
+
+	// Invited addresses were vouched for by the inviter
+	disposable := verifiedAt == nil &&
+		uc.cfg.DisposableEmailAction != DisposableEmailAllow &&
+		uc.disposableDomains.IsDisposable(email)
+	if disposable && uc.cfg.DisposableEmailAction == DisposableEmailBlock {
+		return nil, errs.Wrap(op, domain.ErrDisposableEmail)
+	}
+
+	passwordHash, err := uc.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	user := &domain.User{
+		Email:           email,
+		Username:        req.Username,
+		PasswordHash:    passwordHash,
+		Role:            domain.RoleUser,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerifiedAt: verifiedAt,
+	}
+
+	err = uc.userRepo.Create(ctx, user)
+	if err != nil {
+		return nil, createConflict(err)
+	}
+
+	if err := uc.joinDefaultWorkspace(ctx, user.ID, domain.WorkspaceRoleMember); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	if disposable {
+		if err := uc.flagDisposableEmail(ctx, user); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+	}
+
+	resp := &RegisterResp{
+		UserID:        user.ID,
+		PublicID:      user.PublicID,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		ChatIDs:       []int{},
+	}
+
+	// Pending invites are only accepted for a proven address; an unverified
+	// account joins them after verification.
+	if !user.IsEmailVerified() {
+		if err := uc.sendVerificationEmail(ctx, user); err != nil {
+			// The account exists; the user can request a new link
+			uc.logger.ErrorContext(ctx, "failed to send verification email", "user_id", user.ID, "error", err)
+		}
+		return resp, nil
+	}
+
+	resp.ChatIDs = uc.joinInvitedChats(ctx, user)
+	return resp, nil
+}
+
+func (uc *useCase) VerifyEmail(ctx context.Context, req VerifyEmailReq) error {
+	const op = "useruc.VerifyEmail"
+
+	userID, email, err := uc.verificationSigner.Verify(req.Token)
+	if err != nil {
+		return errs.AddFieldError(nil, "token", err.Error())
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("token", "user not found"))
+	}
+	if user.IsDeleted() || user.Email != email {
+		return errs.AddFieldError(nil, "token", token.ErrInvalidVerification.Error())
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	err = uc.userRepo.MarkEmailVerified(ctx, user.ID, time.Now())
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.joinInvitedChats(ctx, user)
+
+	return nil
+}
+
+func (uc *useCase) ResendVerification(ctx context.Context, req ResendVerificationReq) error {
+	const op = "useruc.ResendVerification"
+
+	// Unknown and already verified addresses succeed silently so the
+	// endpoint can't be used to probe for accounts
+	user, err := uc.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil
+		}
+		return errs.Wrap(op, err)
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := uc.sendVerificationEmail(ctx, user); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+func (uc *useCase) JoinAsGuest(ctx context.Context, req JoinAsGuestReq) (*JoinAsGuestResp, error) {
+	const op = "useruc.JoinAsGuest"
+
+	chatID, err := uc.guestSigner.Verify(req.Token)
+	if err != nil {
+		return nil, errs.AddFieldError(nil, "token", err.Error())
+	}
+
+	// Guests have no email or password; they can't log in again once their
+	// tokens are gone.
+	now := time.Now()
+	expiresAt := now.Add(uc.cfg.GuestTTL)
+	user := &domain.User{
+		Username:  req.Name,
+		Role:      domain.RoleGuest,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: &expiresAt,
+	}
+
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	workspaceID, err := uc.chatPr.AddGuest(ctx, chatID, user.ID)
+	if err != nil {
+		// Don't leave an account behind that belongs to no chat
+		if delErr := uc.userRepo.Delete(ctx, user.ID); delErr != nil {
+			uc.logger.ErrorContext(ctx, "failed to delete guest", "user_id", user.ID, "error", delErr)
+		}
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("token", "chat not found"))
+	}
+
+	sub := token.Subject{
+		UserID:        user.ID,
+		Role:          user.Role.String(),
+		WorkspaceID:   workspaceID,
+		WorkspaceRole: domain.WorkspaceRoleMember.String(),
+	}
+
+	accessToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeAccess)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	refreshToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeRefresh)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &JoinAsGuestResp{
+		UserID:       user.ID,
+		PublicID:     user.PublicID,
+		Username:     user.Username,
+		Role:         user.Role.String(),
+		ChatID:       chatID,
+		ExpiresAt:    expiresAt.Format(time.RFC3339),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// CleanupExpiredGuests deletes guests whose accounts expired and revokes
+// their tokens. Returns the number of deleted guests.
+func (uc *useCase) CleanupExpiredGuests(ctx context.Context) (int, error) {
+	const op = "useruc.CleanupExpiredGuests"
+
+	ids, err := uc.userRepo.SoftDeleteExpiredGuests(ctx, time.Now())
+	if err != nil {
+		return 0, errs.Wrap(op, err)
+	}
+
+	for _, id := range ids {
+		uc.revokeUserTokens(ctx, id)
+	}
+
+	return len(ids), nil
+}
+
+// DeactivateAccount deactivates the caller's account and signs them out
+// everywhere. The account is anonymized after DeletionGraceDays.
+func (uc *useCase) DeactivateAccount(ctx context.Context, req DeactivateAccountReq) error {
+	const op = "useruc.DeactivateAccount"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.passwordHasher.Compare(user.PasswordHash, req.Password)
+	if err != nil {
+		return errs.Wrap(op, errs.NewNotFoundError("password", domain.ErrIncorrectPassword.Error()))
+	}
+
+	err = uc.userRepo.Deactivate(ctx, user.ID, time.Now())
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.revokeUserTokens(ctx, user.ID)
+
+	uc.logger.InfoContext(ctx, "account deactivated", "audit", true, "user_id", user.ID)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:      string(domain.AuditAccountDeactivate),
+		ActorID:     user.ID,
+		WorkspaceID: au.WorkspaceID,
+	})
+
+	return nil
+}
+
+// AnonymizeDeactivatedUsers anonymizes accounts deactivated more than
+// DeletionGraceDays ago and deletes their profile images. Their messages
+// stay, shown from a deleted user. Returns the number of anonymized users.
+func (uc *useCase) AnonymizeDeactivatedUsers(ctx context.Context) (int, error) {
+	const op = "useruc.AnonymizeDeactivatedUsers"
+
+	now := time.Now()
+	ids, images, err := uc.userRepo.AnonymizeDeactivated(ctx, now.AddDate(0, 0, -uc.cfg.DeletionGraceDays), now)
+	if err != nil {
+		return 0, errs.Wrap(op, err)
+	}
+
+	for _, id := range ids {
+		uc.logger.InfoContext(ctx, "deactivated account anonymized", "audit", true, "user_id", id)
+	}
+	for _, image := range images {
+		if err := uc.fileStore.Delete(ctx, image); err != nil {
+			uc.logger.ErrorContext(ctx, "failed to delete anonymized user image", "image_path", image, "error", err)
+		}
+	}
+
+	return len(ids), nil
+}
+
 func (uc *useCase) CreateSuperUser(ctx context.Context, req CreateSuperUserReq) (*CreateSuperUserResp, error) {
 	const op = "useruc.CreateSuperUser"
 
@@ -107,22 +513,24 @@ func (uc *useCase) CreateSuperUser(ctx context.Context, req CreateSuperUserReq)
 		return nil, errs.Wrap(op, err)
 	}
 
+	now := time.Now()
 	user := &domain.User{
-		Email:        req.Email,
-		Username:     req.Username,
-		PasswordHash: passwordHash,
-		Role:         domain.RoleAdmin,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Email:           req.Email,
+		Username:        req.Username,
+		PasswordHash:    passwordHash,
+		Role:            domain.RoleAdmin,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerifiedAt: &now,
 	}
 
 	err = uc.userRepo.Create(ctx, user)
 	if err != nil {
-		return nil, errs.ReplaceOn(
-			err,
-			errs.ErrAlreadyExists,
-			errs.NewConflictError("email", "email already exists"),
-		)
+		return nil, createConflict(err)
+	}
+
+	if err := uc.joinDefaultWorkspace(ctx, user.ID, domain.WorkspaceRoleAdmin); err != nil {
+		return nil, errs.Wrap(op, err)
 	}
 
 	return &CreateSuperUserResp{
@@ -133,28 +541,133 @@ func (uc *useCase) CreateSuperUser(ctx context.Context, req CreateSuperUserReq)
 func (uc *useCase) DeleteUser(ctx context.Context, req DeleteUserReq) error {
 	const op = "useruc.DeleteUser"
 
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
 	// Get user to ensure they exist
-	_, err := uc.userRepo.GetByID(ctx, req.UserID)
+	user, err := uc.userRepo.GetByID(ctx, req.UserID)
 	if err != nil {
 		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user not found"))
 	}
+	if user.IsDeleted() {
+		return errs.Wrap(op, errs.NewNotFoundError("user_id", "user not found"))
+	}
 
 	// Revoke all user tokens BEFORE deleting
-	err = uc.tokenService.RevokeAllUserTokens(ctx, req.UserID)
+	uc.revokeUserTokens(ctx, req.UserID)
+
+	// Soft delete so the user's messages and chats stay intact
+	err = uc.userRepo.SoftDelete(ctx, req.UserID, time.Now())
 	if err != nil {
-		slog.Error("failed to revoke user tokens", "user_id", req.UserID, "error", err)
-		// Don't fail deletion - continue
+		return errs.Wrap(op, err)
+	}
+
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:       string(domain.AuditUserDelete),
+		ActorID:      au.ID,
+		TargetUserID: user.ID,
+	})
+
+	return nil
+}
+
+func (uc *useCase) PurgeUser(ctx context.Context, req PurgeUserReq) error {
+	const op = "useruc.PurgeUser"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user not found"))
 	}
 
-	// Delete user
+	uc.revokeUserTokens(ctx, req.UserID)
+
+	// Permanently remove the user along with their messages and memberships
 	err = uc.userRepo.Delete(ctx, req.UserID)
 	if err != nil {
 		return errs.Wrap(op, err)
 	}
 
+	if user.ImagePath != nil {
+		if err := uc.fileStore.Delete(ctx, *user.ImagePath); err != nil {
+			uc.logger.ErrorContext(ctx, "failed to delete purged user image", "user_id", req.UserID, "error", err)
+		}
+	}
+
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:       string(domain.AuditUserPurge),
+		ActorID:      au.ID,
+		TargetUserID: user.ID,
+	})
+
+	return nil
+}
+
+// SetUserRole assigns a platform role to a user. The user's tokens are
+// revoked, so they log in again with the new role.
+func (uc *useCase) SetUserRole(ctx context.Context, req SetUserRoleReq) error {
+	const op = "useruc.SetUserRole"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if !au.Can(auth.PermManageRoles) {
+		return errs.Wrap(op, domain.ErrCannotManageRoles)
+	}
+	if req.UserID == au.ID {
+		return errs.AddFieldError(nil, "user_id", "you can't change your own role")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user not found"))
+	}
+	if user.IsDeleted() {
+		return errs.NewNotFoundError("user_id", "user not found")
+	}
+	if user.Role == domain.RoleGuest {
+		return errs.AddFieldError(nil, "user_id", "guests can't be given a role")
+	}
+	if user.Role == req.Role {
+		return nil
+	}
+
+	oldRole := user.Role
+	user.Role = req.Role
+	user.UpdatedAt = time.Now()
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.revokeUserTokens(ctx, user.ID)
+
+	uc.logger.InfoContext(ctx, "user role changed", "audit", true,
+		"user_id", au.ID, "target_user_id", user.ID, "old_role", oldRole, "new_role", user.Role)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:       string(domain.AuditRoleChange),
+		ActorID:      au.ID,
+		TargetUserID: user.ID,
+		Details:      fmt.Sprintf("%s -> %s", oldRole, user.Role),
+	})
+
 	return nil
 }
 
+func (uc *useCase) revokeUserTokens(ctx context.Context, userID int) {
+	err := uc.tokenService.RevokeAllUserTokens(ctx, userID)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "failed to revoke user tokens", "user_id", userID, "error", err)
+		// Don't fail deletion - continue
+	}
+}
+
 func (uc *useCase) GetUser(ctx context.Context, req GetUserReq) (*GetUserResp, error) {
 	const op = "useruc.GetUser"
 
@@ -163,28 +676,51 @@ func (uc *useCase) GetUser(ctx context.Context, req GetUserReq) (*GetUserResp, e
 		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user not found"))
 	}
 
+	if user.IsDeleted() {
+		deletedAt := user.DeletedAt.Format(time.RFC3339)
+		return &GetUserResp{
+			UserID:    user.ID,
+			PublicID:  user.PublicID,
+			Username:  domain.DeletedUsername,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt.Format(time.RFC3339),
+			DeletedAt: &deletedAt,
+		}, nil
+	}
+
 	return &GetUserResp{
-		UserID:    user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		ImagePath: user.ImagePath,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		UserID:      user.ID,
+		PublicID:    user.PublicID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        user.Role,
+		ImagePath:   user.ImagePath,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Phone:       user.Phone,
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
 	}, nil
 }
 
 func (uc *useCase) GetUsersList(ctx context.Context, req GetUsersListReq) (*GetUsersListResp, error) {
 	const op = "useruc.GetUsersList"
 
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
 	offset := req.Page * req.Limit
 	var users []*domain.User
 	var total int
-	var err error
 
-	if req.Search != "" {
-		users, total, err = uc.userRepo.SearchByUsernameWithCount(ctx, req.Search, offset, req.Limit)
-	} else {
-		users, total, err = uc.userRepo.ListWithCount(ctx, offset, req.Limit)
+	switch {
+	case req.Contacts:
+		users, total, err = uc.userRepo.ListContactsWithCount(ctx, au.WorkspaceID, au.ID, req.Search, offset, req.Limit)
+	case req.Search != "":
+		users, total, err = uc.userRepo.SearchByUsernameWithCount(ctx, au.WorkspaceID, req.Search, offset, req.Limit)
+	default:
+		users, total, err = uc.userRepo.ListWithCount(ctx, au.WorkspaceID, offset, req.Limit)
 	}
 
 	if err != nil {
@@ -195,6 +731,7 @@ func (uc *useCase) GetUsersList(ctx context.Context, req GetUsersListReq) (*GetU
 	for i, user := range users {
 		userItems[i] = UserListItem{
 			UserID:    user.ID,
+			PublicID:  user.PublicID,
 			Username:  user.Username,
 			Email:     user.Email,
 			Role:      user.Role,
@@ -225,11 +762,194 @@ func (uc *useCase) GetMe(ctx context.Context, req GetMeReq) (*GetMeResp, error)
 	}
 
 	return &GetMeResp{
-		UserID:    user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		ImagePath: user.ImagePath,
+		UserID:       user.ID,
+		PublicID:     user.PublicID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Role:         user.Role,
+		ImagePath:    user.ImagePath,
+		Locale:       user.Locale,
+		HideLastSeen: user.HideLastSeen,
+		DisplayName:  user.DisplayName,
+		Bio:          user.Bio,
+		Phone:        user.Phone,
+		Permissions:  auth.RolePermissions(user.Role.String()),
+	}, nil
+}
+
+// UpdateProfile replaces the caller's display name, bio and phone. Their chat
+// contacts are told of the new profile.
+func (uc *useCase) UpdateProfile(ctx context.Context, req UpdateProfileReq) (*UpdateProfileResp, error) {
+	const op = "useruc.UpdateProfile"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	user.DisplayName = strings.TrimSpace(req.DisplayName)
+	user.Bio = strings.TrimSpace(req.Bio)
+	user.Phone = strings.TrimSpace(req.Phone)
+	user.UpdatedAt = time.Now()
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	uc.notifyProfileUpdated(ctx, user)
+
+	return &UpdateProfileResp{
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Phone:       user.Phone,
+	}, nil
+}
+
+func (uc *useCase) ChangeLocale(ctx context.Context, req ChangeLocaleReq) error {
+	const op = "useruc.ChangeLocale"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user.Locale = req.Locale
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// ChangePrivacy updates the caller's privacy settings.
+func (uc *useCase) ChangePrivacy(ctx context.Context, req ChangePrivacyReq) error {
+	const op = "useruc.ChangePrivacy"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user.HideLastSeen = req.HideLastSeen
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// BlockUser blocks a user for the caller. Blocking a user twice is a no-op.
+func (uc *useCase) BlockUser(ctx context.Context, req BlockUserReq) error {
+	const op = "useruc.BlockUser"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if req.UserID == au.ID {
+		return errs.AddFieldError(nil, "user_id", "you can't block yourself")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user not found"))
+	}
+	if user.IsDeleted() {
+		return errs.NewNotFoundError("user_id", "user not found")
+	}
+
+	err = uc.blockRepo.Block(ctx, &domain.UserBlock{
+		BlockerID: au.ID,
+		BlockedID: user.ID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil && !errors.Is(err, errs.ErrAlreadyExists) {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// UnblockUser lifts the caller's block of a user.
+func (uc *useCase) UnblockUser(ctx context.Context, req UnblockUserReq) error {
+	const op = "useruc.UnblockUser"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.blockRepo.Unblock(ctx, au.ID, req.UserID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user is not blocked"))
+	}
+
+	return nil
+}
+
+// GetBlockedUsers lists the users the caller blocked, most recent first.
+func (uc *useCase) GetBlockedUsers(ctx context.Context, req GetBlockedUsersReq) (*GetBlockedUsersResp, error) {
+	const op = "useruc.GetBlockedUsers"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	blocks, err := uc.blockRepo.ListByBlocker(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	ids := make([]int, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.BlockedID
+	}
+	users, err := uc.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	byID := make(map[int]*domain.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	items := make([]BlockedUserItem, 0, len(blocks))
+	for _, block := range blocks {
+		user, ok := byID[block.BlockedID]
+		if !ok {
+			continue
+		}
+		item := BlockedUserItem{
+			UserID:    user.ID,
+			PublicID:  user.PublicID,
+			Username:  user.Username,
+			ImagePath: user.ImagePath,
+			BlockedAt: block.CreatedAt.Format(time.RFC3339),
+		}
+		if user.IsDeleted() {
+			item.Username = domain.DeletedUsername
+			item.ImagePath = nil
+		}
+		items = append(items, item)
+	}
+
+	return &GetBlockedUsersResp{
+		Users: items,
 	}, nil
 }
 
@@ -261,6 +981,186 @@ func (uc *useCase) ChangePassword(ctx context.Context, req ChangePasswordReq) er
 		return errs.Wrap(op, err)
 	}
 
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:      string(domain.AuditPasswordChange),
+		ActorID:     au.ID,
+		WorkspaceID: au.WorkspaceID,
+	})
+
+	return nil
+}
+
+// ChangeUsername renames the caller. Usernames can be changed once per
+// UsernameCooldown; a change of case only is allowed at any time.
+func (uc *useCase) ChangeUsername(ctx context.Context, req ChangeUsernameReq) (*ChangeUsernameResp, error) {
+	const op = "useruc.ChangeUsername"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if user.Username == req.Username {
+		return &ChangeUsernameResp{Username: user.Username}, nil
+	}
+
+	now := time.Now()
+	caseOnly := strings.EqualFold(user.Username, req.Username)
+	if !caseOnly && uc.cfg.UsernameCooldown > 0 && user.UsernameChangedAt != nil {
+		if wait := user.UsernameChangedAt.Add(uc.cfg.UsernameCooldown).Sub(now); wait > 0 {
+			return nil, errs.NewRateLimitError("username was changed recently, try again later", wait)
+		}
+	}
+
+	oldUsername := user.Username
+	user.Username = req.Username
+	user.UpdatedAt = now
+	if !caseOnly {
+		user.UsernameChangedAt = &now
+	}
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, createConflict(err)
+	}
+
+	uc.logger.InfoContext(ctx, "username changed", "audit", true,
+		"user_id", user.ID, "old_username", oldUsername, "new_username", user.Username)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:  string(domain.AuditUsernameChange),
+		ActorID: user.ID,
+		Details: fmt.Sprintf("%s -> %s", oldUsername, user.Username),
+	})
+
+	uc.notifyProfileUpdated(ctx, user)
+
+	return &ChangeUsernameResp{Username: user.Username}, nil
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The
+// caller's email only changes once the link is followed.
+func (uc *useCase) RequestEmailChange(ctx context.Context, req RequestEmailChangeReq) error {
+	const op = "useruc.RequestEmailChange"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.passwordHasher.Compare(user.PasswordHash, req.Password)
+	if err != nil {
+		return errs.Wrap(op, errs.NewNotFoundError("password", domain.ErrIncorrectPassword.Error()))
+	}
+	if strings.EqualFold(user.Email, req.Email) {
+		return errs.AddFieldError(nil, "email", "email is the same as the current one")
+	}
+
+	_, err = uc.userRepo.GetByEmail(ctx, req.Email)
+	if err == nil {
+		return errs.NewConflictError("email", "email already exists")
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return errs.Wrap(op, err)
+	}
+
+	changeToken, err := uc.verificationSigner.SignEmailChange(user.ID, user.Email, req.Email)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	confirmURL, err := url.Parse(uc.cfg.ConfirmEmailChangeURL)
+	if err != nil {
+		return errs.Wrap(op, fmt.Errorf("invalid confirm email change url: %w", err))
+	}
+	query := confirmURL.Query()
+	query.Set("token", changeToken)
+	confirmURL.RawQuery = query.Encode()
+
+	event := events.EmailVerificationRequestedEvent{
+		Email:       req.Email,
+		Username:    user.Username,
+		VerifyURL:   confirmURL.String(),
+		ExpiresAt:   time.Now().Add(uc.verificationSigner.TTL()),
+		EmailChange: true,
+	}
+
+	eventData, err := event.Marshal()
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.verificationProducer.SendMessage(ctx, &eventbus.Message{
+		Key:   []byte(req.Email),
+		Value: eventData,
+	})
+	if err != nil {
+		return errs.Wrap(op, fmt.Errorf("failed to send email change event: %w", err))
+	}
+
+	uc.logger.InfoContext(ctx, "email change requested", "audit", true,
+		"user_id", user.ID, "old_email", user.Email, "new_email", req.Email)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:  string(domain.AuditEmailChangeRequest),
+		ActorID: user.ID,
+		Details: fmt.Sprintf("%s -> %s", user.Email, req.Email),
+	})
+
+	return nil
+}
+
+// ConfirmEmailChange switches a user to the address an email change token
+// was sent to. The token is only valid while the user still has the email it
+// was requested from.
+func (uc *useCase) ConfirmEmailChange(ctx context.Context, req ConfirmEmailChangeReq) error {
+	const op = "useruc.ConfirmEmailChange"
+
+	userID, oldEmail, newEmail, err := uc.verificationSigner.VerifyEmailChange(req.Token)
+	if err != nil {
+		return errs.AddFieldError(nil, "token", err.Error())
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("token", "user not found"))
+	}
+	if user.IsDeleted() {
+		return errs.AddFieldError(nil, "token", token.ErrInvalidVerification.Error())
+	}
+	if user.Email == newEmail {
+		return nil
+	}
+	if user.Email != oldEmail {
+		return errs.AddFieldError(nil, "token", token.ErrInvalidVerification.Error())
+	}
+
+	now := time.Now()
+	user.Email = newEmail
+	user.UpdatedAt = now
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return createConflict(err)
+	}
+
+	// Following the link proves the new address
+	err = uc.userRepo.MarkEmailVerified(ctx, user.ID, now)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.logger.InfoContext(ctx, "email changed", "audit", true,
+		"user_id", user.ID, "old_email", oldEmail, "new_email", newEmail)
+	uc.authPr.RecordAudit(ctx, auth.AuditEvent{
+		Action:  string(domain.AuditEmailChange),
+		ActorID: user.ID,
+		Details: fmt.Sprintf("%s -> %s", oldEmail, newEmail),
+	})
+
 	return nil
 }
 
@@ -303,6 +1203,8 @@ func (uc *useCase) ChangeImage(ctx context.Context, req ChangeImageReq) (*Change
 		return nil, errs.Wrap(op, err)
 	}
 
+	uc.notifyProfileUpdated(ctx, user)
+
 	return &ChangeImageResp{
 		ImagePath: user.ImagePath,
 	}, nil
@@ -324,8 +1226,13 @@ func (uc *useCase) UploadImage(ctx context.Context, req UploadImageReq) (*Upload
 		return nil, errs.Wrap(op, errs.NewValidationError("file must be a JPEG or PNG image"))
 	}
 
-	ext := filepath.Ext(req.FileName)
-	imagePath := fmt.Sprintf("users/%d/profile%s", au.ID, ext)
+	// Suffix the name with a content hash so a new avatar gets a new URL
+	// and stale copies in browser/CDN caches are never served.
+	sum := sha256.Sum256(req.File)
+	hash := hex.EncodeToString(sum[:])[:imageHashLen]
+
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	imagePath := fmt.Sprintf("users/%d/profile-%s%s", au.ID, hash, ext)
 
 	reader := bytes.NewReader(req.File)
 	err = uc.fileStore.Upload(ctx, imagePath, reader, req.Size, req.ContentType)
@@ -371,5 +1278,114 @@ func (uc *useCase) DownloadImage(ctx context.Context, req DownloadImageReq) (*Do
 		File:        fileData,
 		ContentType: contentType,
 		FileName:    fileName,
+		Immutable:   contentAddressedImage.MatchString(fileName),
 	}, nil
 }
+
+// sendVerificationEmail queues an email with a signed verification link for
+// the user's address.
+func (uc *useCase) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	verifyToken, err := uc.verificationSigner.Sign(user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+
+	verifyURL, err := url.Parse(uc.cfg.VerifyEmailURL)
+	if err != nil {
+		return fmt.Errorf("invalid verify email url: %w", err)
+	}
+	query := verifyURL.Query()
+	query.Set("token", verifyToken)
+	verifyURL.RawQuery = query.Encode()
+
+	event := events.EmailVerificationRequestedEvent{
+		Email:     user.Email,
+		Username:  user.Username,
+		VerifyURL: verifyURL.String(),
+		ExpiresAt: time.Now().Add(uc.verificationSigner.TTL()),
+	}
+
+	eventData, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = uc.verificationProducer.SendMessage(ctx, &eventbus.Message{
+		Key:   []byte(user.Email),
+		Value: eventData,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification event: %w", err)
+	}
+
+	return nil
+}
+
+// joinDefaultWorkspace adds a new user to the default workspace.
+func (uc *useCase) joinDefaultWorkspace(ctx context.Context, userID int, role domain.WorkspaceRole) error {
+	workspace, err := uc.workspaceRepo.GetBySlug(ctx, domain.DefaultWorkspaceSlug)
+	if err != nil {
+		return err
+	}
+
+	return uc.workspaceRepo.AddMember(ctx, &domain.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	})
+}
+
+// flagDisposableEmail flags a new user who registered with a disposable
+// email address for admin review.
+func (uc *useCase) flagDisposableEmail(ctx context.Context, user *domain.User) error {
+	action := domain.SpamActionFlag
+	if uc.cfg.DisposableEmailAction == DisposableEmailShadow {
+		action = domain.SpamActionShadow
+	}
+
+	_, domainName, _ := strings.Cut(user.Email, "@")
+	_, err := uc.spamRepo.Flag(ctx, &domain.SpamFlag{
+		UserID:    user.ID,
+		Reason:    domain.SpamReasonDisposableEmail,
+		Action:    action,
+		Details:   "registered with an address at " + domainName,
+		CreatedAt: user.CreatedAt,
+	})
+	return err
+}
+
+// joinInvitedChats adds a new user to the chats their email was invited to.
+// The account already exists, so a failure is only logged.
+func (uc *useCase) joinInvitedChats(ctx context.Context, user *domain.User) []int {
+	chatIDs, err := uc.chatPr.AcceptInvites(ctx, user.ID, user.Email)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "failed to accept chat invites", "user_id", user.ID, "error", err)
+		return []int{}
+	}
+	return chatIDs
+}
+
+// notifyProfileUpdated pushes the new profile to the user's chat contacts.
+// The change is already saved, so a failure is only logged.
+func (uc *useCase) notifyProfileUpdated(ctx context.Context, user *domain.User) {
+	err := uc.chatPr.NotifyUserUpdated(ctx, chat.UserProfile{
+		ID:          user.ID,
+		PublicID:    user.PublicID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		ImagePath:   user.ImagePath,
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "failed to notify contacts of profile update", "user_id", user.ID, "error", err)
+	}
+}
+
+// createConflict replaces the conflict of a new or updated user with the field
+// that is already taken.
+func createConflict(err error) error {
+	if errors.Is(err, domain.ErrUsernameTaken) {
+		return errs.NewConflictError("username", "username already exists")
+	}
+	return errs.ReplaceOn(err, errs.ErrAlreadyExists, errs.NewConflictError("email", "email already exists"))
+}
@@ -0,0 +1,124 @@
+package workspaceuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/val"
+	"context"
+	"strings"
+)
+
+type UseCase interface {
+	CreateWorkspace(ctx context.Context, req CreateWorkspaceReq) (*CreateWorkspaceResp, error)
+	GetMyWorkspaces(ctx context.Context, req GetMyWorkspacesReq) (*GetMyWorkspacesResp, error)
+	AddMember(ctx context.Context, req AddMemberReq) error
+	RemoveMember(ctx context.Context, req RemoveMemberReq) error
+	SwitchWorkspace(ctx context.Context, req SwitchWorkspaceReq) (*SwitchWorkspaceResp, error)
+}
+
+type CreateWorkspaceReq struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+func (req CreateWorkspaceReq) Validate() error {
+	var verr error
+
+	if err := val.ValidateSlug(req.Slug); err != nil {
+		verr = errs.AddFieldError(verr, "slug", err.Error())
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		verr = errs.AddFieldError(verr, "name", "name must be between 1 and 100 characters")
+	}
+
+	return verr
+}
+
+type CreateWorkspaceResp struct {
+	WorkspaceID int    `json:"workspace_id"`
+	PublicID    string `json:"public_id"`
+	Slug        string `json:"slug"`
+}
+
+type GetMyWorkspacesReq struct{}
+
+func (req GetMyWorkspacesReq) Validate() error {
+	return nil
+}
+
+type WorkspaceDTO struct {
+	WorkspaceID int                  `json:"workspace_id"`
+	PublicID    string               `json:"public_id"`
+	Slug        string               `json:"slug"`
+	Name        string               `json:"name"`
+	Role        domain.WorkspaceRole `json:"role"`
+	JoinedAt    string               `json:"joined_at"`
+	Current     bool                 `json:"current"` // Workspace of the token used for the request
+}
+
+type GetMyWorkspacesResp struct {
+	Workspaces []WorkspaceDTO `json:"workspaces"`
+}
+
+type AddMemberReq struct {
+	WorkspaceID int                  `path:"workspace_id"`
+	UserID      int                  `json:"user_id"`
+	Role        domain.WorkspaceRole `json:"role"` // Defaults to member
+}
+
+func (req AddMemberReq) Validate() error {
+	var verr error
+
+	if req.WorkspaceID <= 0 {
+		verr = errs.AddFieldError(verr, "workspace_id", "invalid workspace id")
+	}
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+	if req.Role != "" && !req.Role.IsValid() {
+		verr = errs.AddFieldError(verr, "role", "role must be admin or member")
+	}
+
+	return verr
+}
+
+type RemoveMemberReq struct {
+	WorkspaceID int `path:"workspace_id"`
+	UserID      int `path:"user_id"`
+}
+
+func (req RemoveMemberReq) Validate() error {
+	var verr error
+
+	if req.WorkspaceID <= 0 {
+		verr = errs.AddFieldError(verr, "workspace_id", "invalid workspace id")
+	}
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type SwitchWorkspaceReq struct {
+	WorkspaceID int `path:"workspace_id"`
+}
+
+func (req SwitchWorkspaceReq) Validate() error {
+	var verr error
+
+	if req.WorkspaceID <= 0 {
+		verr = errs.AddFieldError(verr, "workspace_id", "invalid workspace id")
+	}
+
+	return verr
+}
+
+type SwitchWorkspaceResp struct {
+	WorkspaceID   int                  `json:"workspace_id"`
+	WorkspaceSlug string               `json:"workspace_slug"`
+	WorkspaceRole domain.WorkspaceRole `json:"workspace_role"`
+	AccessToken   string               `json:"access_token"`
+	RefreshToken  string               `json:"refresh_token"`
+}
@@ -0,0 +1,232 @@
+package workspaceuc
+
+import (
+	"chatx-01-backend/internal/auth/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/token"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type useCase struct {
+	workspaceRepo domain.WorkspaceRepository
+	authPr        auth.Portal
+	tokenService  *token.Service
+	logger        *slog.Logger
+}
+
+func New(
+	workspaceRepo domain.WorkspaceRepository,
+	authPr auth.Portal,
+	tokenService *token.Service,
+	logger *slog.Logger,
+) UseCase {
+	return &useCase{
+		workspaceRepo: workspaceRepo,
+		authPr:        authPr,
+		tokenService:  tokenService,
+		logger:        logger,
+	}
+}
+
+func (uc *useCase) CreateWorkspace(ctx context.Context, req CreateWorkspaceReq) (*CreateWorkspaceResp, error) {
+	const op = "workspaceuc.CreateWorkspace"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	workspace := &domain.Workspace{
+		Slug:      req.Slug,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: time.Now(),
+	}
+
+	err = uc.workspaceRepo.Create(ctx, workspace, au.ID)
+	if err != nil {
+		return nil, errs.ReplaceOn(
+			err,
+			errs.ErrAlreadyExists,
+			errs.NewConflictError("slug", "slug already exists"),
+		)
+	}
+
+	return &CreateWorkspaceResp{
+		WorkspaceID: workspace.ID,
+		PublicID:    workspace.PublicID,
+		Slug:        workspace.Slug,
+	}, nil
+}
+
+func (uc *useCase) GetMyWorkspaces(ctx context.Context, req GetMyWorkspacesReq) (*GetMyWorkspacesResp, error) {
+	const op = "workspaceuc.GetMyWorkspaces"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	memberships, err := uc.workspaceRepo.ListByUser(ctx, au.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	workspaces := make([]WorkspaceDTO, len(memberships))
+	for i, m := range memberships {
+		workspaces[i] = WorkspaceDTO{
+			WorkspaceID: m.ID,
+			PublicID:    m.PublicID,
+			Slug:        m.Slug,
+			Name:        m.Name,
+			Role:        m.Role,
+			JoinedAt:    m.JoinedAt.Format(time.RFC3339),
+			Current:     m.ID == au.WorkspaceID,
+		}
+	}
+
+	return &GetMyWorkspacesResp{
+		Workspaces: workspaces,
+	}, nil
+}
+
+func (uc *useCase) AddMember(ctx context.Context, req AddMemberReq) error {
+	const op = "workspaceuc.AddMember"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	if err := uc.checkAdmin(ctx, au, req.WorkspaceID); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	exists, err := uc.authPr.UserExists(ctx, req.UserID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if !exists {
+		return errs.NewNotFoundError("user_id", "user not found")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = domain.WorkspaceRoleMember
+	}
+
+	err = uc.workspaceRepo.AddMember(ctx, &domain.WorkspaceMember{
+		WorkspaceID: req.WorkspaceID,
+		UserID:      req.UserID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	})
+	if err != nil {
+		return errs.ReplaceOn(
+			err,
+			errs.ErrAlreadyExists,
+			errs.NewConflictError("user_id", "user is already a member of this workspace"),
+		)
+	}
+
+	return nil
+}
+
+func (uc *useCase) RemoveMember(ctx context.Context, req RemoveMemberReq) error {
+	const op = "workspaceuc.RemoveMember"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	if err := uc.checkAdmin(ctx, au, req.WorkspaceID); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.workspaceRepo.RemoveMember(ctx, req.WorkspaceID, req.UserID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("user_id", "user is not a member of this workspace"))
+	}
+
+	// Tokens don't say which workspaces a user may still switch to, so the
+	// user signs in again to pick one of the remaining workspaces
+	if err := uc.tokenService.RevokeAllUserTokens(ctx, req.UserID); err != nil {
+		uc.logger.ErrorContext(ctx, "failed to revoke user tokens", "user_id", req.UserID, "error", err)
+	}
+
+	return nil
+}
+
+func (uc *useCase) SwitchWorkspace(ctx context.Context, req SwitchWorkspaceReq) (*SwitchWorkspaceResp, error) {
+	const op = "workspaceuc.SwitchWorkspace"
+
+	au, err := uc.authPr.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	// API keys are bound to one workspace and mustn't turn into tokens
+	if au.APIKeyID != 0 {
+		return nil, errs.Wrap(op, domain.ErrAPIKeyNotAllowed)
+	}
+
+	workspace, err := uc.workspaceRepo.GetByID(ctx, req.WorkspaceID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("workspace_id", "workspace not found"))
+	}
+
+	member, err := uc.workspaceRepo.GetMember(ctx, workspace.ID, au.ID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, domain.ErrNotWorkspaceMember)
+	}
+
+	sub := token.Subject{
+		UserID:        au.ID,
+		Role:          au.Role,
+		WorkspaceID:   workspace.ID,
+		WorkspaceRole: member.Role.String(),
+	}
+
+	accessToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeAccess)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	refreshToken, err := uc.tokenService.GenerateAndStore(ctx, sub, token.TokenTypeRefresh)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &SwitchWorkspaceResp{
+		WorkspaceID:   workspace.ID,
+		WorkspaceSlug: workspace.Slug,
+		WorkspaceRole: member.Role,
+		AccessToken:   accessToken,
+		RefreshToken:  refreshToken,
+	}, nil
+}
+
+// checkAdmin reports an error unless the user administers the workspace.
+// Platform admins administer every workspace.
+func (uc *useCase) checkAdmin(ctx context.Context, au auth.AuthenticatedUser, workspaceID int) error {
+	if _, err := uc.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("workspace_id", "workspace not found"))
+	}
+
+	if au.Role == domain.RoleAdmin.String() {
+		return nil
+	}
+
+	member, err := uc.workspaceRepo.GetMember(ctx, workspaceID, au.ID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, domain.ErrNotWorkspaceMember)
+	}
+	if member.Role != domain.WorkspaceRoleAdmin {
+		return domain.ErrNotWorkspaceAdmin
+	}
+
+	return nil
+}
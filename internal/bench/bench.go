@@ -0,0 +1,412 @@
+// Package bench generates chat load against a running ChatX server to
+// measure how long messages take from sending to WebSocket delivery.
+package bench
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+const (
+	// Users set up at the same time. Login hashes passwords, so running
+	// all at once would mostly measure setup.
+	setupConcurrency = 16
+
+	benchPassword   = "bench-password-1"
+	contentPrefix   = "bench:"
+	eventMessageNew = "message.new"
+)
+
+// Config holds configuration for a bench run.
+type Config struct {
+	BaseURL string // e.g. http://localhost:9900
+	// Users are created by this account, which must administer Workspace.
+	// Cleanup additionally requires the platform admin role.
+	AdminEmail    string
+	AdminPassword string
+	Workspace     string // Slug; empty uses the admin's default workspace
+
+	Users     int           // Simulated users, each with one WebSocket connection
+	GroupSize int           // Users per generated group chat
+	Rate      float64       // Messages per second across all users
+	Duration  time.Duration // How long messages are sent
+	Drain     time.Duration // How long to wait for outstanding deliveries afterwards
+	Timeout   time.Duration // Per HTTP request
+	Cleanup   bool          // Delete the generated users afterwards
+}
+
+func (cfg Config) validate() error {
+	switch {
+	case cfg.BaseURL == "":
+		return errors.New("base url is required")
+	case cfg.AdminEmail == "" || cfg.AdminPassword == "":
+		return errors.New("admin email and password are required")
+	case cfg.Users < 2:
+		return errors.New("users must be at least 2")
+	case cfg.GroupSize < 2:
+		return errors.New("group size must be at least 2")
+	case cfg.Rate <= 0:
+		return errors.New("rate must be positive")
+	case cfg.Duration <= 0:
+		return errors.New("duration must be positive")
+	}
+	return nil
+}
+
+type simUser struct {
+	id     int
+	client *apiClient
+	conn   *websocket.Conn
+	chat   *simChat
+}
+
+type simChat struct {
+	id      int
+	members []*simUser
+}
+
+type runner struct {
+	cfg    Config
+	logger *slog.Logger
+	admin  *apiClient
+	runID  string
+
+	users []*simUser
+	chats []*simChat
+
+	// Send start times by message sequence number
+	pending   sync.Map
+	sent      atomic.Int64
+	failed    atomic.Int64
+	expected  atomic.Int64
+	delivered atomic.Int64
+	send      latencies
+	delivery  latencies
+}
+
+// Run sets up users and chats, sends messages for cfg.Duration and writes
+// the latency report to out.
+func Run(ctx context.Context, cfg Config, logger *slog.Logger, out io.Writer) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	httpClient := newHTTPClient(cfg.Users, cfg.Timeout)
+	r := &runner{
+		cfg:    cfg,
+		logger: logger,
+		admin:  newAPIClient(cfg.BaseURL, httpClient),
+		runID:  newRunID(),
+	}
+
+	adminLogin, err := r.admin.login(ctx, cfg.AdminEmail, cfg.AdminPassword, cfg.Workspace)
+	if err != nil {
+		return fmt.Errorf("admin login: %w", err)
+	}
+	r.admin = r.admin.withToken(adminLogin.AccessToken)
+
+	defer r.cleanup()
+
+	logger.Info("creating users", "run_id", r.runID, "users", cfg.Users)
+	if err := r.setupUsers(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("creating chats", "group_size", cfg.GroupSize)
+	if err := r.setupChats(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("connecting websockets")
+	if err := r.connect(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("sending messages", "rate", cfg.Rate, "duration", cfg.Duration)
+	started := time.Now()
+	r.sendLoop(ctx)
+	elapsed := time.Since(started)
+	r.drain(ctx)
+
+	report := &Report{
+		Users:      len(r.users),
+		Chats:      len(r.chats),
+		Duration:   elapsed,
+		Sent:       r.sent.Load(),
+		SendErrors: r.failed.Load(),
+		Expected:   r.expected.Load(),
+		Delivered:  r.delivered.Load(),
+		Send:       r.send.sorted(),
+		Delivery:   r.delivery.sorted(),
+	}
+	fmt.Fprintln(out)
+	report.Print(out)
+
+	return nil
+}
+
+func (r *runner) setupUsers(ctx context.Context) error {
+	r.users = make([]*simUser, r.cfg.Users)
+
+	return r.forEachUser(func(i int) error {
+		username := fmt.Sprintf("bench_%s_%d", r.runID, i)
+		email := fmt.Sprintf("bench-%s-%d@bench.invalid", r.runID, i)
+
+		id, err := r.admin.createUser(ctx, email, username, benchPassword)
+		if err != nil {
+			return fmt.Errorf("create user %s: %w", username, err)
+		}
+		// Recorded before logging in so cleanup finds it
+		r.users[i] = &simUser{id: id}
+
+		login, err := r.admin.login(ctx, email, benchPassword, r.cfg.Workspace)
+		if err != nil {
+			return fmt.Errorf("login %s: %w", username, err)
+		}
+		client := r.admin.withToken(login.AccessToken)
+
+		if err := client.acceptTerms(ctx); err != nil {
+			return fmt.Errorf("accept terms %s: %w", username, err)
+		}
+		r.users[i].client = client
+
+		return nil
+	})
+}
+
+// setupChats splits the users into group chats of cfg.GroupSize. A last
+// group too small to chat joins the one before it.
+func (r *runner) setupChats(ctx context.Context) error {
+	for start := 0; start < len(r.users); start += r.cfg.GroupSize {
+		end := min(start+r.cfg.GroupSize, len(r.users))
+		if len(r.users)-end < 2 {
+			end = len(r.users)
+		}
+
+		chat := &simChat{members: r.users[start:end]}
+		participantIDs := make([]int, 0, len(chat.members)-1)
+		for _, u := range chat.members[1:] {
+			participantIDs = append(participantIDs, u.id)
+		}
+
+		name := fmt.Sprintf("bench %s #%d", r.runID, len(r.chats)+1)
+		id, err := chat.members[0].client.createGroup(ctx, name, participantIDs)
+		if err != nil {
+			return fmt.Errorf("create chat %q: %w", name, err)
+		}
+		chat.id = id
+
+		for _, u := range chat.members {
+			u.chat = chat
+		}
+		r.chats = append(r.chats, chat)
+
+		if end == len(r.users) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// connect opens each user's WebSocket and starts reading from it. Reading
+// ends when the connection is closed in cleanup.
+func (r *runner) connect(ctx context.Context) error {
+	return r.forEachUser(func(i int) error {
+		u := r.users[i]
+
+		conn, err := u.client.dialWS(ctx)
+		if err != nil {
+			return fmt.Errorf("connect user %d: %w", u.id, err)
+		}
+		u.conn = conn
+
+		go r.read(conn)
+		return nil
+	})
+}
+
+func (r *runner) read(conn *websocket.Conn) {
+	for {
+		var event struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := wsjson.Read(context.Background(), conn, &event); err != nil {
+			return
+		}
+		if event.Type != eventMessageNew {
+			continue
+		}
+
+		var msg struct {
+			Content string `json:"content"`
+		}
+		if err := json.Unmarshal(event.Payload, &msg); err != nil {
+			continue
+		}
+		seq, ok := r.parseContent(msg.Content)
+		if !ok {
+			continue
+		}
+
+		if started, ok := r.pending.Load(seq); ok {
+			r.delivery.add(time.Since(started.(time.Time)))
+			r.delivered.Add(1)
+		}
+	}
+}
+
+// sendLoop sends messages at cfg.Rate from the users in turn. Sends run
+// concurrently so a slow server shows up as latency rather than a lower rate.
+func (r *runner) sendLoop(ctx context.Context) {
+	interval := time.Duration(float64(time.Second) / r.cfg.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(r.cfg.Duration)
+	defer deadline.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var seq int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+		}
+
+		u := r.users[seq%int64(len(r.users))]
+		seq++
+
+		wg.Add(1)
+		go func(seq int64) {
+			defer wg.Done()
+			r.sendOne(ctx, u, seq)
+		}(seq)
+	}
+}
+
+func (r *runner) sendOne(ctx context.Context, u *simUser, seq int64) {
+	started := time.Now()
+	r.pending.Store(seq, started)
+
+	err := u.client.sendMessage(ctx, u.chat.id, r.content(seq))
+	if err != nil {
+		r.pending.Delete(seq)
+		r.failed.Add(1)
+		r.logger.Warn("failed to send message", "user_id", u.id, "error", err)
+		return
+	}
+
+	r.send.add(time.Since(started))
+	r.sent.Add(1)
+	r.expected.Add(int64(len(u.chat.members)))
+}
+
+// drain waits until every sent message was delivered or cfg.Drain elapses.
+func (r *runner) drain(ctx context.Context) {
+	deadline := time.Now().Add(r.cfg.Drain)
+
+	for time.Now().Before(deadline) && r.delivered.Load() < r.expected.Load() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// cleanup closes the connections and deletes the generated users. It runs
+// after failed setups too, so users may be partly initialized.
+func (r *runner) cleanup() {
+	for _, u := range r.users {
+		if u != nil && u.conn != nil {
+			u.conn.Close(websocket.StatusNormalClosure, "")
+		}
+	}
+
+	if !r.cfg.Cleanup {
+		return
+	}
+
+	ctx := context.Background()
+	for _, u := range r.users {
+		if u == nil {
+			continue
+		}
+		if err := r.admin.deleteUser(ctx, u.id); err != nil {
+			r.logger.Warn("failed to delete bench user", "user_id", u.id, "error", err)
+		}
+	}
+}
+
+// forEachUser runs fn for every user index, setupConcurrency at a time,
+// and returns the first error.
+func (r *runner) forEachUser(fn func(i int) error) error {
+	sem := make(chan struct{}, setupConcurrency)
+	errCh := make(chan error, len(r.users))
+
+	var wg sync.WaitGroup
+	for i := range r.users {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(i); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
+
+func (r *runner) content(seq int64) string {
+	return contentPrefix + r.runID + ":" + strconv.FormatInt(seq, 10)
+}
+
+// parseContent returns the sequence number of a message sent by this run.
+func (r *runner) parseContent(content string) (int64, bool) {
+	rest, ok := strings.CutPrefix(content, contentPrefix+r.runID+":")
+	if !ok {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+// newRunID names the users and chats of one run apart from earlier runs.
+func newRunID() string {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().Unix()%1_000_000, 10)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,201 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// apiClient calls the ChatX HTTP API as one user.
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+	token   string
+}
+
+type apiError struct {
+	Status  int
+	Message string `json:"error"`
+	Code    string `json:"code"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("status %d: %s (%s)", e.Status, e.Message, e.Code)
+}
+
+func newAPIClient(baseURL string, httpClient *http.Client) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    httpClient,
+	}
+}
+
+// withToken returns a client for the same server acting with token.
+func (c *apiClient) withToken(token string) *apiClient {
+	return &apiClient{
+		baseURL: c.baseURL,
+		http:    c.http,
+		token:   token,
+	}
+}
+
+type loginResp struct {
+	UserID      int    `json:"user_id"`
+	AccessToken string `json:"access_token"`
+}
+
+func (c *apiClient) login(ctx context.Context, email, password, workspace string) (*loginResp, error) {
+	var resp loginResp
+	err := c.do(ctx, http.MethodPost, "/auth/login", map[string]string{
+		"email":     email,
+		"password":  password,
+		"workspace": workspace,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// acceptTerms accepts the current terms of service if they are pending,
+// otherwise most endpoints refuse the user.
+func (c *apiClient) acceptTerms(ctx context.Context) error {
+	var terms struct {
+		Version  string `json:"version"`
+		Accepted bool   `json:"accepted"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/auth/terms", nil, &terms); err != nil {
+		return err
+	}
+	if terms.Accepted {
+		return nil
+	}
+
+	return c.do(ctx, http.MethodPost, "/auth/terms/accept", map[string]string{"version": terms.Version}, nil)
+}
+
+func (c *apiClient) createUser(ctx context.Context, email, username, password string) (int, error) {
+	var resp struct {
+		UserID int `json:"user_id"`
+	}
+	err := c.do(ctx, http.MethodPost, "/auth/users", map[string]string{
+		"email":    email,
+		"username": username,
+		"password": password,
+	}, &resp)
+	if err != nil {
+		return 0, err
+	}
+	return resp.UserID, nil
+}
+
+func (c *apiClient) deleteUser(ctx context.Context, userID int) error {
+	return c.do(ctx, http.MethodDelete, "/auth/users/"+strconv.Itoa(userID), nil, nil)
+}
+
+func (c *apiClient) createGroup(ctx context.Context, name string, participantIDs []int) (int, error) {
+	var resp struct {
+		ChatID int `json:"chat_id"`
+	}
+	err := c.do(ctx, http.MethodPost, "/chat/chats/groups", map[string]any{
+		"name":            name,
+		"participant_ids": participantIDs,
+	}, &resp)
+	if err != nil {
+		return 0, err
+	}
+	return resp.ChatID, nil
+}
+
+func (c *apiClient) sendMessage(ctx context.Context, chatID int, content string) error {
+	return c.do(ctx, http.MethodPost, "/chat/messages", map[string]any{
+		"chat_id": chatID,
+		"content": content,
+	}, nil)
+}
+
+// dialWS opens the user's WebSocket connection.
+func (c *apiClient) dialWS(ctx context.Context) (*websocket.Conn, error) {
+	u, err := url.Parse(c.baseURL + "/chat/ws")
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.RawQuery = url.Values{"token": {c.token}}.Encode()
+
+	conn, resp, err := websocket.Dial(ctx, u.String(), &websocket.DialOptions{HTTPClient: c.http})
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &apiError{Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(apiErr)
+		return apiErr
+	}
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// newHTTPClient returns a client whose connection pool fits users
+// requesting concurrently.
+func newHTTPClient(users int, timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = users
+	transport.MaxIdleConnsPerHost = users
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
@@ -0,0 +1,94 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencies collects samples from concurrent goroutines.
+type latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func (l *latencies) add(d time.Duration) {
+	l.mu.Lock()
+	l.samples = append(l.samples, d)
+	l.mu.Unlock()
+}
+
+func (l *latencies) sorted() []time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	samples := slices.Clone(l.samples)
+	slices.Sort(samples)
+	return samples
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// Report summarizes a bench run.
+type Report struct {
+	Users    int
+	Chats    int
+	Duration time.Duration
+
+	Sent       int64
+	SendErrors int64
+	// Expected counts the deliveries due to every participant connection of
+	// the chats messages were sent to.
+	Expected  int64
+	Delivered int64
+
+	Send     []time.Duration // HTTP round trip of POST /chat/messages
+	Delivery []time.Duration // From starting the send until a client received message.new
+}
+
+// Print writes the report as a human readable table.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "users: %d, chats: %d, duration: %s\n", r.Users, r.Chats, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "messages: %d sent, %d failed (%.1f msg/s)\n",
+		r.Sent, r.SendErrors, float64(r.Sent)/r.Duration.Seconds())
+
+	lost := r.Expected - r.Delivered
+	fmt.Fprintf(w, "deliveries: %d of %d (%d lost)\n", r.Delivered, r.Expected, lost)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%-10s %8s %10s %10s %10s %10s %10s\n", "latency", "count", "p50", "p90", "p95", "p99", "max")
+	printRow(w, "send", r.Send)
+	printRow(w, "delivery", r.Delivery)
+}
+
+func printRow(w io.Writer, name string, sorted []time.Duration) {
+	if len(sorted) == 0 {
+		fmt.Fprintf(w, "%-10s %8d %10s %10s %10s %10s %10s\n", name, 0, "-", "-", "-", "-", "-")
+		return
+	}
+
+	fmt.Fprintf(w, "%-10s %8d %10s %10s %10s %10s %10s\n",
+		name,
+		len(sorted),
+		round(percentile(sorted, 50)),
+		round(percentile(sorted, 90)),
+		round(percentile(sorted, 95)),
+		round(percentile(sorted, 99)),
+		round(sorted[len(sorted)-1]),
+	)
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
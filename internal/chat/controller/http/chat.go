@@ -9,13 +9,13 @@ import (
 func (c *ctrl) getDMsList(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[chatuc.GetDMsListReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.chatUsecase.GetDMsList(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -25,13 +25,13 @@ func (c *ctrl) getDMsList(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) getGroupsList(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[chatuc.GetGroupsListReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.chatUsecase.GetGroupsList(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -41,13 +41,13 @@ func (c *ctrl) getGroupsList(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) getChat(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[chatuc.GetChatReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.chatUsecase.GetChat(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -57,13 +57,13 @@ func (c *ctrl) getChat(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) createDM(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[chatuc.CreateDMReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.chatUsecase.CreateDM(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -73,13 +73,13 @@ func (c *ctrl) createDM(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) createGroup(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[chatuc.CreateGroupReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.chatUsecase.CreateGroup(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -89,15 +89,527 @@ func (c *ctrl) createGroup(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) checkDMExists(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[chatuc.CheckDMExistsReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.chatUsecase.CheckDMExists(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	httptools.WriteResponse(http.StatusOK, w, resp)
 }
+
+func (c *ctrl) getSavedChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.GetSavedChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.GetSavedChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) inviteByEmail(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.InviteByEmailReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.InviteByEmail(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) createGuestLink(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.CreateGuestLinkReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.CreateGuestLink(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) setRetention(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.SetRetentionReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.SetRetention(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) createWebhook(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.CreateWebhookReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.CreateWebhook(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.GetWebhooksReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.GetWebhooks(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.DeleteWebhookReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.DeleteWebhook(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) createInviteLink(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.CreateInviteLinkReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.CreateInviteLink(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) getInviteLinks(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.GetInviteLinksReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.GetInviteLinks(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) revokeInviteLink(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.RevokeInviteLinkReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.RevokeInviteLink(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) joinByInviteLink(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.JoinByInviteLinkReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.JoinByInviteLink(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) requestToJoin(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.RequestToJoinReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.RequestToJoin(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) getJoinRequests(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.GetJoinRequestsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.GetJoinRequests(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) approveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.JoinRequestReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.ApproveJoinRequest(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) rejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.JoinRequestReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.RejectJoinRequest(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) addParticipants(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.AddParticipantsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.AddParticipants(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) removeParticipant(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.RemoveParticipantReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.RemoveParticipant(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) leaveChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.LeaveChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.LeaveChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) transferOwnership(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.TransferOwnershipReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.TransferOwnership(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.DeleteGroupReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.DeleteGroup(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) setChatDescription(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.SetChatDescriptionReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.SetChatDescription(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) setChatImage(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.SetChatImageReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.SetChatImage(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) muteChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.MuteChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.MuteChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) unmuteChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.UnmuteChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.UnmuteChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) archiveChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.ArchiveChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.ArchiveChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) unarchiveChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.ArchiveChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.UnarchiveChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) pinChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.PinChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.PinChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) unpinChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.PinChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.UnpinChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) createChannel(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.CreateChannelReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.CreateChannel(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) getChannelsList(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.GetChannelsListReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.GetChannelsList(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) subscribeChannel(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.SubscribeChannelReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.chatUsecase.SubscribeChannel(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) unsubscribeChannel(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[chatuc.SubscribeChannelReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.chatUsecase.UnsubscribeChannel(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
@@ -5,35 +5,51 @@ import (
 	"chatx-01-backend/internal/chat/usecase/messageuc"
 	"chatx-01-backend/internal/chat/usecase/notificationuc"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/middleware"
 	"net/http"
+	"time"
 )
 
+// Config holds HTTP-level settings for the chat module handlers.
+type Config struct {
+	UploadTimeout     time.Duration
+	MaxAttachmentSize int64 // bytes
+}
+
 type ctrl struct {
 	mux    *http.ServeMux
 	prefix string
+	cfg    Config
 
 	chatUsecase         chatuc.UseCase
 	messageUsecase      messageuc.UseCase
 	notificationUsecase notificationuc.UseCase
 
 	authPr auth.Portal
+
+	publicIDs map[string]httptools.PublicIDResolver
 }
 
 func Register(
 	mux *http.ServeMux,
 	prefix string,
+	cfg Config,
 	chatUsecase chatuc.UseCase,
 	messageUsecase messageuc.UseCase,
 	notificationUsecase notificationuc.UseCase,
 	authPr auth.Portal,
+	publicIDs map[string]httptools.PublicIDResolver,
 ) {
 	c := &ctrl{
 		mux:                 mux,
 		prefix:              prefix,
+		cfg:                 cfg,
 		chatUsecase:         chatUsecase,
 		messageUsecase:      messageUsecase,
 		notificationUsecase: notificationUsecase,
 		authPr:              authPr,
+		publicIDs:           publicIDs,
 	}
 
 	c.registerHandlers()
@@ -44,16 +60,189 @@ func (c *ctrl) registerHandlers() {
 	// Chat endpoints
 	c.register(http.MethodGet, "/chats/dms", http.HandlerFunc(c.getDMsList), c.authPr.RequireAuth())
 	c.register(http.MethodGet, "/chats/groups", http.HandlerFunc(c.getGroupsList), c.authPr.RequireAuth())
+	c.register(http.MethodGet, "/chats/saved", http.HandlerFunc(c.getSavedChat), c.authPr.RequireMember())
 	c.register(http.MethodGet, "/chats/{chat_id}", http.HandlerFunc(c.getChat), c.authPr.RequireAuth())
+	c.register(http.MethodDelete, "/chats/{chat_id}", http.HandlerFunc(c.deleteGroup), c.authPr.RequireMember())
 	c.register(http.MethodGet, "/chats/dms/check", http.HandlerFunc(c.checkDMExists), c.authPr.RequireAuth())
-	c.register(http.MethodPost, "/chats/dms", http.HandlerFunc(c.createDM), c.authPr.RequireAuth())
-	c.register(http.MethodPost, "/chats/groups", http.HandlerFunc(c.createGroup), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/chats/dms", http.HandlerFunc(c.createDM), c.authPr.RequireMember())
+	c.register(http.MethodPost, "/chats/groups", http.HandlerFunc(c.createGroup), c.authPr.RequireMember())
+	c.register(http.MethodGet, "/chats/channels", http.HandlerFunc(c.getChannelsList), c.authPr.RequireMember())
+	c.register(http.MethodPost, "/chats/channels", http.HandlerFunc(c.createChannel), c.authPr.RequireMember())
+	c.register(
+		http.MethodPut,
+		"/chats/{chat_id}/subscription",
+		http.HandlerFunc(c.subscribeChannel),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodDelete,
+		"/chats/{chat_id}/subscription",
+		http.HandlerFunc(c.unsubscribeChannel),
+		c.authPr.RequireAuth(),
+	)
+	c.register(http.MethodPost, "/chats/{chat_id}/invites", http.HandlerFunc(c.inviteByEmail), c.authPr.RequireMember())
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/guest-links",
+		http.HandlerFunc(c.createGuestLink),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodPut,
+		"/chats/{chat_id}/retention",
+		http.HandlerFunc(c.setRetention),
+		c.authPr.RequireWorkspaceAdmin(),
+	)
+	c.register(http.MethodPost, "/chats/{chat_id}/webhooks", http.HandlerFunc(c.createWebhook), c.authPr.RequireMember())
+	c.register(http.MethodGet, "/chats/{chat_id}/webhooks", http.HandlerFunc(c.getWebhooks), c.authPr.RequireAuth())
+	c.register(
+		http.MethodDelete,
+		"/chats/{chat_id}/webhooks/{webhook_id}",
+		http.HandlerFunc(c.deleteWebhook),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/invite-links",
+		http.HandlerFunc(c.createInviteLink),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodGet,
+		"/chats/{chat_id}/invite-links",
+		http.HandlerFunc(c.getInviteLinks),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodDelete,
+		"/chats/{chat_id}/invite-links/{invite_id}",
+		http.HandlerFunc(c.revokeInviteLink),
+		c.authPr.RequireAuth(),
+	)
+	c.register(http.MethodPost, "/invites/{code}/join", http.HandlerFunc(c.joinByInviteLink), c.authPr.RequireMember())
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/join-requests",
+		http.HandlerFunc(c.requestToJoin),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodGet,
+		"/chats/{chat_id}/join-requests",
+		http.HandlerFunc(c.getJoinRequests),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/join-requests/{request_id}/approve",
+		http.HandlerFunc(c.approveJoinRequest),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/join-requests/{request_id}/reject",
+		http.HandlerFunc(c.rejectJoinRequest),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/participants",
+		http.HandlerFunc(c.addParticipants),
+		c.authPr.RequireMember(),
+	)
+	c.register(
+		http.MethodDelete,
+		"/chats/{chat_id}/participants/{user_id}",
+		http.HandlerFunc(c.removeParticipant),
+		c.authPr.RequireMember(),
+	)
+	c.register(http.MethodPost, "/chats/{chat_id}/leave", http.HandlerFunc(c.leaveChat), c.authPr.RequireAuth())
+	c.register(http.MethodPut, "/chats/{chat_id}/owner", http.HandlerFunc(c.transferOwnership), c.authPr.RequireMember())
+	c.register(
+		http.MethodPut,
+		"/chats/{chat_id}/description",
+		http.HandlerFunc(c.setChatDescription),
+		c.authPr.RequireMember(),
+	)
+	c.register(http.MethodPut, "/chats/{chat_id}/image", http.HandlerFunc(c.setChatImage), c.authPr.RequireMember())
+	c.register(http.MethodPut, "/chats/{chat_id}/mute", http.HandlerFunc(c.muteChat), c.authPr.RequireAuth())
+	c.register(http.MethodDelete, "/chats/{chat_id}/mute", http.HandlerFunc(c.unmuteChat), c.authPr.RequireAuth())
+	c.register(http.MethodPut, "/chats/{chat_id}/archive", http.HandlerFunc(c.archiveChat), c.authPr.RequireAuth())
+	c.register(
+		http.MethodDelete,
+		"/chats/{chat_id}/archive",
+		http.HandlerFunc(c.unarchiveChat),
+		c.authPr.RequireAuth(),
+	)
+	c.register(http.MethodPut, "/chats/{chat_id}/pin", http.HandlerFunc(c.pinChat), c.authPr.RequireAuth())
+	c.register(http.MethodDelete, "/chats/{chat_id}/pin", http.HandlerFunc(c.unpinChat), c.authPr.RequireAuth())
 
 	// Message endpoints
 	c.register(http.MethodGet, "/chats/{chat_id}/messages", http.HandlerFunc(c.getMessagesList), c.authPr.RequireAuth())
+	c.register(
+		http.MethodGet,
+		"/chats/{chat_id}/messages/search",
+		http.HandlerFunc(c.searchChatMessages),
+		c.authPr.RequireAuth(),
+	)
+	c.register(http.MethodGet, "/messages/search", http.HandlerFunc(c.searchMessages), c.authPr.RequireAuth())
 	c.register(http.MethodPost, "/messages", http.HandlerFunc(c.sendMessage), c.authPr.RequireAuth())
+	c.register(
+		http.MethodPost,
+		"/messages/attachments",
+		http.HandlerFunc(c.uploadAttachment),
+		middleware.TimeoutOverride(c.cfg.UploadTimeout),
+		middleware.BodyLimitOverride(c.cfg.MaxAttachmentSize+multipartOverhead),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodGet,
+		"/attachments/{attachment_path...}",
+		http.HandlerFunc(c.downloadAttachment),
+		c.authPr.RequireAuth(),
+	)
 	c.register(http.MethodPut, "/messages/{message_id}", http.HandlerFunc(c.editMessage), c.authPr.RequireAuth())
 	c.register(http.MethodDelete, "/messages/{message_id}", http.HandlerFunc(c.deleteMessage), c.authPr.RequireAuth())
+	c.register(
+		http.MethodGet,
+		"/messages/{message_id}/reactions",
+		http.HandlerFunc(c.listReactions),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodPost,
+		"/messages/{message_id}/reactions",
+		http.HandlerFunc(c.addReaction),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodDelete,
+		"/messages/{message_id}/reactions/{emoji}",
+		http.HandlerFunc(c.removeReaction),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodPost,
+		"/messages/{message_id}/translate",
+		http.HandlerFunc(c.translateMessage),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodPost,
+		"/chats/{chat_id}/summary",
+		http.HandlerFunc(c.summarizeChat),
+		c.authPr.RequireAuth(),
+	)
+	c.register(
+		http.MethodGet,
+		"/chats/{chat_id}/suggested-replies",
+		http.HandlerFunc(c.getSuggestedReplies),
+		c.authPr.RequireAuth(),
+	)
+	c.register(http.MethodGet, "/capabilities", http.HandlerFunc(c.getCapabilities), c.authPr.RequireAuth())
+
+	// Incoming webhooks authenticate with the secret in their URL
+	c.register(http.MethodPost, "/hooks/{hook_id}/{secret}", http.HandlerFunc(c.postWebhookMessage))
 
 	// Notification endpoints
 	c.register(
@@ -62,6 +251,12 @@ func (c *ctrl) registerHandlers() {
 		http.HandlerFunc(c.getUnreadMessagesCount),
 		c.authPr.RequireAuth(),
 	)
+	c.register(
+		http.MethodGet,
+		"/notifications/unread-chats",
+		http.HandlerFunc(c.getUnreadChatsCount),
+		c.authPr.RequireAuth(),
+	)
 	c.register(
 		http.MethodGet,
 		"/chats/{chat_id}/unread",
@@ -69,12 +264,16 @@ func (c *ctrl) registerHandlers() {
 		c.authPr.RequireAuth(),
 	)
 	c.register(http.MethodPost, "/chats/read", http.HandlerFunc(c.markMessagesAsRead), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/chats/read/batch", http.HandlerFunc(c.markChatsAsRead), c.authPr.RequireAuth())
 	c.register(
 		http.MethodPost,
 		"/users/online-status",
 		http.HandlerFunc(c.getOnlineStatusByUsers),
 		c.authPr.RequireAuth(),
 	)
+
+	// Admin endpoints
+	c.register(http.MethodGet, "/admin/connections", http.HandlerFunc(c.getConnections), c.authPr.RequireAdmin())
 }
 
 func (c *ctrl) register(
@@ -83,6 +282,9 @@ func (c *ctrl) register(
 	handler http.Handler,
 	middlewares ...func(http.Handler) http.Handler,
 ) {
+	// Path IDs may be public UUIDs; resolve them right before binding.
+	handler = httptools.ResolvePublicIDs(c.publicIDs)(handler)
+
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		handler = middlewares[i](handler)
 	}
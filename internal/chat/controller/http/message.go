@@ -3,19 +3,60 @@ package http
 import (
 	"chatx-01-backend/internal/chat/usecase/messageuc"
 	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/reqctx"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
+// multipartOverhead is added to the attachment size limit for the form
+// fields and part headers around the file.
+const multipartOverhead = 64 << 10 // 64 KB
+
 func (c *ctrl) getMessagesList(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[messageuc.GetMessagesListReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.messageUsecase.GetMessagesList(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) searchMessages(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.SearchMessagesReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.SearchMessages(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) searchChatMessages(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.SearchChatMessagesReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.SearchChatMessages(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -25,29 +66,110 @@ func (c *ctrl) getMessagesList(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) sendMessage(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[messageuc.SendMessageReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.messageUsecase.SendMessage(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
+
+func (c *ctrl) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(c.cfg.MaxAttachmentSize); err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	fileData, err := io.ReadAll(file)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Invalid IDs are left zero for Validate to report
+	chatID, _ := strconv.Atoi(r.FormValue("chat_id"))
+
+	req := messageuc.UploadAttachmentReq{
+		ChatID:      chatID,
+		File:        fileData,
+		FileName:    header.Filename,
+		ContentType: contentType,
+		Size:        int64(len(fileData)),
+	}
+
+	if err := req.Validate(); err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.UploadAttachment(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	httptools.WriteResponse(http.StatusCreated, w, resp)
 }
 
+func (c *ctrl) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	req := messageuc.DownloadAttachmentReq{
+		Path: strings.TrimPrefix(r.PathValue("attachment_path"), "/"),
+	}
+
+	if err := req.Validate(); err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.DownloadAttachment(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	// Images are shown inline; anything else is downloaded so browsers never
+	// render uploaded documents as pages of this origin.
+	disposition := "attachment"
+	if strings.HasPrefix(resp.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	// Attachment paths are unique, so their content never changes
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	w.Header().Set("Content-Type", resp.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": resp.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp.File)
+}
+
 func (c *ctrl) editMessage(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[messageuc.EditMessageReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	err = c.messageUsecase.EditMessage(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -57,15 +179,146 @@ func (c *ctrl) editMessage(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) deleteMessage(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[messageuc.DeleteMessageReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	err = c.messageUsecase.DeleteMessage(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	httptools.WriteResponse(http.StatusNoContent, w, nil)
 }
+
+func (c *ctrl) addReaction(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.AddReactionReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.messageUsecase.AddReaction(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) removeReaction(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.RemoveReactionReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.messageUsecase.RemoveReaction(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) listReactions(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.ListReactionsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.ListReactions(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getCapabilities(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.GetCapabilitiesReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.GetCapabilities(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) translateMessage(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.TranslateMessageReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.TranslateMessage(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) summarizeChat(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.SummarizeChatReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.SummarizeChat(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getSuggestedReplies(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[messageuc.GetSuggestedRepliesReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.GetSuggestedReplies(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) postWebhookMessage(w http.ResponseWriter, r *http.Request) {
+	// Keep the webhook secret out of access logs and error reports
+	reqctx.SetLogPath(r.Context(), strings.TrimSuffix(r.URL.Path, r.PathValue("secret"))+"[redacted]")
+
+	req, err := httptools.BindRequest[messageuc.PostWebhookMessageReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.messageUsecase.PostWebhookMessage(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
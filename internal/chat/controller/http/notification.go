@@ -9,13 +9,29 @@ import (
 func (c *ctrl) getUnreadMessagesCount(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[notificationuc.GetUnreadMessagesCountReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.notificationUsecase.GetUnreadMessagesCount(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getUnreadChatsCount(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[notificationuc.GetUnreadChatsCountReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.notificationUsecase.GetUnreadChatsCount(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -25,13 +41,13 @@ func (c *ctrl) getUnreadMessagesCount(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) getUnreadMessagesCountByChat(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[notificationuc.GetUnreadMessagesCountByChatReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.notificationUsecase.GetUnreadMessagesCountByChat(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -41,13 +57,29 @@ func (c *ctrl) getUnreadMessagesCountByChat(w http.ResponseWriter, r *http.Reque
 func (c *ctrl) markMessagesAsRead(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[notificationuc.MarkMessagesAsReadReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	err = c.notificationUsecase.MarkMessagesAsRead(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusNoContent, w, nil)
+}
+
+func (c *ctrl) markChatsAsRead(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[notificationuc.MarkChatsAsReadReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	err = c.notificationUsecase.MarkChatsAsRead(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -57,13 +89,29 @@ func (c *ctrl) markMessagesAsRead(w http.ResponseWriter, r *http.Request) {
 func (c *ctrl) getOnlineStatusByUsers(w http.ResponseWriter, r *http.Request) {
 	req, err := httptools.BindRequest[notificationuc.GetOnlineStatusByUsersReq](r)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
 		return
 	}
 
 	resp, err := c.notificationUsecase.GetOnlineStatusByUsers(r.Context(), req)
 	if err != nil {
-		httptools.HandleError(w, err)
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getConnections(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[notificationuc.GetConnectionsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.notificationUsecase.GetConnections(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
 		return
 	}
 
@@ -7,54 +7,117 @@ import "time"
 // depending on the concrete WebSocket implementation.
 type Broadcaster interface {
 	// BroadcastNewMessage broadcasts a new message event to chat participants.
-	BroadcastNewMessage(chatID, messageID, senderID int, content string, sentAt time.Time)
+	BroadcastNewMessage(message MessagePayload)
 
 	// BroadcastEditMessage broadcasts a message edit event to chat participants.
-	BroadcastEditMessage(chatID, messageID, senderID int, content string, editedAt time.Time)
+	BroadcastEditMessage(message MessagePayload)
+
+	// BroadcastMessageUpdated notifies chat participants that the server
+	// changed a message's metadata.
+	BroadcastMessageUpdated(message MessagePayload)
 
 	// BroadcastDeleteMessage broadcasts a message deletion event to chat participants.
 	BroadcastDeleteMessage(chatID, messageID int)
 
 	// BroadcastReadReceipt broadcasts a read receipt event to chat participants.
+	// Receipts of a user in a chat sent in quick succession may be coalesced.
 	BroadcastReadReceipt(chatID, userID, messageID int, readAt time.Time)
+
+	// BroadcastMention notifies the mentioned users that a message mentions them.
+	BroadcastMention(userIDs []int, mention MentionPayload)
+
+	// BroadcastReactionAdded notifies chat participants that a user reacted to a message.
+	BroadcastReactionAdded(reaction ReactionPayload)
+
+	// BroadcastReactionRemoved notifies chat participants that a user removed a reaction.
+	BroadcastReactionRemoved(reaction ReactionPayload)
+
+	// BroadcastChatCreated subscribes the participants' connections to the
+	// chat's workspace to a new chat and notifies them.
+	BroadcastChatCreated(chat ChatPayload)
+
+	// BroadcastChatUpdated notifies chat participants that chat details changed.
+	BroadcastChatUpdated(chat ChatPayload)
+
+	// BroadcastChatDeleted notifies the former participants and unsubscribes them.
+	BroadcastChatDeleted(chatID, actorID int, participantIDs []int)
+
+	// BroadcastMessagesExpired notifies chat participants that the retention
+	// policy deleted or anonymized the chat's messages up to a message.
+	BroadcastMessagesExpired(expired MessagesExpiredPayload)
+
+	// BroadcastParticipantAdded subscribes the added user's connections to the
+	// chat's workspace and notifies the chat that the user joined it.
+	BroadcastParticipantAdded(workspaceID, chatID, userID, actorID int)
+
+	// BroadcastParticipantRemoved notifies a chat and the removed user that the user left it.
+	BroadcastParticipantRemoved(chatID, userID, actorID int)
+
+	// BroadcastChannelSubscribed subscribes a user's connections to the
+	// channel's workspace to its events and notifies only the user, sparing
+	// the channel's other subscribers.
+	BroadcastChannelSubscribed(workspaceID, chatID, userID int)
+
+	// BroadcastChannelUnsubscribed unsubscribes a user from a channel's events
+	// and notifies only the user.
+	BroadcastChannelUnsubscribed(chatID, userID int)
+
+	// BroadcastJoinRequested notifies the given users, who manage a group,
+	// that a user asked to join it.
+	BroadcastJoinRequested(userIDs []int, request JoinRequestPayload)
+
+	// BroadcastUserUpdated notifies the given users that a user's profile changed.
+	BroadcastUserUpdated(userIDs []int, user UserPayload)
+
+	// BroadcastNotification delivers a new inbox item to its user.
+	BroadcastNotification(userID int, notification NotificationPayload)
+
+	// BroadcastContactRequested tells a user that another user asked to
+	// become their contact.
+	BroadcastContactRequested(userID int, requester UserPayload)
+
+	// BroadcastContactAccepted tells a user that another user accepted their
+	// contact request.
+	BroadcastContactAccepted(userID int, contact UserPayload)
 }
 
 // hubBroadcaster implements Broadcaster using the Hub.
 type hubBroadcaster struct {
-	hub *Hub
+	hub          *Hub
+	readReceipts *readReceipts
 }
 
-// NewBroadcaster creates a new Broadcaster using the provided Hub.
-func NewBroadcaster(hub *Hub) Broadcaster {
-	return &hubBroadcaster{hub: hub}
+// NewBroadcaster creates a new Broadcaster using the provided Hub. Read
+// receipts of a user in a chat are coalesced over readReceiptWindow.
+func NewBroadcaster(hub *Hub, readReceiptWindow time.Duration) Broadcaster {
+	return &hubBroadcaster{
+		hub:          hub,
+		readReceipts: newReadReceipts(hub, readReceiptWindow),
+	}
 }
 
-func (b *hubBroadcaster) BroadcastNewMessage(chatID, messageID, senderID int, content string, sentAt time.Time) {
+func (b *hubBroadcaster) BroadcastNewMessage(message MessagePayload) {
 	event := &Event{
-		Type: EventMessageNew,
-		Payload: MessagePayload{
-			ID:       messageID,
-			ChatID:   chatID,
-			SenderID: senderID,
-			Content:  content,
-			SentAt:   sentAt,
-		},
+		Type:    EventMessageNew,
+		Payload: message,
 	}
-	b.hub.BroadcastToChat(chatID, event, 0) // Include sender
+	b.hub.BroadcastToChat(message.ChatID, event, 0) // Include sender
 }
 
-func (b *hubBroadcaster) BroadcastEditMessage(chatID, messageID, senderID int, content string, editedAt time.Time) {
+func (b *hubBroadcaster) BroadcastEditMessage(message MessagePayload) {
 	event := &Event{
-		Type: EventMessageEdit,
-		Payload: MessagePayload{
-			ID:       messageID,
-			ChatID:   chatID,
-			SenderID: senderID,
-			Content:  content,
-			EditedAt: &editedAt,
-		},
+		Type:    EventMessageEdit,
+		Payload: message,
 	}
-	b.hub.BroadcastToChat(chatID, event, 0) // Include sender
+	b.hub.BroadcastToChat(message.ChatID, event, 0) // Include sender
+}
+
+func (b *hubBroadcaster) BroadcastMessageUpdated(message MessagePayload) {
+	event := &Event{
+		Type:    EventMessageUpdated,
+		Payload: message,
+	}
+	b.hub.BroadcastToChat(message.ChatID, event, 0)
 }
 
 func (b *hubBroadcaster) BroadcastDeleteMessage(chatID, messageID int) {
@@ -69,24 +132,203 @@ func (b *hubBroadcaster) BroadcastDeleteMessage(chatID, messageID int) {
 }
 
 func (b *hubBroadcaster) BroadcastReadReceipt(chatID, userID, messageID int, readAt time.Time) {
+	b.readReceipts.add(MessageReadPayload{
+		ChatID:    chatID,
+		UserID:    userID,
+		MessageID: messageID,
+		ReadAt:    readAt,
+	})
+}
+
+func (b *hubBroadcaster) BroadcastMention(userIDs []int, mention MentionPayload) {
+	event := &Event{
+		Type:    EventMessageMention,
+		Payload: mention,
+	}
+	for _, userID := range userIDs {
+		b.hub.BroadcastToUser(userID, event)
+	}
+}
+
+func (b *hubBroadcaster) BroadcastReactionAdded(reaction ReactionPayload) {
+	event := &Event{
+		Type:    EventMessageReactionAdded,
+		Payload: reaction,
+	}
+	b.hub.BroadcastToChat(reaction.ChatID, event, 0)
+}
+
+func (b *hubBroadcaster) BroadcastReactionRemoved(reaction ReactionPayload) {
+	event := &Event{
+		Type:    EventMessageReactionRemoved,
+		Payload: reaction,
+	}
+	b.hub.BroadcastToChat(reaction.ChatID, event, 0)
+}
+
+func (b *hubBroadcaster) BroadcastChatCreated(chat ChatPayload) {
+	event := &Event{
+		Type:    EventChatCreated,
+		Payload: chat,
+	}
+	// Subscribe first so participants receive this and later chat events
+	for _, userID := range chat.ParticipantIDs {
+		b.hub.JoinChat(chat.WorkspaceID, chat.ChatID, userID)
+	}
+	b.hub.BroadcastToChat(chat.ChatID, event, 0)
+}
+
+func (b *hubBroadcaster) BroadcastChatUpdated(chat ChatPayload) {
+	event := &Event{
+		Type:    EventChatUpdated,
+		Payload: chat,
+	}
+	b.hub.BroadcastToChat(chat.ChatID, event, 0)
+}
+
+func (b *hubBroadcaster) BroadcastChatDeleted(chatID, actorID int, participantIDs []int) {
 	event := &Event{
-		Type: EventMessageRead,
-		Payload: MessageReadPayload{
-			ChatID:    chatID,
-			UserID:    userID,
-			MessageID: messageID,
-			ReadAt:    readAt,
+		Type: EventChatDeleted,
+		Payload: ChatDeletePayload{
+			ChatID:  chatID,
+			ActorID: actorID,
 		},
 	}
-	b.hub.BroadcastToChat(chatID, event, userID) // Exclude the reader
+	// Deliver per user since the chat subscription is dropped right away
+	for _, userID := range participantIDs {
+		b.hub.LeaveChat(chatID, userID)
+		b.hub.BroadcastToUser(userID, event)
+	}
 }
 
-// NopBroadcaster is a no-op broadcaster for testing or when WebSocket is disabled.
-type NopBroadcaster struct{}
+func (b *hubBroadcaster) BroadcastMessagesExpired(expired MessagesExpiredPayload) {
+	event := &Event{
+		Type:    EventChatMessagesExpired,
+		Payload: expired,
+	}
+	b.hub.BroadcastToChat(expired.ChatID, event, 0)
+}
 
-func (NopBroadcaster) BroadcastNewMessage(chatID, messageID, senderID int, content string, sentAt time.Time) {
+func (b *hubBroadcaster) BroadcastParticipantAdded(workspaceID, chatID, userID, actorID int) {
+	event := &Event{
+		Type: EventChatParticipantAdded,
+		Payload: ParticipantPayload{
+			ChatID:  chatID,
+			UserID:  userID,
+			ActorID: actorID,
+		},
+	}
+	// Subscribe first so the added user receives this and later chat events
+	b.hub.JoinChat(workspaceID, chatID, userID)
+	b.hub.BroadcastToChat(chatID, event, 0)
 }
-func (NopBroadcaster) BroadcastEditMessage(chatID, messageID, senderID int, content string, editedAt time.Time) {
+
+func (b *hubBroadcaster) BroadcastParticipantRemoved(chatID, userID, actorID int) {
+	event := &Event{
+		Type: EventChatParticipantRemoved,
+		Payload: ParticipantPayload{
+			ChatID:  chatID,
+			UserID:  userID,
+			ActorID: actorID,
+		},
+	}
+	// Unsubscribe first and notify the removed user directly
+	b.hub.LeaveChat(chatID, userID)
+	b.hub.BroadcastToChat(chatID, event, 0)
+	b.hub.BroadcastToUser(userID, event)
 }
-func (NopBroadcaster) BroadcastDeleteMessage(chatID, messageID int) {}
+
+func (b *hubBroadcaster) BroadcastChannelSubscribed(workspaceID, chatID, userID int) {
+	event := &Event{
+		Type: EventChatParticipantAdded,
+		Payload: ParticipantPayload{
+			ChatID:  chatID,
+			UserID:  userID,
+			ActorID: userID,
+		},
+	}
+	b.hub.JoinChat(workspaceID, chatID, userID)
+	b.hub.BroadcastToUser(userID, event)
+}
+
+func (b *hubBroadcaster) BroadcastChannelUnsubscribed(chatID, userID int) {
+	event := &Event{
+		Type: EventChatParticipantRemoved,
+		Payload: ParticipantPayload{
+			ChatID:  chatID,
+			UserID:  userID,
+			ActorID: userID,
+		},
+	}
+	b.hub.LeaveChat(chatID, userID)
+	b.hub.BroadcastToUser(userID, event)
+}
+
+func (b *hubBroadcaster) BroadcastJoinRequested(userIDs []int, request JoinRequestPayload) {
+	event := &Event{
+		Type:    EventChatJoinRequested,
+		Payload: request,
+	}
+	for _, userID := range userIDs {
+		b.hub.BroadcastToUser(userID, event)
+	}
+}
+
+func (b *hubBroadcaster) BroadcastUserUpdated(userIDs []int, user UserPayload) {
+	event := &Event{
+		Type:    EventUserUpdated,
+		Payload: user,
+	}
+	for _, userID := range userIDs {
+		b.hub.BroadcastToUser(userID, event)
+	}
+}
+
+func (b *hubBroadcaster) BroadcastNotification(userID int, notification NotificationPayload) {
+	event := &Event{
+		Type:    EventNotificationNew,
+		Payload: notification,
+	}
+	b.hub.BroadcastToUser(userID, event)
+}
+
+func (b *hubBroadcaster) BroadcastContactRequested(userID int, requester UserPayload) {
+	event := &Event{
+		Type:    EventContactRequested,
+		Payload: requester,
+	}
+	b.hub.BroadcastToUser(userID, event)
+}
+
+func (b *hubBroadcaster) BroadcastContactAccepted(userID int, contact UserPayload) {
+	event := &Event{
+		Type:    EventContactAccepted,
+		Payload: contact,
+	}
+	b.hub.BroadcastToUser(userID, event)
+}
+
+// NopBroadcaster is a no-op broadcaster for testing or when WebSocket is disabled.
+type NopBroadcaster struct{}
+
+func (NopBroadcaster) BroadcastNewMessage(message MessagePayload)                           {}
+func (NopBroadcaster) BroadcastEditMessage(message MessagePayload)                          {}
+func (NopBroadcaster) BroadcastMessageUpdated(message MessagePayload)                       {}
+func (NopBroadcaster) BroadcastDeleteMessage(chatID, messageID int)                         {}
 func (NopBroadcaster) BroadcastReadReceipt(chatID, userID, messageID int, readAt time.Time) {}
+func (NopBroadcaster) BroadcastMention(userIDs []int, mention MentionPayload)               {}
+func (NopBroadcaster) BroadcastReactionAdded(reaction ReactionPayload)                      {}
+func (NopBroadcaster) BroadcastReactionRemoved(reaction ReactionPayload)                    {}
+func (NopBroadcaster) BroadcastChatCreated(chat ChatPayload)                                {}
+func (NopBroadcaster) BroadcastChatUpdated(chat ChatPayload)                                {}
+func (NopBroadcaster) BroadcastChatDeleted(chatID, actorID int, participantIDs []int)       {}
+func (NopBroadcaster) BroadcastMessagesExpired(expired MessagesExpiredPayload)              {}
+func (NopBroadcaster) BroadcastParticipantAdded(workspaceID, chatID, userID, actorID int)   {}
+func (NopBroadcaster) BroadcastParticipantRemoved(chatID, userID, actorID int)              {}
+func (NopBroadcaster) BroadcastChannelSubscribed(workspaceID, chatID, userID int)           {}
+func (NopBroadcaster) BroadcastChannelUnsubscribed(chatID, userID int)                      {}
+func (NopBroadcaster) BroadcastJoinRequested(userIDs []int, request JoinRequestPayload)     {}
+func (NopBroadcaster) BroadcastUserUpdated(userIDs []int, user UserPayload)                 {}
+func (NopBroadcaster) BroadcastNotification(userID int, notification NotificationPayload)   {}
+func (NopBroadcaster) BroadcastContactRequested(userID int, requester UserPayload)          {}
+func (NopBroadcaster) BroadcastContactAccepted(userID int, contact UserPayload)             {}
@@ -1,14 +1,21 @@
 package ws
 
 import (
+	"chatx-01-backend/pkg/errreport"
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/metrics"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"slices"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"nhooyr.io/websocket"
-	"nhooyr.io/websocket/wsjson"
 )
 
 const (
@@ -21,39 +28,199 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer.
-	maxMessageSize = 4096
+	// Minimum read limit for frames from the peer.
+	minReadLimit = 4096
+
+	// Room for the event envelope around message content in a frame.
+	envelopeOverhead = 1024
 
 	// Size of the send channel buffer.
 	sendBufferSize = 256
+
+	// Time allowed to handle a message.send, message.edit or message.delete.
+	actionTimeout = 10 * time.Second
+
+	// How often the token of a connection is checked for revocation
+	// between the messages acting as the user.
+	tokenCheckPeriod = time.Minute
 )
 
-// Client represents a single WebSocket connection.
+// ShutdownCloseReason is the reason of the close frame sent with
+// StatusGoingAway when the server shuts down. Clients should reconnect,
+// resuming from the last sequence number they got.
+const ShutdownCloseReason = "server restarting, reconnect"
+
+// TokenCloseReason is the reason of the close frame sent with
+// StatusPolicyViolation when the token of a connection expires or is
+// revoked. Clients should reconnect with a new token.
+const TokenCloseReason = "token expired or revoked"
+
+// Disconnect reasons reported in logs and the ws_disconnects_total metric.
+const (
+	reasonClientClosed = "client_closed"
+	reasonServerClosed = "server_closed"
+	reasonShutdown     = "shutdown"
+	reasonReadError    = "read_error"
+	reasonWriteError   = "write_error"
+	reasonPongTimeout  = "pong_timeout"
+	reasonRateLimited  = "rate_limited"
+	reasonTokenExpired = "token_expired"
+	reasonTokenRevoked = "token_revoked"
+)
+
+var disconnectsTotal = metrics.NewCounter(
+	"ws_disconnects_total",
+	"Number of closed WebSocket connections by reason.",
+	"reason",
+)
+
+// MarkReadFunc records the reads a client sends with message.read. ctx
+// carries the authenticated user of the connection.
+type MarkReadFunc func(ctx context.Context, reads []ReadPayload) error
+
+// SendMessageFunc sends a message.send of a client and returns the ID and
+// public ID of the new message. ctx carries the authenticated user.
+type SendMessageFunc func(ctx context.Context, chatID int, content string, attachments []string) (int, string, error)
+
+// EditMessageFunc edits a message for a client's message.edit. ctx carries
+// the authenticated user.
+type EditMessageFunc func(ctx context.Context, messageID int, content string) error
+
+// DeleteMessageFunc deletes a message for a client's message.delete. ctx
+// carries the authenticated user.
+type DeleteMessageFunc func(ctx context.Context, messageID int) error
+
+// Actions are the use cases behind the messages clients send. A nil action
+// ignores its message.
+type Actions struct {
+	MarkRead      MarkReadFunc
+	SendMessage   SendMessageFunc
+	EditMessage   EditMessageFunc
+	DeleteMessage DeleteMessageFunc
+}
+
+// BlockedFunc returns the IDs of the users a user blocked or was blocked by,
+// who must not see the user's typing indicators.
+type BlockedFunc func(ctx context.Context, userID int) ([]int, error)
+
+// AuthCheckFunc returns an error when the token a connection was opened with
+// is no longer valid, e.g. after a logout or the user's removal from the
+// workspace.
+type AuthCheckFunc func(ctx context.Context) error
+
+// Client represents a single WebSocket connection or event stream.
 type Client struct {
-	hub     *Hub
-	conn    *websocket.Conn
-	userID  int
-	chatIDs []int
-	send    chan *Event
-	logger  *slog.Logger
+	hub       *Hub
+	conn      Conn
+	codec     codec
+	userID    int
+	send      chan *Event
+	readLimit int64
+	actions   Actions
+	blocked   BlockedFunc
+	authCheck AuthCheckFunc
+	flood     *floodGuard
+	logger    *slog.Logger
+
+	// expiresAt is when the connection's token expires; zero if it doesn't.
+	expiresAt time.Time
+
+	// workspaceID is the workspace of the token the connection was opened
+	// with; only chats in it, and Saved Messages, are subscribed.
+	workspaceID int
+
+	remoteIP    string
+	connectedAt time.Time
+
+	// chats is the set of chats the user participates in, kept up to date
+	// as the user joins or leaves chats while connected.
+	chatsMu sync.RWMutex
+	chats   map[int]struct{}
+
+	// lastActivity is the unix time in nanoseconds of the last frame or pong
+	// received from the peer.
+	lastActivity atomic.Int64
+
+	// pending are the events written before any other when the connection
+	// starts: the missed events of a resuming client and the sync event.
+	// replayed holds the sequence numbers of the missed events, so they
+	// aren't sent twice if they also arrive live.
+	pending  []*Event
+	replayed map[int64]struct{}
+
+	// sentSeq and ackedSeq are the sequence numbers of the last event sent
+	// to and acknowledged by the client.
+	sentSeq  atomic.Int64
+	ackedSeq atomic.Int64
+
+	reasonMu sync.Mutex
+	reason   string
 
 	closeOnce sync.Once
 	closed    chan struct{}
+
+	// draining is closed when the server shuts down, so the write pump sends
+	// what's queued and closes the connection with a reconnect hint.
+	drainOnce sync.Once
+	draining  chan struct{}
+}
+
+// ReadLimit returns the frame size limit that fits a message of maxMessageLength
+// characters, so a message valid over HTTP is never rejected over WebSocket.
+func ReadLimit(maxMessageLength int) int64 {
+	return max(minReadLimit, int64(maxMessageLength)*utf8.UTFMax+envelopeOverhead)
 }
 
-// NewClient creates a new Client instance.
-func NewClient(hub *Hub, conn *websocket.Conn, userID int, chatIDs []int, logger *slog.Logger) *Client {
+// NewClient creates a new Client instance. The connection is closed when its
+// token expires at expiresAt, unless zero, or authCheck reports it revoked.
+func NewClient(
+	hub *Hub,
+	conn Conn,
+	userID int,
+	workspaceID int,
+	chatIDs []int,
+	readLimit int64,
+	actions Actions,
+	blocked BlockedFunc,
+	authCheck AuthCheckFunc,
+	expiresAt time.Time,
+	floodLimits FloodLimits,
+	remoteIP string,
+	logger *slog.Logger,
+) *Client {
+	chats := make(map[int]struct{}, len(chatIDs))
+	for _, chatID := range chatIDs {
+		chats[chatID] = struct{}{}
+	}
+
 	return &Client{
-		hub:     hub,
-		conn:    conn,
-		userID:  userID,
-		chatIDs: chatIDs,
-		send:    make(chan *Event, sendBufferSize),
-		logger:  logger,
-		closed:  make(chan struct{}),
+		hub:         hub,
+		conn:        conn,
+		codec:       codecFor(conn),
+		userID:      userID,
+		workspaceID: workspaceID,
+		send:        make(chan *Event, sendBufferSize),
+		readLimit:   readLimit,
+		actions:     actions,
+		blocked:     blocked,
+		authCheck:   authCheck,
+		expiresAt:   expiresAt,
+		flood:       newFloodGuard(floodLimits),
+		logger:      logger,
+		remoteIP:    remoteIP,
+		connectedAt: time.Now(),
+		chats:       chats,
+		closed:      make(chan struct{}),
+		draining:    make(chan struct{}),
 	}
 }
 
+// Subprotocol returns the subprotocol negotiated for the connection, empty
+// for plain JSON.
+func (c *Client) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
+
 // UserID returns the user ID associated with this client.
 func (c *Client) UserID() int {
 	return c.userID
@@ -61,33 +228,114 @@ func (c *Client) UserID() int {
 
 // ChatIDs returns the chat IDs the user is participating in.
 func (c *Client) ChatIDs() []int {
-	return c.chatIDs
+	c.chatsMu.RLock()
+	defer c.chatsMu.RUnlock()
+
+	chatIDs := make([]int, 0, len(c.chats))
+	for chatID := range c.chats {
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs
 }
 
-// Run starts the client's read and write pumps.
-// This blocks until the connection is closed.
-func (c *Client) Run(ctx context.Context) {
-	var wg sync.WaitGroup
-	wg.Add(2)
+// InChat reports whether the user participates in the chat.
+func (c *Client) InChat(chatID int) bool {
+	c.chatsMu.RLock()
+	defer c.chatsMu.RUnlock()
 
-	go func() {
-		defer wg.Done()
-		c.writePump(ctx)
-	}()
+	_, ok := c.chats[chatID]
+	return ok
+}
 
-	go func() {
-		defer wg.Done()
-		c.readPump(ctx)
-	}()
+func (c *Client) joinChat(chatID int) {
+	c.chatsMu.Lock()
+	c.chats[chatID] = struct{}{}
+	c.chatsMu.Unlock()
+}
+
+func (c *Client) leaveChat(chatID int) {
+	c.chatsMu.Lock()
+	delete(c.chats, chatID)
+	c.chatsMu.Unlock()
+}
+
+// wants reports whether an event routed as described by env is for the
+// client's user.
+func (c *Client) wants(env *relayEnvelope) bool {
+	switch env.Kind {
+	case relayChat:
+		return c.InChat(env.ChatID) && env.ExcludeID != c.userID && !slices.Contains(env.HiddenIDs, c.userID)
+	case relayUser:
+		return env.UserID == c.userID
+	default:
+		return false
+	}
+}
+
+// resume sets the missed events and the sync event to write first. It must
+// be called before Run.
+func (c *Client) resume(missed []*Event, sync *Event) {
+	c.replayed = make(map[int64]struct{}, len(missed))
+	for _, event := range missed {
+		c.replayed[event.Seq] = struct{}{}
+	}
+	c.pending = append(missed, sync)
+}
+
+// Run starts the client's read, write, ping and auth pumps.
+// This blocks until the connection is closed and returns the disconnect reason.
+func (c *Client) Run(ctx context.Context) string {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
+	c.touch()
+
+	pumps := []func(context.Context){c.readPump, c.writePump, c.pingPump, c.authPump}
+
+	var wg sync.WaitGroup
+	wg.Add(len(pumps))
+	for _, pump := range pumps {
+		go func() {
+			defer wg.Done()
+			// Whichever pump stops first takes the others down with it.
+			defer cancel()
+			pump(ctx)
+		}()
+	}
 	wg.Wait()
+
+	c.hub.typing.clientClosed(c)
+
+	reason := c.disconnectReason()
+	disconnectsTotal.Inc(reason)
+
+	return reason
+}
+
+// LastActivity returns when the peer was last heard from.
+func (c *Client) LastActivity() time.Time {
+	return time.Unix(0, c.lastActivity.Load())
 }
 
 // Close closes the client connection.
 func (c *Client) Close() {
+	c.setReason(reasonServerClosed)
+	c.closeWith(websocket.StatusNormalClosure, "connection closed")
+}
+
+// drain makes the client send the events already queued and then close the
+// connection with StatusGoingAway, telling the peer to reconnect.
+func (c *Client) drain() {
+	c.drainOnce.Do(func() {
+		c.setReason(reasonShutdown)
+		close(c.draining)
+	})
+}
+
+func (c *Client) closeWith(code websocket.StatusCode, msg string) {
 	c.closeOnce.Do(func() {
 		close(c.closed)
-		c.conn.Close(websocket.StatusNormalClosure, "connection closed")
+		c.conn.Close(code, msg)
 	})
 }
 
@@ -104,51 +352,104 @@ func (c *Client) Send(event *Event) bool {
 	}
 }
 
-// readPump reads messages from the WebSocket connection.
+func (c *Client) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+}
+
+// setReason records why the connection ended. The first reason wins, as later
+// failures are usually a consequence of the first one.
+func (c *Client) setReason(reason string) {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+
+	if c.reason == "" {
+		c.reason = reason
+	}
+}
+
+func (c *Client) disconnectReason() string {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+
+	if c.reason == "" {
+		return reasonShutdown
+	}
+	return c.reason
+}
+
+// readPump reads messages from the WebSocket connection. Reads are not
+// bounded by a deadline: liveness is enforced by pingPump, so idle but
+// healthy clients are not disconnected.
 func (c *Client) readPump(ctx context.Context) {
 	defer func() {
 		c.hub.Unregister(c)
 	}()
 
-	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadLimit(c.readLimit)
 
 	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-c.closed:
+		_, data, err := c.conn.Read(ctx)
+		if err != nil {
+			switch {
+			case websocket.CloseStatus(err) == websocket.StatusNormalClosure,
+				websocket.CloseStatus(err) == websocket.StatusGoingAway:
+				c.setReason(reasonClientClosed)
+			case ctx.Err() != nil:
+				// Another pump or the server ended the connection.
+			default:
+				c.setReason(reasonReadError)
+				c.logger.Debug("read error", "user_id", c.userID, "error", err)
+			}
 			return
-		default:
 		}
 
-		// Read with timeout
-		readCtx, cancel := context.WithTimeout(ctx, pongWait)
+		c.touch()
+
 		var msg ClientMessage
-		err := wsjson.Read(readCtx, c.conn, &msg)
-		cancel()
+		if err := c.codec.decode(data, &msg); err != nil {
+			c.setReason(reasonReadError)
+			c.logger.Debug("malformed message", "user_id", c.userID, "error", err)
+			c.closeWith(websocket.StatusInvalidFramePayloadData, "malformed message")
+			return
+		}
 
-		if err != nil {
-			if websocket.CloseStatus(err) == websocket.StatusNormalClosure ||
-				websocket.CloseStatus(err) == websocket.StatusGoingAway {
-				c.logger.Debug("client disconnected normally", "user_id", c.userID)
-			} else {
-				c.logger.Debug("read error", "user_id", c.userID, "error", err)
-			}
+		switch c.flood.check(time.Now(), msg.Type) {
+		case floodDrop:
+			continue
+		case floodWarn:
+			c.logger.Warn("client exceeds rate limit", "user_id", c.userID, "type", msg.Type)
+			c.Send(&Event{
+				Type: EventError,
+				Payload: ErrorPayload{
+					RequestID: msg.RequestID,
+					Code:      httptools.CodeRateLimited,
+					Message:   "too many messages, slow down",
+				},
+			})
+			continue
+		case floodClose:
+			c.setReason(reasonRateLimited)
+			c.logger.Warn("closing flooding connection", "user_id", c.userID, "remote_ip", c.remoteIP)
+			c.closeWith(websocket.StatusPolicyViolation, "rate limit exceeded")
 			return
 		}
 
-		c.handleMessage(&msg)
+		c.handleMessage(ctx, &msg)
 	}
 }
 
 // writePump writes messages to the WebSocket connection.
 func (c *Client) writePump(ctx context.Context) {
-	ticker := time.NewTicker(pingPeriod)
 	defer func() {
-		ticker.Stop()
 		c.hub.Unregister(c)
 	}()
 
+	for _, event := range c.pending {
+		if !c.write(ctx, event) {
+			return
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -156,61 +457,203 @@ func (c *Client) writePump(ctx context.Context) {
 		case <-c.closed:
 			return
 
+		case <-c.draining:
+			c.flush(ctx)
+			c.closeWith(websocket.StatusGoingAway, ShutdownCloseReason)
+			return
+
 		case event, ok := <-c.send:
 			if !ok {
 				// Channel closed
 				return
 			}
 
-			writeCtx, cancel := context.WithTimeout(ctx, writeWait)
-			err := wsjson.Write(writeCtx, c.conn, event)
-			cancel()
+			// Already sent while resuming
+			if _, ok := c.replayed[event.Seq]; ok {
+				continue
+			}
 
-			if err != nil {
-				c.logger.Debug("write error", "user_id", c.userID, "error", err)
+			if !c.write(ctx, event) {
 				return
 			}
+		}
+	}
+}
+
+// flush writes the events queued for the client without waiting for more.
+func (c *Client) flush(ctx context.Context) {
+	for {
+		select {
+		case event := <-c.send:
+			if _, ok := c.replayed[event.Seq]; ok {
+				continue
+			}
+			if !c.write(ctx, event) {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// write writes an event to the connection and reports whether the connection
+// is still usable.
+func (c *Client) write(ctx context.Context, event *Event) bool {
+	data, err := c.codec.encode(event)
+	if err != nil {
+		c.logger.Error("failed to encode event", "type", event.Type, "error", err)
+		return true
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, writeWait)
+	err = c.conn.Write(writeCtx, c.codec.messageType(), data)
+	cancel()
+
+	if err != nil {
+		if ctx.Err() == nil {
+			c.setReason(reasonWriteError)
+		}
+		c.logger.Debug("write error", "user_id", c.userID, "error", err)
+		c.reportWriteError(err, event.Type)
+		return false
+	}
+
+	if event.Seq > c.sentSeq.Load() {
+		c.sentSeq.Store(event.Seq)
+	}
+	return true
+}
+
+// pingPump pings the peer every pingPeriod and closes the connection when a
+// pong does not arrive within pongWait.
+func (c *Client) pingPump(ctx context.Context) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.closed:
+			return
 
 		case <-ticker.C:
-			// Send ping
-			pingCtx, cancel := context.WithTimeout(ctx, writeWait)
+			pingCtx, cancel := context.WithTimeout(ctx, pongWait)
 			err := c.conn.Ping(pingCtx)
 			cancel()
 
 			if err != nil {
-				c.logger.Debug("ping error", "user_id", c.userID, "error", err)
+				if ctx.Err() != nil {
+					return
+				}
+
+				c.setReason(reasonPongTimeout)
+				c.logger.Debug("pong not received", "user_id", c.userID, "error", err)
+				c.closeWith(websocket.StatusPolicyViolation, "pong timeout")
+				c.hub.Unregister(c)
+				return
+			}
+
+			c.touch()
+		}
+	}
+}
+
+// authPump closes the connection when its token expires, or is found revoked
+// every tokenCheckPeriod.
+func (c *Client) authPump(ctx context.Context) {
+	var expired <-chan time.Time
+	if !c.expiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(c.expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	ticker := time.NewTicker(tokenCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.closed:
+			return
+
+		case <-expired:
+			c.setReason(reasonTokenExpired)
+			c.closeWith(websocket.StatusPolicyViolation, TokenCloseReason)
+			return
+
+		case <-ticker.C:
+			if !c.authorized(ctx) {
 				return
 			}
 		}
 	}
 }
 
+// authorized reports whether the connection's token is still valid. When it
+// isn't, the connection is closed.
+func (c *Client) authorized(ctx context.Context) bool {
+	if c.authCheck == nil {
+		return true
+	}
+
+	err := c.authCheck(ctx)
+	if err == nil {
+		return true
+	}
+	if ctx.Err() != nil {
+		// Closing anyway
+		return false
+	}
+
+	c.setReason(reasonTokenRevoked)
+	c.logger.Info("closing connection with revoked token", "user_id", c.userID, "error", err)
+	c.closeWith(websocket.StatusPolicyViolation, TokenCloseReason)
+	return false
+}
+
+// reportWriteError reports unexpected write failures. Errors caused by the
+// peer closing the connection or by shutdown are normal and not reported.
+func (c *Client) reportWriteError(err error, eventType EventType) {
+	if websocket.CloseStatus(err) != -1 || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	errreport.Capture(context.Background(), err, map[string]string{
+		"component":  "ws",
+		"user_id":    strconv.Itoa(c.userID),
+		"event_type": string(eventType),
+	})
+}
+
 // handleMessage processes incoming messages from the client.
-func (c *Client) handleMessage(msg *ClientMessage) {
+func (c *Client) handleMessage(ctx context.Context, msg *ClientMessage) {
 	switch msg.Type {
 	case EventTypingStart, EventTypingStop:
-		c.handleTyping(msg)
+		c.handleTyping(ctx, msg)
+	case EventMessageRead:
+		c.handleRead(ctx, msg)
+	case EventMessageSend, EventMessageEdit, EventMessageDelete:
+		c.handleMessageAction(ctx, msg)
+	case EventAck:
+		c.handleAck(msg)
 	default:
 		c.logger.Debug("unknown message type", "type", msg.Type, "user_id", c.userID)
 	}
 }
 
-// handleTyping broadcasts typing events to chat participants.
-func (c *Client) handleTyping(msg *ClientMessage) {
+// handleTyping updates the user's typing indicator in a chat. Participants
+// are only notified when the indicator changes.
+func (c *Client) handleTyping(ctx context.Context, msg *ClientMessage) {
 	if msg.Payload.ChatID == 0 {
 		return
 	}
 
 	// Verify user is participant in the chat
-	isParticipant := false
-	for _, chatID := range c.chatIDs {
-		if chatID == msg.Payload.ChatID {
-			isParticipant = true
-			break
-		}
-	}
-
-	if !isParticipant {
+	if !c.InChat(msg.Payload.ChatID) {
 		c.logger.Warn("user not participant in chat",
 			"user_id", c.userID,
 			"chat_id", msg.Payload.ChatID,
@@ -218,15 +661,130 @@ func (c *Client) handleTyping(msg *ClientMessage) {
 		return
 	}
 
-	event := &Event{
-		Type: msg.Type,
-		Payload: TypingPayload{
-			ChatID: msg.Payload.ChatID,
-			UserID: c.userID,
-		},
+	if msg.Type == EventTypingStart {
+		c.hub.typing.start(ctx, c, msg.Payload.ChatID)
+	} else {
+		c.hub.typing.stop(msg.Payload.ChatID, c.userID)
+	}
+}
+
+// blockedUserIDs returns the users who must not see the user's typing
+// indicators. A failed lookup is logged and hides nobody.
+func (c *Client) blockedUserIDs(ctx context.Context) []int {
+	if c.blocked == nil {
+		return nil
+	}
+
+	ids, err := c.blocked(ctx, c.userID)
+	if err != nil {
+		c.logger.Warn("failed to get blocked users", "user_id", c.userID, "error", err)
+		return nil
+	}
+	return ids
+}
+
+// handleAck records the sequence number of the last event the client
+// processed. Acks older than the last one are ignored.
+func (c *Client) handleAck(msg *ClientMessage) {
+	seq := msg.Payload.Seq
+	if seq <= 0 || seq > c.sentSeq.Load() {
+		return
+	}
+
+	if seq > c.ackedSeq.Load() {
+		c.ackedSeq.Store(seq)
 	}
+}
 
-	c.hub.BroadcastToChat(msg.Payload.ChatID, event, c.userID)
+// handleRead marks the chats in the message as read. Failures are reported to
+// the client with an error event, successes with an ack.
+func (c *Client) handleRead(ctx context.Context, msg *ClientMessage) {
+	if c.actions.MarkRead == nil || len(msg.Payload.Reads) == 0 {
+		return
+	}
+	if !c.authorized(ctx) {
+		return
+	}
+
+	if err := c.actions.MarkRead(ctx, msg.Payload.Reads); err != nil {
+		c.logger.Debug("failed to mark messages as read", "user_id", c.userID, "error", err)
+		c.Send(&Event{Type: EventError, Payload: c.errorPayload(ctx, msg, err)})
+		return
+	}
+
+	c.Send(&Event{
+		Type:    EventAck,
+		Payload: AckPayload{RequestID: msg.RequestID, Type: msg.Type},
+	})
+}
+
+// handleMessageAction sends, edits or deletes a message like the REST
+// endpoints do and answers with an ack, or an error event on failure. Events
+// caused by the change are broadcast as usual, to this connection too.
+func (c *Client) handleMessageAction(ctx context.Context, msg *ClientMessage) {
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+
+	if !c.authorized(ctx) {
+		return
+	}
+
+	ack := AckPayload{RequestID: msg.RequestID, Type: msg.Type}
+
+	var err error
+	switch msg.Type {
+	case EventMessageSend:
+		if c.actions.SendMessage == nil {
+			return
+		}
+		ack.MessageID, ack.PublicID, err = c.actions.SendMessage(
+			ctx,
+			msg.Payload.ChatID,
+			msg.Payload.Content,
+			msg.Payload.Attachments,
+		)
+	case EventMessageEdit:
+		if c.actions.EditMessage == nil {
+			return
+		}
+		err = c.actions.EditMessage(ctx, msg.Payload.MessageID, msg.Payload.Content)
+		ack.MessageID = msg.Payload.MessageID
+	case EventMessageDelete:
+		if c.actions.DeleteMessage == nil {
+			return
+		}
+		err = c.actions.DeleteMessage(ctx, msg.Payload.MessageID)
+		ack.MessageID = msg.Payload.MessageID
+	}
+
+	if err != nil {
+		c.logger.Debug("failed to handle client message", "type", msg.Type, "user_id", c.userID, "error", err)
+		c.Send(&Event{Type: EventError, Payload: c.errorPayload(ctx, msg, err)})
+		return
+	}
+
+	c.Send(&Event{Type: EventAck, Payload: ack})
+}
+
+// errorPayload describes err with the code and fields the REST endpoints
+// would return. Internal errors are reported and their details hidden.
+func (c *Client) errorPayload(ctx context.Context, msg *ClientMessage, err error) ErrorPayload {
+	body := httptools.ErrorBody(err)
+	if body.Code == httptools.CodeInternal {
+		errreport.Capture(ctx, err, map[string]string{
+			"component":  "ws",
+			"user_id":    strconv.Itoa(c.userID),
+			"event_type": string(msg.Type),
+		})
+		body.Error = "internal server error"
+	}
+
+	return ErrorPayload{
+		RequestID: msg.RequestID,
+		Code:      body.Code,
+		Message:   body.Error,
+		Fields:    body.Fields,
+	}
 }
 
 // MarshalJSON implements json.Marshaler for Event.
@@ -0,0 +1,395 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+	"nhooyr.io/websocket"
+)
+
+// Subprotocols a client can request when connecting. Clients that request
+// none get JSON text frames.
+const (
+	SubprotocolJSON    = "chatx.json"
+	SubprotocolMsgpack = "chatx.msgpack"
+)
+
+// Subprotocols lists the supported subprotocols in order of preference.
+var Subprotocols = []string{SubprotocolMsgpack, SubprotocolJSON}
+
+// codec encodes events for and decodes messages from one connection.
+type codec interface {
+	encode(event *Event) ([]byte, error)
+	decode(data []byte, msg *ClientMessage) error
+	messageType() websocket.MessageType
+}
+
+// codecFor returns the codec of a connection: Server-Sent Events for an
+// event stream, otherwise the one of the negotiated subprotocol.
+func codecFor(conn Conn) codec {
+	if _, ok := conn.(*streamConn); ok {
+		return streamCodec{}
+	}
+	if conn.Subprotocol() == SubprotocolMsgpack {
+		return msgpackCodec{}
+	}
+	return jsonCodec{}
+}
+
+// jsonCodec sends events as JSON text frames.
+type jsonCodec struct{}
+
+func (jsonCodec) encode(event *Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+func (jsonCodec) decode(data []byte, msg *ClientMessage) error {
+	return json.Unmarshal(data, msg)
+}
+
+func (jsonCodec) messageType() websocket.MessageType {
+	return websocket.MessageText
+}
+
+// msgpackCodec sends events as MessagePack binary frames. Values are laid out
+// like their JSON form: structs become maps keyed by their json tag names,
+// omitempty fields are left out and times are RFC 3339 strings, so clients
+// can share models between both encodings.
+type msgpackCodec struct{}
+
+func (msgpackCodec) encode(event *Event) ([]byte, error) {
+	return appendMsgpack(make([]byte, 0, 256), reflect.ValueOf(event).Elem())
+}
+
+func (msgpackCodec) decode(data []byte, msg *ClientMessage) error {
+	rest, err := decodeMsgpack(data, reflect.ValueOf(msg).Elem())
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("msgpack: trailing data after message")
+	}
+	return nil
+}
+
+func (msgpackCodec) messageType() websocket.MessageType {
+	return websocket.MessageBinary
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// structField is how a struct field is encoded.
+type structField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// structSchemas caches the fields of encoded struct types by reflect.Type.
+var structSchemas sync.Map
+
+// schemaOf returns the encoded fields of a struct type, following its json
+// tags.
+func schemaOf(t reflect.Type) []structField {
+	if cached, ok := structSchemas.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	fields := make([]structField, 0, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, structField{
+			index:     i,
+			name:      name,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+
+	structSchemas.Store(t, fields)
+	return fields
+}
+
+// appendMsgpack appends the MessagePack encoding of v to b.
+func appendMsgpack(b []byte, v reflect.Value) ([]byte, error) {
+	switch v.Type() {
+	case timeType:
+		return msgp.AppendString(b, v.Interface().(time.Time).Format(time.RFC3339Nano)), nil
+	case rawMessageType:
+		if v.IsNil() {
+			return msgp.AppendNil(b), nil
+		}
+		return appendJSONAsMsgpack(b, v.Bytes())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return msgp.AppendNil(b), nil
+		}
+		return appendMsgpack(b, v.Elem())
+	case reflect.Struct:
+		return appendStruct(b, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return msgp.AppendNil(b), nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("msgpack: unsupported map key type %s", v.Type().Key())
+		}
+
+		b = msgp.AppendMapHeader(b, uint32(v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			b = msgp.AppendString(b, iter.Key().String())
+
+			var err error
+			if b, err = appendMsgpack(b, iter.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return msgp.AppendNil(b), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return msgp.AppendBytes(b, v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		b = msgp.AppendArrayHeader(b, uint32(v.Len()))
+		for i := range v.Len() {
+			var err error
+			if b, err = appendMsgpack(b, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.String:
+		return msgp.AppendString(b, v.String()), nil
+	case reflect.Bool:
+		return msgp.AppendBool(b, v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return msgp.AppendInt64(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return msgp.AppendUint64(b, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return msgp.AppendFloat64(b, v.Float()), nil
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+}
+
+func appendStruct(b []byte, v reflect.Value) ([]byte, error) {
+	fields := schemaOf(v.Type())
+
+	count := 0
+	for _, f := range fields {
+		if !f.omitEmpty || !isEmptyValue(v.Field(f.index)) {
+			count++
+		}
+	}
+
+	b = msgp.AppendMapHeader(b, uint32(count))
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		b = msgp.AppendString(b, f.name)
+
+		var err error
+		if b, err = appendMsgpack(b, fv); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// isEmptyValue reports whether encoding/json would omit v from an omitempty
+// field.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// appendJSONAsMsgpack appends a JSON document, such as message metadata or
+// the payload of a relayed event, as MessagePack.
+func appendJSONAsMsgpack(b []byte, data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("msgpack: %w", err)
+	}
+
+	return appendJSONValue(b, value)
+}
+
+func appendJSONValue(b []byte, value any) ([]byte, error) {
+	switch value := value.(type) {
+	case nil:
+		return msgp.AppendNil(b), nil
+	case bool:
+		return msgp.AppendBool(b, value), nil
+	case string:
+		return msgp.AppendString(b, value), nil
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return msgp.AppendInt64(b, i), nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("msgpack: %w", err)
+		}
+		return msgp.AppendFloat64(b, f), nil
+	case []any:
+		b = msgp.AppendArrayHeader(b, uint32(len(value)))
+		for _, elem := range value {
+			var err error
+			if b, err = appendJSONValue(b, elem); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		b = msgp.AppendMapHeader(b, uint32(len(value)))
+		for key, elem := range value {
+			b = msgp.AppendString(b, key)
+
+			var err error
+			if b, err = appendJSONValue(b, elem); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("msgpack: unexpected JSON value %T", value)
+	}
+}
+
+// decodeMsgpack decodes a MessagePack value from b into v and returns the
+// remaining bytes. Unknown map keys are skipped, nil leaves v unchanged.
+func decodeMsgpack(b []byte, v reflect.Value) ([]byte, error) {
+	if msgp.IsNil(b) {
+		return msgp.ReadNilBytes(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return decodeStruct(b, v)
+	case reflect.Slice:
+		n, rest, err := msgp.ReadArrayHeaderBytes(b)
+		if err != nil {
+			return nil, err
+		}
+
+		slice := reflect.MakeSlice(v.Type(), int(n), int(n))
+		for i := range int(n) {
+			if rest, err = decodeMsgpack(rest, slice.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		v.Set(slice)
+		return rest, nil
+	case reflect.String:
+		s, rest, err := msgp.ReadStringBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		v.SetString(s)
+		return rest, nil
+	case reflect.Bool:
+		t, rest, err := msgp.ReadBoolBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		v.SetBool(t)
+		return rest, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, rest, err := msgp.ReadInt64Bytes(b)
+		if err != nil {
+			return nil, err
+		}
+		if v.OverflowInt(i) {
+			return nil, fmt.Errorf("msgpack: %d overflows %s", i, v.Type())
+		}
+		v.SetInt(i)
+		return rest, nil
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+}
+
+func decodeStruct(b []byte, v reflect.Value) ([]byte, error) {
+	n, rest, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := schemaOf(v.Type())
+	for range n {
+		var key []byte
+		if key, rest, err = msgp.ReadMapKeyZC(rest); err != nil {
+			return nil, err
+		}
+
+		i := fieldIndex(fields, key)
+		if i < 0 {
+			if rest, err = msgp.Skip(rest); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if rest, err = decodeMsgpack(rest, v.Field(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	return rest, nil
+}
+
+// fieldIndex returns the struct index of the field named key, or -1.
+func fieldIndex(fields []structField, key []byte) int {
+	for _, f := range fields {
+		if f.name == string(key) {
+			return f.index
+		}
+	}
+	return -1
+}
@@ -0,0 +1,92 @@
+package ws
+
+import "time"
+
+const (
+	// floodWindow is the window client messages are counted in.
+	floodWindow = time.Second
+
+	// floodWarningReset is how long a connection must stay within its limits
+	// for its warnings to be forgotten.
+	floodWarningReset = time.Minute
+)
+
+// FloodLimits bounds how fast a single connection may send messages, so one
+// misbehaving client can't flood the hub. A zero rate is unlimited.
+type FloodLimits struct {
+	// MessageRate is how many client messages of any type are handled per
+	// second.
+	MessageRate int
+
+	// TypingRate is how many typing.start and typing.stop are handled per
+	// second.
+	TypingRate int
+
+	// MaxWarnings is how many times a connection is warned for going over
+	// a limit before it's closed.
+	MaxWarnings int
+}
+
+// floodVerdict is what to do with a client message.
+type floodVerdict int
+
+const (
+	floodAllow floodVerdict = iota // Handle the message
+	floodDrop                      // Drop it, the client was already warned
+	floodWarn                      // Drop it and warn the client
+	floodClose                     // Close the connection
+)
+
+// floodGuard counts the messages of one connection in fixed windows. It's
+// only used by the read pump, so it isn't safe for concurrent use.
+type floodGuard struct {
+	limits FloodLimits
+
+	windowStart time.Time
+	messages    int
+	typing      int
+	warned      bool // The client was warned in this window
+
+	warnings      int
+	lastViolation time.Time
+}
+
+func newFloodGuard(limits FloodLimits) *floodGuard {
+	return &floodGuard{limits: limits}
+}
+
+// check records a client message of type t received at now.
+func (g *floodGuard) check(now time.Time, t EventType) floodVerdict {
+	if now.Sub(g.windowStart) >= floodWindow {
+		g.windowStart = now
+		g.messages, g.typing = 0, 0
+		g.warned = false
+	}
+
+	g.messages++
+	over := g.limits.MessageRate > 0 && g.messages > g.limits.MessageRate
+	if t == EventTypingStart || t == EventTypingStop {
+		g.typing++
+		over = over || g.limits.TypingRate > 0 && g.typing > g.limits.TypingRate
+	}
+
+	if !over {
+		return floodAllow
+	}
+	if g.warned {
+		return floodDrop
+	}
+
+	// Warn once per window, forgetting warnings of a client that calmed down
+	g.warned = true
+	if now.Sub(g.lastViolation) >= floodWarningReset {
+		g.warnings = 0
+	}
+	g.lastViolation = now
+	g.warnings++
+
+	if g.warnings > g.limits.MaxWarnings {
+		return floodClose
+	}
+	return floodWarn
+}
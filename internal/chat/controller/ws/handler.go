@@ -4,19 +4,35 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"nhooyr.io/websocket"
 
+	"chatx-01-backend/internal/analytics"
 	"chatx-01-backend/internal/chat/domain"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/httptools"
 )
 
+// SubprotocolAuthPrefix marks the Sec-WebSocket-Protocol entry carrying the
+// access token for clients that can't set headers, like browsers. It's never
+// picked as the connection's subprotocol.
+const SubprotocolAuthPrefix = "chatx.auth."
+
 // Handler handles WebSocket connections.
 type Handler struct {
-	hub      *Hub
-	chatRepo domain.ChatRepository
-	authPr   auth.Portal
-	logger   *slog.Logger
+	hub            *Hub
+	chatRepo       domain.ChatRepository
+	authPr         auth.Portal
+	analytics      analytics.Publisher
+	readLimit      int64
+	actions        Actions
+	floodLimits    FloodLimits
+	originPatterns []string
+	logger         *slog.Logger
 }
 
 // NewHandler creates a new WebSocket handler.
@@ -24,89 +40,230 @@ func NewHandler(
 	hub *Hub,
 	chatRepo domain.ChatRepository,
 	authPr auth.Portal,
+	analyticsPr analytics.Publisher,
+	maxMessageLength int,
+	actions Actions,
+	floodLimits FloodLimits,
+	allowedOrigins []string,
 	logger *slog.Logger,
 ) *Handler {
 	return &Handler{
-		hub:      hub,
-		chatRepo: chatRepo,
-		authPr:   authPr,
-		logger:   logger,
+		hub:            hub,
+		chatRepo:       chatRepo,
+		authPr:         authPr,
+		analytics:      analyticsPr,
+		readLimit:      ReadLimit(maxMessageLength),
+		actions:        actions,
+		floodLimits:    floodLimits,
+		originPatterns: allowedOrigins,
+		logger:         logger,
 	}
 }
 
 // ServeHTTP handles the WebSocket upgrade and connection.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Get token from query params
-	tokenString := r.URL.Query().Get("token")
-	if tokenString == "" {
-		http.Error(w, "missing token", http.StatusUnauthorized)
-		return
-	}
-
-	// Validate token
-	authUser, err := h.authPr.ValidateToken(r.Context(), tokenString)
-	if err != nil {
-		http.Error(w, "invalid token", http.StatusUnauthorized)
+	authUser, chatIDs, ok := h.authenticate(w, r)
+	if !ok {
 		return
 	}
 
-	// Get user's chat IDs for subscription
-	chatIDs, err := h.chatRepo.GetUserChatIDs(r.Context(), authUser.ID)
+	// Accept WebSocket connection
+	conn, err := websocket.Accept(w, r, h.acceptOptions())
 	if err != nil {
-		h.logger.Error("failed to get user chat IDs",
+		h.logger.Error("failed to accept websocket connection",
 			"user_id", authUser.ID,
 			"error", err,
 		)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	// Accept WebSocket connection
-	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
-		InsecureSkipVerify: true, // Allow connections from any origin (configure appropriately for production)
-	})
+	h.logger.Info("websocket connection established",
+		"user_id", authUser.ID,
+		"chat_count", len(chatIDs),
+		"subprotocol", conn.Subprotocol(),
+	)
+
+	h.serve(r, conn, authUser, chatIDs)
+}
+
+// ServeStream sends the same events as a WebSocket connection as a
+// Server-Sent Events stream, for clients behind proxies that block
+// WebSockets. Streams only go to the client, which sends messages and reads
+// over HTTP instead.
+func (h *Handler) ServeStream(w http.ResponseWriter, r *http.Request) {
+	authUser, chatIDs, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := newStreamConn(r.Context(), w)
 	if err != nil {
-		h.logger.Error("failed to accept websocket connection",
+		h.logger.Error("failed to start event stream",
 			"user_id", authUser.ID,
 			"error", err,
 		)
 		return
 	}
 
-	h.logger.Info("websocket connection established",
+	h.logger.Info("event stream established",
 		"user_id", authUser.ID,
 		"chat_count", len(chatIDs),
 	)
 
+	h.serve(r, conn, authUser, chatIDs)
+}
+
+// authenticate validates the token of a connection request and returns its
+// user with the chats to subscribe to. When it fails the response is
+// already written.
+func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (auth.AuthenticatedUser, []int, bool) {
+	if h.hub.Closing() {
+		// Clients retry and land on another instance
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+		return auth.AuthenticatedUser{}, nil, false
+	}
+
+	tokenString := requestToken(r)
+	if tokenString == "" {
+		http.Error(w, "missing token", http.StatusUnauthorized)
+		return auth.AuthenticatedUser{}, nil, false
+	}
+
+	// Validate token
+	authUser, err := h.authPr.ValidateToken(r.Context(), tokenString)
+	if err != nil {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return auth.AuthenticatedUser{}, nil, false
+	}
+
+	// Get user's chat IDs in the token's workspace for subscription
+	chatIDs, err := h.chatRepo.GetUserChatIDs(r.Context(), authUser.WorkspaceID, authUser.ID)
+	if err != nil {
+		h.logger.Error("failed to get user chat IDs",
+			"user_id", authUser.ID,
+			"error", err,
+		)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return auth.AuthenticatedUser{}, nil, false
+	}
+
+	return authUser, chatIDs, true
+}
+
+// serve registers a client for an accepted connection and runs it until the
+// connection closes.
+func (h *Handler) serve(r *http.Request, conn Conn, authUser auth.AuthenticatedUser, chatIDs []int) {
+	h.analytics.Track(r.Context(), analytics.Event{
+		Type:        analytics.EventWSConnect,
+		UserID:      authUser.ID,
+		WorkspaceID: authUser.WorkspaceID,
+	})
+
+	// The token is checked again while the connection is open, so a logout
+	// or the user's removal from the workspace closes it
+	token := requestToken(r)
+	authCheck := func(ctx context.Context) error {
+		_, err := h.authPr.ValidateToken(ctx, token)
+		return err
+	}
+
 	// Create client
-	client := NewClient(h.hub, conn, authUser.ID, chatIDs, h.logger)
+	client := NewClient(
+		h.hub,
+		conn,
+		authUser.ID,
+		authUser.WorkspaceID,
+		chatIDs,
+		h.readLimit,
+		h.actions,
+		h.authPr.GetBlockedUserIDs,
+		authCheck,
+		authUser.ExpiresAt,
+		h.floodLimits,
+		httptools.ClientIP(r),
+		h.logger,
+	)
 
-	// Register client with hub
+	// Register client with hub, then queue the events it missed before any
+	// live one
 	h.hub.Register(client)
+	h.hub.Resume(r.Context(), client, resumeFrom(r))
 
 	// Broadcast online status
-	h.broadcastPresence(authUser.ID, true)
+	h.broadcastPresence(authUser.WorkspaceID, authUser.ID, true)
 
-	// Run client (blocks until connection closes)
-	client.Run(r.Context())
+	// Run client (blocks until connection closes). Messages from the client
+	// act as the authenticated user.
+	reason := client.Run(h.authPr.SetAuthUser(r.Context(), authUser))
 
 	// Broadcast offline status after connection closes
-	h.broadcastPresence(authUser.ID, false)
+	h.broadcastPresence(authUser.WorkspaceID, authUser.ID, false)
 
 	h.logger.Info("websocket connection closed",
 		"user_id", authUser.ID,
+		"reason", reason,
+		"idle", time.Since(client.LastActivity()).Round(time.Millisecond).String(),
 	)
 }
 
-// broadcastPresence broadcasts user online/offline status to their contacts.
-func (h *Handler) broadcastPresence(userID int, online bool) {
+// requestToken returns the access token of a connection request, taken from
+// the Authorization header, then a chatx.auth.<token> subprotocol, then the
+// deprecated token query parameter.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), SubprotocolAuthPrefix); ok {
+				return token
+			}
+		}
+	}
+
+	return r.URL.Query().Get("token")
+}
+
+// resumeFrom returns the sequence number a reconnecting client got last, from
+// the resume_from query parameter or the Last-Event-ID header an EventSource
+// reconnects with, or 0 for a new session.
+func resumeFrom(r *http.Request) int64 {
+	value := r.URL.Query().Get("resume_from")
+	if value == "" {
+		value = r.Header.Get("Last-Event-ID")
+	}
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seq < 0 {
+		return 0
+	}
+	return seq
+}
+
+// acceptOptions checks the request origin against the allowed origin
+// patterns, or skips the check when none are configured.
+func (h *Handler) acceptOptions() *websocket.AcceptOptions {
+	opts := &websocket.AcceptOptions{Subprotocols: Subprotocols}
+	if len(h.originPatterns) == 0 || slices.Contains(h.originPatterns, "*") {
+		opts.InsecureSkipVerify = true
+	} else {
+		opts.OriginPatterns = h.originPatterns
+	}
+	return opts
+}
+
+// broadcastPresence broadcasts user online/offline status to their contacts
+// in the workspace they connected to. Channels are skipped, their
+// subscribers aren't contacts.
+func (h *Handler) broadcastPresence(workspaceID, userID int, online bool) {
 	eventType := EventPresenceOffline
 	if online {
 		eventType = EventPresenceOnline
 	}
 
 	// Get user's chats and broadcast to all participants
-	chatIDs, err := h.chatRepo.GetUserChatIDs(context.Background(), userID)
+	chatIDs, err := h.chatRepo.GetUserChatIDs(context.Background(), workspaceID, userID)
 	if err != nil {
 		h.logger.Error("failed to get user chat IDs for presence broadcast",
 			"user_id", userID,
@@ -114,17 +271,58 @@ func (h *Handler) broadcastPresence(userID int, online bool) {
 		)
 		return
 	}
+	channelIDs, err := h.chatRepo.GetUserChannelIDs(context.Background(), workspaceID, userID)
+	if err != nil {
+		h.logger.Error("failed to get user channel IDs for presence broadcast",
+			"user_id", userID,
+			"error", err,
+		)
+		return
+	}
+	// Users on either side of a block don't see each other's presence
+	blockedIDs, err := h.authPr.GetBlockedUserIDs(context.Background(), userID)
+	if err != nil {
+		h.logger.Warn("failed to get blocked users for presence broadcast",
+			"user_id", userID,
+			"error", err,
+		)
+	}
 
+	payload := PresencePayload{
+		UserID: userID,
+		Online: online,
+	}
+	if !online {
+		payload.LastSeen = h.lastSeen(userID)
+	}
 	event := &Event{
-		Type: eventType,
-		Payload: PresencePayload{
-			UserID: userID,
-			Online: online,
-		},
+		Type:    eventType,
+		Payload: payload,
 	}
 
 	// Broadcast to all chats the user is part of
 	for _, chatID := range chatIDs {
-		h.hub.BroadcastToChat(chatID, event, userID)
+		if !slices.Contains(channelIDs, chatID) {
+			h.hub.BroadcastToChatExcept(chatID, event, userID, blockedIDs)
+		}
 	}
 }
+
+// lastSeen returns the last seen time of a user going offline, or nil if they
+// hide it from others.
+func (h *Handler) lastSeen(userID int) *time.Time {
+	user, err := h.authPr.GetUserByID(context.Background(), userID)
+	if err != nil {
+		h.logger.Warn("failed to get user for presence broadcast",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil
+	}
+	if user.HideLastSeen {
+		return nil
+	}
+
+	now := time.Now()
+	return &now
+}
@@ -3,9 +3,39 @@ package ws
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// numberBatchSize caps how many queued broadcasts are numbered in one
+	// replay store round trip.
+	numberBatchSize = 64
+
+	// lingerSweepInterval is how often users who didn't reconnect within
+	// the replay TTL are unsubscribed from their chats.
+	lingerSweepInterval = 30 * time.Second
 )
 
+// HubMode selects whether a hub shares events with other instances.
+type HubMode string
+
+const (
+	// HubModeLocal keeps events, subscriptions and presence in process, for
+	// deployments running a single instance.
+	HubModeLocal HubMode = "local"
+	// HubModeDistributed shares them with the other instances through Redis.
+	HubModeDistributed HubMode = "distributed"
+)
+
+func (m HubMode) IsValid() bool {
+	return m == HubModeLocal || m == HubModeDistributed
+}
+
 // Hub maintains the set of active clients and broadcasts messages.
 type Hub struct {
 	// clients maps userID to their active client connections.
@@ -28,6 +58,45 @@ type Hub struct {
 	// userBroadcast channel for events to be sent to specific users.
 	userBroadcast chan *UserBroadcastMessage
 
+	// numbered carries the broadcasts from the numbering stage to the Run
+	// loop, in the order they were numbered.
+	numbered chan delivery
+
+	// stop requests a graceful shutdown; the channel is closed once every
+	// client is gone.
+	stop chan chan struct{}
+
+	// closing is set once a graceful shutdown starts, so new connections
+	// are refused.
+	closing atomic.Bool
+
+	// drained is the channel of the graceful shutdown in progress. Only
+	// used by the Run loop.
+	drained chan struct{}
+
+	// presence shares online status with other instances; nil when the
+	// hub runs standalone.
+	presence *Presence
+
+	// relay forwards events and subscription changes to other instances;
+	// nil when standalone.
+	relay *Relay
+
+	// lastSeen records when users were last active; nil when not tracked.
+	lastSeen *LastSeen
+
+	// replay numbers events and keeps them for resuming clients; nil when
+	// clients can't resume.
+	replay *Replay
+
+	// lingering maps the users whose last connection closed to when they
+	// are unsubscribed from their chats. Until then the events they miss
+	// are still numbered and kept for them. Only used with replay.
+	lingering map[int]time.Time
+
+	// typing tracks the typing indicators of this instance's connections.
+	typing *typingIndicators
+
 	mu     sync.RWMutex
 	logger *slog.Logger
 }
@@ -36,31 +105,95 @@ type Hub struct {
 type BroadcastMessage struct {
 	ChatID    int
 	Event     *Event
-	ExcludeID int // UserID to exclude from broadcast (e.g., sender)
+	ExcludeID int    // UserID to exclude from broadcast (e.g., sender)
+	HiddenIDs []int  // Users who must not see the event, e.g. due to a block
+	EventID   string // Identifies the event on every instance when it's numbered
+}
+
+// Connection describes a WebSocket connection held by this instance.
+type Connection struct {
+	UserID       int
+	RemoteIP     string
+	ConnectedAt  time.Time
+	LastActivity time.Time
+	ChatCount    int   // Chats the connection receives events of
+	SentSeq      int64 // Sequence number of the last event sent
+	AckedSeq     int64 // Sequence number of the last event the client acknowledged
 }
 
 // UserBroadcastMessage contains an event to be sent to a specific user.
 type UserBroadcastMessage struct {
-	UserID int
-	Event  *Event
+	UserID  int
+	Event   *Event
+	EventID string // Identifies the event on every instance when it's numbered
+}
+
+// delivery is a queued broadcast with the sequence numbers of its local
+// recipients, or a graceful shutdown request following the broadcasts queued
+// before it.
+type delivery struct {
+	chat *BroadcastMessage
+	user *UserBroadcastMessage
+	seqs map[int]int64 // By recipient
+	stop chan struct{}
 }
 
-// NewHub creates a new Hub instance.
-func NewHub(logger *slog.Logger) *Hub {
-	return &Hub{
+// NewHub creates a new Hub instance. presence and relay may be nil, in which
+// case online status and relayed events only cover this instance, and so may
+// lastSeen, in which case no last seen times are recorded, and replay, in
+// which case events aren't numbered and clients can't resume. Typing
+// indicators expire after typingTTL without a new typing.start.
+func NewHub(
+	logger *slog.Logger,
+	presence *Presence,
+	relay *Relay,
+	lastSeen *LastSeen,
+	replay *Replay,
+	typingTTL time.Duration,
+) *Hub {
+	h := &Hub{
 		clients:           make(map[int]map[*Client]struct{}),
 		chatSubscriptions: make(map[int]map[int]struct{}),
 		register:          make(chan *Client),
 		unregister:        make(chan *Client),
 		broadcast:         make(chan *BroadcastMessage, 256),
 		userBroadcast:     make(chan *UserBroadcastMessage, 256),
+		numbered:          make(chan delivery, numberBatchSize),
+		stop:              make(chan chan struct{}),
+		presence:          presence,
+		relay:             relay,
+		lastSeen:          lastSeen,
+		replay:            replay,
+		lingering:         make(map[int]time.Time),
 		logger:            logger,
 	}
+	h.typing = newTypingIndicators(h, typingTTL)
+	return h
 }
 
 // Run starts the hub's main event loop.
 // This should be run in a separate goroutine.
 func (h *Hub) Run(ctx context.Context) {
+	if h.presence != nil {
+		go h.presence.run(ctx, h.connectionCounts)
+	}
+	if h.lastSeen != nil {
+		go h.lastSeen.run(ctx, h.lastActivity)
+	}
+	if h.relay != nil {
+		go h.relay.run(ctx, func(env *relayEnvelope) {
+			h.applyRelayed(ctx, env)
+		})
+	}
+	go h.numberEvents(ctx)
+
+	var sweep <-chan time.Time
+	if h.replay != nil {
+		ticker := time.NewTicker(lingerSweepInterval)
+		defer ticker.Stop()
+		sweep = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -72,14 +205,161 @@ func (h *Hub) Run(ctx context.Context) {
 
 		case client := <-h.unregister:
 			h.unregisterClient(client)
+			h.checkDrained()
+
+		case d := <-h.numbered:
+			switch {
+			case d.stop != nil:
+				h.drain(d.stop)
+			case d.chat != nil:
+				h.broadcastToChat(d.chat, d.seqs)
+			default:
+				h.broadcastToUser(d.user, d.seqs)
+			}
+
+		case <-sweep:
+			h.sweepLingering()
+		}
+	}
+}
 
+// numberEvents numbers the queued broadcasts for the local users they go to
+// and passes them on to the Run loop in order, so neither the callers nor the
+// Run loop wait on the replay store. A graceful shutdown request is passed on
+// after the broadcasts queued before it.
+func (h *Hub) numberEvents(ctx context.Context) {
+	for {
+		var first delivery
+		select {
+		case <-ctx.Done():
+			return
 		case msg := <-h.broadcast:
-			h.broadcastToChat(msg)
+			first = delivery{chat: msg}
+		case msg := <-h.userBroadcast:
+			first = delivery{user: msg}
+		case done := <-h.stop:
+			for batch := h.queued(nil); len(batch) > 0; batch = h.queued(nil) {
+				if !h.deliver(ctx, h.number(batch)) {
+					return
+				}
+			}
+			if !h.deliver(ctx, []delivery{{stop: done}}) {
+				return
+			}
+			continue
+		}
 
+		if !h.deliver(ctx, h.number(h.queued([]delivery{first}))) {
+			return
+		}
+	}
+}
+
+// queued adds the broadcasts already queued to batch, up to numberBatchSize.
+func (h *Hub) queued(batch []delivery) []delivery {
+	for len(batch) < numberBatchSize {
+		select {
+		case msg := <-h.broadcast:
+			batch = append(batch, delivery{chat: msg})
 		case msg := <-h.userBroadcast:
-			h.broadcastToUser(msg)
+			batch = append(batch, delivery{user: msg})
+		default:
+			return batch
 		}
 	}
+	return batch
+}
+
+// number sets the sequence numbers of the local recipients of the numbered
+// broadcasts in batch.
+func (h *Hub) number(batch []delivery) []delivery {
+	if h.replay == nil {
+		return batch
+	}
+
+	items := make([]replayItem, 0, len(batch))
+	numbered := make([]int, 0, len(batch)) // Index of each item's delivery
+
+	h.mu.RLock()
+	for i, d := range batch {
+		var item replayItem
+		switch {
+		case d.chat != nil && d.chat.EventID != "":
+			item = replayItem{
+				env: relayEnvelope{
+					Kind:      relayChat,
+					ChatID:    d.chat.ChatID,
+					ExcludeID: d.chat.ExcludeID,
+					HiddenIDs: d.chat.HiddenIDs,
+					EventID:   d.chat.EventID,
+				},
+				event:   d.chat.Event,
+				userIDs: h.chatRecipients(d.chat),
+			}
+		case d.user != nil && d.user.EventID != "" && h.present(d.user.UserID):
+			item = replayItem{
+				env:     relayEnvelope{Kind: relayUser, UserID: d.user.UserID, EventID: d.user.EventID},
+				event:   d.user.Event,
+				userIDs: []int{d.user.UserID},
+			}
+		}
+		if len(item.userIDs) > 0 {
+			items = append(items, item)
+			numbered = append(numbered, i)
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(items) == 0 {
+		return batch
+	}
+	seqs := h.replay.append(items)
+	for j, i := range numbered {
+		batch[i].seqs = seqs[j]
+	}
+	return batch
+}
+
+// deliver passes numbered broadcasts on to the Run loop and reports whether
+// they all were before ctx was done.
+func (h *Hub) deliver(ctx context.Context, batch []delivery) bool {
+	for _, d := range batch {
+		select {
+		case h.numbered <- d:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// Shutdown stops the hub gracefully: new connections are refused, queued
+// broadcasts are delivered, and every client writes what it has queued before
+// its connection is closed with StatusGoingAway. It returns once all clients
+// are gone, or with ctx's error when ctx is done first; the remaining clients
+// are then closed when Run's context is cancelled.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.closing.Store(true)
+
+	done := make(chan struct{})
+	select {
+	case h.stop <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Closing reports whether the hub is shutting down and refuses new
+// connections.
+func (h *Hub) Closing() bool {
+	return h.closing.Load()
 }
 
 // Register adds a client to the hub.
@@ -92,20 +372,121 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
-// BroadcastToChat sends an event to all participants of a chat.
+// BroadcastToChat sends an event to all participants of a chat, including
+// those connected to other instances. Publishing is synchronous so events
+// from one sender keep their order across instances.
 func (h *Hub) BroadcastToChat(chatID int, event *Event, excludeUserID int) {
-	h.broadcast <- &BroadcastMessage{
+	h.BroadcastToChatExcept(chatID, event, excludeUserID, nil)
+}
+
+// BroadcastToChatExcept works like BroadcastToChat but also skips the users
+// in hiddenIDs.
+func (h *Hub) BroadcastToChatExcept(chatID int, event *Event, excludeUserID int, hiddenIDs []int) {
+	msg := &BroadcastMessage{
 		ChatID:    chatID,
 		Event:     event,
 		ExcludeID: excludeUserID,
+		HiddenIDs: hiddenIDs,
+		EventID:   h.eventID(event),
+	}
+
+	h.broadcast <- msg
+	if h.relay != nil {
+		h.relay.publishChat(msg)
 	}
 }
 
-// BroadcastToUser sends an event to a specific user.
+// BroadcastToUser sends an event to all connections of a user, including
+// those to other instances.
 func (h *Hub) BroadcastToUser(userID int, event *Event) {
-	h.userBroadcast <- &UserBroadcastMessage{
-		UserID: userID,
-		Event:  event,
+	msg := &UserBroadcastMessage{
+		UserID:  userID,
+		Event:   event,
+		EventID: h.eventID(event),
+	}
+
+	h.userBroadcast <- msg
+	if h.relay != nil {
+		h.relay.publishUser(msg)
+	}
+}
+
+// eventID returns a new ID for an event to number, or an empty one for an
+// event that isn't numbered. Instances number an event once per user by its
+// ID, so a user's connections to different instances see the same numbers.
+func (h *Hub) eventID(event *Event) string {
+	if h.replay == nil || !event.Type.replayable() {
+		return ""
+	}
+	return uuid.NewString()
+}
+
+// Resume prepares a registered client to receive the events it missed since
+// the sequence number it last got, followed by a sync event, before any other
+// event. Without a number, or when clients can't resume, only the sync event
+// is sent.
+func (h *Hub) Resume(ctx context.Context, client *Client, resumeFrom int64) {
+	if h.replay == nil {
+		return
+	}
+
+	var (
+		events  []*Event
+		head    int64
+		resumed bool
+		err     error
+	)
+	if resumeFrom > 0 {
+		events, head, resumed, err = h.replay.since(ctx, client, resumeFrom)
+	} else {
+		head, err = h.replay.position(ctx, client.UserID())
+	}
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to read replayed events", "user_id", client.UserID(), "error", err)
+		events, resumed = nil, false
+	}
+	if !resumed {
+		events = nil
+	}
+
+	client.resume(events, &Event{
+		Type: EventSync,
+		Payload: SyncPayload{
+			Seq:      head,
+			Resumed:  resumed,
+			Replayed: len(events),
+		},
+	})
+}
+
+// applyRelayed applies an operation published by another instance to the
+// connections of this one.
+func (h *Hub) applyRelayed(ctx context.Context, env *relayEnvelope) {
+	event := &Event{Type: env.Type, Payload: env.Payload}
+
+	switch env.Kind {
+	case relayChat:
+		select {
+		case h.broadcast <- &BroadcastMessage{
+			ChatID:    env.ChatID,
+			Event:     event,
+			ExcludeID: env.ExcludeID,
+			HiddenIDs: env.HiddenIDs,
+			EventID:   env.EventID,
+		}:
+		case <-ctx.Done():
+		}
+	case relayUser:
+		select {
+		case h.userBroadcast <- &UserBroadcastMessage{UserID: env.UserID, Event: event, EventID: env.EventID}:
+		case <-ctx.Done():
+		}
+	case relayJoin:
+		h.joinChat(env.WorkspaceID, env.ChatID, env.UserID)
+	case relayLeave:
+		h.leaveChat(env.ChatID, env.UserID)
+	default:
+		h.logger.Warn("dropping relayed operation of unknown kind", "kind", env.Kind)
 	}
 }
 
@@ -133,17 +514,105 @@ func (h *Hub) UnsubscribeFromChat(chatID, userID int) {
 	}
 }
 
-// IsUserOnline checks if a user has any active connections.
-func (h *Hub) IsUserOnline(userID int) bool {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// JoinChat subscribes a user who was added to a chat, including the user's
+// already open connections on any instance, so they start receiving the
+// chat's events. Only connections to the chat's workspace are subscribed;
+// workspaceID 0, for Saved Messages, subscribes all of them.
+func (h *Hub) JoinChat(workspaceID, chatID, userID int) {
+	h.joinChat(workspaceID, chatID, userID)
+	if h.relay != nil {
+		h.relay.publishMembership(relayJoin, workspaceID, chatID, userID)
+	}
+}
+
+func (h *Hub) joinChat(workspaceID, chatID, userID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	clients, ok := h.clients[userID]
-	return ok && len(clients) > 0
+	if !ok {
+		// Kept for a user who may resume
+		if _, lingering := h.lingering[userID]; lingering {
+			if h.chatSubscriptions[chatID] == nil {
+				h.chatSubscriptions[chatID] = make(map[int]struct{})
+			}
+			h.chatSubscriptions[chatID][userID] = struct{}{}
+		}
+		return
+	}
+
+	if h.chatSubscriptions[chatID] == nil {
+		h.chatSubscriptions[chatID] = make(map[int]struct{})
+	}
+	h.chatSubscriptions[chatID][userID] = struct{}{}
+
+	for client := range clients {
+		if workspaceID == 0 || client.workspaceID == workspaceID {
+			client.joinChat(chatID)
+		}
+	}
+}
+
+// LeaveChat unsubscribes a user who was removed from a chat on every
+// instance.
+func (h *Hub) LeaveChat(chatID, userID int) {
+	h.leaveChat(chatID, userID)
+	if h.relay != nil {
+		h.relay.publishMembership(relayLeave, 0, chatID, userID)
+	}
+}
+
+func (h *Hub) leaveChat(chatID, userID int) {
+	h.mu.Lock()
+	if users, ok := h.chatSubscriptions[chatID]; ok {
+		delete(users, userID)
+		if len(users) == 0 {
+			delete(h.chatSubscriptions, chatID)
+		}
+	}
+
+	for client := range h.clients[userID] {
+		client.leaveChat(chatID)
+	}
+	h.mu.Unlock()
+
+	// The user can't send typing.stop for a chat they left
+	h.typing.stop(chatID, userID)
+}
+
+// IsUserOnline checks if a user has any active connections on any instance.
+func (h *Hub) IsUserOnline(ctx context.Context, userID int) bool {
+	return len(h.GetOnlineUsers(ctx, []int{userID})) > 0
 }
 
 // GetOnlineUsers returns a list of user IDs that are currently online.
-func (h *Hub) GetOnlineUsers(userIDs []int) []int {
+// Local connections always count; the presence store adds users connected
+// to other instances. If the store is unavailable only local status is used.
+func (h *Hub) GetOnlineUsers(ctx context.Context, userIDs []int) []int {
+	online := h.localOnlineUsers(userIDs)
+	if h.presence == nil || len(online) == len(userIDs) {
+		return online
+	}
+
+	remote, err := h.presence.onlineUsers(ctx, userIDs)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to read presence, using local status", "error", err)
+		return online
+	}
+
+	seen := make(map[int]struct{}, len(online))
+	for _, id := range online {
+		seen[id] = struct{}{}
+	}
+	for _, id := range remote {
+		if _, ok := seen[id]; !ok {
+			online = append(online, id)
+		}
+	}
+	return online
+}
+
+func (h *Hub) localOnlineUsers(userIDs []int) []int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -156,6 +625,87 @@ func (h *Hub) GetOnlineUsers(userIDs []int) []int {
 	return online
 }
 
+// GetLastSeen returns when the given users were last active, leaving out
+// users without a recorded time. It is empty if last seen isn't tracked or
+// the store is unavailable.
+func (h *Hub) GetLastSeen(ctx context.Context, userIDs []int) map[int]time.Time {
+	if h.lastSeen == nil {
+		return map[int]time.Time{}
+	}
+
+	seen, err := h.lastSeen.lookup(ctx, userIDs)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to read last seen", "error", err)
+		return map[int]time.Time{}
+	}
+	return seen
+}
+
+// lastActivity returns when each locally connected user was last heard from
+// on any of their connections.
+func (h *Hub) lastActivity() map[int]time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	activity := make(map[int]time.Time, len(h.clients))
+	for userID, clients := range h.clients {
+		for client := range clients {
+			if at := client.LastActivity(); at.After(activity[userID]) {
+				activity[userID] = at
+			}
+		}
+	}
+	return activity
+}
+
+// connectionCounts returns the number of local connections per user.
+func (h *Hub) connectionCounts() map[int]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make(map[int]int, len(h.clients))
+	for userID, clients := range h.clients {
+		counts[userID] = len(clients)
+	}
+	return counts
+}
+
+// Connections returns the connections held by this instance.
+func (h *Hub) Connections() []Connection {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	connections := make([]Connection, 0)
+	for userID, clients := range h.clients {
+		for client := range clients {
+			client.chatsMu.RLock()
+			chatCount := len(client.chats)
+			client.chatsMu.RUnlock()
+
+			connections = append(connections, Connection{
+				UserID:       userID,
+				RemoteIP:     client.remoteIP,
+				ConnectedAt:  client.connectedAt,
+				LastActivity: client.LastActivity(),
+				ChatCount:    chatCount,
+				SentSeq:      client.sentSeq.Load(),
+				AckedSeq:     client.ackedSeq.Load(),
+			})
+		}
+	}
+	return connections
+}
+
+// RemoteConnectionCounts returns the number of connections per user held by
+// other instances. It is empty when the hub runs standalone.
+func (h *Hub) RemoteConnectionCounts(ctx context.Context) (map[int]int, error) {
+	if h.presence == nil {
+		return map[int]int{}, nil
+	}
+
+	return h.presence.remoteCounts(ctx)
+}
+
 func (h *Hub) registerClient(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -165,6 +715,7 @@ func (h *Hub) registerClient(client *Client) {
 		h.clients[userID] = make(map[*Client]struct{})
 	}
 	h.clients[userID][client] = struct{}{}
+	delete(h.lingering, userID)
 
 	// Subscribe to all user's chats
 	for _, chatID := range client.ChatIDs() {
@@ -174,6 +725,15 @@ func (h *Hub) registerClient(client *Client) {
 		h.chatSubscriptions[chatID][userID] = struct{}{}
 	}
 
+	if h.presence != nil {
+		h.presence.notify(userID, len(h.clients[userID]))
+	}
+
+	// Registered while shutting down, so closed right after resuming
+	if h.closing.Load() {
+		client.drain()
+	}
+
 	h.logger.Info("client registered",
 		"user_id", userID,
 		"total_connections", len(h.clients[userID]),
@@ -192,15 +752,22 @@ func (h *Hub) unregisterClient(client *Client) {
 
 			if len(clients) == 0 {
 				delete(h.clients, userID)
-				// Clean up chat subscriptions for this user
-				for chatID, users := range h.chatSubscriptions {
-					delete(users, userID)
-					if len(users) == 0 {
-						delete(h.chatSubscriptions, chatID)
-					}
+				if h.lastSeen != nil {
+					h.lastSeen.notify(userID, time.Now())
+				}
+				if h.replay != nil {
+					// Keep numbering the user's events until they
+					// can no longer resume
+					h.lingering[userID] = time.Now().Add(h.replay.ttl)
+				} else {
+					h.dropSubscriptions(userID)
 				}
 			}
 
+			if h.presence != nil {
+				h.presence.notify(userID, len(h.clients[userID]))
+			}
+
 			h.logger.Info("client unregistered",
 				"user_id", userID,
 				"remaining_connections", len(h.clients[userID]),
@@ -209,39 +776,91 @@ func (h *Hub) unregisterClient(client *Client) {
 	}
 }
 
-func (h *Hub) broadcastToChat(msg *BroadcastMessage) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// dropSubscriptions unsubscribes a user from all chats.
+// Must be called with write lock held.
+func (h *Hub) dropSubscriptions(userID int) {
+	for chatID, users := range h.chatSubscriptions {
+		delete(users, userID)
+		if len(users) == 0 {
+			delete(h.chatSubscriptions, chatID)
+		}
+	}
+}
 
-	users, ok := h.chatSubscriptions[msg.ChatID]
-	if !ok {
-		return
+// sweepLingering unsubscribes the users who didn't reconnect within the
+// replay TTL.
+func (h *Hub) sweepLingering() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for userID, until := range h.lingering {
+		if now.After(until) {
+			delete(h.lingering, userID)
+			h.dropSubscriptions(userID)
+		}
 	}
+}
 
+// present reports whether a user is connected or may still resume.
+// Must be called with read lock held.
+func (h *Hub) present(userID int) bool {
+	if _, ok := h.clients[userID]; ok {
+		return true
+	}
+	_, ok := h.lingering[userID]
+	return ok
+}
+
+// chatRecipients returns the subscribed users a chat event goes to.
+// Must be called with read lock held.
+func (h *Hub) chatRecipients(msg *BroadcastMessage) []int {
+	users := h.chatSubscriptions[msg.ChatID]
+	recipients := make([]int, 0, len(users))
 	for userID := range users {
-		if userID == msg.ExcludeID {
+		if userID == msg.ExcludeID || slices.Contains(msg.HiddenIDs, userID) {
 			continue
 		}
-		h.sendToUser(userID, msg.Event)
+		recipients = append(recipients, userID)
 	}
+	return recipients
 }
 
-func (h *Hub) broadcastToUser(msg *UserBroadcastMessage) {
+func (h *Hub) broadcastToChat(msg *BroadcastMessage, seqs map[int]int64) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	h.sendToUser(msg.UserID, msg.Event)
+	for _, userID := range h.chatRecipients(msg) {
+		h.sendToUser(userID, msg.ChatID, msg.Event, seqs[userID])
+	}
 }
 
-// sendToUser sends an event to all connections of a user.
+func (h *Hub) broadcastToUser(msg *UserBroadcastMessage, seqs map[int]int64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	h.sendToUser(msg.UserID, 0, msg.Event, seqs[msg.UserID])
+}
+
+// sendToUser sends an event to all connections of a user or, for a chat
+// event, those subscribed to the chat, as a user's connections to other
+// workspaces don't receive its events. A non-zero seq is the event's number
+// for the user.
 // Must be called with read lock held.
-func (h *Hub) sendToUser(userID int, event *Event) {
+func (h *Hub) sendToUser(userID, chatID int, event *Event, seq int64) {
 	clients, ok := h.clients[userID]
 	if !ok {
 		return
 	}
+	if seq > 0 {
+		event = &Event{Type: event.Type, Payload: event.Payload, Seq: seq}
+	}
 
 	for client := range clients {
+		if chatID != 0 && !client.InChat(chatID) {
+			continue
+		}
+
 		select {
 		case client.send <- event:
 		default:
@@ -253,17 +872,62 @@ func (h *Hub) sendToUser(userID int, event *Event) {
 	}
 }
 
+// drain makes every client close its connection after writing its queued
+// events, the broadcasts queued before the shutdown request included. done
+// is closed once all clients are unregistered.
+func (h *Hub) drain(done chan struct{}) {
+	h.drained = done
+
+	h.mu.RLock()
+	count := 0
+	for _, clients := range h.clients {
+		for client := range clients {
+			client.drain()
+			count++
+		}
+	}
+	h.mu.RUnlock()
+
+	h.logger.Info("draining websocket connections", "connections", count)
+	h.checkDrained()
+}
+
+// checkDrained completes a graceful shutdown in progress once no client is
+// left.
+func (h *Hub) checkDrained() {
+	if h.drained == nil {
+		return
+	}
+
+	h.mu.RLock()
+	empty := len(h.clients) == 0
+	h.mu.RUnlock()
+
+	if empty {
+		close(h.drained)
+		h.drained = nil
+		h.logger.Info("websocket connections drained")
+	}
+}
+
 func (h *Hub) shutdown() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	// Users still connected at shutdown were seen just now
+	seen := make(map[int]time.Time, len(h.clients))
 	for userID, clients := range h.clients {
 		for client := range clients {
 			client.Close()
 		}
 		delete(h.clients, userID)
+		seen[userID] = time.Now()
+	}
+	if h.lastSeen != nil {
+		h.lastSeen.write(context.Background(), seen)
 	}
 
 	h.chatSubscriptions = make(map[int]map[int]struct{})
+	h.lingering = make(map[int]time.Time)
 	h.logger.Info("hub shutdown complete")
 }
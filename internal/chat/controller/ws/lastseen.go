@@ -0,0 +1,149 @@
+package ws
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// lastSeenWriteTimeout bounds a single last seen store round trip.
+const lastSeenWriteTimeout = 3 * time.Second
+
+// LastSeenStore persists when users were last active.
+type LastSeenStore interface {
+	SetLastSeen(ctx context.Context, seen map[int]time.Time) error
+	LastSeen(ctx context.Context, userIDs []int) (map[int]time.Time, error)
+}
+
+// LastSeen records when users were last active to a LastSeenStore. A user's
+// time is written when their last connection to this instance closes and,
+// while they stay connected, every interval if they were heard from since.
+type LastSeen struct {
+	store    LastSeenStore
+	interval time.Duration
+	updates  chan lastSeenUpdate
+	logger   *slog.Logger
+}
+
+type lastSeenUpdate struct {
+	userID int
+	at     time.Time
+}
+
+// NewLastSeen creates a last seen recorder flushing activity every interval.
+func NewLastSeen(store LastSeenStore, interval time.Duration, logger *slog.Logger) *LastSeen {
+	return &LastSeen{
+		store:    store,
+		interval: interval,
+		updates:  make(chan lastSeenUpdate, 1024),
+		logger:   logger,
+	}
+}
+
+// notify queues the time a user disconnected. If the queue is full the time
+// is dropped and the user keeps the one of the last flush.
+func (l *LastSeen) notify(userID int, at time.Time) {
+	select {
+	case l.updates <- lastSeenUpdate{userID: userID, at: at}:
+	default:
+		l.logger.Warn("last seen update queue full, dropping update", "user_id", userID)
+	}
+}
+
+// run writes disconnects as they happen and the activity of connected users
+// every interval until ctx is done, then writes the disconnects still queued.
+func (l *LastSeen) run(ctx context.Context, activity func() map[int]time.Time) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	// written is the activity of the last flush, so idle connections aren't
+	// written again on every tick
+	written := make(map[int]time.Time)
+
+	for {
+		select {
+		case <-ctx.Done():
+			pending := make(map[int]time.Time)
+			for len(l.updates) > 0 {
+				u := <-l.updates
+				pending[u.userID] = u.at
+			}
+			l.write(context.Background(), pending)
+			return
+
+		case u := <-l.updates:
+			l.write(ctx, map[int]time.Time{u.userID: u.at})
+
+		case <-ticker.C:
+			current := activity()
+			changed := make(map[int]time.Time)
+			for userID, at := range current {
+				if !at.Equal(written[userID]) {
+					changed[userID] = at
+				}
+			}
+			if l.write(ctx, changed) {
+				written = current
+			}
+		}
+	}
+}
+
+// write stores the given times and reports whether it succeeded.
+func (l *LastSeen) write(ctx context.Context, seen map[int]time.Time) bool {
+	if len(seen) == 0 {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lastSeenWriteTimeout)
+	defer cancel()
+
+	if err := l.store.SetLastSeen(ctx, seen); err != nil {
+		l.logger.Error("failed to write last seen", "users", len(seen), "error", err)
+		return false
+	}
+	return true
+}
+
+func (l *LastSeen) lookup(ctx context.Context, userIDs []int) (map[int]time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, lastSeenWriteTimeout)
+	defer cancel()
+
+	return l.store.LastSeen(ctx, userIDs)
+}
+
+// MemoryLastSeenStore is an in-process LastSeenStore for development without
+// Redis.
+type MemoryLastSeenStore struct {
+	mu   sync.RWMutex
+	seen map[int]time.Time
+}
+
+// NewMemoryLastSeenStore creates an in-process last seen store.
+func NewMemoryLastSeenStore() *MemoryLastSeenStore {
+	return &MemoryLastSeenStore{seen: make(map[int]time.Time)}
+}
+
+func (s *MemoryLastSeenStore) SetLastSeen(ctx context.Context, seen map[int]time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, at := range seen {
+		s.seen[userID] = at
+	}
+	return nil
+}
+
+func (s *MemoryLastSeenStore) LastSeen(ctx context.Context, userIDs []int) (map[int]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[int]time.Time, len(userIDs))
+	for _, userID := range userIDs {
+		if at, ok := s.seen[userID]; ok {
+			seen[userID] = at
+		}
+	}
+	return seen, nil
+}
@@ -0,0 +1,119 @@
+package ws
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// presenceWriteTimeout bounds a single presence store round trip.
+const presenceWriteTimeout = 3 * time.Second
+
+// PresenceStore persists online status shared by all API instances.
+type PresenceStore interface {
+	SetPresence(ctx context.Context, instanceID string, counts map[int]int, ttl time.Duration) error
+	ClearInstancePresence(ctx context.Context, instanceID string) error
+	OnlineUsers(ctx context.Context, userIDs []int) ([]int, error)
+	InstancePresence(ctx context.Context) (map[string]map[int]int, error)
+}
+
+// Presence publishes this instance's connection counts to a PresenceStore.
+// Changes are pushed as they happen and the full snapshot is re-published on
+// every heartbeat, so entries of a crashed instance expire after one TTL.
+type Presence struct {
+	store      PresenceStore
+	instanceID string
+	ttl        time.Duration
+	updates    chan presenceUpdate
+	logger     *slog.Logger
+}
+
+type presenceUpdate struct {
+	userID int
+	count  int
+}
+
+// NewPresence creates a presence publisher for the given instance.
+func NewPresence(store PresenceStore, instanceID string, ttl time.Duration, logger *slog.Logger) *Presence {
+	return &Presence{
+		store:      store,
+		instanceID: instanceID,
+		ttl:        ttl,
+		updates:    make(chan presenceUpdate, 1024),
+		logger:     logger,
+	}
+}
+
+// notify queues a connection count change. If the queue is full the change
+// is dropped; the next heartbeat publishes the correct value anyway.
+func (p *Presence) notify(userID, count int) {
+	select {
+	case p.updates <- presenceUpdate{userID: userID, count: count}:
+	default:
+		p.logger.Warn("presence update queue full, deferring to heartbeat", "user_id", userID)
+	}
+}
+
+// run publishes updates and heartbeats until ctx is done, then removes this
+// instance's entries from the store.
+func (p *Presence) run(ctx context.Context, snapshot func() map[int]int) {
+	ticker := time.NewTicker(p.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			clearCtx, cancel := context.WithTimeout(context.Background(), presenceWriteTimeout)
+			if err := p.store.ClearInstancePresence(clearCtx, p.instanceID); err != nil {
+				p.logger.Error("failed to clear presence", "instance_id", p.instanceID, "error", err)
+			}
+			cancel()
+			return
+
+		case u := <-p.updates:
+			p.publish(ctx, map[int]int{u.userID: u.count})
+
+		case <-ticker.C:
+			p.publish(ctx, snapshot())
+		}
+	}
+}
+
+func (p *Presence) publish(ctx context.Context, counts map[int]int) {
+	ctx, cancel := context.WithTimeout(ctx, presenceWriteTimeout)
+	defer cancel()
+
+	if err := p.store.SetPresence(ctx, p.instanceID, counts, p.ttl); err != nil {
+		p.logger.Error("failed to publish presence", "instance_id", p.instanceID, "error", err)
+	}
+}
+
+func (p *Presence) onlineUsers(ctx context.Context, userIDs []int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, presenceWriteTimeout)
+	defer cancel()
+
+	return p.store.OnlineUsers(ctx, userIDs)
+}
+
+// remoteCounts returns the connection counts per user held by the other
+// instances.
+func (p *Presence) remoteCounts(ctx context.Context) (map[int]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, presenceWriteTimeout)
+	defer cancel()
+
+	instances, err := p.store.InstancePresence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	for instanceID, users := range instances {
+		if instanceID == p.instanceID {
+			continue
+		}
+		for userID, count := range users {
+			counts[userID] += count
+		}
+	}
+	return counts, nil
+}
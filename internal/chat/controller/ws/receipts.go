@@ -0,0 +1,73 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+// readReceipts coalesces the read receipts of a user in a chat. A client
+// reading through a chat marks many messages in a row; participants get one
+// message.read per window with the latest message instead of one per mark.
+type readReceipts struct {
+	hub    *Hub
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[readReceiptKey]*MessageReadPayload
+}
+
+type readReceiptKey struct {
+	chatID int
+	userID int
+}
+
+func newReadReceipts(hub *Hub, window time.Duration) *readReceipts {
+	return &readReceipts{
+		hub:     hub,
+		window:  window,
+		pending: make(map[readReceiptKey]*MessageReadPayload),
+	}
+}
+
+// add queues a receipt. The first receipt of a user in a chat starts the
+// window, later ones in it replace the queued receipt if they read further.
+func (r *readReceipts) add(receipt MessageReadPayload) {
+	if r.window <= 0 {
+		r.send(receipt)
+		return
+	}
+
+	key := readReceiptKey{chatID: receipt.ChatID, userID: receipt.UserID}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if queued, ok := r.pending[key]; ok {
+		if receipt.MessageID >= queued.MessageID {
+			*queued = receipt
+		}
+		return
+	}
+
+	r.pending[key] = &receipt
+	time.AfterFunc(r.window, func() { r.flush(key) })
+}
+
+func (r *readReceipts) flush(key readReceiptKey) {
+	r.mu.Lock()
+	receipt, ok := r.pending[key]
+	delete(r.pending, key)
+	r.mu.Unlock()
+
+	if ok {
+		r.send(*receipt)
+	}
+}
+
+func (r *readReceipts) send(receipt MessageReadPayload) {
+	event := &Event{
+		Type:    EventMessageRead,
+		Payload: receipt,
+	}
+	r.hub.BroadcastToChat(receipt.ChatID, event, receipt.UserID) // Exclude the reader
+}
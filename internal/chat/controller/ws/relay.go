@@ -0,0 +1,167 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+const (
+	// relayChannel is the backplane channel carrying hub operations between
+	// instances.
+	relayChannel = "chatx:ws:chat-events"
+
+	// relayPublishTimeout bounds a single publish to the backplane.
+	relayPublishTimeout = 3 * time.Second
+
+	// relayRetryDelay is the pause before resubscribing after a backplane failure.
+	relayRetryDelay = 2 * time.Second
+)
+
+// Backplane is a pub/sub transport shared by all API instances.
+type Backplane interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error
+}
+
+// Relay forwards events and chat subscription changes to hubs running on
+// other instances, so clients connected to different replicas receive the
+// same events.
+type Relay struct {
+	backplane  Backplane
+	instanceID string
+	logger     *slog.Logger
+}
+
+// Kinds of relayed hub operations.
+const (
+	relayChat  = ""      // Event for the participants of a chat
+	relayUser  = "user"  // Event for the connections of a user
+	relayJoin  = "join"  // A user was subscribed to a chat
+	relayLeave = "leave" // A user was unsubscribed from a chat
+)
+
+// relayEnvelope is the wire format of a relayed hub operation. Chat events
+// keep the envelope of earlier releases, so instances can be upgraded one by
+// one.
+type relayEnvelope struct {
+	Origin      string          `json:"origin"`
+	Kind        string          `json:"kind,omitempty"`
+	ChatID      int             `json:"chat_id,omitempty"`
+	UserID      int             `json:"user_id,omitempty"`
+	WorkspaceID int             `json:"workspace_id,omitempty"` // join only
+	ExcludeID   int             `json:"exclude_id,omitempty"`
+	HiddenIDs   []int           `json:"hidden_ids,omitempty"`
+	Type        EventType       `json:"type,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	EventID     string          `json:"event_id,omitempty"`
+}
+
+// NewRelay creates a relay publishing on behalf of the given instance.
+func NewRelay(backplane Backplane, instanceID string, logger *slog.Logger) *Relay {
+	return &Relay{
+		backplane:  backplane,
+		instanceID: instanceID,
+		logger:     logger,
+	}
+}
+
+// publishChat sends a chat event to the other instances.
+func (r *Relay) publishChat(msg *BroadcastMessage) {
+	r.publishEvent(relayEnvelope{
+		Kind:      relayChat,
+		ChatID:    msg.ChatID,
+		ExcludeID: msg.ExcludeID,
+		HiddenIDs: msg.HiddenIDs,
+		EventID:   msg.EventID,
+	}, msg.Event)
+}
+
+// publishUser sends an event for a user to the other instances.
+func (r *Relay) publishUser(msg *UserBroadcastMessage) {
+	r.publishEvent(relayEnvelope{
+		Kind:    relayUser,
+		UserID:  msg.UserID,
+		EventID: msg.EventID,
+	}, msg.Event)
+}
+
+// publishMembership tells the other instances that a user joined or left a
+// chat of a workspace.
+func (r *Relay) publishMembership(kind string, workspaceID, chatID, userID int) {
+	r.publish(relayEnvelope{
+		Kind:        kind,
+		ChatID:      chatID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+	})
+}
+
+func (r *Relay) publishEvent(env relayEnvelope, event *Event) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		r.logger.Error("failed to encode relayed event", "type", event.Type, "error", err)
+		return
+	}
+
+	env.Type = event.Type
+	env.Payload = payload
+	r.publish(env)
+}
+
+func (r *Relay) publish(env relayEnvelope) {
+	env.Origin = r.instanceID
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		r.logger.Error("failed to encode relay envelope", "kind", env.Kind, "type", env.Type, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayPublishTimeout)
+	defer cancel()
+
+	if err := r.backplane.Publish(ctx, relayChannel, data); err != nil {
+		r.logger.Error("failed to publish relayed event",
+			"kind", env.Kind,
+			"type", env.Type,
+			"chat_id", env.ChatID,
+			"user_id", env.UserID,
+			"error", err,
+		)
+	}
+}
+
+// run applies operations published by other instances until ctx is done,
+// resubscribing after backplane failures.
+func (r *Relay) run(ctx context.Context, apply func(*relayEnvelope)) {
+	handle := func(data []byte) {
+		var env relayEnvelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			r.logger.Warn("dropping malformed relayed event", "error", err)
+			return
+		}
+		if env.Origin == r.instanceID {
+			return
+		}
+
+		apply(&env)
+	}
+
+	for {
+		err := r.backplane.Subscribe(ctx, relayChannel, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.logger.Error("relay subscription failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayRetryDelay):
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// replayTimeout bounds a single replay store round trip.
+const replayTimeout = 3 * time.Second
+
+// ReplayStore keeps a stream of numbered events per user, shared by all API
+// instances, for clients resuming after a reconnect.
+type ReplayStore interface {
+	// AppendReplay keeps events, by ID, for up to ttl and appends them to
+	// the streams of the users they are sent to, in the given order, which
+	// keep their maxEvents latest events. It returns the sequence numbers
+	// the events got by user and event ID. An event already in a user's
+	// stream, appended by another instance, keeps its number.
+	AppendReplay(ctx context.Context, events map[string][]byte, streams map[int][]string, maxEvents int, ttl time.Duration) (map[int]map[string]int64, error)
+	// ReplaySince returns the last sequence number of a user's stream and
+	// the events still kept after the given one, by sequence number.
+	ReplaySince(ctx context.Context, userID int, after int64) (int64, map[int64][]byte, error)
+}
+
+// Replay gives the events sent to each user increasing sequence numbers and
+// keeps them for a while, so a client reconnecting with the last number it
+// got receives the events it missed instead of refetching everything.
+// Typing and presence events aren't numbered.
+type Replay struct {
+	store  ReplayStore
+	ttl    time.Duration
+	size   int
+	logger *slog.Logger
+}
+
+// replayEntry is how an event is kept: the relay envelope with when it was
+// sent, as the store may keep events longer than the TTL.
+type replayEntry struct {
+	relayEnvelope
+	At int64 `json:"at"` // unix ms
+}
+
+// replayItem is an event to number for its local recipients.
+type replayItem struct {
+	env     relayEnvelope
+	event   *Event
+	userIDs []int
+}
+
+// NewReplay creates a replay keeping the size latest events of each user for
+// ttl.
+func NewReplay(store ReplayStore, ttl time.Duration, size int, logger *slog.Logger) *Replay {
+	return &Replay{
+		store:  store,
+		ttl:    ttl,
+		size:   size,
+		logger: logger,
+	}
+}
+
+// append keeps events routed as described by their envelopes for their users
+// and returns their sequence numbers by item and user. Events that couldn't
+// be kept have none; clients then get them unnumbered and can't resume past
+// them.
+func (r *Replay) append(items []replayItem) []map[int]int64 {
+	seqs := make([]map[int]int64, len(items))
+	events := make(map[string][]byte, len(items))
+	streams := make(map[int][]string)
+
+	at := time.Now().UnixMilli()
+	for _, item := range items {
+		payload, err := json.Marshal(item.event.Payload)
+		if err != nil {
+			r.logger.Error("failed to encode replayed event", "type", item.event.Type, "error", err)
+			continue
+		}
+
+		env := item.env
+		env.Type = item.event.Type
+		env.Payload = payload
+		data, err := json.Marshal(replayEntry{relayEnvelope: env, At: at})
+		if err != nil {
+			r.logger.Error("failed to encode replayed event", "type", item.event.Type, "error", err)
+			continue
+		}
+
+		events[env.EventID] = data
+		for _, userID := range item.userIDs {
+			streams[userID] = append(streams[userID], env.EventID)
+		}
+	}
+	if len(events) == 0 {
+		return seqs
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+	defer cancel()
+
+	numbered, err := r.store.AppendReplay(ctx, events, streams, r.size, r.ttl)
+	if err != nil {
+		r.logger.Error("failed to keep events for replay", "events", len(events), "error", err)
+		return seqs
+	}
+	for i, item := range items {
+		seqs[i] = make(map[int]int64, len(item.userIDs))
+		for _, userID := range item.userIDs {
+			if seq := numbered[userID][item.env.EventID]; seq > 0 {
+				seqs[i][userID] = seq
+			}
+		}
+	}
+	return seqs
+}
+
+// since returns the events for client numbered after seq, the last sequence
+// number assigned for its user, and whether none of the user's events after
+// seq were dropped. Events are filtered by the chats the client is in now.
+func (r *Replay) since(ctx context.Context, client *Client, seq int64) ([]*Event, int64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	head, kept, err := r.store.ReplaySince(ctx, client.UserID(), seq)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if seq > head {
+		// The numbering restarted, so seq is from before
+		return nil, head, false, nil
+	}
+
+	seqs := make([]int64, 0, len(kept))
+	for s := range kept {
+		seqs = append(seqs, s)
+	}
+	slices.Sort(seqs)
+
+	cutoff := time.Now().Add(-r.ttl).UnixMilli()
+	events := make([]*Event, 0)
+	next := seq + 1
+	for _, s := range seqs {
+		var entry replayEntry
+		if err := json.Unmarshal(kept[s], &entry); err != nil {
+			r.logger.Warn("dropping malformed replayed event", "seq", s, "error", err)
+			continue
+		}
+		if entry.At < cutoff {
+			continue
+		}
+		if s != next {
+			// Expired or evicted events in between
+			return nil, head, false, nil
+		}
+		next++
+
+		if client.wants(&entry.relayEnvelope) {
+			events = append(events, &Event{Type: entry.Type, Payload: entry.Payload, Seq: s})
+		}
+	}
+
+	return events, head, next > head, nil
+}
+
+// position returns the last sequence number assigned for a user.
+func (r *Replay) position(ctx context.Context, userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	// Nothing is kept after the largest number, so only the position is read
+	head, _, err := r.store.ReplaySince(ctx, userID, math.MaxInt64)
+	return head, err
+}
+
+// MemoryReplayStore is an in-process ReplayStore for development without
+// Redis.
+type MemoryReplayStore struct {
+	mu      sync.Mutex
+	events  map[string]memoryReplayEvent
+	streams map[int]*memoryReplayStream // By user
+}
+
+type memoryReplayEvent struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// memoryReplayStream holds the numbered events of a user.
+type memoryReplayStream struct {
+	seq       int64
+	ids       []string         // Kept events, oldest first
+	seqs      map[string]int64 // Sequence numbers of the kept events
+	expiresAt time.Time
+}
+
+// NewMemoryReplayStore creates an in-process replay store.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{
+		events:  make(map[string]memoryReplayEvent),
+		streams: make(map[int]*memoryReplayStream),
+	}
+}
+
+func (s *MemoryReplayStore) AppendReplay(ctx context.Context, events map[string][]byte, streams map[int][]string, maxEvents int, ttl time.Duration) (map[int]map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, e := range s.events {
+		if e.expiresAt.Before(now) {
+			delete(s.events, id)
+		}
+	}
+	for id, data := range events {
+		if _, ok := s.events[id]; !ok {
+			s.events[id] = memoryReplayEvent{data: data, expiresAt: now.Add(ttl)}
+		}
+	}
+
+	seqs := make(map[int]map[string]int64, len(streams))
+	for userID, ids := range streams {
+		stream, ok := s.streams[userID]
+		if !ok {
+			stream = &memoryReplayStream{seqs: make(map[string]int64)}
+			s.streams[userID] = stream
+		}
+		if stream.expiresAt.Before(now) {
+			// The numbering goes on, as it would after a restart
+			stream.ids = nil
+			clear(stream.seqs)
+		}
+
+		seqs[userID] = make(map[string]int64, len(ids))
+		for _, id := range ids {
+			seq, ok := stream.seqs[id]
+			if !ok {
+				stream.seq++
+				seq = stream.seq
+				stream.ids = append(stream.ids, id)
+				stream.seqs[id] = seq
+			}
+			seqs[userID][id] = seq
+		}
+
+		// Drop the oldest events beyond maxEvents
+		drop := max(len(stream.ids)-maxEvents, 0)
+		for _, id := range stream.ids[:drop] {
+			delete(stream.seqs, id)
+		}
+		stream.ids = slices.Delete(stream.ids, 0, drop)
+		stream.expiresAt = now.Add(ttl)
+	}
+	return seqs, nil
+}
+
+func (s *MemoryReplayStore) ReplaySince(ctx context.Context, userID int, after int64) (int64, map[int64][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make(map[int64][]byte)
+	stream, ok := s.streams[userID]
+	if !ok {
+		return 0, events, nil
+	}
+	if stream.expiresAt.Before(time.Now()) {
+		return stream.seq, events, nil
+	}
+	for _, id := range stream.ids {
+		e, ok := s.events[id]
+		if seq := stream.seqs[id]; ok && seq > after {
+			events[seq] = e.data
+		}
+	}
+	return stream.seq, events, nil
+}
@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// Conn is the connection a client's events go through: a WebSocket
+// connection, or a Server-Sent Events stream for clients behind proxies that
+// block WebSockets.
+type Conn interface {
+	Subprotocol() string
+	SetReadLimit(n int64)
+	Read(ctx context.Context) (websocket.MessageType, []byte, error)
+	Write(ctx context.Context, typ websocket.MessageType, data []byte) error
+	Ping(ctx context.Context) error
+	Close(code websocket.StatusCode, reason string) error
+}
+
+var errStreamReceive = errors.New("event streams don't receive messages")
+
+// streamConn sends events as a Server-Sent Events stream. Clients can't send
+// anything through it, so reads block until the stream ends, and pings are
+// comments keeping proxies from closing an idle stream.
+type streamConn struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+
+	// peerGone is done when the peer disconnects.
+	peerGone context.Context
+
+	mu        sync.Mutex // Serializes writes
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newStreamConn starts an event stream on w. peerGone is the request's
+// context.
+func newStreamConn(peerGone context.Context, w http.ResponseWriter) (*streamConn, error) {
+	s := &streamConn{
+		w:        w,
+		rc:       http.NewResponseController(w),
+		peerGone: peerGone,
+		closed:   make(chan struct{}),
+	}
+
+	// Streams outlive the server's read and write timeouts. Hitting the
+	// read timeout would cancel the request's context, ending the stream.
+	if err := s.rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+	if err := s.rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := s.rc.Flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Subprotocol returns no subprotocol, events are sent as JSON.
+func (s *streamConn) Subprotocol() string {
+	return ""
+}
+
+func (s *streamConn) SetReadLimit(int64) {}
+
+// Read waits for the stream to end. A peer disconnecting is reported like a
+// WebSocket peer going away.
+func (s *streamConn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	select {
+	case <-s.peerGone.Done():
+		return 0, nil, websocket.CloseError{Code: websocket.StatusGoingAway}
+	case <-ctx.Done():
+		if s.peerGone.Err() != nil {
+			return 0, nil, websocket.CloseError{Code: websocket.StatusGoingAway}
+		}
+		return 0, nil, ctx.Err()
+	case <-s.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (s *streamConn) Write(ctx context.Context, _ websocket.MessageType, data []byte) error {
+	return s.send(ctx, data)
+}
+
+// Ping writes a comment line.
+func (s *streamConn) Ping(ctx context.Context) error {
+	return s.send(ctx, []byte(": ping\n\n"))
+}
+
+// Close ends the stream. No more events are written, the response is
+// finished once the client's pumps stop.
+func (s *streamConn) Close(websocket.StatusCode, string) error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
+
+func (s *streamConn) send(ctx context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+		return net.ErrClosed
+	default:
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.rc.SetWriteDeadline(deadline)
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// streamCodec writes events as Server-Sent Events: the JSON event, as sent
+// over WebSocket, with its sequence number as the event ID so a reconnecting
+// EventSource resumes with Last-Event-ID.
+type streamCodec struct{}
+
+func (streamCodec) encode(event *Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sync event carries where the stream stands
+	seq := event.Seq
+	if sync, ok := event.Payload.(SyncPayload); ok {
+		seq = sync.Seq
+	}
+
+	frame := make([]byte, 0, len(data)+32)
+	if seq > 0 {
+		frame = append(frame, "id: "...)
+		frame = strconv.AppendInt(frame, seq, 10)
+		frame = append(frame, '\n')
+	}
+	frame = append(frame, "data: "...)
+	frame = append(frame, data...)
+	return append(frame, "\n\n"...), nil
+}
+
+func (streamCodec) decode([]byte, *ClientMessage) error {
+	return errStreamReceive
+}
+
+func (streamCodec) messageType() websocket.MessageType {
+	return websocket.MessageText
+}
@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamOutlivesReadTimeout(t *testing.T) {
+	const readTimeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name  string
+		http2 bool
+	}{
+		{name: "HTTP/1.1"},
+		{name: "HTTP/2", http2: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readErr := make(chan error, 1)
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := newStreamConn(r.Context(), w)
+				if err != nil {
+					readErr <- err
+					return
+				}
+
+				// Reading ends early if the stream is taken for gone
+				ctx, cancel := context.WithTimeout(context.Background(), 5*readTimeout)
+				defer cancel()
+				_, _, err = conn.Read(ctx)
+				readErr <- err
+
+				_ = conn.Write(r.Context(), streamCodec{}.messageType(), []byte("data: still open\n\n"))
+			}))
+			srv.Config.ReadTimeout = readTimeout
+			if tt.http2 {
+				srv.EnableHTTP2 = true
+				srv.StartTLS()
+			} else {
+				srv.Start()
+			}
+			defer srv.Close()
+
+			resp, err := srv.Client().Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", got)
+			}
+
+			if err := <-readErr; !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("stream ended with %v, want it open past the read timeout", err)
+			}
+
+			line, err := bufio.NewReader(resp.Body).ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "data: still open\n"; line != want {
+				t.Errorf("got %q, want %q", line, want)
+			}
+		})
+	}
+}
@@ -1,43 +1,113 @@
 package ws
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // EventType represents the type of WebSocket event.
 type EventType string
 
 const (
 	// Message events
-	EventMessageNew    EventType = "message.new"
-	EventMessageEdit   EventType = "message.edit"
-	EventMessageDelete EventType = "message.delete"
-	EventMessageRead   EventType = "message.read"
+	EventMessageSend    EventType = "message.send" // client only
+	EventMessageNew     EventType = "message.new"
+	EventMessageEdit    EventType = "message.edit"
+	EventMessageUpdated EventType = "message.updated"
+	EventMessageDelete  EventType = "message.delete"
+	EventMessageRead    EventType = "message.read"
+	EventMessageMention EventType = "message.mention"
+
+	// Reaction events
+	EventMessageReactionAdded   EventType = "message.reaction_added"
+	EventMessageReactionRemoved EventType = "message.reaction_removed"
 
 	// Typing events
 	EventTypingStart EventType = "typing.start"
 	EventTypingStop  EventType = "typing.stop"
 
+	// Chat lifecycle events
+	EventChatCreated EventType = "chat.created"
+	EventChatUpdated EventType = "chat.updated"
+	EventChatDeleted EventType = "chat.deleted"
+
+	// Retention events
+	EventChatMessagesExpired EventType = "chat.messages_expired"
+
+	// Chat membership events
+	EventChatParticipantAdded   EventType = "chat.participant_added"
+	EventChatParticipantRemoved EventType = "chat.participant_removed"
+	EventChatJoinRequested      EventType = "chat.join_requested"
+
+	// User profile events
+	EventUserUpdated EventType = "user.updated"
+
+	// Contact events
+	EventContactRequested EventType = "contact.requested"
+	EventContactAccepted  EventType = "contact.accepted"
+
+	// Inbox events
+	EventNotificationNew EventType = "notification.new"
+
 	// Presence events
 	EventPresenceOnline  EventType = "presence.online"
 	EventPresenceOffline EventType = "presence.offline"
 
-	// Error events
+	// Connection events
+	EventSync EventType = "sync"
+
+	// Acknowledgment and error events
+	EventAck   EventType = "ack"
 	EventError EventType = "error"
 )
 
-// Event is the base WebSocket message envelope.
+// replayable reports whether events of the type are numbered and kept for
+// resuming clients. Typing and presence only matter as they happen.
+func (t EventType) replayable() bool {
+	switch t {
+	case EventTypingStart, EventTypingStop, EventPresenceOnline, EventPresenceOffline, EventAck, EventError, EventSync:
+		return false
+	default:
+		return true
+	}
+}
+
+// Event is the base WebSocket message envelope. Seq is the event's sequence
+// number, set for events kept for resuming clients.
 type Event struct {
 	Type    EventType   `json:"type"`
 	Payload interface{} `json:"payload"`
+	Seq     int64       `json:"seq,omitempty"`
 }
 
 // MessagePayload contains message data for message events.
 type MessagePayload struct {
-	ID       int        `json:"id"`
-	ChatID   int        `json:"chat_id"`
-	SenderID int        `json:"sender_id"`
-	Content  string     `json:"content,omitempty"`
-	SentAt   time.Time  `json:"sent_at,omitempty"`
-	EditedAt *time.Time `json:"edited_at,omitempty"`
+	ID          int                        `json:"id"`
+	PublicID    string                     `json:"public_id,omitempty"`
+	ChatID      int                        `json:"chat_id"`
+	SenderID    int                        `json:"sender_id"`
+	Content     string                     `json:"content,omitempty"`
+	Entities    []EntityPayload            `json:"entities,omitempty"`
+	Attachments []AttachmentPayload        `json:"attachments,omitempty"`
+	Metadata    map[string]json.RawMessage `json:"metadata,omitempty"`
+	SentAt      time.Time                  `json:"sent_at,omitempty"`
+	EditedAt    *time.Time                 `json:"edited_at,omitempty"`
+}
+
+// EntityPayload is a formatted range of message content.
+type EntityPayload struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
+}
+
+// AttachmentPayload is a file sent with a message.
+type AttachmentPayload struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
 }
 
 // MessageDeletePayload contains data for message deletion events.
@@ -54,12 +124,90 @@ type MessageReadPayload struct {
 	ReadAt    time.Time `json:"read_at"`
 }
 
+// MentionPayload contains data for mention events.
+type MentionPayload struct {
+	ChatID    int `json:"chat_id"`
+	MessageID int `json:"message_id"`
+	SenderID  int `json:"sender_id"`
+}
+
+// ReactionPayload contains data for reaction events.
+type ReactionPayload struct {
+	ChatID    int    `json:"chat_id"`
+	MessageID int    `json:"message_id"`
+	UserID    int    `json:"user_id"`
+	Emoji     string `json:"emoji"`
+}
+
 // TypingPayload contains data for typing indicator events.
 type TypingPayload struct {
 	ChatID int `json:"chat_id"`
 	UserID int `json:"user_id"`
 }
 
+// ChatPayload contains chat data for chat lifecycle events.
+type ChatPayload struct {
+	ChatID         int       `json:"chat_id"`
+	PublicID       string    `json:"public_id,omitempty"`
+	Type           string    `json:"type"`
+	Name           string    `json:"name,omitempty"`
+	Description    string    `json:"description,omitempty"`
+	ImagePath      *string   `json:"image_path,omitempty"`
+	CreatorID      int       `json:"creator_id"`
+	WorkspaceID    int       `json:"workspace_id,omitempty"` // 0 for Saved Messages
+	ParticipantIDs []int     `json:"participant_ids,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ChatDeletePayload contains data for chat deletion events.
+type ChatDeletePayload struct {
+	ChatID  int `json:"chat_id"`
+	ActorID int `json:"actor_id"`
+}
+
+// MessagesExpiredPayload contains data for retention events. All messages
+// of the chat up to and including UpToID were deleted or anonymized.
+type MessagesExpiredPayload struct {
+	ChatID int    `json:"chat_id"`
+	UpToID int    `json:"up_to_id"`
+	Mode   string `json:"mode"` // delete or anonymize
+}
+
+// ParticipantPayload contains data for chat membership events.
+type ParticipantPayload struct {
+	ChatID  int `json:"chat_id"`
+	UserID  int `json:"user_id"`
+	ActorID int `json:"actor_id"` // User who added or removed the participant
+}
+
+// JoinRequestPayload contains data for join request events.
+type JoinRequestPayload struct {
+	RequestID int       `json:"request_id"`
+	ChatID    int       `json:"chat_id"`
+	UserID    int       `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserPayload contains public profile data for user events.
+type UserPayload struct {
+	UserID      int     `json:"user_id"`
+	PublicID    string  `json:"public_id,omitempty"`
+	Username    string  `json:"username"`
+	DisplayName string  `json:"display_name,omitempty"`
+	ImagePath   *string `json:"image_path,omitempty"`
+}
+
+// NotificationPayload contains an inbox item for notification events.
+type NotificationPayload struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	ActorID   *int      `json:"actor_id,omitempty"`
+	ChatID    *int      `json:"chat_id,omitempty"`
+	MessageID *int      `json:"message_id,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // PresencePayload contains data for presence events.
 type PresencePayload struct {
 	UserID   int        `json:"user_id"`
@@ -67,19 +215,53 @@ type PresencePayload struct {
 	LastSeen *time.Time `json:"last_seen,omitempty"`
 }
 
-// ErrorPayload contains error information.
+// SyncPayload tells a client where the event stream stands when it connects.
+// Resumed is set when every event missed since the client's resume_from was
+// replayed before this event; otherwise the client must refetch over REST.
+type SyncPayload struct {
+	Seq      int64 `json:"seq"`
+	Resumed  bool  `json:"resumed"`
+	Replayed int   `json:"replayed"`
+}
+
+// ErrorPayload contains error information. RequestID is set when the error
+// answers a client message.
 type ErrorPayload struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	RequestID string            `json:"request_id,omitempty"`
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// AckPayload acknowledges a client message that was handled. MessageID and
+// PublicID are set for message.send.
+type AckPayload struct {
+	RequestID string    `json:"request_id,omitempty"`
+	Type      EventType `json:"type"`
+	MessageID int       `json:"message_id,omitempty"`
+	PublicID  string    `json:"public_id,omitempty"`
 }
 
-// ClientMessage represents a message sent from client to server.
+// ClientMessage represents a message sent from client to server. RequestID
+// is chosen by the client and echoed in the ack or error answering it.
 type ClientMessage struct {
-	Type    EventType       `json:"type"`
-	Payload ClientPayload   `json:"payload"`
+	Type      EventType     `json:"type"`
+	RequestID string        `json:"request_id,omitempty"`
+	Payload   ClientPayload `json:"payload"`
 }
 
 // ClientPayload is the payload for client-sent messages.
 type ClientPayload struct {
-	ChatID int `json:"chat_id,omitempty"`
+	ChatID      int           `json:"chat_id,omitempty"`
+	MessageID   int           `json:"message_id,omitempty"`  // message.edit and message.delete
+	Content     string        `json:"content,omitempty"`     // message.send and message.edit
+	Attachments []string      `json:"attachments,omitempty"` // message.send only
+	Reads       []ReadPayload `json:"reads,omitempty"`       // message.read only
+	Seq         int64         `json:"seq,omitempty"`         // ack only
+}
+
+// ReadPayload marks a chat as read up to a message.
+type ReadPayload struct {
+	ChatID    int `json:"chat_id"`
+	MessageID int `json:"message_id"`
 }
@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// typingIndicators tracks who is typing in which chat. Clients repeat
+// typing.start while the user types; participants get typing.start once when
+// the user starts and typing.stop when the user stops, goes quiet for the
+// TTL or the connection that reported typing closes, so an indicator never
+// gets stuck.
+type typingIndicators struct {
+	hub *Hub
+	ttl time.Duration
+
+	mu     sync.Mutex
+	active map[typingKey]*typingState
+}
+
+type typingKey struct {
+	chatID int
+	userID int
+}
+
+type typingState struct {
+	client *Client // Connection that last reported typing
+	hidden []int   // Users who must not see the indicator
+	timer  *time.Timer
+}
+
+func newTypingIndicators(hub *Hub, ttl time.Duration) *typingIndicators {
+	return &typingIndicators{
+		hub:    hub,
+		ttl:    ttl,
+		active: make(map[typingKey]*typingState),
+	}
+}
+
+// start records that the client's user is typing in a chat. Repeated starts
+// only push the expiry back. The users hidden from the indicator are looked
+// up once when it starts.
+func (t *typingIndicators) start(ctx context.Context, client *Client, chatID int) {
+	key := typingKey{chatID: chatID, userID: client.UserID()}
+
+	t.mu.Lock()
+	_, typing := t.active[key]
+	t.mu.Unlock()
+
+	var hidden []int
+	if !typing {
+		hidden = client.blockedUserIDs(ctx)
+	}
+
+	t.mu.Lock()
+	previous, typing := t.active[key]
+	if typing {
+		previous.timer.Stop()
+		hidden = previous.hidden
+	}
+	// A new state makes an expiry that already fired for the previous one a no-op
+	state := &typingState{client: client, hidden: hidden}
+	state.timer = time.AfterFunc(t.ttl, func() { t.expire(key, state) })
+	t.active[key] = state
+	t.mu.Unlock()
+
+	if !typing {
+		t.send(EventTypingStart, key, hidden)
+	}
+}
+
+// stop clears the typing indicator of a user in a chat, if any.
+func (t *typingIndicators) stop(chatID, userID int) {
+	key := typingKey{chatID: chatID, userID: userID}
+
+	t.mu.Lock()
+	state, typing := t.active[key]
+	if typing {
+		state.timer.Stop()
+		delete(t.active, key)
+	}
+	t.mu.Unlock()
+
+	if typing {
+		t.send(EventTypingStop, key, state.hidden)
+	}
+}
+
+// clientClosed clears the indicators reported by a closed connection.
+func (t *typingIndicators) clientClosed(client *Client) {
+	stopped := make(map[typingKey]*typingState)
+
+	t.mu.Lock()
+	for key, state := range t.active {
+		if state.client == client {
+			state.timer.Stop()
+			delete(t.active, key)
+			stopped[key] = state
+		}
+	}
+	t.mu.Unlock()
+
+	for key, state := range stopped {
+		t.send(EventTypingStop, key, state.hidden)
+	}
+}
+
+func (t *typingIndicators) expire(key typingKey, state *typingState) {
+	t.mu.Lock()
+	current := t.active[key] == state
+	if current {
+		delete(t.active, key)
+	}
+	t.mu.Unlock()
+
+	if current {
+		t.send(EventTypingStop, key, state.hidden)
+	}
+}
+
+func (t *typingIndicators) send(eventType EventType, key typingKey, hidden []int) {
+	event := &Event{
+		Type: eventType,
+		Payload: TypingPayload{
+			ChatID: key.chatID,
+			UserID: key.userID,
+		},
+	}
+	t.hub.BroadcastToChatExcept(key.chatID, event, key.userID, hidden) // Exclude the typist
+}
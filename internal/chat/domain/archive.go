@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MessageArchive is a compressed object in the file store holding the
+// messages one chat sent in one month. Archived messages no longer have a
+// row in the messages table; they can be listed but not edited or deleted.
+type MessageArchive struct {
+	ChatID       int
+	Month        time.Time // First day of the month, UTC
+	Path         string
+	MessageCount int
+	MinID        int
+	MaxID        int
+	ArchivedAt   time.Time
+	AnonymizedAt *time.Time // Set once the retention policy anonymized its messages
+}
+
+// ArchiveCandidate is a chat month whose messages can be archived.
+type ArchiveCandidate struct {
+	ChatID int
+	Month  time.Time
+}
+
+// MessageArchiveRepository defines the interface for archive bookkeeping.
+type MessageArchiveRepository interface {
+	// ListCandidates returns up to limit chat months that ended before
+	// before and still have messages in the table, oldest first.
+	ListCandidates(ctx context.Context, before time.Time, limit int) ([]ArchiveCandidate, error)
+
+	// ArchiveMonth locks the messages a chat sent in a month and passes them,
+	// in ID order, to archive. The archive it returns is recorded and the
+	// messages are deleted in the same transaction, so edits and deletes
+	// wait until the archive holds them. archive isn't called for a month
+	// without messages.
+	ArchiveMonth(
+		ctx context.Context,
+		chatID int,
+		month time.Time,
+		archive func(messages []Message) (*MessageArchive, error),
+	) error
+
+	// ListByChat returns the archives of a chat in ID order.
+	ListByChat(ctx context.Context, chatID int) ([]MessageArchive, error)
+
+	// ListExpired returns the archives whose month ended more than the
+	// retention period of their chat before the policy's Now, so every
+	// message in them expired. Anonymized archives are left out unless
+	// includeAnonymized is set.
+	ListExpired(ctx context.Context, policy RetentionPolicy, includeAnonymized bool) ([]MessageArchive, error)
+
+	// Delete removes an archive record.
+	Delete(ctx context.Context, chatID int, month time.Time) error
+
+	// MarkAnonymized records that the archive's messages were anonymized.
+	MarkAnonymized(ctx context.Context, chatID int, month time.Time, at time.Time) error
+}
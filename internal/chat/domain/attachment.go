@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// attachmentKey matches the random directory of an attachment path.
+var attachmentKey = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// AttachmentPathPrefix is the file store prefix of message attachments.
+const AttachmentPathPrefix = "attachments/"
+
+// MaxAttachments is how many files one message can carry.
+const MaxAttachments = 10
+
+// maxAttachmentNameLength is the longest file name kept in attachment paths.
+const maxAttachmentNameLength = 100
+
+// Attachment is a file uploaded to the file store and sent with a message.
+type Attachment struct {
+	Path        string // File store key under AttachmentPathPrefix
+	Name        string // Original file name
+	ContentType string
+	Size        int64 // bytes
+}
+
+// NewAttachmentPath returns a unique file store key for a file uploaded to a
+// chat. The chat ID is part of the key so downloads can be authorized.
+func NewAttachmentPath(chatID int, fileName string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%d/%s/%s", AttachmentPathPrefix, chatID, hex.EncodeToString(b), attachmentFileName(fileName)), nil
+}
+
+// AttachmentChatID returns the chat an attachment path was uploaded to.
+func AttachmentChatID(p string) (int, bool) {
+	rest, ok := strings.CutPrefix(p, AttachmentPathPrefix)
+	if !ok {
+		return 0, false
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || !attachmentKey.MatchString(parts[1]) || attachmentFileName(parts[2]) != parts[2] {
+		return 0, false
+	}
+
+	chatID, err := strconv.Atoi(parts[0])
+	if err != nil || chatID <= 0 {
+		return 0, false
+	}
+	return chatID, true
+}
+
+// attachmentFileName reduces a client file name to characters safe in file
+// store keys and URLs.
+func attachmentFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+		if b.Len() >= maxAttachmentNameLength {
+			break
+		}
+	}
+
+	name = strings.TrimLeft(b.String(), ".")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
@@ -10,22 +10,151 @@ type ChatType string
 const (
 	ChatTypeDirect ChatType = "direct"
 	ChatTypeGroup  ChatType = "group"
+	ChatTypeSaved  ChatType = "saved" // Saved Messages, a user's chat with themselves
+	// ChatTypeChannel is a broadcast-only chat. Its creator and workspace
+	// admins post, the other participants are read-only subscribers.
+	ChatTypeChannel ChatType = "channel"
 )
 
 type Chat struct {
-	ID        int
-	Type      ChatType
-	Name      string // Empty for direct chats
-	CreatorID int
-	CreatedAt time.Time
+	ID            int
+	PublicID      string // UUID exposed in the API instead of the sequential ID
+	Type          ChatType
+	Name          string  // Empty for direct chats
+	Description   string  // Groups only
+	ImagePath     *string // Group avatar in the file store
+	CreatorID     int
+	WorkspaceID   *int // Nil for Saved Messages, which are personal
+	CreatedAt     time.Time
+	LastMessageAt *time.Time // Denormalized, updated when a message is sent or deleted
+	LastMessageID *int       // Denormalized with LastMessageAt; may point to an archived or expired message
+	RetentionDays *int       // Overrides the global retention period; nil follows it
+	// Denormalized, updated when participants join or leave
+	ParticipantCount int
+}
+
+// Workspace returns the ID of the chat's workspace, or 0 for Saved Messages.
+func (c *Chat) Workspace() int {
+	if c.WorkspaceID == nil {
+		return 0
+	}
+	return *c.WorkspaceID
+}
+
+// MaxChatDescriptionLength is the longest group description, in characters.
+const MaxChatDescriptionLength = 500
+
+// ChatSort is the ordering of chat lists.
+type ChatSort string
+
+const (
+	// ChatSortActivity orders by the latest message, falling back to creation time.
+	ChatSortActivity ChatSort = "activity"
+	// ChatSortCreated orders by chat creation time.
+	ChatSortCreated ChatSort = "created"
+)
+
+// ChatFilter selects which of a user's chats a chat list includes.
+type ChatFilter string
+
+const (
+	// ChatFilterActive lists the chats the user hasn't archived.
+	ChatFilterActive ChatFilter = "active"
+	// ChatFilterArchived lists the chats the user archived.
+	ChatFilterArchived ChatFilter = "archived"
+)
+
+// ChatListEntry is a chat as listed for one of its participants.
+type ChatListEntry struct {
+	Chat        Chat
+	OtherUserID int // Other participant of a direct chat; zero for groups or if they left
+	UnreadCount int
+	MutedUntil  *time.Time // The user's mute deadline of the chat, possibly past
+	Archived    bool
+	Pinned      bool
+	// LastMessage holds the ID, sender, content and send time of the chat's
+	// last message. Nil if the chat has none or it is no longer in the
+	// messages table, such as after archival.
+	LastMessage *Message
+}
+
+// ChannelListEntry is a channel of a workspace as listed for one of its users.
+type ChannelListEntry struct {
+	Chat        Chat
+	Subscribed  bool
+	UnreadCount int // Zero unless subscribed
 }
 
+// TotalMode selects whether a list counts every matching row for its total.
+// Counting reads the whole result set, so clients paging through long lists
+// can skip it and rely on has_more instead.
+type TotalMode string
+
+const (
+	TotalExact TotalMode = "exact"
+	TotalNone  TotalMode = "none"
+)
+
+func (m TotalMode) IsValid() bool {
+	switch m {
+	case TotalExact, TotalNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// SystemEventParticipantLeft is the system metadata event of the message
+// posted when a user leaves a group.
+const SystemEventParticipantLeft = "participant_left"
+
+// SystemEventOwnershipTransferred is the system metadata event of the
+// message posted when a group is handed to another owner.
+const SystemEventOwnershipTransferred = "ownership_transferred"
+
 type ChatParticipant struct {
 	ChatID            int
 	UserID            int
 	JoinedAt          time.Time
 	LastReadMessageID *int
 	LastReadAt        *time.Time // Denormalized for efficiency
+	MutedUntil        *time.Time // Notifications of the chat are muted until then
+	Archived          bool       // Listed apart from the user's other chats
+	Pinned            bool       // Listed before the user's other chats
+}
+
+// MutedForever is the mute deadline of chats muted without one.
+var MutedForever = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+// IsMuted reports whether the participant muted the chat as of now.
+func (p ChatParticipant) IsMuted(now time.Time) bool {
+	return p.MutedUntil != nil && now.Before(*p.MutedUntil)
+}
+
+// ReadMark is the last message a participant read in a chat.
+type ReadMark struct {
+	ChatID    int
+	MessageID int
+}
+
+// ChatInvite is a pending invite of an unregistered email address to a
+// chat. It is accepted when an account with that email is created.
+type ChatInvite struct {
+	ID        int
+	ChatID    int
+	Email     string // Stored lowercase
+	InviterID int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ChatJoinRequest is a pending request of a workspace member to join a
+// group. It is removed once approved or rejected.
+type ChatJoinRequest struct {
+	ID        int
+	ChatID    int
+	UserID    int
+	CreatedAt time.Time
 }
 
 // ChatRepository defines the interface for chat data access.
@@ -36,16 +165,46 @@ type ChatRepository interface {
 	// GetByID retrieves a chat by its ID.
 	GetByID(ctx context.Context, id int) (*Chat, error)
 
-	// GetDMByParticipants finds a direct message chat between two users.
-	GetDMByParticipants(ctx context.Context, userID1, userID2 int) (*Chat, error)
+	// GetIDByPublicID returns the internal ID of the chat with the given public ID.
+	GetIDByPublicID(ctx context.Context, publicID string) (int, error)
+
+	// GetDMByParticipants finds a direct message chat between two users in a workspace.
+	GetDMByParticipants(ctx context.Context, workspaceID, userID1, userID2 int) (*Chat, error)
 
-	// GetDMsListByUser returns paginated list of direct message chats for a user.
-	// Returns chats slice, total count, and error.
-	GetDMsListByUser(ctx context.Context, userID int, offset, limit int) ([]Chat, int, error)
+	// CreateSaved creates the Saved Messages chat of chat.CreatorID together
+	// with its only participant, the creator, and sets its ID. It returns
+	// errs.ErrAlreadyExists if the user already has one.
+	CreateSaved(ctx context.Context, chat *Chat) error
 
-	// GetGroupsListByUser returns paginated list of group chats for a user.
-	// Returns chats slice, total count, and error.
-	GetGroupsListByUser(ctx context.Context, userID int, offset, limit int) ([]Chat, int, error)
+	// GetSavedByUser finds the Saved Messages chat of a user.
+	GetSavedByUser(ctx context.Context, userID int) (*Chat, error)
+
+	// GetDMsListEnriched returns a page of the direct message chats of a user
+	// in a workspace matching filter with their other participant, last
+	// message and the user's unread count. Chats the user pinned come first.
+	// Returns entries, total count, and error. The total is only counted
+	// with withTotal.
+	GetDMsListEnriched(
+		ctx context.Context,
+		workspaceID, userID int,
+		filter ChatFilter,
+		sort ChatSort,
+		offset, limit int,
+		withTotal bool,
+	) ([]ChatListEntry, int, error)
+
+	// GetGroupsListEnriched returns a page of the group chats of a user in a
+	// workspace matching filter with their last message and the user's
+	// unread count. Chats the user pinned come first. Returns entries, total
+	// count, and error. The total is only counted with withTotal.
+	GetGroupsListEnriched(
+		ctx context.Context,
+		workspaceID, userID int,
+		filter ChatFilter,
+		sort ChatSort,
+		offset, limit int,
+		withTotal bool,
+	) ([]ChatListEntry, int, error)
 
 	// AddParticipant adds a user to a chat.
 	AddParticipant(ctx context.Context, participant *ChatParticipant) error
@@ -56,12 +215,123 @@ type ChatRepository interface {
 	// GetParticipants retrieves all participants of a chat.
 	GetParticipants(ctx context.Context, chatID int) ([]ChatParticipant, error)
 
-	// IsParticipant checks if a user is a participant of a chat.
-	IsParticipant(ctx context.Context, chatID, userID int) (bool, error)
+	// IsParticipant checks if a user is a participant of a chat that is in
+	// the workspace or belongs to none.
+	IsParticipant(ctx context.Context, workspaceID, chatID, userID int) (bool, error)
 
 	// UpdateLastRead updates the last read message for a participant.
 	UpdateLastRead(ctx context.Context, chatID, userID, messageID int) error
 
-	// GetUserChatIDs returns all chat IDs that a user is a participant of.
-	GetUserChatIDs(ctx context.Context, userID int) ([]int, error)
+	// UpdateLastReadBatch updates the last read message of a participant in
+	// several chats at once. Chats the user doesn't participate in are skipped.
+	UpdateLastReadBatch(ctx context.Context, userID int, marks []ReadMark) error
+
+	// GetUserChatIDs returns the IDs of the chats a user is a participant of
+	// that are in the workspace or belong to none.
+	GetUserChatIDs(ctx context.Context, workspaceID, userID int) ([]int, error)
+
+	// GetUserChannelIDs returns the IDs of the channels a user subscribes to
+	// in a workspace.
+	GetUserChannelIDs(ctx context.Context, workspaceID, userID int) ([]int, error)
+
+	// GetChannelsList returns a page of the channels of a workspace, most
+	// recently active first, with whether the user subscribes to them. With
+	// subscribedOnly only the user's subscriptions are listed. Returns
+	// entries, total count, and error.
+	GetChannelsList(
+		ctx context.Context,
+		workspaceID, userID int,
+		subscribedOnly bool,
+		offset, limit int,
+	) ([]ChannelListEntry, int, error)
+
+	// GetContactIDs returns the IDs of all other users sharing at least one chat with a user.
+	// Fellow channel subscribers are not contacts.
+	GetContactIDs(ctx context.Context, userID int) ([]int, error)
+
+	// UpsertInvite stores a pending invite and sets its ID. An existing invite
+	// for the same chat and email is refreshed instead.
+	UpsertInvite(ctx context.Context, invite *ChatInvite) error
+
+	// SetArchived archives or unarchives a chat for a participant. Returns
+	// errs.ErrNotFound if the user doesn't participate in the chat.
+	SetArchived(ctx context.Context, chatID, userID int, archived bool) error
+
+	// SetPinned pins or unpins a chat for a participant. Returns
+	// errs.ErrNotFound if the user doesn't participate in the chat.
+	SetPinned(ctx context.Context, chatID, userID int, pinned bool) error
+
+	// SetMutedUntil mutes a chat for a participant until the given time, or
+	// unmutes it if until is nil. Returns errs.ErrNotFound if the user
+	// doesn't participate in the chat.
+	SetMutedUntil(ctx context.Context, chatID, userID int, until *time.Time) error
+
+	// SetRetention sets the retention period of a chat in days; nil follows
+	// the global period.
+	SetRetention(ctx context.Context, chatID int, days *int) error
+
+	// SetCreator hands ownership of a chat to another user.
+	SetCreator(ctx context.Context, chatID, creatorID int) error
+
+	// SetDescription sets the description of a chat; empty clears it.
+	SetDescription(ctx context.Context, chatID int, description string) error
+
+	// SetImage sets the avatar of a chat to a file store path; nil clears it.
+	SetImage(ctx context.Context, chatID int, imagePath *string) error
+
+	// Delete deletes a chat with its participants, messages and everything
+	// else attached to it. Returns errs.ErrNotFound if the chat doesn't exist.
+	Delete(ctx context.Context, chatID int) error
+
+	// AcceptInvites adds the user to every chat with an unexpired invite for
+	// the email and removes all invites for it. Returns the accepted invites.
+	AcceptInvites(ctx context.Context, email string, userID int, joinedAt time.Time) ([]ChatInvite, error)
+
+	// CreateWebhook stores an incoming webhook and sets its ID and public ID.
+	CreateWebhook(ctx context.Context, webhook *ChatWebhook) error
+
+	// GetWebhookByPublicID retrieves an incoming webhook by its public ID.
+	GetWebhookByPublicID(ctx context.Context, publicID string) (*ChatWebhook, error)
+
+	// GetWebhooks returns the incoming webhooks of a chat, oldest first.
+	GetWebhooks(ctx context.Context, chatID int) ([]ChatWebhook, error)
+
+	// DeleteWebhook deletes an incoming webhook of a chat.
+	// Returns errs.ErrNotFound if the chat has no such webhook.
+	DeleteWebhook(ctx context.Context, chatID, webhookID int) error
+
+	// TouchWebhook records that an incoming webhook posted a message.
+	TouchWebhook(ctx context.Context, webhookID int, usedAt time.Time) error
+
+	// CreateInviteLink stores an invite link and sets its ID.
+	// Returns errs.ErrAlreadyExists if the code is taken.
+	CreateInviteLink(ctx context.Context, link *ChatInviteLink) error
+
+	// GetInviteLinkByCode retrieves an invite link by its code, expired or not.
+	GetInviteLinkByCode(ctx context.Context, code string) (*ChatInviteLink, error)
+
+	// GetInviteLinks returns the invite links of a chat that are unexpired at
+	// now, oldest first.
+	GetInviteLinks(ctx context.Context, chatID int, now time.Time) ([]ChatInviteLink, error)
+
+	// DeleteInviteLink deletes an invite link of a chat.
+	// Returns errs.ErrNotFound if the chat has no such invite link.
+	DeleteInviteLink(ctx context.Context, chatID, linkID int) error
+
+	// CreateJoinRequest stores a join request and sets its ID.
+	// Returns errs.ErrAlreadyExists if the user already has a pending request
+	// for the chat.
+	CreateJoinRequest(ctx context.Context, request *ChatJoinRequest) error
+
+	// GetJoinRequests returns the pending join requests of a chat, oldest first.
+	GetJoinRequests(ctx context.Context, chatID int) ([]ChatJoinRequest, error)
+
+	// ApproveJoinRequest removes a join request of a chat and adds its user to
+	// the chat in one step. The bool is false if the user already participates.
+	// Returns errs.ErrNotFound if the chat has no such request.
+	ApproveJoinRequest(ctx context.Context, chatID, requestID int, joinedAt time.Time) (*ChatJoinRequest, bool, error)
+
+	// DeleteJoinRequest removes a join request of a chat and returns it.
+	// Returns errs.ErrNotFound if the chat has no such request.
+	DeleteJoinRequest(ctx context.Context, chatID, requestID int) (*ChatJoinRequest, error)
 }
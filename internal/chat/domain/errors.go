@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"chatx-01-backend/pkg/errs"
+)
 
 // Domain-specific errors for chat module.
 var (
@@ -10,3 +14,47 @@ var (
 	ErrNotMessageOwner   = errors.New("user is not the owner of this message")
 	ErrMessageNotInChat  = errors.New("message does not belong to this chat")
 )
+
+// ErrTranslationDisabled is returned when no translation provider is configured.
+var ErrTranslationDisabled = errs.NewForbiddenError("translation_disabled", "message translation is not enabled")
+
+// ErrAssistantDisabled is returned when no assistant provider is configured
+// or the assistant is switched off.
+var ErrAssistantDisabled = errs.NewForbiddenError("assistant_disabled", "chat assistant is not enabled")
+
+// ErrWebhookInactive is returned when the creator of an incoming webhook has
+// left its chat, so the webhook can no longer post.
+var ErrWebhookInactive = errs.NewForbiddenError("webhook_inactive", "webhook creator is no longer in the chat")
+
+// ErrChannelReadOnly is returned when a subscriber who neither created a
+// channel nor administers its workspace tries to post to it.
+var ErrChannelReadOnly = errs.NewForbiddenError(
+	"channel_read_only",
+	"only the channel creator or a workspace admin can post",
+)
+
+// ErrUserBlocked is returned when a user tries to start a direct chat or
+// message in one with a user who blocked them or whom they blocked.
+var ErrUserBlocked = errs.NewForbiddenError("user_blocked", "you can't message this user")
+
+// ErrNotChatManager is returned when a user who neither created a group nor
+// administers its workspace tries to remove its participants.
+var ErrNotChatManager = errs.NewForbiddenError(
+	"not_chat_manager",
+	"only the group creator or a workspace admin can remove participants",
+)
+
+// ErrNotGroupOwner is returned when a user who neither created a group nor
+// administers its workspace tries to transfer or delete it.
+var ErrNotGroupOwner = errs.NewForbiddenError(
+	"not_chat_manager",
+	"only the group creator or a workspace admin can manage the group",
+)
+
+// ErrNotInviteManager is returned when a user who neither created a group nor
+// administers its workspace tries to list its invite links or handle its join
+// requests.
+var ErrNotInviteManager = errs.NewForbiddenError(
+	"not_chat_manager",
+	"only the group creator or a workspace admin can manage invites",
+)
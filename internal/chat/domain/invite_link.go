@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+)
+
+// ChatInviteLink is an expiring code that lets members of a group's
+// workspace join the group on their own.
+type ChatInviteLink struct {
+	ID        int
+	ChatID    int
+	CreatorID int
+	Code      string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// NewInviteCode generates a random invite code.
+func NewInviteCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Expired reports whether the invite link can no longer be used at now.
+func (l *ChatInviteLink) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Mention records that a message mentioned a participant of its chat.
+type Mention struct {
+	MessageID int
+	ChatID    int
+	UserID    int
+	CreatedAt time.Time
+}
+
+// MentionRepository defines the interface for message mention data access.
+type MentionRepository interface {
+	// Create stores the mentions of a message, skipping ones already stored.
+	Create(ctx context.Context, mentions []Mention) error
+
+	// DeleteByMessage deletes the mentions of a message.
+	DeleteByMessage(ctx context.Context, messageID int) error
+
+	// ListUserIDs returns the users a message mentions.
+	ListUserIDs(ctx context.Context, messageID int) ([]int, error)
+
+	// Delete deletes the mentions of the users from a message.
+	Delete(ctx context.Context, messageID int, userIDs []int) error
+
+	// CountUnreadByChat returns the number of messages in a chat mentioning
+	// the user that are newer than the last message they read there.
+	CountUnreadByChat(ctx context.Context, chatID, userID int) (int, error)
+
+	// CountUnread returns the number of unread messages mentioning the user
+	// across their chats in a workspace, leaving out chats they muted.
+	CountUnread(ctx context.Context, workspaceID, userID int) (int, error)
+}
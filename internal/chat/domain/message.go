@@ -2,16 +2,50 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
 type Message struct {
-	ID       int
-	ChatID   int
-	SenderID int
-	Content  string
-	SentAt   time.Time
-	EditedAt *time.Time
+	ID          int
+	PublicID    string // UUID exposed in the API instead of the sequential ID
+	ChatID      int
+	SenderID    int
+	Content     string
+	Entities    []MessageEntity // Formatting parsed from Content
+	Attachments []Attachment
+	Metadata    Metadata
+	SentAt      time.Time
+	EditedAt    *time.Time
+}
+
+// MessageEntity is a formatted range of a message's content. Offset and
+// Length count Unicode code points.
+type MessageEntity struct {
+	Type   string
+	Offset int
+	Length int
+	URL    string // Set for links
+}
+
+// SortOrder is the direction messages are listed in.
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// MessageListParams selects a page of messages in a chat. BeforeID and AfterID
+// are exclusive message ID bounds and are ignored when zero.
+type MessageListParams struct {
+	ChatID    int
+	BeforeID  int
+	AfterID   int
+	Order     SortOrder
+	Offset    int
+	Limit     int
+	SkipTotal bool // Leave the total zero instead of counting the chat's messages
 }
 
 // MessageRepository defines the interface for message data access.
@@ -22,15 +56,30 @@ type MessageRepository interface {
 	// GetByID retrieves a message by its ID.
 	GetByID(ctx context.Context, id int) (*Message, error)
 
-	// Update updates an existing message's content and edited timestamp.
+	// GetByIDs retrieves the messages with the given IDs that exist, in no
+	// particular order.
+	GetByIDs(ctx context.Context, ids []int) ([]Message, error)
+
+	// GetIDByPublicID returns the internal ID of the message with the given public ID.
+	GetIDByPublicID(ctx context.Context, publicID string) (int, error)
+
+	// Update updates an existing message's content, entities and edited timestamp.
 	Update(ctx context.Context, message *Message) error
 
+	// SetMetadataKey sets one metadata key of a message, or removes it if
+	// value is nil, leaving the other keys as they are.
+	SetMetadataKey(ctx context.Context, id int, key string, value json.RawMessage) error
+
 	// Delete removes a message by its ID.
 	Delete(ctx context.Context, id int) error
 
 	// ListWithCount returns paginated list of messages in a chat.
-	// Returns messages slice, total count, and error.
-	ListWithCount(ctx context.Context, chatID int, offset, limit int) ([]Message, int, error)
+	// Returns messages slice, total count of messages in the chat, and error.
+	ListWithCount(ctx context.Context, params MessageListParams) ([]Message, int, error)
+
+	// Search returns a page of the messages matching a full-text query and
+	// the total number of matches. Archived messages are not searched.
+	Search(ctx context.Context, params MessageSearchParams) ([]MessageSearchResult, int, error)
 
 	// GetLastMessage returns the most recent message in a chat, or nil if no messages exist.
 	GetLastMessage(ctx context.Context, chatID int) (*Message, error)
@@ -38,6 +87,26 @@ type MessageRepository interface {
 	// GetUnreadCountByChat returns the count of unread messages in a specific chat for a user.
 	GetUnreadCountByChat(ctx context.Context, chatID, userID int) (int, error)
 
-	// GetTotalUnreadCount returns the total count of unread messages across all chats for a user.
-	GetTotalUnreadCount(ctx context.Context, userID int) (int, error)
+	// GetFirstUnreadMessageID returns the ID of the oldest message in a chat that
+	// the user has not read yet, or nil when everything has been read.
+	GetFirstUnreadMessageID(ctx context.Context, chatID, userID int) (*int, error)
+
+	// GetTotalUnreadCount returns the total count of unread messages across a user's chats in a workspace,
+	// leaving out chats the user muted.
+	GetTotalUnreadCount(ctx context.Context, workspaceID, userID int) (int, error)
+
+	// GetUnreadChatsCount returns the number of a user's unmuted chats in a workspace with at least one unread message.
+	GetUnreadChatsCount(ctx context.Context, workspaceID, userID int) (int, error)
+
+	// CountExpired returns the number of messages the policy would delete,
+	// or anonymize unless they already are.
+	CountExpired(ctx context.Context, policy RetentionPolicy, mode RetentionMode) (int, error)
+
+	// DeleteExpired deletes up to limit expired messages and returns what it
+	// deleted per chat.
+	DeleteExpired(ctx context.Context, policy RetentionPolicy, limit int) ([]ExpiredMessages, error)
+
+	// AnonymizeExpired anonymizes up to limit expired messages that aren't
+	// yet and returns what it anonymized per chat.
+	AnonymizeExpired(ctx context.Context, policy RetentionPolicy, limit int) ([]ExpiredMessages, error)
 }
@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Known message metadata keys. Each key holds a JSON object whose shape is
+// checked by ValidateMetadata.
+const (
+	// MetadataForward references the original of a forwarded message.
+	MetadataForward = "forward"
+	// MetadataLinkPreview describes a preview of a link in the content.
+	MetadataLinkPreview = "link_preview"
+	// MetadataBot carries a free-form object for bot integrations.
+	MetadataBot = "bot"
+	// MetadataSystem marks a message generated by the server. Clients
+	// cannot set it.
+	MetadataSystem = "system"
+	// MetadataWebhook marks a message posted through an incoming webhook.
+	// Clients cannot set it.
+	MetadataWebhook = "webhook"
+	// MetadataLinkWarning lists unsafe links found in the content by link
+	// scanning. Clients cannot set it.
+	MetadataLinkWarning = "link_warning"
+)
+
+// MaxMetadataSize is the maximum encoded size of message metadata in bytes.
+const MaxMetadataSize = 4096
+
+var (
+	ErrMetadataTooLarge = fmt.Errorf("metadata must be %d bytes or less", MaxMetadataSize)
+	ErrMetadataReserved = errors.New("metadata key is reserved for the server")
+)
+
+// Metadata is structured data attached to a message, keyed by the known
+// metadata keys.
+type Metadata map[string]json.RawMessage
+
+type ForwardMetadata struct {
+	ChatID    int `json:"chat_id"`
+	MessageID int `json:"message_id"`
+	SenderID  int `json:"sender_id"`
+}
+
+type LinkPreviewMetadata struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+}
+
+type SystemMetadata struct {
+	Event     string `json:"event"`
+	UserID    int    `json:"user_id,omitempty"`    // User the event is about
+	CreatorID int    `json:"creator_id,omitempty"` // New owner when ownership changed
+}
+
+type WebhookMetadata struct {
+	WebhookID string `json:"webhook_id"` // Public ID
+	Name      string `json:"name"`
+}
+
+type LinkWarningMetadata struct {
+	Links []UnsafeLink `json:"links"`
+}
+
+type UnsafeLink struct {
+	URL    string `json:"url"`
+	Threat string `json:"threat"`
+}
+
+var metadataValidators = map[string]func(json.RawMessage) error{
+	MetadataForward:     validateForwardMetadata,
+	MetadataLinkPreview: validateLinkPreviewMetadata,
+	MetadataBot:         validateBotMetadata,
+	MetadataSystem:      validateSystemMetadata,
+	MetadataWebhook:     validateWebhookMetadata,
+	MetadataLinkWarning: validateLinkWarningMetadata,
+}
+
+// ValidateClientMetadata validates metadata sent by a client, which may not
+// use server-only keys.
+func ValidateClientMetadata(md Metadata) error {
+	for _, key := range []string{MetadataSystem, MetadataWebhook, MetadataLinkWarning} {
+		if _, ok := md[key]; ok {
+			return fmt.Errorf("%s: %w", key, ErrMetadataReserved)
+		}
+	}
+	return ValidateMetadata(md)
+}
+
+// ValidateMetadata checks that metadata uses known keys with well-formed
+// values and fits the size limit.
+func ValidateMetadata(md Metadata) error {
+	if len(md) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(md)
+	if err != nil {
+		return err
+	}
+	if len(encoded) > MaxMetadataSize {
+		return ErrMetadataTooLarge
+	}
+
+	for key, value := range md {
+		validate, ok := metadataValidators[key]
+		if !ok {
+			return fmt.Errorf("unknown metadata key %q", key)
+		}
+		if err := validate(value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func validateForwardMetadata(raw json.RawMessage) error {
+	var fwd ForwardMetadata
+	if err := decodeStrict(raw, &fwd); err != nil {
+		return err
+	}
+	if fwd.ChatID <= 0 || fwd.MessageID <= 0 || fwd.SenderID <= 0 {
+		return errors.New("chat_id, message_id and sender_id are required")
+	}
+	return nil
+}
+
+func validateLinkPreviewMetadata(raw json.RawMessage) error {
+	var preview LinkPreviewMetadata
+	if err := decodeStrict(raw, &preview); err != nil {
+		return err
+	}
+	if !isWebURL(preview.URL) {
+		return errors.New("url must be an http or https URL")
+	}
+	if preview.ImageURL != "" && !isWebURL(preview.ImageURL) {
+		return errors.New("image_url must be an http or https URL")
+	}
+	return nil
+}
+
+func validateBotMetadata(raw json.RawMessage) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		return errors.New("must be a JSON object")
+	}
+	return nil
+}
+
+func validateSystemMetadata(raw json.RawMessage) error {
+	var sys SystemMetadata
+	if err := decodeStrict(raw, &sys); err != nil {
+		return err
+	}
+	if sys.Event == "" {
+		return errors.New("event is required")
+	}
+	return nil
+}
+
+func validateWebhookMetadata(raw json.RawMessage) error {
+	var hook WebhookMetadata
+	if err := decodeStrict(raw, &hook); err != nil {
+		return err
+	}
+	if hook.WebhookID == "" || hook.Name == "" {
+		return errors.New("webhook_id and name are required")
+	}
+	return nil
+}
+
+func validateLinkWarningMetadata(raw json.RawMessage) error {
+	var warning LinkWarningMetadata
+	if err := decodeStrict(raw, &warning); err != nil {
+		return err
+	}
+	if len(warning.Links) == 0 {
+		return errors.New("links are required")
+	}
+	return nil
+}
+
+// decodeStrict decodes a JSON object, rejecting unknown fields.
+func decodeStrict(raw json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errors.New("invalid value")
+	}
+	return nil
+}
+
+func isWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
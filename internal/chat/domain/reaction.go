@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxReactionsPerUser is how many different emoji one user may react to a
+// message with.
+const MaxReactionsPerUser = 20
+
+// maxEmojiLength bounds a reaction emoji in bytes. Emoji built from several
+// code points, like flags and ZWJ sequences, fit.
+const maxEmojiLength = 32
+
+// Reaction is an emoji a user reacted to a message with.
+type Reaction struct {
+	MessageID int
+	ChatID    int
+	UserID    int
+	Emoji     string
+	CreatedAt time.Time
+}
+
+// ValidReactionEmoji reports whether s can be used as a reaction. It accepts
+// symbols and their modifiers and rejects text, so clients can't react with
+// arbitrary words.
+func ValidReactionEmoji(s string) bool {
+	if s == "" || len(s) > maxEmojiLength || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReactionRepository defines the interface for message reaction data access.
+type ReactionRepository interface {
+	// Add stores a reaction.
+	// Returns errs.ErrAlreadyExists if the user already reacted with the emoji.
+	Add(ctx context.Context, reaction *Reaction) error
+
+	// Remove deletes a user's reaction to a message.
+	// Returns errs.ErrNotFound if the user didn't react with the emoji.
+	Remove(ctx context.Context, messageID, userID int, emoji string) error
+
+	// ListByMessage returns the reactions to a message, oldest first.
+	ListByMessage(ctx context.Context, messageID int) ([]Reaction, error)
+
+	// DeleteByMessage deletes all reactions to a message.
+	DeleteByMessage(ctx context.Context, messageID int) error
+}
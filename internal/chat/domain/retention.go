@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// RetentionMode is what happens to messages older than the retention period
+// of their chat.
+type RetentionMode string
+
+const (
+	// RetentionModeDelete removes expired messages.
+	RetentionModeDelete RetentionMode = "delete"
+	// RetentionModeAnonymize keeps expired messages in place with their
+	// content, entities and metadata removed, so threads keep their shape.
+	RetentionModeAnonymize RetentionMode = "anonymize"
+)
+
+func (m RetentionMode) IsValid() bool {
+	return m == RetentionModeDelete || m == RetentionModeAnonymize
+}
+
+// SystemEventRetentionExpired is the system metadata event of messages
+// anonymized by the retention policy.
+const SystemEventRetentionExpired = "retention_expired"
+
+// MaxRetentionDays bounds retention periods to keep cutoffs representable.
+const MaxRetentionDays = 36500
+
+// RetentionPolicy selects expired messages. A message expires when it was
+// sent more than its chat's retention period before Now; chats without
+// their own period use DefaultDays. A period of zero days keeps messages
+// forever.
+type RetentionPolicy struct {
+	DefaultDays int
+	Now         time.Time
+}
+
+// ExpiredMessages summarizes the messages of one chat that a retention
+// batch deleted or anonymized.
+type ExpiredMessages struct {
+	ChatID int
+	MaxID  int // Highest handled message ID
+	Count  int
+}
+
+// CountExpiredMessages returns the number of messages in batches.
+func CountExpiredMessages(batches []ExpiredMessages) int {
+	count := 0
+	for _, b := range batches {
+		count += b.Count
+	}
+	return count
+}
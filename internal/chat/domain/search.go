@@ -0,0 +1,29 @@
+package domain
+
+// MaxSearchQueryLength is the longest full-text query accepted, in characters.
+const MaxSearchQueryLength = 200
+
+// MessageSearchParams selects a page of the messages matching a full-text
+// query in the chats a user takes part in, newest first.
+type MessageSearchParams struct {
+	WorkspaceID int
+	UserID      int
+	ChatID      int    // Restricts the search to one chat when non-zero
+	Query       string // Words to match, in web search syntax
+	Offset      int
+	Limit       int
+}
+
+// MessageSearchResult is a message matching a search, with an excerpt of
+// its content around the matched words.
+type MessageSearchResult struct {
+	Message    Message
+	Snippet    string
+	Highlights []TextRange // Matched words in Snippet
+}
+
+// TextRange is a range of text. Offset and Length count Unicode code points.
+type TextRange struct {
+	Offset int
+	Length int
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// ChatWebhook is an incoming webhook that lets an external system post
+// messages into a group chat on behalf of its creator. Only a hash of its
+// secret is stored.
+type ChatWebhook struct {
+	ID         int
+	PublicID   string // UUID in the webhook URL
+	ChatID     int
+	CreatorID  int
+	Name       string // Shown as the sender of its messages
+	SecretHash string // Hex SHA-256 of the secret
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// NewWebhookSecret generates a random webhook secret and its hash.
+func NewWebhookSecret() (secret, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret = base64.RawURLEncoding.EncodeToString(b)
+	return secret, hashWebhookSecret(secret), nil
+}
+
+// CheckSecret reports whether secret is the webhook's secret, in constant time.
+func (w *ChatWebhook) CheckSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashWebhookSecret(secret)), []byte(w.SecretHash)) == 1
+}
+
+func hashWebhookSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+package infra
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/filestore"
+)
+
+const archiveContentType = "application/gzip"
+
+// archivedMessage is one line of an archive object. The keys are part of
+// the stored format and must stay stable.
+type archivedMessage struct {
+	ID          int                `json:"id"`
+	PublicID    string             `json:"public_id"`
+	ChatID      int                `json:"chat_id"`
+	SenderID    int                `json:"sender_id"`
+	Content     string             `json:"content"`
+	Entities    messageEntities    `json:"entities"`
+	Attachments messageAttachments `json:"attachments"`
+	Metadata    domain.Metadata    `json:"metadata"`
+	SentAt      time.Time          `json:"sent_at"`
+	EditedAt    *time.Time         `json:"edited_at"`
+}
+
+// archiveStore reads and writes archive objects, gzip'd newline-delimited
+// JSON in ID order.
+type archiveStore struct {
+	files filestore.Store
+}
+
+// archivePath names an archive object. The archive time keeps a rewritten
+// archive from replacing the object its record still points to.
+func archivePath(chatID int, month, archivedAt time.Time) string {
+	return fmt.Sprintf("archives/messages/%d/%s-%d.ndjson.gz", chatID, month.Format("2006-01"), archivedAt.Unix())
+}
+
+func (s archiveStore) write(ctx context.Context, path string, messages []domain.Message) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+
+	for _, m := range messages {
+		err := enc.Encode(archivedMessage{
+			ID:          m.ID,
+			PublicID:    m.PublicID,
+			ChatID:      m.ChatID,
+			SenderID:    m.SenderID,
+			Content:     m.Content,
+			Entities:    messageEntities(m.Entities),
+			Attachments: messageAttachments(m.Attachments),
+			Metadata:    metadataValue(m.Metadata),
+			SentAt:      m.SentAt,
+			EditedAt:    m.EditedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode archived message: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	return s.files.Upload(ctx, path, &buf, int64(buf.Len()), archiveContentType)
+}
+
+func (s archiveStore) read(ctx context.Context, path string) ([]domain.Message, error) {
+	obj, err := s.files.Download(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	gz, err := gzip.NewReader(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	messages := make([]domain.Message, 0)
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	for {
+		var am archivedMessage
+		err := dec.Decode(&am)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode archive %s: %w", path, err)
+		}
+
+		messages = append(messages, domain.Message{
+			ID:          am.ID,
+			PublicID:    am.PublicID,
+			ChatID:      am.ChatID,
+			SenderID:    am.SenderID,
+			Content:     am.Content,
+			Entities:    am.Entities,
+			Attachments: am.Attachments,
+			Metadata:    am.Metadata,
+			SentAt:      am.SentAt,
+			EditedAt:    am.EditedAt,
+		})
+	}
+
+	return messages, nil
+}
+
+// anonymizeMessage removes what the retention policy anonymizes, like
+// PgMessageRepo.AnonymizeExpired does for rows.
+func anonymizeMessage(m *domain.Message) error {
+	system, err := json.Marshal(domain.SystemMetadata{Event: domain.SystemEventRetentionExpired})
+	if err != nil {
+		return err
+	}
+
+	m.Content = ""
+	m.Entities = nil
+	m.Attachments = nil
+	m.Metadata = domain.Metadata{domain.MetadataSystem: system}
+	return nil
+}
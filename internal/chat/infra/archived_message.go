@@ -0,0 +1,306 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/filestore"
+)
+
+// ArchivedMessageRepo is a message repository that also reads messages
+// moved to the file store by MessageArchiver, so archival is invisible to
+// message listings. Writes go to the messages table only.
+type ArchivedMessageRepo struct {
+	*PgMessageRepo
+	archives *PgArchiveRepo
+	store    archiveStore
+}
+
+func NewArchivedMessageRepo(
+	messages *PgMessageRepo,
+	archives *PgArchiveRepo,
+	files filestore.Store,
+) *ArchivedMessageRepo {
+	return &ArchivedMessageRepo{
+		PgMessageRepo: messages,
+		archives:      archives,
+		store:         archiveStore{files: files},
+	}
+}
+
+// ListWithCount lists the messages table and the chat's archives as one
+// sequence. Archived messages are always older than the ones in the table,
+// so a page only reads archives once it runs past the table.
+func (r *ArchivedMessageRepo) ListWithCount(
+	ctx context.Context,
+	params domain.MessageListParams,
+) ([]domain.Message, int, error) {
+	const op = "archivedmessage.List"
+
+	archives, err := r.archives.ListByChat(ctx, params.ChatID)
+	if err != nil {
+		return nil, 0, errs.Wrap(op, err)
+	}
+	if len(archives) == 0 {
+		return r.PgMessageRepo.ListWithCount(ctx, params)
+	}
+
+	archivedCount := 0
+	if !params.SkipTotal {
+		for _, a := range archives {
+			archivedCount += a.MessageCount
+		}
+	}
+
+	if params.Order == domain.SortAsc {
+		archived, skip, err := r.page(ctx, archives, params, params.Offset, params.Limit)
+		if err != nil {
+			return nil, 0, errs.Wrap(op, err)
+		}
+
+		if len(archived) == params.Limit && params.SkipTotal {
+			return archived, 0, nil
+		}
+
+		hotParams := params
+		hotParams.Offset = skip
+		hotParams.Limit = params.Limit - len(archived)
+		if hotParams.Limit == 0 {
+			// Still needed for the total
+			hotParams.Limit = 1
+		}
+		hot, hotCount, err := r.PgMessageRepo.ListWithCount(ctx, hotParams)
+		if err != nil {
+			return nil, 0, errs.Wrap(op, err)
+		}
+		if len(archived) == params.Limit {
+			return archived, hotCount + archivedCount, nil
+		}
+
+		return append(archived, hot...), hotCount + archivedCount, nil
+	}
+
+	hot, hotCount, err := r.PgMessageRepo.ListWithCount(ctx, params)
+	if err != nil {
+		return nil, 0, errs.Wrap(op, err)
+	}
+	if len(hot) == params.Limit {
+		return hot, hotCount + archivedCount, nil
+	}
+
+	// The offset only reaches into the archives by what the table did not cover
+	skip := 0
+	if len(hot) == 0 && params.Offset > 0 {
+		matching, err := r.countInBounds(ctx, params)
+		if err != nil {
+			return nil, 0, errs.Wrap(op, err)
+		}
+		skip = max(params.Offset-matching, 0)
+	}
+
+	slices.Reverse(archives)
+	archived, _, err := r.page(ctx, archives, params, skip, params.Limit-len(hot))
+	if err != nil {
+		return nil, 0, errs.Wrap(op, err)
+	}
+
+	return append(hot, archived...), hotCount + archivedCount, nil
+}
+
+// page collects up to limit archived messages within the bounds of params
+// after skipping skip of them, reading archives in the given order. Archives
+// entirely within the bounds are skipped by their count without being read.
+// It returns the part of skip not used up by the archives.
+func (r *ArchivedMessageRepo) page(
+	ctx context.Context,
+	archives []domain.MessageArchive,
+	params domain.MessageListParams,
+	skip, limit int,
+) ([]domain.Message, int, error) {
+	messages := make([]domain.Message, 0)
+
+	for _, a := range archives {
+		if len(messages) == limit {
+			break
+		}
+		if !overlaps(a, params) {
+			continue
+		}
+		if skip >= a.MessageCount && within(a, params) {
+			skip -= a.MessageCount
+			continue
+		}
+
+		archived, err := r.store.read(ctx, a.Path)
+		if err != nil {
+			return nil, 0, err
+		}
+		archived = slices.DeleteFunc(archived, func(m domain.Message) bool {
+			return (params.BeforeID > 0 && m.ID >= params.BeforeID) ||
+				(params.AfterID > 0 && m.ID <= params.AfterID)
+		})
+		if params.Order != domain.SortAsc {
+			slices.Reverse(archived)
+		}
+
+		if skip >= len(archived) {
+			skip -= len(archived)
+			continue
+		}
+		archived = archived[skip:]
+		skip = 0
+
+		n := min(len(archived), limit-len(messages))
+		messages = append(messages, archived[:n]...)
+	}
+
+	return messages, skip, nil
+}
+
+// overlaps reports whether any message of the archive can be in bounds.
+func overlaps(a domain.MessageArchive, params domain.MessageListParams) bool {
+	return (params.BeforeID == 0 || a.MinID < params.BeforeID) &&
+		(params.AfterID == 0 || a.MaxID > params.AfterID)
+}
+
+// within reports whether every message of the archive is in bounds.
+func within(a domain.MessageArchive, params domain.MessageListParams) bool {
+	return (params.BeforeID == 0 || a.MaxID < params.BeforeID) &&
+		(params.AfterID == 0 || a.MinID > params.AfterID)
+}
+
+// GetLastMessage falls back to the newest archive for chats whose every
+// message was archived.
+func (r *ArchivedMessageRepo) GetLastMessage(ctx context.Context, chatID int) (*domain.Message, error) {
+	const op = "archivedmessage.GetLastMessage"
+
+	message, err := r.PgMessageRepo.GetLastMessage(ctx, chatID)
+	if !errors.Is(err, errs.ErrNotFound) {
+		return message, err
+	}
+
+	archives, err := r.archives.ListByChat(ctx, chatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if len(archives) == 0 {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	archived, err := r.store.read(ctx, archives[len(archives)-1].Path)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if len(archived) == 0 {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return &archived[len(archived)-1], nil
+}
+
+// CountExpired includes archives whose every message expired. Archives are
+// expired as a whole, so their messages count once the month they were sent
+// in is past the retention period.
+func (r *ArchivedMessageRepo) CountExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	mode domain.RetentionMode,
+) (int, error) {
+	const op = "archivedmessage.CountExpired"
+
+	count, err := r.PgMessageRepo.CountExpired(ctx, policy, mode)
+	if err != nil {
+		return 0, err
+	}
+
+	archives, err := r.archives.ListExpired(ctx, policy, mode == domain.RetentionModeDelete)
+	if err != nil {
+		return 0, errs.Wrap(op, err)
+	}
+	for _, a := range archives {
+		count += a.MessageCount
+	}
+
+	return count, nil
+}
+
+// DeleteExpired deletes expired archives once the messages table has no
+// more expired messages. Archives are deleted whole, so the count may
+// exceed limit.
+func (r *ArchivedMessageRepo) DeleteExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	limit int,
+) ([]domain.ExpiredMessages, error) {
+	const op = "archivedmessage.DeleteExpired"
+
+	expired, err := r.PgMessageRepo.DeleteExpired(ctx, policy, limit)
+	if err != nil || domain.CountExpiredMessages(expired) >= limit {
+		return expired, err
+	}
+
+	archives, err := r.archives.ListExpired(ctx, policy, true)
+	if err != nil {
+		return expired, errs.Wrap(op, err)
+	}
+	for _, a := range archives {
+		if err := r.store.files.Delete(ctx, a.Path); err != nil {
+			return expired, errs.Wrap(op, fmt.Errorf("failed to delete archive %s: %w", a.Path, err))
+		}
+		if err := r.archives.Delete(ctx, a.ChatID, a.Month); err != nil {
+			return expired, errs.Wrap(op, err)
+		}
+		expired = append(expired, domain.ExpiredMessages{ChatID: a.ChatID, MaxID: a.MaxID, Count: a.MessageCount})
+	}
+
+	return expired, nil
+}
+
+// AnonymizeExpired rewrites expired archives once the messages table has no
+// more expired messages. Archives are rewritten whole, so the count may
+// exceed limit.
+func (r *ArchivedMessageRepo) AnonymizeExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	limit int,
+) ([]domain.ExpiredMessages, error) {
+	const op = "archivedmessage.AnonymizeExpired"
+
+	expired, err := r.PgMessageRepo.AnonymizeExpired(ctx, policy, limit)
+	if err != nil || domain.CountExpiredMessages(expired) >= limit {
+		return expired, err
+	}
+
+	archives, err := r.archives.ListExpired(ctx, policy, false)
+	if err != nil {
+		return expired, errs.Wrap(op, err)
+	}
+	for _, a := range archives {
+		messages, err := r.store.read(ctx, a.Path)
+		if err != nil {
+			return expired, errs.Wrap(op, err)
+		}
+		for i := range messages {
+			if err := anonymizeMessage(&messages[i]); err != nil {
+				return expired, errs.Wrap(op, err)
+			}
+		}
+
+		// Rewriting in place is safe: a failure before MarkAnonymized
+		// leaves the archive to be anonymized again on the next run.
+		if err := r.store.write(ctx, a.Path, messages); err != nil {
+			return expired, errs.Wrap(op, err)
+		}
+		if err := r.archives.MarkAnonymized(ctx, a.ChatID, a.Month, time.Now()); err != nil {
+			return expired, errs.Wrap(op, err)
+		}
+		expired = append(expired, domain.ExpiredMessages{ChatID: a.ChatID, MaxID: a.MaxID, Count: a.MessageCount})
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,101 @@
+package infra
+
+import (
+	"encoding/json"
+
+	"chatx-01-backend/internal/chat/domain"
+)
+
+// messageEntities stores message entities in the messages.entities JSONB
+// column with stable snake_case keys.
+type messageEntities []domain.MessageEntity
+
+type messageEntityJSON struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
+}
+
+func (e messageEntities) MarshalJSON() ([]byte, error) {
+	rows := make([]messageEntityJSON, len(e))
+	for i, entity := range e {
+		rows[i] = messageEntityJSON{
+			Type:   entity.Type,
+			Offset: entity.Offset,
+			Length: entity.Length,
+			URL:    entity.URL,
+		}
+	}
+	return json.Marshal(rows)
+}
+
+func (e *messageEntities) UnmarshalJSON(data []byte) error {
+	var rows []messageEntityJSON
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+
+	entities := make(messageEntities, len(rows))
+	for i, row := range rows {
+		entities[i] = domain.MessageEntity{
+			Type:   row.Type,
+			Offset: row.Offset,
+			Length: row.Length,
+			URL:    row.URL,
+		}
+	}
+	*e = entities
+	return nil
+}
+
+// messageAttachments stores message attachments in the messages.attachments
+// JSONB column with stable snake_case keys.
+type messageAttachments []domain.Attachment
+
+type messageAttachmentJSON struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+func (a messageAttachments) MarshalJSON() ([]byte, error) {
+	rows := make([]messageAttachmentJSON, len(a))
+	for i, attachment := range a {
+		rows[i] = messageAttachmentJSON{
+			Path:        attachment.Path,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+		}
+	}
+	return json.Marshal(rows)
+}
+
+func (a *messageAttachments) UnmarshalJSON(data []byte) error {
+	var rows []messageAttachmentJSON
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+
+	attachments := make(messageAttachments, len(rows))
+	for i, row := range rows {
+		attachments[i] = domain.Attachment{
+			Path:        row.Path,
+			Name:        row.Name,
+			ContentType: row.ContentType,
+			Size:        row.Size,
+		}
+	}
+	*a = attachments
+	return nil
+}
+
+// metadataValue stores missing metadata as an empty object rather than JSON null.
+func metadataValue(md domain.Metadata) domain.Metadata {
+	if md == nil {
+		return domain.Metadata{}
+	}
+	return md
+}
@@ -0,0 +1,866 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemChatRepo is an in-memory ChatRepository for the dev command.
+type MemChatRepo struct {
+	store *MemStore
+}
+
+func NewMemChatRepo(store *MemStore) *MemChatRepo {
+	return &MemChatRepo{
+		store: store,
+	}
+}
+
+func (r *MemChatRepo) Create(ctx context.Context, chat *domain.Chat) error {
+	const op = "memchat.Create"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(op, chat)
+}
+
+func (r *MemChatRepo) CreateSaved(ctx context.Context, chat *domain.Chat) error {
+	const op = "memchat.CreateSaved"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	chat.Type = domain.ChatTypeSaved
+	if err := r.create(op, chat); err != nil {
+		return err
+	}
+
+	r.store.participants[chat.ID] = append(r.store.participants[chat.ID], &domain.ChatParticipant{
+		ChatID:   chat.ID,
+		UserID:   chat.CreatorID,
+		JoinedAt: chat.CreatedAt,
+	})
+
+	return nil
+}
+
+// create stores a new chat. The caller holds the lock.
+func (r *MemChatRepo) create(op string, chat *domain.Chat) error {
+	if chat.Type == domain.ChatTypeSaved {
+		for _, c := range r.store.chats {
+			if c.Type == domain.ChatTypeSaved && c.CreatorID == chat.CreatorID {
+				return errs.Wrap(op, errs.ErrAlreadyExists)
+			}
+		}
+	}
+
+	r.store.lastChatID++
+	chat.ID = r.store.lastChatID
+	chat.PublicID = uuid.NewString()
+
+	stored := *chat
+	stored.LastMessageAt = nil
+	stored.LastMessageID = nil
+	stored.RetentionDays = nil
+	stored.Description = ""
+	stored.ImagePath = nil
+	r.store.chats[chat.ID] = &stored
+	r.store.chatIDs[chat.PublicID] = chat.ID
+
+	return nil
+}
+
+func (r *MemChatRepo) GetByID(ctx context.Context, id int) (*domain.Chat, error) {
+	const op = "memchat.GetByID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.chats[id]; !ok {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	chat := r.store.chat(id)
+	return &chat, nil
+}
+
+func (r *MemChatRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "memchat.GetIDByPublicID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	id, ok := r.store.chatIDs[publicID]
+	if !ok {
+		return 0, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return id, nil
+}
+
+func (r *MemChatRepo) GetDMByParticipants(
+	ctx context.Context,
+	workspaceID, userID1, userID2 int,
+) (*domain.Chat, error) {
+	const op = "memchat.GetDMByParticipants"
+
+	return r.find(op, func(c *domain.Chat) bool {
+		return c.Type == domain.ChatTypeDirect && inWorkspace(c, workspaceID) &&
+			r.store.participant(c.ID, userID1) != nil && r.store.participant(c.ID, userID2) != nil
+	})
+}
+
+func (r *MemChatRepo) GetSavedByUser(ctx context.Context, userID int) (*domain.Chat, error) {
+	const op = "memchat.GetSavedByUser"
+
+	return r.find(op, func(c *domain.Chat) bool {
+		return c.Type == domain.ChatTypeSaved && c.CreatorID == userID
+	})
+}
+
+func (r *MemChatRepo) GetDMsListEnriched(
+	ctx context.Context,
+	workspaceID, userID int,
+	filter domain.ChatFilter,
+	sort domain.ChatSort,
+	offset, limit int,
+	withTotal bool,
+) ([]domain.ChatListEntry, int, error) {
+	return r.list(domain.ChatTypeDirect, workspaceID, userID, filter, sort, offset, limit, withTotal)
+}
+
+func (r *MemChatRepo) GetGroupsListEnriched(
+	ctx context.Context,
+	workspaceID, userID int,
+	filter domain.ChatFilter,
+	sort domain.ChatSort,
+	offset, limit int,
+	withTotal bool,
+) ([]domain.ChatListEntry, int, error) {
+	return r.list(domain.ChatTypeGroup, workspaceID, userID, filter, sort, offset, limit, withTotal)
+}
+
+func (r *MemChatRepo) AddParticipant(ctx context.Context, participant *domain.ChatParticipant) error {
+	const op = "memchat.AddParticipant"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[participant.ChatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	if r.store.participant(participant.ChatID, participant.UserID) != nil {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+
+	stored := *participant
+	r.store.participants[participant.ChatID] = append(r.store.participants[participant.ChatID], &stored)
+
+	return nil
+}
+
+func (r *MemChatRepo) RemoveParticipant(ctx context.Context, chatID, userID int) error {
+	const op = "memchat.RemoveParticipant"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	participants := r.store.participants[chatID]
+	i := slices.IndexFunc(participants, func(p *domain.ChatParticipant) bool { return p.UserID == userID })
+	if i < 0 {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	r.store.participants[chatID] = slices.Delete(participants, i, i+1)
+
+	return nil
+}
+
+func (r *MemChatRepo) GetParticipants(ctx context.Context, chatID int) ([]domain.ChatParticipant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	participants := make([]domain.ChatParticipant, 0, len(r.store.participants[chatID]))
+	for _, p := range r.store.participants[chatID] {
+		participants = append(participants, *p)
+	}
+
+	return participants, nil
+}
+
+func (r *MemChatRepo) IsParticipant(ctx context.Context, workspaceID, chatID, userID int) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	chat, ok := r.store.chats[chatID]
+	if !ok || !inWorkspaceOrNone(chat, workspaceID) {
+		return false, nil
+	}
+
+	return r.store.participant(chatID, userID) != nil, nil
+}
+
+func (r *MemChatRepo) UpdateLastRead(ctx context.Context, chatID, userID, messageID int) error {
+	const op = "memchat.UpdateLastRead"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p := r.store.participant(chatID, userID)
+	if p == nil {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	now := time.Now()
+	p.LastReadMessageID = &messageID
+	p.LastReadAt = &now
+
+	return nil
+}
+
+func (r *MemChatRepo) UpdateLastReadBatch(ctx context.Context, userID int, marks []domain.ReadMark) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, mark := range marks {
+		p := r.store.participant(mark.ChatID, userID)
+		if p == nil {
+			continue
+		}
+
+		messageID := mark.MessageID
+		p.LastReadMessageID = &messageID
+		p.LastReadAt = &now
+	}
+
+	return nil
+}
+
+func (r *MemChatRepo) GetUserChatIDs(ctx context.Context, workspaceID, userID int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	chatIDs := make([]int, 0)
+	for id, chat := range r.store.chats {
+		if inWorkspaceOrNone(chat, workspaceID) && r.store.participant(id, userID) != nil {
+			chatIDs = append(chatIDs, id)
+		}
+	}
+
+	return chatIDs, nil
+}
+
+func (r *MemChatRepo) GetUserChannelIDs(ctx context.Context, workspaceID, userID int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	chatIDs := make([]int, 0)
+	for id, chat := range r.store.chats {
+		if chat.Type == domain.ChatTypeChannel && inWorkspace(chat, workspaceID) && r.store.participant(id, userID) != nil {
+			chatIDs = append(chatIDs, id)
+		}
+	}
+
+	return chatIDs, nil
+}
+
+func (r *MemChatRepo) GetChannelsList(
+	ctx context.Context,
+	workspaceID, userID int,
+	subscribedOnly bool,
+	offset, limit int,
+) ([]domain.ChannelListEntry, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := make([]domain.ChannelListEntry, 0)
+	for id, chat := range r.store.chats {
+		if chat.Type != domain.ChatTypeChannel || !inWorkspace(chat, workspaceID) {
+			continue
+		}
+		entry := domain.ChannelListEntry{Chat: r.store.chat(id)}
+		if p := r.store.participant(id, userID); p != nil {
+			entry.Subscribed = true
+			entry.UnreadCount = r.store.unreadCount(p)
+		} else if subscribedOnly {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b domain.ChannelListEntry) int {
+		at, bt := a.Chat.CreatedAt, b.Chat.CreatedAt
+		if a.Chat.LastMessageAt != nil {
+			at = *a.Chat.LastMessageAt
+		}
+		if b.Chat.LastMessageAt != nil {
+			bt = *b.Chat.LastMessageAt
+		}
+		if c := bt.Compare(at); c != 0 {
+			return c
+		}
+		return b.Chat.ID - a.Chat.ID
+	})
+
+	return page(entries, offset, limit), len(entries), nil
+}
+
+func (r *MemChatRepo) GetContactIDs(ctx context.Context, userID int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	seen := make(map[int]bool)
+	userIDs := make([]int, 0)
+	for chatID, chat := range r.store.chats {
+		if chat.Type == domain.ChatTypeChannel || r.store.participant(chatID, userID) == nil {
+			continue
+		}
+		for _, p := range r.store.participants[chatID] {
+			if p.UserID != userID && !seen[p.UserID] {
+				seen[p.UserID] = true
+				userIDs = append(userIDs, p.UserID)
+			}
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (r *MemChatRepo) UpsertInvite(ctx context.Context, invite *domain.ChatInvite) error {
+	const op = "memchat.UpsertInvite"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[invite.ChatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	for _, existing := range r.store.invites {
+		if existing.ChatID == invite.ChatID && strings.EqualFold(existing.Email, invite.Email) {
+			existing.InviterID = invite.InviterID
+			existing.CreatedAt = invite.CreatedAt
+			existing.ExpiresAt = invite.ExpiresAt
+			invite.ID = existing.ID
+			return nil
+		}
+	}
+
+	r.store.lastInviteID++
+	invite.ID = r.store.lastInviteID
+
+	stored := *invite
+	r.store.invites[invite.ID] = &stored
+
+	return nil
+}
+
+func (r *MemChatRepo) AcceptInvites(
+	ctx context.Context,
+	email string,
+	userID int,
+	joinedAt time.Time,
+) ([]domain.ChatInvite, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invites := make([]domain.ChatInvite, 0)
+	for id, invite := range r.store.invites {
+		if !strings.EqualFold(invite.Email, email) {
+			continue
+		}
+		delete(r.store.invites, id)
+
+		if !invite.ExpiresAt.After(joinedAt) {
+			continue
+		}
+		if r.store.participant(invite.ChatID, userID) == nil {
+			r.store.participants[invite.ChatID] = append(r.store.participants[invite.ChatID], &domain.ChatParticipant{
+				ChatID:   invite.ChatID,
+				UserID:   userID,
+				JoinedAt: joinedAt,
+			})
+		}
+		invites = append(invites, *invite)
+	}
+
+	return invites, nil
+}
+
+func (r *MemChatRepo) SetArchived(ctx context.Context, chatID, userID int, archived bool) error {
+	const op = "memchat.SetArchived"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p := r.store.participant(chatID, userID)
+	if p == nil {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	p.Archived = archived
+
+	return nil
+}
+
+func (r *MemChatRepo) SetPinned(ctx context.Context, chatID, userID int, pinned bool) error {
+	const op = "memchat.SetPinned"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p := r.store.participant(chatID, userID)
+	if p == nil {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	p.Pinned = pinned
+
+	return nil
+}
+
+func (r *MemChatRepo) SetMutedUntil(ctx context.Context, chatID, userID int, until *time.Time) error {
+	const op = "memchat.SetMutedUntil"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p := r.store.participant(chatID, userID)
+	if p == nil {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	if until != nil {
+		t := *until
+		until = &t
+	}
+	p.MutedUntil = until
+
+	return nil
+}
+
+func (r *MemChatRepo) SetRetention(ctx context.Context, chatID int, days *int) error {
+	const op = "memchat.SetRetention"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	chat, ok := r.store.chats[chatID]
+	if !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	if days != nil {
+		d := *days
+		days = &d
+	}
+	chat.RetentionDays = days
+
+	return nil
+}
+
+func (r *MemChatRepo) SetCreator(ctx context.Context, chatID, creatorID int) error {
+	const op = "memchat.SetCreator"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	chat, ok := r.store.chats[chatID]
+	if !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	chat.CreatorID = creatorID
+
+	return nil
+}
+
+func (r *MemChatRepo) SetDescription(ctx context.Context, chatID int, description string) error {
+	const op = "memchat.SetDescription"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	chat, ok := r.store.chats[chatID]
+	if !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	chat.Description = description
+
+	return nil
+}
+
+func (r *MemChatRepo) SetImage(ctx context.Context, chatID int, imagePath *string) error {
+	const op = "memchat.SetImage"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	chat, ok := r.store.chats[chatID]
+	if !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	if imagePath != nil {
+		p := *imagePath
+		imagePath = &p
+	}
+	chat.ImagePath = imagePath
+
+	return nil
+}
+
+func (r *MemChatRepo) Delete(ctx context.Context, chatID int) error {
+	const op = "memchat.Delete"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[chatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	r.store.deleteChat(chatID)
+
+	return nil
+}
+
+func (r *MemChatRepo) CreateWebhook(ctx context.Context, webhook *domain.ChatWebhook) error {
+	const op = "memchat.CreateWebhook"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[webhook.ChatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	r.store.lastHookID++
+	webhook.ID = r.store.lastHookID
+	webhook.PublicID = uuid.NewString()
+
+	stored := *webhook
+	r.store.webhooks[webhook.ID] = &stored
+
+	return nil
+}
+
+func (r *MemChatRepo) GetWebhookByPublicID(ctx context.Context, publicID string) (*domain.ChatWebhook, error) {
+	const op = "memchat.GetWebhookByPublicID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, webhook := range r.store.webhooks {
+		if webhook.PublicID == publicID {
+			found := *webhook
+			return &found, nil
+		}
+	}
+
+	return nil, errs.Wrap(op, errs.ErrNotFound)
+}
+
+func (r *MemChatRepo) GetWebhooks(ctx context.Context, chatID int) ([]domain.ChatWebhook, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	webhooks := make([]domain.ChatWebhook, 0)
+	for _, webhook := range r.store.webhooks {
+		if webhook.ChatID == chatID {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b domain.ChatWebhook) int { return a.ID - b.ID })
+
+	return webhooks, nil
+}
+
+func (r *MemChatRepo) DeleteWebhook(ctx context.Context, chatID, webhookID int) error {
+	const op = "memchat.DeleteWebhook"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	webhook, ok := r.store.webhooks[webhookID]
+	if !ok || webhook.ChatID != chatID {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	delete(r.store.webhooks, webhookID)
+
+	return nil
+}
+
+func (r *MemChatRepo) TouchWebhook(ctx context.Context, webhookID int, usedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if webhook, ok := r.store.webhooks[webhookID]; ok {
+		webhook.LastUsedAt = &usedAt
+	}
+
+	return nil
+}
+
+func (r *MemChatRepo) CreateInviteLink(ctx context.Context, link *domain.ChatInviteLink) error {
+	const op = "memchat.CreateInviteLink"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[link.ChatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	for _, existing := range r.store.inviteLinks {
+		if existing.Code == link.Code {
+			return errs.Wrap(op, errs.ErrAlreadyExists)
+		}
+	}
+
+	r.store.lastLinkID++
+	link.ID = r.store.lastLinkID
+
+	stored := *link
+	r.store.inviteLinks[link.ID] = &stored
+
+	return nil
+}
+
+func (r *MemChatRepo) GetInviteLinkByCode(ctx context.Context, code string) (*domain.ChatInviteLink, error) {
+	const op = "memchat.GetInviteLinkByCode"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, link := range r.store.inviteLinks {
+		if link.Code == code {
+			found := *link
+			return &found, nil
+		}
+	}
+
+	return nil, errs.Wrap(op, errs.ErrNotFound)
+}
+
+func (r *MemChatRepo) GetInviteLinks(ctx context.Context, chatID int, now time.Time) ([]domain.ChatInviteLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	links := make([]domain.ChatInviteLink, 0)
+	for _, link := range r.store.inviteLinks {
+		if link.ChatID == chatID && !link.Expired(now) {
+			links = append(links, *link)
+		}
+	}
+	slices.SortFunc(links, func(a, b domain.ChatInviteLink) int { return a.ID - b.ID })
+
+	return links, nil
+}
+
+func (r *MemChatRepo) DeleteInviteLink(ctx context.Context, chatID, linkID int) error {
+	const op = "memchat.DeleteInviteLink"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	link, ok := r.store.inviteLinks[linkID]
+	if !ok || link.ChatID != chatID {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	delete(r.store.inviteLinks, linkID)
+
+	return nil
+}
+
+func (r *MemChatRepo) CreateJoinRequest(ctx context.Context, request *domain.ChatJoinRequest) error {
+	const op = "memchat.CreateJoinRequest"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[request.ChatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+	for _, existing := range r.store.joinRequests {
+		if existing.ChatID == request.ChatID && existing.UserID == request.UserID {
+			return errs.Wrap(op, errs.ErrAlreadyExists)
+		}
+	}
+
+	r.store.lastJoinID++
+	request.ID = r.store.lastJoinID
+
+	stored := *request
+	r.store.joinRequests[request.ID] = &stored
+
+	return nil
+}
+
+func (r *MemChatRepo) GetJoinRequests(ctx context.Context, chatID int) ([]domain.ChatJoinRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	requests := make([]domain.ChatJoinRequest, 0)
+	for _, request := range r.store.joinRequests {
+		if request.ChatID == chatID {
+			requests = append(requests, *request)
+		}
+	}
+	slices.SortFunc(requests, func(a, b domain.ChatJoinRequest) int { return a.ID - b.ID })
+
+	return requests, nil
+}
+
+func (r *MemChatRepo) ApproveJoinRequest(
+	ctx context.Context,
+	chatID, requestID int,
+	joinedAt time.Time,
+) (*domain.ChatJoinRequest, bool, error) {
+	const op = "memchat.ApproveJoinRequest"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	request, ok := r.store.joinRequests[requestID]
+	if !ok || request.ChatID != chatID {
+		return nil, false, errs.Wrap(op, errs.ErrNotFound)
+	}
+	delete(r.store.joinRequests, requestID)
+
+	if r.store.participant(chatID, request.UserID) != nil {
+		return request, false, nil
+	}
+	r.store.participants[chatID] = append(r.store.participants[chatID], &domain.ChatParticipant{
+		ChatID:   chatID,
+		UserID:   request.UserID,
+		JoinedAt: joinedAt,
+	})
+
+	return request, true, nil
+}
+
+func (r *MemChatRepo) DeleteJoinRequest(ctx context.Context, chatID, requestID int) (*domain.ChatJoinRequest, error) {
+	const op = "memchat.DeleteJoinRequest"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	request, ok := r.store.joinRequests[requestID]
+	if !ok || request.ChatID != chatID {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+	delete(r.store.joinRequests, requestID)
+
+	return request, nil
+}
+
+// find returns the first chat matching match.
+func (r *MemChatRepo) find(op string, match func(c *domain.Chat) bool) (*domain.Chat, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for id, chat := range r.store.chats {
+		if match(chat) {
+			found := r.store.chat(id)
+			return &found, nil
+		}
+	}
+
+	return nil, errs.Wrap(op, errs.ErrNotFound)
+}
+
+// list returns a page of the workspace's chats of a type the user is a
+// participant of matching filter, pinned chats first and then in the order
+// of chatOrderBy.
+func (r *MemChatRepo) list(
+	chatType domain.ChatType,
+	workspaceID, userID int,
+	filter domain.ChatFilter,
+	sort domain.ChatSort,
+	offset, limit int,
+	withTotal bool,
+) ([]domain.ChatListEntry, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	archived := filter == domain.ChatFilterArchived
+	chats := make([]domain.Chat, 0)
+	for id, chat := range r.store.chats {
+		if chat.Type != chatType || !inWorkspace(chat, workspaceID) {
+			continue
+		}
+		if p := r.store.participant(id, userID); p != nil && p.Archived == archived {
+			chats = append(chats, r.store.chat(id))
+		}
+	}
+
+	slices.SortFunc(chats, func(a, b domain.Chat) int {
+		ap, bp := r.store.participant(a.ID, userID).Pinned, r.store.participant(b.ID, userID).Pinned
+		if ap != bp {
+			if ap {
+				return -1
+			}
+			return 1
+		}
+		at, bt := a.CreatedAt, b.CreatedAt
+		if sort != domain.ChatSortCreated {
+			if a.LastMessageAt != nil {
+				at = *a.LastMessageAt
+			}
+			if b.LastMessageAt != nil {
+				bt = *b.LastMessageAt
+			}
+		}
+		if c := bt.Compare(at); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+
+	total := 0
+	if withTotal {
+		total = len(chats)
+	}
+
+	chats = page(chats, offset, limit)
+	entries := make([]domain.ChatListEntry, 0, len(chats))
+	for _, chat := range chats {
+		p := r.store.participant(chat.ID, userID)
+		entry := domain.ChatListEntry{
+			Chat:        chat,
+			UnreadCount: r.store.unreadCount(p),
+			MutedUntil:  p.MutedUntil,
+			Archived:    p.Archived,
+			Pinned:      p.Pinned,
+		}
+		if chat.Type == domain.ChatTypeDirect {
+			for _, p := range r.store.participants[chat.ID] {
+				if p.UserID != userID {
+					entry.OtherUserID = p.UserID
+					break
+				}
+			}
+		}
+		if messages := r.store.messages[chat.ID]; len(messages) > 0 {
+			message := copyMessage(messages[len(messages)-1])
+			entry.LastMessage = &message
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, nil
+}
+
+func inWorkspace(chat *domain.Chat, workspaceID int) bool {
+	return chat.WorkspaceID != nil && *chat.WorkspaceID == workspaceID
+}
+
+func inWorkspaceOrNone(chat *domain.Chat, workspaceID int) bool {
+	return chat.WorkspaceID == nil || *chat.WorkspaceID == workspaceID
+}
@@ -0,0 +1,115 @@
+package infra
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+)
+
+// MemMentionRepo is an in-memory MentionRepository for the dev command.
+type MemMentionRepo struct {
+	store *MemStore
+}
+
+func NewMemMentionRepo(store *MemStore) *MemMentionRepo {
+	return &MemMentionRepo{
+		store: store,
+	}
+}
+
+func (r *MemMentionRepo) Create(ctx context.Context, mentions []domain.Mention) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, m := range mentions {
+		stored := r.store.mentions[m.MessageID]
+		if slices.ContainsFunc(stored, func(other *domain.Mention) bool { return other.UserID == m.UserID }) {
+			continue
+		}
+		mention := m
+		r.store.mentions[m.MessageID] = append(stored, &mention)
+	}
+
+	return nil
+}
+
+func (r *MemMentionRepo) DeleteByMessage(ctx context.Context, messageID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.mentions, messageID)
+
+	return nil
+}
+
+func (r *MemMentionRepo) ListUserIDs(ctx context.Context, messageID int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var userIDs []int
+	for _, m := range r.store.mentions[messageID] {
+		userIDs = append(userIDs, m.UserID)
+	}
+
+	return userIDs, nil
+}
+
+func (r *MemMentionRepo) Delete(ctx context.Context, messageID int, userIDs []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.mentions[messageID] = slices.DeleteFunc(r.store.mentions[messageID], func(m *domain.Mention) bool {
+		return slices.Contains(userIDs, m.UserID)
+	})
+	if len(r.store.mentions[messageID]) == 0 {
+		delete(r.store.mentions, messageID)
+	}
+
+	return nil
+}
+
+func (r *MemMentionRepo) CountUnreadByChat(ctx context.Context, chatID, userID int) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.countUnread(func(m *domain.Mention) bool {
+		return m.ChatID == chatID && m.UserID == userID
+	}), nil
+}
+
+func (r *MemMentionRepo) CountUnread(ctx context.Context, workspaceID, userID int) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	return r.countUnread(func(m *domain.Mention) bool {
+		if m.UserID != userID {
+			return false
+		}
+		chat, ok := r.store.chats[m.ChatID]
+		p := r.store.participant(m.ChatID, userID)
+		return ok && inWorkspace(chat, workspaceID) && (p == nil || !p.IsMuted(now))
+	}), nil
+}
+
+// countUnread counts the mentions matching match that their user has not
+// read yet, leaving out chats they no longer take part in. The caller holds
+// the lock.
+func (r *MemMentionRepo) countUnread(match func(m *domain.Mention) bool) int {
+	count := 0
+	for _, mentions := range r.store.mentions {
+		for _, m := range mentions {
+			if !match(m) {
+				continue
+			}
+			p := r.store.participant(m.ChatID, m.UserID)
+			if p == nil || (p.LastReadMessageID != nil && m.MessageID <= *p.LastReadMessageID) {
+				continue
+			}
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,475 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/snowflake"
+)
+
+// MemMessageRepo is an in-memory MessageRepository for the dev command.
+type MemMessageRepo struct {
+	store *MemStore
+	ids   *snowflake.Generator
+}
+
+// NewMemMessageRepo creates a message repository sharing store with a
+// MemChatRepo. Message IDs are taken from ids, as in the database.
+func NewMemMessageRepo(store *MemStore, ids *snowflake.Generator) *MemMessageRepo {
+	return &MemMessageRepo{
+		store: store,
+		ids:   ids,
+	}
+}
+
+func (r *MemMessageRepo) Create(ctx context.Context, message *domain.Message) error {
+	const op = "memmessage.Create"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.chats[message.ChatID]; !ok {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	message.ID = int(r.ids.Next())
+	message.PublicID = uuid.NewString()
+
+	stored := copyMessage(message)
+	stored.Metadata = metadataValue(stored.Metadata)
+
+	// IDs grow with time, so appending keeps the chat in ID order
+	r.store.messages[message.ChatID] = append(r.store.messages[message.ChatID], &stored)
+	r.store.messageByID[message.ID] = &stored
+	r.store.messageIDs[message.PublicID] = message.ID
+
+	return nil
+}
+
+func (r *MemMessageRepo) GetByID(ctx context.Context, id int) (*domain.Message, error) {
+	const op = "memmessage.GetByID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m, ok := r.store.messageByID[id]
+	if !ok {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	message := copyMessage(m)
+	return &message, nil
+}
+
+func (r *MemMessageRepo) GetByIDs(ctx context.Context, ids []int) ([]domain.Message, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	messages := make([]domain.Message, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := r.store.messageByID[id]; ok {
+			messages = append(messages, copyMessage(m))
+		}
+	}
+
+	return messages, nil
+}
+
+func (r *MemMessageRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "memmessage.GetIDByPublicID"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	id, ok := r.store.messageIDs[publicID]
+	if !ok {
+		return 0, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return id, nil
+}
+
+func (r *MemMessageRepo) Update(ctx context.Context, message *domain.Message) error {
+	const op = "memmessage.Update"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.messageByID[message.ID]
+	if !ok {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	stored.Content = message.Content
+	stored.Entities = slices.Clone(message.Entities)
+	stored.EditedAt = message.EditedAt
+
+	return nil
+}
+
+func (r *MemMessageRepo) SetMetadataKey(ctx context.Context, id int, key string, value json.RawMessage) error {
+	const op = "memmessage.SetMetadataKey"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.messageByID[id]
+	if !ok {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	if value == nil {
+		delete(stored.Metadata, key)
+	} else {
+		stored.Metadata[key] = slices.Clone(value)
+	}
+
+	return nil
+}
+
+func (r *MemMessageRepo) Delete(ctx context.Context, id int) error {
+	const op = "memmessage.Delete"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	m, ok := r.store.messageByID[id]
+	if !ok {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	r.store.messages[m.ChatID] = slices.DeleteFunc(r.store.messages[m.ChatID], func(other *domain.Message) bool {
+		return other.ID == id
+	})
+	r.store.forgetMessage(m)
+
+	return nil
+}
+
+func (r *MemMessageRepo) ListWithCount(
+	ctx context.Context,
+	params domain.MessageListParams,
+) ([]domain.Message, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	all := r.store.messages[params.ChatID]
+	messages := make([]domain.Message, 0)
+	for _, m := range all {
+		if (params.BeforeID > 0 && m.ID >= params.BeforeID) || (params.AfterID > 0 && m.ID <= params.AfterID) {
+			continue
+		}
+		messages = append(messages, copyMessage(m))
+	}
+	if params.Order != domain.SortAsc {
+		slices.Reverse(messages)
+	}
+
+	totalCount := 0
+	if !params.SkipTotal {
+		totalCount = len(all)
+	}
+
+	return page(messages, params.Offset, params.Limit), totalCount, nil
+}
+
+// Search matches whole words case-insensitively, like the simple text
+// search configuration, and ignores search operators. Snippets are the
+// whole message.
+func (r *MemMessageRepo) Search(
+	ctx context.Context,
+	params domain.MessageSearchParams,
+) ([]domain.MessageSearchResult, int, error) {
+	terms := searchWords(params.Query)
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	results := make([]domain.MessageSearchResult, 0)
+	for chatID, messages := range r.store.messages {
+		chat, ok := r.store.chats[chatID]
+		if !ok || !inWorkspaceOrNone(chat, params.WorkspaceID) || r.store.participant(chatID, params.UserID) == nil {
+			continue
+		}
+		if params.ChatID > 0 && chatID != params.ChatID {
+			continue
+		}
+
+		for _, m := range messages {
+			highlights, ok := matchWords(m.Content, terms)
+			if !ok {
+				continue
+			}
+			results = append(results, domain.MessageSearchResult{
+				Message:    copyMessage(m),
+				Snippet:    m.Content,
+				Highlights: highlights,
+			})
+		}
+	}
+
+	slices.SortFunc(results, func(a, b domain.MessageSearchResult) int {
+		return b.Message.ID - a.Message.ID
+	})
+
+	return page(results, params.Offset, params.Limit), len(results), nil
+}
+
+func (r *MemMessageRepo) GetLastMessage(ctx context.Context, chatID int) (*domain.Message, error) {
+	const op = "memmessage.GetLastMessage"
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	messages := r.store.messages[chatID]
+	if len(messages) == 0 {
+		return nil, errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	message := copyMessage(messages[len(messages)-1])
+	return &message, nil
+}
+
+func (r *MemMessageRepo) GetUnreadCountByChat(ctx context.Context, chatID, userID int) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	p := r.store.participant(chatID, userID)
+	if p == nil {
+		return 0, nil
+	}
+
+	return r.store.unreadCount(p), nil
+}
+
+func (r *MemMessageRepo) GetFirstUnreadMessageID(ctx context.Context, chatID, userID int) (*int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var lastRead *int
+	if p := r.store.participant(chatID, userID); p != nil {
+		lastRead = p.LastReadMessageID
+	}
+
+	for _, m := range r.store.messages[chatID] {
+		if m.SenderID != userID && (lastRead == nil || m.ID > *lastRead) {
+			id := m.ID
+			return &id, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *MemMessageRepo) GetTotalUnreadCount(ctx context.Context, workspaceID, userID int) (int, error) {
+	total := 0
+	r.eachUnread(workspaceID, userID, func(count int) { total += count })
+	return total, nil
+}
+
+func (r *MemMessageRepo) GetUnreadChatsCount(ctx context.Context, workspaceID, userID int) (int, error) {
+	chats := 0
+	r.eachUnread(workspaceID, userID, func(int) { chats++ })
+	return chats, nil
+}
+
+func (r *MemMessageRepo) CountExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	mode domain.RetentionMode,
+) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	r.eachExpired(policy, mode == domain.RetentionModeAnonymize, func(*domain.Message) bool {
+		count++
+		return true
+	})
+
+	return count, nil
+}
+
+func (r *MemMessageRepo) DeleteExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	limit int,
+) ([]domain.ExpiredMessages, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	expired := make([]*domain.Message, 0)
+	r.eachExpired(policy, false, func(m *domain.Message) bool {
+		expired = append(expired, m)
+		return len(expired) < limit
+	})
+
+	for _, m := range expired {
+		r.store.messages[m.ChatID] = slices.DeleteFunc(r.store.messages[m.ChatID], func(other *domain.Message) bool {
+			return other == m
+		})
+		r.store.forgetMessage(m)
+	}
+
+	return summarizeExpired(expired), nil
+}
+
+func (r *MemMessageRepo) AnonymizeExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	limit int,
+) ([]domain.ExpiredMessages, error) {
+	const op = "memmessage.AnonymizeExpired"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	anonymized := make([]*domain.Message, 0)
+	var err error
+	r.eachExpired(policy, true, func(m *domain.Message) bool {
+		if err = anonymizeMessage(m); err != nil {
+			return false
+		}
+		anonymized = append(anonymized, m)
+		return len(anonymized) < limit
+	})
+	if err != nil {
+		return summarizeExpired(anonymized), errs.Wrap(op, err)
+	}
+
+	return summarizeExpired(anonymized), nil
+}
+
+// summarizeExpired groups messages handled by a retention batch by chat.
+func summarizeExpired(messages []*domain.Message) []domain.ExpiredMessages {
+	expired := make([]domain.ExpiredMessages, 0)
+	for _, m := range messages {
+		i := slices.IndexFunc(expired, func(e domain.ExpiredMessages) bool { return e.ChatID == m.ChatID })
+		if i < 0 {
+			expired = append(expired, domain.ExpiredMessages{ChatID: m.ChatID})
+			i = len(expired) - 1
+		}
+		expired[i].MaxID = max(expired[i].MaxID, m.ID)
+		expired[i].Count++
+	}
+	return expired
+}
+
+// eachUnread calls fn with the unread count of each of the user's chats in
+// the workspace with unread messages, leaving out Saved Messages and chats
+// the user muted.
+func (r *MemMessageRepo) eachUnread(workspaceID, userID int, fn func(count int)) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	for id, chat := range r.store.chats {
+		if chat.Type == domain.ChatTypeSaved || !inWorkspace(chat, workspaceID) {
+			continue
+		}
+		if p := r.store.participant(id, userID); p != nil && !p.IsMuted(now) {
+			if count := r.store.unreadCount(p); count > 0 {
+				fn(count)
+			}
+		}
+	}
+}
+
+// eachExpired calls fn with the messages past their chat's retention period
+// until it returns false, leaving out anonymized messages if notAnonymized
+// is set. The caller holds the lock.
+func (r *MemMessageRepo) eachExpired(
+	policy domain.RetentionPolicy,
+	notAnonymized bool,
+	fn func(m *domain.Message) bool,
+) {
+	for id, chat := range r.store.chats {
+		days := policy.DefaultDays
+		if chat.RetentionDays != nil {
+			days = *chat.RetentionDays
+		}
+		if days <= 0 {
+			continue
+		}
+
+		cutoff := policy.Now.AddDate(0, 0, -days)
+		for _, m := range r.store.messages[id] {
+			if !m.SentAt.Before(cutoff) {
+				break
+			}
+			if notAnonymized && isAnonymized(m) {
+				continue
+			}
+			if !fn(m) {
+				return
+			}
+		}
+	}
+}
+
+// isAnonymized reports whether the retention policy anonymized the message.
+func isAnonymized(m *domain.Message) bool {
+	var system domain.SystemMetadata
+	raw, ok := m.Metadata[domain.MetadataSystem]
+	if !ok || json.Unmarshal(raw, &system) != nil {
+		return false
+	}
+	return system.Event == domain.SystemEventRetentionExpired
+}
+
+// searchWords returns the distinct lowercased words of a search query.
+func searchWords(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), isWordSeparator)
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+// matchWords returns the ranges of the words of content that are among
+// terms, and whether every term was found.
+func matchWords(content string, terms []string) ([]domain.TextRange, bool) {
+	if len(terms) == 0 {
+		return nil, false
+	}
+
+	highlights := make([]domain.TextRange, 0)
+	found := make(map[string]bool, len(terms))
+
+	var word []rune
+	offset := 0
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := strings.ToLower(string(word))
+		if slices.Contains(terms, w) {
+			found[w] = true
+			highlights = append(highlights, domain.TextRange{Offset: offset - len(word), Length: len(word)})
+		}
+		word = word[:0]
+	}
+
+	for _, r := range content {
+		if isWordSeparator(r) {
+			flush()
+		} else {
+			word = append(word, r)
+		}
+		offset++
+	}
+	flush()
+
+	return highlights, len(found) == len(terms)
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
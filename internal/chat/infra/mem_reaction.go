@@ -0,0 +1,79 @@
+package infra
+
+import (
+	"context"
+	"slices"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MemReactionRepo is an in-memory ReactionRepository for the dev command.
+type MemReactionRepo struct {
+	store *MemStore
+}
+
+func NewMemReactionRepo(store *MemStore) *MemReactionRepo {
+	return &MemReactionRepo{
+		store: store,
+	}
+}
+
+func (r *MemReactionRepo) Add(ctx context.Context, reaction *domain.Reaction) error {
+	const op = "memreaction.Add"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reactions := r.store.reactions[reaction.MessageID]
+	if slices.ContainsFunc(reactions, func(re *domain.Reaction) bool {
+		return re.UserID == reaction.UserID && re.Emoji == reaction.Emoji
+	}) {
+		return errs.Wrap(op, errs.ErrAlreadyExists)
+	}
+
+	stored := *reaction
+	r.store.reactions[reaction.MessageID] = append(reactions, &stored)
+
+	return nil
+}
+
+func (r *MemReactionRepo) Remove(ctx context.Context, messageID, userID int, emoji string) error {
+	const op = "memreaction.Remove"
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reactions := r.store.reactions[messageID]
+	i := slices.IndexFunc(reactions, func(re *domain.Reaction) bool {
+		return re.UserID == userID && re.Emoji == emoji
+	})
+	if i < 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	r.store.reactions[messageID] = slices.Delete(reactions, i, i+1)
+
+	return nil
+}
+
+func (r *MemReactionRepo) ListByMessage(ctx context.Context, messageID int) ([]domain.Reaction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reactions := make([]domain.Reaction, 0, len(r.store.reactions[messageID]))
+	for _, re := range r.store.reactions[messageID] {
+		reactions = append(reactions, *re)
+	}
+
+	return reactions, nil
+}
+
+func (r *MemReactionRepo) DeleteByMessage(ctx context.Context, messageID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.reactions, messageID)
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package infra
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"chatx-01-backend/internal/chat/domain"
+)
+
+// MemStore holds the tables of the in-memory repositories used by the dev
+// command. Unread counts, participant counts and the last message of a chat
+// are derived from the tables on read rather than denormalized, so they
+// cannot drift. Data is lost when the process exits.
+type MemStore struct {
+	mu sync.RWMutex
+
+	chats        map[int]*domain.Chat
+	chatIDs      map[string]int // public ID -> ID
+	lastChatID   int
+	participants map[int][]*domain.ChatParticipant // chat ID -> participants in join order
+	invites      map[int]*domain.ChatInvite
+	lastInviteID int
+	webhooks     map[int]*domain.ChatWebhook
+	lastHookID   int
+	inviteLinks  map[int]*domain.ChatInviteLink
+	lastLinkID   int
+	joinRequests map[int]*domain.ChatJoinRequest
+	lastJoinID   int
+	messages     map[int][]*domain.Message // chat ID -> messages in ID order
+	messageByID  map[int]*domain.Message
+	messageIDs   map[string]int             // public ID -> ID
+	reactions    map[int][]*domain.Reaction // message ID -> reactions in order added
+	mentions     map[int][]*domain.Mention  // message ID -> mentioned participants
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{
+		chats:        make(map[int]*domain.Chat),
+		chatIDs:      make(map[string]int),
+		participants: make(map[int][]*domain.ChatParticipant),
+		invites:      make(map[int]*domain.ChatInvite),
+		webhooks:     make(map[int]*domain.ChatWebhook),
+		inviteLinks:  make(map[int]*domain.ChatInviteLink),
+		joinRequests: make(map[int]*domain.ChatJoinRequest),
+		messages:     make(map[int][]*domain.Message),
+		messageByID:  make(map[int]*domain.Message),
+		messageIDs:   make(map[string]int),
+		reactions:    make(map[int][]*domain.Reaction),
+		mentions:     make(map[int][]*domain.Mention),
+	}
+}
+
+// DeleteUser removes the user's chat memberships, messages, reactions,
+// mentions, invites, webhooks, invite links, join requests and the chats they
+// created, like the foreign key cascades of the database.
+func (s *MemStore) DeleteUser(ctx context.Context, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, chat := range s.chats {
+		if chat.CreatorID == userID {
+			s.deleteChat(id)
+		}
+	}
+	for chatID, participants := range s.participants {
+		s.participants[chatID] = slices.DeleteFunc(participants, func(p *domain.ChatParticipant) bool {
+			return p.UserID == userID
+		})
+	}
+	for chatID, messages := range s.messages {
+		s.messages[chatID] = slices.DeleteFunc(messages, func(m *domain.Message) bool {
+			if m.SenderID != userID {
+				return false
+			}
+			s.forgetMessage(m)
+			return true
+		})
+	}
+	for messageID, reactions := range s.reactions {
+		s.reactions[messageID] = slices.DeleteFunc(reactions, func(r *domain.Reaction) bool {
+			return r.UserID == userID
+		})
+	}
+	for messageID, mentions := range s.mentions {
+		s.mentions[messageID] = slices.DeleteFunc(mentions, func(m *domain.Mention) bool {
+			return m.UserID == userID
+		})
+	}
+	maps.DeleteFunc(s.invites, func(_ int, invite *domain.ChatInvite) bool {
+		return invite.InviterID == userID
+	})
+	maps.DeleteFunc(s.webhooks, func(_ int, webhook *domain.ChatWebhook) bool {
+		return webhook.CreatorID == userID
+	})
+	maps.DeleteFunc(s.inviteLinks, func(_ int, link *domain.ChatInviteLink) bool {
+		return link.CreatorID == userID
+	})
+	maps.DeleteFunc(s.joinRequests, func(_ int, request *domain.ChatJoinRequest) bool {
+		return request.UserID == userID
+	})
+}
+
+// deleteChat removes a chat with its participants, invites, webhooks, invite
+// links, join requests and messages.
+// The caller holds the lock.
+func (s *MemStore) deleteChat(id int) {
+	for _, m := range s.messages[id] {
+		s.forgetMessage(m)
+	}
+	delete(s.chatIDs, s.chats[id].PublicID)
+	delete(s.chats, id)
+	delete(s.participants, id)
+	delete(s.messages, id)
+	maps.DeleteFunc(s.invites, func(_ int, invite *domain.ChatInvite) bool {
+		return invite.ChatID == id
+	})
+	maps.DeleteFunc(s.webhooks, func(_ int, webhook *domain.ChatWebhook) bool {
+		return webhook.ChatID == id
+	})
+	maps.DeleteFunc(s.inviteLinks, func(_ int, link *domain.ChatInviteLink) bool {
+		return link.ChatID == id
+	})
+	maps.DeleteFunc(s.joinRequests, func(_ int, request *domain.ChatJoinRequest) bool {
+		return request.ChatID == id
+	})
+}
+
+// forgetMessage removes a message from the ID indexes along with its
+// reactions and mentions. The caller holds the lock and removes it from its
+// chat.
+func (s *MemStore) forgetMessage(m *domain.Message) {
+	delete(s.messageByID, m.ID)
+	delete(s.messageIDs, m.PublicID)
+	delete(s.reactions, m.ID)
+	delete(s.mentions, m.ID)
+}
+
+// participant returns the user's participation in a chat, or nil. The caller
+// holds the lock.
+func (s *MemStore) participant(chatID, userID int) *domain.ChatParticipant {
+	for _, p := range s.participants[chatID] {
+		if p.UserID == userID {
+			return p
+		}
+	}
+	return nil
+}
+
+// unreadCount counts the messages of others after the participant's read
+// pointer. The caller holds the lock.
+func (s *MemStore) unreadCount(p *domain.ChatParticipant) int {
+	count := 0
+	for _, m := range s.messages[p.ChatID] {
+		if m.SenderID != p.UserID && (p.LastReadMessageID == nil || m.ID > *p.LastReadMessageID) {
+			count++
+		}
+	}
+	return count
+}
+
+// chat returns a copy of a chat with its derived columns filled in. The
+// caller holds the lock.
+func (s *MemStore) chat(id int) domain.Chat {
+	chat := *s.chats[id]
+	chat.ParticipantCount = len(s.participants[id])
+	chat.LastMessageID = nil
+	chat.LastMessageAt = nil
+
+	if messages := s.messages[id]; len(messages) > 0 {
+		id, sentAt := messages[len(messages)-1].ID, messages[len(messages)-1].SentAt
+		chat.LastMessageID = &id
+		chat.LastMessageAt = &sentAt
+	}
+
+	return chat
+}
+
+// copyMessage returns a copy of a message that shares no slices or maps
+// with the store.
+func copyMessage(m *domain.Message) domain.Message {
+	message := *m
+	message.Entities = slices.Clone(m.Entities)
+	message.Attachments = slices.Clone(m.Attachments)
+	message.Metadata = maps.Clone(m.Metadata)
+	return message
+}
+
+// page returns up to limit items starting at offset.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
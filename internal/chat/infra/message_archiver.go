@@ -0,0 +1,145 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/filestore"
+)
+
+// MessageArchiver moves messages of past months from the messages table to
+// compressed objects in the file store, one per chat and month.
+type MessageArchiver struct {
+	archives *PgArchiveRepo
+	store    archiveStore
+}
+
+func NewMessageArchiver(archives *PgArchiveRepo, files filestore.Store) *MessageArchiver {
+	return &MessageArchiver{
+		archives: archives,
+		store:    archiveStore{files: files},
+	}
+}
+
+// ArchiveResult reports the work of one Archive call.
+type ArchiveResult struct {
+	Archives int
+	Messages int
+}
+
+// Archive archives up to limit chat months that ended before cutoff. Only
+// whole months are archived, so a message stays in the table until the
+// month after it was sent has passed cutoff. Messages sent into an archived
+// month later, such as by a clock-skewed server, are merged into its archive.
+func (a *MessageArchiver) Archive(ctx context.Context, cutoff time.Time, limit int) (*ArchiveResult, error) {
+	const op = "messagearchiver.Archive"
+
+	candidates, err := a.archives.ListCandidates(ctx, cutoff, limit)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	result := &ArchiveResult{}
+	for _, c := range candidates {
+		n, err := a.archiveMonth(ctx, c)
+		if err != nil {
+			return result, errs.Wrap(op, err)
+		}
+		result.Archives++
+		result.Messages += n
+	}
+
+	return result, nil
+}
+
+func (a *MessageArchiver) archiveMonth(ctx context.Context, c domain.ArchiveCandidate) (int, error) {
+	var (
+		added    int
+		existing *domain.MessageArchive
+		archive  *domain.MessageArchive
+	)
+	err := a.archives.ArchiveMonth(ctx, c.ChatID, c.Month, func(messages []domain.Message) (*domain.MessageArchive, error) {
+		added = len(messages)
+
+		var err error
+		existing, err = a.existing(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			archived, err := a.store.read(ctx, existing.Path)
+			if err != nil {
+				return nil, err
+			}
+			messages = mergeMessages(archived, messages)
+		}
+
+		archive = &domain.MessageArchive{
+			ChatID:       c.ChatID,
+			Month:        c.Month,
+			MessageCount: len(messages),
+			MinID:        messages[0].ID,
+			MaxID:        messages[len(messages)-1].ID,
+			ArchivedAt:   time.Now(),
+		}
+		archive.Path = archivePath(c.ChatID, c.Month, archive.ArchivedAt)
+
+		// The messages stay locked during the upload. A failure between
+		// upload and commit leaves an unreferenced object behind, but never
+		// a record without its messages.
+		if err := a.store.write(ctx, archive.Path, messages); err != nil {
+			return nil, fmt.Errorf("failed to upload archive %s: %w", archive.Path, err)
+		}
+		return archive, nil
+	})
+	if err != nil || archive == nil {
+		return 0, err
+	}
+
+	if existing != nil && existing.Path != archive.Path {
+		if err := a.store.files.Delete(ctx, existing.Path); err != nil {
+			return 0, fmt.Errorf("failed to delete replaced archive %s: %w", existing.Path, err)
+		}
+	}
+
+	return added, nil
+}
+
+func (a *MessageArchiver) existing(ctx context.Context, c domain.ArchiveCandidate) (*domain.MessageArchive, error) {
+	archives, err := a.archives.ListByChat(ctx, c.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	for _, archive := range archives {
+		if archive.Month.Equal(c.Month) {
+			return &archive, nil
+		}
+	}
+	return nil, nil
+}
+
+// mergeMessages merges two ID-ordered message lists, preferring b for IDs
+// in both.
+func mergeMessages(a, b []domain.Message) []domain.Message {
+	merged := make([]domain.Message, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].ID < b[j].ID:
+			merged = append(merged, a[i])
+			i++
+		case a[i].ID > b[j].ID:
+			merged = append(merged, b[j])
+			j++
+		default:
+			merged = append(merged, b[j])
+			i++
+			j++
+		}
+	}
+	merged = append(merged, a[i:]...)
+	return append(merged, b[j:]...)
+}
@@ -0,0 +1,262 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgArchiveRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgArchiveRepo(pool *pgxpool.Pool) *PgArchiveRepo {
+	return &PgArchiveRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgArchiveRepo) ListCandidates(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) ([]domain.ArchiveCandidate, error) {
+	const op = "pgarchive.ListCandidates"
+
+	query := `
+		SELECT chat_id, date_trunc('month', sent_at AT TIME ZONE 'UTC') AS month
+		FROM messages
+		WHERE sent_at < date_trunc('month', $1::timestamptz AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+		GROUP BY chat_id, month
+		ORDER BY month, chat_id
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	candidates := make([]domain.ArchiveCandidate, 0)
+	for rows.Next() {
+		var c domain.ArchiveCandidate
+		if err := rows.Scan(&c.ChatID, &c.Month); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		c.Month = c.Month.UTC()
+		candidates = append(candidates, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return candidates, nil
+}
+
+func (r *PgArchiveRepo) ArchiveMonth(
+	ctx context.Context,
+	chatID int,
+	month time.Time,
+	archive func(messages []domain.Message) (*domain.MessageArchive, error),
+) error {
+	const op = "pgarchive.ArchiveMonth"
+
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		messages, err := lockMonthMessages(ctx, tx, chatID, month)
+		if err != nil {
+			return pg.WrapRepoError(op, err)
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		archived, err := archive(messages)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+		}
+
+		// Only the locked messages are in the object; anything sent into the
+		// month since stays for the next run.
+		query := `
+			WITH deleted AS (
+				DELETE FROM messages
+				WHERE id = ANY($8)
+			)
+			INSERT INTO message_archives (chat_id, month, object_path, message_count, min_id, max_id, archived_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (chat_id, month) DO UPDATE
+			SET object_path = EXCLUDED.object_path,
+				message_count = EXCLUDED.message_count,
+				min_id = EXCLUDED.min_id,
+				max_id = EXCLUDED.max_id,
+				archived_at = EXCLUDED.archived_at`
+
+		_, err = tx.Exec(
+			ctx,
+			query,
+			archived.ChatID,
+			archived.Month,
+			archived.Path,
+			archived.MessageCount,
+			archived.MinID,
+			archived.MaxID,
+			archived.ArchivedAt,
+			ids,
+		)
+		if err != nil {
+			return pg.WrapRepoError(op, err)
+		}
+
+		return nil
+	})
+}
+
+// lockMonthMessages returns the messages a chat sent in a month, in ID
+// order, locked until the transaction ends.
+func lockMonthMessages(ctx context.Context, tx pgx.Tx, chatID int, month time.Time) ([]domain.Message, error) {
+	query := `
+		SELECT id, public_id, chat_id, sender_id, content, entities, attachments, metadata, sent_at, edited_at
+		FROM messages
+		WHERE chat_id = $1 AND sent_at >= $2 AND sent_at < $3
+		ORDER BY id
+		FOR UPDATE`
+
+	rows, err := tx.Query(ctx, query, chatID, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]domain.Message, 0)
+	for rows.Next() {
+		message := domain.Message{}
+		err := rows.Scan(
+			&message.ID,
+			&message.PublicID,
+			&message.ChatID,
+			&message.SenderID,
+			&message.Content,
+			(*messageEntities)(&message.Entities),
+			(*messageAttachments)(&message.Attachments),
+			&message.Metadata,
+			&message.SentAt,
+			&message.EditedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *PgArchiveRepo) ListByChat(ctx context.Context, chatID int) ([]domain.MessageArchive, error) {
+	const op = "pgarchive.ListByChat"
+
+	query := `
+		SELECT chat_id, month, object_path, message_count, min_id, max_id, archived_at, anonymized_at
+		FROM message_archives
+		WHERE chat_id = $1
+		ORDER BY min_id`
+
+	return r.list(ctx, op, query, chatID)
+}
+
+func (r *PgArchiveRepo) ListExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	includeAnonymized bool,
+) ([]domain.MessageArchive, error) {
+	const op = "pgarchive.ListExpired"
+
+	query := `
+		SELECT a.chat_id, a.month, a.object_path, a.message_count, a.min_id, a.max_id, a.archived_at, a.anonymized_at
+		FROM message_archives a
+		INNER JOIN chats c ON c.id = a.chat_id
+		WHERE COALESCE(c.retention_days, $1) > 0
+		AND (a.month + interval '1 month') AT TIME ZONE 'UTC'
+			< $2::timestamptz - make_interval(days => COALESCE(c.retention_days, $1))
+		AND ($3 OR a.anonymized_at IS NULL)
+		ORDER BY a.month, a.chat_id`
+
+	return r.list(ctx, op, query, policy.DefaultDays, policy.Now, includeAnonymized)
+}
+
+func (r *PgArchiveRepo) Delete(ctx context.Context, chatID int, month time.Time) error {
+	const op = "pgarchive.Delete"
+
+	query := `DELETE FROM message_archives WHERE chat_id = $1 AND month = $2`
+
+	result, err := r.pool.Exec(ctx, query, chatID, month)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgArchiveRepo) MarkAnonymized(ctx context.Context, chatID int, month time.Time, at time.Time) error {
+	const op = "pgarchive.MarkAnonymized"
+
+	query := `UPDATE message_archives SET anonymized_at = $3 WHERE chat_id = $1 AND month = $2`
+
+	result, err := r.pool.Exec(ctx, query, chatID, month, at)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgArchiveRepo) list(ctx context.Context, op, query string, args ...any) ([]domain.MessageArchive, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	archives := make([]domain.MessageArchive, 0)
+	for rows.Next() {
+		var a domain.MessageArchive
+		err := rows.Scan(
+			&a.ChatID,
+			&a.Month,
+			&a.Path,
+			&a.MessageCount,
+			&a.MinID,
+			&a.MaxID,
+			&a.ArchivedAt,
+			&a.AnonymizedAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		a.Month = a.Month.UTC()
+		archives = append(archives, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return archives, nil
+}
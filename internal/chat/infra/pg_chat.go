@@ -3,6 +3,7 @@ package infra
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -25,9 +26,9 @@ func (r *PgChatRepo) Create(ctx context.Context, chat *domain.Chat) error {
 	const op = "pgchat.Create"
 
 	query := `
-		INSERT INTO chats (type, name, creator_id, created_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
+		INSERT INTO chats (type, name, creator_id, workspace_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, public_id`
 
 	err := r.pool.QueryRow(
 		ctx,
@@ -35,8 +36,41 @@ func (r *PgChatRepo) Create(ctx context.Context, chat *domain.Chat) error {
 		chat.Type,
 		chat.Name,
 		chat.CreatorID,
+		chat.WorkspaceID,
 		chat.CreatedAt,
-	).Scan(&chat.ID)
+	).Scan(&chat.ID, &chat.PublicID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) CreateSaved(ctx context.Context, chat *domain.Chat) error {
+	const op = "pgchat.CreateSaved"
+
+	// One statement, so the chat never exists without its participant
+	query := `
+		WITH created AS (
+			INSERT INTO chats (type, name, creator_id, workspace_id, created_at, participant_count)
+			VALUES ($1, $2, $3, $4, $5, 1)
+			RETURNING id, public_id, creator_id, created_at
+		), joined AS (
+			INSERT INTO chat_participants (chat_id, user_id, joined_at)
+			SELECT id, creator_id, created_at
+			FROM created
+		)
+		SELECT id, public_id FROM created`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		domain.ChatTypeSaved,
+		chat.Name,
+		chat.CreatorID,
+		chat.WorkspaceID,
+		chat.CreatedAt,
+	).Scan(&chat.ID, &chat.PublicID)
 	if err != nil {
 		return pg.WrapRepoError(op, err)
 	}
@@ -48,17 +82,26 @@ func (r *PgChatRepo) GetByID(ctx context.Context, id int) (*domain.Chat, error)
 	const op = "pgchat.GetByID"
 
 	query := `
-		SELECT id, type, name, creator_id, created_at
+		SELECT id, public_id, type, name, creator_id, workspace_id, created_at, last_message_at, retention_days,
+			participant_count, last_message_id, description, image_path
 		FROM chats
 		WHERE id = $1`
 
 	chat := &domain.Chat{}
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&chat.ID,
+		&chat.PublicID,
 		&chat.Type,
 		&chat.Name,
 		&chat.CreatorID,
+		&chat.WorkspaceID,
 		&chat.CreatedAt,
+		&chat.LastMessageAt,
+		&chat.RetentionDays,
+		&chat.ParticipantCount,
+		&chat.LastMessageID,
+		&chat.Description,
+		&chat.ImagePath,
 	)
 	if err != nil {
 		return nil, pg.WrapRepoError(op, err)
@@ -67,23 +110,45 @@ func (r *PgChatRepo) GetByID(ctx context.Context, id int) (*domain.Chat, error)
 	return chat, nil
 }
 
-func (r *PgChatRepo) GetDMByParticipants(ctx context.Context, userID1, userID2 int) (*domain.Chat, error) {
+func (r *PgChatRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "pgchat.GetIDByPublicID"
+
+	query := `SELECT id FROM chats WHERE public_id = $1`
+
+	var id int
+	if err := r.pool.QueryRow(ctx, query, publicID).Scan(&id); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return id, nil
+}
+
+func (r *PgChatRepo) GetDMByParticipants(ctx context.Context, workspaceID, userID1, userID2 int) (*domain.Chat, error) {
 	const op = "pgchat.GetDMByParticipants"
 
 	query := `
-		SELECT c.id, c.type, c.name, c.creator_id, c.created_at
+		SELECT c.id, c.public_id, c.type, c.name, c.creator_id, c.workspace_id, c.created_at, c.last_message_at,
+			c.retention_days, c.participant_count, c.last_message_id, c.description, c.image_path
 		FROM chats c
 		INNER JOIN chat_participants cp1 ON c.id = cp1.chat_id AND cp1.user_id = $1
 		INNER JOIN chat_participants cp2 ON c.id = cp2.chat_id AND cp2.user_id = $2
-		WHERE c.type = $3`
+		WHERE c.type = $3 AND c.workspace_id = $4`
 
 	chat := &domain.Chat{}
-	err := r.pool.QueryRow(ctx, query, userID1, userID2, domain.ChatTypeDirect).Scan(
+	err := r.pool.QueryRow(ctx, query, userID1, userID2, domain.ChatTypeDirect, workspaceID).Scan(
 		&chat.ID,
+		&chat.PublicID,
 		&chat.Type,
 		&chat.Name,
 		&chat.CreatorID,
+		&chat.WorkspaceID,
 		&chat.CreatedAt,
+		&chat.LastMessageAt,
+		&chat.RetentionDays,
+		&chat.ParticipantCount,
+		&chat.LastMessageID,
+		&chat.Description,
+		&chat.ImagePath,
 	)
 	if err != nil {
 		return nil, pg.WrapRepoError(op, err)
@@ -92,120 +157,216 @@ func (r *PgChatRepo) GetDMByParticipants(ctx context.Context, userID1, userID2 i
 	return chat, nil
 }
 
-func (r *PgChatRepo) GetDMsListByUser(ctx context.Context, userID int, offset, limit int) ([]domain.Chat, int, error) {
-	const op = "pgchat.GetDMsListByUser"
+func (r *PgChatRepo) GetSavedByUser(ctx context.Context, userID int) (*domain.Chat, error) {
+	const op = "pgchat.GetSavedByUser"
 
-	var totalCount int
-	countQuery := `
-		SELECT COUNT(DISTINCT c.id)
-		FROM chats c
-		INNER JOIN chat_participants cp ON c.id = cp.chat_id
-		WHERE cp.user_id = $1 AND c.type = $2`
+	query := `
+		SELECT id, public_id, type, name, creator_id, workspace_id, created_at, last_message_at, retention_days,
+			participant_count, last_message_id, description, image_path
+		FROM chats
+		WHERE creator_id = $1 AND type = $2`
 
-	err := r.pool.QueryRow(ctx, countQuery, userID, domain.ChatTypeDirect).Scan(&totalCount)
+	chat := &domain.Chat{}
+	err := r.pool.QueryRow(ctx, query, userID, domain.ChatTypeSaved).Scan(
+		&chat.ID,
+		&chat.PublicID,
+		&chat.Type,
+		&chat.Name,
+		&chat.CreatorID,
+		&chat.WorkspaceID,
+		&chat.CreatedAt,
+		&chat.LastMessageAt,
+		&chat.RetentionDays,
+		&chat.ParticipantCount,
+		&chat.LastMessageID,
+		&chat.Description,
+		&chat.ImagePath,
+	)
 	if err != nil {
-		return nil, 0, pg.WrapRepoError(op, err)
+		return nil, pg.WrapRepoError(op, err)
 	}
 
-	query := `
-		SELECT c.id, c.type, c.name, c.creator_id, c.created_at
-		FROM chats c
-		INNER JOIN chat_participants cp ON c.id = cp.chat_id
-		WHERE cp.user_id = $1 AND c.type = $2
-		ORDER BY c.created_at DESC
-		LIMIT $3 OFFSET $4`
+	return chat, nil
+}
+
+func (r *PgChatRepo) GetDMsListEnriched(
+	ctx context.Context,
+	workspaceID, userID int,
+	filter domain.ChatFilter,
+	sort domain.ChatSort,
+	offset, limit int,
+	withTotal bool,
+) ([]domain.ChatListEntry, int, error) {
+	const op = "pgchat.GetDMsListEnriched"
 
-	rows, err := r.pool.Query(ctx, query, userID, domain.ChatTypeDirect, limit, offset)
+	entries, total, err := r.listEnriched(
+		ctx, domain.ChatTypeDirect, workspaceID, userID, filter, sort, offset, limit, withTotal,
+	)
 	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
-	defer rows.Close()
 
-	chats := make([]domain.Chat, 0)
-	for rows.Next() {
-		chat := domain.Chat{}
-		err := rows.Scan(
-			&chat.ID,
-			&chat.Type,
-			&chat.Name,
-			&chat.CreatorID,
-			&chat.CreatedAt,
-		)
-		if err != nil {
-			return nil, 0, pg.WrapRepoError(op, err)
-		}
-		chats = append(chats, chat)
-	}
+	return entries, total, nil
+}
 
-	if err := rows.Err(); err != nil {
+func (r *PgChatRepo) GetGroupsListEnriched(
+	ctx context.Context,
+	workspaceID, userID int,
+	filter domain.ChatFilter,
+	sort domain.ChatSort,
+	offset, limit int,
+	withTotal bool,
+) ([]domain.ChatListEntry, int, error) {
+	const op = "pgchat.GetGroupsListEnriched"
+
+	entries, total, err := r.listEnriched(
+		ctx, domain.ChatTypeGroup, workspaceID, userID, filter, sort, offset, limit, withTotal,
+	)
+	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
 
-	return chats, totalCount, nil
+	return entries, total, nil
 }
 
-func (r *PgChatRepo) GetGroupsListByUser(
+// listEnriched lists the chats of a type a user participates in with what
+// the chat lists show about them in one query. The other participant is only
+// looked up for direct chats.
+func (r *PgChatRepo) listEnriched(
 	ctx context.Context,
-	userID int,
+	chatType domain.ChatType,
+	workspaceID, userID int,
+	filter domain.ChatFilter,
+	sort domain.ChatSort,
 	offset, limit int,
-) ([]domain.Chat, int, error) {
-	const op = "pgchat.GetGroupsListByUser"
-
+	withTotal bool,
+) ([]domain.ChatListEntry, int, error) {
+	// A user joins a chat at most once, so counting participant rows counts
+	// chats without a DISTINCT over the join.
 	var totalCount int
 	countQuery := `
-		SELECT COUNT(DISTINCT c.id)
+		SELECT COUNT(*)
 		FROM chats c
 		INNER JOIN chat_participants cp ON c.id = cp.chat_id
-		WHERE cp.user_id = $1 AND c.type = $2`
+		WHERE cp.user_id = $1 AND c.type = $2 AND c.workspace_id = $3 AND cp.archived = $4`
 
-	err := r.pool.QueryRow(ctx, countQuery, userID, domain.ChatTypeGroup).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, pg.WrapRepoError(op, err)
+	archived := filter == domain.ChatFilterArchived
+	if withTotal {
+		err := r.pool.QueryRow(ctx, countQuery, userID, chatType, workspaceID, archived).Scan(&totalCount)
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 
 	query := `
-		SELECT c.id, c.type, c.name, c.creator_id, c.created_at
+		SELECT c.id, c.public_id, c.type, c.name, c.creator_id, c.workspace_id, c.created_at, c.last_message_at,
+			c.retention_days, c.participant_count, c.last_message_id, c.description, c.image_path,
+			COALESCE(other.user_id, 0), COALESCE(uc.count, 0), cp.muted_until, cp.archived, cp.pinned,
+			m.id, m.public_id, m.sender_id, m.content, m.sent_at
 		FROM chats c
 		INNER JOIN chat_participants cp ON c.id = cp.chat_id
-		WHERE cp.user_id = $1 AND c.type = $2
-		ORDER BY c.created_at DESC
-		LIMIT $3 OFFSET $4`
+		LEFT JOIN chat_unread_counters uc ON uc.chat_id = cp.chat_id AND uc.user_id = cp.user_id
+		LEFT JOIN LATERAL (
+			SELECT o.user_id
+			FROM chat_participants o
+			WHERE c.type = $6 AND o.chat_id = c.id AND o.user_id != cp.user_id
+			LIMIT 1
+		) other ON true
+		LEFT JOIN messages m ON m.id = c.last_message_id AND m.chat_id = c.id
+		WHERE cp.user_id = $1 AND c.type = $2 AND c.workspace_id = $3 AND cp.archived = $7
+		ORDER BY cp.pinned DESC, ` + chatOrderBy(sort) + `
+		LIMIT $4 OFFSET $5`
 
-	rows, err := r.pool.Query(ctx, query, userID, domain.ChatTypeGroup, limit, offset)
+	rows, err := r.pool.Query(
+		ctx, query, userID, chatType, workspaceID, limit, offset, domain.ChatTypeDirect, archived,
+	)
 	if err != nil {
-		return nil, 0, pg.WrapRepoError(op, err)
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	chats := make([]domain.Chat, 0)
+	entries := make([]domain.ChatListEntry, 0)
 	for rows.Next() {
-		chat := domain.Chat{}
+		var (
+			entry           domain.ChatListEntry
+			messageID       *int
+			messagePublicID *string
+			senderID        *int
+			content         *string
+			sentAt          *time.Time
+		)
+		chat := &entry.Chat
 		err := rows.Scan(
 			&chat.ID,
+			&chat.PublicID,
 			&chat.Type,
 			&chat.Name,
 			&chat.CreatorID,
+			&chat.WorkspaceID,
 			&chat.CreatedAt,
+			&chat.LastMessageAt,
+			&chat.RetentionDays,
+			&chat.ParticipantCount,
+			&chat.LastMessageID,
+			&chat.Description,
+			&chat.ImagePath,
+			&entry.OtherUserID,
+			&entry.UnreadCount,
+			&entry.MutedUntil,
+			&entry.Archived,
+			&entry.Pinned,
+			&messageID,
+			&messagePublicID,
+			&senderID,
+			&content,
+			&sentAt,
 		)
 		if err != nil {
-			return nil, 0, pg.WrapRepoError(op, err)
+			return nil, 0, err
+		}
+		if messageID != nil {
+			entry.LastMessage = &domain.Message{
+				ID:       *messageID,
+				PublicID: *messagePublicID,
+				ChatID:   chat.ID,
+				SenderID: *senderID,
+				Content:  *content,
+				SentAt:   *sentAt,
+			}
 		}
-		chats = append(chats, chat)
+		entries = append(entries, entry)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, pg.WrapRepoError(op, err)
+		return nil, 0, err
 	}
 
-	return chats, totalCount, nil
+	return entries, totalCount, nil
 }
 
 func (r *PgChatRepo) AddParticipant(ctx context.Context, participant *domain.ChatParticipant) error {
 	const op = "pgchat.AddParticipant"
 
+	// Messages sent before joining count as unread, like for any participant
+	// without a read pointer.
 	query := `
-		INSERT INTO chat_participants (chat_id, user_id, joined_at, last_read_message_id, last_read_at)
-		VALUES ($1, $2, $3, $4, $5)`
+		WITH joined AS (
+			INSERT INTO chat_participants (chat_id, user_id, joined_at, last_read_message_id, last_read_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING chat_id, user_id, last_read_message_id
+		), counted AS (
+			INSERT INTO chat_unread_counters (chat_id, user_id, count)
+			SELECT j.chat_id, j.user_id, COUNT(*)
+			FROM joined j
+			INNER JOIN messages m ON m.chat_id = j.chat_id
+			WHERE m.sender_id != j.user_id
+			AND (j.last_read_message_id IS NULL OR m.id > j.last_read_message_id)
+			GROUP BY j.chat_id, j.user_id
+		)
+		UPDATE chats c
+		SET participant_count = c.participant_count + 1
+		FROM joined j
+		WHERE c.id = j.chat_id`
 
 	_, err := r.pool.Exec(
 		ctx,
@@ -226,7 +387,15 @@ func (r *PgChatRepo) AddParticipant(ctx context.Context, participant *domain.Cha
 func (r *PgChatRepo) RemoveParticipant(ctx context.Context, chatID, userID int) error {
 	const op = "pgchat.RemoveParticipant"
 
-	query := `DELETE FROM chat_participants WHERE chat_id = $1 AND user_id = $2`
+	query := `
+		WITH removed AS (
+			DELETE FROM chat_participants WHERE chat_id = $1 AND user_id = $2
+			RETURNING chat_id
+		)
+		UPDATE chats c
+		SET participant_count = GREATEST(c.participant_count - 1, 0)
+		FROM removed r
+		WHERE c.id = r.chat_id`
 
 	result, err := r.pool.Exec(ctx, query, chatID, userID)
 	if err != nil {
@@ -245,7 +414,7 @@ func (r *PgChatRepo) GetParticipants(ctx context.Context, chatID int) ([]domain.
 	const op = "pgchat.GetParticipants"
 
 	query := `
-		SELECT chat_id, user_id, joined_at, last_read_message_id, last_read_at
+		SELECT chat_id, user_id, joined_at, last_read_message_id, last_read_at, muted_until, archived, pinned
 		FROM chat_participants
 		WHERE chat_id = $1
 		ORDER BY joined_at ASC`
@@ -265,6 +434,9 @@ func (r *PgChatRepo) GetParticipants(ctx context.Context, chatID int) ([]domain.
 			&participant.JoinedAt,
 			&participant.LastReadMessageID,
 			&participant.LastReadAt,
+			&participant.MutedUntil,
+			&participant.Archived,
+			&participant.Pinned,
 		)
 		if err != nil {
 			return nil, pg.WrapRepoError(op, err)
@@ -279,13 +451,20 @@ func (r *PgChatRepo) GetParticipants(ctx context.Context, chatID int) ([]domain.
 	return participants, nil
 }
 
-func (r *PgChatRepo) IsParticipant(ctx context.Context, chatID, userID int) (bool, error) {
+func (r *PgChatRepo) IsParticipant(ctx context.Context, workspaceID, chatID, userID int) (bool, error) {
 	const op = "pgchat.IsParticipant"
 
-	query := `SELECT EXISTS(SELECT 1 FROM chat_participants WHERE chat_id = $1 AND user_id = $2)`
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM chat_participants cp
+			INNER JOIN chats c ON c.id = cp.chat_id
+			WHERE cp.chat_id = $1 AND cp.user_id = $2
+				AND (c.workspace_id = $3 OR c.workspace_id IS NULL)
+		)`
 
 	var exists bool
-	err := r.pool.QueryRow(ctx, query, chatID, userID).Scan(&exists)
+	err := r.pool.QueryRow(ctx, query, chatID, userID, workspaceID).Scan(&exists)
 	if err != nil {
 		return false, pg.WrapRepoError(op, err)
 	}
@@ -296,10 +475,22 @@ func (r *PgChatRepo) IsParticipant(ctx context.Context, chatID, userID int) (boo
 func (r *PgChatRepo) UpdateLastRead(ctx context.Context, chatID, userID, messageID int) error {
 	const op = "pgchat.UpdateLastRead"
 
+	// The counter is recounted rather than decremented, which also corrects
+	// any drift whenever a participant reads the chat.
 	query := `
-		UPDATE chat_participants
-		SET last_read_message_id = $1, last_read_at = NOW()
-		WHERE chat_id = $2 AND user_id = $3`
+		WITH updated AS (
+			UPDATE chat_participants
+			SET last_read_message_id = $1, last_read_at = NOW()
+			WHERE chat_id = $2 AND user_id = $3
+			RETURNING chat_id, user_id
+		)
+		INSERT INTO chat_unread_counters (chat_id, user_id, count)
+		SELECT u.chat_id, u.user_id, (
+			SELECT COUNT(*) FROM messages m
+			WHERE m.chat_id = u.chat_id AND m.sender_id != u.user_id AND m.id > $1
+		)
+		FROM updated u
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET count = EXCLUDED.count`
 
 	result, err := r.pool.Exec(ctx, query, messageID, chatID, userID)
 	if err != nil {
@@ -314,12 +505,83 @@ func (r *PgChatRepo) UpdateLastRead(ctx context.Context, chatID, userID, message
 	return nil
 }
 
-func (r *PgChatRepo) GetUserChatIDs(ctx context.Context, userID int) ([]int, error) {
+func (r *PgChatRepo) UpdateLastReadBatch(ctx context.Context, userID int, marks []domain.ReadMark) error {
+	const op = "pgchat.UpdateLastReadBatch"
+
+	chatIDs := make([]int, len(marks))
+	messageIDs := make([]int, len(marks))
+	for i, mark := range marks {
+		chatIDs[i] = mark.ChatID
+		messageIDs[i] = mark.MessageID
+	}
+
+	// Same as UpdateLastRead, for every chat in one statement
+	query := `
+		WITH marks AS (
+			SELECT chat_id, message_id FROM unnest($2::int[], $3::bigint[]) AS m(chat_id, message_id)
+		), updated AS (
+			UPDATE chat_participants cp
+			SET last_read_message_id = m.message_id, last_read_at = NOW()
+			FROM marks m
+			WHERE cp.chat_id = m.chat_id AND cp.user_id = $1
+			RETURNING cp.chat_id, cp.user_id, cp.last_read_message_id
+		)
+		INSERT INTO chat_unread_counters (chat_id, user_id, count)
+		SELECT u.chat_id, u.user_id, (
+			SELECT COUNT(*) FROM messages m
+			WHERE m.chat_id = u.chat_id AND m.sender_id != u.user_id AND m.id > u.last_read_message_id
+		)
+		FROM updated u
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET count = EXCLUDED.count`
+
+	if _, err := r.pool.Exec(ctx, query, userID, chatIDs, messageIDs); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) GetUserChatIDs(ctx context.Context, workspaceID, userID int) ([]int, error) {
 	const op = "pgchat.GetUserChatIDs"
 
-	query := `SELECT chat_id FROM chat_participants WHERE user_id = $1`
+	query := `
+		SELECT cp.chat_id
+		FROM chat_participants cp
+		INNER JOIN chats c ON c.id = cp.chat_id
+		WHERE cp.user_id = $1 AND (c.workspace_id = $2 OR c.workspace_id IS NULL)`
+
+	rows, err := r.pool.Query(ctx, query, userID, workspaceID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	chatIDs := make([]int, 0)
+	for rows.Next() {
+		var chatID int
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return chatIDs, nil
+}
+
+func (r *PgChatRepo) GetUserChannelIDs(ctx context.Context, workspaceID, userID int) ([]int, error) {
+	const op = "pgchat.GetUserChannelIDs"
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	query := `
+		SELECT cp.chat_id
+		FROM chat_participants cp
+		INNER JOIN chats c ON c.id = cp.chat_id
+		WHERE cp.user_id = $1 AND c.workspace_id = $2 AND c.type = $3`
+
+	rows, err := r.pool.Query(ctx, query, userID, workspaceID, domain.ChatTypeChannel)
 	if err != nil {
 		return nil, pg.WrapRepoError(op, err)
 	}
@@ -340,3 +602,716 @@ func (r *PgChatRepo) GetUserChatIDs(ctx context.Context, userID int) ([]int, err
 
 	return chatIDs, nil
 }
+
+func (r *PgChatRepo) GetChannelsList(
+	ctx context.Context,
+	workspaceID, userID int,
+	subscribedOnly bool,
+	offset, limit int,
+) ([]domain.ChannelListEntry, int, error) {
+	const op = "pgchat.GetChannelsList"
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM chats c
+		LEFT JOIN chat_participants cp ON cp.chat_id = c.id AND cp.user_id = $1
+		WHERE c.type = $2 AND c.workspace_id = $3 AND (NOT $4 OR cp.user_id IS NOT NULL)`
+
+	var totalCount int
+	err := r.pool.QueryRow(ctx, countQuery, userID, domain.ChatTypeChannel, workspaceID, subscribedOnly).
+		Scan(&totalCount)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	query := `
+		SELECT c.id, c.public_id, c.type, c.name, c.creator_id, c.workspace_id, c.created_at, c.last_message_at,
+			c.retention_days, c.participant_count, c.last_message_id, c.description, c.image_path,
+			cp.user_id IS NOT NULL, COALESCE(uc.count, 0)
+		FROM chats c
+		LEFT JOIN chat_participants cp ON cp.chat_id = c.id AND cp.user_id = $1
+		LEFT JOIN chat_unread_counters uc ON uc.chat_id = cp.chat_id AND uc.user_id = cp.user_id
+		WHERE c.type = $2 AND c.workspace_id = $3 AND (NOT $4 OR cp.user_id IS NOT NULL)
+		ORDER BY ` + chatOrderBy(domain.ChatSortActivity) + `
+		LIMIT $5 OFFSET $6`
+
+	rows, err := r.pool.Query(ctx, query, userID, domain.ChatTypeChannel, workspaceID, subscribedOnly, limit, offset)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	entries := make([]domain.ChannelListEntry, 0)
+	for rows.Next() {
+		var entry domain.ChannelListEntry
+		chat := &entry.Chat
+		err := rows.Scan(
+			&chat.ID,
+			&chat.PublicID,
+			&chat.Type,
+			&chat.Name,
+			&chat.CreatorID,
+			&chat.WorkspaceID,
+			&chat.CreatedAt,
+			&chat.LastMessageAt,
+			&chat.RetentionDays,
+			&chat.ParticipantCount,
+			&chat.LastMessageID,
+			&chat.Description,
+			&chat.ImagePath,
+			&entry.Subscribed,
+			&entry.UnreadCount,
+		)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	return entries, totalCount, nil
+}
+
+func (r *PgChatRepo) GetContactIDs(ctx context.Context, userID int) ([]int, error) {
+	const op = "pgchat.GetContactIDs"
+
+	query := `
+		SELECT DISTINCT other.user_id
+		FROM chat_participants self
+		JOIN chat_participants other ON other.chat_id = self.chat_id
+		JOIN chats c ON c.id = self.chat_id
+		WHERE self.user_id = $1 AND other.user_id != $1 AND c.type != $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, domain.ChatTypeChannel)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return userIDs, nil
+}
+
+func (r *PgChatRepo) UpsertInvite(ctx context.Context, invite *domain.ChatInvite) error {
+	const op = "pgchat.UpsertInvite"
+
+	query := `
+		INSERT INTO chat_invites (chat_id, email, inviter_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (chat_id, lower(email)) DO UPDATE
+		SET inviter_id = EXCLUDED.inviter_id,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		RETURNING id`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		invite.ChatID,
+		invite.Email,
+		invite.InviterID,
+		invite.CreatedAt,
+		invite.ExpiresAt,
+	).Scan(&invite.ID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) AcceptInvites(
+	ctx context.Context,
+	email string,
+	userID int,
+	joinedAt time.Time,
+) ([]domain.ChatInvite, error) {
+	const op = "pgchat.AcceptInvites"
+
+	// Consume the invites and join their chats in one statement, so an invite
+	// is never accepted twice.
+	query := `
+		WITH removed AS (
+			DELETE FROM chat_invites
+			WHERE lower(email) = lower($1)
+			RETURNING id, chat_id, email, inviter_id, created_at, expires_at
+		), joined AS (
+			INSERT INTO chat_participants (chat_id, user_id, joined_at)
+			SELECT chat_id, $2, $3 FROM removed
+			WHERE expires_at > $3
+			ON CONFLICT (chat_id, user_id) DO NOTHING
+			RETURNING chat_id, user_id
+		), counted AS (
+			INSERT INTO chat_unread_counters (chat_id, user_id, count)
+			SELECT j.chat_id, j.user_id, COUNT(*)
+			FROM joined j
+			INNER JOIN messages m ON m.chat_id = j.chat_id AND m.sender_id != j.user_id
+			GROUP BY j.chat_id, j.user_id
+		), bumped AS (
+			UPDATE chats c
+			SET participant_count = c.participant_count + 1
+			FROM joined j
+			WHERE c.id = j.chat_id
+		)
+		SELECT id, chat_id, email, inviter_id, created_at, expires_at
+		FROM removed
+		WHERE expires_at > $3`
+
+	rows, err := r.pool.Query(ctx, query, email, userID, joinedAt)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	invites := make([]domain.ChatInvite, 0)
+	for rows.Next() {
+		var invite domain.ChatInvite
+		err := rows.Scan(
+			&invite.ID,
+			&invite.ChatID,
+			&invite.Email,
+			&invite.InviterID,
+			&invite.CreatedAt,
+			&invite.ExpiresAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		invites = append(invites, invite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return invites, nil
+}
+
+func (r *PgChatRepo) SetRetention(ctx context.Context, chatID int, days *int) error {
+	const op = "pgchat.SetRetention"
+
+	query := `UPDATE chats SET retention_days = $1 WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, days, chatID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) SetArchived(ctx context.Context, chatID, userID int, archived bool) error {
+	const op = "pgchat.SetArchived"
+
+	query := `UPDATE chat_participants SET archived = $1 WHERE chat_id = $2 AND user_id = $3`
+
+	result, err := r.pool.Exec(ctx, query, archived, chatID, userID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) SetPinned(ctx context.Context, chatID, userID int, pinned bool) error {
+	const op = "pgchat.SetPinned"
+
+	query := `UPDATE chat_participants SET pinned = $1 WHERE chat_id = $2 AND user_id = $3`
+
+	result, err := r.pool.Exec(ctx, query, pinned, chatID, userID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) SetMutedUntil(ctx context.Context, chatID, userID int, until *time.Time) error {
+	const op = "pgchat.SetMutedUntil"
+
+	query := `UPDATE chat_participants SET muted_until = $1 WHERE chat_id = $2 AND user_id = $3`
+
+	result, err := r.pool.Exec(ctx, query, until, chatID, userID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) SetCreator(ctx context.Context, chatID, creatorID int) error {
+	const op = "pgchat.SetCreator"
+
+	query := `UPDATE chats SET creator_id = $1 WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, creatorID, chatID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) SetDescription(ctx context.Context, chatID int, description string) error {
+	const op = "pgchat.SetDescription"
+
+	query := `UPDATE chats SET description = $1 WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, description, chatID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) SetImage(ctx context.Context, chatID int, imagePath *string) error {
+	const op = "pgchat.SetImage"
+
+	query := `UPDATE chats SET image_path = $1 WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, imagePath, chatID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Delete deletes a chat. Participants, messages, invites and the other rows
+// referencing the chat go with it through their foreign key cascades.
+func (r *PgChatRepo) Delete(ctx context.Context, chatID int) error {
+	const op = "pgchat.Delete"
+
+	query := `DELETE FROM chats WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, chatID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+// RepairParticipantCounts recounts the participants of up to limit chats
+// with an ID above afterChatID and corrects the counts that drifted. It
+// returns the highest chat ID checked, zero once no chats are left, and how
+// many counts were corrected.
+func (r *PgChatRepo) RepairParticipantCounts(ctx context.Context, afterChatID, limit int) (int, int, error) {
+	const op = "pgchat.RepairParticipantCounts"
+
+	query := `
+		WITH batch AS (
+			SELECT c.id, c.participant_count, COUNT(cp.user_id) AS actual
+			FROM chats c
+			LEFT JOIN chat_participants cp ON cp.chat_id = c.id
+			WHERE c.id IN (SELECT id FROM chats WHERE id > $1 ORDER BY id LIMIT $2)
+			GROUP BY c.id
+		), repaired AS (
+			UPDATE chats c
+			SET participant_count = b.actual
+			FROM batch b
+			WHERE c.id = b.id AND b.participant_count != b.actual
+			RETURNING 1
+		)
+		SELECT COALESCE((SELECT MAX(id) FROM batch), 0), (SELECT COUNT(*) FROM repaired)`
+
+	var lastChatID, repaired int
+	if err := r.pool.QueryRow(ctx, query, afterChatID, limit).Scan(&lastChatID, &repaired); err != nil {
+		return 0, 0, pg.WrapRepoError(op, err)
+	}
+
+	return lastChatID, repaired, nil
+}
+
+// chatOrderBy returns the ORDER BY clause for a chat list sort.
+// Chats without messages rank by creation time in the activity order.
+func chatOrderBy(sort domain.ChatSort) string {
+	if sort == domain.ChatSortCreated {
+		return "c.created_at DESC, c.id DESC"
+	}
+	return "COALESCE(c.last_message_at, c.created_at) DESC, c.id DESC"
+}
+
+func (r *PgChatRepo) CreateWebhook(ctx context.Context, webhook *domain.ChatWebhook) error {
+	const op = "pgchat.CreateWebhook"
+
+	query := `
+		INSERT INTO chat_webhooks (chat_id, creator_id, name, secret_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, public_id`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		webhook.ChatID,
+		webhook.CreatorID,
+		webhook.Name,
+		webhook.SecretHash,
+		webhook.CreatedAt,
+	).Scan(&webhook.ID, &webhook.PublicID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) GetWebhookByPublicID(ctx context.Context, publicID string) (*domain.ChatWebhook, error) {
+	const op = "pgchat.GetWebhookByPublicID"
+
+	query := `
+		SELECT id, public_id, chat_id, creator_id, name, secret_hash, created_at, last_used_at
+		FROM chat_webhooks
+		WHERE public_id = $1`
+
+	webhook := &domain.ChatWebhook{}
+	err := r.pool.QueryRow(ctx, query, publicID).Scan(
+		&webhook.ID,
+		&webhook.PublicID,
+		&webhook.ChatID,
+		&webhook.CreatorID,
+		&webhook.Name,
+		&webhook.SecretHash,
+		&webhook.CreatedAt,
+		&webhook.LastUsedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return webhook, nil
+}
+
+func (r *PgChatRepo) GetWebhooks(ctx context.Context, chatID int) ([]domain.ChatWebhook, error) {
+	const op = "pgchat.GetWebhooks"
+
+	query := `
+		SELECT id, public_id, chat_id, creator_id, name, secret_hash, created_at, last_used_at
+		FROM chat_webhooks
+		WHERE chat_id = $1
+		ORDER BY id ASC`
+
+	rows, err := r.pool.Query(ctx, query, chatID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]domain.ChatWebhook, 0)
+	for rows.Next() {
+		webhook := domain.ChatWebhook{}
+		err := rows.Scan(
+			&webhook.ID,
+			&webhook.PublicID,
+			&webhook.ChatID,
+			&webhook.CreatorID,
+			&webhook.Name,
+			&webhook.SecretHash,
+			&webhook.CreatedAt,
+			&webhook.LastUsedAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *PgChatRepo) DeleteWebhook(ctx context.Context, chatID, webhookID int) error {
+	const op = "pgchat.DeleteWebhook"
+
+	query := `DELETE FROM chat_webhooks WHERE chat_id = $1 AND id = $2`
+
+	result, err := r.pool.Exec(ctx, query, chatID, webhookID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) TouchWebhook(ctx context.Context, webhookID int, usedAt time.Time) error {
+	const op = "pgchat.TouchWebhook"
+
+	query := `UPDATE chat_webhooks SET last_used_at = $2 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, webhookID, usedAt); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) CreateInviteLink(ctx context.Context, link *domain.ChatInviteLink) error {
+	const op = "pgchat.CreateInviteLink"
+
+	query := `
+		INSERT INTO chat_invite_links (chat_id, creator_id, code, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		link.ChatID,
+		link.CreatorID,
+		link.Code,
+		link.CreatedAt,
+		link.ExpiresAt,
+	).Scan(&link.ID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) GetInviteLinkByCode(ctx context.Context, code string) (*domain.ChatInviteLink, error) {
+	const op = "pgchat.GetInviteLinkByCode"
+
+	query := `
+		SELECT id, chat_id, creator_id, code, created_at, expires_at
+		FROM chat_invite_links
+		WHERE code = $1`
+
+	link := &domain.ChatInviteLink{}
+	err := r.pool.QueryRow(ctx, query, code).Scan(
+		&link.ID,
+		&link.ChatID,
+		&link.CreatorID,
+		&link.Code,
+		&link.CreatedAt,
+		&link.ExpiresAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return link, nil
+}
+
+func (r *PgChatRepo) GetInviteLinks(ctx context.Context, chatID int, now time.Time) ([]domain.ChatInviteLink, error) {
+	const op = "pgchat.GetInviteLinks"
+
+	query := `
+		SELECT id, chat_id, creator_id, code, created_at, expires_at
+		FROM chat_invite_links
+		WHERE chat_id = $1 AND expires_at > $2
+		ORDER BY id ASC`
+
+	rows, err := r.pool.Query(ctx, query, chatID, now)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	links := make([]domain.ChatInviteLink, 0)
+	for rows.Next() {
+		link := domain.ChatInviteLink{}
+		err := rows.Scan(
+			&link.ID,
+			&link.ChatID,
+			&link.CreatorID,
+			&link.Code,
+			&link.CreatedAt,
+			&link.ExpiresAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return links, nil
+}
+
+func (r *PgChatRepo) DeleteInviteLink(ctx context.Context, chatID, linkID int) error {
+	const op = "pgchat.DeleteInviteLink"
+
+	query := `DELETE FROM chat_invite_links WHERE chat_id = $1 AND id = $2`
+
+	result, err := r.pool.Exec(ctx, query, chatID, linkID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) CreateJoinRequest(ctx context.Context, request *domain.ChatJoinRequest) error {
+	const op = "pgchat.CreateJoinRequest"
+
+	query := `
+		INSERT INTO chat_join_requests (chat_id, user_id, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+
+	err := r.pool.QueryRow(ctx, query, request.ChatID, request.UserID, request.CreatedAt).Scan(&request.ID)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgChatRepo) GetJoinRequests(ctx context.Context, chatID int) ([]domain.ChatJoinRequest, error) {
+	const op = "pgchat.GetJoinRequests"
+
+	query := `
+		SELECT id, chat_id, user_id, created_at
+		FROM chat_join_requests
+		WHERE chat_id = $1
+		ORDER BY id ASC`
+
+	rows, err := r.pool.Query(ctx, query, chatID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	requests := make([]domain.ChatJoinRequest, 0)
+	for rows.Next() {
+		request := domain.ChatJoinRequest{}
+		if err := rows.Scan(&request.ID, &request.ChatID, &request.UserID, &request.CreatedAt); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return requests, nil
+}
+
+func (r *PgChatRepo) ApproveJoinRequest(
+	ctx context.Context,
+	chatID, requestID int,
+	joinedAt time.Time,
+) (*domain.ChatJoinRequest, bool, error) {
+	const op = "pgchat.ApproveJoinRequest"
+
+	// Consume the request and join the chat in one statement, so a request
+	// is never approved twice.
+	query := `
+		WITH removed AS (
+			DELETE FROM chat_join_requests
+			WHERE chat_id = $1 AND id = $2
+			RETURNING id, chat_id, user_id, created_at
+		), joined AS (
+			INSERT INTO chat_participants (chat_id, user_id, joined_at)
+			SELECT chat_id, user_id, $3 FROM removed
+			ON CONFLICT (chat_id, user_id) DO NOTHING
+			RETURNING chat_id, user_id
+		), counted AS (
+			INSERT INTO chat_unread_counters (chat_id, user_id, count)
+			SELECT j.chat_id, j.user_id, COUNT(*)
+			FROM joined j
+			INNER JOIN messages m ON m.chat_id = j.chat_id AND m.sender_id != j.user_id
+			GROUP BY j.chat_id, j.user_id
+		), bumped AS (
+			UPDATE chats c
+			SET participant_count = c.participant_count + 1
+			FROM joined j
+			WHERE c.id = j.chat_id
+		)
+		SELECT id, chat_id, user_id, created_at, EXISTS (SELECT 1 FROM joined)
+		FROM removed`
+
+	request := &domain.ChatJoinRequest{}
+	var joined bool
+	err := r.pool.QueryRow(ctx, query, chatID, requestID, joinedAt).Scan(
+		&request.ID,
+		&request.ChatID,
+		&request.UserID,
+		&request.CreatedAt,
+		&joined,
+	)
+	if err != nil {
+		return nil, false, pg.WrapRepoError(op, err)
+	}
+
+	return request, joined, nil
+}
+
+func (r *PgChatRepo) DeleteJoinRequest(ctx context.Context, chatID, requestID int) (*domain.ChatJoinRequest, error) {
+	const op = "pgchat.DeleteJoinRequest"
+
+	query := `
+		DELETE FROM chat_join_requests
+		WHERE chat_id = $1 AND id = $2
+		RETURNING id, chat_id, user_id, created_at`
+
+	request := &domain.ChatJoinRequest{}
+	err := r.pool.QueryRow(ctx, query, chatID, requestID).Scan(
+		&request.ID,
+		&request.ChatID,
+		&request.UserID,
+		&request.CreatedAt,
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return request, nil
+}
@@ -0,0 +1,148 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgMentionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgMentionRepo(pool *pgxpool.Pool) *PgMentionRepo {
+	return &PgMentionRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgMentionRepo) Create(ctx context.Context, mentions []domain.Mention) error {
+	const op = "pgmention.Create"
+
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	messageIDs := make([]int, len(mentions))
+	chatIDs := make([]int, len(mentions))
+	userIDs := make([]int, len(mentions))
+	createdAts := make([]time.Time, len(mentions))
+	for i, m := range mentions {
+		messageIDs[i] = m.MessageID
+		chatIDs[i] = m.ChatID
+		userIDs[i] = m.UserID
+		createdAts[i] = m.CreatedAt
+	}
+
+	query := `
+		INSERT INTO message_mentions (message_id, chat_id, user_id, created_at)
+		SELECT message_id, chat_id, user_id, created_at
+		FROM unnest($1::bigint[], $2::int[], $3::int[], $4::timestamptz[]) AS m(message_id, chat_id, user_id, created_at)
+		ON CONFLICT (message_id, user_id) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, messageIDs, chatIDs, userIDs, createdAts); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgMentionRepo) DeleteByMessage(ctx context.Context, messageID int) error {
+	const op = "pgmention.DeleteByMessage"
+
+	query := `DELETE FROM message_mentions WHERE message_id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, messageID); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgMentionRepo) ListUserIDs(ctx context.Context, messageID int) ([]int, error) {
+	const op = "pgmention.ListUserIDs"
+
+	query := `SELECT user_id FROM message_mentions WHERE message_id = $1`
+
+	rows, err := r.pool.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	defer rows.Close()
+
+	userIDs := make([]int, 0)
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return userIDs, nil
+}
+
+func (r *PgMentionRepo) Delete(ctx context.Context, messageID int, userIDs []int) error {
+	const op = "pgmention.Delete"
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM message_mentions WHERE message_id = $1 AND user_id = ANY($2)`
+
+	if _, err := r.pool.Exec(ctx, query, messageID, userIDs); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgMentionRepo) CountUnreadByChat(ctx context.Context, chatID, userID int) (int, error) {
+	const op = "pgmention.CountUnreadByChat"
+
+	query := `
+		SELECT COUNT(*)
+		FROM message_mentions mm
+		INNER JOIN chat_participants cp ON cp.chat_id = mm.chat_id AND cp.user_id = mm.user_id
+		WHERE mm.chat_id = $1
+		AND mm.user_id = $2
+		AND mm.message_id > COALESCE(cp.last_read_message_id, 0)`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, chatID, userID).Scan(&count); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
+
+func (r *PgMentionRepo) CountUnread(ctx context.Context, workspaceID, userID int) (int, error) {
+	const op = "pgmention.CountUnread"
+
+	query := `
+		SELECT COUNT(*)
+		FROM message_mentions mm
+		INNER JOIN chat_participants cp ON cp.chat_id = mm.chat_id AND cp.user_id = mm.user_id
+		INNER JOIN chats c ON c.id = mm.chat_id
+		WHERE mm.user_id = $1
+		AND c.workspace_id = $2
+		AND mm.message_id > COALESCE(cp.last_read_message_id, 0)
+		AND (cp.muted_until IS NULL OR cp.muted_until <= NOW())`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, userID, workspaceID).Scan(&count); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
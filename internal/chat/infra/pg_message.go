@@ -2,42 +2,71 @@ package infra
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"chatx-01-backend/internal/chat/domain"
 	"chatx-01-backend/pkg/errs"
 	"chatx-01-backend/pkg/pg"
+	"chatx-01-backend/pkg/snowflake"
 )
 
 type PgMessageRepo struct {
 	pool *pgxpool.Pool
+	ids  *snowflake.Generator
 }
 
-func NewPgMessageRepo(pool *pgxpool.Pool) *PgMessageRepo {
+// NewPgMessageRepo creates a message repository. Message IDs are taken from
+// ids rather than a database sequence.
+func NewPgMessageRepo(pool *pgxpool.Pool, ids *snowflake.Generator) *PgMessageRepo {
 	return &PgMessageRepo{
 		pool: pool,
+		ids:  ids,
 	}
 }
 
 func (r *PgMessageRepo) Create(ctx context.Context, message *domain.Message) error {
 	const op = "pgmessage.Create"
 
+	// Bump the chat's last activity and the other participants' unread
+	// counters in the same statement so neither lags behind the messages table.
 	query := `
-		INSERT INTO messages (chat_id, sender_id, content, sent_at, edited_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
+		WITH inserted AS (
+			INSERT INTO messages (id, chat_id, sender_id, content, entities, attachments, metadata, sent_at, edited_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, public_id, chat_id, sender_id, sent_at
+		), touched AS (
+			UPDATE chats c
+			SET last_message_at = i.sent_at, last_message_id = i.id
+			FROM inserted i
+			WHERE c.id = i.chat_id
+			AND (c.last_message_id IS NULL OR c.last_message_id < i.id)
+		), counted AS (
+			INSERT INTO chat_unread_counters (chat_id, user_id, count)
+			SELECT cp.chat_id, cp.user_id, 1
+			FROM inserted i
+			INNER JOIN chat_participants cp ON cp.chat_id = i.chat_id AND cp.user_id != i.sender_id
+			ON CONFLICT (chat_id, user_id) DO UPDATE SET count = chat_unread_counters.count + 1
+		)
+		SELECT id, public_id FROM inserted`
 
 	err := r.pool.QueryRow(
 		ctx,
 		query,
+		int(r.ids.Next()),
 		message.ChatID,
 		message.SenderID,
 		message.Content,
+		messageEntities(message.Entities),
+		messageAttachments(message.Attachments),
+		metadataValue(message.Metadata),
 		message.SentAt,
 		message.EditedAt,
-	).Scan(&message.ID)
+	).Scan(&message.ID, &message.PublicID)
 	if err != nil {
 		return pg.WrapRepoError(op, err)
 	}
@@ -49,16 +78,20 @@ func (r *PgMessageRepo) GetByID(ctx context.Context, id int) (*domain.Message, e
 	const op = "pgmessage.GetByID"
 
 	query := `
-		SELECT id, chat_id, sender_id, content, sent_at, edited_at
+		SELECT id, public_id, chat_id, sender_id, content, entities, attachments, metadata, sent_at, edited_at
 		FROM messages
 		WHERE id = $1`
 
 	message := &domain.Message{}
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&message.ID,
+		&message.PublicID,
 		&message.ChatID,
 		&message.SenderID,
 		&message.Content,
+		(*messageEntities)(&message.Entities),
+		(*messageAttachments)(&message.Attachments),
+		&message.Metadata,
 		&message.SentAt,
 		&message.EditedAt,
 	)
@@ -69,18 +102,74 @@ func (r *PgMessageRepo) GetByID(ctx context.Context, id int) (*domain.Message, e
 	return message, nil
 }
 
+func (r *PgMessageRepo) GetByIDs(ctx context.Context, ids []int) ([]domain.Message, error) {
+	const op = "pgmessage.GetByIDs"
+
+	query := `
+		SELECT id, public_id, chat_id, sender_id, content, entities, attachments, metadata, sent_at, edited_at
+		FROM messages
+		WHERE id = ANY($1)`
+
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	messages := make([]domain.Message, 0, len(ids))
+	for rows.Next() {
+		message := domain.Message{}
+		err := rows.Scan(
+			&message.ID,
+			&message.PublicID,
+			&message.ChatID,
+			&message.SenderID,
+			&message.Content,
+			(*messageEntities)(&message.Entities),
+			(*messageAttachments)(&message.Attachments),
+			&message.Metadata,
+			&message.SentAt,
+			&message.EditedAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return messages, nil
+}
+
+func (r *PgMessageRepo) GetIDByPublicID(ctx context.Context, publicID string) (int, error) {
+	const op = "pgmessage.GetIDByPublicID"
+
+	query := `SELECT id FROM messages WHERE public_id = $1`
+
+	var id int
+	if err := r.pool.QueryRow(ctx, query, publicID).Scan(&id); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return id, nil
+}
+
 func (r *PgMessageRepo) Update(ctx context.Context, message *domain.Message) error {
 	const op = "pgmessage.Update"
 
 	query := `
 		UPDATE messages
-		SET content = $1, edited_at = $2
-		WHERE id = $3`
+		SET content = $1, entities = $2, edited_at = $3
+		WHERE id = $4`
 
 	result, err := r.pool.Exec(
 		ctx,
 		query,
 		message.Content,
+		messageEntities(message.Entities),
 		message.EditedAt,
 		message.ID,
 	)
@@ -96,17 +185,71 @@ func (r *PgMessageRepo) Update(ctx context.Context, message *domain.Message) err
 	return nil
 }
 
-func (r *PgMessageRepo) Delete(ctx context.Context, id int) error {
-	const op = "pgmessage.Delete"
+func (r *PgMessageRepo) SetMetadataKey(ctx context.Context, id int, key string, value json.RawMessage) error {
+	const op = "pgmessage.SetMetadataKey"
 
-	query := `DELETE FROM messages WHERE id = $1`
+	query := `
+		UPDATE messages
+		SET metadata = metadata || jsonb_build_object($2::text, $3::jsonb)
+		WHERE id = $1`
+	args := []any{id, key, string(value)}
+	if value == nil {
+		query = `
+			UPDATE messages
+			SET metadata = metadata - $2::text
+			WHERE id = $1`
+		args = args[:2]
+	}
 
-	result, err := r.pool.Exec(ctx, query, id)
+	result, err := r.pool.Exec(ctx, query, args...)
 	if err != nil {
 		return pg.WrapRepoError(op, err)
 	}
 
-	rowsAffected := result.RowsAffected()
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errors.New("no rows affected"))
+	}
+
+	return nil
+}
+
+func (r *PgMessageRepo) Delete(ctx context.Context, id int) error {
+	const op = "pgmessage.Delete"
+
+	// Participants who had not read the message yet have one unread less.
+	// Deleting a chat's last message moves its last activity back to the
+	// message before, which the statement still sees.
+	query := `
+		WITH deleted AS (
+			DELETE FROM messages WHERE id = $1
+			RETURNING id, chat_id, sender_id
+		), touched AS (
+			UPDATE chats c
+			SET last_message_id = prev.id, last_message_at = prev.sent_at
+			FROM deleted d
+			LEFT JOIN LATERAL (
+				SELECT m.id, m.sent_at
+				FROM messages m
+				WHERE m.chat_id = d.chat_id AND m.id < d.id
+				ORDER BY m.id DESC
+				LIMIT 1
+			) prev ON true
+			WHERE c.id = d.chat_id AND c.last_message_id = d.id
+		), counted AS (
+			UPDATE chat_unread_counters u
+			SET count = GREATEST(u.count - 1, 0)
+			FROM deleted d, chat_participants cp
+			WHERE u.chat_id = d.chat_id
+			AND cp.chat_id = u.chat_id AND cp.user_id = u.user_id
+			AND u.user_id != d.sender_id
+			AND (cp.last_read_message_id IS NULL OR d.id > cp.last_read_message_id)
+		)
+		SELECT COUNT(*) FROM deleted`
+
+	var rowsAffected int
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&rowsAffected); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
 	if rowsAffected == 0 {
 		return errs.Wrap(op, errors.New("no rows affected"))
 	}
@@ -116,26 +259,46 @@ func (r *PgMessageRepo) Delete(ctx context.Context, id int) error {
 
 func (r *PgMessageRepo) ListWithCount(
 	ctx context.Context,
-	chatID int,
-	offset, limit int,
+	params domain.MessageListParams,
 ) ([]domain.Message, int, error) {
 	const op = "pgmessage.List"
 
 	var totalCount int
-	countQuery := `SELECT COUNT(*) FROM messages WHERE chat_id = $1`
-	err := r.pool.QueryRow(ctx, countQuery, chatID).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, pg.WrapRepoError(op, err)
+	if !params.SkipTotal {
+		countQuery := `SELECT COUNT(*) FROM messages WHERE chat_id = $1`
+		err := r.pool.QueryRow(ctx, countQuery, params.ChatID).Scan(&totalCount)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
 	}
 
 	query := `
-		SELECT id, chat_id, sender_id, content, sent_at, edited_at
+		SELECT id, public_id, chat_id, sender_id, content, entities, attachments, metadata, sent_at, edited_at
 		FROM messages
-		WHERE chat_id = $1
-		ORDER BY sent_at ASC
-		LIMIT $2 OFFSET $3`
+		WHERE chat_id = $1`
+	args := []any{params.ChatID}
+
+	if params.BeforeID > 0 {
+		args = append(args, params.BeforeID)
+		query += fmt.Sprintf(" AND id < $%d", len(args))
+	}
+	if params.AfterID > 0 {
+		args = append(args, params.AfterID)
+		query += fmt.Sprintf(" AND id > $%d", len(args))
+	}
 
-	rows, err := r.pool.Query(ctx, query, chatID, limit, offset)
+	// IDs grow with send time, so ordering by ID matches chronological order
+	// and lets the before/after bounds use the (chat_id, id) index.
+	if params.Order == domain.SortAsc {
+		query += " ORDER BY id ASC"
+	} else {
+		query += " ORDER BY id DESC"
+	}
+
+	args = append(args, params.Limit, params.Offset)
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, pg.WrapRepoError(op, err)
 	}
@@ -146,9 +309,13 @@ func (r *PgMessageRepo) ListWithCount(
 		message := domain.Message{}
 		err := rows.Scan(
 			&message.ID,
+			&message.PublicID,
 			&message.ChatID,
 			&message.SenderID,
 			&message.Content,
+			(*messageEntities)(&message.Entities),
+			(*messageAttachments)(&message.Attachments),
+			&message.Metadata,
 			&message.SentAt,
 			&message.EditedAt,
 		)
@@ -165,22 +332,165 @@ func (r *PgMessageRepo) ListWithCount(
 	return messages, totalCount, nil
 }
 
+// countInBounds counts the messages of a chat within the ID bounds of params.
+func (r *PgMessageRepo) countInBounds(ctx context.Context, params domain.MessageListParams) (int, error) {
+	const op = "pgmessage.countInBounds"
+
+	query := `SELECT COUNT(*) FROM messages WHERE chat_id = $1`
+	args := []any{params.ChatID}
+
+	if params.BeforeID > 0 {
+		args = append(args, params.BeforeID)
+		query += fmt.Sprintf(" AND id < $%d", len(args))
+	}
+	if params.AfterID > 0 {
+		args = append(args, params.AfterID)
+		query += fmt.Sprintf(" AND id > $%d", len(args))
+	}
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
+
+// Markers ts_headline puts around matched words. Control characters can't
+// be confused with the message text around them.
+const (
+	headlineStart = '\x02'
+	headlineStop  = '\x03'
+)
+
+// headlineOptions excerpts up to two fragments of 10 to 30 words around the
+// matched words of longer messages.
+var headlineOptions = fmt.Sprintf(
+	`StartSel=%c, StopSel=%c, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=" … "`,
+	headlineStart,
+	headlineStop,
+)
+
+func (r *PgMessageRepo) Search(
+	ctx context.Context,
+	params domain.MessageSearchParams,
+) ([]domain.MessageSearchResult, int, error) {
+	const op = "pgmessage.Search"
+
+	from := `
+		FROM messages m
+		INNER JOIN chat_participants cp ON cp.chat_id = m.chat_id AND cp.user_id = $2
+		INNER JOIN chats c ON c.id = m.chat_id AND (c.workspace_id = $3 OR c.workspace_id IS NULL)
+		WHERE m.search_vector @@ websearch_to_tsquery('simple', $1)`
+	args := []any{params.Query, params.UserID, params.WorkspaceID}
+
+	if params.ChatID > 0 {
+		args = append(args, params.ChatID)
+		from += fmt.Sprintf(" AND m.chat_id = $%d", len(args))
+	}
+
+	var totalCount int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*)"+from, args...).Scan(&totalCount); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	args = append(args, headlineOptions, params.Limit, params.Offset)
+	query := fmt.Sprintf(`
+		SELECT m.id, m.public_id, m.chat_id, m.sender_id, m.content, m.entities, m.attachments, m.metadata, m.sent_at, m.edited_at,
+			ts_headline('simple', m.content, websearch_to_tsquery('simple', $1), $%d)`+from+`
+		ORDER BY m.id DESC
+		LIMIT $%d OFFSET $%d`, len(args)-2, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	results := make([]domain.MessageSearchResult, 0)
+	for rows.Next() {
+		var (
+			message domain.Message
+			marked  string
+		)
+		err := rows.Scan(
+			&message.ID,
+			&message.PublicID,
+			&message.ChatID,
+			&message.SenderID,
+			&message.Content,
+			(*messageEntities)(&message.Entities),
+			(*messageAttachments)(&message.Attachments),
+			&message.Metadata,
+			&message.SentAt,
+			&message.EditedAt,
+			&marked,
+		)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
+
+		snippet, highlights := parseHeadline(marked)
+		results = append(results, domain.MessageSearchResult{
+			Message:    message,
+			Snippet:    snippet,
+			Highlights: highlights,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	return results, totalCount, nil
+}
+
+// parseHeadline strips the markers from a ts_headline result and returns
+// the ranges they enclosed.
+func parseHeadline(marked string) (string, []domain.TextRange) {
+	var b strings.Builder
+	highlights := make([]domain.TextRange, 0)
+
+	offset, start := 0, -1
+	for _, r := range marked {
+		switch r {
+		case headlineStart:
+			start = offset
+		case headlineStop:
+			if start >= 0 && offset > start {
+				highlights = append(highlights, domain.TextRange{Offset: start, Length: offset - start})
+			}
+			start = -1
+		default:
+			b.WriteRune(r)
+			offset++
+		}
+	}
+
+	return b.String(), highlights
+}
+
 func (r *PgMessageRepo) GetLastMessage(ctx context.Context, chatID int) (*domain.Message, error) {
 	const op = "pgmessage.GetLastMessage"
 
+	// Ordering by ID reads the newest partition's (chat_id, id) index first.
 	query := `
-		SELECT id, chat_id, sender_id, content, sent_at, edited_at
+		SELECT id, public_id, chat_id, sender_id, content, entities, attachments, metadata, sent_at, edited_at
 		FROM messages
 		WHERE chat_id = $1
-		ORDER BY sent_at DESC
+		ORDER BY id DESC
 		LIMIT 1`
 
 	message := &domain.Message{}
 	err := r.pool.QueryRow(ctx, query, chatID).Scan(
 		&message.ID,
+		&message.PublicID,
 		&message.ChatID,
 		&message.SenderID,
 		&message.Content,
+		(*messageEntities)(&message.Entities),
+		(*messageAttachments)(&message.Attachments),
+		&message.Metadata,
 		&message.SentAt,
 		&message.EditedAt,
 	)
@@ -195,15 +505,55 @@ func (r *PgMessageRepo) GetUnreadCountByChat(ctx context.Context, chatID, userID
 	const op = "pgmessage.GetUnreadCountByChat"
 
 	query := `
-		SELECT COUNT(*)
+		SELECT COALESCE((
+			SELECT count FROM chat_unread_counters WHERE chat_id = $1 AND user_id = $2
+		), 0)`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, chatID, userID).Scan(&count)
+	if err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
+
+func (r *PgMessageRepo) GetFirstUnreadMessageID(ctx context.Context, chatID, userID int) (*int, error) {
+	const op = "pgmessage.GetFirstUnreadMessageID"
+
+	query := `
+		SELECT MIN(m.id)
 		FROM messages m
 		LEFT JOIN chat_participants cp ON m.chat_id = cp.chat_id AND cp.user_id = $2
 		WHERE m.chat_id = $1
 		AND m.sender_id != $2
 		AND (cp.last_read_message_id IS NULL OR m.id > cp.last_read_message_id)`
 
+	var messageID *int
+	err := r.pool.QueryRow(ctx, query, chatID, userID).Scan(&messageID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return messageID, nil
+}
+
+func (r *PgMessageRepo) GetTotalUnreadCount(ctx context.Context, workspaceID, userID int) (int, error) {
+	const op = "pgmessage.GetTotalUnreadCount"
+
+	query := `
+		SELECT COALESCE(SUM(u.count), 0)
+		FROM chat_unread_counters u
+		INNER JOIN chats c ON c.id = u.chat_id
+		INNER JOIN chat_participants cp ON cp.chat_id = u.chat_id AND cp.user_id = u.user_id
+		WHERE u.user_id = $1
+		AND u.count > 0
+		AND c.type != $2
+		AND c.workspace_id = $3
+		AND (cp.muted_until IS NULL OR cp.muted_until <= NOW())`
+
 	var count int
-	err := r.pool.QueryRow(ctx, query, chatID, userID).Scan(&count)
+	err := r.pool.QueryRow(ctx, query, userID, domain.ChatTypeSaved, workspaceID).Scan(&count)
 	if err != nil {
 		return 0, pg.WrapRepoError(op, err)
 	}
@@ -211,21 +561,207 @@ func (r *PgMessageRepo) GetUnreadCountByChat(ctx context.Context, chatID, userID
 	return count, nil
 }
 
-func (r *PgMessageRepo) GetTotalUnreadCount(ctx context.Context, userID int) (int, error) {
-	const op = "pgmessage.GetTotalUnreadCount"
+func (r *PgMessageRepo) GetUnreadChatsCount(ctx context.Context, workspaceID, userID int) (int, error) {
+	const op = "pgmessage.GetUnreadChatsCount"
 
 	query := `
 		SELECT COUNT(*)
-		FROM messages m
-		INNER JOIN chat_participants cp ON m.chat_id = cp.chat_id AND cp.user_id = $1
-		WHERE m.sender_id != $1
-		AND (cp.last_read_message_id IS NULL OR m.id > cp.last_read_message_id)`
+		FROM chat_unread_counters u
+		INNER JOIN chats c ON c.id = u.chat_id
+		INNER JOIN chat_participants cp ON cp.chat_id = u.chat_id AND cp.user_id = u.user_id
+		WHERE u.user_id = $1
+		AND u.count > 0
+		AND c.type != $2
+		AND c.workspace_id = $3
+		AND (cp.muted_until IS NULL OR cp.muted_until <= NOW())`
 
 	var count int
-	err := r.pool.QueryRow(ctx, query, userID).Scan(&count)
+	err := r.pool.QueryRow(ctx, query, userID, domain.ChatTypeSaved, workspaceID).Scan(&count)
 	if err != nil {
 		return 0, pg.WrapRepoError(op, err)
 	}
 
 	return count, nil
 }
+
+// RepairUnreadCounters recounts the unread messages of every participant,
+// batchSize chats at a time, and corrects the counters that drifted, such
+// as after retention or archival removed unread messages. It returns how
+// many counters were corrected.
+func (r *PgMessageRepo) RepairUnreadCounters(ctx context.Context, batchSize int) (int, error) {
+	const op = "pgmessage.RepairUnreadCounters"
+
+	query := `
+		WITH batch AS (
+			SELECT id FROM chats WHERE id > $1 ORDER BY id LIMIT $2
+		), actual AS (
+			SELECT cp.chat_id, cp.user_id, COUNT(m.id) AS count
+			FROM chat_participants cp
+			INNER JOIN batch b ON b.id = cp.chat_id
+			LEFT JOIN messages m ON m.chat_id = cp.chat_id
+				AND m.sender_id != cp.user_id
+				AND (cp.last_read_message_id IS NULL OR m.id > cp.last_read_message_id)
+			GROUP BY cp.chat_id, cp.user_id
+		), repaired AS (
+			INSERT INTO chat_unread_counters (chat_id, user_id, count)
+			SELECT a.chat_id, a.user_id, a.count
+			FROM actual a
+			LEFT JOIN chat_unread_counters u ON u.chat_id = a.chat_id AND u.user_id = a.user_id
+			WHERE a.count != COALESCE(u.count, 0)
+			ON CONFLICT (chat_id, user_id) DO UPDATE SET count = EXCLUDED.count
+			RETURNING 1
+		)
+		SELECT (SELECT MAX(id) FROM batch), (SELECT COUNT(*) FROM repaired)`
+
+	repaired := 0
+	lastChatID := 0
+	for {
+		var maxID *int
+		var n int
+		if err := r.pool.QueryRow(ctx, query, lastChatID, batchSize).Scan(&maxID, &n); err != nil {
+			return repaired, pg.WrapRepoError(op, err)
+		}
+		repaired += n
+		if maxID == nil {
+			return repaired, nil
+		}
+		lastChatID = *maxID
+	}
+}
+
+// expiredMessagesFilter matches messages past their chat's retention period,
+// with $1 the default period in days and $2 the current time.
+const expiredMessagesFilter = `
+		FROM messages m
+		INNER JOIN chats c ON c.id = m.chat_id
+		WHERE COALESCE(c.retention_days, $1) > 0
+		AND m.sent_at < $2::timestamptz - make_interval(days => COALESCE(c.retention_days, $1))`
+
+// notAnonymizedFilter excludes messages anonymized earlier, with $3 the
+// retention system event.
+const notAnonymizedFilter = `
+		AND m.metadata->'system'->>'event' IS DISTINCT FROM $3`
+
+func (r *PgMessageRepo) CountExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	mode domain.RetentionMode,
+) (int, error) {
+	const op = "pgmessage.CountExpired"
+
+	query := `SELECT COUNT(*)` + expiredMessagesFilter
+	args := []any{policy.DefaultDays, policy.Now}
+	if mode == domain.RetentionModeAnonymize {
+		query += notAnonymizedFilter
+		args = append(args, domain.SystemEventRetentionExpired)
+	}
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
+
+func (r *PgMessageRepo) DeleteExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	limit int,
+) ([]domain.ExpiredMessages, error) {
+	const op = "pgmessage.DeleteExpired"
+
+	// Reactions and mentions reference messages without a foreign key, as
+	// messages are partitioned, so they are deleted along with them.
+	query := `
+		WITH deleted AS (
+			DELETE FROM messages
+			WHERE id IN (
+				SELECT m.id` + expiredMessagesFilter + `
+				LIMIT $3
+			)
+			RETURNING id, chat_id
+		), unreacted AS (
+			DELETE FROM message_reactions mr
+			USING deleted d
+			WHERE mr.message_id = d.id
+		), unmentioned AS (
+			DELETE FROM message_mentions mm
+			USING deleted d
+			WHERE mm.message_id = d.id
+		)
+		SELECT chat_id, MAX(id), COUNT(*)
+		FROM deleted
+		GROUP BY chat_id`
+
+	expired, err := r.queryExpired(ctx, query, policy.DefaultDays, policy.Now, limit)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return expired, nil
+}
+
+func (r *PgMessageRepo) AnonymizeExpired(
+	ctx context.Context,
+	policy domain.RetentionPolicy,
+	limit int,
+) ([]domain.ExpiredMessages, error) {
+	const op = "pgmessage.AnonymizeExpired"
+
+	query := `
+		WITH anonymized AS (
+			UPDATE messages
+			SET content = '', entities = '[]', attachments = '[]', metadata = $5
+			WHERE id IN (
+				SELECT m.id` + expiredMessagesFilter + notAnonymizedFilter + `
+				LIMIT $4
+			)
+			RETURNING id, chat_id
+		)
+		SELECT chat_id, MAX(id), COUNT(*)
+		FROM anonymized
+		GROUP BY chat_id`
+
+	system, err := json.Marshal(domain.SystemMetadata{Event: domain.SystemEventRetentionExpired})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	metadata := domain.Metadata{domain.MetadataSystem: system}
+
+	expired, err := r.queryExpired(
+		ctx,
+		query,
+		policy.DefaultDays,
+		policy.Now,
+		domain.SystemEventRetentionExpired,
+		limit,
+		metadataValue(metadata),
+	)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return expired, nil
+}
+
+// queryExpired runs a retention statement that returns the chat ID, highest
+// message ID and count of the messages it handled per chat.
+func (r *PgMessageRepo) queryExpired(ctx context.Context, query string, args ...any) ([]domain.ExpiredMessages, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expired := make([]domain.ExpiredMessages, 0)
+	for rows.Next() {
+		var e domain.ExpiredMessages
+		if err := rows.Scan(&e.ChatID, &e.MaxID, &e.Count); err != nil {
+			return nil, err
+		}
+		expired = append(expired, e)
+	}
+
+	return expired, rows.Err()
+}
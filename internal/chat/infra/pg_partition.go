@@ -0,0 +1,68 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/pkg/pg"
+	"chatx-01-backend/pkg/snowflake"
+)
+
+// PgPartitionRepo manages the monthly partitions of the messages table.
+// Each partition holds the snowflake IDs generated in its month, so queries
+// by ID only touch one partition.
+type PgPartitionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgPartitionRepo(pool *pgxpool.Pool) *PgPartitionRepo {
+	return &PgPartitionRepo{
+		pool: pool,
+	}
+}
+
+// EnsureMessagePartitions creates the partitions for the month of now and
+// the ahead months after it that don't exist yet, and returns how many it
+// created. Inserting a message fails without a partition for its ID's month.
+func (r *PgPartitionRepo) EnsureMessagePartitions(ctx context.Context, now time.Time, ahead int) (int, error) {
+	const op = "pgpartition.EnsureMessagePartitions"
+
+	now = now.UTC()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	created := 0
+	for i := 0; i <= ahead; i++ {
+		month := first.AddDate(0, i, 0)
+		name := messagePartitionName(month)
+
+		var exists bool
+		err := r.pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists)
+		if err != nil {
+			return created, pg.WrapRepoError(op, err)
+		}
+		if exists {
+			continue
+		}
+
+		// DDL takes no parameters; the name and bounds are generated here.
+		query := fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF messages FOR VALUES FROM (%d) TO (%d)`,
+			name,
+			snowflake.FirstID(month),
+			snowflake.FirstID(month.AddDate(0, 1, 0)),
+		)
+		if _, err := r.pool.Exec(ctx, query); err != nil {
+			return created, pg.WrapRepoError(op, err)
+		}
+		created++
+	}
+
+	return created, nil
+}
+
+func messagePartitionName(month time.Time) string {
+	return fmt.Sprintf("messages_p%04d_%02d", month.Year(), month.Month())
+}
@@ -0,0 +1,110 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgReactionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgReactionRepo(pool *pgxpool.Pool) *PgReactionRepo {
+	return &PgReactionRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgReactionRepo) Add(ctx context.Context, reaction *domain.Reaction) error {
+	const op = "pgreaction.Add"
+
+	query := `
+		INSERT INTO message_reactions (message_id, chat_id, user_id, emoji, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.pool.Exec(
+		ctx,
+		query,
+		reaction.MessageID,
+		reaction.ChatID,
+		reaction.UserID,
+		reaction.Emoji,
+		reaction.CreatedAt,
+	)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgReactionRepo) Remove(ctx context.Context, messageID, userID int, emoji string) error {
+	const op = "pgreaction.Remove"
+
+	query := `DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3`
+
+	result, err := r.pool.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Wrap(op, errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *PgReactionRepo) ListByMessage(ctx context.Context, messageID int) ([]domain.Reaction, error) {
+	const op = "pgreaction.ListByMessage"
+
+	query := `
+		SELECT message_id, chat_id, user_id, emoji, created_at
+		FROM message_reactions
+		WHERE message_id = $1
+		ORDER BY created_at ASC, user_id ASC`
+
+	rows, err := r.pool.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	reactions := make([]domain.Reaction, 0)
+	for rows.Next() {
+		reaction := domain.Reaction{}
+		err := rows.Scan(
+			&reaction.MessageID,
+			&reaction.ChatID,
+			&reaction.UserID,
+			&reaction.Emoji,
+			&reaction.CreatedAt,
+		)
+		if err != nil {
+			return nil, pg.WrapRepoError(op, err)
+		}
+		reactions = append(reactions, reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pg.WrapRepoError(op, err)
+	}
+
+	return reactions, nil
+}
+
+func (r *PgReactionRepo) DeleteByMessage(ctx context.Context, messageID int) error {
+	const op = "pgreaction.DeleteByMessage"
+
+	query := `DELETE FROM message_reactions WHERE message_id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, messageID); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/internal/portal/chat"
+)
+
+var (
+	errGuestChatType = errors.New("guests can only join group chats")
+
+	// Interface guard.
+	_ chat.Portal = (*Portal)(nil)
+)
+
+type Portal struct {
+	chatRepo    domain.ChatRepository
+	authPortal  auth.Portal
+	broadcaster ws.Broadcaster
+}
+
+func New(
+	chatRepo domain.ChatRepository,
+	authPortal auth.Portal,
+	broadcaster ws.Broadcaster,
+) *Portal {
+	return &Portal{
+		chatRepo:    chatRepo,
+		authPortal:  authPortal,
+		broadcaster: broadcaster,
+	}
+}
+
+func (p *Portal) NotifyUserUpdated(ctx context.Context, profile chat.UserProfile) error {
+	contactIDs, err := p.chatRepo.GetContactIDs(ctx, profile.ID)
+	if err != nil {
+		return err
+	}
+
+	// The user's own connections refresh their cached profile too
+	recipients := append(contactIDs, profile.ID)
+
+	p.broadcaster.BroadcastUserUpdated(recipients, ws.UserPayload{
+		UserID:      profile.ID,
+		PublicID:    profile.PublicID,
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		ImagePath:   profile.ImagePath,
+	})
+
+	return nil
+}
+
+func (p *Portal) NotifyContactRequested(ctx context.Context, userID int, requester chat.UserProfile) error {
+	p.broadcaster.BroadcastContactRequested(userID, contactPayload(requester))
+	return nil
+}
+
+func (p *Portal) NotifyContactAccepted(ctx context.Context, userID int, contact chat.UserProfile) error {
+	p.broadcaster.BroadcastContactAccepted(userID, contactPayload(contact))
+	return nil
+}
+
+func (p *Portal) AcceptInvites(ctx context.Context, userID int, email string) ([]int, error) {
+	invites, err := p.chatRepo.AcceptInvites(ctx, email, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	chatIDs := make([]int, 0, len(invites))
+	for _, invite := range invites {
+		// The invite grants access to the chat's workspace as well
+		chat, err := p.chatRepo.GetByID(ctx, invite.ChatID)
+		if err != nil {
+			return nil, err
+		}
+		if chat.WorkspaceID != nil {
+			if err := p.authPortal.AddWorkspaceMember(ctx, *chat.WorkspaceID, userID); err != nil {
+				return nil, err
+			}
+		}
+
+		p.broadcaster.BroadcastParticipantAdded(chat.Workspace(), invite.ChatID, userID, invite.InviterID)
+		chatIDs = append(chatIDs, invite.ChatID)
+	}
+
+	return chatIDs, nil
+}
+
+func (p *Portal) AddGuest(ctx context.Context, chatID, userID int) (int, error) {
+	chat, err := p.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+	// Groups always belong to a workspace
+	if chat.Type != domain.ChatTypeGroup || chat.WorkspaceID == nil {
+		return 0, errGuestChatType
+	}
+
+	if err := p.authPortal.AddWorkspaceMember(ctx, *chat.WorkspaceID, userID); err != nil {
+		return 0, err
+	}
+
+	err = p.chatRepo.AddParticipant(ctx, &domain.ChatParticipant{
+		ChatID:   chatID,
+		UserID:   userID,
+		JoinedAt: time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Guests join by themselves through the link
+	p.broadcaster.BroadcastParticipantAdded(chat.Workspace(), chatID, userID, userID)
+	return *chat.WorkspaceID, nil
+}
+
+// contactPayload converts a profile to the payload of contact events.
+func contactPayload(profile chat.UserProfile) ws.UserPayload {
+	return ws.UserPayload{
+		UserID:      profile.ID,
+		PublicID:    profile.PublicID,
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		ImagePath:   profile.ImagePath,
+	}
+}
@@ -0,0 +1,83 @@
+package chatuc
+
+import (
+	"context"
+
+	"chatx-01-backend/pkg/errs"
+)
+
+// ArchiveChat moves a chat to the caller's archived chat lists.
+func (uc *useCase) ArchiveChat(ctx context.Context, req ArchiveChatReq) error {
+	const op = "chatuc.ArchiveChat"
+
+	err := uc.setListState(ctx, req.ChatID, func(chatID, userID int) error {
+		return uc.chatRepo.SetArchived(ctx, chatID, userID, true)
+	})
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// UnarchiveChat moves a chat back to the caller's active chat lists.
+func (uc *useCase) UnarchiveChat(ctx context.Context, req ArchiveChatReq) error {
+	const op = "chatuc.UnarchiveChat"
+
+	err := uc.setListState(ctx, req.ChatID, func(chatID, userID int) error {
+		return uc.chatRepo.SetArchived(ctx, chatID, userID, false)
+	})
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// PinChat lists a chat before the caller's other chats.
+func (uc *useCase) PinChat(ctx context.Context, req PinChatReq) error {
+	const op = "chatuc.PinChat"
+
+	err := uc.setListState(ctx, req.ChatID, func(chatID, userID int) error {
+		return uc.chatRepo.SetPinned(ctx, chatID, userID, true)
+	})
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// UnpinChat lists a pinned chat among the caller's other chats again.
+func (uc *useCase) UnpinChat(ctx context.Context, req PinChatReq) error {
+	const op = "chatuc.UnpinChat"
+
+	err := uc.setListState(ctx, req.ChatID, func(chatID, userID int) error {
+		return uc.chatRepo.SetPinned(ctx, chatID, userID, false)
+	})
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// setListState changes how a chat is listed for the caller with set after
+// checking they participate in it.
+func (uc *useCase) setListState(ctx context.Context, chatID int, set func(chatID, userID int) error) error {
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, chatID, authUser.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := set(chat.ID, authUser.ID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	return nil
+}
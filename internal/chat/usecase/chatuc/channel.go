@@ -0,0 +1,192 @@
+package chatuc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// CreateChannel creates a broadcast-only channel in the caller's workspace
+// with the caller as its first subscriber.
+func (uc *useCase) CreateChannel(ctx context.Context, req CreateChannelReq) (*CreateChannelResp, error) {
+	const op = "chatuc.CreateChannel"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	now := time.Now()
+	chat := &domain.Chat{
+		Type:        domain.ChatTypeChannel,
+		Name:        strings.TrimSpace(req.Name),
+		CreatorID:   authUser.ID,
+		WorkspaceID: &authUser.WorkspaceID,
+		CreatedAt:   now,
+	}
+
+	if err := uc.chatRepo.Create(ctx, chat); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	if err := uc.chatRepo.AddParticipant(ctx, &domain.ChatParticipant{
+		ChatID:   chat.ID,
+		UserID:   authUser.ID,
+		JoinedAt: now,
+	}); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	uc.broadcaster.BroadcastChatCreated(chatPayload(chat, []int{authUser.ID}))
+	uc.trackChatCreated(ctx, authUser, chat, 1)
+
+	return &CreateChannelResp{
+		ChatID:   chat.ID,
+		PublicID: chat.PublicID,
+	}, nil
+}
+
+// GetChannelsList lists the channels of the caller's workspace, or only the
+// ones they subscribe to.
+func (uc *useCase) GetChannelsList(ctx context.Context, req GetChannelsListReq) (*GetChannelsListResp, error) {
+	const op = "chatuc.GetChannelsList"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	entries, total, err := uc.chatRepo.GetChannelsList(
+		ctx,
+		authUser.WorkspaceID,
+		authUser.ID,
+		req.Filter == "subscribed",
+		req.Page*req.Limit,
+		req.Limit+1,
+	)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	hasMore := len(entries) > req.Limit
+	if hasMore {
+		entries = entries[:req.Limit]
+	}
+
+	items := make([]ChannelListItem, 0, len(entries))
+	for _, entry := range entries {
+		var lastMessageSentAt *string
+		if entry.Chat.LastMessageAt != nil {
+			sentAt := entry.Chat.LastMessageAt.Format(time.RFC3339)
+			lastMessageSentAt = &sentAt
+		}
+
+		items = append(items, ChannelListItem{
+			ChatID:            entry.Chat.ID,
+			PublicID:          entry.Chat.PublicID,
+			Name:              entry.Chat.Name,
+			CreatorID:         entry.Chat.CreatorID,
+			SubscriberCount:   entry.Chat.ParticipantCount,
+			Subscribed:        entry.Subscribed,
+			LastMessageSentAt: lastMessageSentAt,
+			UnreadCount:       entry.UnreadCount,
+		})
+	}
+
+	return &GetChannelsListResp{
+		Channels: items,
+		Total:    total,
+		HasMore:  hasMore,
+		Page:     req.Page,
+		Limit:    req.Limit,
+	}, nil
+}
+
+// SubscribeChannel subscribes the caller to a channel of their workspace.
+// Messages posted before subscribing don't count as unread. Subscribing
+// again succeeds.
+func (uc *useCase) SubscribeChannel(ctx context.Context, req SubscribeChannelReq) (*SubscribeChannelResp, error) {
+	const op = "chatuc.SubscribeChannel"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.workspaceChannel(ctx, authUser.WorkspaceID, req.ChatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	now := time.Now()
+	err = uc.chatRepo.AddParticipant(ctx, &domain.ChatParticipant{
+		ChatID:            chat.ID,
+		UserID:            authUser.ID,
+		JoinedAt:          now,
+		LastReadMessageID: chat.LastMessageID,
+		LastReadAt:        &now,
+	})
+	switch {
+	case err == nil:
+		chat.ParticipantCount++
+		uc.broadcaster.BroadcastChannelSubscribed(chat.Workspace(), chat.ID, authUser.ID)
+	case !errors.Is(err, errs.ErrAlreadyExists):
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &SubscribeChannelResp{
+		ChatID:          chat.ID,
+		SubscriberCount: chat.ParticipantCount,
+	}, nil
+}
+
+// UnsubscribeChannel unsubscribes the caller from a channel. The channel
+// creator can't unsubscribe. Unsubscribing again succeeds.
+func (uc *useCase) UnsubscribeChannel(ctx context.Context, req SubscribeChannelReq) error {
+	const op = "chatuc.UnsubscribeChannel"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.workspaceChannel(ctx, authUser.WorkspaceID, req.ChatID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if chat.CreatorID == authUser.ID {
+		return errs.AddFieldError(nil, "chat_id", "the channel creator can't unsubscribe")
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chat.ID, authUser.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return nil
+	}
+
+	if err := uc.chatRepo.RemoveParticipant(ctx, chat.ID, authUser.ID); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.broadcaster.BroadcastChannelUnsubscribed(chat.ID, authUser.ID)
+
+	return nil
+}
+
+// workspaceChannel returns a channel of the workspace. Other chats can't be
+// told from missing ones.
+func (uc *useCase) workspaceChannel(ctx context.Context, workspaceID, chatID int) (*domain.Chat, error) {
+	chat, err := uc.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "channel not found"))
+	}
+	if chat.Type != domain.ChatTypeChannel || chat.WorkspaceID == nil || *chat.WorkspaceID != workspaceID {
+		return nil, errs.NewNotFoundError("chat_id", "channel not found")
+	}
+	return chat, nil
+}
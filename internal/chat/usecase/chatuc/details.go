@@ -0,0 +1,112 @@
+package chatuc
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// chatImageTypes are the content types a group avatar may have, the same
+// as for profile images.
+var chatImageTypes = []string{"image/jpeg", "image/jpg", "image/png"}
+
+// SetChatDescription sets the description of a group the caller takes part in.
+func (uc *useCase) SetChatDescription(ctx context.Context, req SetChatDescriptionReq) (*SetChatDescriptionResp, error) {
+	const op = "chatuc.SetChatDescription"
+
+	chat, err := uc.participantGroup(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if err := uc.chatRepo.SetDescription(ctx, chat.ID, description); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	chat.Description = description
+
+	uc.broadcaster.BroadcastChatUpdated(chatPayload(chat, nil))
+
+	return &SetChatDescriptionResp{
+		Description: description,
+	}, nil
+}
+
+// SetChatImage sets the avatar of a group the caller takes part in to an
+// image uploaded through the profile image upload, or clears it.
+func (uc *useCase) SetChatImage(ctx context.Context, req SetChatImageReq) (*SetChatImageResp, error) {
+	const op = "chatuc.SetChatImage"
+
+	chat, err := uc.participantGroup(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	if req.ImagePath != nil {
+		if err := uc.checkChatImage(ctx, *req.ImagePath); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+	}
+
+	if err := uc.chatRepo.SetImage(ctx, chat.ID, req.ImagePath); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	chat.ImagePath = req.ImagePath
+
+	uc.broadcaster.BroadcastChatUpdated(chatPayload(chat, nil))
+
+	return &SetChatImageResp{
+		ImagePath: chat.ImagePath,
+	}, nil
+}
+
+// participantGroup returns a group of the caller's workspace the caller
+// takes part in.
+func (uc *useCase) participantGroup(ctx context.Context, chatID int) (*domain.Chat, error) {
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, chatID, authUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "only group chats can be managed")
+	}
+
+	return chat, nil
+}
+
+// checkChatImage checks that an image exists in the file store and is a
+// JPEG or PNG. Message attachments are private to their chat, so they can't
+// become an avatar.
+func (uc *useCase) checkChatImage(ctx context.Context, imagePath string) error {
+	errNotExist := errs.NewNotFoundError("image_path", "file does not exist")
+
+	if strings.HasPrefix(imagePath, domain.AttachmentPathPrefix) {
+		return errNotExist
+	}
+
+	exists, err := uc.fileStore.Exists(ctx, imagePath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errNotExist
+	}
+
+	contentType, err := uc.fileStore.GetContentType(ctx, imagePath)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(chatImageTypes, strings.ToLower(contentType)) {
+		return errs.AddFieldError(nil, "image_path", "file must be a JPEG or PNG image")
+	}
+
+	return nil
+}
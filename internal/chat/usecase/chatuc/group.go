@@ -0,0 +1,127 @@
+package chatuc
+
+import (
+	"context"
+	"fmt"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+)
+
+// TransferOwnership hands a group to another of its participants. The group
+// is told with a system message.
+func (uc *useCase) TransferOwnership(ctx context.Context, req TransferOwnershipReq) error {
+	const op = "chatuc.TransferOwnership"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.ownedGroup(ctx, authUser, req.ChatID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if req.UserID == chat.CreatorID {
+		return nil
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chat.ID, req.UserID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return errs.NewNotFoundError("user_id", "user is not a participant of this chat")
+	}
+
+	previousID := chat.CreatorID
+	if err := uc.chatRepo.SetCreator(ctx, chat.ID, req.UserID); err != nil {
+		return errs.Wrap(op, err)
+	}
+	chat.CreatorID = req.UserID
+
+	err = uc.postSystemMessage(ctx, chat, authUser.ID, domain.SystemMetadata{
+		Event:     domain.SystemEventOwnershipTransferred,
+		UserID:    previousID,
+		CreatorID: chat.CreatorID,
+	})
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	uc.broadcaster.BroadcastChatUpdated(chatPayload(chat, nil))
+
+	uc.authPortal.RecordAudit(ctx, auth.AuditEvent{
+		Action:       auth.AuditGroupOwnershipTransfer,
+		ActorID:      authUser.ID,
+		TargetUserID: chat.CreatorID,
+		ChatID:       chat.ID,
+		WorkspaceID:  authUser.WorkspaceID,
+		Details:      fmt.Sprintf("previous owner %d", previousID),
+	})
+
+	return nil
+}
+
+// DeleteGroup deletes a group with its messages and participants. The former
+// participants are unsubscribed from it and told it is gone.
+func (uc *useCase) DeleteGroup(ctx context.Context, req DeleteGroupReq) error {
+	const op = "chatuc.DeleteGroup"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.ownedGroup(ctx, authUser, req.ChatID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	// Collect the participants first, they are gone with the chat
+	participants, err := uc.chatRepo.GetParticipants(ctx, chat.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	participantIDs := make([]int, len(participants))
+	for i, p := range participants {
+		participantIDs[i] = p.UserID
+	}
+
+	if err := uc.chatRepo.Delete(ctx, chat.ID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	uc.broadcaster.BroadcastChatDeleted(chat.ID, authUser.ID, participantIDs)
+
+	uc.authPortal.RecordAudit(ctx, auth.AuditEvent{
+		Action:      auth.AuditGroupDelete,
+		ActorID:     authUser.ID,
+		ChatID:      chat.ID,
+		WorkspaceID: authUser.WorkspaceID,
+	})
+
+	return nil
+}
+
+// ownedGroup returns a group of the user's workspace after checking the user
+// created it, administers the workspace or may manage any group.
+func (uc *useCase) ownedGroup(ctx context.Context, au auth.AuthenticatedUser, chatID int) (*domain.Chat, error) {
+	const op = "chatuc.ownedGroup"
+
+	chat, err := uc.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != au.WorkspaceID {
+		return nil, errs.NewNotFoundError("chat_id", "chat not found")
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "only group chats can be managed")
+	}
+	if chat.CreatorID != au.ID && !au.IsWorkspaceAdmin() && !au.Can(auth.PermManageAnyGroup) {
+		return nil, errs.Wrap(op, domain.ErrNotGroupOwner)
+	}
+
+	return chat, nil
+}
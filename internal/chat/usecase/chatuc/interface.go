@@ -1,8 +1,13 @@
 package chatuc
 
 import (
+	"chatx-01-backend/internal/chat/domain"
 	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/val"
 	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type UseCase interface {
@@ -12,11 +17,46 @@ type UseCase interface {
 	CreateDM(ctx context.Context, req CreateDMReq) (*CreateDMResp, error)
 	CreateGroup(ctx context.Context, req CreateGroupReq) (*CreateGroupResp, error)
 	CheckDMExists(ctx context.Context, req CheckDMExistsReq) (*CheckDMExistsResp, error)
+	GetSavedChat(ctx context.Context, req GetSavedChatReq) (*GetSavedChatResp, error)
+	InviteByEmail(ctx context.Context, req InviteByEmailReq) (*InviteByEmailResp, error)
+	CreateGuestLink(ctx context.Context, req CreateGuestLinkReq) (*CreateGuestLinkResp, error)
+	SetRetention(ctx context.Context, req SetRetentionReq) (*SetRetentionResp, error)
+	CreateWebhook(ctx context.Context, req CreateWebhookReq) (*CreateWebhookResp, error)
+	GetWebhooks(ctx context.Context, req GetWebhooksReq) (*GetWebhooksResp, error)
+	DeleteWebhook(ctx context.Context, req DeleteWebhookReq) error
+	AddParticipants(ctx context.Context, req AddParticipantsReq) (*AddParticipantsResp, error)
+	RemoveParticipant(ctx context.Context, req RemoveParticipantReq) error
+	LeaveChat(ctx context.Context, req LeaveChatReq) error
+	MuteChat(ctx context.Context, req MuteChatReq) (*MuteChatResp, error)
+	UnmuteChat(ctx context.Context, req UnmuteChatReq) error
+	ArchiveChat(ctx context.Context, req ArchiveChatReq) error
+	UnarchiveChat(ctx context.Context, req ArchiveChatReq) error
+	PinChat(ctx context.Context, req PinChatReq) error
+	UnpinChat(ctx context.Context, req PinChatReq) error
+	CreateChannel(ctx context.Context, req CreateChannelReq) (*CreateChannelResp, error)
+	GetChannelsList(ctx context.Context, req GetChannelsListReq) (*GetChannelsListResp, error)
+	SubscribeChannel(ctx context.Context, req SubscribeChannelReq) (*SubscribeChannelResp, error)
+	UnsubscribeChannel(ctx context.Context, req SubscribeChannelReq) error
+	CreateInviteLink(ctx context.Context, req CreateInviteLinkReq) (*CreateInviteLinkResp, error)
+	GetInviteLinks(ctx context.Context, req GetInviteLinksReq) (*GetInviteLinksResp, error)
+	RevokeInviteLink(ctx context.Context, req RevokeInviteLinkReq) error
+	JoinByInviteLink(ctx context.Context, req JoinByInviteLinkReq) (*JoinByInviteLinkResp, error)
+	RequestToJoin(ctx context.Context, req RequestToJoinReq) (*RequestToJoinResp, error)
+	GetJoinRequests(ctx context.Context, req GetJoinRequestsReq) (*GetJoinRequestsResp, error)
+	ApproveJoinRequest(ctx context.Context, req JoinRequestReq) error
+	RejectJoinRequest(ctx context.Context, req JoinRequestReq) error
+	TransferOwnership(ctx context.Context, req TransferOwnershipReq) error
+	DeleteGroup(ctx context.Context, req DeleteGroupReq) error
+	SetChatDescription(ctx context.Context, req SetChatDescriptionReq) (*SetChatDescriptionResp, error)
+	SetChatImage(ctx context.Context, req SetChatImageReq) (*SetChatImageResp, error)
 }
 
 type GetDMsListReq struct {
-	Page  int `query:"page"`
-	Limit int `query:"limit"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+	Filter string `query:"filter"`
+	Sort   string `query:"sort"`
+	Total  string `query:"total"`
 }
 
 func (req GetDMsListReq) Validate() error {
@@ -28,30 +68,48 @@ func (req GetDMsListReq) Validate() error {
 	if req.Limit <= 0 || req.Limit > 100 {
 		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
 	}
+	if !validChatFilter(req.Filter) {
+		verr = errs.AddFieldError(verr, "filter", "filter must be active or archived")
+	}
+	if !validChatSort(req.Sort) {
+		verr = errs.AddFieldError(verr, "sort", "sort must be activity or created")
+	}
+	if req.Total != "" && !domain.TotalMode(req.Total).IsValid() {
+		verr = errs.AddFieldError(verr, "total", "total must be exact or none")
+	}
 
 	return verr
 }
 
 type GetDMsListResp struct {
-	DMs   []DMListItem `json:"dms"`
-	Total int          `json:"total"`
-	Page  int          `json:"page"`
-	Limit int          `json:"limit"`
+	DMs     []DMListItem `json:"dms"`
+	Total   *int         `json:"total,omitempty"` // Omitted with total=none
+	HasMore bool         `json:"has_more"`
+	Page    int          `json:"page"`
+	Limit   int          `json:"limit"`
 }
 
 type DMListItem struct {
 	ChatID            int     `json:"chat_id"`
+	PublicID          string  `json:"public_id"`
 	OtherUserID       int     `json:"other_user_id"`
+	OtherUserPublicID string  `json:"other_user_public_id"`
 	OtherUsername     string  `json:"other_username"`
 	OtherUserImage    string  `json:"other_user_image,omitempty"`
 	LastMessageText   *string `json:"last_message_text,omitempty"`
 	LastMessageSentAt *string `json:"last_message_sent_at,omitempty"`
 	UnreadCount       int     `json:"unread_count"`
+	MutedUntil        *string `json:"muted_until,omitempty"` // Omitted unless muted
+	Archived          bool    `json:"archived"`
+	Pinned            bool    `json:"pinned"`
 }
 
 type GetGroupsListReq struct {
-	Page  int `query:"page"`
-	Limit int `query:"limit"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+	Filter string `query:"filter"`
+	Sort   string `query:"sort"`
+	Total  string `query:"total"`
 }
 
 func (req GetGroupsListReq) Validate() error {
@@ -63,25 +121,75 @@ func (req GetGroupsListReq) Validate() error {
 	if req.Limit <= 0 || req.Limit > 100 {
 		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
 	}
+	if !validChatFilter(req.Filter) {
+		verr = errs.AddFieldError(verr, "filter", "filter must be active or archived")
+	}
+	if !validChatSort(req.Sort) {
+		verr = errs.AddFieldError(verr, "sort", "sort must be activity or created")
+	}
+	if req.Total != "" && !domain.TotalMode(req.Total).IsValid() {
+		verr = errs.AddFieldError(verr, "total", "total must be exact or none")
+	}
 
 	return verr
 }
 
 type GetGroupsListResp struct {
-	Groups []GroupListItem `json:"groups"`
-	Total  int             `json:"total"`
-	Page   int             `json:"page"`
-	Limit  int             `json:"limit"`
+	Groups  []GroupListItem `json:"groups"`
+	Total   *int            `json:"total,omitempty"` // Omitted with total=none
+	HasMore bool            `json:"has_more"`
+	Page    int             `json:"page"`
+	Limit   int             `json:"limit"`
 }
 
 type GroupListItem struct {
 	ChatID            int     `json:"chat_id"`
+	PublicID          string  `json:"public_id"`
 	Name              string  `json:"name"`
+	Description       string  `json:"description,omitempty"`
+	ImagePath         *string `json:"image_path,omitempty"`
 	CreatorID         int     `json:"creator_id"`
 	ParticipantCount  int     `json:"participant_count"`
 	LastMessageText   *string `json:"last_message_text,omitempty"`
 	LastMessageSentAt *string `json:"last_message_sent_at,omitempty"`
 	UnreadCount       int     `json:"unread_count"`
+	MutedUntil        *string `json:"muted_until,omitempty"` // Omitted unless muted
+	Archived          bool    `json:"archived"`
+	Pinned            bool    `json:"pinned"`
+}
+
+func validChatFilter(filter string) bool {
+	switch domain.ChatFilter(filter) {
+	case "", domain.ChatFilterActive, domain.ChatFilterArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// chatFilter maps the filter query parameter to a domain filter, defaulting to active.
+func chatFilter(filter string) domain.ChatFilter {
+	if filter == "" {
+		return domain.ChatFilterActive
+	}
+	return domain.ChatFilter(filter)
+}
+
+func validChatSort(sort string) bool {
+	switch domain.ChatSort(sort) {
+	case "", domain.ChatSortActivity, domain.ChatSortCreated:
+		return true
+	default:
+		return false
+	}
+}
+
+// chatSort maps the sort query parameter to a domain sort, defaulting to activity.
+func chatSort(sort string) domain.ChatSort {
+	if sort == "" {
+		return domain.ChatSortActivity
+	}
+	return domain.ChatSort(sort)
 }
 
 type GetChatReq struct {
@@ -100,18 +208,28 @@ func (req GetChatReq) Validate() error {
 
 type GetChatResp struct {
 	ChatID       int                  `json:"chat_id"`
+	PublicID     string               `json:"public_id"`
 	Type         string               `json:"type"`
 	Name         string               `json:"name,omitempty"`
+	Description  string               `json:"description,omitempty"`
+	ImagePath    *string              `json:"image_path,omitempty"`
 	CreatorID    int                  `json:"creator_id,omitempty"`
+	WorkspaceID  *int                 `json:"workspace_id"` // Null for Saved Messages
 	Participants []ChatParticipantDTO `json:"participants"`
 	CreatedAt    string               `json:"created_at"`
+	// Days messages are kept; null follows the server default, 0 keeps them forever
+	RetentionDays   *int `json:"retention_days"`
+	SubscriberCount *int `json:"subscriber_count,omitempty"` // Channels only
 }
 
 type ChatParticipantDTO struct {
-	UserID    int     `json:"user_id"`
-	Username  string  `json:"username"`
-	ImagePath *string `json:"image_path,omitempty"`
-	JoinedAt  string  `json:"joined_at"`
+	UserID       int     `json:"user_id"`
+	UserPublicID string  `json:"user_public_id"`
+	Username     string  `json:"username"`
+	DisplayName  string  `json:"display_name,omitempty"`
+	Bio          string  `json:"bio,omitempty"`
+	ImagePath    *string `json:"image_path,omitempty"`
+	JoinedAt     string  `json:"joined_at"`
 }
 
 type CreateDMReq struct {
@@ -129,7 +247,8 @@ func (req CreateDMReq) Validate() error {
 }
 
 type CreateDMResp struct {
-	ChatID int `json:"chat_id"`
+	ChatID   int    `json:"chat_id"`
+	PublicID string `json:"public_id"`
 }
 
 type CreateGroupReq struct {
@@ -154,7 +273,8 @@ func (req CreateGroupReq) Validate() error {
 }
 
 type CreateGroupResp struct {
-	ChatID int `json:"chat_id"`
+	ChatID   int    `json:"chat_id"`
+	PublicID string `json:"public_id"`
 }
 
 type CheckDMExistsReq struct {
@@ -175,3 +295,598 @@ type CheckDMExistsResp struct {
 	Exists bool `json:"exists"`
 	ChatID *int `json:"chat_id,omitempty"`
 }
+
+type GetSavedChatReq struct{}
+
+func (req GetSavedChatReq) Validate() error {
+	return nil
+}
+
+type GetSavedChatResp struct {
+	ChatID    int    `json:"chat_id"`
+	PublicID  string `json:"public_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Invite statuses returned by InviteByEmail.
+const (
+	// InviteStatusAdded means the email belongs to a registered user, who
+	// was added to the chat right away.
+	InviteStatusAdded = "added"
+	// InviteStatusInvited means an invite email was sent and the user joins
+	// the chat on signup.
+	InviteStatusInvited = "invited"
+)
+
+type InviteByEmailReq struct {
+	ChatID int    `path:"chat_id"`
+	Email  string `json:"email"`
+}
+
+func (req InviteByEmailReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if err := val.ValidateEmail(req.Email); err != nil {
+		verr = errs.AddFieldError(verr, "email", err.Error())
+	}
+
+	return verr
+}
+
+type InviteByEmailResp struct {
+	Status    string  `json:"status"`
+	UserID    *int    `json:"user_id,omitempty"`    // Set when the user was added
+	ExpiresAt *string `json:"expires_at,omitempty"` // Set when an invite was sent
+}
+
+type CreateGuestLinkReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req CreateGuestLinkReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type CreateGuestLinkResp struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type SetRetentionReq struct {
+	ChatID        int  `path:"chat_id"`
+	RetentionDays *int `json:"retention_days"` // Null follows the server default
+}
+
+func (req SetRetentionReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.RetentionDays != nil && (*req.RetentionDays < 0 || *req.RetentionDays > domain.MaxRetentionDays) {
+		verr = errs.AddFieldError(verr, "retention_days", "retention_days must be between 0 and 36500")
+	}
+
+	return verr
+}
+
+type SetRetentionResp struct {
+	ChatID        int  `json:"chat_id"`
+	RetentionDays *int `json:"retention_days"`
+}
+
+type CreateWebhookReq struct {
+	ChatID int    `path:"chat_id"`
+	Name   string `json:"name"`
+}
+
+func (req CreateWebhookReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		verr = errs.AddFieldError(verr, "name", "name must be between 1 and 100 characters")
+	}
+
+	return verr
+}
+
+// CreateWebhookResp is the only response that carries the webhook secret,
+// it cannot be retrieved later.
+type CreateWebhookResp struct {
+	WebhookID int    `json:"webhook_id"`
+	PublicID  string `json:"public_id"`
+	Name      string `json:"name"`
+	Secret    string `json:"secret"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"created_at"`
+}
+
+type WebhookDTO struct {
+	WebhookID  int     `json:"webhook_id"`
+	PublicID   string  `json:"public_id"`
+	Name       string  `json:"name"`
+	CreatorID  int     `json:"creator_id"`
+	CreatedAt  string  `json:"created_at"`
+	LastUsedAt *string `json:"last_used_at"`
+}
+
+type GetWebhooksReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req GetWebhooksReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type GetWebhooksResp struct {
+	Webhooks []WebhookDTO `json:"webhooks"`
+}
+
+type DeleteWebhookReq struct {
+	ChatID    int `path:"chat_id"`
+	WebhookID int `path:"webhook_id"`
+}
+
+func (req DeleteWebhookReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.WebhookID <= 0 {
+		verr = errs.AddFieldError(verr, "webhook_id", "invalid webhook id")
+	}
+
+	return verr
+}
+
+// maxAddParticipants is how many users one request may add to a group.
+const maxAddParticipants = 50
+
+type AddParticipantsReq struct {
+	ChatID  int   `path:"chat_id"`
+	UserIDs []int `json:"user_ids"`
+}
+
+func (req AddParticipantsReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if len(req.UserIDs) == 0 {
+		verr = errs.AddFieldError(verr, "user_ids", "at least one user id is required")
+	}
+	if len(req.UserIDs) > maxAddParticipants {
+		verr = errs.AddFieldError(verr, "user_ids", "cannot add more than 50 users at once")
+	}
+
+	return verr
+}
+
+type AddParticipantsResp struct {
+	AddedUserIDs []int `json:"added_user_ids"` // Users who were not participants yet
+}
+
+type RemoveParticipantReq struct {
+	ChatID int `path:"chat_id"`
+	UserID int `path:"user_id"`
+}
+
+func (req RemoveParticipantReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type LeaveChatReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req LeaveChatReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type MuteChatReq struct {
+	ChatID     int        `path:"chat_id"`
+	MutedUntil *time.Time `json:"muted_until"` // Null mutes until unmuted
+}
+
+func (req MuteChatReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.MutedUntil != nil && !req.MutedUntil.After(time.Now()) {
+		verr = errs.AddFieldError(verr, "muted_until", "muted_until must be in the future")
+	}
+
+	return verr
+}
+
+type MuteChatResp struct {
+	ChatID     int    `json:"chat_id"`
+	MutedUntil string `json:"muted_until"`
+}
+
+type UnmuteChatReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req UnmuteChatReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type ArchiveChatReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req ArchiveChatReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type PinChatReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req PinChatReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type CreateChannelReq struct {
+	Name string `json:"name"`
+}
+
+func (req CreateChannelReq) Validate() error {
+	var verr error
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		verr = errs.AddFieldError(verr, "name", "name must be between 1 and 100 characters")
+	}
+
+	return verr
+}
+
+type CreateChannelResp struct {
+	ChatID   int    `json:"chat_id"`
+	PublicID string `json:"public_id"`
+}
+
+type GetChannelsListReq struct {
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit" default:"20"`
+	Filter string `query:"filter"` // all or subscribed
+}
+
+func (req GetChannelsListReq) Validate() error {
+	var verr error
+
+	if req.Page < 0 {
+		verr = errs.AddFieldError(verr, "page", "page must be non-negative")
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
+	}
+	if req.Filter != "" && req.Filter != "all" && req.Filter != "subscribed" {
+		verr = errs.AddFieldError(verr, "filter", "filter must be all or subscribed")
+	}
+
+	return verr
+}
+
+type GetChannelsListResp struct {
+	Channels []ChannelListItem `json:"channels"`
+	Total    int               `json:"total"`
+	HasMore  bool              `json:"has_more"`
+	Page     int               `json:"page"`
+	Limit    int               `json:"limit"`
+}
+
+type ChannelListItem struct {
+	ChatID            int     `json:"chat_id"`
+	PublicID          string  `json:"public_id"`
+	Name              string  `json:"name"`
+	CreatorID         int     `json:"creator_id"`
+	SubscriberCount   int     `json:"subscriber_count"`
+	Subscribed        bool    `json:"subscribed"`
+	LastMessageSentAt *string `json:"last_message_sent_at,omitempty"`
+	UnreadCount       int     `json:"unread_count"` // Zero unless subscribed
+}
+
+type SubscribeChannelReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req SubscribeChannelReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type SubscribeChannelResp struct {
+	ChatID          int `json:"chat_id"`
+	SubscriberCount int `json:"subscriber_count"`
+}
+
+type CreateInviteLinkReq struct {
+	ChatID    int        `path:"chat_id"`
+	ExpiresAt *time.Time `json:"expires_at"` // Null uses the default lifetime
+}
+
+func (req CreateInviteLinkReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		verr = errs.AddFieldError(verr, "expires_at", "expires_at must be in the future")
+	}
+
+	return verr
+}
+
+type CreateInviteLinkResp struct {
+	InviteID  int    `json:"invite_id"`
+	Code      string `json:"code"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type InviteLinkDTO struct {
+	InviteID  int    `json:"invite_id"`
+	Code      string `json:"code"`
+	CreatorID int    `json:"creator_id"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type GetInviteLinksReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req GetInviteLinksReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type GetInviteLinksResp struct {
+	Invites []InviteLinkDTO `json:"invites"`
+}
+
+type RevokeInviteLinkReq struct {
+	ChatID   int `path:"chat_id"`
+	InviteID int `path:"invite_id"`
+}
+
+func (req RevokeInviteLinkReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.InviteID <= 0 {
+		verr = errs.AddFieldError(verr, "invite_id", "invalid invite id")
+	}
+
+	return verr
+}
+
+type JoinByInviteLinkReq struct {
+	Code string `path:"code"`
+}
+
+func (req JoinByInviteLinkReq) Validate() error {
+	var verr error
+
+	if strings.TrimSpace(req.Code) == "" {
+		verr = errs.AddFieldError(verr, "code", "code is required")
+	}
+
+	return verr
+}
+
+type JoinByInviteLinkResp struct {
+	ChatID   int    `json:"chat_id"`
+	PublicID string `json:"public_id"`
+}
+
+type RequestToJoinReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req RequestToJoinReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type RequestToJoinResp struct {
+	RequestID int    `json:"request_id"`
+	ChatID    int    `json:"chat_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type JoinRequestDTO struct {
+	RequestID int    `json:"request_id"`
+	UserID    int    `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetJoinRequestsReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req GetJoinRequestsReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type GetJoinRequestsResp struct {
+	Requests []JoinRequestDTO `json:"requests"`
+}
+
+type JoinRequestReq struct {
+	ChatID    int `path:"chat_id"`
+	RequestID int `path:"request_id"`
+}
+
+func (req JoinRequestReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.RequestID <= 0 {
+		verr = errs.AddFieldError(verr, "request_id", "invalid request id")
+	}
+
+	return verr
+}
+
+type TransferOwnershipReq struct {
+	ChatID int `path:"chat_id"`
+	UserID int `json:"user_id"` // New owner, a participant of the group
+}
+
+func (req TransferOwnershipReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.UserID <= 0 {
+		verr = errs.AddFieldError(verr, "user_id", "invalid user id")
+	}
+
+	return verr
+}
+
+type DeleteGroupReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req DeleteGroupReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type SetChatDescriptionReq struct {
+	ChatID      int    `path:"chat_id"`
+	Description string `json:"description"` // Empty clears the description
+}
+
+func (req SetChatDescriptionReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if utf8.RuneCountInString(req.Description) > domain.MaxChatDescriptionLength {
+		verr = errs.AddFieldError(verr, "description", "description must be 500 characters or less")
+	}
+
+	return verr
+}
+
+type SetChatDescriptionResp struct {
+	Description string `json:"description"`
+}
+
+type SetChatImageReq struct {
+	ChatID    int     `path:"chat_id"`
+	ImagePath *string `json:"image_path"` // Null clears the image
+}
+
+func (req SetChatImageReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if req.ImagePath != nil && *req.ImagePath == "" {
+		verr = errs.AddFieldError(verr, "image_path", "image path is required")
+	}
+
+	return verr
+}
+
+type SetChatImageResp struct {
+	ImagePath *string `json:"image_path"`
+}
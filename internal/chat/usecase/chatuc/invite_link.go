@@ -0,0 +1,164 @@
+package chatuc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// CreateInviteLink creates an invite code for a group the caller takes part
+// in. Members of the group's workspace can join the group with it until it
+// expires.
+func (uc *useCase) CreateInviteLink(ctx context.Context, req CreateInviteLinkReq) (*CreateInviteLinkResp, error) {
+	const op = "chatuc.CreateInviteLink"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "invites are only supported for group chats")
+	}
+
+	code, err := domain.NewInviteCode()
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	now := time.Now()
+	link := &domain.ChatInviteLink{
+		ChatID:    chat.ID,
+		CreatorID: authUser.ID,
+		Code:      code,
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.inviteSigner.TTL()),
+	}
+	if req.ExpiresAt != nil {
+		link.ExpiresAt = *req.ExpiresAt
+	}
+
+	if err := uc.chatRepo.CreateInviteLink(ctx, link); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &CreateInviteLinkResp{
+		InviteID:  link.ID,
+		Code:      link.Code,
+		CreatedAt: link.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: link.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// GetInviteLinks lists the unexpired invite links of a group. Only the
+// group's creator and admins of its workspace may list them.
+func (uc *useCase) GetInviteLinks(ctx context.Context, req GetInviteLinksReq) (*GetInviteLinksResp, error) {
+	const op = "chatuc.GetInviteLinks"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.managedChat(ctx, authUser, req.ChatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	links, err := uc.chatRepo.GetInviteLinks(ctx, chat.ID, time.Now())
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]InviteLinkDTO, len(links))
+	for i, l := range links {
+		dtos[i] = InviteLinkDTO{
+			InviteID:  l.ID,
+			Code:      l.Code,
+			CreatorID: l.CreatorID,
+			CreatedAt: l.CreatedAt.Format(time.RFC3339),
+			ExpiresAt: l.ExpiresAt.Format(time.RFC3339),
+		}
+	}
+
+	return &GetInviteLinksResp{Invites: dtos}, nil
+}
+
+// RevokeInviteLink deletes an invite link. Any participant of the group may
+// revoke its invite links, so a leaked code doesn't depend on its creator.
+func (uc *useCase) RevokeInviteLink(ctx context.Context, req RevokeInviteLinkReq) error {
+	const op = "chatuc.RevokeInviteLink"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.chatRepo.DeleteInviteLink(ctx, chat.ID, req.InviteID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("invite_id", "invite not found"))
+	}
+
+	return nil
+}
+
+// JoinByInviteLink adds the caller to the group of an unexpired invite code.
+// The group must be in the caller's workspace. Joining a group the caller
+// already takes part in succeeds.
+func (uc *useCase) JoinByInviteLink(ctx context.Context, req JoinByInviteLinkReq) (*JoinByInviteLinkResp, error) {
+	const op = "chatuc.JoinByInviteLink"
+
+	errInviteNotFound := errs.NewNotFoundError("code", "invite not found")
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	link, err := uc.chatRepo.GetInviteLinkByCode(ctx, req.Code)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errInviteNotFound)
+	}
+	if link.Expired(time.Now()) {
+		return nil, errInviteNotFound
+	}
+
+	// Codes of other workspaces can't be told from missing ones
+	chat, err := uc.chatRepo.GetByID(ctx, link.ChatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errInviteNotFound)
+	}
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != authUser.WorkspaceID {
+		return nil, errInviteNotFound
+	}
+
+	err = uc.chatRepo.AddParticipant(ctx, &domain.ChatParticipant{
+		ChatID:   chat.ID,
+		UserID:   authUser.ID,
+		JoinedAt: time.Now(),
+	})
+	switch {
+	case err == nil:
+		// Subscribes the caller's connections to the group as well
+		uc.broadcaster.BroadcastParticipantAdded(chat.Workspace(), chat.ID, authUser.ID, authUser.ID)
+	case !errors.Is(err, errs.ErrAlreadyExists):
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &JoinByInviteLinkResp{
+		ChatID:   chat.ID,
+		PublicID: chat.PublicID,
+	}, nil
+}
@@ -0,0 +1,211 @@
+package chatuc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/events"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	eventbus "chatx-01-backend/pkg/events"
+)
+
+// RequestToJoin asks to join a group of the caller's workspace. The group's
+// creator is notified over WebSocket and by email.
+func (uc *useCase) RequestToJoin(ctx context.Context, req RequestToJoinReq) (*RequestToJoinResp, error) {
+	const op = "chatuc.RequestToJoin"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != authUser.WorkspaceID {
+		return nil, errs.NewNotFoundError("chat_id", "chat not found")
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "only group chats can be joined")
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chat.ID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if isParticipant {
+		return nil, errs.NewConflictError("chat_id", "user is already a participant of this chat")
+	}
+
+	request := &domain.ChatJoinRequest{
+		ChatID:    chat.ID,
+		UserID:    authUser.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.chatRepo.CreateJoinRequest(ctx, request); err != nil {
+		return nil, errs.ReplaceOn(
+			err,
+			errs.ErrAlreadyExists,
+			errs.NewConflictError("chat_id", "a join request is already pending"),
+		)
+	}
+
+	uc.broadcaster.BroadcastJoinRequested([]int{chat.CreatorID}, ws.JoinRequestPayload{
+		RequestID: request.ID,
+		ChatID:    chat.ID,
+		UserID:    request.UserID,
+		CreatedAt: request.CreatedAt,
+	})
+
+	if err := uc.sendJoinRequestEmail(ctx, chat, request); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &RequestToJoinResp{
+		RequestID: request.ID,
+		ChatID:    chat.ID,
+		CreatedAt: request.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// GetJoinRequests lists the pending join requests of a group, oldest first.
+func (uc *useCase) GetJoinRequests(ctx context.Context, req GetJoinRequestsReq) (*GetJoinRequestsResp, error) {
+	const op = "chatuc.GetJoinRequests"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.managedChat(ctx, authUser, req.ChatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	requests, err := uc.chatRepo.GetJoinRequests(ctx, chat.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]JoinRequestDTO, len(requests))
+	for i, r := range requests {
+		dtos[i] = JoinRequestDTO{
+			RequestID: r.ID,
+			UserID:    r.UserID,
+			CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &GetJoinRequestsResp{Requests: dtos}, nil
+}
+
+// ApproveJoinRequest adds the user of a join request to the group and
+// removes the request.
+func (uc *useCase) ApproveJoinRequest(ctx context.Context, req JoinRequestReq) error {
+	const op = "chatuc.ApproveJoinRequest"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.managedChat(ctx, authUser, req.ChatID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	request, joined, err := uc.chatRepo.ApproveJoinRequest(ctx, chat.ID, req.RequestID, time.Now())
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("request_id", "join request not found"))
+	}
+	if !joined {
+		return nil // Added some other way in the meantime
+	}
+
+	uc.broadcaster.BroadcastParticipantAdded(chat.Workspace(), chat.ID, request.UserID, authUser.ID)
+	if err := uc.notifyInvited(ctx, chat, []int{request.UserID}, authUser.ID); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// RejectJoinRequest removes a join request without adding its user.
+func (uc *useCase) RejectJoinRequest(ctx context.Context, req JoinRequestReq) error {
+	const op = "chatuc.RejectJoinRequest"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.managedChat(ctx, authUser, req.ChatID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	if _, err := uc.chatRepo.DeleteJoinRequest(ctx, chat.ID, req.RequestID); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("request_id", "join request not found"))
+	}
+
+	return nil
+}
+
+// managedChat returns a chat of the user's workspace after checking the user
+// created it or administers the workspace.
+func (uc *useCase) managedChat(ctx context.Context, au auth.AuthenticatedUser, chatID int) (*domain.Chat, error) {
+	const op = "chatuc.managedChat"
+
+	chat, err := uc.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != au.WorkspaceID {
+		return nil, errs.NewNotFoundError("chat_id", "chat not found")
+	}
+	if chat.CreatorID != au.ID && !au.IsWorkspaceAdmin() {
+		return nil, errs.Wrap(op, domain.ErrNotInviteManager)
+	}
+
+	return chat, nil
+}
+
+// sendJoinRequestEmail queues an email telling the group's creator about a
+// join request.
+func (uc *useCase) sendJoinRequestEmail(ctx context.Context, chat *domain.Chat, request *domain.ChatJoinRequest) error {
+	creator, err := uc.authPortal.GetUserByID(ctx, chat.CreatorID)
+	if err != nil {
+		return err
+	}
+	requester, err := uc.authPortal.GetUserByID(ctx, request.UserID)
+	if err != nil {
+		return err
+	}
+
+	event := events.JoinRequestedEvent{
+		Email:         creator.Email,
+		RequesterName: requester.Username,
+		ChatName:      chat.Name,
+		RequestedAt:   request.CreatedAt,
+	}
+
+	eventData, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = uc.joinProducer.SendMessage(ctx, &eventbus.Message{
+		Key:   []byte(creator.Email),
+		Value: eventData,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send join request event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package chatuc
+
+import (
+	"context"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// MuteChat mutes a chat for the caller until the requested time, or until
+// they unmute it. Muted chats are left out of unread totals and don't push
+// mention or inbox notifications.
+func (uc *useCase) MuteChat(ctx context.Context, req MuteChatReq) (*MuteChatResp, error) {
+	const op = "chatuc.MuteChat"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	until := domain.MutedForever
+	if req.MutedUntil != nil {
+		until = req.MutedUntil.UTC().Truncate(time.Second)
+	}
+
+	if err := uc.chatRepo.SetMutedUntil(ctx, chat.ID, authUser.ID, &until); err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	return &MuteChatResp{
+		ChatID:     chat.ID,
+		MutedUntil: until.Format(time.RFC3339),
+	}, nil
+}
+
+// UnmuteChat unmutes a chat for the caller. Unmuting a chat that isn't
+// muted succeeds.
+func (uc *useCase) UnmuteChat(ctx context.Context, req UnmuteChatReq) error {
+	const op = "chatuc.UnmuteChat"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	if err := uc.chatRepo.SetMutedUntil(ctx, chat.ID, authUser.ID, nil); err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package chatuc
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+)
+
+// AddParticipants adds workspace members to a group the caller takes part
+// in. Users who already participate are skipped.
+func (uc *useCase) AddParticipants(ctx context.Context, req AddParticipantsReq) (*AddParticipantsResp, error) {
+	const op = "chatuc.AddParticipants"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "participants can only be changed in group chats")
+	}
+
+	// Check every user before adding any
+	userIDs := slices.Compact(slices.Sorted(slices.Values(req.UserIDs)))
+	for _, userID := range userIDs {
+		exists, err := uc.workspaceUserExists(ctx, authUser.WorkspaceID, userID)
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+		if !exists {
+			return nil, errs.NewNotFoundError("user_ids", "one or more users not found")
+		}
+	}
+
+	participants, err := uc.chatRepo.GetParticipants(ctx, chat.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	added := make([]int, 0, len(userIDs))
+	now := time.Now()
+	for _, userID := range userIDs {
+		if slices.ContainsFunc(participants, func(p domain.ChatParticipant) bool { return p.UserID == userID }) {
+			continue
+		}
+
+		err := uc.chatRepo.AddParticipant(ctx, &domain.ChatParticipant{
+			ChatID:   chat.ID,
+			UserID:   userID,
+			JoinedAt: now,
+		})
+		if errors.Is(err, errs.ErrAlreadyExists) {
+			continue // Added concurrently
+		}
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+
+		uc.broadcaster.BroadcastParticipantAdded(chat.Workspace(), chat.ID, userID, authUser.ID)
+		added = append(added, userID)
+	}
+
+	if err := uc.notifyInvited(ctx, chat, added, authUser.ID); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &AddParticipantsResp{
+		AddedUserIDs: added,
+	}, nil
+}
+
+// RemoveParticipant removes a user from a group. Only the group's creator and
+// admins of its workspace may remove participants, and the creator can't be
+// removed.
+func (uc *useCase) RemoveParticipant(ctx context.Context, req RemoveParticipantReq) error {
+	const op = "chatuc.RemoveParticipant"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != authUser.WorkspaceID {
+		return errs.NewNotFoundError("chat_id", "chat not found")
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return errs.AddFieldError(nil, "chat_id", "participants can only be changed in group chats")
+	}
+
+	if chat.CreatorID != authUser.ID && !authUser.IsWorkspaceAdmin() && !authUser.Can(auth.PermManageAnyGroup) {
+		return errs.Wrap(op, domain.ErrNotChatManager)
+	}
+	if req.UserID == chat.CreatorID {
+		return errs.AddFieldError(nil, "user_id", "the group creator can't be removed")
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chat.ID, req.UserID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return errs.NewNotFoundError("user_id", "user is not a participant of this chat")
+	}
+
+	if err := uc.chatRepo.RemoveParticipant(ctx, chat.ID, req.UserID); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.broadcaster.BroadcastParticipantRemoved(chat.ID, req.UserID, authUser.ID)
+
+	uc.authPortal.RecordAudit(ctx, auth.AuditEvent{
+		Action:       auth.AuditGroupParticipantRemove,
+		ActorID:      authUser.ID,
+		TargetUserID: req.UserID,
+		ChatID:       chat.ID,
+		WorkspaceID:  authUser.WorkspaceID,
+	})
+
+	return nil
+}
+
+// LeaveChat removes the caller from a group. When the creator leaves, the
+// longest-standing remaining participant becomes the new creator. The
+// remaining participants are told with a system message.
+func (uc *useCase) LeaveChat(ctx context.Context, req LeaveChatReq) error {
+	const op = "chatuc.LeaveChat"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != authUser.WorkspaceID {
+		return errs.NewNotFoundError("chat_id", "chat not found")
+	}
+
+	participants, err := uc.chatRepo.GetParticipants(ctx, chat.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	isCaller := func(p domain.ChatParticipant) bool { return p.UserID == authUser.ID }
+	if !slices.ContainsFunc(participants, isCaller) {
+		return errs.NewNotFoundError("chat_id", "chat not found")
+	}
+	remaining := slices.DeleteFunc(participants, isCaller)
+	if chat.Type != domain.ChatTypeGroup {
+		return errs.AddFieldError(nil, "chat_id", "only group chats can be left")
+	}
+
+	// Hand the group over before leaving so it never has an outside creator
+	transferred := chat.CreatorID == authUser.ID && len(remaining) > 0
+	if transferred {
+		successor := slices.MinFunc(remaining, func(a, b domain.ChatParticipant) int {
+			return cmp.Or(a.JoinedAt.Compare(b.JoinedAt), cmp.Compare(a.UserID, b.UserID))
+		})
+		if err := uc.chatRepo.SetCreator(ctx, chat.ID, successor.UserID); err != nil {
+			return errs.Wrap(op, err)
+		}
+		chat.CreatorID = successor.UserID
+	}
+
+	if err := uc.chatRepo.RemoveParticipant(ctx, chat.ID, authUser.ID); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.broadcaster.BroadcastParticipantRemoved(chat.ID, authUser.ID, authUser.ID)
+
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	if err := uc.postLeftMessage(ctx, chat, authUser.ID, transferred); err != nil {
+		return errs.Wrap(op, err)
+	}
+	if transferred {
+		uc.broadcaster.BroadcastChatUpdated(chatPayload(chat, nil))
+	}
+
+	return nil
+}
+
+// postLeftMessage posts the system message telling a group that a user left
+// it, and who owns it now if the user was its creator.
+func (uc *useCase) postLeftMessage(ctx context.Context, chat *domain.Chat, userID int, transferred bool) error {
+	event := domain.SystemMetadata{
+		Event:  domain.SystemEventParticipantLeft,
+		UserID: userID,
+	}
+	if transferred {
+		event.CreatorID = chat.CreatorID
+	}
+
+	return uc.postSystemMessage(ctx, chat, userID, event)
+}
+
+// postSystemMessage posts a system message with the event to a chat on
+// behalf of the sender.
+func (uc *useCase) postSystemMessage(
+	ctx context.Context,
+	chat *domain.Chat,
+	senderID int,
+	event domain.SystemMetadata,
+) error {
+	system, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	message := &domain.Message{
+		ChatID:   chat.ID,
+		SenderID: senderID,
+		Metadata: domain.Metadata{domain.MetadataSystem: system},
+		SentAt:   time.Now(),
+	}
+	if err := uc.messageRepo.Create(ctx, message); err != nil {
+		return err
+	}
+
+	uc.broadcaster.BroadcastNewMessage(ws.MessagePayload{
+		ID:       message.ID,
+		PublicID: message.PublicID,
+		ChatID:   message.ChatID,
+		SenderID: message.SenderID,
+		Metadata: message.Metadata,
+		SentAt:   message.SentAt,
+	})
+
+	return nil
+}
@@ -3,28 +3,77 @@ package chatuc
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
 	"time"
 
+	"chatx-01-backend/internal/analytics"
+	"chatx-01-backend/internal/chat/controller/ws"
 	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/events"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/internal/portal/notifications"
 	"chatx-01-backend/pkg/errs"
+	eventbus "chatx-01-backend/pkg/events"
+	"chatx-01-backend/pkg/filestore"
+	"chatx-01-backend/pkg/token"
 )
 
+// Config holds settings for chat invites and webhooks.
+type Config struct {
+	// InviteRegisterURL is the registration page linked from invite emails.
+	InviteRegisterURL string
+	// GuestJoinURL is the page guest links point to.
+	GuestJoinURL string
+	// WebhookBaseURL is the public API URL incoming webhook URLs start with.
+	WebhookBaseURL string
+	// MaxWebhooksPerChat is how many incoming webhooks a chat may have.
+	MaxWebhooksPerChat int
+}
+
 type useCase struct {
-	chatRepo    domain.ChatRepository
-	messageRepo domain.MessageRepository
-	authPortal  auth.Portal
+	chatRepo       domain.ChatRepository
+	messageRepo    domain.MessageRepository
+	fileStore      filestore.Store
+	authPortal     auth.Portal
+	notificationPr notifications.Portal
+	broadcaster    ws.Broadcaster
+	inviteSigner   *token.InviteSigner
+	inviteProducer eventbus.Producer
+	joinProducer   eventbus.Producer
+	guestSigner    *token.GuestLinkSigner
+	analytics      analytics.Publisher
+	cfg            Config
 }
 
 func New(
 	chatRepo domain.ChatRepository,
 	messageRepo domain.MessageRepository,
+	fileStore filestore.Store,
 	authPortal auth.Portal,
+	notificationPr notifications.Portal,
+	broadcaster ws.Broadcaster,
+	inviteSigner *token.InviteSigner,
+	inviteProducer eventbus.Producer,
+	joinProducer eventbus.Producer,
+	guestSigner *token.GuestLinkSigner,
+	analyticsPr analytics.Publisher,
+	cfg Config,
 ) UseCase {
 	return &useCase{
-		chatRepo:    chatRepo,
-		messageRepo: messageRepo,
-		authPortal:  authPortal,
+		chatRepo:       chatRepo,
+		messageRepo:    messageRepo,
+		fileStore:      fileStore,
+		authPortal:     authPortal,
+		notificationPr: notificationPr,
+		broadcaster:    broadcaster,
+		inviteSigner:   inviteSigner,
+		inviteProducer: inviteProducer,
+		joinProducer:   joinProducer,
+		guestSigner:    guestSigner,
+		analytics:      analyticsPr,
+		cfg:            cfg,
 	}
 }
 
@@ -35,77 +84,126 @@ func (uc *useCase) GetDMsList(ctx context.Context, req GetDMsListReq) (*GetDMsLi
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
-	userID := authUser.ID
 
 	offset := req.Page * req.Limit
-	chats, total, err := uc.chatRepo.GetDMsListByUser(ctx, userID, offset, req.Limit)
+	withTotal := domain.TotalMode(req.Total) != domain.TotalNone
+	entries, total, err := uc.chatRepo.GetDMsListEnriched(
+		ctx,
+		authUser.WorkspaceID,
+		authUser.ID,
+		chatFilter(req.Filter),
+		chatSort(req.Sort),
+		offset,
+		req.Limit+1,
+		withTotal,
+	)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
+	hasMore := len(entries) > req.Limit
+	if hasMore {
+		entries = entries[:req.Limit]
+	}
 
-	dmItems := make([]DMListItem, 0, len(chats))
-	for _, chat := range chats {
-		participants, err := uc.chatRepo.GetParticipants(ctx, chat.ID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
+	if err := uc.loadArchivedLastMessages(ctx, entries); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
 
-		var otherUserID int
-		for _, p := range participants {
-			if p.UserID != userID {
-				otherUserID = p.UserID
-				break
-			}
+	otherUserIDs := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		if entry.OtherUserID != 0 {
+			otherUserIDs = append(otherUserIDs, entry.OtherUserID)
 		}
+	}
+	otherUsers, err := uc.authPortal.GetUsersByIDs(ctx, otherUserIDs)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	usersByID := make(map[int]*auth.User, len(otherUsers))
+	for _, u := range otherUsers {
+		usersByID[u.ID] = u
+	}
 
-		if otherUserID == 0 {
+	dmItems := make([]DMListItem, 0, len(entries))
+	for _, entry := range entries {
+		otherUser, ok := usersByID[entry.OtherUserID]
+		if !ok {
 			continue
 		}
 
-		otherUser, err := uc.authPortal.GetUserByID(ctx, otherUserID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
-
-		var lastMessageText *string
-		var lastMessageSentAt *string
-		lastMsg, err := uc.messageRepo.GetLastMessage(ctx, chat.ID)
-		if err != nil && !errors.Is(err, errs.ErrNotFound) {
-			return nil, errs.Wrap(op, err)
-		}
-		if lastMsg != nil {
-			lastMessageText = &lastMsg.Content
-			sentAt := lastMsg.SentAt.Format(time.RFC3339)
-			lastMessageSentAt = &sentAt
-		}
-
-		unreadCount, err := uc.messageRepo.GetUnreadCountByChat(ctx, chat.ID, userID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
-
 		otherUserImage := ""
 		if otherUser.ImagePath != nil {
 			otherUserImage = *otherUser.ImagePath
 		}
 
+		lastMessageText, lastMessageSentAt := lastMessageFields(entry.LastMessage)
+
 		dmItems = append(dmItems, DMListItem{
-			ChatID:            chat.ID,
+			ChatID:            entry.Chat.ID,
+			PublicID:          entry.Chat.PublicID,
 			OtherUserID:       otherUser.ID,
+			OtherUserPublicID: otherUser.PublicID,
 			OtherUsername:     otherUser.Username,
 			OtherUserImage:    otherUserImage,
 			LastMessageText:   lastMessageText,
 			LastMessageSentAt: lastMessageSentAt,
-			UnreadCount:       unreadCount,
+			UnreadCount:       entry.UnreadCount,
+			MutedUntil:        mutedUntil(entry.MutedUntil),
+			Archived:          entry.Archived,
+			Pinned:            entry.Pinned,
 		})
 	}
 
-	return &GetDMsListResp{
-		DMs:   dmItems,
-		Total: total,
-		Page:  req.Page,
-		Limit: req.Limit,
-	}, nil
+	resp := &GetDMsListResp{
+		DMs:     dmItems,
+		HasMore: hasMore,
+		Page:    req.Page,
+		Limit:   req.Limit,
+	}
+	if withTotal {
+		resp.Total = &total
+	}
+
+	return resp, nil
+}
+
+// loadArchivedLastMessages fills in the last message of listed chats whose
+// last message is no longer in the messages table, such as after archival,
+// with a lookup of their own.
+func (uc *useCase) loadArchivedLastMessages(ctx context.Context, entries []domain.ChatListEntry) error {
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Chat.LastMessageID == nil || entry.LastMessage != nil {
+			continue
+		}
+		message, err := uc.messageRepo.GetLastMessage(ctx, entry.Chat.ID)
+		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+			return err
+		}
+		entry.LastMessage = message
+	}
+
+	return nil
+}
+
+// lastMessageFields returns the text and send time chat lists show for a
+// chat's last message.
+func lastMessageFields(message *domain.Message) (*string, *string) {
+	if message == nil {
+		return nil, nil
+	}
+	sentAt := message.SentAt.Format(time.RFC3339)
+	return &message.Content, &sentAt
+}
+
+// mutedUntil formats a participant's mute deadline, or returns nil if the
+// chat isn't muted.
+func mutedUntil(until *time.Time) *string {
+	if until == nil || !time.Now().Before(*until) {
+		return nil
+	}
+	formatted := until.Format(time.RFC3339)
+	return &formatted
 }
 
 func (uc *useCase) GetGroupsList(ctx context.Context, req GetGroupsListReq) (*GetGroupsListResp, error) {
@@ -115,55 +213,63 @@ func (uc *useCase) GetGroupsList(ctx context.Context, req GetGroupsListReq) (*Ge
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
-	userID := authUser.ID
 
 	offset := req.Page * req.Limit
-	chats, total, err := uc.chatRepo.GetGroupsListByUser(ctx, userID, offset, req.Limit)
+	withTotal := domain.TotalMode(req.Total) != domain.TotalNone
+	entries, total, err := uc.chatRepo.GetGroupsListEnriched(
+		ctx,
+		authUser.WorkspaceID,
+		authUser.ID,
+		chatFilter(req.Filter),
+		chatSort(req.Sort),
+		offset,
+		req.Limit+1,
+		withTotal,
+	)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
+	hasMore := len(entries) > req.Limit
+	if hasMore {
+		entries = entries[:req.Limit]
+	}
 
-	groupItems := make([]GroupListItem, 0, len(chats))
-	for _, chat := range chats {
-		participants, err := uc.chatRepo.GetParticipants(ctx, chat.ID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
-
-		var lastMessageText *string
-		var lastMessageSentAt *string
-		lastMsg, err := uc.messageRepo.GetLastMessage(ctx, chat.ID)
-		if err != nil && !errors.Is(err, errs.ErrNotFound) {
-			return nil, errs.Wrap(op, err)
-		}
-		if lastMsg != nil {
-			lastMessageText = &lastMsg.Content
-			sentAt := lastMsg.SentAt.Format(time.RFC3339)
-			lastMessageSentAt = &sentAt
-		}
+	if err := uc.loadArchivedLastMessages(ctx, entries); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
 
-		unreadCount, err := uc.messageRepo.GetUnreadCountByChat(ctx, chat.ID, userID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
+	groupItems := make([]GroupListItem, 0, len(entries))
+	for _, entry := range entries {
+		lastMessageText, lastMessageSentAt := lastMessageFields(entry.LastMessage)
 
 		groupItems = append(groupItems, GroupListItem{
-			ChatID:            chat.ID,
-			Name:              chat.Name,
-			CreatorID:         chat.CreatorID,
-			ParticipantCount:  len(participants),
+			ChatID:            entry.Chat.ID,
+			PublicID:          entry.Chat.PublicID,
+			Name:              entry.Chat.Name,
+			Description:       entry.Chat.Description,
+			ImagePath:         entry.Chat.ImagePath,
+			CreatorID:         entry.Chat.CreatorID,
+			ParticipantCount:  entry.Chat.ParticipantCount,
 			LastMessageText:   lastMessageText,
 			LastMessageSentAt: lastMessageSentAt,
-			UnreadCount:       unreadCount,
+			UnreadCount:       entry.UnreadCount,
+			MutedUntil:        mutedUntil(entry.MutedUntil),
+			Archived:          entry.Archived,
+			Pinned:            entry.Pinned,
 		})
 	}
 
-	return &GetGroupsListResp{
-		Groups: groupItems,
-		Total:  total,
-		Page:   req.Page,
-		Limit:  req.Limit,
-	}, nil
+	resp := &GetGroupsListResp{
+		Groups:  groupItems,
+		HasMore: hasMore,
+		Page:    req.Page,
+		Limit:   req.Limit,
+	}
+	if withTotal {
+		resp.Total = &total
+	}
+
+	return resp, nil
 }
 
 func (uc *useCase) GetChat(ctx context.Context, req GetChatReq) (*GetChatResp, error) {
@@ -181,7 +287,7 @@ func (uc *useCase) GetChat(ctx context.Context, req GetChatReq) (*GetChatResp, e
 		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
 	}
 
-	isParticipant, err := uc.chatRepo.IsParticipant(ctx, req.ChatID, userID)
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, userID)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
@@ -189,6 +295,27 @@ func (uc *useCase) GetChat(ctx context.Context, req GetChatReq) (*GetChatResp, e
 		return nil, errs.Wrap(op, domain.ErrNotParticipant)
 	}
 
+	resp := &GetChatResp{
+		ChatID:       chat.ID,
+		PublicID:     chat.PublicID,
+		Type:         string(chat.Type),
+		Name:         chat.Name,
+		Description:  chat.Description,
+		ImagePath:    chat.ImagePath,
+		CreatorID:    chat.CreatorID,
+		WorkspaceID:  chat.WorkspaceID,
+		Participants: []ChatParticipantDTO{},
+		CreatedAt:    chat.CreatedAt.Format(time.RFC3339),
+
+		RetentionDays: chat.RetentionDays,
+	}
+
+	// Channels can have too many subscribers to list
+	if chat.Type == domain.ChatTypeChannel {
+		resp.SubscriberCount = &chat.ParticipantCount
+		return resp, nil
+	}
+
 	// Get participants
 	participants, err := uc.chatRepo.GetParticipants(ctx, req.ChatID)
 	if err != nil {
@@ -196,29 +323,39 @@ func (uc *useCase) GetChat(ctx context.Context, req GetChatReq) (*GetChatResp, e
 	}
 
 	// Enrich with user data
-	participantDTOs := make([]ChatParticipantDTO, len(participants))
+	participantIDs := make([]int, len(participants))
 	for i, p := range participants {
-		u, err := uc.authPortal.GetUserByID(ctx, p.UserID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
+		participantIDs[i] = p.UserID
+	}
+	users, err := uc.authPortal.GetUsersByIDs(ctx, participantIDs)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	usersByID := make(map[int]*auth.User, len(users))
+	for _, u := range users {
+		usersByID[u.ID] = u
+	}
 
-		participantDTOs[i] = ChatParticipantDTO{
-			UserID:    u.ID,
-			Username:  u.Username,
-			ImagePath: u.ImagePath,
-			JoinedAt:  p.JoinedAt.Format(time.RFC3339),
+	participantDTOs := make([]ChatParticipantDTO, 0, len(participants))
+	for _, p := range participants {
+		u, ok := usersByID[p.UserID]
+		if !ok {
+			continue
 		}
+
+		participantDTOs = append(participantDTOs, ChatParticipantDTO{
+			UserID:       u.ID,
+			UserPublicID: u.PublicID,
+			Username:     u.Username,
+			DisplayName:  u.DisplayName,
+			Bio:          u.Bio,
+			ImagePath:    u.ImagePath,
+			JoinedAt:     p.JoinedAt.Format(time.RFC3339),
+		})
 	}
+	resp.Participants = participantDTOs
 
-	return &GetChatResp{
-		ChatID:       chat.ID,
-		Type:         string(chat.Type),
-		Name:         chat.Name,
-		CreatorID:    chat.CreatorID,
-		Participants: participantDTOs,
-		CreatedAt:    chat.CreatedAt.Format(time.RFC3339),
-	}, nil
+	return resp, nil
 }
 
 func (uc *useCase) CreateDM(ctx context.Context, req CreateDMReq) (*CreateDMResp, error) {
@@ -235,8 +372,8 @@ func (uc *useCase) CreateDM(ctx context.Context, req CreateDMReq) (*CreateDMResp
 		return nil, errs.Wrap(op, domain.ErrCannotMessageSelf)
 	}
 
-	// Check if other user exists
-	exists, err := uc.authPortal.UserExists(ctx, req.OtherUserID)
+	// Check if other user exists in the workspace
+	exists, err := uc.workspaceUserExists(ctx, authUser.WorkspaceID, req.OtherUserID)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
@@ -244,8 +381,16 @@ func (uc *useCase) CreateDM(ctx context.Context, req CreateDMReq) (*CreateDMResp
 		return nil, errs.NewNotFoundError("other_user_id", "user not found")
 	}
 
+	blocked, err := uc.authPortal.IsBlocked(ctx, userID, req.OtherUserID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if blocked {
+		return nil, errs.Wrap(op, domain.ErrUserBlocked)
+	}
+
 	// Check if DM already exists
-	existingChat, err := uc.chatRepo.GetDMByParticipants(ctx, userID, req.OtherUserID)
+	existingChat, err := uc.chatRepo.GetDMByParticipants(ctx, authUser.WorkspaceID, userID, req.OtherUserID)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		return nil, errs.Wrap(op, err)
 	}
@@ -255,9 +400,10 @@ func (uc *useCase) CreateDM(ctx context.Context, req CreateDMReq) (*CreateDMResp
 
 	// Create chat
 	chat := &domain.Chat{
-		Type:      domain.ChatTypeDirect,
-		CreatorID: userID,
-		CreatedAt: time.Now(),
+		Type:        domain.ChatTypeDirect,
+		CreatorID:   userID,
+		WorkspaceID: &authUser.WorkspaceID,
+		CreatedAt:   time.Now(),
 	}
 
 	if err := uc.chatRepo.Create(ctx, chat); err != nil {
@@ -282,8 +428,12 @@ func (uc *useCase) CreateDM(ctx context.Context, req CreateDMReq) (*CreateDMResp
 		return nil, errs.Wrap(op, err)
 	}
 
+	uc.broadcaster.BroadcastChatCreated(chatPayload(chat, []int{userID, req.OtherUserID}))
+	uc.trackChatCreated(ctx, authUser, chat, 2)
+
 	return &CreateDMResp{
-		ChatID: chat.ID,
+		ChatID:   chat.ID,
+		PublicID: chat.PublicID,
 	}, nil
 }
 
@@ -296,9 +446,9 @@ func (uc *useCase) CreateGroup(ctx context.Context, req CreateGroupReq) (*Create
 	}
 	userID := authUser.ID
 
-	// Validate all participant IDs exist
+	// Validate all participant IDs exist in the workspace
 	for _, participantID := range req.ParticipantIDs {
-		exists, err := uc.authPortal.UserExists(ctx, participantID)
+		exists, err := uc.workspaceUserExists(ctx, authUser.WorkspaceID, participantID)
 		if err != nil {
 			return nil, errs.Wrap(op, err)
 		}
@@ -309,10 +459,11 @@ func (uc *useCase) CreateGroup(ctx context.Context, req CreateGroupReq) (*Create
 
 	// Create chat
 	chat := &domain.Chat{
-		Type:      domain.ChatTypeGroup,
-		Name:      req.Name,
-		CreatorID: userID,
-		CreatedAt: time.Now(),
+		Type:        domain.ChatTypeGroup,
+		Name:        req.Name,
+		CreatorID:   userID,
+		WorkspaceID: &authUser.WorkspaceID,
+		CreatedAt:   time.Now(),
 	}
 
 	if err := uc.chatRepo.Create(ctx, chat); err != nil {
@@ -330,6 +481,7 @@ func (uc *useCase) CreateGroup(ctx context.Context, req CreateGroupReq) (*Create
 	}
 
 	// Add other participants
+	participantIDs := []int{userID}
 	for _, participantID := range req.ParticipantIDs {
 		if participantID == userID {
 			continue // Skip creator, already added
@@ -342,10 +494,20 @@ func (uc *useCase) CreateGroup(ctx context.Context, req CreateGroupReq) (*Create
 		}); err != nil {
 			return nil, errs.Wrap(op, err)
 		}
+		participantIDs = append(participantIDs, participantID)
+	}
+
+	// Let participants know about the group without polling
+	uc.broadcaster.BroadcastChatCreated(chatPayload(chat, participantIDs))
+	uc.trackChatCreated(ctx, authUser, chat, len(participantIDs))
+
+	if err := uc.notifyInvited(ctx, chat, participantIDs[1:], userID); err != nil {
+		return nil, errs.Wrap(op, err)
 	}
 
 	return &CreateGroupResp{
-		ChatID: chat.ID,
+		ChatID:   chat.ID,
+		PublicID: chat.PublicID,
 	}, nil
 }
 
@@ -358,8 +520,13 @@ func (uc *useCase) CheckDMExists(ctx context.Context, req CheckDMExistsReq) (*Ch
 	}
 	userID := authUser.ID
 
-	// Check if other user exists
-	exists, err := uc.authPortal.UserExists(ctx, req.OtherUserID)
+	// Self chats are Saved Messages, not DMs
+	if userID == req.OtherUserID {
+		return nil, errs.Wrap(op, domain.ErrCannotMessageSelf)
+	}
+
+	// Check if other user exists in the workspace
+	exists, err := uc.workspaceUserExists(ctx, authUser.WorkspaceID, req.OtherUserID)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
@@ -368,7 +535,7 @@ func (uc *useCase) CheckDMExists(ctx context.Context, req CheckDMExistsReq) (*Ch
 	}
 
 	// Check if DM exists
-	existingChat, err := uc.chatRepo.GetDMByParticipants(ctx, userID, req.OtherUserID)
+	existingChat, err := uc.chatRepo.GetDMByParticipants(ctx, authUser.WorkspaceID, userID, req.OtherUserID)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		return nil, errs.Wrap(op, err)
 	}
@@ -385,3 +552,331 @@ func (uc *useCase) CheckDMExists(ctx context.Context, req CheckDMExistsReq) (*Ch
 		ChatID: nil,
 	}, nil
 }
+
+func (uc *useCase) GetSavedChat(ctx context.Context, req GetSavedChatReq) (*GetSavedChatResp, error) {
+	const op = "chatuc.GetSavedChat"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.getOrCreateSavedChat(ctx, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &GetSavedChatResp{
+		ChatID:    chat.ID,
+		PublicID:  chat.PublicID,
+		CreatedAt: chat.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (uc *useCase) InviteByEmail(ctx context.Context, req InviteByEmailReq) (*InviteByEmailResp, error) {
+	const op = "chatuc.InviteByEmail"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chat.ID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "invites are only supported for group chats")
+	}
+
+	// Registered users are added right away
+	user, err := uc.authPortal.GetUserByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, errs.Wrap(op, err)
+	}
+	if user != nil {
+		// Joining the group brings the user into its workspace
+		if err := uc.authPortal.AddWorkspaceMember(ctx, *chat.WorkspaceID, user.ID); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+		if err := uc.addParticipant(ctx, chat, user.ID, authUser.ID); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+
+		return &InviteByEmailResp{
+			Status: InviteStatusAdded,
+			UserID: &user.ID,
+		}, nil
+	}
+
+	inviter, err := uc.authPortal.GetUserByID(ctx, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	now := time.Now()
+	invite := &domain.ChatInvite{
+		ChatID:    chat.ID,
+		Email:     req.Email,
+		InviterID: authUser.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.inviteSigner.TTL()),
+	}
+	if err := uc.chatRepo.UpsertInvite(ctx, invite); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	if err := uc.sendInviteEmail(ctx, invite, inviter.Username, chat.Name); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	expiresAt := invite.ExpiresAt.Format(time.RFC3339)
+	return &InviteByEmailResp{
+		Status:    InviteStatusInvited,
+		ExpiresAt: &expiresAt,
+	}, nil
+}
+
+func (uc *useCase) CreateGuestLink(ctx context.Context, req CreateGuestLinkReq) (*CreateGuestLinkResp, error) {
+	const op = "chatuc.CreateGuestLink"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chat.ID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "guest links are only supported for group chats")
+	}
+
+	guestToken, err := uc.guestSigner.Sign(chat.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	expiresAt := time.Now().Add(uc.guestSigner.TTL())
+
+	joinURL, err := url.Parse(uc.cfg.GuestJoinURL)
+	if err != nil {
+		return nil, errs.Wrap(op, fmt.Errorf("invalid guest join url: %w", err))
+	}
+	query := joinURL.Query()
+	query.Set("token", guestToken)
+	joinURL.RawQuery = query.Encode()
+
+	return &CreateGuestLinkResp{
+		Token:     guestToken,
+		URL:       joinURL.String(),
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// SetRetention overrides the message retention period of a chat in the
+// workspace of the caller, who administers it.
+func (uc *useCase) SetRetention(ctx context.Context, req SetRetentionReq) (*SetRetentionResp, error) {
+	const op = "chatuc.SetRetention"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	// Saved Messages have no workspace and follow the server default
+	if chat.WorkspaceID == nil || *chat.WorkspaceID != authUser.WorkspaceID {
+		return nil, errs.NewNotFoundError("chat_id", "chat not found")
+	}
+
+	if err := uc.chatRepo.SetRetention(ctx, chat.ID, req.RetentionDays); err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	return &SetRetentionResp{
+		ChatID:        chat.ID,
+		RetentionDays: req.RetentionDays,
+	}, nil
+}
+
+// workspaceUserExists checks if an active user belongs to the workspace.
+func (uc *useCase) workspaceUserExists(ctx context.Context, workspaceID, userID int) (bool, error) {
+	exists, err := uc.authPortal.UserExists(ctx, userID)
+	if err != nil || !exists {
+		return false, err
+	}
+	return uc.authPortal.IsWorkspaceMember(ctx, workspaceID, userID)
+}
+
+// addParticipant adds a user to a group and notifies its participants and the
+// user.
+func (uc *useCase) addParticipant(ctx context.Context, chat *domain.Chat, userID, actorID int) error {
+	err := uc.chatRepo.AddParticipant(ctx, &domain.ChatParticipant{
+		ChatID:   chat.ID,
+		UserID:   userID,
+		JoinedAt: time.Now(),
+	})
+	if err != nil {
+		return errs.ReplaceOn(
+			err,
+			errs.ErrAlreadyExists,
+			errs.NewConflictError("email", "user is already a participant of this chat"),
+		)
+	}
+
+	uc.broadcaster.BroadcastParticipantAdded(chat.Workspace(), chat.ID, userID, actorID)
+	return uc.notifyInvited(ctx, chat, []int{userID}, actorID)
+}
+
+// notifyInvited adds a chat invite to the inbox of users added to a group.
+func (uc *useCase) notifyInvited(ctx context.Context, chat *domain.Chat, userIDs []int, actorID int) error {
+	if len(userIDs) == 0 || chat.WorkspaceID == nil {
+		return nil
+	}
+
+	items := make([]notifications.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		items = append(items, notifications.Notification{
+			WorkspaceID: *chat.WorkspaceID,
+			UserID:      userID,
+			Type:        notifications.TypeChatInvite,
+			ActorID:     actorID,
+			ChatID:      chat.ID,
+			Text:        chat.Name,
+		})
+	}
+
+	return uc.notificationPr.Notify(ctx, items)
+}
+
+// sendInviteEmail queues an email with a signed registration link for the
+// invited address.
+func (uc *useCase) sendInviteEmail(ctx context.Context, invite *domain.ChatInvite, inviterName, chatName string) error {
+	inviteToken, err := uc.inviteSigner.Sign(invite.Email)
+	if err != nil {
+		return err
+	}
+
+	registerURL, err := url.Parse(uc.cfg.InviteRegisterURL)
+	if err != nil {
+		return fmt.Errorf("invalid invite register url: %w", err)
+	}
+	query := registerURL.Query()
+	query.Set("token", inviteToken)
+	registerURL.RawQuery = query.Encode()
+
+	event := events.UserInvitedEvent{
+		Email:       invite.Email,
+		InviterName: inviterName,
+		ChatName:    chatName,
+		RegisterURL: registerURL.String(),
+		ExpiresAt:   invite.ExpiresAt,
+	}
+
+	eventData, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = uc.inviteProducer.SendMessage(ctx, &eventbus.Message{
+		Key:   []byte(invite.Email),
+		Value: eventData,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send invite event: %w", err)
+	}
+
+	return nil
+}
+
+// trackChatCreated records a chat_created usage event.
+func (uc *useCase) trackChatCreated(
+	ctx context.Context,
+	au auth.AuthenticatedUser,
+	chat *domain.Chat,
+	participants int,
+) {
+	uc.analytics.Track(ctx, analytics.Event{
+		Type:        analytics.EventChatCreated,
+		UserID:      au.ID,
+		WorkspaceID: au.WorkspaceID,
+		Properties: map[string]string{
+			"chat_type":    string(chat.Type),
+			"participants": strconv.Itoa(participants),
+		},
+	})
+}
+
+// getOrCreateSavedChat returns the user's Saved Messages chat, creating it
+// on first access.
+func (uc *useCase) getOrCreateSavedChat(ctx context.Context, userID int) (*domain.Chat, error) {
+	const op = "chatuc.getOrCreateSavedChat"
+
+	chat, err := uc.chatRepo.GetSavedByUser(ctx, userID)
+	if err == nil {
+		return chat, nil
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat = &domain.Chat{
+		Type:      domain.ChatTypeSaved,
+		CreatorID: userID,
+		CreatedAt: time.Now(),
+	}
+
+	if err := uc.chatRepo.CreateSaved(ctx, chat); err != nil {
+		// A concurrent request created it first
+		if errors.Is(err, errs.ErrAlreadyExists) {
+			chat, err = uc.chatRepo.GetSavedByUser(ctx, userID)
+			if err != nil {
+				return nil, errs.Wrap(op, err)
+			}
+			return chat, nil
+		}
+		return nil, errs.Wrap(op, err)
+	}
+
+	// Subscribe the user's open connections so notes sync across devices
+	uc.broadcaster.BroadcastChatCreated(chatPayload(chat, []int{userID}))
+
+	return chat, nil
+}
+
+func chatPayload(chat *domain.Chat, participantIDs []int) ws.ChatPayload {
+	return ws.ChatPayload{
+		ChatID:         chat.ID,
+		PublicID:       chat.PublicID,
+		Type:           string(chat.Type),
+		Name:           chat.Name,
+		Description:    chat.Description,
+		ImagePath:      chat.ImagePath,
+		CreatorID:      chat.CreatorID,
+		WorkspaceID:    chat.Workspace(),
+		ParticipantIDs: participantIDs,
+		CreatedAt:      chat.CreatedAt,
+	}
+}
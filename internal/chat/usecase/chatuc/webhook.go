@@ -0,0 +1,151 @@
+package chatuc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// CreateWebhook creates an incoming webhook posting into a group chat the
+// caller participates in. Messages it posts are sent as the caller.
+func (uc *useCase) CreateWebhook(ctx context.Context, req CreateWebhookReq) (*CreateWebhookResp, error) {
+	const op = "chatuc.CreateWebhook"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if chat.Type != domain.ChatTypeGroup {
+		return nil, errs.AddFieldError(nil, "chat_id", "webhooks are only supported for group chats")
+	}
+
+	webhooks, err := uc.chatRepo.GetWebhooks(ctx, chat.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if len(webhooks) >= uc.cfg.MaxWebhooksPerChat {
+		return nil, errs.AddFieldError(
+			nil,
+			"chat_id",
+			fmt.Sprintf("a chat can have at most %d webhooks", uc.cfg.MaxWebhooksPerChat),
+		)
+	}
+
+	secret, secretHash, err := domain.NewWebhookSecret()
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	webhook := &domain.ChatWebhook{
+		ChatID:     chat.ID,
+		CreatorID:  authUser.ID,
+		Name:       strings.TrimSpace(req.Name),
+		SecretHash: secretHash,
+		CreatedAt:  time.Now(),
+	}
+	if err := uc.chatRepo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &CreateWebhookResp{
+		WebhookID: webhook.ID,
+		PublicID:  webhook.PublicID,
+		Name:      webhook.Name,
+		Secret:    secret,
+		URL:       strings.TrimSuffix(uc.cfg.WebhookBaseURL, "/") + "/chat/hooks/" + webhook.PublicID + "/" + secret,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// GetWebhooks lists the incoming webhooks of a chat the caller participates
+// in. Secrets are not included.
+func (uc *useCase) GetWebhooks(ctx context.Context, req GetWebhooksReq) (*GetWebhooksResp, error) {
+	const op = "chatuc.GetWebhooks"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	webhooks, err := uc.chatRepo.GetWebhooks(ctx, chat.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]WebhookDTO, len(webhooks))
+	for i, w := range webhooks {
+		var lastUsedAt *string
+		if w.LastUsedAt != nil {
+			usedAt := w.LastUsedAt.Format(time.RFC3339)
+			lastUsedAt = &usedAt
+		}
+
+		dtos[i] = WebhookDTO{
+			WebhookID:  w.ID,
+			PublicID:   w.PublicID,
+			Name:       w.Name,
+			CreatorID:  w.CreatorID,
+			CreatedAt:  w.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: lastUsedAt,
+		}
+	}
+
+	return &GetWebhooksResp{Webhooks: dtos}, nil
+}
+
+// DeleteWebhook revokes an incoming webhook. Any participant of the chat may
+// revoke its webhooks, so a leaked URL doesn't depend on its creator.
+func (uc *useCase) DeleteWebhook(ctx context.Context, req DeleteWebhookReq) error {
+	const op = "chatuc.DeleteWebhook"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	chat, err := uc.participantChat(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.chatRepo.DeleteWebhook(ctx, chat.ID, req.WebhookID)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("webhook_id", "webhook not found"))
+	}
+
+	return nil
+}
+
+// participantChat returns a chat after checking the user participates in it.
+func (uc *useCase) participantChat(ctx context.Context, workspaceID, chatID, userID int) (*domain.Chat, error) {
+	const op = "chatuc.participantChat"
+
+	chat, err := uc.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, workspaceID, chat.ID, userID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	return chat, nil
+}
@@ -0,0 +1,278 @@
+package messageuc
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/llm"
+)
+
+// Cache kinds of assistant results.
+const (
+	assistantSummary = "summary"
+	assistantReplies = "replies"
+)
+
+// maxSuggestedReplies is how many suggested replies are returned at most.
+const maxSuggestedReplies = 3
+
+// listMarker matches a bullet or number some models put before each reply.
+var listMarker = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+const summaryInstructions = "You summarize chat conversations. Write a short, neutral summary of the " +
+	"conversation below in a few sentences, in the language most of it is written in. " +
+	"Mention decisions and open questions. Do not invent anything that is not in the conversation."
+
+const repliesInstructions = "You suggest replies in a chat. Given the conversation below, suggest up to three " +
+	"short replies the user named in the prompt could send next, in the language of the conversation. " +
+	"Write one reply per line, without numbering, quotes or any other text."
+
+// AssistantCache stores summaries and suggested replies shared by all API
+// instances. Results are keyed by the newest message they cover, so a new
+// message is answered again; edits of older messages are not noticed until
+// the entry expires.
+type AssistantCache interface {
+	GetAssistantResult(ctx context.Context, kind string, chatID, lastMessageID, userID int) (string, bool, error)
+	SetAssistantResult(
+		ctx context.Context,
+		kind string,
+		chatID, lastMessageID, userID int,
+		result string,
+		ttl time.Duration,
+	) error
+}
+
+func (uc *useCase) SummarizeChat(ctx context.Context, req SummarizeChatReq) (*SummarizeChatResp, error) {
+	const op = "messageuc.SummarizeChat"
+
+	if uc.assistant == nil {
+		return nil, domain.ErrAssistantDisabled
+	}
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	messages, err := uc.recentMessages(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	resp := &SummarizeChatResp{
+		ChatID:       req.ChatID,
+		MessageCount: len(messages),
+	}
+	if len(messages) == 0 {
+		return resp, nil
+	}
+	resp.LastMessageID = messages[len(messages)-1].ID
+
+	// Summaries don't depend on who asks, so all participants share one
+	summary, ok, err := uc.assistantCache.GetAssistantResult(ctx, assistantSummary, req.ChatID, resp.LastMessageID, 0)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if ok {
+		resp.Summary = summary
+		resp.Cached = true
+		return resp, nil
+	}
+
+	transcript, err := uc.transcript(ctx, messages)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	summary, err = uc.assistant.Complete(ctx, llm.Request{
+		System:    summaryInstructions,
+		Prompt:    transcript,
+		MaxTokens: 400,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	err = uc.assistantCache.SetAssistantResult(
+		ctx,
+		assistantSummary,
+		req.ChatID,
+		resp.LastMessageID,
+		0,
+		summary,
+		uc.cfg.AssistantCacheTTL,
+	)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	resp.Summary = summary
+	return resp, nil
+}
+
+func (uc *useCase) GetSuggestedReplies(
+	ctx context.Context,
+	req GetSuggestedRepliesReq,
+) (*GetSuggestedRepliesResp, error) {
+	const op = "messageuc.GetSuggestedReplies"
+
+	if uc.assistant == nil {
+		return nil, domain.ErrAssistantDisabled
+	}
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	messages, err := uc.recentMessages(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	resp := &GetSuggestedRepliesResp{
+		ChatID:  req.ChatID,
+		Replies: make([]string, 0),
+	}
+	if len(messages) == 0 {
+		return resp, nil
+	}
+	resp.LastMessageID = messages[len(messages)-1].ID
+
+	// Replies are written for the asking user, so they are cached per user
+	cached, ok, err := uc.assistantCache.GetAssistantResult(
+		ctx,
+		assistantReplies,
+		req.ChatID,
+		resp.LastMessageID,
+		authUser.ID,
+	)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if ok {
+		resp.Replies = parseReplies(cached)
+		resp.Cached = true
+		return resp, nil
+	}
+
+	transcript, err := uc.transcript(ctx, messages)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	user, err := uc.authPortal.GetUserByID(ctx, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	completion, err := uc.assistant.Complete(ctx, llm.Request{
+		System:    repliesInstructions,
+		Prompt:    "Suggest replies for " + user.Username + ".\n\n" + transcript,
+		MaxTokens: 200,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	resp.Replies = parseReplies(completion)
+
+	err = uc.assistantCache.SetAssistantResult(
+		ctx,
+		assistantReplies,
+		req.ChatID,
+		resp.LastMessageID,
+		authUser.ID,
+		strings.Join(resp.Replies, "\n"),
+		uc.cfg.AssistantCacheTTL,
+	)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return resp, nil
+}
+
+// recentMessages returns the chat's latest messages with content, oldest
+// first, after checking the user participates in the chat.
+func (uc *useCase) recentMessages(ctx context.Context, workspaceID, chatID, userID int) ([]domain.Message, error) {
+	const op = "messageuc.recentMessages"
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, workspaceID, chatID, userID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	messages, _, err := uc.messageRepo.ListWithCount(ctx, domain.MessageListParams{
+		ChatID:    chatID,
+		Order:     domain.SortDesc,
+		Limit:     uc.cfg.AssistantMaxMessages,
+		SkipTotal: true,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	// Anonymized messages have no content to pass on
+	messages = slices.DeleteFunc(messages, func(m domain.Message) bool {
+		return strings.TrimSpace(m.Content) == ""
+	})
+	slices.Reverse(messages)
+
+	return messages, nil
+}
+
+// transcript renders messages as "username: content" lines for the provider.
+func (uc *useCase) transcript(ctx context.Context, messages []domain.Message) (string, error) {
+	const op = "messageuc.transcript"
+
+	senderIDs := make([]int, len(messages))
+	for i, msg := range messages {
+		senderIDs[i] = msg.SenderID
+	}
+	senders, err := uc.usersByID(ctx, senderIDs)
+	if err != nil {
+		return "", errs.Wrap(op, err)
+	}
+
+	var b strings.Builder
+	for _, msg := range messages {
+		var name string
+		if sender, ok := senders[msg.SenderID]; ok {
+			name = sender.Username
+		}
+
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strings.ReplaceAll(msg.Content, "\n", " "))
+		b.WriteString("\n")
+	}
+
+	return b.String(), nil
+}
+
+// parseReplies splits a completion into replies, dropping list markers and
+// quotes models add despite the instructions.
+func parseReplies(completion string) []string {
+	replies := make([]string, 0, maxSuggestedReplies)
+	for line := range strings.Lines(completion) {
+		line = listMarker.ReplaceAllString(strings.TrimSpace(line), "")
+		line = strings.TrimSpace(strings.Trim(line, `"“”`))
+		if line == "" {
+			continue
+		}
+
+		replies = append(replies, line)
+		if len(replies) == maxSuggestedReplies {
+			break
+		}
+	}
+	return replies
+}
@@ -0,0 +1,205 @@
+package messageuc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// UploadAttachment stores a file for a message in a chat the user takes
+// part in. The file is only visible to participants of that chat.
+func (uc *useCase) UploadAttachment(ctx context.Context, req UploadAttachmentReq) (*UploadAttachmentResp, error) {
+	const op = "messageuc.UploadAttachment"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	contentType := attachmentContentType(req.ContentType)
+	if !slices.Contains(uc.cfg.AttachmentTypes, contentType) {
+		return nil, errs.Wrap(op, errs.AddFieldError(nil, "file", "file type is not allowed"))
+	}
+	if req.Size > uc.cfg.MaxAttachmentSize {
+		return nil, errs.Wrap(op, errs.AddFieldError(
+			nil,
+			"file",
+			fmt.Sprintf("file must be %d bytes or less", uc.cfg.MaxAttachmentSize),
+		))
+	}
+
+	filePath, err := domain.NewAttachmentPath(req.ChatID, req.FileName)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	err = uc.fileStore.Upload(ctx, filePath, bytes.NewReader(req.File), req.Size, contentType)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dto := attachmentDTO(domain.Attachment{
+		Path:        filePath,
+		Name:        path.Base(filePath),
+		ContentType: contentType,
+		Size:        req.Size,
+	})
+
+	return &UploadAttachmentResp{
+		Path:        dto.Path,
+		Name:        dto.Name,
+		ContentType: dto.ContentType,
+		Size:        dto.Size,
+	}, nil
+}
+
+// DownloadAttachment returns an attachment to a participant of the chat it
+// was uploaded to.
+func (uc *useCase) DownloadAttachment(ctx context.Context, req DownloadAttachmentReq) (*DownloadAttachmentResp, error) {
+	const op = "messageuc.DownloadAttachment"
+
+	errAttachmentNotFound := errs.NewNotFoundError("attachment_path", "attachment not found")
+
+	filePath := domain.AttachmentPathPrefix + req.Path
+	chatID, ok := domain.AttachmentChatID(filePath)
+	if !ok {
+		return nil, errAttachmentNotFound
+	}
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	// Outsiders can't tell attachments of other chats from missing ones
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, chatID, authUser.ID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errAttachmentNotFound)
+	}
+	if !isParticipant {
+		return nil, errAttachmentNotFound
+	}
+
+	exists, err := uc.fileStore.Exists(ctx, filePath)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !exists {
+		return nil, errAttachmentNotFound
+	}
+
+	info, err := uc.fileStore.Stat(ctx, filePath)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	reader, err := uc.fileStore.Download(ctx, filePath)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	defer reader.Close()
+
+	fileData, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &DownloadAttachmentResp{
+		File:        fileData,
+		ContentType: info.ContentType,
+		FileName:    path.Base(filePath),
+	}, nil
+}
+
+// resolveAttachments looks up the uploaded files a message is sent with.
+// Each must have been uploaded to the message's chat.
+func (uc *useCase) resolveAttachments(ctx context.Context, chatID int, paths []string) ([]domain.Attachment, error) {
+	attachments := make([]domain.Attachment, 0, len(paths))
+	for _, p := range paths {
+		filePath := domain.AttachmentPathPrefix + strings.TrimPrefix(p, "/")
+		if id, ok := domain.AttachmentChatID(filePath); !ok || id != chatID {
+			return nil, errs.AddFieldError(nil, "attachments", "attachment was not uploaded to this chat")
+		}
+		if slices.ContainsFunc(attachments, func(a domain.Attachment) bool { return a.Path == filePath }) {
+			continue
+		}
+
+		exists, err := uc.fileStore.Exists(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errs.NewNotFoundError("attachments", "attachment not found")
+		}
+
+		info, err := uc.fileStore.Stat(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, domain.Attachment{
+			Path:        filePath,
+			Name:        path.Base(filePath),
+			ContentType: info.ContentType,
+			Size:        info.Size,
+		})
+	}
+	return attachments, nil
+}
+
+// attachmentContentType returns the media type of a Content-Type header
+// without its parameters.
+func attachmentContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+func attachmentDTO(a domain.Attachment) AttachmentDTO {
+	return AttachmentDTO{
+		Path:        strings.TrimPrefix(a.Path, domain.AttachmentPathPrefix),
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+	}
+}
+
+func attachmentDTOs(attachments []domain.Attachment) []AttachmentDTO {
+	dtos := make([]AttachmentDTO, len(attachments))
+	for i, a := range attachments {
+		dtos[i] = attachmentDTO(a)
+	}
+	return dtos
+}
+
+func attachmentPayloads(attachments []domain.Attachment) []ws.AttachmentPayload {
+	payloads := make([]ws.AttachmentPayload, len(attachments))
+	for i, a := range attachments {
+		payloads[i] = ws.AttachmentPayload{
+			Path:        strings.TrimPrefix(a.Path, domain.AttachmentPathPrefix),
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+		}
+	}
+	return payloads
+}
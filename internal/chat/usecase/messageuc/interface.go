@@ -1,21 +1,43 @@
 package messageuc
 
 import (
+	"chatx-01-backend/internal/chat/domain"
 	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/translate"
 	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 type UseCase interface {
 	GetMessagesList(ctx context.Context, req GetMessagesListReq) (*GetMessagesListResp, error)
+	SearchMessages(ctx context.Context, req SearchMessagesReq) (*SearchMessagesResp, error)
+	SearchChatMessages(ctx context.Context, req SearchChatMessagesReq) (*SearchMessagesResp, error)
 	SendMessage(ctx context.Context, req SendMessageReq) (*SendMessageResp, error)
 	EditMessage(ctx context.Context, req EditMessageReq) error
 	DeleteMessage(ctx context.Context, req DeleteMessageReq) error
+	AddReaction(ctx context.Context, req AddReactionReq) error
+	RemoveReaction(ctx context.Context, req RemoveReactionReq) error
+	ListReactions(ctx context.Context, req ListReactionsReq) (*ListReactionsResp, error)
+	UploadAttachment(ctx context.Context, req UploadAttachmentReq) (*UploadAttachmentResp, error)
+	DownloadAttachment(ctx context.Context, req DownloadAttachmentReq) (*DownloadAttachmentResp, error)
+	GetCapabilities(ctx context.Context, req GetCapabilitiesReq) (*GetCapabilitiesResp, error)
+	TranslateMessage(ctx context.Context, req TranslateMessageReq) (*TranslateMessageResp, error)
+	SummarizeChat(ctx context.Context, req SummarizeChatReq) (*SummarizeChatResp, error)
+	GetSuggestedReplies(ctx context.Context, req GetSuggestedRepliesReq) (*GetSuggestedRepliesResp, error)
+	PostWebhookMessage(ctx context.Context, req PostWebhookMessageReq) (*SendMessageResp, error)
+	ApplyRetention(ctx context.Context) (*RetentionResult, error)
 }
 
 type GetMessagesListReq struct {
-	ChatID int `path:"chat_id"`
-	Page   int `query:"page"`
-	Limit  int `query:"limit"`
+	ChatID   int    `path:"chat_id"`
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+	Order    string `query:"order"`
+	BeforeID int    `query:"before_id"`
+	AfterID  int    `query:"after_id"`
+	Total    string `query:"total"`
 }
 
 func (req GetMessagesListReq) Validate() error {
@@ -30,31 +52,142 @@ func (req GetMessagesListReq) Validate() error {
 	if req.Limit <= 0 || req.Limit > 100 {
 		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
 	}
+	if req.Order != "" && req.Order != string(domain.SortAsc) && req.Order != string(domain.SortDesc) {
+		verr = errs.AddFieldError(verr, "order", "order must be asc or desc")
+	}
+	if req.BeforeID < 0 {
+		verr = errs.AddFieldError(verr, "before_id", "invalid message id")
+	}
+	if req.AfterID < 0 {
+		verr = errs.AddFieldError(verr, "after_id", "invalid message id")
+	}
+	if req.BeforeID > 0 && req.AfterID > 0 && req.AfterID >= req.BeforeID {
+		verr = errs.AddFieldError(verr, "after_id", "after_id must be less than before_id")
+	}
+	if req.Total != "" && !domain.TotalMode(req.Total).IsValid() {
+		verr = errs.AddFieldError(verr, "total", "total must be exact or none")
+	}
 
 	return verr
 }
 
 type GetMessagesListResp struct {
 	Messages []MessageDTO `json:"messages"`
-	Total    int          `json:"total"`
+	Total    *int         `json:"total,omitempty"` // Omitted with total=none
+	HasMore  bool         `json:"has_more"`
 	Page     int          `json:"page"`
 	Limit    int          `json:"limit"`
 }
 
+type SearchMessagesReq struct {
+	Query string `query:"q"`
+	Page  int    `query:"page"`
+	Limit int    `query:"limit" default:"20"`
+}
+
+func (req SearchMessagesReq) Validate() error {
+	return validateSearch(nil, req.Query, req.Page, req.Limit)
+}
+
+type SearchChatMessagesReq struct {
+	ChatID int    `path:"chat_id"`
+	Query  string `query:"q"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit" default:"20"`
+}
+
+func (req SearchChatMessagesReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return validateSearch(verr, req.Query, req.Page, req.Limit)
+}
+
+func validateSearch(verr error, query string, page, limit int) error {
+	if strings.TrimSpace(query) == "" {
+		verr = errs.AddFieldError(verr, "q", "search query is required")
+	}
+	if utf8.RuneCountInString(query) > domain.MaxSearchQueryLength {
+		verr = errs.AddFieldError(
+			verr,
+			"q",
+			fmt.Sprintf("search query must be %d characters or less", domain.MaxSearchQueryLength),
+		)
+	}
+	if page < 0 {
+		verr = errs.AddFieldError(verr, "page", "page must be non-negative")
+	}
+	if limit <= 0 || limit > 100 {
+		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
+	}
+
+	return verr
+}
+
+type SearchMessagesResp struct {
+	Messages []SearchResultDTO `json:"messages"` // Newest first
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	Limit    int               `json:"limit"`
+}
+
+// SearchResultDTO is a matching message with an excerpt of its content
+// around the matched words.
+type SearchResultDTO struct {
+	MessageDTO
+	Snippet    string         `json:"snippet"`
+	Highlights []HighlightDTO `json:"highlights"`
+}
+
+// HighlightDTO is a matched word in a snippet. Offset and Length count
+// Unicode code points.
+type HighlightDTO struct {
+	Offset int `json:"offset"`
+	Length int `json:"length"`
+}
+
 type MessageDTO struct {
-	MessageID   int     `json:"message_id"`
-	ChatID      int     `json:"chat_id"`
-	SenderID    int     `json:"sender_id"`
-	SenderName  string  `json:"sender_name"`
-	SenderImage *string `json:"sender_image,omitempty"`
-	Content     string  `json:"content"`
-	SentAt      string  `json:"sent_at"`
-	EditedAt    *string `json:"edited_at,omitempty"`
+	MessageID         int             `json:"message_id"`
+	PublicID          string          `json:"public_id"`
+	ChatID            int             `json:"chat_id"`
+	SenderID          int             `json:"sender_id"`
+	SenderName        string          `json:"sender_name"`
+	SenderDisplayName string          `json:"sender_display_name,omitempty"`
+	SenderImage       *string         `json:"sender_image,omitempty"`
+	Content           string          `json:"content"`
+	Entities          []EntityDTO     `json:"entities"`
+	Attachments       []AttachmentDTO `json:"attachments"`
+	Metadata          domain.Metadata `json:"metadata,omitempty"`
+	SentAt            string          `json:"sent_at"`
+	EditedAt          *string         `json:"edited_at,omitempty"`
+}
+
+// EntityDTO is a formatted range of message content. Offset and Length count
+// Unicode code points; style entities include their markdown delimiters.
+type EntityDTO struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
+}
+
+// AttachmentDTO is a file sent with a message. Path is relative to the
+// attachment download endpoint.
+type AttachmentDTO struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
 }
 
 type SendMessageReq struct {
-	ChatID  int    `json:"chat_id"`
-	Content string `json:"content"`
+	ChatID      int             `json:"chat_id"`
+	Content     string          `json:"content"`
+	Attachments []string        `json:"attachments,omitempty"` // Paths returned by the upload endpoint
+	Metadata    domain.Metadata `json:"metadata,omitempty"`
 }
 
 func (req SendMessageReq) Validate() error {
@@ -63,11 +196,18 @@ func (req SendMessageReq) Validate() error {
 	if req.ChatID <= 0 {
 		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
 	}
-	if req.Content == "" {
+	if req.Content == "" && len(req.Attachments) == 0 {
 		verr = errs.AddFieldError(verr, "content", "message content is required")
 	}
-	if len(req.Content) > 5000 {
-		verr = errs.AddFieldError(verr, "content", "message content must be 5000 characters or less")
+	if len(req.Attachments) > domain.MaxAttachments {
+		verr = errs.AddFieldError(
+			verr,
+			"attachments",
+			fmt.Sprintf("a message can have at most %d attachments", domain.MaxAttachments),
+		)
+	}
+	if err := domain.ValidateClientMetadata(req.Metadata); err != nil {
+		verr = errs.AddFieldError(verr, "metadata", err.Error())
 	}
 
 	return verr
@@ -75,6 +215,7 @@ func (req SendMessageReq) Validate() error {
 
 type SendMessageResp struct {
 	MessageID int    `json:"message_id"`
+	PublicID  string `json:"public_id"`
 	SentAt    string `json:"sent_at"`
 }
 
@@ -92,9 +233,6 @@ func (req EditMessageReq) Validate() error {
 	if req.Content == "" {
 		verr = errs.AddFieldError(verr, "content", "message content is required")
 	}
-	if len(req.Content) > 5000 {
-		verr = errs.AddFieldError(verr, "content", "message content must be 5000 characters or less")
-	}
 
 	return verr
 }
@@ -112,3 +250,231 @@ func (req DeleteMessageReq) Validate() error {
 
 	return verr
 }
+
+type AddReactionReq struct {
+	MessageID int    `path:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+func (req AddReactionReq) Validate() error {
+	var verr error
+
+	if req.MessageID <= 0 {
+		verr = errs.AddFieldError(verr, "message_id", "invalid message id")
+	}
+	if !domain.ValidReactionEmoji(req.Emoji) {
+		verr = errs.AddFieldError(verr, "emoji", "invalid emoji")
+	}
+
+	return verr
+}
+
+type RemoveReactionReq struct {
+	MessageID int    `path:"message_id"`
+	Emoji     string `path:"emoji"`
+}
+
+func (req RemoveReactionReq) Validate() error {
+	var verr error
+
+	if req.MessageID <= 0 {
+		verr = errs.AddFieldError(verr, "message_id", "invalid message id")
+	}
+	if !domain.ValidReactionEmoji(req.Emoji) {
+		verr = errs.AddFieldError(verr, "emoji", "invalid emoji")
+	}
+
+	return verr
+}
+
+type ListReactionsReq struct {
+	MessageID int `path:"message_id"`
+}
+
+func (req ListReactionsReq) Validate() error {
+	var verr error
+
+	if req.MessageID <= 0 {
+		verr = errs.AddFieldError(verr, "message_id", "invalid message id")
+	}
+
+	return verr
+}
+
+type ListReactionsResp struct {
+	MessageID int           `json:"message_id"`
+	Reactions []ReactionDTO `json:"reactions"` // In the order each emoji was first used
+}
+
+type ReactionDTO struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	UserIDs []int  `json:"user_ids"` // In the order they reacted
+	Reacted bool   `json:"reacted"`  // The caller reacted with this emoji
+}
+
+type UploadAttachmentReq struct {
+	ChatID      int    `json:"-"`
+	File        []byte `json:"-"`
+	FileName    string `json:"-"`
+	ContentType string `json:"-"`
+	Size        int64  `json:"-"`
+}
+
+func (req UploadAttachmentReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+	if len(req.File) == 0 {
+		verr = errs.AddFieldError(verr, "file", "file is required")
+	}
+	if req.FileName == "" {
+		verr = errs.AddFieldError(verr, "file_name", "file name is required")
+	}
+	if req.Size <= 0 {
+		verr = errs.AddFieldError(verr, "size", "invalid file size")
+	}
+
+	return verr
+}
+
+// UploadAttachmentResp describes the uploaded file. Its path is sent in
+// SendMessageReq.Attachments.
+type UploadAttachmentResp struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type DownloadAttachmentReq struct {
+	Path string `path:"attachment_path"`
+}
+
+func (req DownloadAttachmentReq) Validate() error {
+	var verr error
+
+	if req.Path == "" {
+		verr = errs.AddFieldError(verr, "attachment_path", "attachment path is required")
+	}
+
+	return verr
+}
+
+type DownloadAttachmentResp struct {
+	File        []byte
+	ContentType string
+	FileName    string
+}
+
+type GetCapabilitiesReq struct{}
+
+func (req GetCapabilitiesReq) Validate() error {
+	return nil
+}
+
+type GetCapabilitiesResp struct {
+	MaxMessageLength  int   `json:"max_message_length"`
+	MaxAttachmentSize int64 `json:"max_attachment_size"` // bytes
+	Assistant         bool  `json:"assistant"`           // Chat summaries and suggested replies are available
+}
+
+type TranslateMessageReq struct {
+	MessageID  int    `path:"message_id"`
+	TargetLang string `json:"target_lang"`
+}
+
+func (req TranslateMessageReq) Validate() error {
+	var verr error
+
+	if req.MessageID <= 0 {
+		verr = errs.AddFieldError(verr, "message_id", "invalid message id")
+	}
+	if _, err := translate.NormalizeLanguage(req.TargetLang); err != nil {
+		verr = errs.AddFieldError(verr, "target_lang", err.Error())
+	}
+
+	return verr
+}
+
+type TranslateMessageResp struct {
+	MessageID  int    `json:"message_id"`
+	TargetLang string `json:"target_lang"`
+	SourceLang string `json:"source_lang"` // Detected by the provider
+	Text       string `json:"text"`
+	Cached     bool   `json:"cached"`
+}
+
+type SummarizeChatReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req SummarizeChatReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type SummarizeChatResp struct {
+	ChatID        int    `json:"chat_id"`
+	Summary       string `json:"summary"`
+	MessageCount  int    `json:"message_count"`   // Recent messages the summary covers
+	LastMessageID int    `json:"last_message_id"` // Newest message the summary covers
+	Cached        bool   `json:"cached"`
+}
+
+type GetSuggestedRepliesReq struct {
+	ChatID int `path:"chat_id"`
+}
+
+func (req GetSuggestedRepliesReq) Validate() error {
+	var verr error
+
+	if req.ChatID <= 0 {
+		verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+	}
+
+	return verr
+}
+
+type GetSuggestedRepliesResp struct {
+	ChatID        int      `json:"chat_id"`
+	Replies       []string `json:"replies"`
+	LastMessageID int      `json:"last_message_id"` // Newest message the replies answer
+	Cached        bool     `json:"cached"`
+}
+
+// PostWebhookMessageReq is sent by an external system; the webhook's public
+// ID and secret in the path authenticate it.
+type PostWebhookMessageReq struct {
+	HookID   string          `path:"hook_id"`
+	Secret   string          `path:"secret"`
+	Content  string          `json:"content"`
+	Metadata domain.Metadata `json:"metadata,omitempty"`
+}
+
+func (req PostWebhookMessageReq) Validate() error {
+	var verr error
+
+	if req.Content == "" {
+		verr = errs.AddFieldError(verr, "content", "message content is required")
+	}
+	if err := domain.ValidateClientMetadata(req.Metadata); err != nil {
+		verr = errs.AddFieldError(verr, "metadata", err.Error())
+	}
+
+	return verr
+}
+
+// RetentionResult reports a run of the retention job.
+type RetentionResult struct {
+	Mode     domain.RetentionMode
+	DryRun   bool
+	Messages int // Deleted or anonymized, or expired in a dry run
+}
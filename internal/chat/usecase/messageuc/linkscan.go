@@ -0,0 +1,205 @@
+package messageuc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+const (
+	// linkScanQueueSize is how many messages may wait for a scan; messages
+	// beyond it are not scanned.
+	linkScanQueueSize = 1000
+	linkScanWorkers   = 4
+	linkScanTimeout   = 30 * time.Second
+	// maxScannedLinks is how many links of one message are scanned.
+	maxScannedLinks = 20
+)
+
+// LinkScanCache stores scan results of URLs shared by all API instances.
+// Safe URLs are cached with an empty threat.
+type LinkScanCache interface {
+	GetLinkThreat(ctx context.Context, url string) (string, bool, error)
+	SetLinkThreat(ctx context.Context, url, threat string, ttl time.Duration) error
+}
+
+type linkScanJob struct {
+	messageID int
+	// editedAt identifies the revision whose links are scanned
+	editedAt *time.Time
+	urls     []string
+}
+
+// scanLinks queues the links of a message that was just created or edited
+// for scanning. A message without links is only queued to clear an earlier
+// warning.
+func (uc *useCase) scanLinks(ctx context.Context, message *domain.Message) {
+	if uc.linkScanner == nil {
+		return
+	}
+
+	urls := messageURLs(message)
+	if _, warned := message.Metadata[domain.MetadataLinkWarning]; len(urls) == 0 && !warned {
+		return
+	}
+
+	select {
+	case uc.linkJobs <- linkScanJob{messageID: message.ID, editedAt: message.EditedAt, urls: urls}:
+	default:
+		uc.logger.WarnContext(ctx, "link scan queue full, skipping message", "message_id", message.ID)
+	}
+}
+
+func (uc *useCase) runLinkScans() {
+	for job := range uc.linkJobs {
+		if err := uc.processLinkScan(job); err != nil {
+			uc.logger.Warn("failed to scan message links", "message_id", job.messageID, "error", err)
+		}
+	}
+}
+
+// processLinkScan scans the links of a message and sets or clears its link
+// warning, notifying the chat if the warning changed.
+func (uc *useCase) processLinkScan(job linkScanJob) error {
+	const op = "messageuc.processLinkScan"
+
+	// Not tied to the request, which has usually finished by now
+	ctx, cancel := context.WithTimeout(context.Background(), linkScanTimeout)
+	defer cancel()
+
+	threats, err := uc.linkThreats(ctx, job.urls)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	warning := domain.LinkWarningMetadata{}
+	for _, url := range job.urls {
+		if threat, ok := threats[url]; ok {
+			warning.Links = append(warning.Links, domain.UnsafeLink{URL: url, Threat: threat})
+		}
+	}
+
+	message, err := uc.messageRepo.GetByID(ctx, job.messageID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil // Deleted in the meantime
+		}
+		return errs.Wrap(op, err)
+	}
+
+	// An edit in the meantime queued a scan of its own links
+	if !sameTime(message.EditedAt, job.editedAt) {
+		return nil
+	}
+
+	var current domain.LinkWarningMetadata
+	if raw, ok := message.Metadata[domain.MetadataLinkWarning]; ok {
+		if err := json.Unmarshal(raw, &current); err != nil {
+			return errs.Wrap(op, err)
+		}
+	}
+	if slices.Equal(current.Links, warning.Links) {
+		return nil
+	}
+
+	var value json.RawMessage
+	if len(warning.Links) > 0 {
+		value, err = json.Marshal(warning)
+		if err != nil {
+			return errs.Wrap(op, err)
+		}
+	}
+
+	if err := uc.messageRepo.SetMetadataKey(ctx, message.ID, domain.MetadataLinkWarning, value); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	if message.Metadata == nil {
+		message.Metadata = make(domain.Metadata)
+	}
+	if value == nil {
+		delete(message.Metadata, domain.MetadataLinkWarning)
+	} else {
+		message.Metadata[domain.MetadataLinkWarning] = value
+	}
+	uc.broadcaster.BroadcastMessageUpdated(messagePayload(message))
+
+	return nil
+}
+
+// linkThreats returns the threat type of every unsafe URL in urls, scanning
+// only the URLs without a cached result.
+func (uc *useCase) linkThreats(ctx context.Context, urls []string) (map[string]string, error) {
+	const op = "messageuc.linkThreats"
+
+	threats := make(map[string]string)
+	var unknown []string
+	for _, url := range urls {
+		threat, ok, err := uc.linkCache.GetLinkThreat(ctx, url)
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+		if !ok {
+			unknown = append(unknown, url)
+		} else if threat != "" {
+			threats[url] = threat
+		}
+	}
+	if len(unknown) == 0 {
+		return threats, nil
+	}
+
+	scanned, err := uc.linkScanner.Scan(ctx, unknown)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	for _, url := range unknown {
+		threat := scanned[url]
+		if threat != "" {
+			threats[url] = threat
+		}
+		if err := uc.linkCache.SetLinkThreat(ctx, url, threat, uc.cfg.LinkScanCacheTTL); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+	}
+
+	return threats, nil
+}
+
+// messageURLs returns the distinct links in a message's content and link
+// preview, at most maxScannedLinks of them.
+func messageURLs(message *domain.Message) []string {
+	var urls []string
+	add := func(url string) {
+		if url != "" && len(urls) < maxScannedLinks && !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+
+	for _, e := range message.Entities {
+		add(e.URL)
+	}
+	if raw, ok := message.Metadata[domain.MetadataLinkPreview]; ok {
+		var preview domain.LinkPreviewMetadata
+		if json.Unmarshal(raw, &preview) == nil {
+			add(preview.URL)
+		}
+	}
+
+	return urls
+}
+
+// sameTime compares edit timestamps at the microsecond precision Postgres
+// stores them with.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+}
@@ -0,0 +1,205 @@
+package messageuc
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/portal/notifications"
+	"chatx-01-backend/pkg/markup"
+)
+
+// mentionPreviewLength is how many characters of a message the inbox item of
+// a mention shows.
+const mentionPreviewLength = 100
+
+// notifyMentions records the participants of the chat mentioned in the
+// message, except its sender, notifies them over WebSocket and adds the
+// mention to their inbox. Participants who muted the chat are only recorded.
+// Failures are logged, the message has been sent already.
+func (uc *useCase) notifyMentions(ctx context.Context, workspaceID int, message *domain.Message) {
+	usernames := mentionedUsernames(message)
+	if len(usernames) == 0 {
+		return
+	}
+
+	recipients, err := uc.mentionedParticipants(ctx, message, usernames)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "failed to resolve mentions", "message_id", message.ID, "error", err)
+		return
+	}
+
+	uc.recordMentions(ctx, workspaceID, message, recipients)
+}
+
+// syncMentions brings the mentions of an edited message in line with its new
+// content: mentions it lost are deleted, so they no longer count as unread,
+// and participants it newly mentions are recorded and notified as for a new
+// message. Failures are logged, the edit has been saved already.
+func (uc *useCase) syncMentions(ctx context.Context, workspaceID int, message *domain.Message) {
+	stored, err := uc.mentionRepo.ListUserIDs(ctx, message.ID)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "failed to list mentions", "message_id", message.ID, "error", err)
+		return
+	}
+
+	var recipients []domain.ChatParticipant
+	if usernames := mentionedUsernames(message); len(usernames) > 0 {
+		recipients, err = uc.mentionedParticipants(ctx, message, usernames)
+		if err != nil {
+			uc.logger.WarnContext(ctx, "failed to resolve mentions", "message_id", message.ID, "error", err)
+			return
+		}
+	}
+
+	current := make(map[int]struct{}, len(recipients))
+	added := make([]domain.ChatParticipant, 0, len(recipients))
+	for _, p := range recipients {
+		current[p.UserID] = struct{}{}
+		if !slices.Contains(stored, p.UserID) {
+			added = append(added, p)
+		}
+	}
+
+	removed := make([]int, 0, len(stored))
+	for _, userID := range stored {
+		if _, ok := current[userID]; !ok {
+			removed = append(removed, userID)
+		}
+	}
+	if len(removed) > 0 {
+		if err := uc.mentionRepo.Delete(ctx, message.ID, removed); err != nil {
+			uc.logger.WarnContext(ctx, "failed to delete mentions", "message_id", message.ID, "error", err)
+		}
+	}
+
+	uc.recordMentions(ctx, workspaceID, message, added)
+}
+
+// recordMentions stores the mentions of recipients in a message and notifies
+// those who didn't mute the chat.
+func (uc *useCase) recordMentions(ctx context.Context, workspaceID int, message *domain.Message, recipients []domain.ChatParticipant) {
+	if len(recipients) == 0 {
+		return
+	}
+
+	now := time.Now()
+	mentions := make([]domain.Mention, len(recipients))
+	notified := make([]int, 0, len(recipients))
+	for i, p := range recipients {
+		mentions[i] = domain.Mention{
+			MessageID: message.ID,
+			ChatID:    message.ChatID,
+			UserID:    p.UserID,
+			CreatedAt: now,
+		}
+		if !p.IsMuted(now) {
+			notified = append(notified, p.UserID)
+		}
+	}
+	if err := uc.mentionRepo.Create(ctx, mentions); err != nil {
+		uc.logger.WarnContext(ctx, "failed to store mentions", "message_id", message.ID, "error", err)
+	}
+	if len(notified) == 0 {
+		return
+	}
+
+	uc.broadcaster.BroadcastMention(notified, ws.MentionPayload{
+		ChatID:    message.ChatID,
+		MessageID: message.ID,
+		SenderID:  message.SenderID,
+	})
+
+	items := make([]notifications.Notification, 0, len(notified))
+	for _, userID := range notified {
+		items = append(items, notifications.Notification{
+			WorkspaceID: workspaceID,
+			UserID:      userID,
+			Type:        notifications.TypeMention,
+			ActorID:     message.SenderID,
+			ChatID:      message.ChatID,
+			MessageID:   message.ID,
+			Text:        preview(message.Content, mentionPreviewLength),
+		})
+	}
+
+	if err := uc.notificationPr.Notify(ctx, items); err != nil {
+		uc.logger.WarnContext(ctx, "failed to notify mentioned users", "message_id", message.ID, "error", err)
+	}
+}
+
+// mentionedParticipants returns the chat participants whose username is in
+// usernames, which must be lowercase.
+func (uc *useCase) mentionedParticipants(
+	ctx context.Context,
+	message *domain.Message,
+	usernames map[string]struct{},
+) ([]domain.ChatParticipant, error) {
+	participants, err := uc.chatRepo.GetParticipants(ctx, message.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	byUserID := make(map[int]domain.ChatParticipant, len(participants))
+	userIDs := make([]int, 0, len(participants))
+	for _, p := range participants {
+		if p.UserID != message.SenderID {
+			byUserID[p.UserID] = p
+			userIDs = append(userIDs, p.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	users, err := uc.authPortal.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]domain.ChatParticipant, 0, len(usernames))
+	for _, user := range users {
+		if _, ok := usernames[strings.ToLower(user.Username)]; ok {
+			recipients = append(recipients, byUserID[user.ID])
+		}
+	}
+
+	return recipients, nil
+}
+
+// mentionedUsernames returns the lowercased usernames of the mention entities
+// of a message. Usernames are matched case-insensitively.
+func mentionedUsernames(message *domain.Message) map[string]struct{} {
+	var runes []rune
+
+	usernames := make(map[string]struct{})
+	for _, e := range message.Entities {
+		if e.Type != string(markup.EntityMention) {
+			continue
+		}
+		if runes == nil {
+			runes = []rune(message.Content)
+		}
+		if e.Offset+e.Length > len(runes) {
+			continue
+		}
+
+		// Skip the leading '@'
+		username := string(runes[e.Offset+1 : e.Offset+e.Length])
+		usernames[strings.ToLower(username)] = struct{}{}
+	}
+
+	return usernames
+}
+
+// preview shortens text to at most n characters.
+func preview(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,150 @@
+package messageuc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/internal/chat/infra"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/internal/portal/notifications"
+	"chatx-01-backend/pkg/snowflake"
+)
+
+// mentionAuthPortal serves the authenticated user and the users of the test.
+// Methods the tests don't use panic through the nil embedded Portal.
+type mentionAuthPortal struct {
+	auth.Portal
+	authUser auth.AuthenticatedUser
+	users    []*auth.User
+}
+
+func (p *mentionAuthPortal) GetAuthUser(ctx context.Context) (auth.AuthenticatedUser, error) {
+	return p.authUser, nil
+}
+
+func (p *mentionAuthPortal) GetUsersByIDs(ctx context.Context, ids []int) ([]*auth.User, error) {
+	var users []*auth.User
+	for _, u := range p.users {
+		if slices.Contains(ids, u.ID) {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+type mentionBroadcaster struct {
+	ws.NopBroadcaster
+	mentioned []int
+}
+
+func (b *mentionBroadcaster) BroadcastMention(userIDs []int, mention ws.MentionPayload) {
+	b.mentioned = append(b.mentioned, userIDs...)
+}
+
+type mentionNotifier struct {
+	notified []int
+}
+
+func (n *mentionNotifier) Notify(ctx context.Context, items []notifications.Notification) error {
+	for _, item := range items {
+		n.notified = append(n.notified, item.UserID)
+	}
+	return nil
+}
+
+func TestEditMessageSyncsMentions(t *testing.T) {
+	const (
+		workspaceID = 1
+		senderID    = 10
+		aliceID     = 11
+		bobID       = 12
+		carolID     = 13
+	)
+	ctx := context.Background()
+
+	ids, err := snowflake.New(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := infra.NewMemStore()
+	chatRepo := infra.NewMemChatRepo(store)
+	messageRepo := infra.NewMemMessageRepo(store, ids)
+	mentionRepo := infra.NewMemMentionRepo(store)
+
+	wsID := workspaceID
+	chat := &domain.Chat{Type: domain.ChatTypeGroup, Name: "team", CreatorID: senderID, WorkspaceID: &wsID, CreatedAt: time.Now()}
+	if err := chatRepo.Create(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []int{senderID, aliceID, bobID, carolID} {
+		if err := chatRepo.AddParticipant(ctx, &domain.ChatParticipant{ChatID: chat.ID, UserID: userID, JoinedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	broadcaster := &mentionBroadcaster{}
+	notifier := &mentionNotifier{}
+	uc := &useCase{
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		mentionRepo: mentionRepo,
+		authPortal: &mentionAuthPortal{
+			authUser: auth.AuthenticatedUser{ID: senderID, WorkspaceID: workspaceID},
+			users: []*auth.User{
+				{ID: senderID, Username: "sender"},
+				{ID: aliceID, Username: "alice"},
+				{ID: bobID, Username: "bob"},
+				{ID: carolID, Username: "carol"},
+			},
+		},
+		notificationPr: notifier,
+		broadcaster:    broadcaster,
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cfg:            Config{MaxMessageLength: 4096},
+	}
+
+	content := "hi @alice and @bob"
+	message := &domain.Message{ChatID: chat.ID, SenderID: senderID, Content: content, Entities: parseEntities(content)}
+	if err := messageRepo.Create(ctx, message); err != nil {
+		t.Fatal(err)
+	}
+	uc.notifyMentions(ctx, workspaceID, message)
+	broadcaster.mentioned, notifier.notified = nil, nil
+
+	if err := uc.EditMessage(ctx, EditMessageReq{MessageID: message.ID, Content: "hi @Bob and @carol"}); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+
+	stored, err := mentionRepo.ListUserIDs(ctx, message.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(stored)
+	if want := []int{bobID, carolID}; !slices.Equal(stored, want) {
+		t.Errorf("stored mentions = %v, want %v", stored, want)
+	}
+	if want := []int{carolID}; !slices.Equal(broadcaster.mentioned, want) {
+		t.Errorf("mention events sent to %v, want %v", broadcaster.mentioned, want)
+	}
+	if want := []int{carolID}; !slices.Equal(notifier.notified, want) {
+		t.Errorf("mention notifications sent to %v, want %v", notifier.notified, want)
+	}
+
+	// Removing every mention leaves none counting as unread
+	if err := uc.EditMessage(ctx, EditMessageReq{MessageID: message.ID, Content: "hi all"}); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+	unread, err := mentionRepo.CountUnreadByChat(ctx, chat.ID, bobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unread != 0 {
+		t.Errorf("unread mentions of bob = %d, want 0", unread)
+	}
+}
@@ -0,0 +1,160 @@
+package messageuc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// AddReaction reacts to a message with an emoji. Reacting again with the
+// same emoji does nothing.
+func (uc *useCase) AddReaction(ctx context.Context, req AddReactionReq) error {
+	const op = "messageuc.AddReaction"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	message, err := uc.participantMessage(ctx, authUser.WorkspaceID, req.MessageID, authUser.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	reactions, err := uc.reactionRepo.ListByMessage(ctx, message.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	own := 0
+	for _, r := range reactions {
+		if r.UserID != authUser.ID {
+			continue
+		}
+		if r.Emoji == req.Emoji {
+			return nil
+		}
+		own++
+	}
+	if own >= domain.MaxReactionsPerUser {
+		return errs.AddFieldError(nil, "emoji", fmt.Sprintf("cannot add more than %d reactions to a message", domain.MaxReactionsPerUser))
+	}
+
+	err = uc.reactionRepo.Add(ctx, &domain.Reaction{
+		MessageID: message.ID,
+		ChatID:    message.ChatID,
+		UserID:    authUser.ID,
+		Emoji:     req.Emoji,
+		CreatedAt: time.Now(),
+	})
+	if errors.Is(err, errs.ErrAlreadyExists) {
+		return nil // Added concurrently
+	}
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.broadcaster.BroadcastReactionAdded(ws.ReactionPayload{
+		ChatID:    message.ChatID,
+		MessageID: message.ID,
+		UserID:    authUser.ID,
+		Emoji:     req.Emoji,
+	})
+
+	return nil
+}
+
+// RemoveReaction removes the caller's reaction with an emoji from a message.
+func (uc *useCase) RemoveReaction(ctx context.Context, req RemoveReactionReq) error {
+	const op = "messageuc.RemoveReaction"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	message, err := uc.participantMessage(ctx, authUser.WorkspaceID, req.MessageID, authUser.ID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	err = uc.reactionRepo.Remove(ctx, message.ID, authUser.ID, req.Emoji)
+	if err != nil {
+		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("emoji", "reaction not found"))
+	}
+
+	uc.broadcaster.BroadcastReactionRemoved(ws.ReactionPayload{
+		ChatID:    message.ChatID,
+		MessageID: message.ID,
+		UserID:    authUser.ID,
+		Emoji:     req.Emoji,
+	})
+
+	return nil
+}
+
+// ListReactions returns the reactions to a message grouped by emoji.
+func (uc *useCase) ListReactions(ctx context.Context, req ListReactionsReq) (*ListReactionsResp, error) {
+	const op = "messageuc.ListReactions"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	message, err := uc.participantMessage(ctx, authUser.WorkspaceID, req.MessageID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	reactions, err := uc.reactionRepo.ListByMessage(ctx, message.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]ReactionDTO, 0)
+	index := make(map[string]int)
+	for _, r := range reactions {
+		i, ok := index[r.Emoji]
+		if !ok {
+			i = len(dtos)
+			index[r.Emoji] = i
+			dtos = append(dtos, ReactionDTO{Emoji: r.Emoji, UserIDs: make([]int, 0, 1)})
+		}
+		dtos[i].Count++
+		dtos[i].UserIDs = append(dtos[i].UserIDs, r.UserID)
+		if r.UserID == authUser.ID {
+			dtos[i].Reacted = true
+		}
+	}
+
+	return &ListReactionsResp{
+		MessageID: message.ID,
+		Reactions: dtos,
+	}, nil
+}
+
+// participantMessage returns a message after checking the user participates
+// in its chat.
+func (uc *useCase) participantMessage(ctx context.Context, workspaceID, messageID, userID int) (*domain.Message, error) {
+	const op = "messageuc.participantMessage"
+
+	message, err := uc.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("message_id", "message not found"))
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, workspaceID, message.ChatID, userID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	return message, nil
+}
@@ -0,0 +1,98 @@
+package messageuc
+
+import (
+	"context"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/metrics"
+)
+
+var (
+	retentionMessagesTotal = metrics.NewCounter(
+		"message_retention_messages_total",
+		"Number of expired messages handled by the retention job, by mode (delete or anonymize).",
+		"mode",
+	)
+	// Dry runs report what a real run would handle without counting the same
+	// messages again on every run.
+	retentionPendingMessages = metrics.NewGauge(
+		"message_retention_pending_messages",
+		"Number of expired messages found by the latest dry run of the retention job.",
+		"mode",
+	)
+)
+
+// ApplyRetention deletes or anonymizes the messages past their chat's
+// retention period, in batches of Config.RetentionBatchSize so no single
+// statement holds locks for long. In dry-run mode it only counts them.
+//
+// Once every expired message is handled, participants of the affected chats
+// are told up to which message they can drop or blank their local copies.
+func (uc *useCase) ApplyRetention(ctx context.Context) (*RetentionResult, error) {
+	const op = "messageuc.ApplyRetention"
+
+	policy := domain.RetentionPolicy{
+		DefaultDays: uc.cfg.RetentionDays,
+		Now:         time.Now(),
+	}
+	mode := uc.cfg.RetentionMode
+	result := &RetentionResult{Mode: mode, DryRun: uc.cfg.RetentionDryRun}
+
+	if result.DryRun {
+		count, err := uc.messageRepo.CountExpired(ctx, policy, mode)
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+		retentionPendingMessages.Set(float64(count), string(mode))
+		result.Messages = count
+
+		return result, nil
+	}
+
+	// Batches don't handle a chat's messages in order, so only a finished
+	// run guarantees that nothing up to the highest handled ID is left.
+	upToIDs := make(map[int]int)
+	for ctx.Err() == nil {
+		var (
+			expired []domain.ExpiredMessages
+			err     error
+		)
+		if mode == domain.RetentionModeAnonymize {
+			expired, err = uc.messageRepo.AnonymizeExpired(ctx, policy, uc.cfg.RetentionBatchSize)
+		} else {
+			expired, err = uc.messageRepo.DeleteExpired(ctx, policy, uc.cfg.RetentionBatchSize)
+		}
+		if err != nil {
+			return result, errs.Wrap(op, err)
+		}
+
+		n := domain.CountExpiredMessages(expired)
+		retentionMessagesTotal.Add(float64(n), string(mode))
+		result.Messages += n
+
+		for _, e := range expired {
+			upToIDs[e.ChatID] = max(upToIDs[e.ChatID], e.MaxID)
+		}
+
+		if n < uc.cfg.RetentionBatchSize {
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		return result, nil
+	}
+
+	for chatID, upToID := range upToIDs {
+		uc.broadcaster.BroadcastMessagesExpired(ws.MessagesExpiredPayload{
+			ChatID: chatID,
+			UpToID: upToID,
+			Mode:   string(mode),
+		})
+	}
+
+	return result, nil
+}
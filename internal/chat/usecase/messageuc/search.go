@@ -0,0 +1,103 @@
+package messageuc
+
+import (
+	"context"
+
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+)
+
+// SearchMessages finds messages matching a full-text query in all chats the
+// user takes part in.
+func (uc *useCase) SearchMessages(ctx context.Context, req SearchMessagesReq) (*SearchMessagesResp, error) {
+	const op = "messageuc.SearchMessages"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	resp, err := uc.search(ctx, domain.MessageSearchParams{
+		WorkspaceID: authUser.WorkspaceID,
+		UserID:      authUser.ID,
+		Query:       req.Query,
+		Offset:      req.Page * req.Limit,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	resp.Page, resp.Limit = req.Page, req.Limit
+
+	return resp, nil
+}
+
+// SearchChatMessages finds messages matching a full-text query in one chat
+// the user takes part in.
+func (uc *useCase) SearchChatMessages(ctx context.Context, req SearchChatMessagesReq) (*SearchMessagesResp, error) {
+	const op = "messageuc.SearchChatMessages"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	resp, err := uc.search(ctx, domain.MessageSearchParams{
+		WorkspaceID: authUser.WorkspaceID,
+		UserID:      authUser.ID,
+		ChatID:      req.ChatID,
+		Query:       req.Query,
+		Offset:      req.Page * req.Limit,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	resp.Page, resp.Limit = req.Page, req.Limit
+
+	return resp, nil
+}
+
+// search runs a message search and enriches the results with sender data.
+func (uc *useCase) search(ctx context.Context, params domain.MessageSearchParams) (*SearchMessagesResp, error) {
+	results, total, err := uc.messageRepo.Search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	senderIDs := make([]int, len(results))
+	for i, r := range results {
+		senderIDs[i] = r.Message.SenderID
+	}
+	senders, err := uc.usersByID(ctx, senderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]SearchResultDTO, len(results))
+	for i, r := range results {
+		highlights := make([]HighlightDTO, len(r.Highlights))
+		for j, h := range r.Highlights {
+			highlights[j] = HighlightDTO{Offset: h.Offset, Length: h.Length}
+		}
+
+		dtos[i] = SearchResultDTO{
+			MessageDTO: messageDTO(r.Message, senders),
+			Snippet:    r.Snippet,
+			Highlights: highlights,
+		}
+	}
+
+	return &SearchMessagesResp{
+		Messages: dtos,
+		Total:    total,
+	}, nil
+}
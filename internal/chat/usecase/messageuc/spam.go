@@ -0,0 +1,91 @@
+package messageuc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/spam"
+)
+
+// minDuplicateLength is the shortest message checked for duplicates, so
+// greetings and one-word replies sent to many chats don't count.
+const minDuplicateLength = 20
+
+// checkSpam scores a message about to be sent and reports the sender once
+// it crosses a threshold. Returns whether the message must be withheld.
+func (uc *useCase) checkSpam(ctx context.Context, userID, chatID int, content string) (bool, error) {
+	const op = "messageuc.checkSpam"
+
+	limited, err := uc.authPortal.IsShadowLimited(ctx, userID)
+	if err != nil {
+		return false, errs.Wrap(op, err)
+	}
+	if limited {
+		return true, nil
+	}
+
+	var reports []auth.SpamReport
+
+	if uc.cfg.SpamMessageLimit > 0 {
+		ok, _, err := uc.limiter.Allow(
+			ctx,
+			"spam:messages:"+strconv.Itoa(userID),
+			uc.cfg.SpamMessageLimit,
+			uc.cfg.SpamMessageWindow,
+		)
+		if err != nil {
+			return false, errs.Wrap(op, err)
+		}
+		if !ok {
+			reports = append(reports, auth.SpamReport{
+				Reason:  auth.SpamReasonMessageRate,
+				Details: fmt.Sprintf("more than %d messages within %s", uc.cfg.SpamMessageLimit, uc.cfg.SpamMessageWindow),
+			})
+		}
+	}
+
+	if uc.cfg.SpamDuplicateChats > 0 && utf8.RuneCountInString(content) >= minDuplicateLength {
+		chats, err := uc.spamCounter.AddDistinct(
+			ctx,
+			"duplicates:"+strconv.Itoa(userID)+":"+spam.Fingerprint(content),
+			strconv.Itoa(chatID),
+			uc.cfg.SpamDuplicateWindow,
+		)
+		if err != nil {
+			return false, errs.Wrap(op, err)
+		}
+		if chats > uc.cfg.SpamDuplicateChats {
+			reports = append(reports, auth.SpamReport{
+				Reason:  auth.SpamReasonDuplicateContent,
+				Details: fmt.Sprintf("same message sent to %d chats within %s", chats, uc.cfg.SpamDuplicateWindow),
+			})
+		}
+	}
+
+	for _, report := range reports {
+		report.UserID = userID
+		report.Shadow = uc.cfg.SpamShadow
+		if err := uc.authPortal.ReportSpam(ctx, report); err != nil {
+			return false, errs.Wrap(op, err)
+		}
+	}
+
+	return len(reports) > 0 && uc.cfg.SpamShadow, nil
+}
+
+// withheldResponse answers a withheld message as if it had been sent, so
+// shadow limited users don't notice their messages aren't delivered.
+func (uc *useCase) withheldResponse() *SendMessageResp {
+	return &SendMessageResp{
+		MessageID: int(uc.ids.Next()),
+		PublicID:  uuid.NewString(),
+		SentAt:    time.Now().Format(time.RFC3339),
+	}
+}
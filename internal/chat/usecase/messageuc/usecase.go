@@ -2,34 +2,170 @@ package messageuc
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
+	"chatx-01-backend/internal/analytics"
 	"chatx-01-backend/internal/chat/controller/ws"
 	"chatx-01-backend/internal/chat/domain"
 	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/internal/portal/notifications"
 	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/filestore"
+	"chatx-01-backend/pkg/linkscan"
+	"chatx-01-backend/pkg/llm"
+	"chatx-01-backend/pkg/markup"
+	"chatx-01-backend/pkg/ratelimit"
+	"chatx-01-backend/pkg/snowflake"
+	"chatx-01-backend/pkg/spam"
+	"chatx-01-backend/pkg/translate"
 )
 
+// Config holds message limits shared by every send and edit path.
+type Config struct {
+	// MaxMessageLength is the maximum message length in characters.
+	MaxMessageLength int
+	// MaxAttachmentSize is the maximum size of an uploaded attachment in bytes.
+	MaxAttachmentSize int64
+	// AttachmentTypes are the media types attachments may have.
+	AttachmentTypes []string
+	// TranslationCacheTTL is how long translated messages are cached.
+	TranslationCacheTTL time.Duration
+	// AssistantMaxMessages is how many recent messages summaries and
+	// suggested replies are based on.
+	AssistantMaxMessages int
+	// AssistantCacheTTL is how long summaries and suggested replies are cached.
+	AssistantCacheTTL time.Duration
+	// WebhookRateLimit is how many messages one incoming webhook may post
+	// per minute.
+	WebhookRateLimit int
+	// LinkScanCacheTTL is how long scan results of links are cached.
+	LinkScanCacheTTL time.Duration
+
+	// SpamMessageLimit is how many messages a user may send per
+	// SpamMessageWindow before being reported as a spammer. Zero disables
+	// the check.
+	SpamMessageLimit  int
+	SpamMessageWindow time.Duration
+	// SpamDuplicateChats is how many chats a user may send the same message
+	// to within SpamDuplicateWindow before being reported. Zero disables the
+	// check.
+	SpamDuplicateChats  int
+	SpamDuplicateWindow time.Duration
+	// SpamShadow withholds the messages of reported users until an admin
+	// reviews the report, instead of only flagging them.
+	SpamShadow bool
+
+	// RetentionDays is how long messages are kept in chats without their own
+	// retention period. Zero keeps them forever.
+	RetentionDays int
+	// RetentionMode is what happens to expired messages.
+	RetentionMode domain.RetentionMode
+	// RetentionDryRun only counts expired messages.
+	RetentionDryRun bool
+	// RetentionBatchSize is how many messages one statement handles.
+	RetentionBatchSize int
+}
+
+// TranslationCache stores translated messages shared by all API instances.
+// A revision identifies one version of a message's content.
+type TranslationCache interface {
+	GetTranslation(ctx context.Context, messageID int, revision int64, lang string) (string, string, bool, error)
+	SetTranslation(
+		ctx context.Context,
+		messageID int,
+		revision int64,
+		lang, text, sourceLang string,
+		ttl time.Duration,
+	) error
+}
+
 type useCase struct {
-	chatRepo    domain.ChatRepository
-	messageRepo domain.MessageRepository
-	authPortal  auth.Portal
-	broadcaster ws.Broadcaster
+	chatRepo       domain.ChatRepository
+	messageRepo    domain.MessageRepository
+	reactionRepo   domain.ReactionRepository
+	mentionRepo    domain.MentionRepository
+	authPortal     auth.Portal
+	notificationPr notifications.Portal
+	broadcaster    ws.Broadcaster
+	fileStore      filestore.Store
+	translator     translate.Translator
+	cache          TranslationCache
+	assistant      llm.Provider
+	assistantCache AssistantCache
+	limiter        ratelimit.Limiter
+	spamCounter    spam.DistinctCounter
+	ids            *snowflake.Generator
+	linkScanner    linkscan.Scanner
+	linkCache      LinkScanCache
+	linkJobs       chan linkScanJob
+	analytics      analytics.Publisher
+	logger         *slog.Logger
+	cfg            Config
 }
 
-// New creates a new message use case.
+// New creates a new message use case. translator, assistant and linkScanner
+// may be nil, in which case translation, summaries and suggested replies, or
+// link scanning are disabled.
 func New(
 	chatRepo domain.ChatRepository,
 	messageRepo domain.MessageRepository,
+	reactionRepo domain.ReactionRepository,
+	mentionRepo domain.MentionRepository,
 	authPortal auth.Portal,
+	notificationPr notifications.Portal,
 	broadcaster ws.Broadcaster,
+	fileStore filestore.Store,
+	translator translate.Translator,
+	cache TranslationCache,
+	assistant llm.Provider,
+	assistantCache AssistantCache,
+	limiter ratelimit.Limiter,
+	spamCounter spam.DistinctCounter,
+	ids *snowflake.Generator,
+	linkScanner linkscan.Scanner,
+	linkCache LinkScanCache,
+	analyticsPr analytics.Publisher,
+	logger *slog.Logger,
+	cfg Config,
 ) UseCase {
-	return &useCase{
-		chatRepo:    chatRepo,
-		messageRepo: messageRepo,
-		authPortal:  authPortal,
-		broadcaster: broadcaster,
+	uc := &useCase{
+		chatRepo:       chatRepo,
+		messageRepo:    messageRepo,
+		reactionRepo:   reactionRepo,
+		mentionRepo:    mentionRepo,
+		authPortal:     authPortal,
+		notificationPr: notificationPr,
+		broadcaster:    broadcaster,
+		fileStore:      fileStore,
+		translator:     translator,
+		cache:          cache,
+		assistant:      assistant,
+		assistantCache: assistantCache,
+		limiter:        limiter,
+		spamCounter:    spamCounter,
+		ids:            ids,
+		linkScanner:    linkScanner,
+		linkCache:      linkCache,
+		analytics:      analyticsPr,
+		logger:         logger,
+		cfg:            cfg,
 	}
+
+	if linkScanner != nil {
+		uc.linkJobs = make(chan linkScanJob, linkScanQueueSize)
+		for range linkScanWorkers {
+			go uc.runLinkScans()
+		}
+	}
+
+	return uc
 }
 
 func (uc *useCase) GetMessagesList(ctx context.Context, req GetMessagesListReq) (*GetMessagesListResp, error) {
@@ -42,7 +178,7 @@ func (uc *useCase) GetMessagesList(ctx context.Context, req GetMessagesListReq)
 	userID := authUser.ID
 
 	// Check if user is participant
-	isParticipant, err := uc.chatRepo.IsParticipant(ctx, req.ChatID, userID)
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, userID)
 	if err != nil {
 		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
 	}
@@ -50,49 +186,113 @@ func (uc *useCase) GetMessagesList(ctx context.Context, req GetMessagesListReq)
 		return nil, errs.Wrap(op, domain.ErrNotParticipant)
 	}
 
-	offset := req.Page * req.Limit
-	messages, total, err := uc.messageRepo.ListWithCount(ctx, req.ChatID, offset, req.Limit)
+	order := domain.SortDesc
+	if req.Order != "" {
+		order = domain.SortOrder(req.Order)
+	}
+
+	withTotal := domain.TotalMode(req.Total) != domain.TotalNone
+
+	// One message more than the page tells whether another page follows
+	messages, total, err := uc.messageRepo.ListWithCount(ctx, domain.MessageListParams{
+		ChatID:    req.ChatID,
+		BeforeID:  req.BeforeID,
+		AfterID:   req.AfterID,
+		Order:     order,
+		Offset:    req.Page * req.Limit,
+		Limit:     req.Limit + 1,
+		SkipTotal: !withTotal,
+	})
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
+	hasMore := len(messages) > req.Limit
+	if hasMore {
+		messages = messages[:req.Limit]
+	}
 
 	// Enrich messages with sender data
-	messageDTOs := make([]MessageDTO, len(messages))
+	senderIDs := make([]int, len(messages))
 	for i, msg := range messages {
-		user, err := uc.authPortal.GetUserByID(ctx, msg.SenderID)
-		if err != nil {
-			return nil, errs.Wrap(op, err)
-		}
-
-		var editedAt *string
-		if msg.EditedAt != nil {
-			formatted := msg.EditedAt.Format(time.RFC3339)
-			editedAt = &formatted
-		}
+		senderIDs[i] = msg.SenderID
+	}
+	senders, err := uc.usersByID(ctx, senderIDs)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
 
-		messageDTOs[i] = MessageDTO{
-			MessageID:   msg.ID,
-			ChatID:      msg.ChatID,
-			SenderID:    msg.SenderID,
-			SenderName:  user.Username,
-			SenderImage: user.ImagePath,
-			Content:     msg.Content,
-			SentAt:      msg.SentAt.Format(time.RFC3339),
-			EditedAt:    editedAt,
-		}
+	messageDTOs := make([]MessageDTO, len(messages))
+	for i, msg := range messages {
+		messageDTOs[i] = messageDTO(msg, senders)
 	}
 
-	return &GetMessagesListResp{
+	resp := &GetMessagesListResp{
 		Messages: messageDTOs,
-		Total:    total,
+		HasMore:  hasMore,
 		Page:     req.Page,
 		Limit:    req.Limit,
-	}, nil
+	}
+	if withTotal {
+		resp.Total = &total
+	}
+
+	return resp, nil
+}
+
+// usersByID looks up the given users in one batch, keyed by ID. Duplicate
+// IDs are looked up once and missing users are left out.
+func (uc *useCase) usersByID(ctx context.Context, ids []int) (map[int]*auth.User, error) {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+
+	users, err := uc.authPortal.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*auth.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	return byID, nil
+}
+
+// messageDTO converts a message, taking sender data from senders.
+func messageDTO(msg domain.Message, senders map[int]*auth.User) MessageDTO {
+	sender, ok := senders[msg.SenderID]
+	if !ok {
+		sender = &auth.User{ID: msg.SenderID}
+	}
+
+	var editedAt *string
+	if msg.EditedAt != nil {
+		formatted := msg.EditedAt.Format(time.RFC3339)
+		editedAt = &formatted
+	}
+
+	return MessageDTO{
+		MessageID:         msg.ID,
+		PublicID:          msg.PublicID,
+		ChatID:            msg.ChatID,
+		SenderID:          msg.SenderID,
+		SenderName:        sender.Username,
+		SenderDisplayName: sender.DisplayName,
+		SenderImage:       sender.ImagePath,
+		Content:           msg.Content,
+		Entities:          entityDTOs(msg.Entities),
+		Attachments:       attachmentDTOs(msg.Attachments),
+		Metadata:          msg.Metadata,
+		SentAt:            msg.SentAt.Format(time.RFC3339),
+		EditedAt:          editedAt,
+	}
 }
 
 func (uc *useCase) SendMessage(ctx context.Context, req SendMessageReq) (*SendMessageResp, error) {
 	const op = "messageuc.SendMessage"
 
+	if err := uc.validateContent(req.Content); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
 	authUser, err := uc.authPortal.GetAuthUser(ctx)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
@@ -100,7 +300,7 @@ func (uc *useCase) SendMessage(ctx context.Context, req SendMessageReq) (*SendMe
 	userID := authUser.ID
 
 	// Check if user is participant
-	isParticipant, err := uc.chatRepo.IsParticipant(ctx, req.ChatID, userID)
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, userID)
 	if err != nil {
 		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
 	}
@@ -108,12 +308,46 @@ func (uc *useCase) SendMessage(ctx context.Context, req SendMessageReq) (*SendMe
 		return nil, errs.Wrap(op, domain.ErrNotParticipant)
 	}
 
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if chat.Type == domain.ChatTypeChannel && chat.CreatorID != userID && !authUser.IsWorkspaceAdmin() {
+		return nil, errs.Wrap(op, domain.ErrChannelReadOnly)
+	}
+	if chat.Type == domain.ChatTypeDirect {
+		if err := uc.checkNotBlocked(ctx, chat.ID, userID); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+	}
+
+	attachments, err := uc.resolveAttachments(ctx, req.ChatID, req.Attachments)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	metadata, err := uc.resolveForward(ctx, authUser.WorkspaceID, userID, req.Metadata)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	withheld, err := uc.checkSpam(ctx, userID, req.ChatID, req.Content)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if withheld {
+		return uc.withheldResponse(), nil
+	}
+
 	// Create message
 	message := &domain.Message{
-		ChatID:   req.ChatID,
-		SenderID: userID,
-		Content:  req.Content,
-		SentAt:   time.Now(),
+		ChatID:      req.ChatID,
+		SenderID:    userID,
+		Content:     req.Content,
+		Entities:    parseEntities(req.Content),
+		Attachments: attachments,
+		Metadata:    metadata,
+		SentAt:      time.Now(),
 	}
 
 	if err := uc.messageRepo.Create(ctx, message); err != nil {
@@ -121,23 +355,94 @@ func (uc *useCase) SendMessage(ctx context.Context, req SendMessageReq) (*SendMe
 	}
 
 	// Broadcast new message event via WebSocket
-	uc.broadcaster.BroadcastNewMessage(
-		message.ChatID,
-		message.ID,
-		message.SenderID,
-		message.Content,
-		message.SentAt,
-	)
+	uc.broadcaster.BroadcastNewMessage(messagePayload(message))
+	uc.scanLinks(ctx, message)
+	uc.notifyMentions(ctx, authUser.WorkspaceID, message)
+
+	uc.analytics.Track(ctx, analytics.Event{
+		Type:        analytics.EventMessageSent,
+		UserID:      userID,
+		WorkspaceID: authUser.WorkspaceID,
+		Properties:  map[string]string{"has_metadata": strconv.FormatBool(len(req.Metadata) > 0)},
+	})
 
 	return &SendMessageResp{
 		MessageID: message.ID,
+		PublicID:  message.PublicID,
 		SentAt:    message.SentAt.Format(time.RFC3339),
 	}, nil
 }
 
+// checkNotBlocked returns domain.ErrUserBlocked if the user and the other
+// participant of a direct chat blocked each other.
+func (uc *useCase) checkNotBlocked(ctx context.Context, chatID, userID int) error {
+	participants, err := uc.chatRepo.GetParticipants(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range participants {
+		if p.UserID == userID {
+			continue
+		}
+		blocked, err := uc.authPortal.IsBlocked(ctx, userID, p.UserID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return domain.ErrUserBlocked
+		}
+	}
+
+	return nil
+}
+
+// resolveForward checks that the user can see the message a forward
+// references and sets its chat and sender from the original, so a forward
+// can't be attributed to anyone the client likes. Metadata without a
+// forward is returned as is.
+func (uc *useCase) resolveForward(ctx context.Context, workspaceID, userID int, md domain.Metadata) (domain.Metadata, error) {
+	raw, ok := md[domain.MetadataForward]
+	if !ok {
+		return md, nil
+	}
+
+	var fwd domain.ForwardMetadata
+	if err := json.Unmarshal(raw, &fwd); err != nil {
+		return nil, err
+	}
+
+	errForwardNotFound := errs.NewNotFoundError("metadata", "forwarded message not found")
+	original, err := uc.messageRepo.GetByID(ctx, fwd.MessageID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errForwardNotFound)
+	}
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, workspaceID, original.ChatID, userID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errForwardNotFound)
+	}
+	if !isParticipant {
+		return nil, errForwardNotFound
+	}
+
+	fwd.ChatID = original.ChatID
+	fwd.SenderID = original.SenderID
+	if raw, err = json.Marshal(fwd); err != nil {
+		return nil, err
+	}
+
+	md = maps.Clone(md)
+	md[domain.MetadataForward] = raw
+	return md, nil
+}
+
 func (uc *useCase) EditMessage(ctx context.Context, req EditMessageReq) error {
 	const op = "messageuc.EditMessage"
 
+	if err := uc.validateContent(req.Content); err != nil {
+		return errs.Wrap(op, err)
+	}
+
 	authUser, err := uc.authPortal.GetAuthUser(ctx)
 	if err != nil {
 		return errs.Wrap(op, err)
@@ -157,6 +462,7 @@ func (uc *useCase) EditMessage(ctx context.Context, req EditMessageReq) error {
 
 	// Update message
 	message.Content = req.Content
+	message.Entities = parseEntities(req.Content)
 	now := time.Now()
 	message.EditedAt = &now
 
@@ -165,13 +471,9 @@ func (uc *useCase) EditMessage(ctx context.Context, req EditMessageReq) error {
 	}
 
 	// Broadcast message edit event via WebSocket
-	uc.broadcaster.BroadcastEditMessage(
-		message.ChatID,
-		message.ID,
-		message.SenderID,
-		message.Content,
-		now,
-	)
+	uc.broadcaster.BroadcastEditMessage(messagePayload(message))
+	uc.syncMentions(ctx, authUser.WorkspaceID, message)
+	uc.scanLinks(ctx, message)
 
 	return nil
 }
@@ -191,9 +493,24 @@ func (uc *useCase) DeleteMessage(ctx context.Context, req DeleteMessageReq) erro
 		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("message_id", "message not found"))
 	}
 
-	// Check if user is the sender
+	// Only the sender and moderators can delete a message. Moderators only
+	// reach the groups and channels of their workspace, direct chats and
+	// Saved Messages stay private.
 	if message.SenderID != userID {
-		return errs.Wrap(op, domain.ErrNotMessageOwner)
+		if !authUser.Can(auth.PermDeleteAnyMessage) {
+			return errs.Wrap(op, domain.ErrNotMessageOwner)
+		}
+
+		chat, err := uc.chatRepo.GetByID(ctx, message.ChatID)
+		if err != nil {
+			return errs.Wrap(op, err)
+		}
+		if chat.Workspace() != authUser.WorkspaceID {
+			return errs.NewNotFoundError("message_id", "message not found")
+		}
+		if chat.Type != domain.ChatTypeGroup && chat.Type != domain.ChatTypeChannel {
+			return errs.Wrap(op, domain.ErrNotMessageOwner)
+		}
 	}
 
 	chatID := message.ChatID
@@ -201,9 +518,179 @@ func (uc *useCase) DeleteMessage(ctx context.Context, req DeleteMessageReq) erro
 	if err := uc.messageRepo.Delete(ctx, req.MessageID); err != nil {
 		return errs.Wrap(op, err)
 	}
+	if err := uc.reactionRepo.DeleteByMessage(ctx, req.MessageID); err != nil {
+		uc.logger.WarnContext(ctx, "failed to delete message reactions", "message_id", req.MessageID, "error", err)
+	}
+	if err := uc.mentionRepo.DeleteByMessage(ctx, req.MessageID); err != nil {
+		uc.logger.WarnContext(ctx, "failed to delete message mentions", "message_id", req.MessageID, "error", err)
+	}
+
+	if message.SenderID != userID {
+		uc.logger.InfoContext(ctx, "message deleted by moderator", "audit", true,
+			"user_id", userID, "message_id", req.MessageID, "chat_id", chatID, "sender_id", message.SenderID)
+		uc.authPortal.RecordAudit(ctx, auth.AuditEvent{
+			Action:       auth.AuditMessageDelete,
+			ActorID:      userID,
+			TargetUserID: message.SenderID,
+			ChatID:       chatID,
+			WorkspaceID:  authUser.WorkspaceID,
+			Details:      fmt.Sprintf("message %d", req.MessageID),
+		})
+	}
 
 	// Broadcast message delete event via WebSocket
 	uc.broadcaster.BroadcastDeleteMessage(chatID, req.MessageID)
 
 	return nil
 }
+
+func (uc *useCase) GetCapabilities(ctx context.Context, req GetCapabilitiesReq) (*GetCapabilitiesResp, error) {
+	return &GetCapabilitiesResp{
+		MaxMessageLength:  uc.cfg.MaxMessageLength,
+		MaxAttachmentSize: uc.cfg.MaxAttachmentSize,
+		Assistant:         uc.assistant != nil,
+	}, nil
+}
+
+func (uc *useCase) TranslateMessage(ctx context.Context, req TranslateMessageReq) (*TranslateMessageResp, error) {
+	const op = "messageuc.TranslateMessage"
+
+	if uc.translator == nil {
+		return nil, domain.ErrTranslationDisabled
+	}
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	message, err := uc.messageRepo.GetByID(ctx, req.MessageID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("message_id", "message not found"))
+	}
+
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, message.ChatID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return nil, errs.Wrap(op, domain.ErrNotParticipant)
+	}
+
+	// Validate has already rejected malformed codes
+	lang, _ := translate.NormalizeLanguage(req.TargetLang)
+
+	// Edits change the revision, so an edited message is translated again
+	var revision int64
+	if message.EditedAt != nil {
+		revision = message.EditedAt.UnixMilli()
+	}
+
+	text, sourceLang, ok, err := uc.cache.GetTranslation(ctx, message.ID, revision, lang)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if ok {
+		return &TranslateMessageResp{
+			MessageID:  message.ID,
+			TargetLang: lang,
+			SourceLang: sourceLang,
+			Text:       text,
+			Cached:     true,
+		}, nil
+	}
+
+	result, err := uc.translator.Translate(ctx, message.Content, lang)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	err = uc.cache.SetTranslation(
+		ctx,
+		message.ID,
+		revision,
+		lang,
+		result.Text,
+		result.SourceLang,
+		uc.cfg.TranslationCacheTTL,
+	)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &TranslateMessageResp{
+		MessageID:  message.ID,
+		TargetLang: lang,
+		SourceLang: result.SourceLang,
+		Text:       result.Text,
+	}, nil
+}
+
+// validateContent enforces the configured message length limit.
+func (uc *useCase) validateContent(content string) error {
+	if utf8.RuneCountInString(content) > uc.cfg.MaxMessageLength {
+		return errs.AddFieldError(
+			nil,
+			"content",
+			fmt.Sprintf("message content must be %d characters or less", uc.cfg.MaxMessageLength),
+		)
+	}
+	return nil
+}
+
+// parseEntities extracts formatting entities from message content.
+func parseEntities(content string) []domain.MessageEntity {
+	parsed := markup.Parse(content)
+
+	entities := make([]domain.MessageEntity, len(parsed))
+	for i, e := range parsed {
+		entities[i] = domain.MessageEntity{
+			Type:   string(e.Type),
+			Offset: e.Offset,
+			Length: e.Length,
+			URL:    e.URL,
+		}
+	}
+	return entities
+}
+
+func entityDTOs(entities []domain.MessageEntity) []EntityDTO {
+	dtos := make([]EntityDTO, len(entities))
+	for i, e := range entities {
+		dtos[i] = EntityDTO{
+			Type:   e.Type,
+			Offset: e.Offset,
+			Length: e.Length,
+			URL:    e.URL,
+		}
+	}
+	return dtos
+}
+
+func messagePayload(message *domain.Message) ws.MessagePayload {
+	return ws.MessagePayload{
+		ID:          message.ID,
+		PublicID:    message.PublicID,
+		ChatID:      message.ChatID,
+		SenderID:    message.SenderID,
+		Content:     message.Content,
+		Entities:    entityPayloads(message.Entities),
+		Attachments: attachmentPayloads(message.Attachments),
+		Metadata:    message.Metadata,
+		SentAt:      message.SentAt,
+		EditedAt:    message.EditedAt,
+	}
+}
+
+func entityPayloads(entities []domain.MessageEntity) []ws.EntityPayload {
+	payloads := make([]ws.EntityPayload, len(entities))
+	for i, e := range entities {
+		payloads[i] = ws.EntityPayload{
+			Type:   e.Type,
+			Offset: e.Offset,
+			Length: e.Length,
+			URL:    e.URL,
+		}
+	}
+	return payloads
+}
@@ -0,0 +1,126 @@
+package messageuc
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"time"
+
+	"chatx-01-backend/internal/analytics"
+	"chatx-01-backend/internal/chat/domain"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/val"
+)
+
+// webhookRateWindow is the window WebhookRateLimit counts messages in.
+const webhookRateWindow = time.Minute
+
+// PostWebhookMessage posts a message through an incoming webhook, as the
+// webhook's creator. Unknown webhooks and wrong secrets look the same to the
+// caller.
+func (uc *useCase) PostWebhookMessage(ctx context.Context, req PostWebhookMessageReq) (*SendMessageResp, error) {
+	const op = "messageuc.PostWebhookMessage"
+
+	errWebhookNotFound := errs.NewNotFoundError("hook_id", "webhook not found")
+	if !val.IsUUID(req.HookID) {
+		return nil, errWebhookNotFound
+	}
+
+	webhook, err := uc.chatRepo.GetWebhookByPublicID(ctx, req.HookID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errWebhookNotFound)
+	}
+	if !webhook.CheckSecret(req.Secret) {
+		return nil, errWebhookNotFound
+	}
+
+	if err := uc.validateContent(req.Content); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	ok, retryAfter, err := uc.limiter.Allow(
+		ctx,
+		"webhook:"+strconv.Itoa(webhook.ID),
+		uc.cfg.WebhookRateLimit,
+		webhookRateWindow,
+	)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !ok {
+		return nil, errs.NewRateLimitError("too many webhook messages, try again later", retryAfter)
+	}
+
+	chat, err := uc.chatRepo.GetByID(ctx, webhook.ChatID)
+	if err != nil {
+		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errWebhookNotFound)
+	}
+	var workspaceID int
+	if chat.WorkspaceID != nil {
+		workspaceID = *chat.WorkspaceID
+	}
+
+	// The webhook stops working once its creator leaves the chat
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, workspaceID, chat.ID, webhook.CreatorID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	if !isParticipant {
+		return nil, domain.ErrWebhookInactive
+	}
+
+	hookMetadata, err := json.Marshal(domain.WebhookMetadata{
+		WebhookID: webhook.PublicID,
+		Name:      webhook.Name,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	// Forwards are checked against the creator, who the message is sent as
+	metadata, err := uc.resolveForward(ctx, workspaceID, webhook.CreatorID, req.Metadata)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+	metadata = maps.Clone(metadata)
+	if metadata == nil {
+		metadata = make(domain.Metadata)
+	}
+	metadata[domain.MetadataWebhook] = hookMetadata
+	if err := domain.ValidateMetadata(metadata); err != nil {
+		return nil, errs.AddFieldError(nil, "metadata", err.Error())
+	}
+
+	message := &domain.Message{
+		ChatID:   chat.ID,
+		SenderID: webhook.CreatorID,
+		Content:  req.Content,
+		Entities: parseEntities(req.Content),
+		Metadata: metadata,
+		SentAt:   time.Now(),
+	}
+	if err := uc.messageRepo.Create(ctx, message); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	uc.broadcaster.BroadcastNewMessage(messagePayload(message))
+	uc.scanLinks(ctx, message)
+	uc.notifyMentions(ctx, workspaceID, message)
+
+	if err := uc.chatRepo.TouchWebhook(ctx, webhook.ID, message.SentAt); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	uc.analytics.Track(ctx, analytics.Event{
+		Type:        analytics.EventMessageSent,
+		UserID:      webhook.CreatorID,
+		WorkspaceID: workspaceID,
+		Properties:  map[string]string{"has_metadata": strconv.FormatBool(len(req.Metadata) > 0), "webhook": "true"},
+	})
+
+	return &SendMessageResp{
+		MessageID: message.ID,
+		PublicID:  message.PublicID,
+		SentAt:    message.SentAt.Format(time.RFC3339),
+	}, nil
+}
@@ -3,6 +3,7 @@ package notificationuc
 import (
 	"chatx-01-backend/pkg/errs"
 	"context"
+	"time"
 )
 
 type UseCase interface {
@@ -10,15 +11,18 @@ type UseCase interface {
 		ctx context.Context,
 		req GetUnreadMessagesCountReq,
 	) (*GetUnreadMessagesCountResp, error)
+	GetUnreadChatsCount(ctx context.Context, req GetUnreadChatsCountReq) (*GetUnreadChatsCountResp, error)
 	GetUnreadMessagesCountByChat(
 		ctx context.Context,
 		req GetUnreadMessagesCountByChatReq,
 	) (*GetUnreadMessagesCountByChatResp, error)
 	MarkMessagesAsRead(ctx context.Context, req MarkMessagesAsReadReq) error
+	MarkChatsAsRead(ctx context.Context, req MarkChatsAsReadReq) error
 	GetOnlineStatusByUsers(
 		ctx context.Context,
 		req GetOnlineStatusByUsersReq,
 	) (*GetOnlineStatusByUsersResp, error)
+	GetConnections(ctx context.Context, req GetConnectionsReq) (*GetConnectionsResp, error)
 }
 
 type GetUnreadMessagesCountReq struct{}
@@ -28,7 +32,18 @@ func (req GetUnreadMessagesCountReq) Validate() error {
 }
 
 type GetUnreadMessagesCountResp struct {
-	TotalUnreadCount int `json:"total_unread_count"`
+	TotalUnreadCount    int `json:"total_unread_count"`
+	UnreadMentionsCount int `json:"unread_mentions_count"` // Unread messages mentioning the user
+}
+
+type GetUnreadChatsCountReq struct{}
+
+func (req GetUnreadChatsCountReq) Validate() error {
+	return nil
+}
+
+type GetUnreadChatsCountResp struct {
+	UnreadChatsCount int `json:"unread_chats_count"`
 }
 
 type GetUnreadMessagesCountByChatReq struct {
@@ -46,8 +61,10 @@ func (req GetUnreadMessagesCountByChatReq) Validate() error {
 }
 
 type GetUnreadMessagesCountByChatResp struct {
-	ChatID      int `json:"chat_id"`
-	UnreadCount int `json:"unread_count"`
+	ChatID               int  `json:"chat_id"`
+	UnreadCount          int  `json:"unread_count"`
+	UnreadMentionsCount  int  `json:"unread_mentions_count"` // Unread messages mentioning the user
+	FirstUnreadMessageID *int `json:"first_unread_message_id"`
 }
 
 type MarkMessagesAsReadReq struct {
@@ -68,6 +85,39 @@ func (req MarkMessagesAsReadReq) Validate() error {
 	return verr
 }
 
+// maxReadMarks is the number of chats one batch can mark as read.
+const maxReadMarks = 100
+
+type MarkChatsAsReadReq struct {
+	Reads []ReadMarkDTO `json:"reads"`
+}
+
+type ReadMarkDTO struct {
+	ChatID    int `json:"chat_id"`
+	MessageID int `json:"message_id"`
+}
+
+func (req MarkChatsAsReadReq) Validate() error {
+	var verr error
+
+	if len(req.Reads) == 0 {
+		verr = errs.AddFieldError(verr, "reads", "at least one read is required")
+	}
+	if len(req.Reads) > maxReadMarks {
+		verr = errs.AddFieldError(verr, "reads", "cannot mark more than 100 chats at once")
+	}
+	for _, read := range req.Reads {
+		if read.ChatID <= 0 {
+			verr = errs.AddFieldError(verr, "chat_id", "invalid chat id")
+		}
+		if read.MessageID <= 0 {
+			verr = errs.AddFieldError(verr, "message_id", "invalid message id")
+		}
+	}
+
+	return verr
+}
+
 type GetOnlineStatusByUsersReq struct {
 	UserIDs []int `json:"user_ids"`
 }
@@ -90,7 +140,38 @@ type GetOnlineStatusByUsersResp struct {
 }
 
 type UserOnlineStatus struct {
-	UserID   int     `json:"user_id"`
-	IsOnline bool    `json:"is_online"`
-	LastSeen *string `json:"last_seen,omitempty"`
+	UserID   int        `json:"user_id"`
+	IsOnline bool       `json:"is_online"`
+	LastSeen *time.Time `json:"last_seen,omitempty"` // Offline users who don't hide it
+}
+
+type GetConnectionsReq struct{}
+
+func (req GetConnectionsReq) Validate() error {
+	return nil
+}
+
+type GetConnectionsResp struct {
+	Users            []UserConnections `json:"users"`
+	TotalUsers       int               `json:"total_users"`
+	TotalConnections int               `json:"total_connections"`
+}
+
+// UserConnections lists a user's WebSocket connections. Details are only
+// known for connections to the instance serving the request, other instances
+// contribute their counts.
+type UserConnections struct {
+	UserID                int              `json:"user_id"`
+	ConnectionCount       int              `json:"connection_count"`
+	RemoteConnectionCount int              `json:"remote_connection_count"`
+	Connections           []ConnectionInfo `json:"connections"`
+}
+
+type ConnectionInfo struct {
+	RemoteIP        string `json:"remote_ip"`
+	ConnectedAt     string `json:"connected_at"`
+	LastActivityAt  string `json:"last_activity_at"`
+	SubscribedChats int    `json:"subscribed_chats"`
+	SentSeq         int64  `json:"sent_seq,omitempty"`
+	AckedSeq        int64  `json:"acked_seq,omitempty"`
 }
@@ -6,37 +6,51 @@ import (
 	"chatx-01-backend/internal/portal/auth"
 	"chatx-01-backend/pkg/errs"
 	"context"
+	"slices"
 	"time"
 )
 
 // OnlineChecker provides online status checking capability.
 type OnlineChecker interface {
-	IsUserOnline(userID int) bool
-	GetOnlineUsers(userIDs []int) []int
+	IsUserOnline(ctx context.Context, userID int) bool
+	GetOnlineUsers(ctx context.Context, userIDs []int) []int
+	GetLastSeen(ctx context.Context, userIDs []int) map[int]time.Time
+}
+
+// ConnectionLister lists the WebSocket connections of this and other instances.
+type ConnectionLister interface {
+	Connections() []ws.Connection
+	RemoteConnectionCounts(ctx context.Context) (map[int]int, error)
 }
 
 type useCase struct {
 	chatRepo      domain.ChatRepository
 	messageRepo   domain.MessageRepository
+	mentionRepo   domain.MentionRepository
 	authPortal    auth.Portal
 	broadcaster   ws.Broadcaster
 	onlineChecker OnlineChecker
+	connections   ConnectionLister
 }
 
 // New creates a new notification use case.
 func New(
 	chatRepo domain.ChatRepository,
 	messageRepo domain.MessageRepository,
+	mentionRepo domain.MentionRepository,
 	authPortal auth.Portal,
 	broadcaster ws.Broadcaster,
 	onlineChecker OnlineChecker,
+	connections ConnectionLister,
 ) UseCase {
 	return &useCase{
 		chatRepo:      chatRepo,
 		messageRepo:   messageRepo,
+		mentionRepo:   mentionRepo,
 		authPortal:    authPortal,
 		broadcaster:   broadcaster,
 		onlineChecker: onlineChecker,
+		connections:   connections,
 	}
 }
 
@@ -52,13 +66,40 @@ func (uc *useCase) GetUnreadMessagesCount(
 	}
 	userID := authUser.ID
 
-	totalCount, err := uc.messageRepo.GetTotalUnreadCount(ctx, userID)
+	totalCount, err := uc.messageRepo.GetTotalUnreadCount(ctx, authUser.WorkspaceID, userID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	mentionsCount, err := uc.mentionRepo.CountUnread(ctx, authUser.WorkspaceID, userID)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
 
 	return &GetUnreadMessagesCountResp{
-		TotalUnreadCount: totalCount,
+		TotalUnreadCount:    totalCount,
+		UnreadMentionsCount: mentionsCount,
+	}, nil
+}
+
+func (uc *useCase) GetUnreadChatsCount(
+	ctx context.Context,
+	req GetUnreadChatsCountReq,
+) (*GetUnreadChatsCountResp, error) {
+	const op = "notificationuc.GetUnreadChatsCount"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	count, err := uc.messageRepo.GetUnreadChatsCount(ctx, authUser.WorkspaceID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &GetUnreadChatsCountResp{
+		UnreadChatsCount: count,
 	}, nil
 }
 
@@ -75,7 +116,7 @@ func (uc *useCase) GetUnreadMessagesCountByChat(
 	userID := authUser.ID
 
 	// Check if user is participant
-	isParticipant, err := uc.chatRepo.IsParticipant(ctx, req.ChatID, userID)
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, userID)
 	if err != nil {
 		return nil, errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
 	}
@@ -88,9 +129,27 @@ func (uc *useCase) GetUnreadMessagesCountByChat(
 		return nil, errs.Wrap(op, err)
 	}
 
+	var (
+		firstUnreadID *int
+		mentionsCount int
+	)
+	if unreadCount > 0 {
+		firstUnreadID, err = uc.messageRepo.GetFirstUnreadMessageID(ctx, req.ChatID, userID)
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+
+		mentionsCount, err = uc.mentionRepo.CountUnreadByChat(ctx, req.ChatID, userID)
+		if err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+	}
+
 	return &GetUnreadMessagesCountByChatResp{
-		ChatID:      req.ChatID,
-		UnreadCount: unreadCount,
+		ChatID:               req.ChatID,
+		UnreadCount:          unreadCount,
+		UnreadMentionsCount:  mentionsCount,
+		FirstUnreadMessageID: firstUnreadID,
 	}, nil
 }
 
@@ -104,7 +163,7 @@ func (uc *useCase) MarkMessagesAsRead(ctx context.Context, req MarkMessagesAsRea
 	userID := authUser.ID
 
 	// Check if user is participant
-	isParticipant, err := uc.chatRepo.IsParticipant(ctx, req.ChatID, userID)
+	isParticipant, err := uc.chatRepo.IsParticipant(ctx, authUser.WorkspaceID, req.ChatID, userID)
 	if err != nil {
 		return errs.ReplaceOn(err, errs.ErrNotFound, errs.NewNotFoundError("chat_id", "chat not found"))
 	}
@@ -127,8 +186,85 @@ func (uc *useCase) MarkMessagesAsRead(ctx context.Context, req MarkMessagesAsRea
 		return errs.Wrap(op, err)
 	}
 
-	// Broadcast read receipt via WebSocket
-	uc.broadcaster.BroadcastReadReceipt(req.ChatID, userID, req.MessageID, time.Now())
+	// Broadcast read receipt via WebSocket. Receipts of channel subscribers
+	// would reach every other subscriber, so channels have none.
+	chat, err := uc.chatRepo.GetByID(ctx, req.ChatID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if chat.Type != domain.ChatTypeChannel {
+		uc.broadcaster.BroadcastReadReceipt(req.ChatID, userID, req.MessageID, time.Now())
+	}
+
+	return nil
+}
+
+func (uc *useCase) MarkChatsAsRead(ctx context.Context, req MarkChatsAsReadReq) error {
+	const op = "notificationuc.MarkChatsAsRead"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	userID := authUser.ID
+
+	// Keep the latest message of chats listed more than once
+	latest := make(map[int]int, len(req.Reads))
+	for _, read := range req.Reads {
+		latest[read.ChatID] = max(latest[read.ChatID], read.MessageID)
+	}
+
+	chatIDs, err := uc.chatRepo.GetUserChatIDs(ctx, authUser.WorkspaceID, userID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	for chatID := range latest {
+		if !slices.Contains(chatIDs, chatID) {
+			return errs.Wrap(op, domain.ErrNotParticipant)
+		}
+	}
+
+	// Verify messages exist and belong to their chats
+	marks := make([]domain.ReadMark, 0, len(latest))
+	messageIDs := make([]int, 0, len(latest))
+	for chatID, messageID := range latest {
+		marks = append(marks, domain.ReadMark{ChatID: chatID, MessageID: messageID})
+		messageIDs = append(messageIDs, messageID)
+	}
+
+	messages, err := uc.messageRepo.GetByIDs(ctx, messageIDs)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	messageChats := make(map[int]int, len(messages))
+	for _, message := range messages {
+		messageChats[message.ID] = message.ChatID
+	}
+	for _, mark := range marks {
+		chatID, ok := messageChats[mark.MessageID]
+		if !ok {
+			return errs.Wrap(op, errs.NewNotFoundError("message_id", "message not found"))
+		}
+		if chatID != mark.ChatID {
+			return errs.Wrap(op, domain.ErrMessageNotInChat)
+		}
+	}
+
+	if err := uc.chatRepo.UpdateLastReadBatch(ctx, userID, marks); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	channelIDs, err := uc.chatRepo.GetUserChannelIDs(ctx, authUser.WorkspaceID, userID)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	readAt := time.Now()
+	for _, mark := range marks {
+		if !slices.Contains(channelIDs, mark.ChatID) {
+			uc.broadcaster.BroadcastReadReceipt(mark.ChatID, userID, mark.MessageID, readAt)
+		}
+	}
 
 	return nil
 }
@@ -139,24 +275,31 @@ func (uc *useCase) GetOnlineStatusByUsers(
 ) (*GetOnlineStatusByUsersResp, error) {
 	const op = "notificationuc.GetOnlineStatusByUsers"
 
-	_, err := uc.authPortal.GetAuthUser(ctx)
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
 	if err != nil {
 		return nil, errs.Wrap(op, err)
 	}
 
-	// Get online users from WebSocket hub
-	onlineUserIDs := uc.onlineChecker.GetOnlineUsers(req.UserIDs)
+	// Get online users from WebSocket hub (shared across instances via presence)
+	onlineUserIDs := uc.onlineChecker.GetOnlineUsers(ctx, req.UserIDs)
 	onlineSet := make(map[int]bool, len(onlineUserIDs))
 	for _, id := range onlineUserIDs {
 		onlineSet[id] = true
 	}
 
+	lastSeen, err := uc.visibleLastSeen(ctx, authUser.ID, req.UserIDs, onlineSet)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
 	statuses := make([]UserOnlineStatus, len(req.UserIDs))
 	for i, userID := range req.UserIDs {
 		statuses[i] = UserOnlineStatus{
 			UserID:   userID,
 			IsOnline: onlineSet[userID],
-			LastSeen: nil, // TODO: Implement last seen tracking with Redis
+		}
+		if at, ok := lastSeen[userID]; ok {
+			statuses[i].LastSeen = &at
 		}
 	}
 
@@ -164,3 +307,104 @@ func (uc *useCase) GetOnlineStatusByUsers(
 		Statuses: statuses,
 	}, nil
 }
+
+// visibleLastSeen returns when the offline users were last seen, leaving out
+// users who hide it from others and deleted users.
+func (uc *useCase) visibleLastSeen(
+	ctx context.Context,
+	callerID int,
+	userIDs []int,
+	online map[int]bool,
+) (map[int]time.Time, error) {
+	offlineIDs := make([]int, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !online[userID] {
+			offlineIDs = append(offlineIDs, userID)
+		}
+	}
+	if len(offlineIDs) == 0 {
+		return map[int]time.Time{}, nil
+	}
+
+	lastSeen := uc.onlineChecker.GetLastSeen(ctx, offlineIDs)
+	if len(lastSeen) == 0 {
+		return lastSeen, nil
+	}
+
+	users, err := uc.authPortal.GetUsersByIDs(ctx, offlineIDs)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[int]bool, len(users))
+	for _, u := range users {
+		visible[u.ID] = !u.HideLastSeen || u.ID == callerID
+	}
+
+	for userID := range lastSeen {
+		if !visible[userID] {
+			delete(lastSeen, userID)
+		}
+	}
+
+	return lastSeen, nil
+}
+
+func (uc *useCase) GetConnections(ctx context.Context, req GetConnectionsReq) (*GetConnectionsResp, error) {
+	const op = "notificationuc.GetConnections"
+
+	_, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	remote, err := uc.connections.RemoteConnectionCounts(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	users := make(map[int]*UserConnections)
+	user := func(userID int) *UserConnections {
+		if users[userID] == nil {
+			users[userID] = &UserConnections{UserID: userID, Connections: make([]ConnectionInfo, 0)}
+		}
+		return users[userID]
+	}
+
+	// Oldest connections first
+	conns := uc.connections.Connections()
+	slices.SortFunc(conns, func(a, b ws.Connection) int {
+		return a.ConnectedAt.Compare(b.ConnectedAt)
+	})
+
+	for _, conn := range conns {
+		u := user(conn.UserID)
+		u.ConnectionCount++
+		u.Connections = append(u.Connections, ConnectionInfo{
+			RemoteIP:        conn.RemoteIP,
+			ConnectedAt:     conn.ConnectedAt.Format(time.RFC3339),
+			LastActivityAt:  conn.LastActivity.Format(time.RFC3339),
+			SubscribedChats: conn.ChatCount,
+			SentSeq:         conn.SentSeq,
+			AckedSeq:        conn.AckedSeq,
+		})
+	}
+	for userID, count := range remote {
+		u := user(userID)
+		u.ConnectionCount += count
+		u.RemoteConnectionCount = count
+	}
+
+	resp := &GetConnectionsResp{
+		Users: make([]UserConnections, 0, len(users)),
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, *u)
+		resp.TotalConnections += u.ConnectionCount
+	}
+	slices.SortFunc(resp.Users, func(a, b UserConnections) int {
+		return a.UserID - b.UserID
+	})
+	resp.TotalUsers = len(resp.Users)
+
+	return resp, nil
+}
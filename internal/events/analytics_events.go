@@ -0,0 +1,35 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AnalyticsEvent represents an anonymized product usage event.
+type AnalyticsEvent struct {
+	EventID     string            `json:"event_id"`
+	Type        string            `json:"type"`
+	AnonymousID string            `json:"anonymous_id"` // Keyed hash of the user ID, stable per user
+	WorkspaceID int               `json:"workspace_id,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+	OccurredAt  time.Time         `json:"occurred_at"`
+}
+
+// Marshal marshals the event to JSON.
+func (e AnalyticsEvent) Marshal() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalAnalyticsEvent unmarshals the event from JSON.
+func UnmarshalAnalyticsEvent(data []byte) (AnalyticsEvent, error) {
+	var event AnalyticsEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return AnalyticsEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // UserRegisteredEvent represents a user registration event.
@@ -29,3 +30,112 @@ func UnmarshalUserRegisteredEvent(data []byte) (UserRegisteredEvent, error) {
 	}
 	return event, nil
 }
+
+// UserInvitedEvent represents an invite of an unregistered email address to a chat.
+type UserInvitedEvent struct {
+	Email       string    `json:"email"`
+	InviterName string    `json:"inviter_name"`
+	ChatName    string    `json:"chat_name"`
+	RegisterURL string    `json:"register_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Marshal marshals the event to JSON.
+func (e UserInvitedEvent) Marshal() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalUserInvitedEvent unmarshals the event from JSON.
+func UnmarshalUserInvitedEvent(data []byte) (UserInvitedEvent, error) {
+	var event UserInvitedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return UserInvitedEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
+
+// JoinRequestedEvent represents a request of a user to join a group, sent to
+// the group's creator.
+type JoinRequestedEvent struct {
+	Email         string    `json:"email"`
+	RequesterName string    `json:"requester_name"`
+	ChatName      string    `json:"chat_name"`
+	RequestedAt   time.Time `json:"requested_at"`
+}
+
+// Marshal marshals the event to JSON.
+func (e JoinRequestedEvent) Marshal() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalJoinRequestedEvent unmarshals the event from JSON.
+func UnmarshalJoinRequestedEvent(data []byte) (JoinRequestedEvent, error) {
+	var event JoinRequestedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return JoinRequestedEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
+
+// ContactRequestedEvent represents a request of a user to become another
+// user's contact, sent to the addressee.
+type ContactRequestedEvent struct {
+	Email         string    `json:"email"`
+	RequesterName string    `json:"requester_name"`
+	RequestedAt   time.Time `json:"requested_at"`
+}
+
+// Marshal marshals the event to JSON.
+func (e ContactRequestedEvent) Marshal() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalContactRequestedEvent unmarshals the event from JSON.
+func UnmarshalContactRequestedEvent(data []byte) (ContactRequestedEvent, error) {
+	var event ContactRequestedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return ContactRequestedEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
+
+// EmailVerificationRequestedEvent represents a request to confirm a user's email address.
+type EmailVerificationRequestedEvent struct {
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	VerifyURL string    `json:"verify_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// EmailChange is set when Email is a new address the user wants to
+	// switch to rather than the one they signed up with
+	EmailChange bool `json:"email_change,omitempty"`
+}
+
+// Marshal marshals the event to JSON.
+func (e EmailVerificationRequestedEvent) Marshal() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalEmailVerificationRequestedEvent unmarshals the event from JSON.
+func UnmarshalEmailVerificationRequestedEvent(data []byte) (EmailVerificationRequestedEvent, error) {
+	var event EmailVerificationRequestedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return EmailVerificationRequestedEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
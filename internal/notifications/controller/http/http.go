@@ -0,0 +1,63 @@
+package http
+
+import (
+	"chatx-01-backend/internal/notifications/usecase/inboxuc"
+	"chatx-01-backend/internal/portal/auth"
+	"net/http"
+)
+
+type ctrl struct {
+	mux    *http.ServeMux
+	prefix string
+
+	inboxUsecase inboxuc.UseCase
+
+	authPr auth.Portal
+}
+
+func Register(
+	mux *http.ServeMux,
+	prefix string,
+	inboxUsecase inboxuc.UseCase,
+	authPr auth.Portal,
+) {
+	c := &ctrl{
+		mux:          mux,
+		prefix:       prefix,
+		inboxUsecase: inboxUsecase,
+		authPr:       authPr,
+	}
+
+	c.registerHandlers()
+}
+
+// registerHandlers registers all handlers.
+func (c *ctrl) registerHandlers() {
+	// inbox endpoints
+	c.register(http.MethodGet, "", http.HandlerFunc(c.getNotifications), c.authPr.RequireAuth())
+	c.register(http.MethodGet, "/unread-count", http.HandlerFunc(c.getUnreadCount), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/read", http.HandlerFunc(c.markRead), c.authPr.RequireAuth())
+	c.register(http.MethodPost, "/read-all", http.HandlerFunc(c.markAllRead), c.authPr.RequireAuth())
+
+	// announcement endpoints
+	c.register(
+		http.MethodPost,
+		"/announcements",
+		http.HandlerFunc(c.createAnnouncement),
+		c.authPr.RequireWorkspaceAdmin(),
+	)
+}
+
+func (c *ctrl) register(
+	method string,
+	path string,
+	handler http.Handler,
+	middlewares ...func(http.Handler) http.Handler,
+) {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	fullPath := c.prefix + path
+	c.mux.Handle(method+" "+fullPath, handler)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"chatx-01-backend/internal/notifications/usecase/inboxuc"
+	"chatx-01-backend/pkg/httptools"
+	"net/http"
+)
+
+func (c *ctrl) getNotifications(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[inboxuc.GetNotificationsReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.inboxUsecase.GetNotifications(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) getUnreadCount(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[inboxuc.GetUnreadCountReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.inboxUsecase.GetUnreadCount(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) markRead(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[inboxuc.MarkReadReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.inboxUsecase.MarkRead(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) markAllRead(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[inboxuc.MarkAllReadReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.inboxUsecase.MarkAllRead(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusOK, w, resp)
+}
+
+func (c *ctrl) createAnnouncement(w http.ResponseWriter, r *http.Request) {
+	req, err := httptools.BindRequest[inboxuc.CreateAnnouncementReq](r)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	resp, err := c.inboxUsecase.CreateAnnouncement(r.Context(), req)
+	if err != nil {
+		httptools.HandleError(w, r, err)
+		return
+	}
+
+	httptools.WriteResponse(http.StatusCreated, w, resp)
+}
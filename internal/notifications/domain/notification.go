@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationType is the kind of event an inbox item is about.
+type NotificationType string
+
+const (
+	NotificationMention      NotificationType = "mention"
+	NotificationChatInvite   NotificationType = "chat_invite"
+	NotificationAnnouncement NotificationType = "announcement"
+)
+
+func (t NotificationType) IsValid() bool {
+	return t == NotificationMention || t == NotificationChatInvite || t == NotificationAnnouncement
+}
+
+// Notification is an item of a user's inbox in a workspace. It is independent
+// of the unread counters of chats.
+type Notification struct {
+	ID          int
+	WorkspaceID int
+	UserID      int
+	Type        NotificationType
+	ActorID     *int // User who caused the notification
+	ChatID      *int
+	MessageID   *int
+	Text        string
+	CreatedAt   time.Time
+	ReadAt      *time.Time
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationListParams selects a page of a user's inbox, newest first.
+type NotificationListParams struct {
+	WorkspaceID int
+	UserID      int
+	UnreadOnly  bool
+	Offset      int
+	Limit       int
+}
+
+// NotificationRepository defines the interface for inbox data access.
+type NotificationRepository interface {
+	// CreateBatch stores the notifications and sets their IDs.
+	CreateBatch(ctx context.Context, notifications []*Notification) error
+
+	// ListWithCount returns a page of a user's notifications and the total
+	// number matching the params.
+	ListWithCount(ctx context.Context, params NotificationListParams) ([]Notification, int, error)
+
+	// CountUnread returns the number of unread notifications of a user in a
+	// workspace.
+	CountUnread(ctx context.Context, workspaceID, userID int) (int, error)
+
+	// MarkRead marks the user's notifications with the given IDs as read.
+	// IDs of other users' or already read notifications are skipped.
+	// Returns the number of notifications marked.
+	MarkRead(ctx context.Context, userID int, ids []int, readAt time.Time) (int, error)
+
+	// MarkAllRead marks all unread notifications of a user in a workspace as read.
+	// Returns the number of notifications marked.
+	MarkAllRead(ctx context.Context, workspaceID, userID int, readAt time.Time) (int, error)
+}
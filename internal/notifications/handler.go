@@ -3,10 +3,9 @@ package notifications
 import (
 	"chatx-01-backend/internal/events"
 	"chatx-01-backend/internal/notifications/usecase"
+	eventbus "chatx-01-backend/pkg/events"
 	"context"
 	"fmt"
-
-	"github.com/IBM/sarama"
 )
 
 // Handler handles notification events.
@@ -22,7 +21,7 @@ func NewHandler(notificationUC usecase.UseCase) *Handler {
 }
 
 // HandleUserRegistration handles user registration events.
-func (h *Handler) HandleUserRegistration(ctx context.Context, msg *sarama.ConsumerMessage) error {
+func (h *Handler) HandleUserRegistration(ctx context.Context, msg *eventbus.Message) error {
 	// Parse event
 	event, err := events.UnmarshalUserRegisteredEvent(msg.Value)
 	if err != nil {
@@ -36,3 +35,70 @@ func (h *Handler) HandleUserRegistration(ctx context.Context, msg *sarama.Consum
 		Password: event.Password,
 	})
 }
+
+// HandleUserInvite handles chat invite events.
+func (h *Handler) HandleUserInvite(ctx context.Context, msg *eventbus.Message) error {
+	// Parse event
+	event, err := events.UnmarshalUserInvitedEvent(msg.Value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	// Forward to use case
+	return h.notificationUC.SendInviteEmail(ctx, usecase.SendInviteEmailReq{
+		Email:       event.Email,
+		InviterName: event.InviterName,
+		ChatName:    event.ChatName,
+		RegisterURL: event.RegisterURL,
+		ExpiresAt:   event.ExpiresAt,
+	})
+}
+
+// HandleJoinRequest handles group join request events.
+func (h *Handler) HandleJoinRequest(ctx context.Context, msg *eventbus.Message) error {
+	// Parse event
+	event, err := events.UnmarshalJoinRequestedEvent(msg.Value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	// Forward to use case
+	return h.notificationUC.SendJoinRequestEmail(ctx, usecase.SendJoinRequestEmailReq{
+		Email:         event.Email,
+		RequesterName: event.RequesterName,
+		ChatName:      event.ChatName,
+	})
+}
+
+// HandleContactRequest handles contact request events.
+func (h *Handler) HandleContactRequest(ctx context.Context, msg *eventbus.Message) error {
+	// Parse event
+	event, err := events.UnmarshalContactRequestedEvent(msg.Value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	// Forward to use case
+	return h.notificationUC.SendContactRequestEmail(ctx, usecase.SendContactRequestEmailReq{
+		Email:         event.Email,
+		RequesterName: event.RequesterName,
+	})
+}
+
+// HandleEmailVerification handles email verification events.
+func (h *Handler) HandleEmailVerification(ctx context.Context, msg *eventbus.Message) error {
+	// Parse event
+	event, err := events.UnmarshalEmailVerificationRequestedEvent(msg.Value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	// Forward to use case
+	return h.notificationUC.SendVerificationEmail(ctx, usecase.SendVerificationEmailReq{
+		Email:       event.Email,
+		Username:    event.Username,
+		VerifyURL:   event.VerifyURL,
+		ExpiresAt:   event.ExpiresAt,
+		EmailChange: event.EmailChange,
+	})
+}
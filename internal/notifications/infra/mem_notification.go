@@ -0,0 +1,103 @@
+package infra
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"chatx-01-backend/internal/notifications/domain"
+)
+
+// MemNotificationRepo keeps notifications in process for the dev command.
+type MemNotificationRepo struct {
+	mu            sync.RWMutex
+	notifications []*domain.Notification // Oldest first
+	lastID        int
+}
+
+func NewMemNotificationRepo() *MemNotificationRepo {
+	return &MemNotificationRepo{}
+}
+
+func (r *MemNotificationRepo) CreateBatch(ctx context.Context, notifications []*domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range notifications {
+		r.lastID++
+		n.ID = r.lastID
+
+		stored := *n
+		r.notifications = append(r.notifications, &stored)
+	}
+
+	return nil
+}
+
+func (r *MemNotificationRepo) ListWithCount(
+	ctx context.Context,
+	params domain.NotificationListParams,
+) ([]domain.Notification, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matching := make([]domain.Notification, 0)
+	for i := len(r.notifications) - 1; i >= 0; i-- {
+		n := r.notifications[i]
+		if n.WorkspaceID == params.WorkspaceID && n.UserID == params.UserID &&
+			(!params.UnreadOnly || !n.IsRead()) {
+			matching = append(matching, *n)
+		}
+	}
+
+	total := len(matching)
+	start := min(params.Offset, total)
+	end := min(start+params.Limit, total)
+
+	return matching[start:end], total, nil
+}
+
+func (r *MemNotificationRepo) CountUnread(ctx context.Context, workspaceID, userID int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, n := range r.notifications {
+		if n.WorkspaceID == workspaceID && n.UserID == userID && !n.IsRead() {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MemNotificationRepo) MarkRead(ctx context.Context, userID int, ids []int, readAt time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	marked := 0
+	for _, n := range r.notifications {
+		if n.UserID == userID && !n.IsRead() && slices.Contains(ids, n.ID) {
+			n.ReadAt = &readAt
+			marked++
+		}
+	}
+
+	return marked, nil
+}
+
+func (r *MemNotificationRepo) MarkAllRead(ctx context.Context, workspaceID, userID int, readAt time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	marked := 0
+	for _, n := range r.notifications {
+		if n.WorkspaceID == workspaceID && n.UserID == userID && !n.IsRead() {
+			n.ReadAt = &readAt
+			marked++
+		}
+	}
+
+	return marked, nil
+}
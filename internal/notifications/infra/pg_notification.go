@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"chatx-01-backend/internal/notifications/domain"
+	"chatx-01-backend/pkg/pg"
+)
+
+type PgNotificationRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgNotificationRepo(pool *pgxpool.Pool) *PgNotificationRepo {
+	return &PgNotificationRepo{
+		pool: pool,
+	}
+}
+
+func (r *PgNotificationRepo) CreateBatch(ctx context.Context, notifications []*domain.Notification) error {
+	const op = "pgnotification.CreateBatch"
+
+	query := `
+		INSERT INTO notifications (workspace_id, user_id, type, actor_id, chat_id, message_id, text, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	// One round trip for all recipients of an announcement
+	batch := &pgx.Batch{}
+	for _, n := range notifications {
+		batch.Queue(
+			query,
+			n.WorkspaceID,
+			n.UserID,
+			n.Type,
+			n.ActorID,
+			n.ChatID,
+			n.MessageID,
+			n.Text,
+			n.CreatedAt,
+		).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&n.ID)
+		})
+	}
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return pg.WrapRepoError(op, err)
+	}
+
+	return nil
+}
+
+func (r *PgNotificationRepo) ListWithCount(
+	ctx context.Context,
+	params domain.NotificationListParams,
+) ([]domain.Notification, int, error) {
+	const op = "pgnotification.ListWithCount"
+
+	where := "WHERE workspace_id = $1 AND user_id = $2"
+	if params.UnreadOnly {
+		where += " AND read_at IS NULL"
+	}
+
+	var totalCount int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM notifications `+where, params.WorkspaceID, params.UserID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	query := `
+		SELECT id, workspace_id, user_id, type, actor_id, chat_id, message_id, text, created_at, read_at
+		FROM notifications
+		` + where + `
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.pool.Query(ctx, query, params.WorkspaceID, params.UserID, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+	defer rows.Close()
+
+	notifications := make([]domain.Notification, 0)
+	for rows.Next() {
+		n := domain.Notification{}
+		err := rows.Scan(
+			&n.ID,
+			&n.WorkspaceID,
+			&n.UserID,
+			&n.Type,
+			&n.ActorID,
+			&n.ChatID,
+			&n.MessageID,
+			&n.Text,
+			&n.CreatedAt,
+			&n.ReadAt,
+		)
+		if err != nil {
+			return nil, 0, pg.WrapRepoError(op, err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, pg.WrapRepoError(op, err)
+	}
+
+	return notifications, totalCount, nil
+}
+
+func (r *PgNotificationRepo) CountUnread(ctx context.Context, workspaceID, userID int) (int, error) {
+	const op = "pgnotification.CountUnread"
+
+	query := `SELECT COUNT(*) FROM notifications WHERE workspace_id = $1 AND user_id = $2 AND read_at IS NULL`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, workspaceID, userID).Scan(&count); err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return count, nil
+}
+
+func (r *PgNotificationRepo) MarkRead(ctx context.Context, userID int, ids []int, readAt time.Time) (int, error) {
+	const op = "pgnotification.MarkRead"
+
+	query := `
+		UPDATE notifications
+		SET read_at = $3
+		WHERE user_id = $1 AND id = ANY($2) AND read_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, userID, ids, readAt)
+	if err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *PgNotificationRepo) MarkAllRead(ctx context.Context, workspaceID, userID int, readAt time.Time) (int, error) {
+	const op = "pgnotification.MarkAllRead"
+
+	query := `
+		UPDATE notifications
+		SET read_at = $3
+		WHERE workspace_id = $1 AND user_id = $2 AND read_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, workspaceID, userID, readAt)
+	if err != nil {
+		return 0, pg.WrapRepoError(op, err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,76 @@
+package portal
+
+import (
+	"context"
+	"time"
+
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/notifications/domain"
+	"chatx-01-backend/internal/portal/notifications"
+)
+
+// Interface guard.
+var _ notifications.Portal = (*Portal)(nil)
+
+type Portal struct {
+	notificationRepo domain.NotificationRepository
+	broadcaster      ws.Broadcaster
+}
+
+func New(
+	notificationRepo domain.NotificationRepository,
+	broadcaster ws.Broadcaster,
+) *Portal {
+	return &Portal{
+		notificationRepo: notificationRepo,
+		broadcaster:      broadcaster,
+	}
+}
+
+func (p *Portal) Notify(ctx context.Context, items []notifications.Notification) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	stored := make([]*domain.Notification, 0, len(items))
+	for _, item := range items {
+		stored = append(stored, &domain.Notification{
+			WorkspaceID: item.WorkspaceID,
+			UserID:      item.UserID,
+			Type:        domain.NotificationType(item.Type),
+			ActorID:     optionalID(item.ActorID),
+			ChatID:      optionalID(item.ChatID),
+			MessageID:   optionalID(item.MessageID),
+			Text:        item.Text,
+			CreatedAt:   now,
+		})
+	}
+
+	if err := p.notificationRepo.CreateBatch(ctx, stored); err != nil {
+		return err
+	}
+
+	for _, n := range stored {
+		p.broadcaster.BroadcastNotification(n.UserID, ws.NotificationPayload{
+			ID:        n.ID,
+			Type:      string(n.Type),
+			ActorID:   n.ActorID,
+			ChatID:    n.ChatID,
+			MessageID: n.MessageID,
+			Text:      n.Text,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	return nil
+}
+
+// optionalID maps the 0 of a portal notification to a missing ID.
+func optionalID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,124 @@
+package inboxuc
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+type UseCase interface {
+	GetNotifications(ctx context.Context, req GetNotificationsReq) (*GetNotificationsResp, error)
+	GetUnreadCount(ctx context.Context, req GetUnreadCountReq) (*GetUnreadCountResp, error)
+	MarkRead(ctx context.Context, req MarkReadReq) (*MarkReadResp, error)
+	MarkAllRead(ctx context.Context, req MarkAllReadReq) (*MarkReadResp, error)
+	CreateAnnouncement(ctx context.Context, req CreateAnnouncementReq) (*CreateAnnouncementResp, error)
+}
+
+type GetNotificationsReq struct {
+	Unread bool `query:"unread"` // Only unread notifications
+	Page   int  `query:"page"`
+	Limit  int  `query:"limit" default:"20"`
+}
+
+func (req GetNotificationsReq) Validate() error {
+	var verr error
+
+	if req.Page < 0 {
+		verr = errs.AddFieldError(verr, "page", "page must be non-negative")
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		verr = errs.AddFieldError(verr, "limit", "limit must be between 1 and 100")
+	}
+
+	return verr
+}
+
+type GetNotificationsResp struct {
+	Notifications []NotificationDTO `json:"notifications"`
+	Total         int               `json:"total"`
+	Page          int               `json:"page"`
+	Limit         int               `json:"limit"`
+}
+
+type NotificationDTO struct {
+	ID        int     `json:"id"`
+	Type      string  `json:"type"`
+	ActorID   *int    `json:"actor_id"`
+	ChatID    *int    `json:"chat_id"`
+	MessageID *int    `json:"message_id"`
+	Text      string  `json:"text"`
+	CreatedAt string  `json:"created_at"`
+	ReadAt    *string `json:"read_at"`
+}
+
+type GetUnreadCountReq struct{}
+
+func (req GetUnreadCountReq) Validate() error {
+	return nil
+}
+
+type GetUnreadCountResp struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+// maxMarkIDs is the number of notifications one request can mark as read.
+const maxMarkIDs = 100
+
+type MarkReadReq struct {
+	IDs []int `json:"ids"`
+}
+
+func (req MarkReadReq) Validate() error {
+	var verr error
+
+	if len(req.IDs) == 0 {
+		verr = errs.AddFieldError(verr, "ids", "at least one notification id is required")
+	}
+	if len(req.IDs) > maxMarkIDs {
+		verr = errs.AddFieldError(verr, "ids", "cannot mark more than 100 notifications at once")
+	}
+	for _, id := range req.IDs {
+		if id <= 0 {
+			verr = errs.AddFieldError(verr, "ids", "invalid notification id")
+			break
+		}
+	}
+
+	return verr
+}
+
+type MarkAllReadReq struct{}
+
+func (req MarkAllReadReq) Validate() error {
+	return nil
+}
+
+type MarkReadResp struct {
+	Marked int `json:"marked"`
+}
+
+// maxAnnouncementLength is the maximum length of an announcement in characters.
+const maxAnnouncementLength = 2000
+
+type CreateAnnouncementReq struct {
+	Text string `json:"text"`
+}
+
+func (req CreateAnnouncementReq) Validate() error {
+	var verr error
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		verr = errs.AddFieldError(verr, "text", "announcement text is required")
+	}
+	if utf8.RuneCountInString(text) > maxAnnouncementLength {
+		verr = errs.AddFieldError(verr, "text", "announcement text must be 2000 characters or less")
+	}
+
+	return verr
+}
+
+type CreateAnnouncementResp struct {
+	Recipients int `json:"recipients"`
+}
@@ -0,0 +1,205 @@
+package inboxuc
+
+import (
+	"chatx-01-backend/internal/chat/controller/ws"
+	"chatx-01-backend/internal/notifications/domain"
+	"chatx-01-backend/internal/portal/auth"
+	"chatx-01-backend/pkg/errs"
+	"context"
+	"strings"
+	"time"
+)
+
+type useCase struct {
+	notificationRepo domain.NotificationRepository
+	authPortal       auth.Portal
+	broadcaster      ws.Broadcaster
+}
+
+// New creates a new inbox use case.
+func New(
+	notificationRepo domain.NotificationRepository,
+	authPortal auth.Portal,
+	broadcaster ws.Broadcaster,
+) UseCase {
+	return &useCase{
+		notificationRepo: notificationRepo,
+		authPortal:       authPortal,
+		broadcaster:      broadcaster,
+	}
+}
+
+// GetNotifications lists the user's notifications in the token's workspace,
+// newest first.
+func (uc *useCase) GetNotifications(ctx context.Context, req GetNotificationsReq) (*GetNotificationsResp, error) {
+	const op = "inboxuc.GetNotifications"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	notifications, total, err := uc.notificationRepo.ListWithCount(ctx, domain.NotificationListParams{
+		WorkspaceID: authUser.WorkspaceID,
+		UserID:      authUser.ID,
+		UnreadOnly:  req.Unread,
+		Offset:      req.Page * req.Limit,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	dtos := make([]NotificationDTO, 0, len(notifications))
+	for _, n := range notifications {
+		dtos = append(dtos, notificationDTO(&n))
+	}
+
+	return &GetNotificationsResp{
+		Notifications: dtos,
+		Total:         total,
+		Page:          req.Page,
+		Limit:         req.Limit,
+	}, nil
+}
+
+func (uc *useCase) GetUnreadCount(ctx context.Context, req GetUnreadCountReq) (*GetUnreadCountResp, error) {
+	const op = "inboxuc.GetUnreadCount"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	count, err := uc.notificationRepo.CountUnread(ctx, authUser.WorkspaceID, authUser.ID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &GetUnreadCountResp{
+		UnreadCount: count,
+	}, nil
+}
+
+// MarkRead marks notifications of the user as read. IDs that are unknown,
+// belong to someone else or are already read are skipped.
+func (uc *useCase) MarkRead(ctx context.Context, req MarkReadReq) (*MarkReadResp, error) {
+	const op = "inboxuc.MarkRead"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	marked, err := uc.notificationRepo.MarkRead(ctx, authUser.ID, req.IDs, time.Now())
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &MarkReadResp{
+		Marked: marked,
+	}, nil
+}
+
+func (uc *useCase) MarkAllRead(ctx context.Context, req MarkAllReadReq) (*MarkReadResp, error) {
+	const op = "inboxuc.MarkAllRead"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	marked, err := uc.notificationRepo.MarkAllRead(ctx, authUser.WorkspaceID, authUser.ID, time.Now())
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return &MarkReadResp{
+		Marked: marked,
+	}, nil
+}
+
+// CreateAnnouncement adds an announcement from a workspace admin to the inbox
+// of every other member of the token's workspace.
+func (uc *useCase) CreateAnnouncement(
+	ctx context.Context,
+	req CreateAnnouncementReq,
+) (*CreateAnnouncementResp, error) {
+	const op = "inboxuc.CreateAnnouncement"
+
+	authUser, err := uc.authPortal.GetAuthUser(ctx)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	memberIDs, err := uc.authPortal.GetWorkspaceMemberIDs(ctx, authUser.WorkspaceID)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	now := time.Now()
+	text := strings.TrimSpace(req.Text)
+
+	notifications := make([]*domain.Notification, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if memberID == authUser.ID {
+			continue
+		}
+
+		notifications = append(notifications, &domain.Notification{
+			WorkspaceID: authUser.WorkspaceID,
+			UserID:      memberID,
+			Type:        domain.NotificationAnnouncement,
+			ActorID:     &authUser.ID,
+			Text:        text,
+			CreatedAt:   now,
+		})
+	}
+
+	if len(notifications) > 0 {
+		if err := uc.notificationRepo.CreateBatch(ctx, notifications); err != nil {
+			return nil, errs.Wrap(op, err)
+		}
+	}
+
+	for _, n := range notifications {
+		uc.broadcaster.BroadcastNotification(n.UserID, notificationPayload(n))
+	}
+
+	return &CreateAnnouncementResp{
+		Recipients: len(notifications),
+	}, nil
+}
+
+// notificationPayload converts a stored notification to its WebSocket event
+// payload.
+func notificationPayload(n *domain.Notification) ws.NotificationPayload {
+	return ws.NotificationPayload{
+		ID:        n.ID,
+		Type:      string(n.Type),
+		ActorID:   n.ActorID,
+		ChatID:    n.ChatID,
+		MessageID: n.MessageID,
+		Text:      n.Text,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+func notificationDTO(n *domain.Notification) NotificationDTO {
+	var readAt *string
+	if n.ReadAt != nil {
+		formatted := n.ReadAt.Format(time.RFC3339)
+		readAt = &formatted
+	}
+
+	return NotificationDTO{
+		ID:        n.ID,
+		Type:      string(n.Type),
+		ActorID:   n.ActorID,
+		ChatID:    n.ChatID,
+		MessageID: n.MessageID,
+		Text:      n.Text,
+		CreatedAt: n.CreatedAt.Format(time.RFC3339),
+		ReadAt:    readAt,
+	}
+}
@@ -1,9 +1,16 @@
 package usecase
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type UseCase interface {
 	SendWelcomeEmail(ctx context.Context, req SendWelcomeEmailReq) error
+	SendInviteEmail(ctx context.Context, req SendInviteEmailReq) error
+	SendVerificationEmail(ctx context.Context, req SendVerificationEmailReq) error
+	SendJoinRequestEmail(ctx context.Context, req SendJoinRequestEmailReq) error
+	SendContactRequestEmail(ctx context.Context, req SendContactRequestEmailReq) error
 }
 
 type SendWelcomeEmailReq struct {
@@ -11,3 +18,30 @@ type SendWelcomeEmailReq struct {
 	Username string
 	Password string
 }
+
+type SendInviteEmailReq struct {
+	Email       string
+	InviterName string
+	ChatName    string
+	RegisterURL string
+	ExpiresAt   time.Time
+}
+
+type SendJoinRequestEmailReq struct {
+	Email         string
+	RequesterName string
+	ChatName      string
+}
+
+type SendContactRequestEmailReq struct {
+	Email         string
+	RequesterName string
+}
+
+type SendVerificationEmailReq struct {
+	Email       string
+	Username    string
+	VerifyURL   string
+	ExpiresAt   time.Time
+	EmailChange bool // Email is a new address replacing the user's current one
+}
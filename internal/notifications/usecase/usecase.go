@@ -9,18 +9,20 @@ import (
 
 type useCase struct {
 	emailSender email.Sender
+	logger      *slog.Logger
 }
 
-func New(emailSender email.Sender) UseCase {
+func New(emailSender email.Sender, logger *slog.Logger) UseCase {
 	return &useCase{
 		emailSender: emailSender,
+		logger:      logger,
 	}
 }
 
 func (uc *useCase) SendWelcomeEmail(ctx context.Context, req SendWelcomeEmailReq) error {
 	const op = "notificationuc.SendWelcomeEmail"
 
-	slog.Info("processing user registration event",
+	uc.logger.InfoContext(ctx, "processing user registration event",
 		"email", req.Email,
 		"username", req.Username,
 	)
@@ -37,10 +39,124 @@ func (uc *useCase) SendWelcomeEmail(ctx context.Context, req SendWelcomeEmailReq
 		return errs.Wrap(op, err)
 	}
 
-	slog.Info("welcome email sent successfully",
+	uc.logger.InfoContext(ctx, "welcome email sent successfully",
 		"email", req.Email,
 		"username", req.Username,
 	)
 
 	return nil
 }
+
+func (uc *useCase) SendInviteEmail(ctx context.Context, req SendInviteEmailReq) error {
+	const op = "notificationuc.SendInviteEmail"
+
+	uc.logger.InfoContext(ctx, "processing user invite event",
+		"email", req.Email,
+		"chat_name", req.ChatName,
+	)
+
+	// Build invite email
+	inviteEmail, err := email.BuildInviteEmail(req.Email, req.InviterName, req.ChatName, req.RegisterURL, req.ExpiresAt)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	// Send email
+	err = uc.emailSender.Send(inviteEmail)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.logger.InfoContext(ctx, "invite email sent successfully",
+		"email", req.Email,
+		"chat_name", req.ChatName,
+	)
+
+	return nil
+}
+
+func (uc *useCase) SendVerificationEmail(ctx context.Context, req SendVerificationEmailReq) error {
+	const op = "notificationuc.SendVerificationEmail"
+
+	uc.logger.InfoContext(ctx, "processing email verification event",
+		"email", req.Email,
+		"username", req.Username,
+	)
+
+	// Build verification email
+	build := email.BuildVerificationEmail
+	if req.EmailChange {
+		build = email.BuildEmailChangeEmail
+	}
+	verificationEmail, err := build(req.Email, req.Username, req.VerifyURL, req.ExpiresAt)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	// Send email
+	err = uc.emailSender.Send(verificationEmail)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.logger.InfoContext(ctx, "verification email sent successfully",
+		"email", req.Email,
+		"username", req.Username,
+	)
+
+	return nil
+}
+
+func (uc *useCase) SendJoinRequestEmail(ctx context.Context, req SendJoinRequestEmailReq) error {
+	const op = "notificationuc.SendJoinRequestEmail"
+
+	uc.logger.InfoContext(ctx, "processing join request event",
+		"email", req.Email,
+		"chat_name", req.ChatName,
+	)
+
+	// Build join request email
+	joinRequestEmail, err := email.BuildJoinRequestEmail(req.Email, req.RequesterName, req.ChatName)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	// Send email
+	err = uc.emailSender.Send(joinRequestEmail)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.logger.InfoContext(ctx, "join request email sent successfully",
+		"email", req.Email,
+		"chat_name", req.ChatName,
+	)
+
+	return nil
+}
+
+func (uc *useCase) SendContactRequestEmail(ctx context.Context, req SendContactRequestEmailReq) error {
+	const op = "notificationuc.SendContactRequestEmail"
+
+	uc.logger.InfoContext(ctx, "processing contact request event",
+		"email", req.Email,
+	)
+
+	// Build contact request email
+	contactRequestEmail, err := email.BuildContactRequestEmail(req.Email, req.RequesterName)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	// Send email
+	err = uc.emailSender.Send(contactRequestEmail)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	uc.logger.InfoContext(ctx, "contact request email sent successfully",
+		"email", req.Email,
+	)
+
+	return nil
+}
@@ -3,19 +3,106 @@ package auth
 import (
 	"context"
 	"net/http"
+	"slices"
+	"time"
 )
 
+// Roles compared by other modules.
+const (
+	RoleAdmin          = "admin"
+	RoleModerator      = "moderator"
+	WorkspaceRoleAdmin = "admin"
+)
+
+// Permission is an action a platform role grants beyond what every user may
+// do.
+type Permission string
+
+const (
+	// PermDeleteAnyMessage allows deleting messages of other users.
+	PermDeleteAnyMessage Permission = "delete_any_message"
+	// PermManageAnyGroup allows managing, removing participants of and
+	// disbanding groups of the workspace the user didn't create.
+	PermManageAnyGroup Permission = "manage_any_group"
+	// PermManageRoles allows assigning platform roles.
+	PermManageRoles Permission = "manage_roles"
+)
+
+// rolePermissions is the policy of the permissions each platform role grants.
+var rolePermissions = map[string][]Permission{
+	RoleAdmin:     {PermDeleteAnyMessage, PermManageAnyGroup, PermManageRoles},
+	RoleModerator: {PermDeleteAnyMessage, PermManageAnyGroup},
+}
+
+// RolePermissions returns the permissions a platform role grants, never
+// nil.
+func RolePermissions(role string) []Permission {
+	return append([]Permission{}, rolePermissions[role]...)
+}
+
 type AuthenticatedUser struct {
-	ID   int
-	Role string
+	ID            int
+	Role          string
+	WorkspaceID   int       // Workspace the token was issued for
+	WorkspaceRole string    // Role in that workspace
+	APIKeyID      int       // Set when the request authenticated with an API key
+	ExpiresAt     time.Time // When the access token expires; zero for API keys
+}
+
+// IsWorkspaceAdmin reports whether the user administers the token's
+// workspace. Platform admins administer every workspace.
+func (au AuthenticatedUser) IsWorkspaceAdmin() bool {
+	return au.Role == RoleAdmin || au.WorkspaceRole == WorkspaceRoleAdmin
+}
+
+// Can reports whether the user's platform role grants perm.
+func (au AuthenticatedUser) Can(perm Permission) bool {
+	return slices.Contains(rolePermissions[au.Role], perm)
 }
 
 type User struct {
-	ID        int
-	Email     string
-	Username  string
-	Role      string
-	ImagePath *string
+	ID           int
+	PublicID     string
+	Email        string
+	Username     string
+	Role         string
+	ImagePath    *string
+	DisplayName  string // Empty if not set
+	Bio          string
+	HideLastSeen bool // The user hides when they were last online
+}
+
+// Reasons of spam reports raised by other modules.
+const (
+	SpamReasonMessageRate      = "message_rate"
+	SpamReasonDuplicateContent = "duplicate_content"
+)
+
+// SpamReport is a spam signal about a user raised by another module.
+type SpamReport struct {
+	UserID  int
+	Reason  string // One of the SpamReason constants
+	Details string // What triggered the report, without message content
+	Shadow  bool   // Withhold the user's messages until an admin resolves the flag
+}
+
+// Audit actions recorded by other modules.
+const (
+	AuditGroupDelete            = "group_delete"
+	AuditGroupOwnershipTransfer = "group_ownership_transfer"
+	AuditGroupParticipantRemove = "group_participant_remove"
+	AuditMessageDelete          = "message_delete"
+)
+
+// AuditEvent is a security-sensitive action to record in the audit log. Zero
+// IDs are left empty.
+type AuditEvent struct {
+	Action       string // One of the audit action constants
+	ActorID      int
+	TargetUserID int
+	ChatID       int
+	WorkspaceID  int
+	Details      string
 }
 
 type Portal interface {
@@ -26,20 +113,70 @@ type Portal interface {
 	SetAuthUser(ctx context.Context, au AuthenticatedUser) context.Context
 
 	// GetUserByID retrieves a user by their ID.
+	// Deleted users are returned with an anonymous placeholder profile.
 	GetUserByID(ctx context.Context, id int) (*User, error)
 
-	// GetUsersByIDs retrieves multiple users by their IDs.
+	// GetUsersByIDs retrieves multiple users by their IDs in one lookup, in
+	// no particular order. Missing users are skipped and deleted users get
+	// the same placeholder profile as GetUserByID.
 	GetUsersByIDs(ctx context.Context, ids []int) ([]*User, error)
 
-	// UserExists checks if a user exists by ID.
+	// GetUserByEmail retrieves an active user by email.
+	// Returns errs.ErrNotFound if no active user has the email.
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+
+	// UserExists checks if an active user exists by ID.
 	UserExists(ctx context.Context, id int) (bool, error)
 
+	// IsWorkspaceMember checks if a user belongs to a workspace.
+	IsWorkspaceMember(ctx context.Context, workspaceID, userID int) (bool, error)
+
+	// GetWorkspaceMemberIDs returns the IDs of the active members of a workspace.
+	GetWorkspaceMemberIDs(ctx context.Context, workspaceID int) ([]int, error)
+
+	// AddWorkspaceMember adds a user to a workspace as a member.
+	// Adding an existing member is a no-op.
+	AddWorkspaceMember(ctx context.Context, workspaceID, userID int) error
+
+	// ReportSpam flags a user for admin review. A user has at most one open
+	// flag per reason, so repeated reports are dropped.
+	ReportSpam(ctx context.Context, report SpamReport) error
+
+	// RecordAudit records an audit event with the client address and user
+	// agent of the request. Failures are logged rather than returned, so the
+	// audit log being down doesn't block the action.
+	RecordAudit(ctx context.Context, event AuditEvent)
+
+	// IsShadowLimited reports whether an open spam flag withholds the user's
+	// messages from other users.
+	IsShadowLimited(ctx context.Context, userID int) (bool, error)
+
+	// IsBlocked reports whether either user blocked the other.
+	IsBlocked(ctx context.Context, userID1, userID2 int) (bool, error)
+
+	// GetBlockedUserIDs returns the IDs of the users the user blocked or was
+	// blocked by.
+	GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error)
+
 	// RequireAuth returns a middleware that checks if the user is authenticated.
 	RequireAuth() func(next http.Handler) http.Handler
 
 	// RequireAdmin creates a middleware that checks if the user is authenticated and has admin role.
 	RequireAdmin() func(next http.Handler) http.Handler
 
+	// RequireWorkspaceAdmin creates a middleware that checks if the user is
+	// authenticated and administers the workspace of their token. Platform
+	// admins pass in every workspace.
+	RequireWorkspaceAdmin() func(next http.Handler) http.Handler
+
+	// RequireMember creates a middleware that checks if the user is authenticated
+	// and has a full account, rejecting guests.
+	RequireMember() func(next http.Handler) http.Handler
+
+	// RequireAuthPendingTerms works like RequireAuth but also lets through
+	// users who haven't accepted the current terms of service yet.
+	RequireAuthPendingTerms() func(next http.Handler) http.Handler
+
 	// ValidateToken validates a token string and returns the authenticated user.
 	// Used for WebSocket authentication where token comes from query params.
 	ValidateToken(ctx context.Context, tokenString string) (AuthenticatedUser, error)
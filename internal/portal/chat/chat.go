@@ -0,0 +1,37 @@
+package chat
+
+import (
+	"context"
+)
+
+// UserProfile is the public part of a user's profile visible to their contacts.
+type UserProfile struct {
+	ID          int
+	PublicID    string
+	Username    string
+	DisplayName string // Empty if not set
+	ImagePath   *string
+}
+
+type Portal interface {
+	// NotifyUserUpdated tells everyone sharing a chat with the user, and the
+	// user's other connections, that the user's profile changed.
+	NotifyUserUpdated(ctx context.Context, profile UserProfile) error
+
+	// NotifyContactRequested tells a user that the user in requester asked
+	// to become their contact.
+	NotifyContactRequested(ctx context.Context, userID int, requester UserProfile) error
+
+	// NotifyContactAccepted tells a user that the user in contact accepted
+	// their contact request.
+	NotifyContactAccepted(ctx context.Context, userID int, contact UserProfile) error
+
+	// AcceptInvites adds a newly registered user to the chats their email
+	// address was invited to and to the workspaces of those chats.
+	// Returns the IDs of the joined chats.
+	AcceptInvites(ctx context.Context, userID int, email string) ([]int, error)
+
+	// AddGuest adds a guest to the group chat their link was issued for and
+	// to its workspace. Returns the ID of the workspace.
+	AddGuest(ctx context.Context, chatID, userID int) (int, error)
+}
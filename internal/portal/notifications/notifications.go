@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"context"
+)
+
+// Types of inbox notifications other modules raise.
+const (
+	TypeMention    = "mention"     // The user was mentioned in a message
+	TypeChatInvite = "chat_invite" // The user was added to a group chat
+)
+
+// Notification is an item for a user's inbox. ActorID, ChatID and MessageID
+// are 0 when they don't apply.
+type Notification struct {
+	WorkspaceID int
+	UserID      int
+	Type        string // One of the Type constants
+	ActorID     int
+	ChatID      int
+	MessageID   int
+	Text        string // Preview shown in the inbox
+}
+
+type Portal interface {
+	// Notify adds the notifications to their users' inboxes and delivers
+	// them to the users' connections.
+	Notify(ctx context.Context, notifications []Notification) error
+}
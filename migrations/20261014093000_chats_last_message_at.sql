@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats ADD COLUMN last_message_at TIMESTAMPTZ;
+
+UPDATE chats c
+SET last_message_at = m.last_sent_at
+FROM (
+    SELECT chat_id, MAX(sent_at) AS last_sent_at
+    FROM messages
+    GROUP BY chat_id
+) m
+WHERE c.id = m.chat_id;
+
+CREATE INDEX idx_chats_activity ON chats ((COALESCE(last_message_at, created_at)) DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chats_activity;
+ALTER TABLE chats DROP COLUMN IF EXISTS last_message_at;
+-- +goose StatementEnd
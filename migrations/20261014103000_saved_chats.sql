@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats DROP CONSTRAINT chats_type_check;
+ALTER TABLE chats ADD CONSTRAINT chats_type_check CHECK (type IN ('direct', 'group', 'saved'));
+
+-- Each user has at most one Saved Messages chat.
+CREATE UNIQUE INDEX idx_chats_saved_creator ON chats(creator_id) WHERE type = 'saved';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chats_saved_creator;
+DELETE FROM chats WHERE type = 'saved';
+ALTER TABLE chats DROP CONSTRAINT chats_type_check;
+ALTER TABLE chats ADD CONSTRAINT chats_type_check CHECK (type IN ('direct', 'group'));
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE chats ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE messages ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX idx_users_public_id ON users(public_id);
+CREATE UNIQUE INDEX idx_chats_public_id ON chats(public_id);
+CREATE UNIQUE INDEX idx_messages_public_id ON messages(public_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE messages DROP COLUMN IF EXISTS public_id;
+ALTER TABLE chats DROP COLUMN IF EXISTS public_id;
+ALTER TABLE users DROP COLUMN IF EXISTS public_id;
+-- +goose StatementEnd
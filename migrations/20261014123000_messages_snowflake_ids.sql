@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Message IDs are generated by the application from now on. Existing serial
+-- IDs are far below any generated ID, so ordering by id is preserved.
+ALTER TABLE chat_participants ALTER COLUMN last_read_message_id TYPE BIGINT;
+ALTER TABLE messages ALTER COLUMN id TYPE BIGINT;
+ALTER TABLE messages ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS messages_id_seq;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Generated IDs do not fit INTEGER, so the columns stay BIGINT.
+CREATE SEQUENCE messages_id_seq OWNED BY messages.id;
+SELECT setval('messages_id_seq', COALESCE((SELECT MAX(id) FROM messages), 0) + 1, false);
+ALTER TABLE messages ALTER COLUMN id SET DEFAULT nextval('messages_id_seq');
+-- +goose StatementEnd
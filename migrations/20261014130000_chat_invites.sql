@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE chat_invites (
+    id SERIAL PRIMARY KEY,
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    inviter_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- One pending invite per chat and address; re-inviting refreshes it.
+CREATE UNIQUE INDEX idx_chat_invites_chat_id_email ON chat_invites(chat_id, lower(email));
+CREATE INDEX idx_chat_invites_email ON chat_invites(lower(email));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_invites;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Guests sign in through a chat link with a name only and expire automatically.
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user', 'guest'));
+
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_expires_at ON users(expires_at) WHERE expires_at IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM users WHERE role = 'guest';
+
+DROP INDEX IF EXISTS idx_users_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
+
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user'));
+-- +goose StatementEnd
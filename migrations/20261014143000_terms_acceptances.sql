@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every accepted terms of service / privacy policy version, kept as history.
+CREATE TABLE terms_acceptances (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version VARCHAR(64) NOT NULL,
+    accepted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, version)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS terms_acceptances;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE workspaces (
+    id SERIAL PRIMARY KEY,
+    public_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_workspaces_public_id ON workspaces(public_id);
+
+CREATE TABLE workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- Existing users and chats move to a default workspace; platform admins
+-- administer it.
+INSERT INTO workspaces (slug, name) VALUES ('default', 'Default');
+
+INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+SELECT w.id, u.id, CASE WHEN u.role = 'admin' THEN 'admin' ELSE 'member' END, u.created_at
+FROM users u, workspaces w
+WHERE w.slug = 'default';
+
+-- Saved messages chats are personal and belong to no workspace.
+ALTER TABLE chats ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE chats SET workspace_id = (SELECT id FROM workspaces WHERE slug = 'default')
+WHERE type <> 'saved';
+
+CREATE INDEX idx_chats_workspace_id ON chats(workspace_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Preferred language for error messages when a request doesn't ask for one.
+ALTER TABLE users ADD COLUMN locale VARCHAR(10);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+-- +goose StatementEnd
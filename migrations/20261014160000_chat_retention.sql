@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL follows the global MESSAGE_RETENTION_DAYS, 0 keeps messages forever
+ALTER TABLE chats ADD COLUMN retention_days INTEGER CHECK (retention_days >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS retention_days;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Messages of one chat and month moved to an object in the file store.
+CREATE TABLE message_archives (
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    object_path VARCHAR(500) NOT NULL,
+    message_count INTEGER NOT NULL,
+    min_id BIGINT NOT NULL,
+    max_id BIGINT NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    anonymized_at TIMESTAMPTZ,
+    PRIMARY KEY (chat_id, month)
+);
+
+-- Read pointers may reference archived messages, which no longer have a row.
+-- Message IDs only grow, so comparing against a missing ID still works.
+ALTER TABLE chat_participants DROP CONSTRAINT fk_chat_participants_last_read_message;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE chat_participants cp
+SET last_read_message_id = NULL
+WHERE last_read_message_id IS NOT NULL
+AND NOT EXISTS (SELECT 1 FROM messages m WHERE m.id = cp.last_read_message_id);
+
+ALTER TABLE chat_participants
+    ADD CONSTRAINT fk_chat_participants_last_read_message
+    FOREIGN KEY (last_read_message_id)
+    REFERENCES messages(id)
+    ON DELETE SET NULL;
+
+DROP TABLE IF EXISTS message_archives;
+-- +goose StatementEnd
@@ -0,0 +1,103 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Messages are range partitioned by ID, one partition per month. IDs are
+-- snowflakes, so a month's partition holds the IDs generated from the first
+-- millisecond of the month; the bound is
+-- (milliseconds since 2025-01-01 UTC) << 22, matching pkg/snowflake. Serial
+-- IDs from before snowflakes are far below the first bound and land in the
+-- first partition, which is open at the bottom.
+--
+-- The partitions for the coming months are created by the server, see
+-- PgPartitionRepo.
+ALTER TABLE messages RENAME TO messages_unpartitioned;
+
+CREATE TABLE messages (
+    id BIGINT NOT NULL,
+    chat_id INTEGER NOT NULL,
+    sender_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMPTZ,
+    public_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    entities JSONB NOT NULL DEFAULT '[]',
+    metadata JSONB NOT NULL DEFAULT '{}'
+) PARTITION BY RANGE (id);
+
+DO $$
+DECLARE
+    month_start TIMESTAMP;
+    lower_bound TEXT;
+BEGIN
+    FOR month_start IN
+        SELECT generate_series(
+            TIMESTAMP '2025-01-01',
+            date_trunc('month', now() AT TIME ZONE 'UTC') + interval '3 months',
+            interval '1 month'
+        )
+    LOOP
+        IF month_start = TIMESTAMP '2025-01-01' THEN
+            lower_bound := 'MINVALUE';
+        ELSE
+            lower_bound := (((extract(epoch FROM month_start) - 1735689600) * 1000)::BIGINT << 22)::TEXT;
+        END IF;
+
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF messages FOR VALUES FROM (%s) TO (%s)',
+            'messages_p' || to_char(month_start, 'YYYY_MM'),
+            lower_bound,
+            ((extract(epoch FROM month_start + interval '1 month') - 1735689600) * 1000)::BIGINT << 22
+        );
+    END LOOP;
+END $$;
+
+INSERT INTO messages (id, chat_id, sender_id, content, sent_at, edited_at, public_id, entities, metadata)
+SELECT id, chat_id, sender_id, content, sent_at, edited_at, public_id, entities, metadata
+FROM messages_unpartitioned;
+
+DROP TABLE messages_unpartitioned;
+
+ALTER TABLE messages ADD PRIMARY KEY (id);
+ALTER TABLE messages ADD FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE;
+ALTER TABLE messages ADD FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_messages_chat_id ON messages(chat_id, sent_at DESC);
+CREATE INDEX idx_messages_chat_id_id ON messages(chat_id, id DESC);
+CREATE INDEX idx_messages_sender_id ON messages(sender_id);
+CREATE INDEX idx_messages_sent_at ON messages(sent_at DESC);
+-- A unique index on a partitioned table must include the partition key.
+-- Public IDs are random UUIDs, so lookups only need the index.
+CREATE INDEX idx_messages_public_id ON messages(public_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE messages RENAME TO messages_partitioned;
+
+CREATE TABLE messages (
+    id BIGINT NOT NULL,
+    chat_id INTEGER NOT NULL,
+    sender_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMPTZ,
+    public_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    entities JSONB NOT NULL DEFAULT '[]',
+    metadata JSONB NOT NULL DEFAULT '{}'
+);
+
+INSERT INTO messages (id, chat_id, sender_id, content, sent_at, edited_at, public_id, entities, metadata)
+SELECT id, chat_id, sender_id, content, sent_at, edited_at, public_id, entities, metadata
+FROM messages_partitioned;
+
+DROP TABLE messages_partitioned;
+
+ALTER TABLE messages ADD PRIMARY KEY (id);
+ALTER TABLE messages ADD FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE;
+ALTER TABLE messages ADD FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_messages_chat_id ON messages(chat_id, sent_at DESC);
+CREATE INDEX idx_messages_chat_id_id ON messages(chat_id, id DESC);
+CREATE INDEX idx_messages_sender_id ON messages(sender_id);
+CREATE INDEX idx_messages_sent_at ON messages(sent_at DESC);
+CREATE UNIQUE INDEX idx_messages_public_id ON messages(public_id);
+-- +goose StatementEnd
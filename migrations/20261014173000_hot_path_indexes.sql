@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Message pages and last messages use idx_messages_chat_id_id (chat_id, id),
+-- archival uses idx_messages_chat_id (chat_id, sent_at); both exist on every
+-- partition of messages already.
+
+-- Chat lists and unread totals filter participants by user and join on
+-- chat_id. With chat_id in the index they are an index-only scan instead of
+-- a scan on user_id followed by heap lookups, so it replaces the user_id
+-- index.
+CREATE INDEX idx_chat_participants_user_id_chat_id ON chat_participants(user_id, chat_id);
+DROP INDEX IF EXISTS idx_chat_participants_user_id;
+
+-- Username search matches '%term%', which a btree index cannot serve; a
+-- trigram index turns the sequential scan of users into a bitmap index scan
+-- for terms of three or more characters.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_users_username_trgm ON users USING gin (username gin_trgm_ops) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- pg_trgm stays installed; other objects may depend on it.
+DROP INDEX IF EXISTS idx_users_username_trgm;
+CREATE INDEX idx_chat_participants_user_id ON chat_participants(user_id);
+DROP INDEX IF EXISTS idx_chat_participants_user_id_chat_id;
+-- +goose StatementEnd
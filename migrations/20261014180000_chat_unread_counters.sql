@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Unread messages per participant, kept up to date by the statements that
+-- send, delete and read messages. A missing row means no unread messages.
+CREATE TABLE chat_unread_counters (
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0 CHECK (count >= 0),
+    PRIMARY KEY (chat_id, user_id),
+    FOREIGN KEY (chat_id, user_id) REFERENCES chat_participants(chat_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_chat_unread_counters_user_id ON chat_unread_counters(user_id) WHERE count > 0;
+
+INSERT INTO chat_unread_counters (chat_id, user_id, count)
+SELECT cp.chat_id, cp.user_id, COUNT(*)
+FROM chat_participants cp
+INNER JOIN messages m ON m.chat_id = cp.chat_id
+WHERE m.sender_id != cp.user_id
+AND (cp.last_read_message_id IS NULL OR m.id > cp.last_read_message_id)
+GROUP BY cp.chat_id, cp.user_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_unread_counters;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Denormalized, changed by the statements that add and remove participants.
+ALTER TABLE chats ADD COLUMN participant_count INTEGER NOT NULL DEFAULT 0 CHECK (participant_count >= 0);
+
+UPDATE chats c
+SET participant_count = p.count
+FROM (
+    SELECT chat_id, COUNT(*) AS count
+    FROM chat_participants
+    GROUP BY chat_id
+) p
+WHERE p.chat_id = c.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS participant_count;
+-- +goose StatementEnd
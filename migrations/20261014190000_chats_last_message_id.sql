@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Denormalized like last_message_at, for chat list previews. There is no
+-- foreign key: messages is partitioned, and archival and retention remove
+-- messages without touching their chat.
+ALTER TABLE chats ADD COLUMN last_message_id BIGINT;
+
+UPDATE chats c
+SET last_message_id = m.id
+FROM (
+    SELECT chat_id, MAX(id) AS id
+    FROM messages
+    GROUP BY chat_id
+) m
+WHERE m.chat_id = c.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS last_message_id;
+-- +goose StatementEnd
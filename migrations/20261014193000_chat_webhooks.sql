@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Incoming webhooks post into a chat as their creator, so they go away with
+-- the chat or the creator. Only a hash of the secret is stored.
+CREATE TABLE chat_webhooks (
+    id SERIAL PRIMARY KEY,
+    public_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    creator_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    secret_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_chat_webhooks_public_id ON chat_webhooks(public_id);
+CREATE INDEX idx_chat_webhooks_chat_id ON chat_webhooks(chat_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_webhooks;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Spam signals raised about users, kept for admin review. A user has at most
+-- one open flag per reason; an open shadow flag withholds their messages.
+CREATE TABLE spam_flags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL CHECK (reason IN ('message_rate', 'duplicate_content', 'disposable_email')),
+    action VARCHAR(20) NOT NULL CHECK (action IN ('flag', 'shadow')),
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMPTZ,
+    resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_spam_flags_open ON spam_flags(user_id, reason) WHERE resolved_at IS NULL;
+CREATE INDEX idx_spam_flags_shadow ON spam_flags(user_id) WHERE resolved_at IS NULL AND action = 'shadow';
+CREATE INDEX idx_spam_flags_created_at ON spam_flags(created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS spam_flags;
+-- +goose StatementEnd
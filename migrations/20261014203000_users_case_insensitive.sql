@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emails and usernames are unique regardless of case, so "Alice" and "alice"
+-- are one account. Accounts that already clash keep the oldest one as is:
+-- later ones get their id appended to the username, and lose their email,
+-- which belongs to the same mailbox as the oldest account's. They can still
+-- log in by username.
+UPDATE users u
+SET username = u.username || '_' || u.id
+WHERE u.role != 'guest' AND u.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM users o
+    WHERE lower(o.username) = lower(u.username) AND o.role != 'guest' AND o.deleted_at IS NULL AND o.id < u.id
+  );
+
+UPDATE users u
+SET email = NULL
+WHERE u.email IS NOT NULL AND u.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM users o
+    WHERE lower(o.email) = lower(u.email) AND o.deleted_at IS NULL AND o.id < u.id
+  );
+
+-- Guests pick a display name when joining, it doesn't identify them.
+CREATE UNIQUE INDEX idx_users_username_active ON users(lower(username))
+    WHERE role != 'guest' AND deleted_at IS NULL;
+
+CREATE UNIQUE INDEX idx_users_email_lower_active ON users(lower(email)) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_users_email_active;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Renamed usernames and removed emails are not restored.
+CREATE UNIQUE INDEX idx_users_email_active ON users(email) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_users_email_lower_active;
+DROP INDEX IF EXISTS idx_users_username_active;
+-- +goose StatementEnd
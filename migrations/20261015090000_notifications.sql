@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Inbox items of users: mentions, group invites and admin announcements.
+-- message_id has no foreign key as messages are partitioned.
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('mention', 'chat_invite', 'announcement')),
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    chat_id INTEGER REFERENCES chats(id) ON DELETE CASCADE,
+    message_id BIGINT,
+    text TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notifications_user ON notifications(workspace_id, user_id, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications(workspace_id, user_id) WHERE read_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+-- +goose StatementEnd
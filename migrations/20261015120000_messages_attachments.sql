@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Files sent with a message, stored in the file store under attachments/.
+ALTER TABLE messages ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE messages DROP COLUMN IF EXISTS attachments;
+-- +goose StatementEnd
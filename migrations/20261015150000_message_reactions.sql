@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emoji reactions to messages, one row per user and emoji.
+-- message_id has no foreign key as messages are partitioned.
+CREATE TABLE message_reactions (
+    message_id BIGINT NOT NULL,
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE INDEX idx_message_reactions_chat_id ON message_reactions(chat_id);
+CREATE INDEX idx_message_reactions_user_id ON message_reactions(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS message_reactions;
+-- +goose StatementEnd
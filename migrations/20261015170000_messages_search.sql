@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Full-text search over message content. The simple configuration only
+-- lowercases words, as messages are written in several languages and
+-- stemming for one would mangle the others.
+ALTER TABLE messages
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+CREATE INDEX idx_messages_search_vector ON messages USING gin (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_search_vector;
+ALTER TABLE messages DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Participants mentioned by a message, counted as unread mentions until the
+-- participant reads past the message.
+-- message_id has no foreign key as messages are partitioned.
+CREATE TABLE message_mentions (
+    message_id BIGINT NOT NULL,
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX idx_message_mentions_user_id_chat_id ON message_mentions(user_id, chat_id, message_id);
+CREATE INDEX idx_message_mentions_chat_id ON message_mentions(chat_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS message_mentions;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Participants don't get notified of a muted chat, nor is it counted in their
+-- unread totals, until muted_until passes.
+ALTER TABLE chat_participants ADD COLUMN muted_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat_participants DROP COLUMN IF EXISTS muted_until;
+-- +goose StatementEnd
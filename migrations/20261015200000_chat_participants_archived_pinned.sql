@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-participant chat list state: archived chats are listed apart from the
+-- others, pinned chats are listed first.
+ALTER TABLE chat_participants
+    ADD COLUMN archived BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat_participants
+    DROP COLUMN IF EXISTS pinned,
+    DROP COLUMN IF EXISTS archived;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Channels are broadcast-only chats: the creator and workspace admins post,
+-- other participants subscribe to read.
+ALTER TABLE chats DROP CONSTRAINT chats_type_check;
+ALTER TABLE chats ADD CONSTRAINT chats_type_check CHECK (type IN ('direct', 'group', 'saved', 'channel'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM chats WHERE type = 'channel';
+ALTER TABLE chats DROP CONSTRAINT chats_type_check;
+ALTER TABLE chats ADD CONSTRAINT chats_type_check CHECK (type IN ('direct', 'group', 'saved'));
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Invite links let workspace members join a group by its code until they
+-- expire. They go away with the group or their creator.
+CREATE TABLE chat_invite_links (
+    id SERIAL PRIMARY KEY,
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    creator_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_chat_invite_links_code ON chat_invite_links(code);
+CREATE INDEX idx_chat_invite_links_chat_id ON chat_invite_links(chat_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_invite_links;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pending requests to join a group. A row is removed once the request is
+-- approved or rejected, so a user has at most one per group.
+CREATE TABLE chat_join_requests (
+    id SERIAL PRIMARY KEY,
+    chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_chat_join_requests_chat_user ON chat_join_requests(chat_id, user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_join_requests;
+-- +goose StatementEnd
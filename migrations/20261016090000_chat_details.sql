@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- image_path is a file store path, like users.image_path
+ALTER TABLE chats ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE chats ADD COLUMN image_path VARCHAR(500);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS image_path;
+ALTER TABLE chats DROP COLUMN IF EXISTS description;
+-- +goose StatementEnd
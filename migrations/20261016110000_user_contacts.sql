@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per pair of users, either a pending request or an accepted contact
+CREATE TABLE user_contacts (
+    requester_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    addressee_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMPTZ,
+    PRIMARY KEY (requester_id, addressee_id)
+);
+
+-- A request or contact exists once, whichever user sent it
+CREATE UNIQUE INDEX idx_user_contacts_pair
+    ON user_contacts (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+
+CREATE INDEX idx_user_contacts_addressee_id ON user_contacts(addressee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_contacts;
+-- +goose StatementEnd
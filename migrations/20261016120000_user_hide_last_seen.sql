@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Hides when the user was last online from other users.
+ALTER TABLE users ADD COLUMN hide_last_seen BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS hide_last_seen;
+-- +goose StatementEnd
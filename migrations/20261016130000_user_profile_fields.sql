@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Optional profile details the user edits themselves.
+ALTER TABLE users
+    ADD COLUMN display_name VARCHAR(64),
+    ADD COLUMN bio VARCHAR(500),
+    ADD COLUMN phone VARCHAR(16);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS display_name;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- When the user last changed their username, to enforce the change cooldown.
+ALTER TABLE users ADD COLUMN username_changed_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS username_changed_at;
+-- +goose StatementEnd
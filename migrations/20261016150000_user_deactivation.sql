@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Users who deactivated their account can't log in and are anonymized once
+-- the deletion grace period passes.
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_deactivated_at ON users(deactivated_at) WHERE deactivated_at IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_deactivated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Moderators can delete any message and manage any group.
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'moderator', 'user', 'guest'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE users SET role = 'user' WHERE role = 'moderator';
+
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user', 'guest'));
+-- +goose StatementEnd
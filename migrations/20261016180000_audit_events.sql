@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Security-sensitive actions for admin review. User and chat IDs aren't
+-- foreign keys so events outlive what they mention.
+CREATE TABLE audit_events (
+    id SERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    actor_id INTEGER NOT NULL,
+    target_user_id INTEGER,
+    chat_id INTEGER,
+    workspace_id INTEGER,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at DESC, id DESC);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id, created_at DESC);
+CREATE INDEX idx_audit_events_target_user_id ON audit_events(target_user_id, created_at DESC)
+    WHERE target_user_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Saved Messages chats whose creation failed halfway have no participant,
+-- which leaves them unusable; add their creator.
+WITH joined AS (
+    INSERT INTO chat_participants (chat_id, user_id, joined_at)
+    SELECT c.id, c.creator_id, c.created_at
+    FROM chats c
+    WHERE c.type = 'saved'
+    AND NOT EXISTS (SELECT 1 FROM chat_participants cp WHERE cp.chat_id = c.id)
+    RETURNING chat_id
+)
+UPDATE chats c
+SET participant_count = 1
+FROM joined j
+WHERE c.id = j.chat_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- Nothing to undo: the added participants belong to their chats.
@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"net/smtp"
 	"strings"
+	"time"
 )
 
 // Sender defines the interface for sending emails.
@@ -255,3 +256,482 @@ func BuildWelcomeEmail(to, username, password string) (Email, error) {
 		IsHTML:  true,
 	}, nil
 }
+
+// InviteEmailData represents data for invite email template.
+type InviteEmailData struct {
+	InviterName string
+	ChatName    string
+	RegisterURL string
+	ExpiresAt   string
+}
+
+// InviteEmailTemplate is the HTML template for chat invite emails.
+const InviteEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            line-height: 1.6;
+            color: #333;
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+        .header {
+            background-color: #4CAF50;
+            color: white;
+            padding: 20px;
+            text-align: center;
+            border-radius: 5px 5px 0 0;
+        }
+        .content {
+            background-color: #f9f9f9;
+            padding: 30px;
+            border-radius: 0 0 5px 5px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 24px;
+            background-color: #4CAF50;
+            color: white;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            text-align: center;
+            margin-top: 30px;
+            color: #666;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>You're invited to ChatX!</h1>
+    </div>
+    <div class="content">
+        <p>Hello,</p>
+
+        <p><strong>{{.InviterName}}</strong> invited you to join <strong>{{.ChatName}}</strong> on ChatX.</p>
+
+        <p>Create your account with the link below and you will be added to the chat automatically.</p>
+
+        <a href="{{.RegisterURL}}" class="button">Join ChatX</a>
+
+        <p>The link is valid until {{.ExpiresAt}}. If you were not expecting this invite, you can ignore this email.</p>
+
+        <p>Best regards,<br>The ChatX Team</p>
+    </div>
+    <div class="footer">
+        <p>This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>`
+
+// BuildInviteEmail builds a chat invite email from template.
+func BuildInviteEmail(to, inviterName, chatName, registerURL string, expiresAt time.Time) (Email, error) {
+	tmpl, err := template.New("invite").Parse(InviteEmailTemplate)
+	if err != nil {
+		return Email{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := InviteEmailData{
+		InviterName: inviterName,
+		ChatName:    chatName,
+		RegisterURL: registerURL,
+		ExpiresAt:   expiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return Email{}, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return Email{
+		To:      []string{to},
+		Subject: headerSafe(fmt.Sprintf("%s invited you to %s on ChatX", inviterName, chatName)),
+		Body:    body.String(),
+		IsHTML:  true,
+	}, nil
+}
+
+// VerificationEmailData represents data for email verification template.
+type VerificationEmailData struct {
+	Username  string
+	VerifyURL string
+	ExpiresAt string
+}
+
+// VerificationEmailTemplate is the HTML template for email verification emails.
+const VerificationEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            line-height: 1.6;
+            color: #333;
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+        .header {
+            background-color: #4CAF50;
+            color: white;
+            padding: 20px;
+            text-align: center;
+            border-radius: 5px 5px 0 0;
+        }
+        .content {
+            background-color: #f9f9f9;
+            padding: 30px;
+            border-radius: 0 0 5px 5px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 24px;
+            background-color: #4CAF50;
+            color: white;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            text-align: center;
+            margin-top: 30px;
+            color: #666;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>Confirm your email</h1>
+    </div>
+    <div class="content">
+        <p>Hello <strong>{{.Username}}</strong>,</p>
+
+        <p>Please confirm this email address to finish setting up your ChatX account.</p>
+
+        <a href="{{.VerifyURL}}" class="button">Verify email</a>
+
+        <p>The link is valid until {{.ExpiresAt}}. If you did not create a ChatX account, you can ignore this email.</p>
+
+        <p>Best regards,<br>The ChatX Team</p>
+    </div>
+    <div class="footer">
+        <p>This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>`
+
+// BuildVerificationEmail builds an email verification email from template.
+func BuildVerificationEmail(to, username, verifyURL string, expiresAt time.Time) (Email, error) {
+	tmpl, err := template.New("verification").Parse(VerificationEmailTemplate)
+	if err != nil {
+		return Email{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := VerificationEmailData{
+		Username:  username,
+		VerifyURL: verifyURL,
+		ExpiresAt: expiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return Email{}, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return Email{
+		To:      []string{to},
+		Subject: "Verify your ChatX email address",
+		Body:    body.String(),
+		IsHTML:  true,
+	}, nil
+}
+
+// EmailChangeEmailTemplate is the HTML template for emails confirming a new
+// email address. It takes VerificationEmailData.
+const EmailChangeEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            line-height: 1.6;
+            color: #333;
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+        .header {
+            background-color: #4CAF50;
+            color: white;
+            padding: 20px;
+            text-align: center;
+            border-radius: 5px 5px 0 0;
+        }
+        .content {
+            background-color: #f9f9f9;
+            padding: 30px;
+            border-radius: 0 0 5px 5px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 24px;
+            background-color: #4CAF50;
+            color: white;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            text-align: center;
+            margin-top: 30px;
+            color: #666;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>Confirm your email</h1>
+    </div>
+    <div class="content">
+        <p>Hello <strong>{{.Username}}</strong>,</p>
+
+        <p>You asked to use this email address for your ChatX account. Your email changes once you confirm it.</p>
+
+        <a href="{{.VerifyURL}}" class="button">Confirm email</a>
+
+        <p>The link is valid until {{.ExpiresAt}}. If you did not ask for this change, you can ignore this email and your address stays the same.</p>
+
+        <p>Best regards,<br>The ChatX Team</p>
+    </div>
+    <div class="footer">
+        <p>This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>`
+
+// BuildEmailChangeEmail builds an email asking to confirm a new email address
+// from template.
+func BuildEmailChangeEmail(to, username, confirmURL string, expiresAt time.Time) (Email, error) {
+	tmpl, err := template.New("email_change").Parse(EmailChangeEmailTemplate)
+	if err != nil {
+		return Email{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := VerificationEmailData{
+		Username:  username,
+		VerifyURL: confirmURL,
+		ExpiresAt: expiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return Email{}, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return Email{
+		To:      []string{to},
+		Subject: "Confirm your new ChatX email address",
+		Body:    body.String(),
+		IsHTML:  true,
+	}, nil
+}
+
+// JoinRequestEmailData represents data for join request email template.
+type JoinRequestEmailData struct {
+	RequesterName string
+	ChatName      string
+}
+
+// JoinRequestEmailTemplate is the HTML template for group join request emails.
+const JoinRequestEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            line-height: 1.6;
+            color: #333;
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+        .header {
+            background-color: #4CAF50;
+            color: white;
+            padding: 20px;
+            text-align: center;
+            border-radius: 5px 5px 0 0;
+        }
+        .content {
+            background-color: #f9f9f9;
+            padding: 30px;
+            border-radius: 0 0 5px 5px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 24px;
+            background-color: #4CAF50;
+            color: white;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            text-align: center;
+            margin-top: 30px;
+            color: #666;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>New join request</h1>
+    </div>
+    <div class="content">
+        <p>Hello,</p>
+
+        <p><strong>{{.RequesterName}}</strong> asked to join <strong>{{.ChatName}}</strong> on ChatX.</p>
+
+        <p>Open the group in ChatX to approve or reject the request.</p>
+
+        <p>Best regards,<br>The ChatX Team</p>
+    </div>
+    <div class="footer">
+        <p>This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>`
+
+// BuildJoinRequestEmail builds a group join request email from template.
+func BuildJoinRequestEmail(to, requesterName, chatName string) (Email, error) {
+	tmpl, err := template.New("join_request").Parse(JoinRequestEmailTemplate)
+	if err != nil {
+		return Email{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := JoinRequestEmailData{
+		RequesterName: requesterName,
+		ChatName:      chatName,
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return Email{}, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return Email{
+		To:      []string{to},
+		Subject: headerSafe(fmt.Sprintf("%s asked to join %s on ChatX", requesterName, chatName)),
+		Body:    body.String(),
+		IsHTML:  true,
+	}, nil
+}
+
+// headerSafe strips line breaks so user-provided text cannot inject headers.
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// ContactRequestEmailData represents data for contact request email template.
+type ContactRequestEmailData struct {
+	RequesterName string
+}
+
+// ContactRequestEmailTemplate is the HTML template for contact request emails.
+const ContactRequestEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            line-height: 1.6;
+            color: #333;
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+        .header {
+            background-color: #4CAF50;
+            color: white;
+            padding: 20px;
+            text-align: center;
+            border-radius: 5px 5px 0 0;
+        }
+        .content {
+            background-color: #f9f9f9;
+            padding: 30px;
+            border-radius: 0 0 5px 5px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 24px;
+            background-color: #4CAF50;
+            color: white;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            text-align: center;
+            margin-top: 30px;
+            color: #666;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>New contact request</h1>
+    </div>
+    <div class="content">
+        <p>Hello,</p>
+
+        <p><strong>{{.RequesterName}}</strong> wants to add you as a contact on ChatX.</p>
+
+        <p>Open ChatX to accept or decline the request.</p>
+
+        <p>Best regards,<br>The ChatX Team</p>
+    </div>
+    <div class="footer">
+        <p>This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>`
+
+// BuildContactRequestEmail builds a contact request email from template.
+func BuildContactRequestEmail(to, requesterName string) (Email, error) {
+	tmpl, err := template.New("contact_request").Parse(ContactRequestEmailTemplate)
+	if err != nil {
+		return Email{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := ContactRequestEmailData{
+		RequesterName: requesterName,
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return Email{}, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return Email{
+		To:      []string{to},
+		Subject: headerSafe(fmt.Sprintf("%s wants to add you as a contact on ChatX", requesterName)),
+		Body:    body.String(),
+		IsHTML:  true,
+	}, nil
+}
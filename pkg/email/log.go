@@ -0,0 +1,27 @@
+package email
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// logSender writes emails to the log instead of sending them.
+type logSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender creates a sender that logs every email, for development
+// without an SMTP server. Verification and invite links can be copied from
+// the log.
+func NewLogSender(logger *slog.Logger) Sender {
+	return &logSender{logger: logger}
+}
+
+func (s *logSender) Send(email Email) error {
+	s.logger.Info("email not sent, logged instead",
+		"to", strings.Join(email.To, ", "),
+		"subject", email.Subject,
+		"body", email.Body,
+	)
+	return nil
+}
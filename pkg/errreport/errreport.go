@@ -0,0 +1,69 @@
+package errreport
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event is a single error occurrence with its context.
+type Event struct {
+	Err   error
+	Level string            // "error" if empty
+	Tags  map[string]string // indexed, low-cardinality values
+	Extra map[string]any    // free-form details such as stack traces
+}
+
+// Reporter sends error events to an external error tracker.
+type Reporter interface {
+	// Capture queues an event for delivery. It must not block the caller.
+	Capture(ctx context.Context, event Event)
+
+	// Flush waits up to timeout for queued events to be delivered.
+	Flush(timeout time.Duration)
+}
+
+var (
+	mu              sync.RWMutex
+	defaultReporter Reporter = nopReporter{}
+)
+
+// SetDefault installs the process-wide reporter.
+func SetDefault(r Reporter) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultReporter = r
+}
+
+// Default returns the process-wide reporter.
+func Default() Reporter {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultReporter
+}
+
+// Capture reports err with optional tags through the default reporter.
+func Capture(ctx context.Context, err error, tags map[string]string) {
+	if err == nil {
+		return
+	}
+	Default().Capture(ctx, Event{Err: err, Tags: tags})
+}
+
+// CaptureEvent reports a fully specified event through the default reporter.
+func CaptureEvent(ctx context.Context, event Event) {
+	if event.Err == nil {
+		return
+	}
+	Default().Capture(ctx, event)
+}
+
+// Flush flushes the default reporter.
+func Flush(timeout time.Duration) {
+	Default().Flush(timeout)
+}
+
+type nopReporter struct{}
+
+func (nopReporter) Capture(context.Context, Event) {}
+func (nopReporter) Flush(time.Duration)            {}
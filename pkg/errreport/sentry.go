@@ -0,0 +1,201 @@
+package errreport
+
+import (
+	"bytes"
+	"chatx-01-backend/pkg/reqctx"
+	"chatx-01-backend/pkg/requestid"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sentryQueueSize = 256
+	sentryTimeout   = 5 * time.Second
+)
+
+// SentryConfig holds configuration for the Sentry reporter.
+type SentryConfig struct {
+	DSN         string // https://<public_key>@<host>/<project_id>
+	Environment string
+	Release     string
+	ServerName  string
+}
+
+// sentryReporter delivers events to a Sentry-compatible store endpoint.
+type sentryReporter struct {
+	cfg        SentryConfig
+	storeURL   string
+	authHeader string
+	client     *http.Client
+
+	queue chan sentryEvent
+	wg    sync.WaitGroup
+}
+
+// NewSentry creates a reporter sending events to the project identified by the DSN.
+func NewSentry(cfg SentryConfig) (Reporter, error) {
+	u, err := url.Parse(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sentry dsn: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("sentry dsn is missing the public key")
+	}
+
+	projectID := strings.TrimPrefix(u.Path, "/")
+	if projectID == "" {
+		return nil, fmt.Errorf("sentry dsn is missing the project id")
+	}
+
+	r := &sentryReporter{
+		cfg:      cfg,
+		storeURL: fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, projectID),
+		authHeader: fmt.Sprintf(
+			"Sentry sentry_version=7, sentry_client=chatx/1.0, sentry_key=%s",
+			u.User.Username(),
+		),
+		client: &http.Client{Timeout: sentryTimeout},
+		queue:  make(chan sentryEvent, sentryQueueSize),
+	}
+
+	go r.run()
+
+	return r, nil
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Message     string            `json:"message"`
+	Exception   []sentryException `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	User        *sentryUser       `json:"user,omitempty"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+// Capture converts the event and queues it; events are dropped when the queue is full.
+func (r *sentryReporter) Capture(ctx context.Context, event Event) {
+	level := event.Level
+	if level == "" {
+		level = "error"
+	}
+
+	tags := make(map[string]string, len(event.Tags)+1)
+	for k, v := range event.Tags {
+		tags[k] = v
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		tags["request_id"] = id
+	}
+
+	se := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       level,
+		Platform:    "go",
+		Logger:      "chatx",
+		Release:     r.cfg.Release,
+		Environment: r.cfg.Environment,
+		ServerName:  r.cfg.ServerName,
+		Message:     event.Err.Error(),
+		Exception: []sentryException{{
+			Type:  fmt.Sprintf("%T", event.Err),
+			Value: event.Err.Error(),
+		}},
+		Tags:  tags,
+		Extra: event.Extra,
+	}
+	if userID := reqctx.UserID(ctx); userID != 0 {
+		se.User = &sentryUser{ID: strconv.Itoa(userID)}
+	}
+
+	r.wg.Add(1)
+	select {
+	case r.queue <- se:
+	default:
+		r.wg.Done()
+		slog.Warn("error report queue full, dropping event", "message", se.Message)
+	}
+}
+
+// Flush waits until queued events are sent or timeout elapses.
+func (r *sentryReporter) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func (r *sentryReporter) run() {
+	for event := range r.queue {
+		if err := r.send(event); err != nil {
+			slog.Warn("failed to deliver error report", "error", err)
+		}
+		r.wg.Done()
+	}
+}
+
+func (r *sentryReporter) send(event sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.authHeader)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
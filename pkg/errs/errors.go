@@ -1,6 +1,9 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Generic repository errors.
 var (
@@ -42,6 +45,60 @@ func (e ConflictError) Error() string {
 	return e.Message
 }
 
+// UnauthorizedError is returned when credentials are wrong.
+// Code is a stable machine-readable reason clients can branch on.
+type UnauthorizedError struct {
+	Code    string
+	Message string
+}
+
+func NewUnauthorizedError(code, message string) error {
+	return UnauthorizedError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError represents an action the user is not allowed to perform.
+// Code is a stable machine-readable reason clients can branch on.
+type ForbiddenError struct {
+	Code    string
+	Message string
+}
+
+func NewForbiddenError(code, message string) error {
+	return ForbiddenError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+// RateLimitError is returned when a caller sent too many requests.
+// RetryAfter is when they may try again.
+type RateLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func NewRateLimitError(message string, retryAfter time.Duration) error {
+	return RateLimitError{
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e RateLimitError) Error() string {
+	return e.Message
+}
+
 // ReplaceOn replaces target error with replacement if err matches target.
 // This should only be used for user input errors.
 func ReplaceOn(err error, target error, replacement error) error {
@@ -0,0 +1,414 @@
+package errs
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the language error messages are written in.
+const DefaultLocale = "en"
+
+// catalog maps a locale to translations keyed by the English message.
+// Messages without a translation are returned in English, so codes and
+// field names stay the stable part of an error response.
+var catalog = map[string]map[string]string{
+	"ru": {
+		// Generic
+		"validation error":                    "ошибка валидации",
+		"resource not found":                  "ресурс не найден",
+		"resource already exists":             "ресурс уже существует",
+		"request timed out":                   "время ожидания запроса истекло",
+		"request body too large":              "тело запроса слишком большое",
+		"invalid username, email or password": "неверное имя пользователя, email или пароль",
+		"refresh token is invalid or expired": "токен обновления недействителен или истёк",
+
+		// Field validation
+		"must be a valid email address.":             "должен быть действительным адресом электронной почты.",
+		"must be a phone number like +998901234567.": "должен быть номером телефона вида +998901234567.",
+		"must be between 3 and 20 characters long and can only contain letters, numbers, underscores, and hyphens.": "должно содержать от 3 до 20 символов: буквы, цифры, подчёркивания и дефисы.",
+		"must be between 3 and 50 characters long and can only contain lowercase letters, numbers, and hyphens.":    "должно содержать от 3 до 50 символов: строчные буквы, цифры и дефисы.",
+		"language must be an ISO 639-1 code with an optional region, e.g. de or pt-BR":                              "язык должен быть кодом ISO 639-1 с необязательным регионом, например de или pt-BR",
+		"after_id must be less than before_id":              "after_id должен быть меньше before_id",
+		"announcement text is required":                     "требуется текст объявления",
+		"announcement text must be 2000 characters or less": "текст объявления должен быть не длиннее 2000 символов",
+		"at least one participant is required":              "требуется хотя бы один участник",
+		"at least one user id is required":                  "требуется хотя бы один идентификатор пользователя",
+		"cannot check more than 100 users at once":          "нельзя проверить более 100 пользователей за раз",
+		"at least one read is required":                     "требуется хотя бы одна отметка о прочтении",
+		"at least one notification id is required":          "требуется хотя бы один идентификатор уведомления",
+		"cannot mark more than 100 chats at once":           "нельзя отметить более 100 чатов за раз",
+		"cannot mark more than 100 notifications at once":   "нельзя отметить более 100 уведомлений за раз",
+		"cannot add more than 20 reactions to a message":    "нельзя добавить к сообщению более 20 реакций",
+		"email is required without an invite token":         "без токена приглашения требуется email",
+		"file is required":                                  "требуется файл",
+		"refresh token is required":                         "требуется токен обновления",
+		"file type is not allowed":                          "тип файла не разрешён",
+		"attachment path is required":                       "требуется путь к вложению",
+		"attachment was not uploaded to this chat":          "вложение не было загружено в этот чат",
+		"file must be a JPEG or PNG image":                  "файл должен быть изображением JPEG или PNG",
+		"file name is required":                             "требуется имя файла",
+		"group name is required":                            "требуется название группы",
+		"group name must be 100 characters or less":         "название группы должно быть не длиннее 100 символов",
+		"guest link token is required":                      "требуется токен гостевой ссылки",
+		"image path is required":                            "требуется путь к изображению",
+		"description must be 500 characters or less":        "описание должно содержать не более 500 символов",
+		"display name must be 64 characters or less":        "отображаемое имя должно быть не длиннее 64 символов",
+		"bio must be 500 characters or less":                "описание профиля должно содержать не более 500 символов",
+		"invalid chat id":                                   "неверный идентификатор чата",
+		"invalid file size":                                 "неверный размер файла",
+		"invalid flag id":                                   "неверный идентификатор отметки",
+		"invalid message id":                                "неверный идентификатор сообщения",
+		"invalid emoji":                                     "неверный эмодзи",
+		"search query is required":                          "поисковый запрос обязателен",
+		"search query must be 200 characters or less":       "поисковый запрос должен быть не длиннее 200 символов",
+		"muted_until must be in the future":                 "muted_until должно быть в будущем",
+		"expires_at must be in the future":                  "expires_at должно быть в будущем",
+		"invalid notification id":                           "неверный идентификатор уведомления",
+		"invalid other user id":                             "неверный идентификатор собеседника",
+		"invalid user id":                                   "неверный идентификатор пользователя",
+		"invalid webhook id":                                "неверный идентификатор вебхука",
+		"invalid invite id":                                 "неверный идентификатор приглашения",
+		"invalid request id":                                "неверный идентификатор запроса",
+		"code is required":                                  "требуется код",
+		"invalid workspace id":                              "неверный идентификатор рабочего пространства",
+		"limit must be between 1 and 100":                   "limit должен быть от 1 до 100",
+		"message content is required":                       "требуется текст сообщения",
+		"name must be between 1 and 100 characters":         "название должно содержать от 1 до 100 символов",
+		"new password is required":                          "требуется новый пароль",
+		"old password is required":                          "требуется старый пароль",
+		"order must be asc or desc":                         "order должен быть asc или desc",
+		"page must be non-negative":                         "page не может быть отрицательным",
+		"password is required":                              "требуется пароль",
+		"password must be at least 8 characters":            "пароль должен содержать не менее 8 символов",
+		"role must be admin or member":                      "роль должна быть admin или member",
+		"sort must be activity or created":                  "sort должен быть activity или created",
+		"filter must be active or archived":                 "filter должен быть active или archived",
+		"filter must be all or subscribed":                  "filter должен быть all или subscribed",
+		"channel not found":                                 "канал не найден",
+		"the channel creator can't unsubscribe":             "создатель канала не может отписаться",
+		"username or email is required":                     "требуется имя пользователя или email",
+		"status must be open, resolved or all":              "status должен быть open, resolved или all",
+		"verification token is required":                    "требуется токен подтверждения",
+		"email change token is required":                    "требуется токен смены email",
+		"email is the same as the current one":              "email совпадает с текущим",
+		"version is required":                               "требуется версия",
+		"version is not the current terms version":          "версия не совпадает с текущей версией условий",
+		"invalid or expired verification token":             "недействительный или просроченный токен подтверждения",
+		"email does not match the invite":                   "email не совпадает с приглашением",
+		"guest links are only supported for group chats":    "гостевые ссылки доступны только для групповых чатов",
+		"invites are only supported for group chats":        "приглашения доступны только для групповых чатов",
+		"webhooks are only supported for group chats":       "вебхуки доступны только для групповых чатов",
+		"participants can only be changed in group chats":   "участников можно менять только в групповых чатах",
+		"only group chats can be left":                      "покинуть можно только групповой чат",
+		"only group chats can be joined":                    "вступать можно только в групповые чаты",
+		"only group chats can be managed":                   "управлять можно только групповыми чатами",
+		"cannot add more than 50 users at once":             "нельзя добавить более 50 пользователей за раз",
+		"the group creator can't be removed":                "создателя группы нельзя удалить",
+		"you can't block yourself":                          "нельзя заблокировать самого себя",
+		"you can't change your own role":                    "нельзя изменить собственную роль",
+		"guests can't be given a role":                      "гостям нельзя назначить роль",
+		"role must be user, moderator or admin":             "роль должна быть user, moderator или admin",
+		"you can't add yourself as a contact":               "нельзя добавить самого себя в контакты",
+		"metadata key is reserved for the server":           "ключ метаданных зарезервирован сервером",
+		"must be a JSON object":                             "должен быть JSON-объектом",
+		"locale must be en, ru or uz":                       "язык должен быть en, ru или uz",
+
+		// Not found
+		"chat not found":                         "чат не найден",
+		"message not found":                      "сообщение не найдено",
+		"attachment not found":                   "вложение не найдено",
+		"reaction not found":                     "реакция не найдена",
+		"user not found":                         "пользователь не найден",
+		"user is not blocked":                    "пользователь не заблокирован",
+		"contact request not found":              "запрос на добавление в контакты не найден",
+		"contact not found":                      "контакт не найден",
+		"workspace not found":                    "рабочее пространство не найдено",
+		"webhook not found":                      "вебхук не найден",
+		"invite not found":                       "приглашение не найдено",
+		"join request not found":                 "запрос на вступление не найден",
+		"spam flag not found":                    "отметка о спаме не найдена",
+		"file does not exist":                    "файл не существует",
+		"incorrect password":                     "неверный пароль",
+		"one or more participants not found":     "один или несколько участников не найдены",
+		"one or more users not found":            "один или несколько пользователей не найдены",
+		"user is not a participant of this chat": "пользователь не является участником этого чата",
+		"user is not a member of this workspace": "пользователь не состоит в этом рабочем пространстве",
+
+		// Conflict
+		"email already exists":                       "email уже используется",
+		"username already exists":                    "имя пользователя уже занято",
+		"slug already exists":                        "slug уже занят",
+		"user is already a member of this workspace": "пользователь уже состоит в этом рабочем пространстве",
+		"user is already a participant of this chat": "пользователь уже участник этого чата",
+		"a join request is already pending":          "запрос на вступление уже ожидает рассмотрения",
+		"contact request already exists":             "запрос на добавление в контакты уже существует",
+
+		// Forbidden
+		"email address is not verified":                                       "адрес электронной почты не подтверждён",
+		"account is deactivated":                                              "аккаунт деактивирован",
+		"registration requires an invite":                                     "для регистрации требуется приглашение",
+		"registration is disabled":                                            "регистрация отключена",
+		"the current terms of service must be accepted":                       "необходимо принять текущие условия использования",
+		"user is not a member of the workspace":                               "пользователь не состоит в рабочем пространстве",
+		"only workspace admins can manage members":                            "управлять участниками могут только администраторы рабочего пространства",
+		"admin access is not allowed from this address":                       "доступ администратора с этого адреса запрещён",
+		"a valid admin one-time code is required":                             "требуется действительный одноразовый код администратора",
+		"message translation is not enabled":                                  "перевод сообщений не включён",
+		"chat assistant is not enabled":                                       "ассистент чата не включён",
+		"webhook creator is no longer in the chat":                            "создатель вебхука больше не состоит в чате",
+		"only the group creator or a workspace admin can remove participants": "удалять участников могут только создатель группы или администратор рабочего пространства",
+		"only the group creator or a workspace admin can manage invites":      "управлять приглашениями могут только создатель группы или администратор рабочего пространства",
+		"only the group creator or a workspace admin can manage the group":    "управлять группой могут только создатель группы или администратор рабочего пространства",
+		"you can't message this user":                                         "вы не можете писать этому пользователю",
+		"you can't add this user as a contact":                                "вы не можете добавить этого пользователя в контакты",
+		"you can't assign roles":                                              "вы не можете назначать роли",
+		"this action can't be done with an api key":                           "это действие нельзя выполнить с API-ключом",
+		"api key limit reached, revoke a key first":                           "достигнут лимит API-ключей, сначала отзовите ключ",
+		"api key not found":                                                   "API-ключ не найден",
+		"name is required":                                                    "требуется название",
+		"name must be 64 characters or less":                                  "название должно быть не длиннее 64 символов",
+		"at least one scope is required":                                      "требуется хотя бы одна область доступа",
+		"scopes must be chat:read, chat:write, account:read or account:write": "области доступа должны быть chat:read, chat:write, account:read или account:write",
+		"invalid key id":                                                      "неверный идентификатор ключа",
+		"unknown audit action":                                                "неизвестное действие аудита",
+		"to must be after from":                                               "to должно быть позже from",
+		"only the channel creator or a workspace admin can post":              "публиковать в канале могут только создатель канала или администратор рабочего пространства",
+		"disposable email addresses are not allowed":                          "одноразовые адреса электронной почты не допускаются",
+
+		// Rate limited
+		"too many webhook messages, try again later":     "слишком много сообщений через вебхук, попробуйте позже",
+		"username was changed recently, try again later": "имя пользователя недавно менялось, попробуйте позже",
+	},
+	"uz": {
+		// Generic
+		"validation error":                    "tekshiruv xatosi",
+		"resource not found":                  "resurs topilmadi",
+		"resource already exists":             "resurs allaqachon mavjud",
+		"request timed out":                   "so'rov vaqti tugadi",
+		"request body too large":              "so'rov tanasi juda katta",
+		"invalid username, email or password": "foydalanuvchi nomi, email yoki parol noto'g'ri",
+		"refresh token is invalid or expired": "yangilash tokeni yaroqsiz yoki muddati o'tgan",
+
+		// Field validation
+		"must be a valid email address.":             "haqiqiy elektron pochta manzili bo'lishi kerak.",
+		"must be a phone number like +998901234567.": "+998901234567 ko'rinishidagi telefon raqami bo'lishi kerak.",
+		"must be between 3 and 20 characters long and can only contain letters, numbers, underscores, and hyphens.": "3 dan 20 gacha belgidan iborat bo'lishi va faqat harflar, raqamlar, pastki chiziq va defisdan iborat bo'lishi kerak.",
+		"must be between 3 and 50 characters long and can only contain lowercase letters, numbers, and hyphens.":    "3 dan 50 gacha belgidan iborat bo'lishi va faqat kichik harflar, raqamlar va defisdan iborat bo'lishi kerak.",
+		"language must be an ISO 639-1 code with an optional region, e.g. de or pt-BR":                              "til ixtiyoriy mintaqali ISO 639-1 kodi bo'lishi kerak, masalan de yoki pt-BR",
+		"after_id must be less than before_id":              "after_id before_id dan kichik bo'lishi kerak",
+		"announcement text is required":                     "e'lon matni talab qilinadi",
+		"announcement text must be 2000 characters or less": "e'lon matni 2000 belgidan oshmasligi kerak",
+		"at least one participant is required":              "kamida bitta ishtirokchi talab qilinadi",
+		"at least one user id is required":                  "kamida bitta foydalanuvchi identifikatori talab qilinadi",
+		"cannot check more than 100 users at once":          "bir vaqtda 100 tadan ortiq foydalanuvchini tekshirib bo'lmaydi",
+		"at least one read is required":                     "kamida bitta o'qilganlik belgisi talab qilinadi",
+		"at least one notification id is required":          "kamida bitta bildirishnoma identifikatori talab qilinadi",
+		"cannot mark more than 100 chats at once":           "bir vaqtda 100 tadan ortiq chatni belgilab bo'lmaydi",
+		"cannot mark more than 100 notifications at once":   "bir vaqtda 100 tadan ortiq bildirishnomani belgilab bo'lmaydi",
+		"cannot add more than 20 reactions to a message":    "xabarga 20 tadan ortiq reaksiya qo'shib bo'lmaydi",
+		"email is required without an invite token":         "taklif tokenisiz email talab qilinadi",
+		"file is required":                                  "fayl talab qilinadi",
+		"refresh token is required":                         "yangilash tokeni talab qilinadi",
+		"file type is not allowed":                          "fayl turiga ruxsat berilmagan",
+		"attachment path is required":                       "ilova yo'li talab qilinadi",
+		"attachment was not uploaded to this chat":          "ilova bu chatga yuklanmagan",
+		"file must be a JPEG or PNG image":                  "fayl JPEG yoki PNG rasm bo'lishi kerak",
+		"file name is required":                             "fayl nomi talab qilinadi",
+		"group name is required":                            "guruh nomi talab qilinadi",
+		"group name must be 100 characters or less":         "guruh nomi 100 belgidan oshmasligi kerak",
+		"guest link token is required":                      "mehmon havolasi tokeni talab qilinadi",
+		"image path is required":                            "rasm yo'li talab qilinadi",
+		"description must be 500 characters or less":        "tavsif 500 belgidan oshmasligi kerak",
+		"display name must be 64 characters or less":        "ko'rsatiladigan ism 64 belgidan oshmasligi kerak",
+		"bio must be 500 characters or less":                "profil tavsifi 500 belgidan oshmasligi kerak",
+		"invalid chat id":                                   "chat identifikatori noto'g'ri",
+		"invalid file size":                                 "fayl hajmi noto'g'ri",
+		"invalid flag id":                                   "belgi identifikatori noto'g'ri",
+		"invalid message id":                                "xabar identifikatori noto'g'ri",
+		"invalid emoji":                                     "emoji noto'g'ri",
+		"search query is required":                          "qidiruv so'rovi talab qilinadi",
+		"search query must be 200 characters or less":       "qidiruv so'rovi 200 belgidan oshmasligi kerak",
+		"muted_until must be in the future":                 "muted_until kelajakdagi vaqt bo'lishi kerak",
+		"expires_at must be in the future":                  "expires_at kelajakdagi vaqt bo'lishi kerak",
+		"invalid notification id":                           "bildirishnoma identifikatori noto'g'ri",
+		"invalid other user id":                             "suhbatdosh identifikatori noto'g'ri",
+		"invalid user id":                                   "foydalanuvchi identifikatori noto'g'ri",
+		"invalid webhook id":                                "vebhuk identifikatori noto'g'ri",
+		"invalid invite id":                                 "taklif identifikatori noto'g'ri",
+		"invalid request id":                                "so'rov identifikatori noto'g'ri",
+		"code is required":                                  "kod talab qilinadi",
+		"invalid workspace id":                              "ish maydoni identifikatori noto'g'ri",
+		"limit must be between 1 and 100":                   "limit 1 dan 100 gacha bo'lishi kerak",
+		"message content is required":                       "xabar matni talab qilinadi",
+		"name must be between 1 and 100 characters":         "nom 1 dan 100 gacha belgidan iborat bo'lishi kerak",
+		"new password is required":                          "yangi parol talab qilinadi",
+		"old password is required":                          "eski parol talab qilinadi",
+		"order must be asc or desc":                         "order asc yoki desc bo'lishi kerak",
+		"page must be non-negative":                         "page manfiy bo'lmasligi kerak",
+		"password is required":                              "parol talab qilinadi",
+		"password must be at least 8 characters":            "parol kamida 8 belgidan iborat bo'lishi kerak",
+		"role must be admin or member":                      "rol admin yoki member bo'lishi kerak",
+		"sort must be activity or created":                  "sort activity yoki created bo'lishi kerak",
+		"filter must be active or archived":                 "filter active yoki archived bo'lishi kerak",
+		"filter must be all or subscribed":                  "filter all yoki subscribed bo'lishi kerak",
+		"channel not found":                                 "kanal topilmadi",
+		"the channel creator can't unsubscribe":             "kanal yaratuvchisi obunani bekor qila olmaydi",
+		"username or email is required":                     "foydalanuvchi nomi yoki email talab qilinadi",
+		"status must be open, resolved or all":              "status open, resolved yoki all bo'lishi kerak",
+		"verification token is required":                    "tasdiqlash tokeni talab qilinadi",
+		"email change token is required":                    "email o'zgartirish tokeni talab qilinadi",
+		"email is the same as the current one":              "email joriy email bilan bir xil",
+		"version is required":                               "versiya talab qilinadi",
+		"version is not the current terms version":          "versiya joriy shartlar versiyasi emas",
+		"invalid or expired verification token":             "tasdiqlash tokeni yaroqsiz yoki muddati o'tgan",
+		"email does not match the invite":                   "email taklifga mos kelmaydi",
+		"guest links are only supported for group chats":    "mehmon havolalari faqat guruh chatlari uchun mavjud",
+		"invites are only supported for group chats":        "takliflar faqat guruh chatlari uchun mavjud",
+		"webhooks are only supported for group chats":       "vebhuklar faqat guruh chatlari uchun mavjud",
+		"participants can only be changed in group chats":   "ishtirokchilarni faqat guruh chatlarida o'zgartirish mumkin",
+		"only group chats can be left":                      "faqat guruh chatlaridan chiqish mumkin",
+		"only group chats can be joined":                    "faqat guruh chatlariga qo'shilish mumkin",
+		"only group chats can be managed":                   "faqat guruh chatlarini boshqarish mumkin",
+		"cannot add more than 50 users at once":             "bir vaqtda 50 tadan ortiq foydalanuvchi qo'shib bo'lmaydi",
+		"the group creator can't be removed":                "guruh yaratuvchisini olib tashlab bo'lmaydi",
+		"you can't block yourself":                          "o'zingizni bloklab bo'lmaydi",
+		"you can't change your own role":                    "o'z rolingizni o'zgartirib bo'lmaydi",
+		"guests can't be given a role":                      "mehmonlarga rol berib bo'lmaydi",
+		"role must be user, moderator or admin":             "rol user, moderator yoki admin bo'lishi kerak",
+		"you can't add yourself as a contact":               "o'zingizni kontaktlarga qo'shib bo'lmaydi",
+		"metadata key is reserved for the server":           "metadata kaliti server uchun ajratilgan",
+		"must be a JSON object":                             "JSON obyekt bo'lishi kerak",
+		"locale must be en, ru or uz":                       "til en, ru yoki uz bo'lishi kerak",
+
+		// Not found
+		"chat not found":                         "chat topilmadi",
+		"message not found":                      "xabar topilmadi",
+		"attachment not found":                   "ilova topilmadi",
+		"reaction not found":                     "reaksiya topilmadi",
+		"user not found":                         "foydalanuvchi topilmadi",
+		"user is not blocked":                    "foydalanuvchi bloklanmagan",
+		"contact request not found":              "kontakt so'rovi topilmadi",
+		"contact not found":                      "kontakt topilmadi",
+		"workspace not found":                    "ish maydoni topilmadi",
+		"webhook not found":                      "vebhuk topilmadi",
+		"invite not found":                       "taklif topilmadi",
+		"join request not found":                 "qo'shilish so'rovi topilmadi",
+		"spam flag not found":                    "spam belgisi topilmadi",
+		"file does not exist":                    "fayl mavjud emas",
+		"incorrect password":                     "parol noto'g'ri",
+		"one or more participants not found":     "bir yoki bir nechta ishtirokchi topilmadi",
+		"one or more users not found":            "bir yoki bir nechta foydalanuvchi topilmadi",
+		"user is not a participant of this chat": "foydalanuvchi bu chat ishtirokchisi emas",
+		"user is not a member of this workspace": "foydalanuvchi bu ish maydoni a'zosi emas",
+
+		// Conflict
+		"email already exists":                       "bu email allaqachon ishlatilgan",
+		"username already exists":                    "bu foydalanuvchi nomi allaqachon band",
+		"slug already exists":                        "bu slug allaqachon band",
+		"user is already a member of this workspace": "foydalanuvchi allaqachon bu ish maydoni a'zosi",
+		"user is already a participant of this chat": "foydalanuvchi allaqachon bu chat ishtirokchisi",
+		"a join request is already pending":          "qo'shilish so'rovi allaqachon ko'rib chiqilmoqda",
+		"contact request already exists":             "kontakt so'rovi allaqachon mavjud",
+
+		// Forbidden
+		"email address is not verified":                                       "elektron pochta manzili tasdiqlanmagan",
+		"account is deactivated":                                              "akkaunt o'chirilgan",
+		"registration requires an invite":                                     "ro'yxatdan o'tish uchun taklif kerak",
+		"registration is disabled":                                            "ro'yxatdan o'tish o'chirilgan",
+		"the current terms of service must be accepted":                       "joriy foydalanish shartlarini qabul qilish kerak",
+		"user is not a member of the workspace":                               "foydalanuvchi ish maydoni a'zosi emas",
+		"only workspace admins can manage members":                            "a'zolarni faqat ish maydoni administratorlari boshqarishi mumkin",
+		"admin access is not allowed from this address":                       "bu manzildan administrator kirishi taqiqlangan",
+		"a valid admin one-time code is required":                             "administratorning amaldagi bir martalik kodi talab qilinadi",
+		"message translation is not enabled":                                  "xabarlarni tarjima qilish yoqilmagan",
+		"chat assistant is not enabled":                                       "chat yordamchisi yoqilmagan",
+		"webhook creator is no longer in the chat":                            "vebhuk yaratuvchisi endi chatda emas",
+		"only the group creator or a workspace admin can remove participants": "ishtirokchilarni faqat guruh yaratuvchisi yoki ish maydoni administratori olib tashlashi mumkin",
+		"only the group creator or a workspace admin can manage invites":      "takliflarni faqat guruh yaratuvchisi yoki ish maydoni administratori boshqarishi mumkin",
+		"only the group creator or a workspace admin can manage the group":    "guruhni faqat guruh yaratuvchisi yoki ish maydoni administratori boshqarishi mumkin",
+		"you can't message this user":                                         "siz bu foydalanuvchiga yoza olmaysiz",
+		"you can't add this user as a contact":                                "siz bu foydalanuvchini kontaktlarga qo'sha olmaysiz",
+		"you can't assign roles":                                              "siz rollarni tayinlay olmaysiz",
+		"this action can't be done with an api key":                           "bu amalni API kalit bilan bajarib bo'lmaydi",
+		"api key limit reached, revoke a key first":                           "API kalitlar chegarasiga yetildi, avval kalitni bekor qiling",
+		"api key not found":                                                   "API kalit topilmadi",
+		"name is required":                                                    "nom talab qilinadi",
+		"name must be 64 characters or less":                                  "nom 64 belgidan oshmasligi kerak",
+		"at least one scope is required":                                      "kamida bitta ruxsat doirasi talab qilinadi",
+		"scopes must be chat:read, chat:write, account:read or account:write": "ruxsat doiralari chat:read, chat:write, account:read yoki account:write bo'lishi kerak",
+		"invalid key id":                                                      "noto'g'ri kalit identifikatori",
+		"unknown audit action":                                                "noma'lum audit amali",
+		"to must be after from":                                               "to from dan keyin bo'lishi kerak",
+		"only the channel creator or a workspace admin can post":              "kanalga faqat kanal yaratuvchisi yoki ish maydoni administratori post qila oladi",
+		"disposable email addresses are not allowed":                          "bir martalik elektron pochta manzillariga ruxsat berilmaydi",
+
+		// Rate limited
+		"too many webhook messages, try again later":     "vebhuk orqali juda ko'p xabar yuborildi, keyinroq urinib ko'ring",
+		"username was changed recently, try again later": "foydalanuvchi nomi yaqinda o'zgartirilgan, keyinroq urinib ko'ring",
+	},
+}
+
+// IsSupportedLocale reports whether error messages are available in locale.
+func IsSupportedLocale(locale string) bool {
+	if locale == DefaultLocale {
+		return true
+	}
+	_, ok := catalog[locale]
+	return ok
+}
+
+// Localize returns message translated into locale, or message itself if
+// there's no translation.
+func Localize(locale, message string) string {
+	if translated, ok := catalog[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// MatchLocale returns the supported locale the client prefers most according
+// to an Accept-Language header, or "" if it accepts none of them.
+func MatchLocale(acceptLanguage string) string {
+	type tag struct {
+		locale string
+		q      float64
+	}
+
+	var tags []tag
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		// Regional variants share their language's messages
+		base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+		if q > 0 && IsSupportedLocale(base) {
+			tags = append(tags, tag{locale: base, q: q})
+		}
+	}
+
+	if len(tags) == 0 {
+		return ""
+	}
+
+	slices.SortStableFunc(tags, func(a, b tag) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return tags[0].locale
+}
@@ -19,8 +19,12 @@ func (v ValidationError) Error() string {
 func AddFieldError(err error, field string, message string) error {
 	validationError, ok := err.(ValidationError)
 	if !ok {
+		message := "validation error"
+		if err != nil {
+			message = err.Error()
+		}
 		validationError = ValidationError{
-			Message: err.Error(),
+			Message: message,
 			Fields:  make(map[string]string),
 		}
 	}
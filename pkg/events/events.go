@@ -0,0 +1,39 @@
+// Package events defines the broker-neutral contract for producing and
+// consuming events, so the broker can be chosen by configuration.
+// Implementations live in their own packages: pkg/kafka, pkg/natsjs and pkg/rabbitmq.
+package events
+
+import "context"
+
+// Message represents an event with key, value, and headers.
+type Message struct {
+	// Topic is set on consumed messages; producers send to their own topic.
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Producer sends messages to a single topic.
+type Producer interface {
+	// SendMessage sends a message to the producer's topic.
+	SendMessage(ctx context.Context, m *Message) error
+
+	// SendMessages sends multiple messages to the producer's topic.
+	SendMessages(ctx context.Context, messages []Message) error
+
+	// Close releases the producer's connection.
+	Close() error
+}
+
+// HandleFunc is a delivery handler that should be injected into a consumer.
+type HandleFunc func(context.Context, *Message) error
+
+// Consumer delivers the messages of a topic to a HandleFunc.
+type Consumer interface {
+	// Start consumes messages until Stop is called.
+	Start() error
+
+	// Stop stops consuming and releases the consumer's connection.
+	Stop() error
+}
@@ -0,0 +1,138 @@
+package events
+
+import (
+	"chatx-01-backend/pkg/errreport"
+	"chatx-01-backend/pkg/requestid"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// Handler runs the HandleFunc of a broker consumer with the handling every
+// broker shares: the request and trace IDs produced upstream are restored
+// from the headers, each attempt runs under a timeout and is logged, failed
+// attempts are retried and panics fail the attempt. Brokers convert their
+// messages and settle them by the result.
+type Handler struct {
+	HandleFn HandleFunc
+	Broker   string // Names the broker in log messages, e.g. "kafka"
+	Topic    string
+	Timeout  time.Duration
+	// RetryCount is how many times a failed message is retried, RetryDelay
+	// apart. Brokers that redeliver failed messages themselves retry none.
+	RetryCount int
+	RetryDelay time.Duration
+	// OnRetry is called before each retry, if set.
+	OnRetry func()
+	Logger  *slog.Logger
+}
+
+// panicError is the error of an attempt that panicked. The panic is reported
+// when recovered, with its stack.
+type panicError struct {
+	value any
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// Handle handles the message, retrying failed attempts until the retries
+// are exhausted or ctx is done. attrs describe the delivery, such as its
+// partition and offset; they are logged and tag error reports.
+func (h *Handler) Handle(ctx context.Context, msg *Message, attrs ...slog.Attr) error {
+	if id := msg.Headers[requestid.Header]; id != "" {
+		ctx = requestid.WithContext(ctx, id)
+	}
+	if tp := msg.Headers[requestid.TraceParentHeader]; requestid.ValidTraceParent(tp) {
+		ctx = requestid.WithTraceParent(ctx, tp)
+	}
+
+	err := h.attempt(ctx, msg, attrs)
+	for retry := 0; err != nil && retry < h.RetryCount; retry++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(h.RetryDelay):
+		}
+
+		if h.OnRetry != nil {
+			h.OnRetry()
+		}
+		err = h.attempt(ctx, msg, attrs)
+	}
+
+	return err
+}
+
+// attempt runs the handler once and logs the result.
+func (h *Handler) attempt(ctx context.Context, msg *Message, attrs []slog.Attr) error {
+	start := time.Now()
+	err := h.run(ctx, msg)
+	duration := time.Since(start)
+
+	logger := h.Logger.With(
+		"topic", h.Topic,
+		"key", string(msg.Key),
+		"duration", duration.String(),
+		"headers", msg.Headers,
+	)
+	for _, attr := range attrs {
+		logger = logger.With(attr)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if tp := requestid.TraceParentFromContext(ctx); tp != "" {
+		logger = logger.With("traceparent", tp)
+	}
+
+	logMsg := "consumed incoming " + h.Broker + " message"
+	if err == nil {
+		logger.Info(logMsg)
+		return nil
+	}
+
+	logger.Error(logMsg, "error", err)
+	if perr := (*panicError)(nil); !errors.As(err, &perr) {
+		tags := map[string]string{"topic": h.Topic}
+		for _, attr := range attrs {
+			tags[attr.Key] = attr.Value.String()
+		}
+		errreport.Capture(ctx, err, tags)
+	}
+
+	return err
+}
+
+// run calls the handler under the timeout, turning a panic into an error.
+func (h *Handler) run(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := make([]byte, 4096) // 4KB
+			stackTrace = stackTrace[:runtime.Stack(stackTrace, false)]
+
+			h.Logger.ErrorContext(ctx, "panic recovered in "+h.Broker+" handler",
+				"topic", h.Topic,
+				"panic", fmt.Sprintf("%v", r),
+				"stack", string(stackTrace),
+			)
+
+			err = &panicError{value: r}
+			errreport.CaptureEvent(ctx, errreport.Event{
+				Err:   err,
+				Level: "fatal",
+				Tags:  map[string]string{"topic": h.Topic},
+				Extra: map[string]any{"stack": string(stackTrace)},
+			})
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	return h.HandleFn(ctx, msg)
+}
@@ -0,0 +1,239 @@
+package events
+
+import (
+	"chatx-01-backend/pkg/errreport"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/requestid"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Handling settings of in-process consumers. The bus is meant for
+// development, so they aren't configurable.
+const (
+	memoryQueueSize      = 256
+	memoryHandlerTimeout = 30 * time.Second
+	memoryRetryCount     = 3
+	memoryRetryDelay     = 100 * time.Millisecond
+)
+
+// MemoryBus is an in-process broker for development. Messages are passed
+// over channels to consumers in the same process: each consumer group gets
+// a copy of every message sent after it subscribed, and queued messages are
+// lost when the process exits.
+type MemoryBus struct {
+	mu     sync.RWMutex
+	queues map[string]map[string]chan *Message // topic -> group ID -> queue
+}
+
+// NewMemoryBus creates a new in-process broker.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{queues: make(map[string]map[string]chan *Message)}
+}
+
+// NewProducer returns a producer sending to the topic's consumer groups.
+func (b *MemoryBus) NewProducer(topic string) *MemoryProducer {
+	return &MemoryProducer{bus: b, topic: topic}
+}
+
+// NewConsumer subscribes the consumer group to the topic. Consumers sharing a
+// group ID split the group's messages between them.
+func (b *MemoryBus) NewConsumer(topic, groupID string, handleFn HandleFunc, logger *slog.Logger) *MemoryConsumer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	groups, ok := b.queues[topic]
+	if !ok {
+		groups = make(map[string]chan *Message)
+		b.queues[topic] = groups
+	}
+	queue, ok := groups[groupID]
+	if !ok {
+		queue = make(chan *Message, memoryQueueSize)
+		groups[groupID] = queue
+	}
+
+	return &MemoryConsumer{
+		topic:    topic,
+		queue:    queue,
+		handleFn: handleFn,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// MemoryProducer sends messages to a topic of a MemoryBus. It implements Producer.
+type MemoryProducer struct {
+	bus   *MemoryBus
+	topic string
+}
+
+// SendMessage queues the message for every consumer group, waiting while a
+// group's queue is full.
+func (p *MemoryProducer) SendMessage(ctx context.Context, m *Message) error {
+	const op = "events.MemoryProducer.SendMessage"
+
+	msg := &Message{
+		Topic:   p.topic,
+		Key:     m.Key,
+		Value:   m.Value,
+		Headers: maps.Clone(m.Headers),
+	}
+
+	// Propagate request and trace IDs unless the caller set them explicitly
+	msg.Headers = addContextHeader(msg.Headers, requestid.Header, requestid.FromContext(ctx))
+	msg.Headers = addContextHeader(msg.Headers, requestid.TraceParentHeader, requestid.TraceParentFromContext(ctx))
+
+	p.bus.mu.RLock()
+	defer p.bus.mu.RUnlock()
+
+	for _, queue := range p.bus.queues[p.topic] {
+		select {
+		case queue <- msg:
+		case <-ctx.Done():
+			return errs.Wrap(op, fmt.Errorf("send_error: %w, topic: %s", ctx.Err(), p.topic))
+		}
+	}
+
+	return nil
+}
+
+// SendMessages queues the messages in order.
+func (p *MemoryProducer) SendMessages(ctx context.Context, messages []Message) error {
+	for i := range messages {
+		if err := p.SendMessage(ctx, &messages[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements Producer; there is nothing to release.
+func (p *MemoryProducer) Close() error {
+	return nil
+}
+
+func addContextHeader(headers map[string]string, key, value string) map[string]string {
+	if value == "" {
+		return headers
+	}
+	if _, ok := headers[key]; ok {
+		return headers
+	}
+	if headers == nil {
+		headers = make(map[string]string, 2)
+	}
+
+	headers[key] = value
+	return headers
+}
+
+// MemoryConsumer handles the messages of a MemoryBus consumer group. Failed
+// messages are retried a few times and then dropped. It implements Consumer.
+type MemoryConsumer struct {
+	topic    string
+	queue    chan *Message
+	handleFn HandleFunc
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	started  bool
+	stopping bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Start handles queued messages until Stop is called.
+func (c *MemoryConsumer) Start() error {
+	c.mu.Lock()
+	if c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+	c.started = true
+	c.mu.Unlock()
+
+	defer close(c.done)
+
+	for {
+		select {
+		case <-c.stop:
+			return nil
+		case msg := <-c.queue:
+			c.handle(msg)
+		}
+	}
+}
+
+// Stop waits for the message being handled. Messages still queued are kept
+// for other consumers of the group.
+func (c *MemoryConsumer) Stop() error {
+	c.mu.Lock()
+	if c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+	c.stopping = true
+	started := c.started
+	c.mu.Unlock()
+
+	close(c.stop)
+	if started {
+		<-c.done
+	}
+	return nil
+}
+
+func (c *MemoryConsumer) handle(msg *Message) {
+	ctx := context.Background()
+	if id := msg.Headers[requestid.Header]; id != "" {
+		ctx = requestid.WithContext(ctx, id)
+	}
+	if tp := msg.Headers[requestid.TraceParentHeader]; requestid.ValidTraceParent(tp) {
+		ctx = requestid.WithTraceParent(ctx, tp)
+	}
+
+	start := time.Now()
+	err := c.attempt(ctx, msg)
+retry:
+	for attempt := 0; err != nil && attempt < memoryRetryCount; attempt++ {
+		select {
+		case <-c.stop:
+			break retry
+		case <-time.After(memoryRetryDelay):
+		}
+		err = c.attempt(ctx, msg)
+	}
+
+	logger := c.logger.With(
+		"topic", c.topic,
+		"key", string(msg.Key),
+		"duration", time.Since(start).String(),
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to handle in-process message", "error", err)
+		errreport.Capture(ctx, err, map[string]string{"topic": c.topic})
+		return
+	}
+	logger.InfoContext(ctx, "consumed in-process message")
+}
+
+// attempt runs the handler once under the handler timeout, turning a panic
+// into an error.
+func (c *MemoryConsumer) attempt(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, memoryHandlerTimeout)
+	defer cancel()
+
+	return c.handleFn(ctx, msg)
+}
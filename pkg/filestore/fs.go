@@ -0,0 +1,135 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// fsStore implements Store interface on a local directory, for development
+// without MinIO. Content types are derived from the file extension.
+type fsStore struct {
+	root *os.Root
+}
+
+// NewFSStore creates a file store keeping files under dir, which is created
+// if missing. Paths cannot escape dir.
+func NewFSStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create file store directory: %w", err)
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file store directory: %w", err)
+	}
+
+	return &fsStore{root: root}, nil
+}
+
+// Exists checks if a file exists at the given path.
+func (s *fsStore) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := s.root.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return true, nil
+}
+
+// GetContentType retrieves the content type of a file.
+func (s *fsStore) GetContentType(ctx context.Context, path string) (string, error) {
+	exists, err := s.Exists(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("file not found")
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		return "application/octet-stream", nil
+	}
+
+	return contentType, nil
+}
+
+// Stat retrieves the size and content type of a file.
+func (s *fsStore) Stat(ctx context.Context, path string) (FileInfo, error) {
+	info, err := s.root.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return FileInfo{}, fmt.Errorf("file not found")
+		}
+		return FileInfo{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return FileInfo{
+		Size:        info.Size(),
+		ContentType: contentType,
+	}, nil
+}
+
+// Upload writes a file, replacing an existing one. The content is written to
+// a temporary file first, so readers never see a partial file.
+func (s *fsStore) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	if err := s.root.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp := path + ".upload"
+	f, err := s.root.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = io.Copy(f, reader)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = s.root.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := s.root.Rename(tmp, path); err != nil {
+		_ = s.root.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// Download opens a file for reading.
+func (s *fsStore) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	f, err := s.root.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("file not found")
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
+
+// Delete removes a file. Deleting a missing file is not an error, as with MinIO.
+func (s *fsStore) Delete(ctx context.Context, path string) error {
+	err := s.root.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
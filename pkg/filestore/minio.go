@@ -17,6 +17,9 @@ type Store interface {
 	// GetContentType retrieves the MIME type of a file.
 	GetContentType(ctx context.Context, path string) (string, error)
 
+	// Stat retrieves the size and MIME type of a file.
+	Stat(ctx context.Context, path string) (FileInfo, error)
+
 	// Upload uploads a file to the storage.
 	// Returns the path where the file was stored.
 	Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error
@@ -29,6 +32,12 @@ type Store interface {
 	Delete(ctx context.Context, path string) error
 }
 
+// FileInfo describes a stored file.
+type FileInfo struct {
+	Size        int64 // bytes
+	ContentType string
+}
+
 // minioStore implements Store interface using MinIO client SDK.
 type minioStore struct {
 	client *minio.Client
@@ -92,6 +101,28 @@ func (s *minioStore) GetContentType(ctx context.Context, path string) (string, e
 	return contentType, nil
 }
 
+// Stat retrieves the size and content type of a file.
+func (s *minioStore) Stat(ctx context.Context, path string) (FileInfo, error) {
+	objInfo, err := s.client.StatObject(ctx, s.bucket, path, minio.StatObjectOptions{})
+	if err != nil {
+		errResponse := minio.ToErrorResponse(err)
+		if errResponse.Code == "NoSuchKey" {
+			return FileInfo{}, fmt.Errorf("file not found")
+		}
+		return FileInfo{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	contentType := objInfo.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return FileInfo{
+		Size:        objInfo.Size,
+		ContentType: contentType,
+	}, nil
+}
+
 // Upload uploads a file to MinIO storage.
 func (s *minioStore) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
 	opts := minio.PutObjectOptions{
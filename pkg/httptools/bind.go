@@ -4,11 +4,23 @@ import (
 	"chatx-01-backend/pkg/errs"
 	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
+var timeType = reflect.TypeOf(time.Time{})
+
+// BindRequest decodes the request into R and validates it.
+//
+// Fields are bound from the `path`, `query` and `form` struct tags, the latter
+// from application/x-www-form-urlencoded bodies. A JSON body is decoded over
+// the struct. Fields that get no value are set from their `default` tag.
+// Slice fields take repeated or comma-separated values, as in
+// ?user_ids=1,2&user_ids=3, and time.Time fields take RFC3339 values.
 func BindRequest[R interface{ Validate() error }](r *http.Request) (R, error) {
 	const op = "BindRequest"
 	var req R
@@ -17,7 +29,20 @@ func BindRequest[R interface{ Validate() error }](r *http.Request) (R, error) {
 	reqVal := reflect.ValueOf(&req).Elem()
 	reqType := reqVal.Type()
 
-	// Bind path and query parameters
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	// Parse form body if content type is application/x-www-form-urlencoded
+	var form map[string][]string
+	if mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			return req, errs.Wrap(op, err)
+		}
+		form = r.PostForm
+	}
+
+	query := r.URL.Query()
+
+	// Bind path, query, form and default values
 	for i := 0; i < reqType.NumField(); i++ {
 		field := reqType.Field(i)
 		fieldVal := reqVal.Field(i)
@@ -26,30 +51,43 @@ func BindRequest[R interface{ Validate() error }](r *http.Request) (R, error) {
 			continue
 		}
 
+		name, values := "", []string(nil)
+
 		// Bind path parameter
 		if pathTag := field.Tag.Get("path"); pathTag != "" {
-			pathValue := r.PathValue(pathTag)
-			if pathValue != "" {
-				if err := setFieldValue(fieldVal, pathValue); err != nil {
-					return req, errs.Wrap(op, errs.AddFieldError(nil, pathTag, err.Error()))
-				}
+			if pathValue := r.PathValue(pathTag); pathValue != "" {
+				name, values = pathTag, []string{pathValue}
 			}
 		}
 
 		// Bind query parameter
-		if queryTag := field.Tag.Get("query"); queryTag != "" {
-			queryValue := r.URL.Query().Get(queryTag)
-			if queryValue != "" {
-				if err := setFieldValue(fieldVal, queryValue); err != nil {
-					return req, errs.Wrap(op, errs.AddFieldError(nil, queryTag, err.Error()))
-				}
+		if queryTag := field.Tag.Get("query"); queryTag != "" && len(values) == 0 {
+			name, values = queryTag, nonEmpty(query[queryTag])
+		}
+
+		// Bind form field
+		if formTag := field.Tag.Get("form"); formTag != "" && len(values) == 0 {
+			name, values = formTag, nonEmpty(form[formTag])
+		}
+
+		// Fall back to the default value
+		if defaultTag, ok := field.Tag.Lookup("default"); ok && len(values) == 0 {
+			if name == "" {
+				name = field.Name
 			}
+			values = []string{defaultTag}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+		if err := setField(fieldVal, values); err != nil {
+			return req, errs.Wrap(op, errs.AddFieldError(nil, name, err.Error()))
 		}
 	}
 
 	// Bind JSON body if content type is application/json
-	contentType := r.Header.Get("Content-Type")
-	if contentType == "application/json" {
+	if mediaType == "application/json" {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			return req, errs.Wrap(op, err)
 		}
@@ -63,9 +101,67 @@ func BindRequest[R interface{ Validate() error }](r *http.Request) (R, error) {
 	return req, nil
 }
 
+// nonEmpty returns the values that are not empty strings.
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// setField sets a field from the bound values. Slices take every value split
+// on commas, other types take the first value.
+func setField(field reflect.Value, values []string) error {
+	const op = "setField"
+
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if field.Kind() != reflect.Slice {
+		return setFieldValue(field, values[0])
+	}
+
+	var parts []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := setFieldValue(slice.Index(i), part); err != nil {
+			return errs.Wrap(op, err)
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
 // setFieldValue sets a field value from a string based on its type.
 func setFieldValue(field reflect.Value, value string) error {
 	const op = "setFieldValue"
+
+	if field.Type() == timeType {
+		timeVal, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return errs.Wrap(op, errors.New("must be an RFC3339 timestamp"))
+		}
+		field.Set(reflect.ValueOf(timeVal))
+		return nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
@@ -0,0 +1,163 @@
+package httptools
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"chatx-01-backend/pkg/errs"
+)
+
+type bindReq struct {
+	ID      int        `path:"id" query:"id" form:"id"`
+	Name    string     `query:"name" form:"name" json:"name"`
+	Limit   int        `query:"limit" default:"20"`
+	UserIDs []int      `query:"user_ids"`
+	Before  *int       `query:"before"`
+	Since   time.Time  `query:"since"`
+	Until   *time.Time `query:"until"`
+	Note    string     `form:"note"`
+}
+
+func (bindReq) Validate() error { return nil }
+
+func intPtr(v int) *int { return &v }
+
+func TestBindRequest(t *testing.T) {
+	since := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		target      string
+		pathID      string
+		contentType string
+		body        string
+		want        bindReq
+	}{
+		{
+			name:   "path wins over query",
+			target: "/items?id=2",
+			pathID: "1",
+			want:   bindReq{ID: 1, Limit: 20},
+		},
+		{
+			name:        "query wins over form",
+			target:      "/items?id=2",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "id=3&note=hi",
+			want:        bindReq{ID: 2, Limit: 20, Note: "hi"},
+		},
+		{
+			name:        "form fills what path and query leave out",
+			target:      "/items",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "id=3&name=form",
+			want:        bindReq{ID: 3, Name: "form", Limit: 20},
+		},
+		{
+			name:        "form with charset parameter",
+			target:      "/items",
+			contentType: "application/x-www-form-urlencoded; charset=UTF-8",
+			body:        "note=hi",
+			want:        bindReq{Limit: 20, Note: "hi"},
+		},
+		{
+			name:        "form ignored for other content types",
+			target:      "/items",
+			contentType: "text/plain",
+			body:        "note=hi",
+			want:        bindReq{Limit: 20},
+		},
+		{
+			name:   "default used when value missing",
+			target: "/items",
+			want:   bindReq{Limit: 20},
+		},
+		{
+			name:   "default used when value empty",
+			target: "/items?limit=",
+			want:   bindReq{Limit: 20},
+		},
+		{
+			name:   "default overridden",
+			target: "/items?limit=5",
+			want:   bindReq{Limit: 5},
+		},
+		{
+			name:   "repeated and comma separated slice values",
+			target: "/items?user_ids=1,2&user_ids=3&user_ids=&user_ids=4,,5",
+			want:   bindReq{Limit: 20, UserIDs: []int{1, 2, 3, 4, 5}},
+		},
+		{
+			name:   "pointer fields",
+			target: "/items?before=7&until=2026-10-01T12:30:00Z",
+			want:   bindReq{Limit: 20, Before: intPtr(7), Until: &since},
+		},
+		{
+			name:   "RFC3339 time",
+			target: "/items?since=2026-10-01T12:30:00Z",
+			want:   bindReq{Limit: 20, Since: since},
+		},
+		{
+			name:        "JSON body overrides bound fields",
+			target:      "/items?name=query&limit=5",
+			contentType: "application/json",
+			body:        `{"name":"json","Limit":7}`,
+			want:        bindReq{Name: "json", Limit: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.pathID != "" {
+				r.SetPathValue("id", tt.pathID)
+			}
+
+			got, err := BindRequest[bindReq](r)
+			if err != nil {
+				t.Fatalf("BindRequest() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BindRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindRequestInvalidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		field  string
+	}{
+		{name: "invalid time", target: "/items?since=yesterday", field: "since"},
+		{name: "time without zone", target: "/items?since=2026-10-01T12:30:00", field: "since"},
+		{name: "invalid pointer time", target: "/items?until=2026-10-01", field: "until"},
+		{name: "invalid int", target: "/items?limit=ten", field: "limit"},
+		{name: "invalid slice element", target: "/items?user_ids=1,x", field: "user_ids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			_, err := BindRequest[bindReq](r)
+
+			var validationErr errs.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("BindRequest() error = %v, want a validation error", err)
+			}
+			if _, ok := validationErr.Fields[tt.field]; !ok {
+				t.Errorf("BindRequest() field errors = %v, want one for %q", validationErr.Fields, tt.field)
+			}
+		})
+	}
+}
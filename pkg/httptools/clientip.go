@@ -0,0 +1,15 @@
+package httptools
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the IP address of the peer that sent the request.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
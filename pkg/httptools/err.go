@@ -1,8 +1,167 @@
 package httptools
 
-import "net/http"
+import (
+	"chatx-01-backend/pkg/errreport"
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/reqctx"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+)
 
-func HandleError(w http.ResponseWriter, err error) {
-	// TODO: handle based on error type
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+// ErrorResponse is the JSON body returned for failed requests.
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Code   string            `json:"code,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Stable codes of error responses without a more specific code.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeTimeout          = "timeout"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+)
+
+// UserLocaleFunc returns the preferred locale of a user, or "" if they have none.
+type UserLocaleFunc func(ctx context.Context, userID int) string
+
+var userLocale UserLocaleFunc
+
+// SetUserLocale sets the lookup used to localize errors for authenticated
+// users whose request has no supported Accept-Language. Call it at startup.
+func SetUserLocale(fn UserLocaleFunc) {
+	userLocale = fn
+}
+
+func HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	code, resp := errorToResponse(err)
+
+	locale := requestLocale(r)
+	resp = localize(resp, locale)
+	w.Header().Set("Content-Language", locale)
+
+	var rateLimitErr errs.RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+	}
+
+	if code >= http.StatusInternalServerError {
+		errreport.Capture(r.Context(), err, map[string]string{
+			"method": r.Method,
+			"path":   reqctx.LogPath(r.Context(), r.URL.Path),
+			"status": http.StatusText(code),
+		})
+	}
+
+	WriteResponse(code, w, resp)
+}
+
+// WriteError writes a JSON error body with the given status and error code.
+func WriteError(w http.ResponseWriter, status int, code, message string) {
+	WriteResponse(status, w, ErrorResponse{Error: message, Code: code})
+}
+
+// ErrorBody returns the response body HandleError writes for err, for errors
+// sent over other transports like WebSocket. Messages aren't localized.
+func ErrorBody(err error) ErrorResponse {
+	_, resp := errorToResponse(err)
+	return resp
+}
+
+// errorToResponse maps known error types to an HTTP status and response body.
+func errorToResponse(err error) (int, ErrorResponse) {
+	var (
+		validationErr errs.ValidationError
+		notFoundErr   errs.NotFoundError
+		conflictErr   errs.ConflictError
+		unauthErr     errs.UnauthorizedError
+		forbiddenErr  errs.ForbiddenError
+		rateLimitErr  errs.RateLimitError
+		maxBytesErr   *http.MaxBytesError
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrorResponse{Error: "request timed out", Code: CodeTimeout}
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, ErrorResponse{Error: "request body too large", Code: CodePayloadTooLarge}
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest, ErrorResponse{
+			Error:  validationErr.Message,
+			Code:   CodeValidationFailed,
+			Fields: validationErr.Fields,
+		}
+	case errors.As(err, &notFoundErr):
+		return http.StatusNotFound, ErrorResponse{
+			Error:  notFoundErr.Message,
+			Code:   CodeNotFound,
+			Fields: fieldOf(notFoundErr.Field, notFoundErr.Message),
+		}
+	case errors.As(err, &conflictErr):
+		return http.StatusConflict, ErrorResponse{
+			Error:  conflictErr.Message,
+			Code:   CodeConflict,
+			Fields: fieldOf(conflictErr.Field, conflictErr.Message),
+		}
+	case errors.As(err, &unauthErr):
+		return http.StatusUnauthorized, ErrorResponse{Error: unauthErr.Message, Code: unauthErr.Code}
+	case errors.As(err, &forbiddenErr):
+		return http.StatusForbidden, ErrorResponse{Error: forbiddenErr.Message, Code: forbiddenErr.Code}
+	case errors.As(err, &rateLimitErr):
+		return http.StatusTooManyRequests, ErrorResponse{Error: rateLimitErr.Message, Code: CodeRateLimited}
+	case errors.Is(err, errs.ErrNotFound):
+		return http.StatusNotFound, ErrorResponse{Error: "resource not found", Code: CodeNotFound}
+	case errors.Is(err, errs.ErrAlreadyExists):
+		return http.StatusConflict, ErrorResponse{Error: "resource already exists", Code: CodeConflict}
+	default:
+		return http.StatusInternalServerError, ErrorResponse{Error: err.Error(), Code: CodeInternal}
+	}
+}
+
+func fieldOf(field, message string) map[string]string {
+	if field == "" {
+		return nil
+	}
+	return map[string]string{field: message}
+}
+
+// requestLocale picks the error message locale from the Accept-Language
+// header, falling back to the authenticated user's preference.
+func requestLocale(r *http.Request) string {
+	if locale := errs.MatchLocale(r.Header.Get("Accept-Language")); locale != "" {
+		return locale
+	}
+
+	if userID := reqctx.UserID(r.Context()); userID != 0 && userLocale != nil {
+		if locale := userLocale(r.Context(), userID); errs.IsSupportedLocale(locale) {
+			return locale
+		}
+	}
+
+	return errs.DefaultLocale
+}
+
+// localize translates the messages of resp; codes and field names are kept.
+func localize(resp ErrorResponse, locale string) ErrorResponse {
+	if locale == errs.DefaultLocale {
+		return resp
+	}
+
+	resp.Error = errs.Localize(locale, resp.Error)
+	if resp.Fields != nil {
+		fields := make(map[string]string, len(resp.Fields))
+		for field, message := range resp.Fields {
+			fields[field] = errs.Localize(locale, message)
+		}
+		resp.Fields = fields
+	}
+
+	return resp
 }
@@ -0,0 +1,42 @@
+package httptools
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/val"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// PublicIDResolver maps the public UUID of a resource to its internal ID.
+type PublicIDResolver func(ctx context.Context, publicID string) (int, error)
+
+// ResolvePublicIDs lets the given path parameters hold either an internal ID
+// or a public UUID. UUIDs are replaced with the internal ID before the
+// handler binds the request.
+func ResolvePublicIDs(resolvers map[string]PublicIDResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, resolve := range resolvers {
+				value := r.PathValue(name)
+				if !val.IsUUID(value) {
+					continue
+				}
+
+				id, err := resolve(r.Context(), value)
+				if err != nil {
+					if errors.Is(err, errs.ErrNotFound) {
+						err = errs.NewNotFoundError(name, "resource not found")
+					}
+					HandleError(w, r, err)
+					return
+				}
+
+				r.SetPathValue(name, strconv.Itoa(id))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
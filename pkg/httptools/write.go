@@ -6,8 +6,8 @@ import (
 )
 
 func WriteResponse(code int, w http.ResponseWriter, resp any) {
-	w.WriteHeader(code)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
 
 	if resp == nil {
 		return
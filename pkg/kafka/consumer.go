@@ -2,14 +2,18 @@ package kafka
 
 import (
 	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/events"
 	"context"
 	"errors"
 	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/IBM/sarama"
 )
 
+// Consumer represents a Kafka consumer group member. It implements events.Consumer.
 type Consumer struct {
 	cfg            ConsumerConfig
 	topic          string
@@ -17,19 +21,18 @@ type Consumer struct {
 	serviceVersion string
 	saramaCfg      *sarama.Config
 	consumerGroup  sarama.ConsumerGroup
-	handleFn       HandleFunc
+	handler        *events.Handler
+	logger         *slog.Logger
 }
 
-// HandleFunc is a delivery handler that should be injected into the consumer.
-type HandleFunc func(context.Context, *sarama.ConsumerMessage) error
-
 // NewConsumer creates a new kafka consumer.
 func NewConsumer(
 	cfg ConsumerConfig,
 	topic string,
 	serviceName string,
 	serviceVersion string,
-	handleFn HandleFunc,
+	handleFn events.HandleFunc,
+	logger *slog.Logger,
 ) (*Consumer, error) {
 	const op = "kafka.NewConsumer"
 
@@ -44,6 +47,19 @@ func NewConsumer(
 		return nil, errs.Wrap(op, err)
 	}
 
+	handler := &events.Handler{
+		HandleFn:   handleFn,
+		Broker:     "kafka",
+		Topic:      topic,
+		Timeout:    cfg.HandlerTimeout,
+		RetryDelay: cfg.RetryDelay,
+		OnRetry:    func() { consumerRetriesTotal.Inc(topic) },
+		Logger:     logger,
+	}
+	if !cfg.RetryDisabled {
+		handler.RetryCount = int(cfg.RetryCount)
+	}
+
 	return &Consumer{
 		cfg:            cfg,
 		topic:          topic,
@@ -51,7 +67,8 @@ func NewConsumer(
 		serviceVersion: serviceVersion,
 		saramaCfg:      saramaCfg,
 		consumerGroup:  consumerGroup,
-		handleFn:       handleFn,
+		handler:        handler,
+		logger:         logger,
 	}, nil
 }
 
@@ -69,7 +86,7 @@ func (c *Consumer) Start() error {
 			return errs.Wrap(op, err)
 		}
 
-		slog.Info("rebalancing occurred, waiting for new messages")
+		c.logger.Info("rebalancing occurred, waiting for new messages", "topic", c.topic)
 	}
 }
 
@@ -106,12 +123,16 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 				return nil
 			}
 
-			// Build the handler chain
-			chain := c.buildHandlerChain()
+			// the error is already logged and reported by the handler,
+			// move on to the next message
+			c.handleMessage(message)
 
-			// ignore the error and move on to the next message
-			// as the error is already handled in the handler chain
-			_ = chain(context.Background(), message)
+			// HighWaterMarkOffset is the offset of the next message to be produced
+			consumerLag.Set(
+				float64(max(claim.HighWaterMarkOffset()-message.Offset-1, 0)),
+				message.Topic,
+				strconv.Itoa(int(message.Partition)),
+			)
 
 			// mask this message offset as consumed
 			session.MarkMessage(message, "")
@@ -125,14 +146,27 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 	}
 }
 
-func (c *Consumer) buildHandlerChain() HandleFunc {
-	// start with the core business logic handler
-	handler := c.handleFn
-
-	// build the chain in reverse order (last wrapper first)
-	handler = c.handlerWithLogging(handler)
-	handler = c.handlerWithTimeout(handler)
-	handler = c.handlerWithRecovery(handler)
+// handleMessage passes the message to the injected handler and records
+// its final result.
+func (c *Consumer) handleMessage(msg *sarama.ConsumerMessage) {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
 
-	return handler
+	start := time.Now()
+	err := c.handler.Handle(
+		context.Background(),
+		&events.Message{
+			Topic:   msg.Topic,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: headers,
+		},
+		slog.Int("partition", int(msg.Partition)),
+		slog.Int64("offset", msg.Offset),
+	)
+
+	consumerHandlerDuration.Observe(time.Since(start).Seconds(), msg.Topic)
+	consumerMessagesTotal.Inc(msg.Topic, resultLabel(err))
 }
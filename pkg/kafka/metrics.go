@@ -0,0 +1,46 @@
+package kafka
+
+import "chatx-01-backend/pkg/metrics"
+
+var (
+	consumerLag = metrics.NewGauge(
+		"kafka_consumer_lag",
+		"Messages between the last consumed offset and the partition high watermark.",
+		"topic", "partition",
+	)
+	// There's no dead letter topic, so messages with an error result are
+	// skipped once their retries are exhausted.
+	consumerMessagesTotal = metrics.NewCounter(
+		"kafka_consumer_messages_total",
+		"Number of consumed messages by final handler result (success or error).",
+		"topic", "result",
+	)
+	consumerHandlerDuration = metrics.NewHistogram(
+		"kafka_consumer_handler_duration_seconds",
+		"Time spent handling a consumed message, including retries.",
+		metrics.DefaultBuckets,
+		"topic",
+	)
+	consumerRetriesTotal = metrics.NewCounter(
+		"kafka_consumer_retries_total",
+		"Number of handler retries after a failed attempt.",
+		"topic",
+	)
+	producerMessagesTotal = metrics.NewCounter(
+		"kafka_producer_messages_total",
+		"Number of produced messages by result (success or error).",
+		"topic", "result",
+	)
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+func resultLabel(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultSuccess
+}
@@ -2,21 +2,17 @@ package kafka
 
 import (
 	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/events"
+	"chatx-01-backend/pkg/requestid"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/IBM/sarama"
 )
 
-// Message represents a Producer Kafka message with key, value, and headers.
-type Message struct {
-	Key     []byte
-	Value   []byte
-	Headers map[string]string
-}
-
-// Producer represents a Kafka producer.
+// Producer represents a Kafka producer. It implements events.Producer.
 type Producer struct {
 	cfg          ProducerConfig
 	topic        string
@@ -54,13 +50,14 @@ func NewProducer(
 }
 
 // SendMessage sends a message to the configured Kafka topic.
-func (p *Producer) SendMessage(ctx context.Context, m *Message) error {
+func (p *Producer) SendMessage(ctx context.Context, m *events.Message) error {
 	const op = "kafka.SendMessage"
 
 	kafkaMsg := p.buildKafkaProducerMsg(ctx, m)
 
 	// Produce message
 	partition, offset, err := p.syncProducer.SendMessage(kafkaMsg)
+	producerMessagesTotal.Inc(p.topic, resultLabel(err))
 	if err != nil {
 		return errs.Wrap(
 			op,
@@ -75,7 +72,7 @@ func (p *Producer) SendMessage(ctx context.Context, m *Message) error {
 }
 
 // SendMessages sends multiple messages to the configured Kafka topic.
-func (p *Producer) SendMessages(ctx context.Context, messages []Message) error {
+func (p *Producer) SendMessages(ctx context.Context, messages []events.Message) error {
 	const op = "kafka.SendMessages"
 
 	if len(messages) == 0 {
@@ -89,6 +86,17 @@ func (p *Producer) SendMessages(ctx context.Context, messages []Message) error {
 
 	err := p.syncProducer.SendMessages(kafkaMessages)
 	if err != nil {
+		// Messages that weren't reported as failed were delivered
+		var failed int
+		var producerErrs sarama.ProducerErrors
+		if errors.As(err, &producerErrs) {
+			failed = len(producerErrs)
+		} else {
+			failed = len(kafkaMessages)
+		}
+		producerMessagesTotal.Add(float64(failed), p.topic, resultError)
+		producerMessagesTotal.Add(float64(len(kafkaMessages)-failed), p.topic, resultSuccess)
+
 		return errs.Wrap(
 			op,
 			fmt.Errorf(
@@ -98,10 +106,12 @@ func (p *Producer) SendMessages(ctx context.Context, messages []Message) error {
 		)
 	}
 
+	producerMessagesTotal.Add(float64(len(kafkaMessages)), p.topic, resultSuccess)
+
 	return nil
 }
 
-func (p *Producer) buildKafkaProducerMsg(ctx context.Context, m *Message) *sarama.ProducerMessage {
+func (p *Producer) buildKafkaProducerMsg(ctx context.Context, m *events.Message) *sarama.ProducerMessage {
 	msg := &sarama.ProducerMessage{
 		Topic: p.topic,
 		Key:   sarama.ByteEncoder(m.Key),
@@ -116,9 +126,27 @@ func (p *Producer) buildKafkaProducerMsg(ctx context.Context, m *Message) *saram
 		})
 	}
 
+	// Propagate request and trace IDs unless the caller set them explicitly
+	p.addContextHeader(msg, m, requestid.Header, requestid.FromContext(ctx))
+	p.addContextHeader(msg, m, requestid.TraceParentHeader, requestid.TraceParentFromContext(ctx))
+
 	return msg
 }
 
+func (p *Producer) addContextHeader(msg *sarama.ProducerMessage, m *events.Message, key, value string) {
+	if value == "" {
+		return
+	}
+	if _, ok := m.Headers[key]; ok {
+		return
+	}
+
+	msg.Headers = append(msg.Headers, sarama.RecordHeader{
+		Key:   []byte(key),
+		Value: []byte(value),
+	})
+}
+
 // Close closes the producer.
 func (p *Producer) Close() error {
 	const op = "kafka.Producer.Close"
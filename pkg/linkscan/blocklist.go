@@ -0,0 +1,46 @@
+package linkscan
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// blocklistScanner flags links to a fixed set of domains.
+type blocklistScanner struct {
+	domains map[string]struct{}
+}
+
+// NewBlocklist creates a scanner flagging links to the given domains and
+// their subdomains, for self-hosted setups without a Safe Browsing key and
+// for development.
+func NewBlocklist(domains []string) Scanner {
+	s := &blocklistScanner{domains: make(map[string]struct{}, len(domains))}
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			s.domains[domain] = struct{}{}
+		}
+	}
+	return s
+}
+
+func (s *blocklistScanner) Scan(ctx context.Context, urls []string) (map[string]string, error) {
+	threats := make(map[string]string)
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+
+		// Parent domains too, so sub.example.com matches example.com
+		host := strings.ToLower(u.Hostname())
+		for host != "" {
+			if _, ok := s.domains[host]; ok {
+				threats[raw] = ThreatBlocklisted
+				break
+			}
+			_, host, _ = strings.Cut(host, ".")
+		}
+	}
+	return threats, nil
+}
@@ -0,0 +1,52 @@
+package linkscan
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache keeps scan results in process, for development without Redis.
+// Expired entries are dropped when they are read.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	threat    string
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an empty scan result cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]cacheEntry)}
+}
+
+// GetLinkThreat returns the cached threat type of a URL, empty if it was
+// found safe. ok is false if the URL hasn't been scanned.
+func (c *MemoryCache) GetLinkThreat(ctx context.Context, url string) (threat string, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[url]
+	if !ok {
+		return "", false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, url)
+		return "", false, nil
+	}
+
+	return entry.threat, true, nil
+}
+
+// SetLinkThreat caches the threat type of a URL, empty if it's safe, for ttl.
+func (c *MemoryCache) SetLinkThreat(ctx context.Context, url, threat string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[url] = cacheEntry{threat: threat, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+// Package linkscan checks links in messages against a list of unsafe sites.
+package linkscan
+
+import "context"
+
+// Threat types reported for unsafe links.
+const (
+	ThreatMalware            = "malware"
+	ThreatSocialEngineering  = "social_engineering"
+	ThreatUnwantedSoftware   = "unwanted_software"
+	ThreatHarmfulApplication = "potentially_harmful_application"
+	ThreatBlocklisted        = "blocklisted"
+)
+
+// Scanner looks links up with an external provider.
+type Scanner interface {
+	// Scan returns the threat type of every unsafe URL in urls, keyed by the
+	// URL. Safe URLs are left out.
+	Scan(ctx context.Context, urls []string) (map[string]string, error)
+}
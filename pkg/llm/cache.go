@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache keeps generated summaries and replies in process, for
+// development without Redis. Expired entries are dropped when they are read.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	kind          string
+	chatID        int
+	lastMessageID int
+	userID        int
+}
+
+type cacheEntry struct {
+	result    string
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an empty cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[cacheKey]cacheEntry)}
+}
+
+// GetAssistantResult returns a cached result for a chat up to its last
+// message. ok is false if none is cached.
+func (c *MemoryCache) GetAssistantResult(
+	ctx context.Context,
+	kind string,
+	chatID, lastMessageID, userID int,
+) (result string, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{kind: kind, chatID: chatID, lastMessageID: lastMessageID, userID: userID}
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false, nil
+	}
+
+	return entry.result, true, nil
+}
+
+// SetAssistantResult caches a result for a chat up to its last message for ttl.
+func (c *MemoryCache) SetAssistantResult(
+	ctx context.Context,
+	kind string,
+	chatID, lastMessageID, userID int,
+	result string,
+	ttl time.Duration,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{kind: kind, chatID: chatID, lastMessageID: lastMessageID, userID: userID}
+	c.entries[key] = cacheEntry{
+		result:    result,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package llm
+
+import "context"
+
+// Request is a single-turn completion request.
+type Request struct {
+	// System sets the model's instructions.
+	System string
+	// Prompt is the user turn, e.g. a conversation transcript.
+	Prompt string
+	// MaxTokens bounds the length of the completion; zero uses the
+	// provider default.
+	MaxTokens int
+}
+
+// Provider generates text through an external language model.
+type Provider interface {
+	// Complete returns the model's reply to req.
+	Complete(ctx context.Context, req Request) (string, error)
+}
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	openAIDefaultURL = "https://api.openai.com/v1"
+	openAITimeout    = 30 * time.Second
+)
+
+// OpenAIConfig holds configuration for the OpenAI provider.
+type OpenAIConfig struct {
+	// BaseURL defaults to the OpenAI API. Any server implementing the chat
+	// completions endpoint, e.g. a self-hosted model, can be used instead.
+	BaseURL string
+	APIKey  string
+	Model   string
+}
+
+type openAIProvider struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewOpenAI creates a provider backed by an OpenAI compatible chat
+// completions API.
+func NewOpenAI(cfg OpenAIConfig) (Provider, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("openai model is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("openai api key is required")
+		}
+		baseURL = openAIDefaultURL
+	}
+
+	return &openAIProvider{
+		url:    strings.TrimSuffix(baseURL, "/") + "/chat/completions",
+		apiKey: cfg.APIKey,
+		model:  cfg.Model,
+		client: &http.Client{Timeout: openAITimeout},
+	}, nil
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model     string          `json:"model"`
+	Messages  []openAIMessage `json:"messages"`
+	MaxTokens int             `json:"max_tokens,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req Request) (string, error) {
+	messages := make([]openAIMessage, 0, 2)
+	if req.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, openAIMessage{Role: "user", Content: req.Prompt})
+
+	body, err := json.Marshal(openAIRequest{
+		Model:     p.model,
+		Messages:  messages,
+		MaxTokens: req.MaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode openai request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build openai request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to call openai: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("openai returned status %d", resp.StatusCode)
+	}
+
+	var out openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode openai response: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+
+	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+}
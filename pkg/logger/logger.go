@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config holds logger configuration.
+type Config struct {
+	Level  string // debug, info, warn, error
+	Format string // json or text
+}
+
+// New builds the application logger and installs it as the slog default,
+// so the standard log package and slog.Default share the same output.
+func New(cfg Config) *slog.Logger {
+	return newLogger(os.Stdout, cfg)
+}
+
+func newLogger(w io.Writer, cfg Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(cfg.Level)}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	return logger
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
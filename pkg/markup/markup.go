@@ -0,0 +1,271 @@
+// Package markup parses lightweight markdown-style formatting in chat
+// messages into entities.
+//
+// Offsets and lengths are counted in Unicode code points and refer to the
+// raw text. Style entities (bold, italic, strikethrough, code, pre) and text
+// links span their delimiters as well, so "**hi**" yields a bold entity with
+// offset 0 and length 6.
+package markup
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// EntityType is the kind of formatting an entity applies.
+type EntityType string
+
+const (
+	EntityBold          EntityType = "bold"          // **text**
+	EntityItalic        EntityType = "italic"        // _text_ or *text*
+	EntityStrikethrough EntityType = "strikethrough" // ~~text~~
+	EntityCode          EntityType = "code"          // `text`
+	EntityPre           EntityType = "pre"           // ```text```
+	EntityTextLink      EntityType = "text_link"     // [text](https://example.com)
+	EntityURL           EntityType = "url"           // https://example.com
+	EntityMention       EntityType = "mention"       // @username
+)
+
+// MaxEntities bounds the number of entities parsed from a single text.
+const MaxEntities = 100
+
+// Mentions follow the username rules: 3 to 20 letters, digits, '_' or '-'.
+const (
+	minMentionLen = 3
+	maxMentionLen = 20
+)
+
+// Entity is a formatted range of a text.
+type Entity struct {
+	Type   EntityType
+	Offset int
+	Length int
+	URL    string // Set for text links and URLs
+}
+
+// style is a delimited formatting span.
+type style struct {
+	delim  string
+	typ    EntityType
+	nested bool // Whether formatting inside the span is parsed
+	word   bool // Whether the delimiters must sit on word boundaries
+}
+
+// styles are tried in order, so longer delimiters come first.
+var styles = []style{
+	{delim: "```", typ: EntityPre},
+	{delim: "`", typ: EntityCode},
+	{delim: "**", typ: EntityBold, nested: true},
+	{delim: "~~", typ: EntityStrikethrough, nested: true},
+	{delim: "*", typ: EntityItalic, nested: true},
+	{delim: "_", typ: EntityItalic, nested: true, word: true},
+}
+
+// Parse returns the entities found in text ordered by offset.
+func Parse(text string) []Entity {
+	p := &parser{src: []rune(text)}
+	p.parse(0, len(p.src))
+
+	sort.SliceStable(p.entities, func(i, j int) bool {
+		if p.entities[i].Offset != p.entities[j].Offset {
+			return p.entities[i].Offset < p.entities[j].Offset
+		}
+		// Outer entities first
+		return p.entities[i].Length > p.entities[j].Length
+	})
+
+	return p.entities
+}
+
+type parser struct {
+	src      []rune
+	entities []Entity
+}
+
+func (p *parser) parse(start, end int) {
+	for i := start; i < end && len(p.entities) < MaxEntities; {
+		if n := p.parseAt(i, start, end); n > 0 {
+			i += n
+			continue
+		}
+		i++
+	}
+}
+
+// parseAt tries every entity kind at position i and returns the number of
+// runes consumed, or 0 if nothing matched.
+func (p *parser) parseAt(i, start, end int) int {
+	for _, s := range styles {
+		if n := p.parseStyle(s, i, start, end); n > 0 {
+			return n
+		}
+	}
+	if n := p.parseTextLink(i, end); n > 0 {
+		return n
+	}
+	if n := p.parseURL(i, start, end); n > 0 {
+		return n
+	}
+	return p.parseMention(i, start, end)
+}
+
+func (p *parser) parseStyle(s style, i, start, end int) int {
+	delim := []rune(s.delim)
+	if !p.hasPrefix(i, end, delim) {
+		return 0
+	}
+	if s.word && i > start && isWordRune(p.src[i-1]) {
+		return 0
+	}
+
+	inner := i + len(delim)
+	if inner >= end || unicode.IsSpace(p.src[inner]) {
+		return 0
+	}
+
+	for j := inner + 1; j+len(delim) <= end; j++ {
+		if !p.hasPrefix(j, end, delim) || unicode.IsSpace(p.src[j-1]) {
+			continue
+		}
+		after := j + len(delim)
+		if s.word && after < end && isWordRune(p.src[after]) {
+			continue
+		}
+
+		p.add(Entity{Type: s.typ, Offset: i, Length: after - i})
+		if s.nested {
+			p.parse(inner, j)
+		}
+		return after - i
+	}
+
+	return 0
+}
+
+func (p *parser) parseTextLink(i, end int) int {
+	if p.src[i] != '[' {
+		return 0
+	}
+
+	textEnd := p.index(i+1, end, "](")
+	if textEnd <= i+1 {
+		return 0
+	}
+	urlStart := textEnd + 2
+	urlEnd := p.index(urlStart, end, ")")
+	if urlEnd < 0 {
+		return 0
+	}
+
+	link := string(p.src[urlStart:urlEnd])
+	if !validURL(link) {
+		return 0
+	}
+
+	p.add(Entity{Type: EntityTextLink, Offset: i, Length: urlEnd + 1 - i, URL: link})
+	p.parse(i+1, textEnd)
+	return urlEnd + 1 - i
+}
+
+func (p *parser) parseURL(i, start, end int) int {
+	if i > start && isWordRune(p.src[i-1]) {
+		return 0
+	}
+	if !p.hasPrefixFold(i, end, "https://") && !p.hasPrefixFold(i, end, "http://") {
+		return 0
+	}
+
+	j := i
+	for j < end && !unicode.IsSpace(p.src[j]) {
+		j++
+	}
+	// Trailing punctuation usually belongs to the sentence
+	for j > i && strings.ContainsRune(".,;:!?)]}'\"", p.src[j-1]) {
+		j--
+	}
+
+	link := string(p.src[i:j])
+	if !validURL(link) {
+		return 0
+	}
+
+	p.add(Entity{Type: EntityURL, Offset: i, Length: j - i, URL: link})
+	return j - i
+}
+
+func (p *parser) parseMention(i, start, end int) int {
+	if p.src[i] != '@' {
+		return 0
+	}
+	if i > start && (isWordRune(p.src[i-1]) || p.src[i-1] == '@') {
+		return 0
+	}
+
+	j := i + 1
+	for j < end && isUsernameRune(p.src[j]) {
+		j++
+	}
+
+	n := j - i - 1
+	if n < minMentionLen || n > maxMentionLen {
+		return 0
+	}
+
+	p.add(Entity{Type: EntityMention, Offset: i, Length: j - i})
+	return j - i
+}
+
+func (p *parser) add(e Entity) {
+	if len(p.entities) < MaxEntities {
+		p.entities = append(p.entities, e)
+	}
+}
+
+func (p *parser) hasPrefix(i, end int, prefix []rune) bool {
+	if i+len(prefix) > end {
+		return false
+	}
+	for k, r := range prefix {
+		if p.src[i+k] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) hasPrefixFold(i, end int, prefix string) bool {
+	n := len(prefix) // ASCII only
+	if i+n > end {
+		return false
+	}
+	return strings.EqualFold(string(p.src[i:i+n]), prefix)
+}
+
+// index returns the position of sub in src[from:end], or -1.
+func (p *parser) index(from, end int, sub string) int {
+	subRunes := []rune(sub)
+	for j := from; j+len(subRunes) <= end; j++ {
+		if p.hasPrefix(j, end, subRunes) {
+			return j
+		}
+	}
+	return -1
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func isUsernameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-'
+}
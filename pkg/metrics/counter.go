@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Counter is a monotonically increasing metric, optionally partitioned by labels.
+type Counter struct {
+	desc
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter creates and registers a counter.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{
+		desc:   desc{metricName: name, help: help, labelNames: labelNames},
+		values: make(map[string]float64),
+	}
+	defaultRegistry.register(c)
+	return c
+}
+
+// Inc increments the counter for the given label values by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by v. Negative values are ignored.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[seriesKey(labelValues)] += v
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeHeader(w, "counter")
+	if len(c.values) == 0 && len(c.labelNames) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.metricName)
+		return
+	}
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		labels := c.formatLabels(strings.Split(key, "\xff"))
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, labels, formatValue(c.values[key]))
+	}
+}
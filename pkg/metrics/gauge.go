@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Gauge is a metric that can go up and down, optionally partitioned by labels.
+type Gauge struct {
+	desc
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewGauge creates and registers a gauge.
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	g := &Gauge{
+		desc:   desc{metricName: name, help: help, labelNames: labelNames},
+		values: make(map[string]float64),
+	}
+	defaultRegistry.register(g)
+	return g
+}
+
+// Set sets the gauge for the given label values to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[seriesKey(labelValues)] = v
+}
+
+// Add adds v, which may be negative, to the gauge for the given label values.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[seriesKey(labelValues)] += v
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.writeHeader(w, "gauge")
+	if len(g.values) == 0 && len(g.labelNames) == 0 {
+		fmt.Fprintf(w, "%s 0\n", g.metricName)
+		return
+	}
+
+	keys := make([]string, 0, len(g.values))
+	for key := range g.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		labels := g.formatLabels(strings.Split(key, "\xff"))
+		fmt.Fprintf(w, "%s%s %s\n", g.metricName, labels, formatValue(g.values[key]))
+	}
+}
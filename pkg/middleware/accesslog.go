@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/reqctx"
+	"chatx-01-backend/pkg/requestid"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (rw *responseWriter) Status() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+	rw.wroteHeader = true
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// AccessLog emits one structured entry per request with the matched route
+// pattern, status, response size, latency, client IP and authenticated user.
+// It must run inside RequestScope and outside Recovery so that panics still
+// produce an entry; RoutePattern supplies the pattern from the router.
+func AccessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r)
+
+			ctx := r.Context()
+			route := reqctx.Route(ctx)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			attrs := []any{
+				"request_id", requestid.FromContext(ctx),
+				"method", r.Method,
+				"route", route,
+				"path", reqctx.LogPath(ctx, r.URL.Path),
+				"status", wrapped.Status(),
+				"bytes", wrapped.bytes,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"client_ip", httptools.ClientIP(r),
+			}
+			if userID := reqctx.UserID(ctx); userID != 0 {
+				attrs = append(attrs, "user_id", userID)
+			}
+
+			level := slog.LevelInfo
+			if wrapped.Status() >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			logger.Log(ctx, level, "http request", attrs...)
+		})
+	}
+}
+
+// RoutePattern records the pattern matched by the wrapped http.ServeMux in the
+// request scope. It must wrap the mux directly: ServeMux sets Request.Pattern
+// on the request it receives, which outer middlewares never see.
+func RoutePattern(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			reqctx.SetRoute(r.Context(), r.Pattern)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
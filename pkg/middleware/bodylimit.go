@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+type originalBodyKey struct{}
+
+// BodyLimit caps the request body of every request at limit bytes.
+// Reading past the limit fails with *http.MaxBytesError, which
+// httptools.HandleError turns into a 413 response.
+func BodyLimit(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Keep the unwrapped body so routes can raise the limit later.
+			ctx := context.WithValue(r.Context(), originalBodyKey{}, r.Body)
+			r = r.WithContext(ctx)
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BodyLimitOverride replaces the global body limit for a single route.
+// It must run after BodyLimit in the handler chain.
+func BodyLimitOverride(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, ok := r.Context().Value(originalBodyKey{}).(io.ReadCloser)
+			if !ok {
+				body = r.Body
+			}
+			if body != nil && body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, body, limit)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package middleware
+
+import "net/http"
+
+// Chain wraps handler with middlewares. The first middleware is the outermost.
+func Chain(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
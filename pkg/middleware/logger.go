@@ -1,54 +0,0 @@
-package middleware
-
-import (
-	"log"
-	"net/http"
-	"time"
-)
-
-type responseWriter struct {
-	http.ResponseWriter
-	status      int
-	wroteHeader bool
-}
-
-func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
-	return &responseWriter{ResponseWriter: w}
-}
-
-func (rw *responseWriter) Status() int {
-	return rw.status
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	if rw.wroteHeader {
-		return
-	}
-
-	rw.status = code
-	rw.ResponseWriter.WriteHeader(code)
-	rw.wroteHeader = true
-}
-
-func Logger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		wrapped := wrapResponseWriter(w)
-		next.ServeHTTP(wrapped, r)
-
-		status := wrapped.status
-		if status == 0 {
-			status = 200
-		}
-
-		log.Printf(
-			"%s %s %s %d %s",
-			r.Method,
-			r.RequestURI,
-			r.RemoteAddr,
-			status,
-			time.Since(start),
-		)
-	})
-}
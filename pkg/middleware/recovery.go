@@ -1,23 +1,58 @@
 package middleware
 
 import (
-	"log"
+	"chatx-01-backend/pkg/errreport"
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/metrics"
+	"chatx-01-backend/pkg/reqctx"
+	"chatx-01-backend/pkg/requestid"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"runtime/debug"
 )
 
-func Recovery(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				log.Printf("PANIC: %v\n%s", err, debug.Stack())
+var panicsTotal = metrics.NewCounter(
+	"http_panics_total",
+	"Number of panics recovered in HTTP handlers.",
+)
+
+// Recovery turns handler panics into a 500 response, logging and reporting them.
+func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					// Deliberate abort, let net/http handle it.
+					panic(rec)
+				}
+
+				panicsTotal.Inc()
+				stack := string(debug.Stack())
+
+				errreport.CaptureEvent(r.Context(), errreport.Event{
+					Err:   fmt.Errorf("panic: %v", rec),
+					Level: "fatal",
+					Tags:  map[string]string{"method": r.Method, "path": reqctx.LogPath(r.Context(), r.URL.Path)},
+					Extra: map[string]any{"stack": stack},
+				})
+
+				logger.ErrorContext(r.Context(), "panic recovered in http handler",
+					"request_id", requestid.FromContext(r.Context()),
+					"method", r.Method,
+					"path", reqctx.LogPath(r.Context(), r.URL.Path),
+					"panic", fmt.Sprintf("%v", rec),
+					"stack", stack,
+				)
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(`{"error":"internal server error"}`))
-			}
-		}()
+				httptools.WriteError(w, http.StatusInternalServerError, httptools.CodeInternal, "internal server error")
+			}()
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"chatx-01-backend/pkg/requestid"
+	"net/http"
+)
+
+// maxRequestIDLen bounds client-supplied request IDs.
+const maxRequestIDLen = 128
+
+// RequestID reuses the incoming X-Request-ID header or generates a new ID,
+// stores it in the request context and echoes it in the response. The W3C
+// traceparent header is carried along the same way so it can be forwarded
+// to downstream services such as Kafka consumers.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if id == "" || len(id) > maxRequestIDLen {
+			id = requestid.New()
+		}
+
+		traceParent := r.Header.Get(requestid.TraceParentHeader)
+		if !requestid.ValidTraceParent(traceParent) {
+			traceParent = requestid.NewTraceParent()
+		}
+
+		ctx := requestid.WithContext(r.Context(), id)
+		ctx = requestid.WithTraceParent(ctx, traceParent)
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"chatx-01-backend/pkg/httptools"
+	"chatx-01-backend/pkg/reqctx"
+	"net/http"
+)
+
+// RequestScope attaches a reqctx.Scope so outer middlewares can observe
+// values such as the authenticated user set further down the chain. The
+// client address and user agent are recorded for audit events.
+func RequestScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := reqctx.WithScope(r.Context())
+		reqctx.SetClient(ctx, httptools.ClientIP(r), r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
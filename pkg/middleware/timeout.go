@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"chatx-01-backend/pkg/httptools"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type parentContextKey struct{}
+
+// Timeout bounds every request with a context deadline: readTimeout for
+// GET/HEAD requests and writeTimeout for everything else. Downstream pgx
+// and Redis calls observe the deadline and are cancelled when it passes.
+// If the handler returns without writing a response after the deadline,
+// a 504 is written.
+func Timeout(readTimeout, writeTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := writeTimeout
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				timeout = readTimeout
+			}
+
+			// Keep the parent context so routes can pick their own deadline.
+			parent := context.WithValue(r.Context(), parentContextKey{}, r.Context())
+			serveWithTimeout(w, r.WithContext(parent), next, timeout)
+		})
+	}
+}
+
+// TimeoutOverride replaces the global timeout for a single route, e.g. to
+// give uploads or exports more time. It also extends the connection
+// read/write deadlines so the server timeouts don't cut the request short.
+func TimeoutOverride(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if parent, ok := r.Context().Value(parentContextKey{}).(context.Context); ok {
+				r = r.WithContext(context.WithValue(parent, parentContextKey{}, parent))
+			}
+
+			deadline := time.Now().Add(timeout)
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+
+			serveWithTimeout(w, r, next, timeout)
+		})
+	}
+}
+
+func serveWithTimeout(w http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	tw := &timeoutWriter{ResponseWriter: w}
+	next.ServeHTTP(tw, r.WithContext(ctx))
+
+	if !tw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		httptools.WriteError(w, http.StatusGatewayTimeout, httptools.CodeTimeout, "request timed out")
+	}
+}
+
+// timeoutWriter records whether the handler has started a response.
+type timeoutWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.wroteHeader = true
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.wroteHeader = true
+	return tw.ResponseWriter.Write(b)
+}
+
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
@@ -0,0 +1,118 @@
+package natsjs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	newestOffset = "newest"
+	oldestOffset = "oldest"
+
+	// setupTimeout bounds connecting and creating streams and consumers.
+	setupTimeout = 10 * time.Second
+	// ackWaitMargin is added to the handler timeout for the ack wait, so the
+	// server does not redeliver a message that is still being handled.
+	ackWaitMargin = 10 * time.Second
+)
+
+// Config holds connection settings for a NATS server.
+type Config struct {
+	URL      string `yaml:"url"` // Comma-separated server URLs
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// StreamMaxAge limits how long messages are kept in the streams created
+	// for topics. Zero keeps them until other stream limits are hit.
+	StreamMaxAge time.Duration `yaml:"stream_max_age"`
+}
+
+// ConsumerConfig holds configuration for a NATS JetStream consumer.
+type ConsumerConfig struct {
+	Config
+
+	// If not set default to the service name.
+	Durable        string        `yaml:"durable"`
+	InitialOffset  string        `yaml:"initial_offset"  default:"newest" validate:"oneof=newest oldest"`
+	HandlerTimeout time.Duration `yaml:"handler_timeout" default:"30s"`
+	RetryDisabled  bool          `yaml:"retry_disabled"  default:"false"`
+	RetryCount     uint8         `yaml:"retry_count"     default:"3"`
+	RetryDelay     time.Duration `yaml:"retry_delay"     default:"100ms"`
+}
+
+func (c *ConsumerConfig) getJetStreamConfig(serviceName, topic string) jetstream.ConsumerConfig {
+	if c.Durable == "" {
+		c.Durable = serviceName
+	}
+
+	maxDeliver := 1
+	if !c.RetryDisabled {
+		maxDeliver += int(c.RetryCount)
+	}
+
+	deliverPolicy := jetstream.DeliverNewPolicy
+	if c.InitialOffset == oldestOffset {
+		deliverPolicy = jetstream.DeliverAllPolicy
+	}
+
+	return jetstream.ConsumerConfig{
+		Durable:       c.Durable,
+		FilterSubject: topic,
+		DeliverPolicy: deliverPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.HandlerTimeout + ackWaitMargin,
+		MaxDeliver:    maxDeliver,
+	}
+}
+
+func connect(cfg Config, serviceName string) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.Name(serviceName),
+		nats.Timeout(setupTimeout),
+		// Keep reconnecting for as long as the process runs, like the Kafka clients
+		nats.MaxReconnects(-1),
+	}
+	if cfg.Username != "" && cfg.Password != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+
+	return nats.Connect(cfg.URL, opts...)
+}
+
+// streamName returns the name of the stream holding a topic. Stream names
+// can't contain dots, which topics use as separators.
+func streamName(topic string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(topic))
+}
+
+// ensureStream creates the stream of a topic unless it exists and returns
+// its name. Existing streams are left as they are, so operators can tune them.
+func ensureStream(ctx context.Context, js jetstream.JetStream, cfg Config, topic string) (string, error) {
+	name := streamName(topic)
+
+	_, err := js.Stream(ctx, name)
+	if err == nil {
+		return name, nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return "", err
+	}
+
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     name,
+		Subjects: []string{topic},
+		Storage:  jetstream.FileStorage,
+		MaxAge:   cfg.StreamMaxAge,
+	})
+	// Another producer or consumer may have created it meanwhile
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return "", err
+	}
+
+	return name, nil
+}
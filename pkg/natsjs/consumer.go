@@ -0,0 +1,198 @@
+package natsjs
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/events"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Consumer represents a durable NATS JetStream pull consumer. Instances
+// sharing a durable name split the messages between them, like a Kafka
+// consumer group. It implements events.Consumer.
+type Consumer struct {
+	cfg            ConsumerConfig
+	topic          string
+	serviceName    string
+	serviceVersion string
+	conn           *nats.Conn
+	consumer       jetstream.Consumer
+	handler        *events.Handler
+	logger         *slog.Logger
+
+	mu         sync.Mutex
+	consumeCtx jetstream.ConsumeContext
+}
+
+// NewConsumer creates a new NATS JetStream consumer, creating the topic's
+// stream and the durable consumer if needed.
+func NewConsumer(
+	cfg ConsumerConfig,
+	topic string,
+	serviceName string,
+	serviceVersion string,
+	handleFn events.HandleFunc,
+	logger *slog.Logger,
+) (*Consumer, error) {
+	const op = "natsjs.NewConsumer"
+
+	conn, err := connect(cfg.Config, serviceName)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errs.Wrap(op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	stream, err := ensureStream(ctx, js, cfg.Config, topic)
+	if err != nil {
+		conn.Close()
+		return nil, errs.Wrap(op, fmt.Errorf("failed to ensure stream for %s: %w", topic, err))
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, cfg.getJetStreamConfig(serviceName, topic))
+	if err != nil {
+		conn.Close()
+		return nil, errs.Wrap(op, err)
+	}
+
+	// Failed messages are redelivered by the server, not retried here
+	handler := &events.Handler{
+		HandleFn: handleFn,
+		Broker:   "nats",
+		Topic:    topic,
+		Timeout:  cfg.HandlerTimeout,
+		Logger:   logger,
+	}
+
+	return &Consumer{
+		cfg:            cfg,
+		topic:          topic,
+		serviceName:    serviceName,
+		serviceVersion: serviceVersion,
+		conn:           conn,
+		consumer:       consumer,
+		handler:        handler,
+		logger:         logger,
+	}, nil
+}
+
+// Start starts the consumer and blocks until it is stopped.
+func (c *Consumer) Start() error {
+	const op = "natsjs.Consumer.Start"
+
+	consumeCtx, err := c.consumer.Consume(
+		c.consumeMessage,
+		jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+			c.logger.Warn("nats consume error, retrying", "topic", c.topic, "error", err)
+		}),
+	)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	c.mu.Lock()
+	c.consumeCtx = consumeCtx
+	c.mu.Unlock()
+
+	<-consumeCtx.Closed()
+	return nil
+}
+
+// Stop waits for the message being handled and closes the connection.
+// Messages fetched but not yet handled are redelivered once their ack wait
+// expires.
+func (c *Consumer) Stop() error {
+	const op = "natsjs.Consumer.Stop"
+
+	c.mu.Lock()
+	consumeCtx := c.consumeCtx
+	c.mu.Unlock()
+
+	if consumeCtx != nil {
+		consumeCtx.Stop()
+		<-consumeCtx.Closed()
+	}
+
+	// Drain rather than close, so acks still in flight reach the server
+	if err := c.conn.Drain(); err != nil {
+		return errs.Wrap(op, err)
+	}
+	return nil
+}
+
+// consumeMessage runs the handler and settles the message with the server.
+// Failed messages are redelivered RetryDelay later until they were delivered
+// RetryCount more times, then dropped.
+func (c *Consumer) consumeMessage(msg jetstream.Msg) {
+	meta, metaErr := msg.Metadata()
+	if metaErr == nil {
+		consumerPending.Set(float64(meta.NumPending), c.topic)
+		if meta.NumDelivered > 1 {
+			consumerRedeliveriesTotal.Inc(c.topic)
+		}
+	}
+
+	// the error is already logged and reported by the handler
+	err := c.handleMessage(msg, meta)
+
+	switch {
+	case err == nil:
+		err = msg.Ack()
+	case c.cfg.RetryDisabled || metaErr != nil || meta.NumDelivered > uint64(c.cfg.RetryCount):
+		err = msg.Term()
+	default:
+		err = msg.NakWithDelay(c.cfg.RetryDelay)
+	}
+	if err != nil {
+		c.logger.Warn("failed to settle nats message", "topic", c.topic, "error", err)
+	}
+}
+
+// handleMessage passes the message to the injected handler and records the
+// result of the delivery. meta is nil if the message has no metadata.
+func (c *Consumer) handleMessage(msg jetstream.Msg, meta *jetstream.MsgMetadata) error {
+	headers := make(map[string]string, len(msg.Headers()))
+	for k, v := range msg.Headers() {
+		if k != keyHeader && len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+
+	var attrs []slog.Attr
+	if meta != nil {
+		attrs = append(attrs,
+			slog.Uint64("stream_sequence", meta.Sequence.Stream),
+			slog.Uint64("delivered", meta.NumDelivered),
+		)
+	}
+
+	start := time.Now()
+	err := c.handler.Handle(
+		context.Background(),
+		&events.Message{
+			Topic:   msg.Subject(),
+			Key:     []byte(msg.Headers().Get(keyHeader)),
+			Value:   msg.Data(),
+			Headers: headers,
+		},
+		attrs...,
+	)
+
+	consumerHandlerDuration.Observe(time.Since(start).Seconds(), c.topic)
+	consumerMessagesTotal.Inc(c.topic, resultLabel(err))
+
+	return err
+}
@@ -0,0 +1,46 @@
+package natsjs
+
+import "chatx-01-backend/pkg/metrics"
+
+var (
+	consumerPending = metrics.NewGauge(
+		"nats_consumer_pending",
+		"Messages in the stream not yet delivered to the consumer.",
+		"topic",
+	)
+	// Every delivery is counted, so a message failing all its retries adds
+	// RetryCount+1 errors before it is dropped.
+	consumerMessagesTotal = metrics.NewCounter(
+		"nats_consumer_messages_total",
+		"Number of message deliveries by handler result (success or error).",
+		"topic", "result",
+	)
+	consumerHandlerDuration = metrics.NewHistogram(
+		"nats_consumer_handler_duration_seconds",
+		"Time spent handling a delivered message.",
+		metrics.DefaultBuckets,
+		"topic",
+	)
+	consumerRedeliveriesTotal = metrics.NewCounter(
+		"nats_consumer_redeliveries_total",
+		"Number of deliveries of messages that were delivered before.",
+		"topic",
+	)
+	producerMessagesTotal = metrics.NewCounter(
+		"nats_producer_messages_total",
+		"Number of published messages by result (success or error).",
+		"topic", "result",
+	)
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+func resultLabel(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultSuccess
+}
@@ -0,0 +1,157 @@
+package natsjs
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/events"
+	"chatx-01-backend/pkg/requestid"
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// keyHeader carries the message key, which NATS messages don't have.
+const keyHeader = "Chatx-Key"
+
+// Producer represents a NATS JetStream producer publishing to the subject
+// named after its topic. It implements events.Producer.
+type Producer struct {
+	topic string
+	conn  *nats.Conn
+	js    jetstream.JetStream
+}
+
+// NewProducer creates a new NATS JetStream producer, creating the topic's
+// stream if needed.
+func NewProducer(
+	cfg Config,
+	topic string,
+	serviceName string,
+) (*Producer, error) {
+	const op = "natsjs.NewProducer"
+
+	conn, err := connect(cfg, serviceName)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errs.Wrap(op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	if _, err := ensureStream(ctx, js, cfg, topic); err != nil {
+		conn.Close()
+		return nil, errs.Wrap(op, fmt.Errorf("failed to ensure stream for %s: %w", topic, err))
+	}
+
+	return &Producer{
+		topic: topic,
+		conn:  conn,
+		js:    js,
+	}, nil
+}
+
+// SendMessage publishes a message and waits for the stream to store it.
+func (p *Producer) SendMessage(ctx context.Context, m *events.Message) error {
+	const op = "natsjs.SendMessage"
+
+	_, err := p.js.PublishMsg(ctx, p.buildNatsMsg(ctx, m))
+	producerMessagesTotal.Inc(p.topic, resultLabel(err))
+	if err != nil {
+		return errs.Wrap(op, fmt.Errorf("publish_error: %w, subject: %s", err, p.topic))
+	}
+
+	return nil
+}
+
+// SendMessages publishes multiple messages without waiting in between and
+// then waits for the stream to store all of them.
+func (p *Producer) SendMessages(ctx context.Context, messages []events.Message) error {
+	const op = "natsjs.SendMessages"
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var firstErr error
+	futures := make([]jetstream.PubAckFuture, 0, len(messages))
+	for i := range messages {
+		future, err := p.js.PublishMsgAsync(p.buildNatsMsg(ctx, &messages[i]))
+		if err != nil {
+			firstErr = err
+			break
+		}
+		futures = append(futures, future)
+	}
+
+	sent := 0
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+			sent++
+		case err := <-future.Err():
+			if firstErr == nil {
+				firstErr = err
+			}
+		case <-ctx.Done():
+			if firstErr == nil {
+				firstErr = ctx.Err()
+			}
+		}
+	}
+
+	producerMessagesTotal.Add(float64(sent), p.topic, resultSuccess)
+	if firstErr != nil {
+		producerMessagesTotal.Add(float64(len(messages)-sent), p.topic, resultError)
+		return errs.Wrap(op, fmt.Errorf("publish_error: %w, subject: %s", firstErr, p.topic))
+	}
+
+	return nil
+}
+
+func (p *Producer) buildNatsMsg(ctx context.Context, m *events.Message) *nats.Msg {
+	msg := nats.NewMsg(p.topic)
+	msg.Data = m.Value
+
+	for k, v := range m.Headers {
+		msg.Header.Set(k, v)
+	}
+	if len(m.Key) > 0 {
+		msg.Header.Set(keyHeader, string(m.Key))
+	}
+
+	// Propagate request and trace IDs unless the caller set them explicitly
+	addContextHeader(msg, m, requestid.Header, requestid.FromContext(ctx))
+	addContextHeader(msg, m, requestid.TraceParentHeader, requestid.TraceParentFromContext(ctx))
+
+	return msg
+}
+
+func addContextHeader(msg *nats.Msg, m *events.Message, key, value string) {
+	if value == "" {
+		return
+	}
+	if _, ok := m.Headers[key]; ok {
+		return
+	}
+
+	msg.Header.Set(key, value)
+}
+
+// Close waits for pending publishes and closes the connection.
+func (p *Producer) Close() error {
+	const op = "natsjs.Producer.Close"
+
+	err := p.conn.Drain()
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
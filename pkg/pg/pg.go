@@ -57,6 +57,16 @@ func isConflict(err error) bool {
 	return false
 }
 
+// ConstraintName returns the name of the constraint or index err violated, or
+// "" if err is not a constraint violation.
+func ConstraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
+
 func isNotFound(err error) bool {
 	return errors.Is(err, pgx.ErrNoRows)
 }
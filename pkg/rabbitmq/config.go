@@ -0,0 +1,90 @@
+package rabbitmq
+
+import (
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// setupTimeout bounds dialing the broker.
+	setupTimeout = 10 * time.Second
+	// prefetchCount is how many unacknowledged deliveries a consumer holds,
+	// so instances sharing a queue split its messages evenly.
+	prefetchCount = 10
+)
+
+// Config holds connection settings for a RabbitMQ broker.
+type Config struct {
+	// URL is the AMQP URI, including credentials and virtual host.
+	URL string `yaml:"url" mask:"true"`
+}
+
+// ConsumerConfig holds configuration for a RabbitMQ consumer.
+type ConsumerConfig struct {
+	Config
+
+	// Names the consumer's queue; if not set default to the service name.
+	GroupID        string        `yaml:"group_id"`
+	HandlerTimeout time.Duration `yaml:"handler_timeout" default:"30s"`
+	RetryDisabled  bool          `yaml:"retry_disabled"  default:"false"`
+	RetryCount     uint8         `yaml:"retry_count"     default:"3"`
+	RetryDelay     time.Duration `yaml:"retry_delay"     default:"100ms"`
+}
+
+func dial(cfg Config, serviceName string) (*amqp.Connection, error) {
+	return amqp.DialConfig(cfg.URL, amqp.Config{
+		Properties: amqp.Table{"connection_name": serviceName},
+		Dial:       amqp.DefaultDial(setupTimeout),
+	})
+}
+
+// deadLetterExchange returns the name of the exchange receiving a topic's
+// messages that failed all their retries.
+func deadLetterExchange(topic string) string {
+	return topic + ".dlx"
+}
+
+// queueName returns the name of a consumer group's queue for a topic.
+func queueName(topic, groupID string) string {
+	return topic + "." + groupID
+}
+
+// declareExchanges declares the fanout exchange of a topic, which copies
+// every message to the queue of each consumer group, and its dead-letter
+// exchange. Declaring is idempotent, so producers and consumers both do it.
+func declareExchanges(ch *amqp.Channel, topic string) error {
+	if err := ch.ExchangeDeclare(topic, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	return ch.ExchangeDeclare(deadLetterExchange(topic), amqp.ExchangeDirect, true, false, false, false, nil)
+}
+
+// declareQueue declares the queue of a consumer group bound to the topic's
+// exchange, and its dead-letter queue. Dead letters are routed by queue name,
+// so each group only receives its own.
+func declareQueue(ch *amqp.Channel, topic, groupID string) (string, error) {
+	name := queueName(topic, groupID)
+	deadName := name + ".dead"
+
+	if _, err := ch.QueueDeclare(deadName, true, false, false, false, nil); err != nil {
+		return "", err
+	}
+	if err := ch.QueueBind(deadName, name, deadLetterExchange(topic), false, nil); err != nil {
+		return "", err
+	}
+
+	_, err := ch.QueueDeclare(name, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    deadLetterExchange(topic),
+		"x-dead-letter-routing-key": name,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := ch.QueueBind(name, "", topic, false, nil); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
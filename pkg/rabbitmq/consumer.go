@@ -0,0 +1,217 @@
+package rabbitmq
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/events"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var errConnectionClosed = errors.New("channel closed by broker")
+
+// Consumer represents a RabbitMQ consumer of its group's queue. Instances
+// sharing a group ID split the queue's messages between them, like a Kafka
+// consumer group. It implements events.Consumer.
+type Consumer struct {
+	cfg            ConsumerConfig
+	topic          string
+	queue          string
+	serviceName    string
+	serviceVersion string
+	conn           *amqp.Connection
+	channel        *amqp.Channel
+	handler        *events.Handler
+	logger         *slog.Logger
+
+	mu       sync.Mutex
+	started  bool
+	stopping bool
+	done     chan struct{}
+}
+
+// NewConsumer creates a new RabbitMQ consumer, declaring the topic's
+// exchanges and the group's queues.
+func NewConsumer(
+	cfg ConsumerConfig,
+	topic string,
+	serviceName string,
+	serviceVersion string,
+	handleFn events.HandleFunc,
+	logger *slog.Logger,
+) (*Consumer, error) {
+	const op = "rabbitmq.NewConsumer"
+
+	if cfg.GroupID == "" {
+		cfg.GroupID = serviceName
+	}
+
+	conn, err := dial(cfg.Config, serviceName)
+	if err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, errs.Wrap(op, err)
+	}
+
+	err = declareExchanges(channel, topic)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errs.Wrap(op, fmt.Errorf("failed to declare exchanges for %s: %w", topic, err))
+	}
+
+	queue, err := declareQueue(channel, topic, cfg.GroupID)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errs.Wrap(op, fmt.Errorf("failed to declare queue for %s: %w", topic, err))
+	}
+
+	if err := channel.Qos(prefetchCount, 0, false); err != nil {
+		_ = conn.Close()
+		return nil, errs.Wrap(op, err)
+	}
+
+	handler := &events.Handler{
+		HandleFn:   handleFn,
+		Broker:     "rabbitmq",
+		Topic:      topic,
+		Timeout:    cfg.HandlerTimeout,
+		RetryDelay: cfg.RetryDelay,
+		OnRetry:    func() { consumerRetriesTotal.Inc(topic) },
+		Logger:     logger,
+	}
+	if !cfg.RetryDisabled {
+		handler.RetryCount = int(cfg.RetryCount)
+	}
+
+	return &Consumer{
+		cfg:            cfg,
+		topic:          topic,
+		queue:          queue,
+		serviceName:    serviceName,
+		serviceVersion: serviceVersion,
+		conn:           conn,
+		channel:        channel,
+		handler:        handler,
+		logger:         logger,
+		done:           make(chan struct{}),
+	}, nil
+}
+
+// Start starts the consumer and blocks until it is stopped. It returns an
+// error when the broker closes the channel.
+func (c *Consumer) Start() error {
+	const op = "rabbitmq.Consumer.Start"
+
+	c.mu.Lock()
+	if c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+	deliveries, err := c.channel.Consume(c.queue, c.consumerTag(), false, false, false, false, nil)
+	if err != nil {
+		c.mu.Unlock()
+		return errs.Wrap(op, err)
+	}
+	c.started = true
+	c.mu.Unlock()
+
+	defer close(c.done)
+
+	for delivery := range deliveries {
+		c.consumeDelivery(&delivery)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopping {
+		return nil
+	}
+	return errs.Wrap(op, errConnectionClosed)
+}
+
+// Stop waits for the fetched deliveries to be handled and closes the connection.
+func (c *Consumer) Stop() error {
+	const op = "rabbitmq.Consumer.Stop"
+
+	c.mu.Lock()
+	c.stopping = true
+	started := c.started
+	c.mu.Unlock()
+
+	if started {
+		if err := c.channel.Cancel(c.consumerTag(), false); err != nil {
+			return errs.Wrap(op, err)
+		}
+		<-c.done
+	}
+
+	if err := c.conn.Close(); err != nil {
+		return errs.Wrap(op, err)
+	}
+	return nil
+}
+
+func (c *Consumer) consumerTag() string {
+	return c.serviceName + "-" + c.serviceVersion
+}
+
+// consumeDelivery runs the handler and settles the delivery. Messages
+// failing all their retries are dead-lettered to the group's dead queue.
+func (c *Consumer) consumeDelivery(delivery *amqp.Delivery) {
+	// the error is already logged and reported by the handler
+	err := c.handleMessage(delivery)
+
+	if err == nil {
+		err = delivery.Ack(false)
+	} else {
+		consumerDeadLetteredTotal.Inc(c.topic)
+		err = delivery.Nack(false, false)
+	}
+	if err != nil {
+		c.logger.Warn("failed to settle rabbitmq delivery", "topic", c.topic, "error", err)
+	}
+}
+
+// handleMessage passes the message to the injected handler and records its
+// final result.
+func (c *Consumer) handleMessage(delivery *amqp.Delivery) error {
+	start := time.Now()
+	err := c.handler.Handle(
+		context.Background(),
+		&events.Message{
+			Topic:   c.topic,
+			Key:     []byte(delivery.RoutingKey),
+			Value:   delivery.Body,
+			Headers: headerStrings(delivery.Headers),
+		},
+		slog.String("queue", c.queue),
+		slog.Uint64("delivery_tag", delivery.DeliveryTag),
+		slog.Bool("redelivered", delivery.Redelivered),
+	)
+
+	consumerHandlerDuration.Observe(time.Since(start).Seconds(), c.topic)
+	consumerMessagesTotal.Inc(c.topic, resultLabel(err))
+
+	return err
+}
+
+// headerStrings returns the string-valued headers of a delivery. Producers
+// only set strings, but the broker adds others, such as x-death.
+func headerStrings(table amqp.Table) map[string]string {
+	headers := make(map[string]string, len(table))
+	for k, v := range table {
+		if s, ok := v.(string); ok {
+			headers[k] = s
+		}
+	}
+	return headers
+}
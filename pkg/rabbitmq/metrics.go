@@ -0,0 +1,46 @@
+package rabbitmq
+
+import "chatx-01-backend/pkg/metrics"
+
+var (
+	consumerMessagesTotal = metrics.NewCounter(
+		"rabbitmq_consumer_messages_total",
+		"Number of consumed messages by final handler result (success or error).",
+		"topic", "result",
+	)
+	consumerHandlerDuration = metrics.NewHistogram(
+		"rabbitmq_consumer_handler_duration_seconds",
+		"Time spent handling a consumed message, including retries.",
+		metrics.DefaultBuckets,
+		"topic",
+	)
+	consumerRetriesTotal = metrics.NewCounter(
+		"rabbitmq_consumer_retries_total",
+		"Number of handler retries after a failed attempt.",
+		"topic",
+	)
+	// Dead-lettered messages stay in the group's dead queue for inspection
+	// or manual replay.
+	consumerDeadLetteredTotal = metrics.NewCounter(
+		"rabbitmq_consumer_dead_lettered_total",
+		"Number of messages dead-lettered after their retries were exhausted.",
+		"topic",
+	)
+	producerMessagesTotal = metrics.NewCounter(
+		"rabbitmq_producer_messages_total",
+		"Number of published messages by result (success or error).",
+		"topic", "result",
+	)
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+func resultLabel(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultSuccess
+}
@@ -0,0 +1,198 @@
+package rabbitmq
+
+import (
+	"chatx-01-backend/pkg/errs"
+	"chatx-01-backend/pkg/events"
+	"chatx-01-backend/pkg/requestid"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var errNacked = errors.New("message nacked by broker")
+
+// Producer represents a RabbitMQ producer publishing to the exchange named
+// after its topic with publisher confirms. Messages are only kept for the
+// consumer groups whose queue already exists. It implements events.Producer.
+type Producer struct {
+	cfg         Config
+	topic       string
+	serviceName string
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+// NewProducer creates a new RabbitMQ producer, declaring the topic's exchanges.
+func NewProducer(
+	cfg Config,
+	topic string,
+	serviceName string,
+) (*Producer, error) {
+	const op = "rabbitmq.NewProducer"
+
+	p := &Producer{
+		cfg:         cfg,
+		topic:       topic,
+		serviceName: serviceName,
+	}
+
+	if _, err := p.getChannel(); err != nil {
+		return nil, errs.Wrap(op, err)
+	}
+
+	return p, nil
+}
+
+// getChannel returns the publishing channel, reconnecting when the broker
+// closed the previous one.
+func (p *Producer) getChannel() (*amqp.Channel, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.channel != nil && !p.channel.IsClosed() {
+		return p.channel, nil
+	}
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+
+	conn, err := dial(p.cfg, p.serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := conn.Channel()
+	if err == nil {
+		err = channel.Confirm(false)
+	}
+	if err == nil {
+		err = declareExchanges(channel, p.topic)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	p.conn = conn
+	p.channel = channel
+
+	return channel, nil
+}
+
+// SendMessage publishes a message and waits for the broker to confirm it.
+func (p *Producer) SendMessage(ctx context.Context, m *events.Message) error {
+	const op = "rabbitmq.SendMessage"
+
+	err := p.publish(ctx, []events.Message{*m})
+	producerMessagesTotal.Inc(p.topic, resultLabel(err))
+	if err != nil {
+		return errs.Wrap(op, fmt.Errorf("publish_error: %w, exchange: %s", err, p.topic))
+	}
+
+	return nil
+}
+
+// SendMessages publishes multiple messages and waits for the broker to
+// confirm all of them.
+func (p *Producer) SendMessages(ctx context.Context, messages []events.Message) error {
+	const op = "rabbitmq.SendMessages"
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	err := p.publish(ctx, messages)
+	producerMessagesTotal.Add(float64(len(messages)), p.topic, resultLabel(err))
+	if err != nil {
+		return errs.Wrap(op, fmt.Errorf("publish_error: %w, exchange: %s", err, p.topic))
+	}
+
+	return nil
+}
+
+func (p *Producer) publish(ctx context.Context, messages []events.Message) error {
+	channel, err := p.getChannel()
+	if err != nil {
+		return err
+	}
+
+	confirmations := make([]*amqp.DeferredConfirmation, 0, len(messages))
+	for i := range messages {
+		confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+			ctx,
+			p.topic,
+			string(messages[i].Key), // Not used for routing, but passed on to consumers
+			false,
+			false,
+			p.buildPublishing(ctx, &messages[i]),
+		)
+		if err != nil {
+			return err
+		}
+		confirmations = append(confirmations, confirmation)
+	}
+
+	for _, confirmation := range confirmations {
+		acked, err := confirmation.WaitContext(ctx)
+		if err != nil {
+			return err
+		}
+		if !acked {
+			return errNacked
+		}
+	}
+
+	return nil
+}
+
+func (p *Producer) buildPublishing(ctx context.Context, m *events.Message) amqp.Publishing {
+	headers := make(amqp.Table, len(m.Headers)+2)
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+
+	// Propagate request and trace IDs unless the caller set them explicitly
+	addContextHeader(headers, m, requestid.Header, requestid.FromContext(ctx))
+	addContextHeader(headers, m, requestid.TraceParentHeader, requestid.TraceParentFromContext(ctx))
+
+	return amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         m.Value,
+	}
+}
+
+func addContextHeader(headers amqp.Table, m *events.Message, key, value string) {
+	if value == "" {
+		return
+	}
+	if _, ok := m.Headers[key]; ok {
+		return
+	}
+
+	headers[key] = value
+}
+
+// Close closes the producer's connection.
+func (p *Producer) Close() error {
+	const op = "rabbitmq.Producer.Close"
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil || p.conn.IsClosed() {
+		return nil
+	}
+	if err := p.conn.Close(); err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	return nil
+}
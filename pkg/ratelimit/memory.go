@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process Limiter for development without Redis. Each
+// process counts on its own.
+type Memory struct {
+	mu      sync.Mutex
+	windows map[string]window
+}
+
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewMemory creates an in-process limiter.
+func NewMemory() *Memory {
+	return &Memory{windows: make(map[string]window)}
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, limit int, period time.Duration) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		// Drop expired windows so keys that stop being used don't pile up
+		for k, other := range m.windows {
+			if !now.Before(other.resetAt) {
+				delete(m.windows, k)
+			}
+		}
+		w = window{resetAt: now.Add(period)}
+	}
+
+	w.count++
+	m.windows[key] = w
+
+	if w.count > limit {
+		return false, w.resetAt.Sub(now), nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,13 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter counts events per key in fixed windows.
+type Limiter interface {
+	// Allow records an event for key and reports whether it is within limit
+	// events per window. If not, retryAfter is when the window resets.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (ok bool, retryAfter time.Duration, err error)
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Assistant layout:
+//   assistant:<kind>:<chat_id>:<last_message_id>:<user_id>  STRING  result
+// A new message changes the last message ID, so results never cover a stale
+// conversation and simply expire. Summaries are shared and use user ID 0.
+
+func assistantKey(kind string, chatID, lastMessageID, userID int) string {
+	return fmt.Sprintf("assistant:%s:%d:%d:%d", kind, chatID, lastMessageID, userID)
+}
+
+// GetAssistantResult returns a cached result for a chat up to its last
+// message. ok is false if none is cached.
+func (c *Client) GetAssistantResult(
+	ctx context.Context,
+	kind string,
+	chatID, lastMessageID, userID int,
+) (result string, ok bool, err error) {
+	result, err = c.rdb.Get(ctx, assistantKey(kind, chatID, lastMessageID, userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get assistant result: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// SetAssistantResult caches a result for a chat up to its last message for ttl.
+func (c *Client) SetAssistantResult(
+	ctx context.Context,
+	kind string,
+	chatID, lastMessageID, userID int,
+	result string,
+	ttl time.Duration,
+) error {
+	if err := c.rdb.Set(ctx, assistantKey(kind, chatID, lastMessageID, userID), result, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set assistant result: %w", err)
+	}
+
+	return nil
+}
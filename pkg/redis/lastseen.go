@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Last seen layout:
+//   last_seen:<user_id>  STRING  unix ms of the user's last activity
+
+func lastSeenKey(userID int) string {
+	return fmt.Sprintf("last_seen:%d", userID)
+}
+
+// SetLastSeen records when users were last active.
+func (c *Client) SetLastSeen(ctx context.Context, seen map[int]time.Time) error {
+	if len(seen) == 0 {
+		return nil
+	}
+
+	pipe := c.rdb.Pipeline()
+	for userID, at := range seen {
+		pipe.Set(ctx, lastSeenKey(userID), at.UnixMilli(), 0)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set last seen: %w", err)
+	}
+
+	return nil
+}
+
+// LastSeen returns when the given users were last active. Users without a
+// recorded time are left out.
+func (c *Client) LastSeen(ctx context.Context, userIDs []int) (map[int]time.Time, error) {
+	if len(userIDs) == 0 {
+		return map[int]time.Time{}, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = lastSeenKey(userID)
+	}
+
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last seen: %w", err)
+	}
+
+	seen := make(map[int]time.Time, len(userIDs))
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		ms, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		seen[userIDs[i]] = time.UnixMilli(ms)
+	}
+
+	return seen, nil
+}
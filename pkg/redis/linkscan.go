@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Link scan layout:
+//   linkscan:<sha256 of url>  STRING  threat type, empty for safe links
+// URLs are hashed to bound the key length.
+
+func linkScanKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "linkscan:" + hex.EncodeToString(sum[:])
+}
+
+// GetLinkThreat returns the cached threat type of a URL, empty if it was
+// found safe. ok is false if the URL hasn't been scanned.
+func (c *Client) GetLinkThreat(ctx context.Context, url string) (threat string, ok bool, err error) {
+	threat, err = c.rdb.Get(ctx, linkScanKey(url)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get link scan result: %w", err)
+	}
+
+	return threat, true, nil
+}
+
+// SetLinkThreat caches the threat type of a URL, empty if it's safe, for ttl.
+func (c *Client) SetLinkThreat(ctx context.Context, url, threat string, ttl time.Duration) error {
+	if err := c.rdb.Set(ctx, linkScanKey(url), threat, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set link scan result: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Presence layout:
+//   presence:user:<user_id>       ZSET  member=instance ID, score=expiry (unix ms)
+//   presence:instance:<instance>  HASH  field=user ID, value=connection count
+// A user is online while at least one instance entry has not expired, so a
+// crashed instance stops counting once its last heartbeat TTL runs out.
+
+func presenceUserKey(userID int) string {
+	return fmt.Sprintf("presence:user:%d", userID)
+}
+
+func presenceInstanceKey(instanceID string) string {
+	return fmt.Sprintf("presence:instance:%s", instanceID)
+}
+
+// SetPresence records the connection counts held by an instance and extends
+// their lifetime by ttl. Users with a zero count are removed for that instance.
+func (c *Client) SetPresence(ctx context.Context, instanceID string, counts map[int]int, ttl time.Duration) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	expiresAt := float64(now.Add(ttl).UnixMilli())
+	stale := strconv.FormatInt(now.UnixMilli(), 10)
+	instanceKey := presenceInstanceKey(instanceID)
+
+	pipe := c.rdb.Pipeline()
+	for userID, count := range counts {
+		userKey := presenceUserKey(userID)
+		field := strconv.Itoa(userID)
+
+		if count <= 0 {
+			pipe.ZRem(ctx, userKey, instanceID)
+			pipe.HDel(ctx, instanceKey, field)
+			continue
+		}
+
+		pipe.ZAdd(ctx, userKey, redis.Z{Score: expiresAt, Member: instanceID})
+		pipe.ZRemRangeByScore(ctx, userKey, "-inf", "("+stale)
+		pipe.Expire(ctx, userKey, ttl)
+		pipe.HSet(ctx, instanceKey, field, count)
+	}
+	pipe.Expire(ctx, instanceKey, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set presence: %w", err)
+	}
+
+	return nil
+}
+
+// ClearInstancePresence removes every presence entry owned by an instance.
+func (c *Client) ClearInstancePresence(ctx context.Context, instanceID string) error {
+	instanceKey := presenceInstanceKey(instanceID)
+
+	fields, err := c.rdb.HKeys(ctx, instanceKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get instance presence: %w", err)
+	}
+
+	pipe := c.rdb.Pipeline()
+	for _, field := range fields {
+		pipe.ZRem(ctx, "presence:user:"+field, instanceID)
+	}
+	pipe.Del(ctx, instanceKey)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to clear instance presence: %w", err)
+	}
+
+	return nil
+}
+
+// OnlineUsers returns the subset of userIDs that have a live entry on any instance.
+func (c *Client) OnlineUsers(ctx context.Context, userIDs []int) ([]int, error) {
+	if len(userIDs) == 0 {
+		return []int{}, nil
+	}
+
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	pipe := c.rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, len(userIDs))
+	for i, userID := range userIDs {
+		cmds[i] = pipe.ZCount(ctx, presenceUserKey(userID), now, "+inf")
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get online users: %w", err)
+	}
+
+	online := make([]int, 0)
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			online = append(online, userIDs[i])
+		}
+	}
+
+	return online, nil
+}
+
+// InstancePresence returns the connection counts per user of every instance
+// whose heartbeat has not expired, keyed by instance ID.
+func (c *Client) InstancePresence(ctx context.Context) (map[string]map[int]int, error) {
+	const prefix = "presence:instance:"
+
+	keys := make([]string, 0)
+	iter := c.rdb.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan instance presence: %w", err)
+	}
+
+	pipe := c.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HGetAll(ctx, key)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get instance presence: %w", err)
+	}
+
+	instances := make(map[string]map[int]int, len(keys))
+	for i, cmd := range cmds {
+		counts := make(map[int]int, len(cmd.Val()))
+		for field, value := range cmd.Val() {
+			userID, err := strconv.Atoi(field)
+			if err != nil {
+				continue
+			}
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				continue
+			}
+			counts[userID] = count
+		}
+		if len(counts) > 0 {
+			instances[strings.TrimPrefix(keys[i], prefix)] = counts
+		}
+	}
+
+	return instances, nil
+}
@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+)
+
+// Publish sends payload to every subscriber of channel.
+func (c *Client) Publish(ctx context.Context, channel string, payload []byte) error {
+	if err := c.rdb.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+
+	return nil
+}
+
+// Subscribe calls handle for every message published to channel until ctx
+// is done. The underlying connection reconnects automatically on failure.
+func (c *Client) Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error {
+	pubsub := c.rdb.Subscribe(ctx, channel)
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed before consuming.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handle([]byte(msg.Payload))
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Rate limit layout:
+//   ratelimit:<key>  STRING  events in the current window, expiring with it
+
+// Allow records an event for key and reports whether it is within limit
+// events per window, shared by all instances. If not, retryAfter is when the
+// window resets.
+func (c *Client) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	redisKey := "ratelimit:" + key
+
+	pipe := c.rdb.Pipeline()
+	incr := pipe.Incr(ctx, redisKey)
+	pipe.ExpireNX(ctx, redisKey, window)
+	ttl := pipe.PTTL(ctx, redisKey)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, 0, fmt.Errorf("failed to count rate limited event: %w", err)
+	}
+
+	if incr.Val() > int64(limit) {
+		return false, max(ttl.Val(), 0), nil
+	}
+	return true, 0, nil
+}
@@ -128,6 +128,23 @@ func (c *Client) RevokeToken(ctx context.Context, tokenID string, tokenType stri
 	return nil
 }
 
+// ConsumeToken revokes a token and reports whether it was still stored. Of
+// concurrent calls for the same token, only one gets true.
+func (c *Client) ConsumeToken(ctx context.Context, tokenID string, tokenType string, userID int) (bool, error) {
+	key := fmt.Sprintf("token:%s:%s", tokenType, tokenID)
+	userIndexKey := fmt.Sprintf("user:tokens:%d", userID)
+
+	pipe := c.rdb.Pipeline()
+	deleted := pipe.Del(ctx, key)
+	pipe.SRem(ctx, userIndexKey, tokenID)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	return deleted.Val() == 1, nil
+}
+
 // RevokeAllUserTokens revokes all tokens for a specific user.
 func (c *Client) RevokeAllUserTokens(ctx context.Context, userID int) error {
 	userIndexKey := fmt.Sprintf("user:tokens:%d", userID)
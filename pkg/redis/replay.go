@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Replay layout:
+//   ws:replay:event:<id>   STRING  event, expires after the TTL
+//   ws:replay:<user>:seq   STRING  last sequence number of the user, never expires
+//   ws:replay:<user>:ids   ZSET    member=event id, score=seq
+
+func replayEventKey(eventID string) string {
+	return fmt.Sprintf("ws:replay:event:%s", eventID)
+}
+
+func replaySeqKey(userID int) string {
+	return fmt.Sprintf("ws:replay:%d:seq", userID)
+}
+
+func replayIDsKey(userID int) string {
+	return fmt.Sprintf("ws:replay:%d:ids", userID)
+}
+
+// appendReplayScript appends events to a user's stream in one step, so they
+// are kept in the order of their numbers. Events already in the stream keep
+// their number. ARGV: max events, ttl in ms, event IDs.
+var appendReplayScript = redis.NewScript(`
+local seqs = {}
+for i = 3, #ARGV do
+	local seq = redis.call('ZSCORE', KEYS[2], ARGV[i])
+	if seq then
+		seq = tonumber(seq)
+	else
+		seq = redis.call('INCR', KEYS[1])
+		redis.call('ZADD', KEYS[2], seq, ARGV[i])
+	end
+	seqs[#seqs + 1] = seq
+end
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[1]) - 1)
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return seqs
+`)
+
+// AppendReplay keeps events, by ID, for ttl and appends them to the streams
+// of the users they are sent to, in the given order. A stream keeps its
+// maxEvents latest events and is dropped ttl after the last one was added,
+// but its numbering goes on. It returns the sequence numbers by user and
+// event ID.
+func (c *Client) AppendReplay(
+	ctx context.Context,
+	events map[string][]byte,
+	streams map[int][]string,
+	maxEvents int,
+	ttl time.Duration,
+) (map[int]map[string]int64, error) {
+	exec := func() (map[int]*redis.Cmd, error) {
+		pipe := c.rdb.Pipeline()
+		for id, data := range events {
+			pipe.SetNX(ctx, replayEventKey(id), data, ttl)
+		}
+
+		cmds := make(map[int]*redis.Cmd, len(streams))
+		for userID, ids := range streams {
+			args := make([]any, 0, len(ids)+2)
+			args = append(args, maxEvents, ttl.Milliseconds())
+			for _, id := range ids {
+				args = append(args, id)
+			}
+			cmds[userID] = appendReplayScript.EvalSha(ctx, pipe,
+				[]string{replaySeqKey(userID), replayIDsKey(userID)},
+				args...,
+			)
+		}
+
+		_, err := pipe.Exec(ctx)
+		return cmds, err
+	}
+
+	cmds, err := exec()
+	if redis.HasErrorPrefix(err, "NOSCRIPT") {
+		// First use since Redis started, load the script and retry
+		if err := appendReplayScript.Load(ctx, c.rdb).Err(); err != nil {
+			return nil, fmt.Errorf("failed to load replay script: %w", err)
+		}
+		cmds, err = exec()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to append replayed events: %w", err)
+	}
+
+	seqs := make(map[int]map[string]int64, len(streams))
+	for userID, ids := range streams {
+		numbers, err := cmds[userID].Int64Slice()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read replay sequence numbers: %w", err)
+		}
+		if len(numbers) != len(ids) {
+			return nil, fmt.Errorf("got %d replay sequence numbers for %d events", len(numbers), len(ids))
+		}
+
+		seqs[userID] = make(map[string]int64, len(ids))
+		for i, id := range ids {
+			seqs[userID][id] = numbers[i]
+		}
+	}
+
+	return seqs, nil
+}
+
+// ReplaySince returns the last sequence number of a user's stream and the
+// events kept after the given one, by sequence number.
+func (c *Client) ReplaySince(ctx context.Context, userID int, after int64) (int64, map[int64][]byte, error) {
+	pipe := c.rdb.Pipeline()
+	head := pipe.Get(ctx, replaySeqKey(userID))
+	members := pipe.ZRangeByScoreWithScores(ctx, replayIDsKey(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(after, 10),
+		Max: "+inf",
+	})
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, nil, fmt.Errorf("failed to get replayed events: %w", err)
+	}
+
+	seq, err := head.Int64()
+	if err != nil && err != redis.Nil {
+		return 0, nil, fmt.Errorf("failed to get replay sequence: %w", err)
+	}
+
+	events := make(map[int64][]byte, len(members.Val()))
+	if len(members.Val()) == 0 {
+		return seq, events, nil
+	}
+
+	keys := make([]string, len(members.Val()))
+	for i, member := range members.Val() {
+		keys[i] = replayEventKey(fmt.Sprint(member.Member))
+	}
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get replayed events: %w", err)
+	}
+
+	// Expired events are left out
+	for i, member := range members.Val() {
+		if data, ok := values[i].(string); ok {
+			events[int64(member.Score)] = []byte(data)
+		}
+	}
+
+	return seq, events, nil
+}
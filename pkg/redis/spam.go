@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Spam counter layout:
+//   spam:<key>  SET  distinct members seen in the current window, expiring with it
+
+// AddDistinct adds member to the set of key and returns how many distinct
+// members it holds in the current window, shared by all instances.
+func (c *Client) AddDistinct(ctx context.Context, key, member string, window time.Duration) (int, error) {
+	redisKey := "spam:" + key
+
+	pipe := c.rdb.Pipeline()
+	pipe.SAdd(ctx, redisKey, member)
+	pipe.ExpireNX(ctx, redisKey, window)
+	card := pipe.SCard(ctx, redisKey)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count distinct spam members: %w", err)
+	}
+
+	return int(card.Val()), nil
+}
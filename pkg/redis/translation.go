@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Translation layout:
+//   translation:<message_id>:<revision>:<lang>  HASH  text, source
+// The revision changes whenever the message is edited, so stale translations
+// are never served and simply expire.
+
+func translationKey(messageID int, revision int64, lang string) string {
+	return fmt.Sprintf("translation:%d:%d:%s", messageID, revision, lang)
+}
+
+// GetTranslation returns a cached translation of a message revision.
+// ok is false if none is cached.
+func (c *Client) GetTranslation(
+	ctx context.Context,
+	messageID int,
+	revision int64,
+	lang string,
+) (text, sourceLang string, ok bool, err error) {
+	values, err := c.rdb.HMGet(ctx, translationKey(messageID, revision, lang), "text", "source").Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", "", false, nil
+		}
+		return "", "", false, fmt.Errorf("failed to get translation: %w", err)
+	}
+
+	text, hasText := values[0].(string)
+	sourceLang, _ = values[1].(string)
+	if !hasText {
+		return "", "", false, nil
+	}
+
+	return text, sourceLang, true, nil
+}
+
+// SetTranslation caches a translation of a message revision for ttl.
+func (c *Client) SetTranslation(
+	ctx context.Context,
+	messageID int,
+	revision int64,
+	lang, text, sourceLang string,
+	ttl time.Duration,
+) error {
+	key := translationKey(messageID, revision, lang)
+
+	pipe := c.rdb.Pipeline()
+	pipe.HSet(ctx, key, "text", text, "source", sourceLang)
+	pipe.Expire(ctx, key, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set translation: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package reqctx
+
+import (
+	"context"
+	"sync"
+)
+
+// Scope carries request-scoped values that are filled in by inner handlers
+// (e.g. the authenticated user) but must be visible to outer middlewares
+// such as the access log, panic recovery and error reporting.
+type Scope struct {
+	mu        sync.RWMutex
+	userID    int
+	route     string
+	logPath   string
+	clientIP  string
+	userAgent string
+}
+
+type scopeKey struct{}
+
+// WithScope attaches a new, empty scope to ctx.
+func WithScope(ctx context.Context) (context.Context, *Scope) {
+	s := &Scope{}
+	return context.WithValue(ctx, scopeKey{}, s), s
+}
+
+// FromContext returns the scope attached to ctx, or nil.
+func FromContext(ctx context.Context) *Scope {
+	s, _ := ctx.Value(scopeKey{}).(*Scope)
+	return s
+}
+
+// SetUserID records the authenticated user for the request. No-op without a scope.
+func SetUserID(ctx context.Context, userID int) {
+	if s := FromContext(ctx); s != nil {
+		s.mu.Lock()
+		s.userID = userID
+		s.mu.Unlock()
+	}
+}
+
+// UserID returns the authenticated user recorded for the request, or 0.
+func UserID(ctx context.Context) int {
+	s := FromContext(ctx)
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.userID
+}
+
+// SetRoute records the route pattern that matched the request. No-op without a scope.
+func SetRoute(ctx context.Context, route string) {
+	if s := FromContext(ctx); s != nil {
+		s.mu.Lock()
+		s.route = route
+		s.mu.Unlock()
+	}
+}
+
+// Route returns the matched route pattern recorded for the request, or "".
+func Route(ctx context.Context) string {
+	s := FromContext(ctx)
+	if s == nil {
+		return ""
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.route
+}
+
+// SetLogPath replaces the path logged for the request, for paths that carry
+// a secret such as an incoming webhook token. No-op without a scope.
+func SetLogPath(ctx context.Context, path string) {
+	if s := FromContext(ctx); s != nil {
+		s.mu.Lock()
+		s.logPath = path
+		s.mu.Unlock()
+	}
+}
+
+// LogPath returns the path to log for the request: the one set by
+// SetLogPath, or path.
+func LogPath(ctx context.Context, path string) string {
+	s := FromContext(ctx)
+	if s == nil {
+		return path
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.logPath != "" {
+		return s.logPath
+	}
+	return path
+}
+
+// SetClient records the address and user agent of the client that sent the
+// request. No-op without a scope.
+func SetClient(ctx context.Context, ip, userAgent string) {
+	if s := FromContext(ctx); s != nil {
+		s.mu.Lock()
+		s.clientIP = ip
+		s.userAgent = userAgent
+		s.mu.Unlock()
+	}
+}
+
+// Client returns the client address and user agent recorded for the
+// request, or empty strings.
+func Client(ctx context.Context) (ip, userAgent string) {
+	s := FromContext(ctx)
+	if s == nil {
+		return "", ""
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientIP, s.userAgent
+}
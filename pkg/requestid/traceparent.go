@@ -0,0 +1,40 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// TraceParentHeader is the W3C Trace Context header.
+const TraceParentHeader = "traceparent"
+
+var traceParentRe = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+type traceParentKey struct{}
+
+// ValidTraceParent reports whether v is a well-formed traceparent value.
+func ValidTraceParent(v string) bool {
+	return traceParentRe.MatchString(v)
+}
+
+// NewTraceParent starts a new sampled trace with a random trace and span ID.
+func NewTraceParent() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(b[:16]) + "-" + hex.EncodeToString(b[16:]) + "-01"
+}
+
+// WithTraceParent returns a copy of ctx carrying the traceparent value.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, traceParent)
+}
+
+// TraceParentFromContext returns the traceparent stored in ctx, or an empty string.
+func TraceParentFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(traceParentKey{}).(string)
+	return v
+}
@@ -0,0 +1,98 @@
+// Package snowflake generates time-sortable 64-bit IDs without a central
+// sequence.
+//
+// An ID is laid out as, from the most significant bit:
+//
+//	1 bit   unused, keeps IDs positive
+//	41 bits milliseconds since Epoch (about 69 years)
+//	10 bits worker ID, unique per running instance
+//	12 bits sequence within the millisecond
+//
+// IDs from one generator are strictly increasing. IDs from different
+// generators are ordered by creation time to the millisecond.
+package snowflake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	workerBits   = 10
+	sequenceBits = 12
+
+	// MaxWorkerID is the largest valid worker ID.
+	MaxWorkerID = 1<<workerBits - 1
+
+	maxSequence = 1<<sequenceBits - 1
+	timeShift   = workerBits + sequenceBits
+)
+
+// Epoch is the start of the ID timeline. Changing it would break ordering
+// against existing IDs.
+var Epoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator hands out IDs for a single worker. It is safe for concurrent use.
+type Generator struct {
+	mu       sync.Mutex
+	workerID int64
+	lastMs   int64
+	sequence int64
+}
+
+// New creates a generator for the given worker ID. Every instance writing to
+// the same table must use a distinct worker ID.
+func New(workerID int) (*Generator, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("snowflake: worker id must be between 0 and %d, got %d", MaxWorkerID, workerID)
+	}
+
+	return &Generator{
+		workerID: int64(workerID),
+	}, nil
+}
+
+// Next returns a new ID.
+func (g *Generator) Next() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.sinceEpoch()
+	// Never go back in time if the wall clock is adjusted backwards; keep
+	// counting on the last millisecond instead.
+	if ms < g.lastMs {
+		ms = g.lastMs
+	}
+
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// Sequence exhausted, wait for the next millisecond.
+			for ms <= g.lastMs {
+				time.Sleep(time.Millisecond / 10)
+				ms = max(g.sinceEpoch(), ms)
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	return ms<<timeShift | g.workerID<<sequenceBits | g.sequence
+}
+
+func (g *Generator) sinceEpoch() int64 {
+	return time.Since(Epoch).Milliseconds()
+}
+
+// Time returns the time an ID was generated, to the millisecond.
+func Time(id int64) time.Time {
+	return Epoch.Add(time.Duration(id>>timeShift) * time.Millisecond)
+}
+
+// FirstID returns the smallest ID a generator can return at t, for bounding
+// ID ranges by time.
+func FirstID(t time.Time) int64 {
+	return t.Sub(Epoch).Milliseconds() << timeShift
+}
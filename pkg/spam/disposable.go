@@ -0,0 +1,58 @@
+package spam
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// disposableDomains lists well-known disposable email providers, one domain
+// per line.
+//
+//go:embed disposable_domains.txt
+var disposableDomains string
+
+// DomainList matches email addresses against disposable email domains.
+type DomainList struct {
+	domains map[string]struct{}
+}
+
+// NewDomainList creates a list of the built-in disposable domains and extra.
+func NewDomainList(extra []string) *DomainList {
+	l := &DomainList{domains: make(map[string]struct{})}
+	for line := range strings.Lines(disposableDomains) {
+		l.add(line)
+	}
+	for _, domain := range extra {
+		l.add(domain)
+	}
+	return l
+}
+
+func (l *DomainList) add(domain string) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain != "" && !strings.HasPrefix(domain, "#") {
+		l.domains[domain] = struct{}{}
+	}
+}
+
+// IsDisposable reports whether email belongs to a listed domain or one of
+// its subdomains.
+func (l *DomainList) IsDisposable(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+	for domain != "" {
+		if _, ok := l.domains[domain]; ok {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+	return false
+}
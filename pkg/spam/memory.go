@@ -0,0 +1,45 @@
+package spam
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process DistinctCounter for development without Redis.
+// Each process counts on its own.
+type Memory struct {
+	mu   sync.Mutex
+	sets map[string]set
+}
+
+type set struct {
+	members   map[string]struct{}
+	expiresAt time.Time
+}
+
+// NewMemory creates an in-process counter.
+func NewMemory() *Memory {
+	return &Memory{sets: make(map[string]set)}
+}
+
+func (m *Memory) AddDistinct(ctx context.Context, key, member string, window time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	s, ok := m.sets[key]
+	if !ok || !now.Before(s.expiresAt) {
+		// Drop expired sets so keys that stop being used don't pile up
+		for k, other := range m.sets {
+			if !now.Before(other.expiresAt) {
+				delete(m.sets, k)
+			}
+		}
+		s = set{members: make(map[string]struct{}), expiresAt: now.Add(window)}
+		m.sets[key] = s
+	}
+
+	s.members[member] = struct{}{}
+	return len(s.members), nil
+}
@@ -0,0 +1,33 @@
+// Package spam holds the building blocks of spam detection: content
+// fingerprints, counters of distinct values and disposable email domains.
+package spam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DistinctCounter counts distinct members per key in fixed windows.
+type DistinctCounter interface {
+	// AddDistinct adds member to the set of key and returns how many distinct
+	// members it holds in the current window.
+	AddDistinct(ctx context.Context, key, member string, window time.Duration) (int, error)
+}
+
+// Fingerprint returns a short hash of content that ignores case, spacing and
+// punctuation, so trivially varied copies of a message match.
+func Fingerprint(content string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(content) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,103 @@
+package token
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepThreshold is the cache size above which expired entries are purged on insert.
+const sweepThreshold = 10000
+
+// validationCache remembers recently validated token IDs for a short TTL so
+// hot paths can skip the token store round trip. Revocations through the
+// Service invalidate entries immediately on this instance; other instances
+// may keep accepting a revoked token for at most one TTL.
+type validationCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	userID    int
+	expiresAt time.Time
+}
+
+func newValidationCache(ttl time.Duration) *validationCache {
+	return &validationCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *validationCache) enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+func (c *validationCache) get(jti string) bool {
+	if !c.enabled() {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[jti]
+	if !ok {
+		return false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, jti)
+		return false
+	}
+	return true
+}
+
+func (c *validationCache) set(jti string, userID int, tokenExpiresAt time.Time) {
+	if !c.enabled() {
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(c.ttl)
+	if !tokenExpiresAt.IsZero() && tokenExpiresAt.Before(expiresAt) {
+		expiresAt = tokenExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= sweepThreshold {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[jti] = cacheEntry{userID: userID, expiresAt: expiresAt}
+}
+
+func (c *validationCache) invalidate(jti string) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, jti)
+	c.mu.Unlock()
+}
+
+func (c *validationCache) invalidateUser(userID int) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.entries {
+		if e.userID == userID {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package token
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidGuestLink is returned for guest link tokens that are malformed,
+// forged or expired.
+var ErrInvalidGuestLink = errors.New("invalid or expired guest link")
+
+// guestLinkClaims is the payload of a guest link token.
+type guestLinkClaims struct {
+	ChatID int    `json:"chat_id"`
+	Type   string `json:"type"`
+	Exp    int64  `json:"exp"`
+}
+
+const guestLinkTokenType = "guest"
+
+// GuestLinkSigner issues and verifies signed links that let a guest join a
+// single chat without an account.
+type GuestLinkSigner struct {
+	keys linkKeys
+	ttl  time.Duration
+}
+
+// NewGuestLinkSigner creates a guest link signer whose tokens are valid for ttl.
+// Tokens signed with a previous secret still verify.
+func NewGuestLinkSigner(secret string, previous []string, ttl time.Duration) *GuestLinkSigner {
+	return &GuestLinkSigner{
+		keys: newLinkKeys(secret, previous),
+		ttl:  ttl,
+	}
+}
+
+// TTL returns how long issued tokens stay valid.
+func (s *GuestLinkSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign returns a token granting guest access to the chat.
+func (s *GuestLinkSigner) Sign(chatID int) (string, error) {
+	return s.keys.sign(guestLinkTokenType, guestLinkClaims{
+		ChatID: chatID,
+		Type:   guestLinkTokenType,
+		Exp:    time.Now().Add(s.ttl).Unix(),
+	})
+}
+
+// Verify checks the token and returns the ID of the chat it grants access to.
+func (s *GuestLinkSigner) Verify(token string) (int, error) {
+	var claims guestLinkClaims
+	if !s.keys.verify(guestLinkTokenType, token, &claims) {
+		return 0, ErrInvalidGuestLink
+	}
+
+	if claims.Type != guestLinkTokenType || claims.ChatID <= 0 {
+		return 0, ErrInvalidGuestLink
+	}
+	if time.Now().Unix() > claims.Exp {
+		return 0, ErrInvalidGuestLink
+	}
+
+	return claims.ChatID, nil
+}
@@ -0,0 +1,67 @@
+package token
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidInvite is returned for invite tokens that are malformed, forged
+// or expired.
+var ErrInvalidInvite = errors.New("invalid or expired invite token")
+
+// inviteClaims is the payload of an invite token.
+type inviteClaims struct {
+	Email string `json:"email"`
+	Type  string `json:"type"`
+	Exp   int64  `json:"exp"`
+}
+
+const inviteTokenType = "invite"
+
+// InviteSigner issues and verifies signed registration tokens that bind an
+// invited email address. They are stateless, so the link works on any
+// instance sharing the secret.
+type InviteSigner struct {
+	keys linkKeys
+	ttl  time.Duration
+}
+
+// NewInviteSigner creates an invite signer whose tokens are valid for ttl.
+// Tokens signed with a previous secret still verify.
+func NewInviteSigner(secret string, previous []string, ttl time.Duration) *InviteSigner {
+	return &InviteSigner{
+		keys: newLinkKeys(secret, previous),
+		ttl:  ttl,
+	}
+}
+
+// TTL returns how long issued tokens stay valid.
+func (s *InviteSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign returns a token for the email address.
+func (s *InviteSigner) Sign(email string) (string, error) {
+	return s.keys.sign(inviteTokenType, inviteClaims{
+		Email: email,
+		Type:  inviteTokenType,
+		Exp:   time.Now().Add(s.ttl).Unix(),
+	})
+}
+
+// Verify checks the token and returns the invited email address.
+func (s *InviteSigner) Verify(token string) (string, error) {
+	var claims inviteClaims
+	if !s.keys.verify(inviteTokenType, token, &claims) {
+		return "", ErrInvalidInvite
+	}
+
+	if claims.Type != inviteTokenType || claims.Email == "" {
+		return "", ErrInvalidInvite
+	}
+	if time.Now().Unix() > claims.Exp {
+		return "", ErrInvalidInvite
+	}
+
+	return claims.Email, nil
+}
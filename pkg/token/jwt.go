@@ -1,15 +1,15 @@
 package token
 
 import (
-	"crypto/hmac"
-	"crypto/rand"
-	"crypto/sha256"
+	"crypto"
 	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TokenType represents the type of token.
@@ -22,37 +22,129 @@ const (
 
 // Claims represents JWT claims.
 type Claims struct {
-	JTI    string `json:"jti"`     // JWT ID - unique token identifier
-	UserID int    `json:"user_id"`
-	Role   string `json:"role"`
-	Type   string `json:"type"`
-	Exp    int64  `json:"exp"`
-	Iat    int64  `json:"iat"`
+	JTI           string `json:"jti"` // JWT ID - unique token identifier (UUID)
+	Issuer        string `json:"iss,omitempty"`
+	Audience      string `json:"aud,omitempty"`
+	UserID        int    `json:"user_id"`
+	Role          string `json:"role"`
+	WorkspaceID   int    `json:"workspace_id,omitempty"`
+	WorkspaceRole string `json:"workspace_role,omitempty"`
+	Type          string `json:"type"`
+	Exp           int64  `json:"exp"`
+	Iat           int64  `json:"iat"`
+}
+
+// Subject identifies who a token is issued to and in which workspace.
+type Subject struct {
+	UserID        int
+	Role          string
+	WorkspaceID   int
+	WorkspaceRole string
 }
 
 // Generator defines the interface for token generation and validation.
 type Generator interface {
-	Generate(userID int, role string, tokenType TokenType) (string, error)
+	Generate(sub Subject, tokenType TokenType) (string, error)
 	Validate(token string) (*Claims, error)
+
+	// JWKS returns the public keys tokens are verified with, so other
+	// services can validate tokens. HMAC keys are left out.
+	JWKS() []JWK
+}
+
+// SigningKey is a key tokens are signed with, named by the kid header of the
+// tokens it signs. Exactly one of Secret (HS256), PrivateKey (RS256 or
+// EdDSA, by key type) or PublicKey (a retired asymmetric key that only
+// verifies) is set.
+type SigningKey struct {
+	ID         string
+	Secret     string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// ParseSigningKeys parses keys written as "kid:secret".
+func ParseSigningKeys(pairs []string) ([]SigningKey, error) {
+	keys := make([]SigningKey, 0, len(pairs))
+	for _, pair := range pairs {
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid signing key %q: want kid:secret", id)
+		}
+		keys = append(keys, SigningKey{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
+// GeneratorConfig holds the keys and claims of issued tokens.
+type GeneratorConfig struct {
+	// Keys verify tokens by their kid. The first one signs new tokens and
+	// can't be a PublicKey; the others keep tokens signed before a rotation
+	// valid until they expire.
+	Keys            []SigningKey
+	Issuer          string
+	Audience        string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// UnkeyedBefore accepts HS256 tokens without a kid header, issued
+	// before key IDs were introduced, if their iat is before it. Zero
+	// rejects them.
+	UnkeyedBefore time.Time
 }
 
 type jwtGenerator struct {
-	secret          []byte
+	signingKey      *jwtKey
+	keys            []*jwtKey
+	issuer          string
+	audience        string
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	unkeyedBefore   time.Time
 }
 
 // NewGenerator creates a new JWT token generator.
-func NewGenerator(secret string, accessTokenTTL, refreshTokenTTL time.Duration) Generator {
-	return &jwtGenerator{
-		secret:          []byte(secret),
-		accessTokenTTL:  accessTokenTTL,
-		refreshTokenTTL: refreshTokenTTL,
+func NewGenerator(cfg GeneratorConfig) (Generator, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+
+	keys := make([]*jwtKey, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		k, err := newJWTKey(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range keys {
+			if other.id == k.id {
+				return nil, fmt.Errorf("duplicate signing key ID %q", k.id)
+			}
+		}
+		keys = append(keys, k)
+	}
+	if keys[0].alg != AlgHS256 && keys[0].private == nil {
+		return nil, errors.New("the first signing key needs a private key")
 	}
+
+	return &jwtGenerator{
+		signingKey:      keys[0],
+		keys:            keys,
+		issuer:          cfg.Issuer,
+		audience:        cfg.Audience,
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+		unkeyedBefore:   cfg.UnkeyedBefore,
+	}, nil
+}
+
+// jwtHeader is the header of issued tokens.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
 }
 
 // Generate creates a new JWT token.
-func (g *jwtGenerator) Generate(userID int, role string, tokenType TokenType) (string, error) {
+func (g *jwtGenerator) Generate(sub Subject, tokenType TokenType) (string, error) {
 	now := time.Now()
 	var exp time.Time
 
@@ -62,28 +154,24 @@ func (g *jwtGenerator) Generate(userID int, role string, tokenType TokenType) (s
 		exp = now.Add(g.refreshTokenTTL)
 	}
 
-	// Generate unique token ID
-	jti, err := generateJTI()
-	if err != nil {
-		return "", fmt.Errorf("failed to generate JTI: %w", err)
-	}
-
 	claims := Claims{
-		JTI:    jti,
-		UserID: userID,
-		Role:   role,
-		Type:   string(tokenType),
-		Iat:    now.Unix(),
-		Exp:    exp.Unix(),
-	}
-
-	// Create header
-	header := map[string]string{
-		"alg": "HS256",
-		"typ": "JWT",
+		JTI:           uuid.NewString(),
+		Issuer:        g.issuer,
+		Audience:      g.audience,
+		UserID:        sub.UserID,
+		Role:          sub.Role,
+		WorkspaceID:   sub.WorkspaceID,
+		WorkspaceRole: sub.WorkspaceRole,
+		Type:          string(tokenType),
+		Iat:           now.Unix(),
+		Exp:           exp.Unix(),
 	}
 
-	headerJSON, err := json.Marshal(header)
+	headerJSON, err := json.Marshal(jwtHeader{
+		Alg: g.signingKey.alg,
+		Typ: "JWT",
+		Kid: g.signingKey.id,
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal header: %w", err)
 	}
@@ -99,7 +187,10 @@ func (g *jwtGenerator) Generate(userID int, role string, tokenType TokenType) (s
 
 	// Create signature
 	message := headerEncoded + "." + claimsEncoded
-	signature := g.sign(message)
+	signature, err := g.signingKey.sign(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
 
 	return message + "." + signature, nil
 }
@@ -115,11 +206,19 @@ func (g *jwtGenerator) Validate(token string) (*Claims, error) {
 	claimsEncoded := parts[1]
 	signatureEncoded := parts[2]
 
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerEncoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode header: %w", err)
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal header: %w", err)
+	}
+
 	// Verify signature
 	message := headerEncoded + "." + claimsEncoded
-	expectedSignature := g.sign(message)
-
-	if signatureEncoded != expectedSignature {
+	if !g.verify(header, message, signatureEncoded) {
 		return nil, fmt.Errorf("invalid token signature")
 	}
 
@@ -138,22 +237,55 @@ func (g *jwtGenerator) Validate(token string) (*Claims, error) {
 	if time.Now().Unix() > claims.Exp {
 		return nil, fmt.Errorf("token expired")
 	}
+	if claims.JTI == "" {
+		return nil, fmt.Errorf("token has no ID")
+	}
+
+	// Tokens issued before key IDs were introduced carry no issuer or
+	// audience; they are accepted only if issued before the rollout
+	if header.Kid == "" {
+		if claims.Iat >= g.unkeyedBefore.Unix() {
+			return nil, fmt.Errorf("token has no key ID")
+		}
+		return &claims, nil
+	}
+	if claims.Issuer != g.issuer {
+		return nil, fmt.Errorf("invalid token issuer")
+	}
+	if claims.Audience != g.audience {
+		return nil, fmt.Errorf("invalid token audience")
+	}
 
 	return &claims, nil
 }
 
-func (g *jwtGenerator) sign(message string) string {
-	h := hmac.New(sha256.New, g.secret)
-	h.Write([]byte(message))
-	signature := h.Sum(nil)
-	return base64.RawURLEncoding.EncodeToString(signature)
+// verify checks the signature with the key named by the kid header, or with
+// every HMAC key for tokens issued before key IDs were introduced. The
+// header algorithm must be the key's, so a public key is never used as an
+// HMAC secret.
+func (g *jwtGenerator) verify(header jwtHeader, message, signature string) bool {
+	for _, key := range g.keys {
+		switch {
+		case header.Kid != "" && key.id != header.Kid:
+			continue
+		case header.Kid == "" && key.alg != AlgHS256:
+			continue
+		case key.alg != header.Alg:
+			continue
+		}
+		if key.verify(message, signature) {
+			return true
+		}
+	}
+	return false
 }
 
-// generateJTI generates a unique token ID using crypto/rand.
-func generateJTI() (string, error) {
-	b := make([]byte, 16) // 128 bits
-	if _, err := rand.Read(b); err != nil {
-		return "", err
+func (g *jwtGenerator) JWKS() []JWK {
+	jwks := make([]JWK, 0, len(g.keys))
+	for _, key := range g.keys {
+		if jwk, ok := key.jwk(); ok {
+			jwks = append(jwks, jwk)
+		}
 	}
-	return hex.EncodeToString(b), nil
+	return jwks
 }
@@ -0,0 +1,196 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Signing algorithms of issued tokens.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing.
+const minRSAKeyBits = 2048
+
+// jwtKey signs and verifies tokens with one algorithm. Retired asymmetric
+// keys only have their public half and can't sign.
+type jwtKey struct {
+	id      string
+	alg     string
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// newJWTKey picks the algorithm of a signing key from the key material set.
+func newJWTKey(key SigningKey) (*jwtKey, error) {
+	if key.ID == "" {
+		return nil, errors.New("signing keys need an ID")
+	}
+
+	k := &jwtKey{id: key.ID}
+	switch {
+	case key.Secret != "":
+		k.alg = AlgHS256
+		k.secret = []byte(key.Secret)
+		return k, nil
+	case key.PrivateKey != nil:
+		k.private = key.PrivateKey
+		k.public = key.PrivateKey.Public()
+	case key.PublicKey != nil:
+		k.public = key.PublicKey
+	default:
+		return nil, fmt.Errorf("signing key %q has no secret or key", key.ID)
+	}
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("signing key %q: RSA keys need at least %d bits", key.ID, minRSAKeyBits)
+		}
+		k.alg = AlgRS256
+	case ed25519.PublicKey:
+		k.alg = AlgEdDSA
+	default:
+		return nil, fmt.Errorf("signing key %q: unsupported key type %T", key.ID, k.public)
+	}
+	return k, nil
+}
+
+func (k *jwtKey) sign(message string) (string, error) {
+	var signature []byte
+	switch k.alg {
+	case AlgHS256:
+		h := hmac.New(sha256.New, k.secret)
+		h.Write([]byte(message))
+		signature = h.Sum(nil)
+	case AlgRS256:
+		if k.private == nil {
+			return "", fmt.Errorf("signing key %q can only verify", k.id)
+		}
+		digest := sha256.Sum256([]byte(message))
+		var err error
+		signature, err = k.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+		if err != nil {
+			return "", err
+		}
+	case AlgEdDSA:
+		if k.private == nil {
+			return "", fmt.Errorf("signing key %q can only verify", k.id)
+		}
+		var err error
+		signature, err = k.private.Sign(rand.Reader, []byte(message), crypto.Hash(0))
+		if err != nil {
+			return "", err
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (k *jwtKey) verify(message, signature string) bool {
+	if k.alg == AlgHS256 {
+		expected, err := k.sign(message)
+		return err == nil && hmac.Equal([]byte(signature), []byte(expected))
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256([]byte(message))
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, []byte(message), sig)
+	}
+	return false
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Ed25519 curve
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// jwk returns the public key as a JWK. HMAC secrets are never published.
+func (k *jwtKey) jwk() (JWK, bool) {
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.id,
+			Use: "sig",
+			Alg: AlgRS256,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.id,
+			Use: "sig",
+			Alg: AlgEdDSA,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// ParsePrivateKeyPEM parses an RSA or Ed25519 private key in PKCS#8 PEM, or
+// an RSA key in PKCS#1 PEM.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// ParsePublicKeyPEM parses an RSA or Ed25519 public key in PKIX PEM.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
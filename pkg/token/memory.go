@@ -0,0 +1,134 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process TokenStore for development without Redis.
+// Tokens are lost when the process exits, which signs every user out.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[memoryTokenKey]memoryToken
+	users  map[int]map[string]struct{} // user ID -> token IDs
+}
+
+type memoryTokenKey struct {
+	tokenType string
+	tokenID   string
+}
+
+type memoryToken struct {
+	userID    int
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-process token store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens: make(map[memoryTokenKey]memoryToken),
+		users:  make(map[int]map[string]struct{}),
+	}
+}
+
+// StoreToken stores a token with the given TTL.
+func (s *MemoryStore) StoreToken(
+	ctx context.Context,
+	tokenID string,
+	userID int,
+	tokenType string,
+	ttl time.Duration,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[memoryTokenKey{tokenType: tokenType, tokenID: tokenID}] = memoryToken{
+		userID:    userID,
+		expiresAt: time.Now().Add(ttl),
+	}
+	if s.users[userID] == nil {
+		s.users[userID] = make(map[string]struct{})
+	}
+	s.users[userID][tokenID] = struct{}{}
+
+	return nil
+}
+
+// TokenExists checks if an unexpired token is stored.
+func (s *MemoryStore) TokenExists(ctx context.Context, tokenID string, tokenType string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.get(tokenID, tokenType)
+	return ok, nil
+}
+
+// GetUserIDByToken retrieves the user ID associated with a token.
+func (s *MemoryStore) GetUserIDByToken(ctx context.Context, tokenID string, tokenType string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.get(tokenID, tokenType)
+	if !ok {
+		return 0, fmt.Errorf("token not found")
+	}
+
+	return token.userID, nil
+}
+
+// RevokeToken revokes a specific token.
+func (s *MemoryStore) RevokeToken(ctx context.Context, tokenID string, tokenType string, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, memoryTokenKey{tokenType: tokenType, tokenID: tokenID})
+	delete(s.users[userID], tokenID)
+
+	return nil
+}
+
+// ConsumeToken revokes a token and reports whether it was still stored.
+func (s *MemoryStore) ConsumeToken(ctx context.Context, tokenID string, tokenType string, userID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.get(tokenID, tokenType)
+	delete(s.tokens, memoryTokenKey{tokenType: tokenType, tokenID: tokenID})
+	delete(s.users[userID], tokenID)
+
+	return ok, nil
+}
+
+// RevokeAllUserTokens revokes all tokens of a user.
+func (s *MemoryStore) RevokeAllUserTokens(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for tokenID := range s.users[userID] {
+		delete(s.tokens, memoryTokenKey{tokenType: string(TokenTypeAccess), tokenID: tokenID})
+		delete(s.tokens, memoryTokenKey{tokenType: string(TokenTypeRefresh), tokenID: tokenID})
+	}
+	delete(s.users, userID)
+
+	return nil
+}
+
+// get returns an unexpired token, dropping it once it expired. The caller
+// holds the lock.
+func (s *MemoryStore) get(tokenID, tokenType string) (memoryToken, bool) {
+	key := memoryTokenKey{tokenType: tokenType, tokenID: tokenID}
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return memoryToken{}, false
+	}
+	if time.Now().After(token.expiresAt) {
+		delete(s.tokens, key)
+		delete(s.users[token.userID], tokenID)
+		return memoryToken{}, false
+	}
+
+	return token, true
+}
@@ -1,17 +1,37 @@
 package token
 
 import (
+	"chatx-01-backend/pkg/metrics"
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
 
+var (
+	cacheHitsTotal = metrics.NewCounter(
+		"token_validation_cache_hits_total",
+		"Number of token validations served from the local cache.",
+	)
+	cacheMissesTotal = metrics.NewCounter(
+		"token_validation_cache_misses_total",
+		"Number of token validations that required a token store lookup.",
+	)
+)
+
+// ErrTokenReused is returned by Consume for a valid token that was already
+// consumed or revoked.
+var ErrTokenReused = errors.New("token has already been used")
+
 // TokenStore defines the interface for token storage operations.
 type TokenStore interface {
 	StoreToken(ctx context.Context, tokenID string, userID int, tokenType string, ttl time.Duration) error
 	TokenExists(ctx context.Context, tokenID string, tokenType string) (bool, error)
 	GetUserIDByToken(ctx context.Context, tokenID string, tokenType string) (int, error)
 	RevokeToken(ctx context.Context, tokenID string, tokenType string, userID int) error
+	// ConsumeToken revokes a token and reports whether it was still stored,
+	// atomically, so a token is consumed once.
+	ConsumeToken(ctx context.Context, tokenID string, tokenType string, userID int) (bool, error)
 	RevokeAllUserTokens(ctx context.Context, userID int) error
 }
 
@@ -21,22 +41,30 @@ type Service struct {
 	tokenStore      TokenStore
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	cache           *validationCache
 }
 
-// NewService creates a new token service.
-func NewService(generator Generator, tokenStore TokenStore, accessTokenTTL, refreshTokenTTL time.Duration) *Service {
+// NewService creates a new token service. A positive validationCacheTTL enables
+// an in-process cache of validated tokens; zero disables it.
+func NewService(
+	generator Generator,
+	tokenStore TokenStore,
+	accessTokenTTL, refreshTokenTTL time.Duration,
+	validationCacheTTL time.Duration,
+) *Service {
 	return &Service{
 		generator:       generator,
 		tokenStore:      tokenStore,
 		accessTokenTTL:  accessTokenTTL,
 		refreshTokenTTL: refreshTokenTTL,
+		cache:           newValidationCache(validationCacheTTL),
 	}
 }
 
 // GenerateAndStore generates a JWT token and stores it in Redis.
-func (s *Service) GenerateAndStore(ctx context.Context, userID int, role string, tokenType TokenType) (string, error) {
+func (s *Service) GenerateAndStore(ctx context.Context, sub Subject, tokenType TokenType) (string, error) {
 	// Generate JWT
-	tokenString, err := s.generator.Generate(userID, role, tokenType)
+	tokenString, err := s.generator.Generate(sub, tokenType)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -56,7 +84,7 @@ func (s *Service) GenerateAndStore(ctx context.Context, userID int, role string,
 	}
 
 	// Store in Redis
-	err = s.tokenStore.StoreToken(ctx, claims.JTI, userID, string(tokenType), ttl)
+	err = s.tokenStore.StoreToken(ctx, claims.JTI, sub.UserID, string(tokenType), ttl)
 	if err != nil {
 		return "", fmt.Errorf("failed to store token: %w", err)
 	}
@@ -72,6 +100,14 @@ func (s *Service) ValidateAndCheck(ctx context.Context, tokenString string) (*Cl
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
+	if s.cache.get(claims.JTI) {
+		cacheHitsTotal.Inc()
+		return claims, nil
+	}
+	if s.cache.enabled() {
+		cacheMissesTotal.Inc()
+	}
+
 	// Check if token exists in Redis (not revoked)
 	exists, err := s.tokenStore.TokenExists(ctx, claims.JTI, claims.Type)
 	if err != nil {
@@ -82,6 +118,8 @@ func (s *Service) ValidateAndCheck(ctx context.Context, tokenString string) (*Cl
 		return nil, fmt.Errorf("token has been revoked")
 	}
 
+	s.cache.set(claims.JTI, claims.UserID, time.Unix(claims.Exp, 0))
+
 	return claims, nil
 }
 
@@ -94,6 +132,8 @@ func (s *Service) Revoke(ctx context.Context, tokenString string) error {
 		return nil
 	}
 
+	s.cache.invalidate(claims.JTI)
+
 	// Revoke from Redis
 	err = s.tokenStore.RevokeToken(ctx, claims.JTI, claims.Type, claims.UserID)
 	if err != nil {
@@ -103,8 +143,39 @@ func (s *Service) Revoke(ctx context.Context, tokenString string) error {
 	return nil
 }
 
+// Consume validates a token of the given type and revokes it, so it can be
+// used once even by concurrent requests. A valid token that comes back
+// after it was consumed was likely stolen, so every token of its user is
+// revoked and ErrTokenReused returned.
+func (s *Service) Consume(ctx context.Context, tokenString string, tokenType TokenType) (*Claims, error) {
+	claims, err := s.generator.Validate(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if claims.Type != string(tokenType) {
+		return nil, fmt.Errorf("invalid token type %q", claims.Type)
+	}
+
+	s.cache.invalidate(claims.JTI)
+
+	consumed, err := s.tokenStore.ConsumeToken(ctx, claims.JTI, claims.Type, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+	if !consumed {
+		if err := s.RevokeAllUserTokens(ctx, claims.UserID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+
+	return claims, nil
+}
+
 // RevokeAllUserTokens revokes all tokens for a specific user.
 func (s *Service) RevokeAllUserTokens(ctx context.Context, userID int) error {
+	s.cache.invalidateUser(userID)
+
 	err := s.tokenStore.RevokeAllUserTokens(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke all user tokens: %w", err)
@@ -113,6 +184,11 @@ func (s *Service) RevokeAllUserTokens(ctx context.Context, userID int) error {
 	return nil
 }
 
+// JWKS returns the public keys tokens are verified with.
+func (s *Service) JWKS() []JWK {
+	return s.generator.JWKS()
+}
+
 // GetClaims parses and returns claims without Redis validation (for logout).
 func (s *Service) GetClaims(tokenString string) (*Claims, error) {
 	return s.generator.Validate(tokenString)
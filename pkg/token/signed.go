@@ -0,0 +1,77 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// signClaims encodes claims and signs them for the token type. The type is
+// part of the signed message, so a token of one type never verifies as
+// another even when both share the secret.
+func signClaims(secret []byte, tokenType string, claims any) (string, error) {
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s claims: %w", tokenType, err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(claimsJSON)
+	return payload + "." + signPayload(secret, tokenType, payload), nil
+}
+
+// verifyClaims checks the token signature and decodes its claims into v.
+// Reports false for malformed or forged tokens.
+func verifyClaims(secret []byte, tokenType, token string, v any) bool {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signPayload(secret, tokenType, payload))) {
+		return false
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(claimsJSON, v) == nil
+}
+
+func signPayload(secret []byte, tokenType, payload string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(tokenType + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// linkKeys signs with the current secret and verifies with it or a previous
+// one, so rotating the secret doesn't invalidate links already sent.
+type linkKeys struct {
+	secrets [][]byte
+}
+
+func newLinkKeys(secret string, previous []string) linkKeys {
+	secrets := make([][]byte, 0, len(previous)+1)
+	secrets = append(secrets, []byte(secret))
+	for _, s := range previous {
+		secrets = append(secrets, []byte(s))
+	}
+	return linkKeys{secrets: secrets}
+}
+
+func (k linkKeys) sign(tokenType string, claims any) (string, error) {
+	return signClaims(k.secrets[0], tokenType, claims)
+}
+
+func (k linkKeys) verify(tokenType, token string, v any) bool {
+	for _, secret := range k.secrets {
+		if verifyClaims(secret, tokenType, token, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package token
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidVerification is returned for email verification tokens that are
+// malformed, forged or expired.
+var ErrInvalidVerification = errors.New("invalid or expired verification token")
+
+// verificationClaims is the payload of an email verification token.
+type verificationClaims struct {
+	UserID int    `json:"user_id"`
+	Email  string `json:"email"`
+	Type   string `json:"type"`
+	Exp    int64  `json:"exp"`
+}
+
+const verificationTokenType = "verify"
+
+// VerificationSigner issues and verifies signed links that confirm a user
+// owns their email address.
+type VerificationSigner struct {
+	keys linkKeys
+	ttl  time.Duration
+}
+
+// NewVerificationSigner creates a verification signer whose tokens are valid
+// for ttl. Tokens signed with a previous secret still verify.
+func NewVerificationSigner(secret string, previous []string, ttl time.Duration) *VerificationSigner {
+	return &VerificationSigner{
+		keys: newLinkKeys(secret, previous),
+		ttl:  ttl,
+	}
+}
+
+// TTL returns how long issued tokens stay valid.
+func (s *VerificationSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign returns a token confirming the user's email address.
+func (s *VerificationSigner) Sign(userID int, email string) (string, error) {
+	return s.keys.sign(verificationTokenType, verificationClaims{
+		UserID: userID,
+		Email:  email,
+		Type:   verificationTokenType,
+		Exp:    time.Now().Add(s.ttl).Unix(),
+	})
+}
+
+// Verify checks the token and returns the user ID and email address it was
+// issued for.
+func (s *VerificationSigner) Verify(token string) (int, string, error) {
+	var claims verificationClaims
+	if !s.keys.verify(verificationTokenType, token, &claims) {
+		return 0, "", ErrInvalidVerification
+	}
+
+	if claims.Type != verificationTokenType || claims.UserID <= 0 || claims.Email == "" {
+		return 0, "", ErrInvalidVerification
+	}
+	if time.Now().Unix() > claims.Exp {
+		return 0, "", ErrInvalidVerification
+	}
+
+	return claims.UserID, claims.Email, nil
+}
+
+// emailChangeClaims is the payload of an email change confirmation token.
+type emailChangeClaims struct {
+	UserID   int    `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+	Type     string `json:"type"`
+	Exp      int64  `json:"exp"`
+}
+
+const emailChangeTokenType = "email_change"
+
+// SignEmailChange returns a token confirming the user owns newEmail and wants
+// it to replace oldEmail.
+func (s *VerificationSigner) SignEmailChange(userID int, oldEmail, newEmail string) (string, error) {
+	return s.keys.sign(emailChangeTokenType, emailChangeClaims{
+		UserID:   userID,
+		OldEmail: oldEmail,
+		NewEmail: newEmail,
+		Type:     emailChangeTokenType,
+		Exp:      time.Now().Add(s.ttl).Unix(),
+	})
+}
+
+// VerifyEmailChange checks an email change token and returns the user ID and
+// the old and new email addresses it was issued for.
+func (s *VerificationSigner) VerifyEmailChange(token string) (int, string, string, error) {
+	var claims emailChangeClaims
+	if !s.keys.verify(emailChangeTokenType, token, &claims) {
+		return 0, "", "", ErrInvalidVerification
+	}
+
+	if claims.Type != emailChangeTokenType || claims.UserID <= 0 || claims.NewEmail == "" {
+		return 0, "", "", ErrInvalidVerification
+	}
+	if time.Now().Unix() > claims.Exp {
+		return 0, "", "", ErrInvalidVerification
+	}
+
+	return claims.UserID, claims.OldEmail, claims.NewEmail, nil
+}
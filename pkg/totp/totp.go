@@ -0,0 +1,74 @@
+// Package totp validates time-based one-time passwords (RFC 6238) as
+// generated by authenticator apps: 6 digits, 30 second steps, HMAC-SHA1.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	step   = 30 * time.Second
+	digits = 6
+
+	// skew is the number of steps before and after the current one whose
+	// codes are accepted, allowing for clock drift and typing time.
+	skew = 1
+)
+
+// DecodeSecret decodes a base32 secret as shown by authenticator apps.
+// Spaces and padding are optional and letters are case-insensitive.
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32 secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("empty secret")
+	}
+	return key, nil
+}
+
+// Code returns the code of the step that contains t.
+func Code(key []byte, t time.Time) string {
+	return generate(key, uint64(t.Unix())/uint64(step.Seconds()))
+}
+
+// Validate reports whether code is the code of the step that contains t or
+// of an adjacent step.
+func Validate(key []byte, code string, t time.Time) bool {
+	if len(code) != digits {
+		return false
+	}
+
+	counter := int64(t.Unix()) / int64(step.Seconds())
+	valid := 0
+	for i := counter - skew; i <= counter+skew; i++ {
+		valid |= subtle.ConstantTimeCompare([]byte(generate(key, uint64(i))), []byte(code))
+	}
+	return valid == 1
+}
+
+func generate(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1_000_000)
+}
@@ -0,0 +1,75 @@
+package translate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache keeps translated messages in process, for development without
+// Redis. Expired entries are dropped when they are read.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	messageID int
+	revision  int64
+	lang      string
+}
+
+type cacheEntry struct {
+	text       string
+	sourceLang string
+	expiresAt  time.Time
+}
+
+// NewMemoryCache creates an empty translation cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[cacheKey]cacheEntry)}
+}
+
+// GetTranslation returns a cached translation of a message revision.
+// ok is false if none is cached.
+func (c *MemoryCache) GetTranslation(
+	ctx context.Context,
+	messageID int,
+	revision int64,
+	lang string,
+) (text, sourceLang string, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{messageID: messageID, revision: revision, lang: lang}
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", "", false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", "", false, nil
+	}
+
+	return entry.text, entry.sourceLang, true, nil
+}
+
+// SetTranslation caches a translation of a message revision for ttl.
+func (c *MemoryCache) SetTranslation(
+	ctx context.Context,
+	messageID int,
+	revision int64,
+	lang, text, sourceLang string,
+	ttl time.Duration,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[cacheKey{messageID: messageID, revision: revision, lang: lang}] = cacheEntry{
+		text:       text,
+		sourceLang: sourceLang,
+		expiresAt:  time.Now().Add(ttl),
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	deeplFreeURL = "https://api-free.deepl.com/v2/translate"
+	deeplProURL  = "https://api.deepl.com/v2/translate"
+	deeplTimeout = 10 * time.Second
+)
+
+// DeepLConfig holds configuration for the DeepL translator.
+type DeepLConfig struct {
+	APIKey string // keys ending in ":fx" use the free API
+}
+
+type deeplTranslator struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+// NewDeepL creates a translator backed by the DeepL API.
+func NewDeepL(cfg DeepLConfig) (Translator, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("deepl api key is required")
+	}
+
+	url := deeplProURL
+	if strings.HasSuffix(cfg.APIKey, ":fx") {
+		url = deeplFreeURL
+	}
+
+	return &deeplTranslator{
+		apiKey: cfg.APIKey,
+		url:    url,
+		client: &http.Client{Timeout: deeplTimeout},
+	}, nil
+}
+
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deeplResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+func (t *deeplTranslator) Translate(ctx context.Context, text, targetLang string) (*Result, error) {
+	body, err := json.Marshal(deeplRequest{
+		Text:       []string{text},
+		TargetLang: strings.ToUpper(targetLang),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode deepl request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build deepl request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call deepl: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deepl returned status %d", resp.StatusCode)
+	}
+
+	var out deeplResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode deepl response: %w", err)
+	}
+	if len(out.Translations) == 0 {
+		return nil, fmt.Errorf("deepl returned no translations")
+	}
+
+	return &Result{
+		Text:       out.Translations[0].Text,
+		SourceLang: strings.ToLower(out.Translations[0].DetectedSourceLanguage),
+	}, nil
+}
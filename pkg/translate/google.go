@@ -0,0 +1,104 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	googleURL     = "https://translation.googleapis.com/language/translate/v2"
+	googleTimeout = 10 * time.Second
+)
+
+// GoogleConfig holds configuration for the Google Cloud Translation translator.
+type GoogleConfig struct {
+	APIKey string
+}
+
+type googleTranslator struct {
+	url    string
+	client *http.Client
+}
+
+// NewGoogle creates a translator backed by the Google Cloud Translation API (v2).
+func NewGoogle(cfg GoogleConfig) (Translator, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("google api key is required")
+	}
+
+	return &googleTranslator{
+		url:    googleURL + "?key=" + url.QueryEscape(cfg.APIKey),
+		client: &http.Client{Timeout: googleTimeout},
+	}, nil
+}
+
+type googleRequest struct {
+	Q      string `json:"q"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+}
+
+type googleResponse struct {
+	Data struct {
+		Translations []struct {
+			TranslatedText         string `json:"translatedText"`
+			DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+		} `json:"translations"`
+	} `json:"data"`
+}
+
+func (t *googleTranslator) Translate(ctx context.Context, text, targetLang string) (*Result, error) {
+	body, err := json.Marshal(googleRequest{
+		Q:      text,
+		Target: targetLang,
+		Format: "text", // keep markdown and HTML-like content as is
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode google request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build google request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The URL carries the API key, so don't let it end up in the logs
+		return nil, fmt.Errorf("failed to call google translate: %w", unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google translate returned status %d", resp.StatusCode)
+	}
+
+	var out googleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode google response: %w", err)
+	}
+	if len(out.Data.Translations) == 0 {
+		return nil, fmt.Errorf("google translate returned no translations")
+	}
+
+	return &Result{
+		Text:       out.Data.Translations[0].TranslatedText,
+		SourceLang: strings.ToLower(out.Data.Translations[0].DetectedSourceLanguage),
+	}, nil
+}
+
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidLanguage is returned for language codes that aren't of the form
+// "de" or "pt-BR".
+var ErrInvalidLanguage = errors.New("language must be an ISO 639-1 code with an optional region, e.g. de or pt-BR")
+
+// Result is a translated text.
+type Result struct {
+	Text string
+	// SourceLang is the language the provider detected, lowercased (e.g. "en").
+	SourceLang string
+}
+
+// Translator translates text through an external provider.
+type Translator interface {
+	// Translate translates text into targetLang, detecting the source language.
+	Translate(ctx context.Context, text, targetLang string) (*Result, error)
+}
+
+// NormalizeLanguage validates a language code and returns it lowercased, so
+// "PT-br" and "pt-BR" name the same translation.
+func NormalizeLanguage(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+
+	base, region, hasRegion := strings.Cut(lang, "-")
+	if !isLetters(base, 2) {
+		return "", ErrInvalidLanguage
+	}
+	if hasRegion && !isLetters(region, 2) && !isLetters(region, 4) {
+		return "", ErrInvalidLanguage
+	}
+
+	return lang, nil
+}
+
+func isLetters(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
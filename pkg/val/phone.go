@@ -0,0 +1,23 @@
+package val
+
+import (
+	"errors"
+	"regexp"
+)
+
+const (
+	// E.164: a plus sign and up to 15 digits, the first of them not zero
+	phoneRegex = `^\+[1-9][0-9]{6,14}$`
+)
+
+var (
+	ErrInvalidPhone = errors.New("must be a phone number like +998901234567.")
+)
+
+func ValidatePhone(phone string) error {
+	if regexp.MustCompile(phoneRegex).MatchString(phone) {
+		return nil
+	}
+
+	return ErrInvalidPhone
+}
@@ -0,0 +1,34 @@
+package val
+
+import "errors"
+
+var (
+	ErrInvalidSlug = errors.New(
+		"must be between 3 and 50 characters long and can only contain lowercase letters, numbers, and hyphens.",
+	)
+)
+
+func ValidateSlug(slug string) error {
+	if !isValidSlug(slug) {
+		return ErrInvalidSlug
+	}
+
+	return nil
+}
+
+func isValidSlug(slug string) bool {
+	if len(slug) < 3 || len(slug) > 50 {
+		return false
+	}
+	if slug[0] == '-' || slug[len(slug)-1] == '-' {
+		return false
+	}
+
+	for _, c := range slug {
+		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}